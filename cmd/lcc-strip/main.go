@@ -0,0 +1,91 @@
+// Command lcc-strip prepares a source tree for edition-specific builds.
+//
+// Given a feature manifest and the features an edition is entitled to, it
+// moves each unlicensed feature function behind a generated build tag and
+// writes a stub in its place, then prints the tags to build the edition with:
+//
+//	lcc-strip -manifest lcc-features.yaml -src . -features basic_reports,export
+//	go build -tags "$(lcc-strip -manifest lcc-features.yaml -features basic_reports,export -tags-only)" ./...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/yourorg/lcc-sdk/pkg/activation"
+	"github.com/yourorg/lcc-sdk/pkg/codegen"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func main() {
+	manifestPath := flag.String("manifest", "lcc-features.yaml", "feature manifest")
+	srcDir := flag.String("src", ".", "source root containing the intercepted packages")
+	features := flag.String("features", "", "comma-separated features the edition includes (\"*\" for all)")
+	entitlementPath := flag.String("entitlement", "", "JSON entitlement file whose \"features\" the edition includes")
+	tagsOnly := flag.Bool("tags-only", false, "print the edition's build tags without rewriting sources")
+	flag.Parse()
+
+	manifest, err := config.LoadManifest(*manifestPath)
+	if err != nil {
+		log.Fatalf("failed to load manifest: %v", err)
+	}
+
+	ent, err := loadEntitlement(*features, *entitlementPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stripper := codegen.NewStripper(manifest)
+	tags := strings.Join(stripper.EditionTags(ent.Allows), ",")
+	if *tagsOnly {
+		fmt.Println(tags)
+		return
+	}
+
+	files, err := stripper.Prepare(*srcDir, ent.Allows)
+	for _, f := range files {
+		fmt.Printf("Updated: %s\n", f)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if tags == "" {
+		fmt.Println("Edition includes every feature; build without tags")
+		return
+	}
+	fmt.Printf("Build the edition with: go build -tags %s\n", tags)
+}
+
+// loadEntitlement builds the edition's entitlement from -features or an
+// entitlement file
+func loadEntitlement(features, path string) (*activation.Entitlement, error) {
+	switch {
+	case features != "" && path != "":
+		return nil, fmt.Errorf("-features and -entitlement are mutually exclusive")
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read entitlement: %w", err)
+		}
+		var ent activation.Entitlement
+		if err := json.Unmarshal(data, &ent); err != nil {
+			return nil, fmt.Errorf("failed to parse entitlement: %w", err)
+		}
+		return &ent, nil
+	case features != "":
+		ent := &activation.Entitlement{}
+		for _, f := range strings.Split(features, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				ent.Features = append(ent.Features, f)
+			}
+		}
+		return ent, nil
+	default:
+		return nil, fmt.Errorf("one of -features or -entitlement is required")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// License-protected entry points, in the shape lcc-codegen generates from
+// lcc-features.yaml: each wrapper enforces the license and then calls the
+// unexported original in reports.go. They depend on client.LCCClient so
+// tests can swap in clienttest.MockClient.
+
+const (
+	featureReports = "reports"
+	featureExport  = "report-export"
+	featureBatch   = "batch-jobs"
+)
+
+var _lccClient client.LCCClient
+
+// SetLCCClient sets the LCC client used by the wrappers
+func SetLCCClient(c client.LCCClient) {
+	_lccClient = c
+}
+
+// CreateReport is the license-protected wrapper of createReport: it
+// enforces the product capacity limit on stored reports
+func CreateReport(ctx context.Context, store *ReportStore, title string, rows []string) (*Report, error) {
+	if _lccClient != nil {
+		allowed, max, err := _lccClient.CheckCapacityWithHelper()
+		if !allowed {
+			log.Printf("[LCC] Capacity exceeded: max=%d, err=%v", max, err)
+			return nil, _lccClient.LimitError(client.LimitCapacity, err)
+		}
+	}
+	return createReport(ctx, store, title, rows)
+}
+
+// ExportReport is the license-protected wrapper of exportReport: it
+// requires the report-export feature, draws one unit of product quota and
+// reports the export as feature usage
+func ExportReport(ctx context.Context, store *ReportStore, id string) ([]byte, error) {
+	if _lccClient != nil {
+		status, err := _lccClient.CheckFeatureCtx(ctx, featureExport)
+		if err != nil {
+			log.Printf("[LCC] Feature check failed for %s: %v", featureExport, err)
+			return nil, err
+		}
+		if !status.Enabled {
+			return nil, client.NewFeatureError(featureExport, status, nil)
+		}
+		if allowed, remaining, err := _lccClient.Consume(1); !allowed {
+			log.Printf("[LCC] Quota exceeded: remaining=%d, err=%v", remaining, err)
+			return nil, _lccClient.LimitError(client.LimitQuota, err)
+		}
+	}
+
+	out, err := exportReport(ctx, store, id)
+	if err == nil && _lccClient != nil {
+		if err := _lccClient.ReportUsage(featureExport, 1); err != nil {
+			log.Printf("[LCC] Usage report failed for %s: %v", featureExport, err)
+		}
+	}
+	return out, err
+}
+
+// RunBatch is the license-protected wrapper of runBatch: it holds a
+// concurrency slot for the duration of the batch, checks the TPS limit and
+// draws one unit of quota per item (via the QuotaConsumer helper)
+func RunBatch(ctx context.Context, items []string) (int, error) {
+	if _lccClient != nil {
+		release, allowed, err := _lccClient.AcquireSlotContext(ctx)
+		if !allowed {
+			log.Printf("[LCC] Concurrency limit exceeded: %v", err)
+			return 0, _lccClient.LimitError(client.LimitConcurrency, err)
+		}
+		defer release()
+
+		if allowed, maxTPS, err := _lccClient.CheckTPS(); !allowed {
+			log.Printf("[LCC] TPS exceeded: max=%.2f, err=%v", maxTPS, err)
+			return 0, _lccClient.LimitError(client.LimitTPS, err)
+		}
+		if allowed, remaining, err := _lccClient.ConsumeWithContext(ctx, items); !allowed {
+			log.Printf("[LCC] Quota exceeded: remaining=%d, err=%v", remaining, err)
+			return 0, _lccClient.LimitError(client.LimitQuota, err)
+		}
+	}
+	return runBatch(ctx, items)
+}
@@ -0,0 +1,103 @@
+// Command licensed-service is a reference microservice enforcing an LCC
+// license end to end: feature gating middleware, license-protected
+// wrappers, product limits, usage reporting, metrics and graceful shutdown.
+//
+//	go run ./examples/licensed-service -config examples/licensed-service/lcc-features.yaml
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func main() {
+	configPath := flag.String("config", "lcc-features.yaml", "LCC manifest")
+	addr := flag.String("addr", ":8080", "listen address")
+	flag.Parse()
+
+	manifest, err := config.LoadManifest(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	lcc, err := client.NewClient(&manifest.SDK)
+	if err != nil {
+		log.Fatalf("Failed to create LCC client: %v", err)
+	}
+	lcc.SetManifest(manifest)
+
+	reports := NewReportStore()
+	if err := lcc.RegisterHelpers(newHelpers(reports)); err != nil {
+		log.Fatalf("Failed to register helpers: %v", err)
+	}
+	lcc.OnFeatureStatusChange(func(ch client.FeatureStatusChange) {
+		log.Printf("License change for %s: %v", ch.FeatureID, ch.Changed)
+	})
+
+	if err := lcc.Register(); err != nil {
+		log.Fatalf("Failed to register with LCC: %v", err)
+	}
+	if err := lcc.EnsureRequired(context.Background()); err != nil {
+		log.Fatalf("License check failed: %v", err)
+	}
+	SetLCCClient(lcc)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           NewService(lcc, reports, manifest.GetFeatureIDs()).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Listening on %s (instance %s)", *addr, lcc.GetInstanceID())
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("Shutting down")
+
+	// Finish in-flight requests (and release their slots) before the
+	// license client stops
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP shutdown: %v", err)
+	}
+	if err := lcc.FlushUsage(); err != nil {
+		log.Printf("Usage flush: %v", err)
+	}
+	if err := lcc.Close(); err != nil {
+		log.Printf("LCC close: %v", err)
+	}
+}
+
+// newHelpers connects product limits to the service: quota is drawn per
+// batch item and capacity is the number of stored reports
+func newHelpers(reports *ReportStore) *client.HelperFunctions {
+	return &client.HelperFunctions{
+		QuotaConsumer: func(ctx context.Context, args ...interface{}) int {
+			if len(args) > 0 {
+				if items, ok := args[0].([]string); ok {
+					return len(items)
+				}
+			}
+			return 1
+		},
+		CapacityCounter: reports.Count,
+	}
+}
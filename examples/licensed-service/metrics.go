@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// sdkStats is the introspection surface of *client.Client exported on
+// /metrics. Mock clients do not implement it and get an empty page.
+type sdkStats interface {
+	Lifecycle() client.LifecycleState
+	BreakerState() client.BreakerState
+	FeatureUsage() []client.FeatureUsage
+	HookStats() client.HookStats
+	CapacityPeak() (client.CapacityPeak, bool)
+}
+
+// metrics serves SDK internals in the Prometheus text format
+func (s *Service) metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	stats, ok := s.lcc.(sdkStats)
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "# TYPE lcc_client_registered gauge\n")
+	fmt.Fprintf(w, "lcc_client_registered %d\n", boolValue(stats.Lifecycle() == client.StateRegistered))
+	fmt.Fprintf(w, "# TYPE lcc_breaker_open gauge\n")
+	fmt.Fprintf(w, "lcc_breaker_open %d\n", boolValue(stats.BreakerState() == client.BreakerOpen))
+
+	fmt.Fprintf(w, "# TYPE lcc_feature_checks_total counter\n")
+	for _, u := range stats.FeatureUsage() {
+		fmt.Fprintf(w, "lcc_feature_checks_total{feature=%q,outcome=\"allowed\"} %d\n", u.FeatureID, u.Allowed)
+		fmt.Fprintf(w, "lcc_feature_checks_total{feature=%q,outcome=\"denied\"} %d\n", u.FeatureID, u.Denied)
+	}
+
+	hooks := stats.HookStats()
+	fmt.Fprintf(w, "# TYPE lcc_hook_invocations_total counter\n")
+	for _, m := range []struct {
+		outcome string
+		n       int64
+	}{
+		{"completed", hooks.Completed},
+		{"dropped", hooks.Dropped},
+		{"panicked", hooks.Panicked},
+		{"timed_out", hooks.TimedOut},
+	} {
+		fmt.Fprintf(w, "lcc_hook_invocations_total{outcome=%q} %d\n", m.outcome, m.n)
+	}
+
+	if peak, ok := stats.CapacityPeak(); ok {
+		fmt.Fprintf(w, "# TYPE lcc_capacity_peak gauge\n")
+		fmt.Fprintf(w, "lcc_capacity_peak %d\n", peak.Peak)
+	}
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Business code: no license logic here. The exported, license-protected
+// entry points are in licensed.go.
+
+// ErrReportNotFound is returned for unknown report IDs
+var ErrReportNotFound = errors.New("report not found")
+
+// Report is a stored report
+type Report struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Rows      []string  `json:"rows"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportStore keeps reports in memory
+type ReportStore struct {
+	mu      sync.Mutex
+	reports map[string]*Report
+	nextID  int
+}
+
+// NewReportStore creates an empty store
+func NewReportStore() *ReportStore {
+	return &ReportStore{reports: make(map[string]*Report)}
+}
+
+// Count returns the number of stored reports (the capacity counter)
+func (s *ReportStore) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.reports)
+}
+
+func createReport(_ context.Context, store *ReportStore, title string, rows []string) (*Report, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.nextID++
+	r := &Report{
+		ID:        fmt.Sprintf("r%d", store.nextID),
+		Title:     title,
+		Rows:      rows,
+		CreatedAt: time.Now(),
+	}
+	store.reports[r.ID] = r
+	return r, nil
+}
+
+func exportReport(_ context.Context, store *ReportStore, id string) ([]byte, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	r, ok := store.reports[id]
+	if !ok {
+		return nil, ErrReportNotFound
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", r.Title)
+	for _, row := range r.Rows {
+		fmt.Fprintln(&b, row)
+	}
+	return []byte(b.String()), nil
+}
+
+// runBatch processes items and returns the number processed
+func runBatch(_ context.Context, items []string) (int, error) {
+	return len(items), nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// Service is the HTTP API of the example
+type Service struct {
+	lcc      client.LCCClient
+	reports  *ReportStore
+	features []string // feature IDs listed by GET /features
+}
+
+// NewService creates the API. features are the IDs reported to the UI,
+// typically the manifest's feature IDs.
+func NewService(lcc client.LCCClient, reports *ReportStore, features []string) *Service {
+	return &Service{lcc: lcc, reports: reports, features: features}
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.health)
+	mux.HandleFunc("GET /features", s.listFeatures)
+	mux.HandleFunc("GET /metrics", s.metrics)
+	mux.Handle("POST /reports", s.requireFeature(featureReports, http.HandlerFunc(s.createReport)))
+	mux.Handle("GET /reports/{id}/export", s.requireFeature(featureReports, http.HandlerFunc(s.exportReport)))
+	mux.Handle("POST /batch", s.requireFeature(featureBatch, http.HandlerFunc(s.runBatch)))
+	return logRequests(withFeatureMemo(mux))
+}
+
+// withFeatureMemo gives every request a feature memo, so the middleware,
+// the handlers and the license wrappers share one decision per feature
+func withFeatureMemo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(client.WithFeatureMemo(r.Context())))
+	})
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s (%s)", r.Method, r.URL.Path, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// requireFeature rejects requests with 403 unless featureID is licensed
+func (s *Service) requireFeature(featureID string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := s.lcc.CheckFeatureCtx(r.Context(), featureID)
+		if err == nil && !status.Enabled {
+			err = client.NewFeatureError(featureID, status, nil)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Service) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "instance_id": s.lcc.GetInstanceID()})
+}
+
+// listFeatures reports which features are licensed, for the UI to hide or
+// disable what the customer cannot use
+func (s *Service) listFeatures(w http.ResponseWriter, r *http.Request) {
+	type feature struct {
+		ID      string `json:"id"`
+		Enabled bool   `json:"enabled"`
+		Reason  string `json:"reason,omitempty"`
+	}
+	out := make([]feature, 0, len(s.features))
+	for _, id := range s.features {
+		f := feature{ID: id}
+		if status, err := s.lcc.CheckFeatureCtx(r.Context(), id); err == nil {
+			f.Enabled, f.Reason = status.Enabled, status.Reason
+		}
+		out = append(out, f)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Service) createReport(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string   `json:"title"`
+		Rows  []string `json:"rows"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Title == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title is required"})
+		return
+	}
+	report, err := CreateReport(r.Context(), s.reports, req.Title, req.Rows)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, report)
+}
+
+func (s *Service) exportReport(w http.ResponseWriter, r *http.Request) {
+	out, err := ExportReport(r.Context(), s.reports, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(out)
+}
+
+func (s *Service) runBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Items []string `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Items) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "items are required"})
+		return
+	}
+	n, err := RunBatch(r.Context(), req.Items)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"processed": n})
+}
+
+// writeError maps license errors to HTTP statuses: unlicensed features are
+// 403, exceeded limits 429 and an unavailable license client 503
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, client.ErrFeatureNotLicensed):
+		status = http.StatusForbidden
+	case errors.Is(err, client.ErrLimitExceeded):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, client.ErrClientClosed):
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+	"github.com/yourorg/lcc-sdk/pkg/clienttest"
+)
+
+var allFeatures = []string{featureReports, featureExport, featureBatch}
+
+func newTestService(t *testing.T, lcc client.LCCClient) (*httptest.Server, *ReportStore) {
+	t.Helper()
+	reports := NewReportStore()
+	if err := lcc.RegisterHelpers(newHelpers(reports)); err != nil {
+		t.Fatalf("RegisterHelpers() error = %v", err)
+	}
+	SetLCCClient(lcc)
+	t.Cleanup(func() { SetLCCClient(nil) })
+
+	srv := httptest.NewServer(NewService(lcc, reports, allFeatures).Handler())
+	t.Cleanup(srv.Close)
+	return srv, reports
+}
+
+func do(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func TestService_Mock(t *testing.T) {
+	m := clienttest.NewMockClient()
+	m.SetFeature(featureReports, true)
+	m.SetFeature(featureExport, true)
+	m.SetProductStatus(&client.FeatureStatus{Enabled: true, MaxCapacity: 2, MaxConcurrency: 1})
+	m.SetQuota(5, 0)
+	srv, _ := newTestService(t, m)
+
+	if code, body := do(t, "POST", srv.URL+"/reports", `{"title":"Q3","rows":["a","b"]}`); code != http.StatusCreated {
+		t.Fatalf("POST /reports = %d %s", code, body)
+	}
+	if code, body := do(t, "GET", srv.URL+"/reports/r1/export", ""); code != http.StatusOK || !strings.Contains(body, "# Q3") {
+		t.Fatalf("export = %d %s", code, body)
+	}
+	if got := m.Usage(featureExport); got != 1 {
+		t.Errorf("export usage = %v, want 1", got)
+	}
+	if code, _ := do(t, "GET", srv.URL+"/reports/r9/export", ""); code != http.StatusNotFound {
+		t.Errorf("export of unknown report = %d, want 404", code)
+	}
+
+	// Capacity: two reports allowed, the third is over the limit
+	do(t, "POST", srv.URL+"/reports", `{"title":"Q4"}`)
+	if code, _ := do(t, "POST", srv.URL+"/reports", `{"title":"Q5"}`); code != http.StatusTooManyRequests {
+		t.Errorf("POST /reports over capacity = %d, want 429", code)
+	}
+
+	// Unlicensed feature
+	if code, _ := do(t, "POST", srv.URL+"/batch", `{"items":["x"]}`); code != http.StatusForbidden {
+		t.Errorf("POST /batch unlicensed = %d, want 403", code)
+	}
+	m.SetFeature(featureBatch, true)
+	if code, body := do(t, "POST", srv.URL+"/batch", `{"items":["x","y","z"]}`); code != http.StatusOK {
+		t.Errorf("POST /batch = %d %s", code, body)
+	}
+	if code, _ := do(t, "POST", srv.URL+"/batch", `{"items":["x","y"]}`); code != http.StatusTooManyRequests {
+		t.Errorf("POST /batch over quota = %d, want 429", code)
+	}
+
+	var features []struct {
+		ID      string
+		Enabled bool
+	}
+	_, body := do(t, "GET", srv.URL+"/features", "")
+	if err := json.Unmarshal([]byte(body), &features); err != nil || len(features) != 3 || !features[2].Enabled {
+		t.Errorf("GET /features = %s", body)
+	}
+
+	_ = m.Close()
+	if code, _ := do(t, "POST", srv.URL+"/reports", `{"title":"late"}`); code != http.StatusServiceUnavailable {
+		t.Errorf("POST /reports after Close = %d, want 503", code)
+	}
+}
+
+// TestService_FakeServer runs the service against a real client and a fake
+// LCC server, exercising registration, signed requests and usage reports
+func TestService_FakeServer(t *testing.T) {
+	lccSrv := clienttest.NewFakeServer()
+	defer lccSrv.Close()
+	lccSrv.SetFeature(featureReports, true)
+	lccSrv.SetFeature(featureExport, false)
+	lccSrv.SetProductStatus(&client.FeatureStatus{Enabled: true, MaxConcurrency: 2, MaxCapacity: 10})
+	lccSrv.SetQuota(100, 0)
+
+	lcc, err := client.NewClient(lccSrv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer lcc.Close()
+	if err := lcc.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	srv, reports := newTestService(t, lcc)
+
+	do(t, "POST", srv.URL+"/reports", `{"title":"Q3","rows":["a"]}`)
+	if reports.Count() != 1 {
+		t.Fatalf("reports = %d, want 1", reports.Count())
+	}
+	if code, _ := do(t, "GET", srv.URL+"/reports/r1/export", ""); code != http.StatusForbidden {
+		t.Errorf("export without license = %d, want 403", code)
+	}
+
+	lccSrv.SetFeature(featureExport, true)
+	if code, body := do(t, "GET", srv.URL+"/reports/r1/export", ""); code != http.StatusOK {
+		t.Fatalf("export = %d %s", code, body)
+	}
+	if got := lccSrv.Usage(featureExport); got != 1 {
+		t.Errorf("server export usage = %d, want 1", got)
+	}
+
+	lccSrv.SetFeature(featureBatch, true)
+	if code, body := do(t, "POST", srv.URL+"/batch", `{"items":["x","y","z"]}`); code != http.StatusOK {
+		t.Fatalf("POST /batch = %d %s", code, body)
+	}
+	if got := lccSrv.ProductUsage(); got != 4 {
+		t.Errorf("server product usage = %d, want 4 (1 export + 3 items)", got)
+	}
+
+	_, metrics := do(t, "GET", srv.URL+"/metrics", "")
+	for _, want := range []string{
+		"lcc_client_registered 1",
+		`lcc_feature_checks_total{feature="report-export",outcome="denied"} 1`,
+		`lcc_feature_checks_total{feature="report-export",outcome="allowed"} 1`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics missing %q:\n%s", want, metrics)
+		}
+	}
+}
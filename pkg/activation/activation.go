@@ -0,0 +1,197 @@
+// Package activation implements offline (air-gapped) license activation.
+//
+// The flow has three steps:
+//
+//  1. The customer's instance writes an activation request file containing
+//     its fingerprint and public key (NewRequest, Request.WriteFile).
+//  2. The vendor signs an entitlement for that instance with its own key
+//     (Issue) and hands back an activation response file.
+//  3. The instance verifies the response against the vendor public key and
+//     persists it; later starts reload and re-verify the same file.
+package activation
+
+import (
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// Errors returned when verifying an activation response
+var (
+	ErrInvalidSignature = errors.New("activation response signature is invalid")
+	ErrInstanceMismatch = errors.New("activation response was issued for another instance")
+	ErrExpired          = errors.New("activation has expired")
+	ErrNotYetValid      = errors.New("activation is not yet valid")
+)
+
+// Request is an offline activation request produced by an instance
+type Request struct {
+	ProductID      string    `json:"product_id"`
+	ProductVersion string    `json:"product_version"`
+	Fingerprint    string    `json:"fingerprint"` // the instance ID
+	PublicKey      string    `json:"public_key"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewRequest creates an activation request for the instance identified by kp
+func NewRequest(kp auth.KeyPair, productID, productVersion string) (*Request, error) {
+	fingerprint, err := kp.GetFingerprint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fingerprint: %w", err)
+	}
+	publicKey, err := kp.GetPublicKeyPEM()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	return &Request{
+		ProductID:      productID,
+		ProductVersion: productVersion,
+		Fingerprint:    fingerprint,
+		PublicKey:      publicKey,
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// WriteFile writes the request as JSON
+func (r *Request) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode activation request: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadRequestFile reads an activation request written by Request.WriteFile
+func ReadRequestFile(path string) (*Request, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Request
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse activation request: %w", err)
+	}
+	return &r, nil
+}
+
+// Entitlement is the vendor's grant for a single instance
+type Entitlement struct {
+	InstanceID string    `json:"instance_id"`
+	ProductID  string    `json:"product_id"`
+	Features   []string  `json:"features"`
+	IssuedAt   time.Time `json:"issued_at"`
+	NotBefore  time.Time `json:"not_before,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+}
+
+// Allows reports whether featureID is granted
+func (e *Entitlement) Allows(featureID string) bool {
+	for _, f := range e.Features {
+		if f == featureID || f == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the entitlement's validity window at now
+func (e *Entitlement) Validate(now time.Time) error {
+	if !e.NotBefore.IsZero() && now.Before(e.NotBefore) {
+		return ErrNotYetValid
+	}
+	if !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Response is a vendor-signed activation response. The signature covers the
+// exact entitlement bytes, which are base64-encoded so the file survives
+// reformatting and can be persisted and re-verified as is.
+type Response struct {
+	Entitlement []byte `json:"entitlement"`
+	Signature   string `json:"signature"`
+}
+
+// Issue signs an entitlement for req with the vendor key. This is used by
+// vendor tooling; validFor <= 0 issues a perpetual activation.
+func Issue(req *Request, features []string, validFor time.Duration, vendor auth.KeyPair) (*Response, error) {
+	now := time.Now().UTC()
+	ent := Entitlement{
+		InstanceID: req.Fingerprint,
+		ProductID:  req.ProductID,
+		Features:   features,
+		IssuedAt:   now,
+		NotBefore:  now,
+	}
+	if validFor > 0 {
+		ent.ExpiresAt = now.Add(validFor)
+	}
+
+	payload, err := json.Marshal(ent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entitlement: %w", err)
+	}
+	signature, err := vendor.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Entitlement: payload,
+		Signature:   hex.EncodeToString(signature),
+	}, nil
+}
+
+// Verify checks the vendor signature and that the response was issued for
+// instanceID, and returns the entitlement. The validity window is not checked
+// here; see Entitlement.Validate.
+func (r *Response) Verify(vendorKey crypto.PublicKey, instanceID string) (*Entitlement, error) {
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := auth.VerifySignature(vendorKey, r.Entitlement, signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	var ent Entitlement
+	if err := json.Unmarshal(r.Entitlement, &ent); err != nil {
+		return nil, fmt.Errorf("failed to parse entitlement: %w", err)
+	}
+	if ent.InstanceID != instanceID {
+		return nil, ErrInstanceMismatch
+	}
+	return &ent, nil
+}
+
+// WriteFile persists the response as JSON with 0600 permissions
+func (r *Response) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode activation response: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadResponseFile reads an activation response file
+func ReadResponseFile(path string) (*Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Response
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse activation response: %w", err)
+	}
+	return &r, nil
+}
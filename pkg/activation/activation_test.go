@@ -0,0 +1,132 @@
+package activation
+
+import (
+	"crypto/rsa"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+func newVendor(t *testing.T) (auth.KeyPair, *rsa.PublicKey) {
+	t.Helper()
+	kp, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	pemStr, _ := kp.GetPublicKeyPEM()
+	pub, err := auth.ParsePublicKeyFromPEM([]byte(pemStr))
+	if err != nil {
+		t.Fatalf("ParsePublicKeyFromPEM() error = %v", err)
+	}
+	return kp, pub
+}
+
+func TestActivation_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	vendor, vendorKey := newVendor(t)
+	instance, _ := auth.GenerateKeyPair()
+	fp, _ := instance.GetFingerprint()
+
+	req, err := NewRequest(instance, "app", "1.0.0")
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	reqPath := filepath.Join(dir, "activation-request.json")
+	if err := req.WriteFile(reqPath); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// Vendor side
+	received, err := ReadRequestFile(reqPath)
+	if err != nil {
+		t.Fatalf("ReadRequestFile() error = %v", err)
+	}
+	resp, err := Issue(received, []string{"core", "reports"}, 24*time.Hour, vendor)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	respPath := filepath.Join(dir, "activation.json")
+	if err := resp.WriteFile(respPath); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// Instance side
+	loaded, err := ReadResponseFile(respPath)
+	if err != nil {
+		t.Fatalf("ReadResponseFile() error = %v", err)
+	}
+	ent, err := loaded.Verify(vendorKey, fp)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := ent.Validate(time.Now()); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if !ent.Allows("reports") || ent.Allows("export") {
+		t.Errorf("Allows() mismatch for features %v", ent.Features)
+	}
+}
+
+func TestResponse_Verify(t *testing.T) {
+	vendor, vendorKey := newVendor(t)
+	_, otherKey := newVendor(t)
+	instance, _ := auth.GenerateKeyPair()
+	fp, _ := instance.GetFingerprint()
+	req, _ := NewRequest(instance, "app", "1.0.0")
+
+	resp, err := Issue(req, []string{"*"}, 0, vendor)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	tampered := *resp
+	tampered.Entitlement = append([]byte{}, resp.Entitlement...)
+	tampered.Entitlement[len(tampered.Entitlement)-2] ^= 1
+
+	tests := []struct {
+		name       string
+		resp       *Response
+		key        *rsa.PublicKey
+		instanceID string
+		wantErr    error
+	}{
+		{"valid", resp, vendorKey, fp, nil},
+		{"wrong vendor key", resp, otherKey, fp, ErrInvalidSignature},
+		{"tampered entitlement", &tampered, vendorKey, fp, ErrInvalidSignature},
+		{"other instance", resp, vendorKey, "other", ErrInstanceMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.resp.Verify(tt.key, tt.instanceID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntitlement_Validate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		ent     Entitlement
+		wantErr error
+	}{
+		{"perpetual", Entitlement{}, nil},
+		{"within window", Entitlement{NotBefore: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}, nil},
+		{"expired", Entitlement{ExpiresAt: now.Add(-time.Second)}, ErrExpired},
+		{"not yet valid", Entitlement{NotBefore: now.Add(time.Hour)}, ErrNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ent.Validate(now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,8 +2,25 @@ package auth
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateKeyPair(t *testing.T) {
@@ -119,7 +136,7 @@ func TestParsePublicKeyFromPEM(t *testing.T) {
 	signature, _ := kp.Sign(data)
 
 	// Create temporary keypair with parsed public key for verification
-	tempKP := &KeyPair{publicKey: parsedKey}
+	tempKP := &RSAKeyPair{publicKey: parsedKey}
 	err = tempKP.Verify(data, signature)
 	if err != nil {
 		t.Errorf("Verify() with parsed key error = %v", err)
@@ -183,6 +200,372 @@ func TestRequestSigner_SignAndVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyRequest_GatewayPrefix(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		signPrefix string
+		serverPath string
+		header     string
+		opts       VerifyOptions
+		wantErr    bool
+	}{
+		{
+			name:       "client strips prefix, proxy strips prefix",
+			signPrefix: "/lcc",
+			serverPath: "/api/v1/sdk/register",
+		},
+		{
+			name:       "client strips prefix, proxy forwards full path",
+			signPrefix: "/lcc",
+			serverPath: "/lcc/api/v1/sdk/register",
+			opts:       VerifyOptions{Canonicalizer: PathCanonicalizer{StripPrefix: "/lcc"}},
+		},
+		{
+			name:       "client signs full path, proxy strips and forwards prefix",
+			serverPath: "/api/v1/sdk/register",
+			header:     "/lcc",
+			opts:       VerifyOptions{Canonicalizer: PathCanonicalizer{TrustForwardedPrefix: true}},
+		},
+		{
+			name:       "client signs full path, proxy strips without trust",
+			serverPath: "/api/v1/sdk/register",
+			header:     "/lcc",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewRequestSigner(kp, WithPathCanonicalizer(PathCanonicalizer{StripPrefix: tt.signPrefix}))
+
+			body := []byte(`{"product_id": "test"}`)
+			clientReq := httptest.NewRequest("POST", "/lcc/api/v1/sdk/register", bytes.NewReader(body))
+			if err := signer.SignRequest(clientReq); err != nil {
+				t.Fatalf("SignRequest() error = %v", err)
+			}
+
+			// Simulate the proxy forwarding to the server
+			serverReq := httptest.NewRequest("POST", tt.serverPath, bytes.NewReader(body))
+			serverReq.Header = clientReq.Header.Clone()
+			if tt.header != "" {
+				serverReq.Header.Set(HeaderForwardedPrefix, tt.header)
+			}
+
+			err := VerifyRequestWithOptions(serverReq, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyRequestWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStripPathPrefix(t *testing.T) {
+	tests := []struct {
+		path, prefix, want string
+	}{
+		{"/lcc/api/v1", "/lcc", "/api/v1"},
+		{"/lcc/api/v1", "/lcc/", "/api/v1"},
+		{"/lcc", "/lcc", "/"},
+		{"/lccx/api", "/lcc", "/lccx/api"},
+		{"/api/v1", "", "/api/v1"},
+	}
+
+	for _, tt := range tests {
+		if got := stripPathPrefix(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("stripPathPrefix(%q, %q) = %q, want %q", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestRequestSigner_SignRequestWithBodyHash(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	signer := NewRequestSigner(kp)
+
+	body := bytes.Repeat([]byte(`{"feature_id":"export","count":1}`), 1<<15)
+	bodyHash, err := ComputeBodyHashFromReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("ComputeBodyHashFromReader() error = %v", err)
+	}
+	if bodyHash != ComputeBodyHash(body) {
+		t.Fatal("streaming hash differs from ComputeBodyHash")
+	}
+
+	newReq := func(payload []byte) *http.Request {
+		req := httptest.NewRequest("POST", "/api/v1/sdk/usage/batch", bytes.NewReader(payload))
+		if err := signer.SignRequestWithBodyHash(req, bodyHash); err != nil {
+			t.Fatalf("SignRequestWithBodyHash() error = %v", err)
+		}
+		return req
+	}
+
+	// Buffered verification
+	if err := VerifyRequest(newReq(body)); err != nil {
+		t.Errorf("VerifyRequest() error = %v", err)
+	}
+
+	// Streaming verification succeeds once the body is fully read
+	req := newReq(body)
+	if err := VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true}); err != nil {
+		t.Fatalf("VerifyRequestWithOptions() error = %v", err)
+	}
+	if _, err := io.ReadAll(req.Body); err != nil {
+		t.Errorf("reading verified body error = %v", err)
+	}
+
+	// Streaming verification of a tampered body fails at EOF
+	tampered := append([]byte{}, body...)
+	tampered[10] = 'X'
+	req = newReq(tampered)
+	if err := VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true}); err != nil {
+		t.Fatalf("VerifyRequestWithOptions() error = %v", err)
+	}
+	if _, err := io.ReadAll(req.Body); !errors.Is(err, ErrBodyHashMismatch) {
+		t.Errorf("reading tampered body error = %v, want ErrBodyHashMismatch", err)
+	}
+
+	if err := signer.SignRequestWithBodyHash(httptest.NewRequest("POST", "/", nil), "abc"); err == nil {
+		t.Error("SignRequestWithBodyHash() should reject malformed hash")
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	fp, _ := kp.GetFingerprint()
+
+	newSignedReq := func(kp KeyPair) *http.Request {
+		req := httptest.NewRequest("POST", "/api/v1/test", bytes.NewReader([]byte(`{"a":1}`)))
+		if err := NewRequestSigner(kp).SignRequest(req); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+		return req
+	}
+
+	// Without a lookup any valid key is accepted and cached
+	v := NewVerifier()
+	for i := 0; i < 3; i++ {
+		if err := v.Verify(newSignedReq(kp)); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+	if n := v.cache.len(); n != 1 {
+		t.Errorf("cache len = %d, want 1", n)
+	}
+	if got, err := v.Fingerprint(newSignedReq(kp)); err != nil || got != fp {
+		t.Errorf("Fingerprint() = %q, %v; want %q", got, err, fp)
+	}
+
+	// With a lookup only registered keys are accepted
+	lookups := 0
+	v = NewVerifier(WithKeyLookup(KeyLookupFunc(func(f string) (crypto.PublicKey, error) {
+		lookups++
+		if f == fp {
+			return kp.publicKey, nil
+		}
+		return nil, ErrKeyNotRegistered
+	})))
+	for i := 0; i < 2; i++ {
+		if err := v.Verify(newSignedReq(kp)); err != nil {
+			t.Fatalf("Verify() registered key error = %v", err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("lookups = %d, want 1 (cached)", lookups)
+	}
+	if err := v.Verify(newSignedReq(other)); !errors.Is(err, ErrKeyNotRegistered) {
+		t.Errorf("Verify() unregistered key error = %v, want ErrKeyNotRegistered", err)
+	}
+
+	// Purge forces the next request to consult the lookup again
+	v.Purge(fp)
+	if err := v.Verify(newSignedReq(kp)); err != nil {
+		t.Fatalf("Verify() after purge error = %v", err)
+	}
+	if lookups != 3 {
+		t.Errorf("lookups = %d, want 3", lookups)
+	}
+
+	// A lookup returning a different key than the one presented fails
+	v = NewVerifier(WithKeyLookup(KeyLookupFunc(func(string) (crypto.PublicKey, error) {
+		return other.publicKey, nil
+	})))
+	if err := v.Verify(newSignedReq(kp)); err == nil {
+		t.Error("Verify() should fail when lookup returns a different key")
+	}
+}
+
+// issueTestCert creates a certificate for pub signed by parent/parentKey. A
+// nil parent creates a self-signed CA.
+func issueTestCert(t *testing.T, pub *rsa.PublicKey, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, []byte) {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "lcc-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestVerifier_TrustPolicy(t *testing.T) {
+	caKP, _ := GenerateKeyPair()
+	rogueKP, _ := GenerateKeyPair()
+	certified, _ := GenerateKeyPair()
+	registered, _ := GenerateKeyPair()
+	stranger, _ := GenerateKeyPair()
+
+	ca, _ := issueTestCert(t, caKP.publicKey, nil, caKP.privateKey)
+	rogue, _ := issueTestCert(t, rogueKP.publicKey, nil, rogueKP.privateKey)
+	_, certifiedPEM := issueTestCert(t, certified.publicKey, ca, caKP.privateKey)
+	_, rogueCertPEM := issueTestCert(t, stranger.publicKey, rogue, rogueKP.privateKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	registeredPEM, _ := registered.GetPublicKeyPEM()
+	allowlist, err := NewKeyAllowlist([]byte(registeredPEM))
+	if err != nil {
+		t.Fatalf("NewKeyAllowlist() error = %v", err)
+	}
+
+	v := NewVerifier(WithKeyLookup(allowlist), WithTrustedCAs(roots))
+
+	tests := []struct {
+		name    string
+		kp      KeyPair
+		opts    []SignerOption
+		wantErr error
+	}{
+		{"registered key", registered, nil, nil},
+		{"certified key", certified, []SignerOption{WithCertificate(certifiedPEM)}, nil},
+		{"self-signed key", stranger, nil, ErrKeyNotTrusted},
+		{"untrusted CA", stranger, []SignerOption{WithCertificate(rogueCertPEM)}, ErrKeyNotTrusted},
+		{"certificate for another key", stranger, []SignerOption{WithCertificate(certifiedPEM)}, ErrKeyNotTrusted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/test", bytes.NewReader([]byte(`{}`)))
+			if err := NewRequestSigner(tt.kp, tt.opts...).SignRequest(req); err != nil {
+				t.Fatalf("SignRequest() error = %v", err)
+			}
+			err := v.Verify(req)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifier_EntitlementToken(t *testing.T) {
+	kp, _ := GenerateKeyPair()
+	other, _ := GenerateKeyPair()
+	fp, _ := kp.GetFingerprint()
+	signer := NewRequestSigner(kp)
+
+	claims := EntitlementClaims{InstanceID: fp, ProductID: "app", FeatureID: "analytics"}
+	token, err := signer.IssueEntitlementToken(claims, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueEntitlementToken() error = %v", err)
+	}
+
+	kpPEM, _ := kp.GetPublicKeyPEM()
+	trusted, _ := NewKeyAllowlist([]byte(kpPEM))
+	v := NewVerifier(WithKeyLookup(trusted))
+
+	got, err := v.VerifyEntitlementToken(token)
+	if err != nil {
+		t.Fatalf("VerifyEntitlementToken() error = %v", err)
+	}
+	if got.InstanceID != fp || got.KeyFingerprint != fp || got.FeatureID != "analytics" {
+		t.Errorf("claims = %+v", got)
+	}
+
+	expired, _ := signer.IssueEntitlementToken(claims, -time.Minute)
+	// Signed by other but presenting kp's trusted key
+	forgedClaims := claims
+	forgedClaims.IssuedAt, forgedClaims.ExpiresAt = time.Now().Unix(), time.Now().Add(time.Minute).Unix()
+	forgedClaims.PublicKey = base64.StdEncoding.EncodeToString([]byte(kpPEM))
+	forged, _ := signToken(other, forgedClaims)
+	payload, sig, _ := strings.Cut(token, ".")
+	tampered := payload[:len(payload)-2] + "xx." + sig
+
+	otherPEM, _ := other.GetPublicKeyPEM()
+	allowlist, _ := NewKeyAllowlist([]byte(otherPEM))
+	both, _ := NewKeyAllowlist([]byte(kpPEM), []byte(otherPEM))
+
+	tests := []struct {
+		name    string
+		v       *Verifier
+		token   string
+		wantErr error
+	}{
+		{"expired", v, expired, ErrTokenExpired},
+		{"not signed by the presented key", NewVerifier(WithKeyLookup(both)), forged, ErrInvalidToken},
+		{"tampered payload", v, tampered, ErrInvalidToken},
+		{"malformed", v, "not-a-token", ErrInvalidToken},
+		{"untrusted key", NewVerifier(WithKeyLookup(allowlist)), token, ErrKeyNotRegistered},
+		{"no trust policy", NewVerifier(), token, ErrNoTrustPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.v.VerifyEntitlementToken(tt.token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyEntitlementToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyCache_Eviction(t *testing.T) {
+	c := newKeyCache(2)
+	k := &rsa.PublicKey{}
+	c.add("a", k, time.Time{})
+	c.add("b", k, time.Time{})
+	c.get("a") // "b" is now least recently used
+	c.add("c", k, time.Time{})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used key should be evicted")
+	}
+	for _, fp := range []string{"a", "c"} {
+		if _, ok := c.get(fp); !ok {
+			t.Errorf("key %q should be cached", fp)
+		}
+	}
+}
+
 func TestVerifyRequest_InvalidSignature(t *testing.T) {
 	kp, err := GenerateKeyPair()
 	if err != nil {
@@ -265,6 +648,179 @@ func TestKeyPair_Destroy(t *testing.T) {
 	}
 }
 
+func TestECDSAKeyPair_SignAndVerify(t *testing.T) {
+	kp, err := GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateECDSAKeyPair() error = %v", err)
+	}
+	if kp.Algorithm() != AlgorithmECDSAP256 {
+		t.Errorf("Algorithm() = %q, want %q", kp.Algorithm(), AlgorithmECDSAP256)
+	}
+
+	data := []byte("test data to sign")
+	signature, err := kp.Sign(data)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if err := kp.Verify(data, signature); err != nil {
+		t.Errorf("Verify() error = %v, want nil", err)
+	}
+	if err := kp.Verify([]byte("wrong data"), signature); err == nil {
+		t.Error("Verify() with wrong data should fail")
+	}
+
+	pubPEM, err := kp.GetPublicKeyPEM()
+	if err != nil {
+		t.Fatalf("GetPublicKeyPEM() error = %v", err)
+	}
+	if err := VerifySignatureWithPublicKey([]byte(pubPEM), data, signature); err != nil {
+		t.Errorf("VerifySignatureWithPublicKey() error = %v, want nil", err)
+	}
+	if _, err := ParsePublicKeyFromPEM([]byte(pubPEM)); err == nil {
+		t.Error("ParsePublicKeyFromPEM() should reject an ECDSA key")
+	}
+
+	fp, err := kp.GetFingerprint()
+	if err != nil {
+		t.Fatalf("GetFingerprint() error = %v", err)
+	}
+	v := NewVerifier()
+	req := httptest.NewRequest("POST", "/api/v1/sdk/register", strings.NewReader(`{}`))
+	if err := NewRequestSigner(kp).SignRequest(req); err != nil {
+		t.Fatalf("SignRequest() error = %v", err)
+	}
+	if got, _ := v.Fingerprint(req); got != fp {
+		t.Errorf("Fingerprint() = %s, want %s", got, fp)
+	}
+	if err := v.Verify(req); err != nil {
+		t.Errorf("Verifier.Verify() error = %v, want nil", err)
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	ecPKCS8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	rsaPKCS8, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p384DER, _ := x509.MarshalECPrivateKey(p384)
+
+	tests := []struct {
+		name    string
+		block   *pem.Block
+		want    string
+		wantErr bool
+	}{
+		{"PKCS#1 RSA", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, AlgorithmRSA, false},
+		{"SEC1 ECDSA", &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, AlgorithmECDSAP256, false},
+		{"PKCS#8 ECDSA", &pem.Block{Type: "PRIVATE KEY", Bytes: ecPKCS8}, AlgorithmECDSAP256, false},
+		{"PKCS#8 RSA", &pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}, AlgorithmRSA, false},
+		{"P-384", &pem.Block{Type: "EC PRIVATE KEY", Bytes: p384DER}, "", true},
+		{"wrong type", &pem.Block{Type: "PUBLIC KEY", Bytes: sec1}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kp, err := ParsePrivateKeyPEM(pem.EncodeToMemory(tt.block))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrivateKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if kp.Algorithm() != tt.want {
+				t.Errorf("Algorithm() = %q, want %q", kp.Algorithm(), tt.want)
+			}
+		})
+	}
+
+	// An exported key round-trips
+	kp, _ := NewECDSAKeyPair(ecKey)
+	keyPEM, err := kp.ExportPrivateKeyPEM()
+	if err != nil {
+		t.Fatalf("ExportPrivateKeyPEM() error = %v", err)
+	}
+	if !strings.Contains(keyPEM, "EC PRIVATE KEY") {
+		t.Errorf("ExportPrivateKeyPEM() = %q, want SEC1", keyPEM)
+	}
+	parsed, err := ParsePrivateKeyPEM([]byte(keyPEM))
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+	}
+	fp1, _ := kp.GetFingerprint()
+	fp2, _ := parsed.GetFingerprint()
+	if fp1 != fp2 {
+		t.Errorf("fingerprint after round trip = %s, want %s", fp2, fp1)
+	}
+}
+
+func TestGenerateKeyPairForAlgorithm(t *testing.T) {
+	for _, alg := range []string{"", AlgorithmRSA, AlgorithmECDSAP256} {
+		kp, err := GenerateKeyPairForAlgorithm(alg)
+		if err != nil {
+			t.Fatalf("GenerateKeyPairForAlgorithm(%q) error = %v", alg, err)
+		}
+		want := alg
+		if want == "" {
+			want = AlgorithmRSA
+		}
+		if kp.Algorithm() != want {
+			t.Errorf("GenerateKeyPairForAlgorithm(%q).Algorithm() = %q", alg, kp.Algorithm())
+		}
+	}
+	if _, err := GenerateKeyPairForAlgorithm("dsa"); err == nil {
+		t.Error("GenerateKeyPairForAlgorithm(dsa) should fail")
+	}
+}
+
+func TestFileKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "instance.pem")
+	ks := NewFileKeyStore(path)
+
+	if _, err := ks.Load(); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Load() before Save error = %v, want ErrNoKey", err)
+	}
+	kp, err := LoadOrCreateKeyPair(ks, AlgorithmECDSAP256)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKeyPair() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key file not saved: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file permissions = %o, want 600", perm)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("key directory holds %d files, want only the key", len(entries))
+	}
+
+	// The saved key is reused, whatever the requested algorithm
+	again, err := LoadOrCreateKeyPair(ks, AlgorithmRSA)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKeyPair() error = %v", err)
+	}
+	fp1, _ := kp.GetFingerprint()
+	fp2, _ := again.GetFingerprint()
+	if fp1 != fp2 {
+		t.Errorf("reloaded fingerprint = %s, want %s", fp2, fp1)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKeyPair(ks, ""); err == nil {
+		t.Error("LoadOrCreateKeyPair() with a corrupt key file should fail, not replace the key")
+	}
+}
+
 func BenchmarkGenerateKeyPair(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, err := GenerateKeyPair()
@@ -300,3 +856,775 @@ func BenchmarkVerify(b *testing.B) {
 		}
 	}
 }
+
+// memoryKeyProvider is a KeyProvider standing in for a secret manager
+type memoryKeyProvider struct {
+	pem  []byte
+	puts int
+}
+
+func (p *memoryKeyProvider) GetPrivateKeyPEM() ([]byte, error) {
+	if p.pem == nil {
+		return nil, ErrNoKey
+	}
+	return p.pem, nil
+}
+
+func (p *memoryKeyProvider) PutPrivateKeyPEM(pemData []byte) error {
+	p.pem = pemData
+	p.puts++
+	return nil
+}
+
+func TestProviderKeyStore(t *testing.T) {
+	p := &memoryKeyProvider{}
+	ks := NewProviderKeyStore(p)
+
+	kp, err := LoadOrCreateKeyPair(ks, AlgorithmECDSAP256)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKeyPair() error = %v", err)
+	}
+	again, err := LoadOrCreateKeyPair(ks, AlgorithmECDSAP256)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKeyPair() error = %v", err)
+	}
+	fp1, _ := kp.GetFingerprint()
+	fp2, _ := again.GetFingerprint()
+	if fp1 != fp2 || p.puts != 1 {
+		t.Errorf("reloaded fingerprint = %s after %d puts, want %s after 1", fp2, p.puts, fp1)
+	}
+}
+
+func TestEnvKeyStore(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, _ := kp.ExportPrivateKeyPEM()
+	want, _ := kp.GetFingerprint()
+	ks := NewEnvKeyStore("LCC_TEST_PRIVATE_KEY")
+
+	for name, value := range map[string]string{
+		"PEM":    keyPEM,
+		"base64": base64.StdEncoding.EncodeToString([]byte(keyPEM)),
+	} {
+		t.Setenv("LCC_TEST_PRIVATE_KEY", value)
+		loaded, err := ks.Load()
+		if err != nil {
+			t.Fatalf("Load() with %s error = %v", name, err)
+		}
+		if fp, _ := loaded.GetFingerprint(); fp != want {
+			t.Errorf("Load() with %s fingerprint = %s, want %s", name, fp, want)
+		}
+	}
+	if err := ks.Save(kp); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Save() error = %v, want ErrReadOnly", err)
+	}
+
+	// Without the variable there is no key, and none can be created
+	t.Setenv("LCC_TEST_PRIVATE_KEY", "")
+	if _, err := ks.Load(); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load() without the variable error = %v, want ErrNoKey", err)
+	}
+	if _, err := LoadOrCreateKeyPair(ks, ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("LoadOrCreateKeyPair() error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestSignerKeyPair(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		signer    crypto.Signer
+		algorithm string
+	}{
+		{rsaKey, AlgorithmRSA},
+		{ecKey, AlgorithmECDSAP256},
+	} {
+		signer, err := NewRequestSignerFromSigner(tt.signer)
+		if err != nil {
+			t.Fatalf("NewRequestSignerFromSigner(%s) error = %v", tt.algorithm, err)
+		}
+		kp := signer.keyPair
+		if kp.Algorithm() != tt.algorithm {
+			t.Errorf("Algorithm() = %s, want %s", kp.Algorithm(), tt.algorithm)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/sdk/register", strings.NewReader(`{"product_id":"app"}`))
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyRequest(req); err != nil {
+			t.Errorf("%s: VerifyRequest() error = %v", tt.algorithm, err)
+		}
+		if _, err := kp.ExportPrivateKeyPEM(); !errors.Is(err, ErrKeyNotExportable) {
+			t.Errorf("ExportPrivateKeyPEM() error = %v, want ErrKeyNotExportable", err)
+		}
+	}
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := NewSignerKeyPair(p384); err == nil {
+		t.Error("NewSignerKeyPair() accepted a P-384 key")
+	}
+}
+
+// softPKCS11Session emulates a PKCS#11 token with a software key
+type softPKCS11Session struct {
+	key        crypto.PrivateKey
+	mechanisms []uint
+}
+
+func (s *softPKCS11Session) Sign(mechanism uint, data []byte) ([]byte, error) {
+	s.mechanisms = append(s.mechanisms, mechanism)
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		// Hash 0 signs data as given: the DigestInfo is already prepended
+		return rsa.SignPKCS1v15(rand.Reader, key, 0, data)
+	case *ecdsa.PrivateKey:
+		r, sig, err := ecdsa.Sign(rand.Reader, key, data)
+		if err != nil {
+			return nil, err
+		}
+		raw := make([]byte, 64)
+		r.FillBytes(raw[:32])
+		sig.FillBytes(raw[32:])
+		return raw, nil
+	}
+	return nil, errors.New("unsupported key")
+}
+
+func TestPKCS11Signer(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for _, tt := range []struct {
+		key       crypto.PrivateKey
+		public    crypto.PublicKey
+		mechanism uint
+	}{
+		{rsaKey, &rsaKey.PublicKey, MechanismRSAPKCS},
+		{ecKey, &ecKey.PublicKey, MechanismECDSA},
+	} {
+		session := &softPKCS11Session{key: tt.key}
+		signer, err := NewPKCS11Signer(session, tt.public)
+		if err != nil {
+			t.Fatalf("NewPKCS11Signer() error = %v", err)
+		}
+		kp, err := NewSignerKeyPair(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := kp.Sign([]byte("data"))
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		if err := VerifySignature(tt.public, []byte("data"), signature); err != nil {
+			t.Errorf("mechanism %#x: VerifySignature() error = %v", tt.mechanism, err)
+		}
+		if len(session.mechanisms) != 1 || session.mechanisms[0] != tt.mechanism {
+			t.Errorf("mechanisms = %v, want [%#x]", session.mechanisms, tt.mechanism)
+		}
+		if _, err := signer.Sign(nil, make([]byte, 32), &rsa.PSSOptions{Hash: crypto.SHA256}); err == nil {
+			t.Error("Sign() accepted PSS options")
+		}
+	}
+}
+
+func TestWithNonceSource(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewRequestSigner(kp, WithNonceSource(NewSequentialGenerator("test")))
+
+	for _, want := range []string{"test-1", "test-2"} {
+		req := httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("X-LCC-Nonce"); got != want {
+			t.Errorf("nonce = %q, want %q", got, want)
+		}
+		if err := VerifyRequest(req); err != nil {
+			t.Errorf("VerifyRequest: %v", err)
+		}
+	}
+
+	failing := NewRequestSigner(kp, WithNonceSource(func() (string, error) {
+		return "", errors.New("no entropy")
+	}))
+	req := httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+	if err := failing.SignRequest(req); err == nil {
+		t.Error("SignRequest succeeded without a nonce")
+	}
+}
+
+func TestNewUUIDGenerator(t *testing.T) {
+	gen := NewUUIDGenerator(bytes.NewReader(bytes.Repeat([]byte{0xab}, 32)))
+	first, err := gen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "abababab-abab-4bab-abab-abababababab" {
+		t.Errorf("first ID = %s", first)
+	}
+	if _, err := gen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gen(); err == nil {
+		t.Error("expected an error once the reader is exhausted")
+	}
+}
+
+func TestSaltedInstanceID(t *testing.T) {
+	kp, err := GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, err := SaltedInstanceID("customer-a")(kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, _ := SaltedInstanceID("customer-a")(kp)
+	b, _ := SaltedInstanceID("customer-b")(kp)
+	fp, _ := FingerprintInstanceID(kp)
+
+	if a1 != a2 {
+		t.Errorf("salted ID not stable: %s != %s", a1, a2)
+	}
+	if a1 == b || a1 == fp {
+		t.Errorf("salted ID %s should differ from %s and the fingerprint %s", a1, b, fp)
+	}
+	if len(a1) != 64 {
+		t.Errorf("salted ID length = %d, want 64", len(a1))
+	}
+}
+
+func TestVerifyResponse(t *testing.T) {
+	server, err := GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, _ := server.GetPublicKeyPEM()
+	pub, err := ParsePublicKeyPEM([]byte(pubPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := []byte(`{"feature_id":"export","enabled":true}`)
+	response := func(nonce string, status int, signature string) *http.Response {
+		req := httptest.NewRequest("GET", "/api/v1/sdk/features/export/check", nil)
+		if nonce != "" {
+			req.Header.Set("X-LCC-Nonce", nonce)
+		}
+		resp := &http.Response{StatusCode: status, Header: http.Header{}, Request: req}
+		if signature != "" {
+			resp.Header.Set(HeaderServerSignature, signature)
+		}
+		return resp
+	}
+	sign := func(kp KeyPair, nonce string, status int) string {
+		signature, err := SignResponse(kp, nonce, status, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+
+	if err := VerifyResponse(pub, response("n1", 200, sign(server, "n1", 200)), body); err != nil {
+		t.Errorf("VerifyResponse() with a valid signature error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		resp *http.Response
+		body []byte
+	}{
+		{"unsigned", response("n1", 200, ""), body},
+		{"unsigned request", response("", 200, sign(server, "", 200)), body},
+		{"other key", response("n1", 200, sign(other, "n1", 200)), body},
+		{"replayed to another request", response("n2", 200, sign(server, "n1", 200)), body},
+		{"status changed", response("n1", 200, sign(server, "n1", 402)), body},
+		{"body changed", response("n1", 200, sign(server, "n1", 200)), []byte(`{"feature_id":"export","enabled":false}`)},
+		{"not hex", response("n1", 200, "zz"), body},
+	}
+	for _, tt := range tests {
+		if err := VerifyResponse(pub, tt.resp, tt.body); !errors.Is(err, ErrResponseSignature) {
+			t.Errorf("VerifyResponse() %s error = %v, want ErrResponseSignature", tt.name, err)
+		}
+	}
+}
+
+// fixedClock is a Clock stopped at a given time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestOffsetClock(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewOffsetClock(fixedClock(base))
+	if got := clock.Now(); !got.Equal(base) {
+		t.Errorf("Now() = %v, want %v", got, base)
+	}
+
+	// The server is 90 minutes ahead
+	offset, ok := clock.SyncDate(base.Add(90 * time.Minute).Format(http.TimeFormat))
+	if !ok || offset != 90*time.Minute {
+		t.Errorf("SyncDate() = %v, %v, want 1h30m0s", offset, ok)
+	}
+	if got := clock.Now(); !got.Equal(base.Add(90 * time.Minute)) {
+		t.Errorf("Now() after sync = %v", got)
+	}
+
+	// In step with the server: no offset
+	if offset, _ := clock.SyncDate(base.Format(http.TimeFormat)); offset != 0 {
+		t.Errorf("SyncDate() of the local time = %v, want 0", offset)
+	}
+	if _, ok := clock.SyncDate("yesterday"); ok {
+		t.Error("SyncDate() accepted an invalid date")
+	}
+
+	clock.SetOffset(-time.Hour)
+	if got := clock.Offset(); got != -time.Hour {
+		t.Errorf("Offset() = %v, want -1h", got)
+	}
+}
+
+func TestVerifyRequestWithOptions_ClockSkew(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		skew    time.Duration // signer clock minus verifier clock
+		opts    VerifyOptions
+		wantErr bool
+	}{
+		{"in sync", 0, VerifyOptions{}, false},
+		{"10m behind", -10 * time.Minute, VerifyOptions{}, true},
+		{"10m behind, max age 15m", -10 * time.Minute, VerifyOptions{MaxAge: 15 * time.Minute}, false},
+		{"2m ahead", 2 * time.Minute, VerifyOptions{}, true},
+		{"2m ahead, max future skew 5m", 2 * time.Minute, VerifyOptions{MaxFutureSkew: 5 * time.Minute}, false},
+	}
+	for _, tt := range tests {
+		signer := NewRequestSigner(kp, WithClock(fixedClock(now.Add(tt.skew))))
+		req := httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		tt.opts.Clock = fixedClock(now)
+		if err := VerifyRequestWithOptions(req, tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: VerifyRequestWithOptions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"b=2&a=1", "a=1&b=2"},
+		{"a=2&a=1&a=10", "a=1&a=10&a=2"},
+		{"q=a+b&x=%2F", "q=a+b&x=%2F"},
+		{"flag", "flag="},
+	}
+	for _, tt := range tests {
+		got, err := CanonicalQuery(tt.raw)
+		if err != nil {
+			t.Errorf("CanonicalQuery(%q) error = %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CanonicalQuery(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+	if _, err := CanonicalQuery("a=%zz"); err == nil {
+		t.Error("CanonicalQuery() accepted an invalid escape")
+	}
+}
+
+func TestSignatureV2(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := NewRequestSigner(kp, WithSignatureVersion(SignatureV2), WithSignedHeaders("X-Tenant"))
+
+	signed := func(signer *RequestSigner) *http.Request {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/v1/sdk/usage?b=2&a=1", strings.NewReader(`{"n":1}`))
+		req.Header.Set("X-Tenant", "acme")
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	req := signed(v2)
+	if got := req.Header.Get(HeaderSigVersion); got != "2" {
+		t.Errorf("%s = %q, want 2", HeaderSigVersion, got)
+	}
+	if got := req.Header.Get(HeaderSignedHeaders); got != "content-type;x-tenant" {
+		t.Errorf("%s = %q, want content-type;x-tenant", HeaderSignedHeaders, got)
+	}
+	if err := VerifyRequest(req); err != nil {
+		t.Errorf("VerifyRequest() error = %v", err)
+	}
+
+	tamper := map[string]func(*http.Request){
+		"query":         func(r *http.Request) { r.URL.RawQuery = "a=1&b=3" },
+		"added param":   func(r *http.Request) { r.URL.RawQuery += "&c=1" },
+		"signed header": func(r *http.Request) { r.Header.Set("X-Tenant", "other") },
+		"content type":  func(r *http.Request) { r.Header.Set("Content-Type", "text/plain") },
+		"header list":   func(r *http.Request) { r.Header.Set(HeaderSignedHeaders, "content-type") },
+		"downgrade":     func(r *http.Request) { r.Header.Del(HeaderSigVersion) },
+		"bad version":   func(r *http.Request) { r.Header.Set(HeaderSigVersion, "3") },
+	}
+	for name, fn := range tamper {
+		req := signed(v2)
+		fn(req)
+		if err := VerifyRequest(req); err == nil {
+			t.Errorf("%s: VerifyRequest() accepted a tampered request", name)
+		}
+	}
+
+	// Reordering the query and unsigned headers keep the signature valid
+	req = signed(v2)
+	req.URL.RawQuery = "a=1&b=2"
+	req.Header.Set("User-Agent", "proxy")
+	if err := VerifyRequest(req); err != nil {
+		t.Errorf("VerifyRequest() after reordering the query: %v", err)
+	}
+
+	// v1 signatures still verify, unless the verifier requires v2
+	v1 := signed(NewRequestSigner(kp))
+	if v1.Header.Get(HeaderSigVersion) != "" {
+		t.Errorf("v1 request carries %s", HeaderSigVersion)
+	}
+	if err := VerifyRequest(v1); err != nil {
+		t.Errorf("VerifyRequest(v1) error = %v", err)
+	}
+	v1.URL.RawQuery = "tampered=1"
+	if err := VerifyRequest(v1); err != nil {
+		t.Errorf("v1 signatures do not cover the query: %v", err)
+	}
+	opts := VerifyOptions{MinSignatureVersion: SignatureV2}
+	if err := VerifyRequestWithOptions(v1, opts); err == nil {
+		t.Error("VerifyRequestWithOptions() accepted v1 with MinSignatureVersion 2")
+	}
+	if err := VerifyRequestWithOptions(signed(v2), opts); err != nil {
+		t.Errorf("VerifyRequestWithOptions(v2) error = %v", err)
+	}
+}
+
+func TestSignatureSchemePSS(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSigner, err := NewRequestSignerFromSigner(rsaKey, WithSignatureScheme(SchemeRSAPSS))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, signer := range map[string]*RequestSigner{
+		"key pair": NewRequestSigner(kp, WithSignatureScheme(SchemeRSAPSS)),
+		"signer":   fromSigner,
+	} {
+		req := httptest.NewRequest("POST", "/api/v1/sdk/usage", strings.NewReader(`{"n":1}`))
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatalf("%s: SignRequest() error = %v", name, err)
+		}
+		if got := req.Header.Get(HeaderSigScheme); got != SchemeRSAPSS {
+			t.Errorf("%s: %s = %q, want %q", name, HeaderSigScheme, got, SchemeRSAPSS)
+		}
+		if err := VerifyRequestWithOptions(req, VerifyOptions{RequirePSS: true}); err != nil {
+			t.Errorf("%s: VerifyRequestWithOptions() error = %v", name, err)
+		}
+
+		// A PSS signature does not verify as PKCS#1 v1.5
+		req.Header.Del(HeaderSigScheme)
+		if err := VerifyRequest(req); err == nil {
+			t.Errorf("%s: VerifyRequest() accepted a PSS signature as PKCS#1 v1.5", name)
+		}
+	}
+
+	// PKCS#1 v1.5 stays the default and verifies, unless PSS is required
+	req := httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+	if err := NewRequestSigner(kp).SignRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderSigScheme) != "" {
+		t.Errorf("PKCS#1 v1.5 request carries %s", HeaderSigScheme)
+	}
+	if err := VerifyRequest(req); err != nil {
+		t.Errorf("VerifyRequest() error = %v", err)
+	}
+	if err := VerifyRequestWithOptions(req, VerifyOptions{RequirePSS: true}); err == nil {
+		t.Error("VerifyRequestWithOptions() accepted PKCS#1 v1.5 with RequirePSS")
+	}
+	req.Header.Set(HeaderSigScheme, SchemeRSAPKCS1v15)
+	if err := VerifyRequest(req); err != nil {
+		t.Errorf("VerifyRequest() with explicit %s error = %v", SchemeRSAPKCS1v15, err)
+	}
+	req.Header.Set(HeaderSigScheme, "rsa-md5")
+	if err := VerifyRequest(req); err == nil {
+		t.Error("VerifyRequest() accepted an unknown signature scheme")
+	}
+
+	// ECDSA keys have no PSS; their requests verify even with RequirePSS
+	ec, err := GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+	if err := NewRequestSigner(ec, WithSignatureScheme(SchemeRSAPSS)).SignRequest(req); err == nil {
+		t.Error("SignRequest() signed RSA-PSS with an ECDSA key")
+	}
+	if err := NewRequestSigner(ec).SignRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRequestWithOptions(req, VerifyOptions{RequirePSS: true}); err != nil {
+		t.Errorf("VerifyRequestWithOptions(ECDSA) error = %v", err)
+	}
+
+	// VerifySignatureWithScheme is the scheme-aware VerifySignature
+	sig, err := kp.SignPSS([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignatureWithScheme(&rsaKey.PublicKey, SchemeRSAPSS, []byte("data"), sig); err == nil {
+		t.Error("VerifySignatureWithScheme() accepted the wrong key")
+	}
+	pub, _ := kp.GetPublicKeyPEM()
+	parsed, err := ParsePublicKeyPEM([]byte(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignatureWithScheme(parsed, SchemeRSAPSS, []byte("data"), sig); err != nil {
+		t.Errorf("VerifySignatureWithScheme() error = %v", err)
+	}
+}
+
+func TestHashAlgorithms(t *testing.T) {
+	rsaKP, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKP, err := GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaToken, _ := NewPKCS11Signer(&softPKCS11Session{key: rsaKey}, &rsaKey.PublicKey)
+	ecToken, _ := NewPKCS11Signer(&softPKCS11Session{key: ecKey}, &ecKey.PublicKey)
+	rsaHSM, err := NewSignerKeyPair(rsaToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecHSM, err := NewSignerKeyPair(ecToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		kp   KeyPair
+		opts []SignerOption
+	}{
+		{"rsa", rsaKP, nil},
+		{"rsa-pss", rsaKP, []SignerOption{WithSignatureScheme(SchemeRSAPSS)}},
+		{"ecdsa", ecKP, nil},
+		{"pkcs11-rsa", rsaHSM, nil},
+		{"pkcs11-ecdsa", ecHSM, nil},
+	} {
+		for _, algorithm := range []string{HashSHA384, HashSHA512} {
+			signer := NewRequestSigner(tt.kp, append(tt.opts, WithHashAlgorithm(algorithm))...)
+			req := httptest.NewRequest("POST", "/api/v1/sdk/usage", strings.NewReader(`{"n":1}`))
+			if err := signer.SignRequest(req); err != nil {
+				t.Fatalf("%s/%s: SignRequest() error = %v", tt.name, algorithm, err)
+			}
+			if got := req.Header.Get(HeaderHash); got != algorithm {
+				t.Errorf("%s/%s: %s = %q", tt.name, algorithm, HeaderHash, got)
+			}
+			if err := VerifyRequest(req); err != nil {
+				t.Errorf("%s/%s: VerifyRequest() error = %v", tt.name, algorithm, err)
+			}
+			if d := DiagnoseRequest(req, nil, VerifyOptions{}); !d.OK() {
+				t.Errorf("%s/%s: DiagnoseRequest() failed at %+v", tt.name, algorithm, d.Failed())
+			}
+
+			// Announcing another algorithm breaks the signature
+			req.Header.Set(HeaderHash, HashSHA256)
+			if err := VerifyRequest(req); err == nil {
+				t.Errorf("%s/%s: VerifyRequest() accepted a downgraded hash", tt.name, algorithm)
+			}
+		}
+	}
+
+	// SHA-256 stays the default and announces nothing
+	req := httptest.NewRequest("GET", "/api/v1/sdk/product/status", nil)
+	if err := NewRequestSigner(rsaKP).SignRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderHash) != "" {
+		t.Errorf("SHA-256 request carries %s", HeaderHash)
+	}
+	opts := VerifyOptions{HashAlgorithms: []string{HashSHA512}}
+	if err := VerifyRequestWithOptions(req, opts); !errors.Is(err, ErrHashNotAccepted) {
+		t.Errorf("VerifyRequestWithOptions() error = %v, want ErrHashNotAccepted", err)
+	}
+	if d := DiagnoseRequest(req, nil, opts); d.Failed() == nil || d.Failed().Name != StepBodyHash {
+		t.Errorf("DiagnoseRequest() failed at %+v, want %s", d.Failed(), StepBodyHash)
+	}
+	h := http.Header{}
+	SetAcceptHash(h, opts)
+	if got := h.Get(HeaderAcceptHash); got != HashSHA512 {
+		t.Errorf("%s = %q, want %q", HeaderAcceptHash, got, HashSHA512)
+	}
+
+	// Declared body hashes of other algorithms are streamed too
+	signer := NewRequestSigner(rsaKP, WithHashAlgorithm(HashSHA512))
+	body := []byte(`{"feature_id":"export","count":1}`)
+	bodyHash, err := signer.BodyHash(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.SignRequestWithBodyHash(httptest.NewRequest("POST", "/", nil), ComputeBodyHash(body)); err == nil {
+		t.Error("SignRequestWithBodyHash() accepted a SHA-256 hash for SHA-512")
+	}
+	req = httptest.NewRequest("POST", "/api/v1/sdk/usage/batch", bytes.NewReader([]byte(`{"tampered":1}`)))
+	if err := signer.SignRequestWithBodyHash(req, bodyHash); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderContentHash) != bodyHash || req.Header.Get(HeaderContentSHA256) != "" {
+		t.Errorf("declared hash headers = %v", req.Header)
+	}
+	if err := VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(req.Body); !errors.Is(err, ErrBodyHashMismatch) {
+		t.Errorf("reading tampered body error = %v, want ErrBodyHashMismatch", err)
+	}
+
+	// Fingerprints and instance IDs
+	fp, _ := rsaKP.GetFingerprint()
+	if got, _ := Fingerprint(rsaKP, HashSHA256); got != fp {
+		t.Errorf("Fingerprint(sha256) = %s, want GetFingerprint() %s", got, fp)
+	}
+	if got, _ := FingerprintInstanceIDWithHash(HashSHA384)(rsaKP); len(got) != 96 {
+		t.Errorf("SHA-384 instance ID = %q", got)
+	}
+	salted, _ := SaltedInstanceID("acme")(rsaKP)
+	if got, _ := SaltedInstanceIDWithHash("acme", HashSHA256)(rsaKP); got != salted {
+		t.Errorf("SaltedInstanceIDWithHash(sha256) = %s, want %s", got, salted)
+	}
+	if _, err := Fingerprint(rsaKP, "md5"); err == nil {
+		t.Error("Fingerprint() accepted md5")
+	}
+
+	if got := NegotiateHash([]string{HashSHA384, HashSHA256}, "SHA512, sha256"); got != HashSHA256 {
+		t.Errorf("NegotiateHash() = %q, want %q", got, HashSHA256)
+	}
+	if got := NegotiateHash([]string{HashSHA384}, "sha256"); got != "" {
+		t.Errorf("NegotiateHash() = %q, want none", got)
+	}
+}
+
+func TestDiagnoseRequest(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, _ := kp.GetPublicKeyPEM()
+	otherPEM, _ := other.GetPublicKeyPEM()
+	now := time.Now()
+
+	signed := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/api/v1/sdk/usage", strings.NewReader(body))
+		if err := NewRequestSigner(kp, WithClock(fixedClock(now))).SignRequestWithBodyHash(req, ComputeBodyHash([]byte(body))); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		req        func() *http.Request
+		key        string
+		opts       VerifyOptions
+		failedStep string
+		detail     string
+	}{
+		{"valid", func() *http.Request { return signed(`{"count":1}`) }, pubPEM, VerifyOptions{}, "", ""},
+		{"missing headers", func() *http.Request {
+			req := signed(`{}`)
+			req.Header.Del("X-LCC-Nonce")
+			return req
+		}, pubPEM, VerifyOptions{}, StepHeaders, "X-LCC-Nonce"},
+		{"clock skew", func() *http.Request { return signed(`{}`) }, pubPEM, VerifyOptions{Clock: fixedClock(now.Add(10 * time.Minute))}, StepTimestamp, "skew 10m0s"},
+		{"modified body", func() *http.Request {
+			req := signed(`{"count":1}`)
+			req.Body = io.NopCloser(strings.NewReader(`{"count":9}`))
+			return req
+		}, pubPEM, VerifyOptions{}, StepBodyHash, "modified after signing"},
+		{"unexpected key", func() *http.Request { return signed(`{}`) }, otherPEM, VerifyOptions{}, StepPublicKey, "expected"},
+		{"rewritten path", func() *http.Request {
+			req := signed(`{}`)
+			req.URL.Path = "/api/v1/sdk/heartbeat"
+			return req
+		}, "", VerifyOptions{}, StepSignature, "does not match"},
+	}
+	for _, tt := range tests {
+		var key []byte
+		if tt.key != "" {
+			key = []byte(tt.key)
+		}
+		d := DiagnoseRequest(tt.req(), key, tt.opts)
+		failed := d.Failed()
+		switch {
+		case tt.failedStep == "" && failed != nil:
+			t.Errorf("%s: step %s failed: %s", tt.name, failed.Name, failed.Detail)
+		case tt.failedStep != "" && (failed == nil || failed.Name != tt.failedStep || !strings.Contains(failed.Detail, tt.detail)):
+			t.Errorf("%s: Failed() = %+v, want step %s mentioning %q", tt.name, failed, tt.failedStep, tt.detail)
+		}
+		if d.OK() != (tt.failedStep == "") {
+			t.Errorf("%s: OK() = %v", tt.name, d.OK())
+		}
+	}
+
+	// The signature still matches the declared hash of a modified body
+	req := signed(`{"count":1}`)
+	req.Body = io.NopCloser(strings.NewReader(`{"count":9}`))
+	d := DiagnoseRequest(req, nil, VerifyOptions{})
+	if last := d.Steps[len(d.Steps)-1]; last.Name != StepSignature || !strings.Contains(last.Detail, "declared body hash") {
+		t.Errorf("signature step = %+v", last)
+	}
+	if len(d.Canonical) != 1 || d.Fingerprint == "" {
+		t.Errorf("Canonical = %q, Fingerprint = %q", d.Canonical, d.Fingerprint)
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderForwardedPrefix is the header reverse proxies use to announce a path
+// prefix they removed before forwarding the request.
+const HeaderForwardedPrefix = "X-Forwarded-Prefix"
+
+// PathCanonicalizer controls which request path is covered by the signature.
+// It lets signatures survive reverse proxies and API gateways that rewrite
+// paths (e.g., a gateway exposing LCC under /lcc and stripping that prefix).
+//
+// The canonical path is the path with the gateway prefix removed.
+type PathCanonicalizer struct {
+	// StripPrefix is removed from the request path before signing or
+	// verifying (e.g., "/lcc"). Matching is on whole path segments.
+	StripPrefix string
+
+	// TrustForwardedPrefix makes the verifier also accept signatures over
+	// X-Forwarded-Prefix + path, for clients that signed the full external
+	// path. Enable only behind a proxy that sets or strips this header.
+	TrustForwardedPrefix bool
+}
+
+// SignPath returns the path the signer covers for req
+func (p PathCanonicalizer) SignPath(req *http.Request) string {
+	return stripPathPrefix(req.URL.Path, p.StripPrefix)
+}
+
+// VerifyPaths returns the candidate paths a verifier accepts for req, most
+// specific first. A signature is valid if it matches any of them.
+func (p PathCanonicalizer) VerifyPaths(req *http.Request) []string {
+	path := stripPathPrefix(req.URL.Path, p.StripPrefix)
+	paths := []string{path}
+
+	if p.TrustForwardedPrefix {
+		if prefix := strings.TrimRight(req.Header.Get(HeaderForwardedPrefix), "/"); prefix != "" {
+			paths = append(paths, prefix+path)
+		}
+	}
+
+	return paths
+}
+
+// stripPathPrefix removes prefix from path if it matches on a segment boundary.
+// The result always starts with "/".
+func stripPathPrefix(path, prefix string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" || !strings.HasPrefix(path, prefix) {
+		return path
+	}
+
+	rest := path[len(prefix):]
+	if rest == "" {
+		return "/"
+	}
+	if rest[0] != '/' {
+		// "/lccx/..." does not match prefix "/lcc"
+		return path
+	}
+	return rest
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Default timestamp tolerances of request verification (see VerifyOptions)
+const (
+	DefaultMaxAge        = 5 * time.Minute
+	DefaultMaxFutureSkew = time.Minute
+)
+
+// Clock is the time source of request signing and verification
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the local clock, the default
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// WithClock sets the time source of request timestamps (default:
+// SystemClock), e.g. an OffsetClock corrected from the server's time
+func WithClock(clock Clock) SignerOption {
+	return func(s *RequestSigner) {
+		s.clock = clock
+	}
+}
+
+// now returns the signer's current time
+func (s *RequestSigner) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// OffsetClock adds an offset to another clock, so a host whose clock is
+// badly skewed can sign with the server's time. It is safe for concurrent
+// use.
+type OffsetClock struct {
+	base   Clock
+	offset atomic.Int64 // nanoseconds
+}
+
+// NewOffsetClock returns a clock running offset-free on base (nil uses
+// SystemClock) until SetOffset or SyncDate
+func NewOffsetClock(base Clock) *OffsetClock {
+	if base == nil {
+		base = SystemClock
+	}
+	return &OffsetClock{base: base}
+}
+
+// Now returns the base time plus the offset
+func (c *OffsetClock) Now() time.Time {
+	return c.base.Now().Add(c.Offset())
+}
+
+// Offset returns the current offset
+func (c *OffsetClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// SetOffset sets the offset added to the base time
+func (c *OffsetClock) SetOffset(d time.Duration) {
+	c.offset.Store(int64(d))
+}
+
+// SyncDate sets the offset from an HTTP Date header value, the server's
+// time when it answered. The header has a resolution of one second, so it
+// is compared with the base time truncated to the second: the offset is in
+// whole seconds, and a clock in step with the server gets none. It reports
+// false if the header does not parse.
+func (c *OffsetClock) SyncDate(header string) (time.Duration, bool) {
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return c.Offset(), false
+	}
+	offset := date.Sub(c.base.Now().Truncate(time.Second))
+	c.SetOffset(offset)
+	return offset, true
+}
@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Steps of request verification reported by DiagnoseRequest, in order
+const (
+	StepHeaders   = "headers"
+	StepTimestamp = "timestamp"
+	StepBodyHash  = "body_hash"
+	StepPublicKey = "public_key"
+	StepSignature = "signature"
+)
+
+// StepResult is the outcome of a DiagnosticStep
+type StepResult string
+
+// Step results
+const (
+	StepOK      StepResult = "ok"
+	StepFailed  StepResult = "failed"
+	StepSkipped StepResult = "skipped" // an earlier step lacked the input
+)
+
+// DiagnosticStep is one verification step of a Diagnosis
+type DiagnosticStep struct {
+	Name   string
+	Result StepResult
+	Detail string
+}
+
+// Diagnosis reports every step of verifying a signed request, where
+// VerifyRequest stops at the first failure with a terse error
+type Diagnosis struct {
+	Steps []DiagnosticStep
+
+	// Fingerprint is the fingerprint of the key presented in
+	// X-LCC-PublicKey
+	Fingerprint string
+
+	// Skew is the verifier's clock minus the request timestamp
+	Skew time.Duration
+
+	// Canonical holds the canonical strings rebuilt by the verifier, one
+	// per accepted path
+	Canonical []string
+}
+
+// OK reports whether the request passes verification
+func (d *Diagnosis) OK() bool {
+	for _, s := range d.Steps {
+		if s.Result != StepOK {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the first step that did not pass, or nil
+func (d *Diagnosis) Failed() *DiagnosticStep {
+	for i := range d.Steps {
+		if d.Steps[i].Result != StepOK {
+			return &d.Steps[i]
+		}
+	}
+	return nil
+}
+
+func (d *Diagnosis) add(name string, result StepResult, format string, args ...interface{}) {
+	d.Steps = append(d.Steps, DiagnosticStep{Name: name, Result: result, Detail: fmt.Sprintf(format, args...)})
+}
+
+// DiagnoseRequest verifies req step by step and reports the outcome of
+// each step, so an opaque 401 can be traced to missing headers, clock
+// skew, a modified body, an unexpected key or a differing canonical
+// string. Steps after a failure still run when they have their input.
+//
+// publicKeyPEM is the key the client is expected to sign with; nil trusts
+// the key presented in the request. The body is always buffered
+// (opts.StreamBody is ignored) and restored afterwards.
+func DiagnoseRequest(req *http.Request, publicKeyPEM []byte, opts VerifyOptions) *Diagnosis {
+	d := &Diagnosis{}
+
+	// Headers
+	presentedKey := req.Header.Get("X-LCC-PublicKey")
+	timestampStr := req.Header.Get("X-LCC-Timestamp")
+	nonce := req.Header.Get("X-LCC-Nonce")
+	signatureHex := req.Header.Get("X-LCC-Signature")
+	var missing []string
+	for _, h := range [][2]string{
+		{"X-LCC-PublicKey", presentedKey},
+		{"X-LCC-Timestamp", timestampStr},
+		{"X-LCC-Nonce", nonce},
+		{"X-LCC-Signature", signatureHex},
+	} {
+		if h[1] == "" {
+			missing = append(missing, h[0])
+		}
+	}
+	if len(missing) > 0 {
+		d.add(StepHeaders, StepFailed, "missing %s (stripped by a proxy, or the request was not signed)", strings.Join(missing, ", "))
+	} else {
+		d.add(StepHeaders, StepOK, "all authentication headers present")
+	}
+
+	// Timestamp
+	timestamp, tsErr := strconv.ParseInt(timestampStr, 10, 64)
+	switch {
+	case timestampStr == "":
+		d.add(StepTimestamp, StepSkipped, "no X-LCC-Timestamp")
+	case tsErr != nil:
+		d.add(StepTimestamp, StepFailed, "X-LCC-Timestamp %q is not Unix seconds", timestampStr)
+	default:
+		clock := opts.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		now := clock.Now()
+		d.Skew = time.Duration(now.Unix()-timestamp) * time.Second
+		maxAge, maxFuture := opts.MaxAge, opts.MaxFutureSkew
+		if maxAge <= 0 {
+			maxAge = DefaultMaxAge
+		}
+		if maxFuture <= 0 {
+			maxFuture = DefaultMaxFutureSkew
+		}
+		detail := fmt.Sprintf("signed at %s, verifier clock %s: skew %s (accepted: %s old to %s ahead)",
+			time.Unix(timestamp, 0).UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339), d.Skew, maxAge, maxFuture)
+		if err := opts.checkTimestamp(timestamp); err != nil {
+			d.add(StepTimestamp, StepFailed, "%s; check the client's clock or enable clock sync", detail)
+		} else {
+			d.add(StepTimestamp, StepOK, "%s", detail)
+		}
+	}
+
+	// Body hash
+	var (
+		body    []byte
+		readErr error
+	)
+	if req.Body != nil {
+		body, readErr = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	// An unknown algorithm is reported, then SHA-256 is tried
+	algorithm := strings.ToLower(req.Header.Get(HeaderHash))
+	if algorithm == "" {
+		algorithm = HashSHA256
+	}
+	_, _, hashErr := opts.requestHash(req)
+	h, err := HashFunc(algorithm)
+	if err != nil {
+		h = crypto.SHA256
+	}
+	bodyHash := hex.EncodeToString(digest(h, body))
+	declaredHash := strings.ToLower(req.Header.Get(contentHashHeader(algorithm)))
+	switch {
+	case hashErr != nil:
+		d.add(StepBodyHash, StepFailed, "%v; the verifier accepts %s", hashErr, strings.Join(opts.acceptedHashes(), ", "))
+	case readErr != nil:
+		d.add(StepBodyHash, StepFailed, "failed to read request body: %v", readErr)
+	case declaredHash != "" && declaredHash != bodyHash:
+		d.add(StepBodyHash, StepFailed, "%s declares %s, the %d-byte body hashes to %s; the body was modified after signing", contentHashHeader(algorithm), declaredHash, len(body), bodyHash)
+	default:
+		d.add(StepBodyHash, StepOK, "%d-byte body hashes to %s", len(body), bodyHash)
+	}
+
+	// Public key
+	var key crypto.PublicKey
+	presentedPEM, err := base64.StdEncoding.DecodeString(presentedKey)
+	switch {
+	case presentedKey == "":
+		d.add(StepPublicKey, StepSkipped, "no X-LCC-PublicKey")
+	case err != nil:
+		d.add(StepPublicKey, StepFailed, "X-LCC-PublicKey is not base64: %v", err)
+	default:
+		key, err = diagnoseKey(d, presentedPEM, publicKeyPEM)
+		if err != nil {
+			d.add(StepPublicKey, StepFailed, "%v", err)
+		}
+	}
+	if publicKeyPEM != nil && key == nil {
+		// Still check the signature against the expected key
+		key, _ = ParsePublicKeyPEM(publicKeyPEM)
+	}
+
+	// Signature
+	signature, sigErr := hex.DecodeString(signatureHex)
+	switch {
+	case signatureHex == "" || timestampStr == "" || nonce == "":
+		d.add(StepSignature, StepSkipped, "signature headers missing")
+	case key == nil:
+		d.add(StepSignature, StepSkipped, "no usable public key")
+	case sigErr != nil:
+		d.add(StepSignature, StepFailed, "X-LCC-Signature is not hex: %v", sigErr)
+	default:
+		diagnoseSignature(d, req, opts, key, h, signature, bodyHash, declaredHash, timestampStr, nonce)
+	}
+	return d
+}
+
+// diagnoseKey checks the presented key against the expected one, adding
+// the public key step unless it returns an error
+func diagnoseKey(d *Diagnosis, presentedPEM, expectedPEM []byte) (crypto.PublicKey, error) {
+	fp, _, err := fingerprintPEM(presentedPEM)
+	if err != nil {
+		return nil, fmt.Errorf("presented key: %w", err)
+	}
+	d.Fingerprint = fp
+	presented, err := ParsePublicKeyPEM(presentedPEM)
+	if err != nil {
+		return nil, fmt.Errorf("presented key %s: %w", fp, err)
+	}
+	if expectedPEM == nil {
+		d.add(StepPublicKey, StepOK, "presented key %s (%s), not checked against an expected key", fp, keyType(presented))
+		return presented, nil
+	}
+
+	expectedFP, _, err := fingerprintPEM(expectedPEM)
+	if err != nil {
+		return nil, fmt.Errorf("expected key: %w", err)
+	}
+	if expectedFP != fp {
+		return nil, fmt.Errorf("request presents key %s, expected %s; the client signs with another identity", fp, expectedFP)
+	}
+	d.add(StepPublicKey, StepOK, "presented key %s (%s) is the expected key", fp, keyType(presented))
+	return presented, nil
+}
+
+// diagnoseSignature rebuilds the canonical strings and checks the
+// signature, trying likely causes when it does not match
+func diagnoseSignature(d *Diagnosis, req *http.Request, opts VerifyOptions, key crypto.PublicKey, h crypto.Hash, signature []byte, bodyHash, declaredHash, timestamp, nonce string) {
+	version := SignatureV1
+	if v := req.Header.Get(HeaderSigVersion); v != "" {
+		var err error
+		if version, err = strconv.Atoi(v); err != nil || version < SignatureV1 || version > SignatureV2 {
+			d.add(StepSignature, StepFailed, "unsupported %s %q", HeaderSigVersion, v)
+			return
+		}
+	}
+	if version < opts.MinSignatureVersion {
+		d.add(StepSignature, StepFailed, "signature version %d not accepted (minimum %d); upgrade the client SDK", version, opts.MinSignatureVersion)
+		return
+	}
+	scheme := req.Header.Get(HeaderSigScheme)
+	if _, isRSA := key.(*rsa.PublicKey); isRSA && opts.RequirePSS && scheme != SchemeRSAPSS {
+		d.add(StepSignature, StepFailed, "signature scheme %s required, request announces %q", SchemeRSAPSS, scheme)
+		return
+	}
+
+	canonicalFor := func(path, bodyHash string) (string, error) {
+		if version == SignatureV1 {
+			return fmt.Sprintf("%s\n%s\n%s\n%s\n%s", req.Method, path, bodyHash, timestamp, nonce), nil
+		}
+		query, err := CanonicalQuery(req.URL.RawQuery)
+		if err != nil {
+			return "", err
+		}
+		signedHeaders := parseSignedHeaders(req.Header.Get(HeaderSignedHeaders))
+		return canonicalV2(req.Method, path, query, signedHeaders, req.Header, bodyHash, timestamp, nonce), nil
+	}
+	matches := func(canonical string) bool {
+		return verifyDigestWithScheme(key, scheme, h, digest(h, []byte(canonical)), signature) == nil
+	}
+
+	paths := opts.Canonicalizer.VerifyPaths(req)
+	for _, path := range paths {
+		canonical, err := canonicalFor(path, bodyHash)
+		if err != nil {
+			d.add(StepSignature, StepFailed, "cannot canonicalize the query string: %v", err)
+			return
+		}
+		d.Canonical = append(d.Canonical, canonical)
+		if matches(canonical) {
+			d.add(StepSignature, StepOK, "v%d %s signature matches the canonical string for path %s", version, schemeName(key, scheme), path)
+			return
+		}
+	}
+
+	// Look for the canonical string the client did sign
+	hint := "the client signed a different method, path, query, headers or body, or with another key"
+	if declaredHash != "" && declaredHash != bodyHash {
+		if canonical, err := canonicalFor(paths[0], declaredHash); err == nil && matches(canonical) {
+			hint = "the signature covers the declared body hash; the body was modified after signing"
+		}
+	}
+	if prefix := strings.TrimRight(req.Header.Get(HeaderForwardedPrefix), "/"); prefix != "" && !opts.Canonicalizer.TrustForwardedPrefix {
+		if canonical, err := canonicalFor(prefix+paths[0], bodyHash); err == nil && matches(canonical) {
+			hint = fmt.Sprintf("the client signed the external path %s; set PathCanonicalizer.TrustForwardedPrefix", prefix+paths[0])
+		}
+	}
+	if opts.Canonicalizer.StripPrefix != "" && req.URL.Path != paths[0] {
+		if canonical, err := canonicalFor(req.URL.Path, bodyHash); err == nil && matches(canonical) {
+			hint = fmt.Sprintf("the client signed the unstripped path %s; check PathCanonicalizer.StripPrefix", req.URL.Path)
+		}
+	}
+	d.add(StepSignature, StepFailed, "v%d %s signature does not match the canonical string: %s", version, schemeName(key, scheme), hint)
+}
+
+// keyType names the algorithm of a public key
+func keyType(key crypto.PublicKey) string {
+	if k, ok := key.(*rsa.PublicKey); ok {
+		return fmt.Sprintf("RSA-%d", k.N.BitLen())
+	}
+	return "ECDSA P-256"
+}
+
+// schemeName names the signature scheme of a request
+func schemeName(key crypto.PublicKey, scheme string) string {
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		return "ECDSA"
+	}
+	if scheme == "" {
+		return SchemeRSAPKCS1v15
+	}
+	return scheme
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+// Key generation dominates fuzzing time, so all targets share one key pair
+var (
+	fuzzKeyOnce sync.Once
+	fuzzKey     KeyPair
+)
+
+func fuzzSigner(t testing.TB) *RequestSigner {
+	fuzzKeyOnce.Do(func() {
+		kp, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair() error = %v", err)
+		}
+		fuzzKey = kp
+	})
+	return NewRequestSigner(fuzzKey)
+}
+
+// newFuzzRequest builds a request without URL parsing, so any path,
+// including invalid UTF-8 and control characters, reaches the signer
+func newFuzzRequest(method, path, query string, body []byte) *http.Request {
+	req := &http.Request{
+		Method: method,
+		URL:    &url.URL{Scheme: "http", Host: "lcc", Path: path, RawQuery: query},
+		Header: make(http.Header),
+	}
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return req
+}
+
+// validMethod reports whether method is an HTTP token (no separators)
+func validMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for _, r := range method {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// FuzzSignVerifyRoundTrip checks that every signed request verifies, with
+// any path, query string, body and extra (repeated) headers, and that
+// changing the method, path or body breaks the signature
+func FuzzSignVerifyRoundTrip(f *testing.F) {
+	f.Add("POST", "/api/v1/sdk/usage", "", []byte(`{"count":1}`), "X-Trace", "a")
+	f.Add("GET", "/api/v1/sdk/features/導出/check", "x=1&x=2", []byte(nil), "Accept", "*/*")
+	f.Add("PUT", "/api/v1/%2F/../a b", "q=%ZZ", []byte("\x00\xff"), "X-LCC-Nonce", "dup")
+	f.Add("DELETE", "/\n/\r\n", "", bytes.Repeat([]byte("x"), 1<<16), "Content-Type", "text/plain")
+	f.Add("GET", "", "", []byte{}, "", "")
+
+	f.Fuzz(func(t *testing.T, method, path, query string, body []byte, hdrName, hdrValue string) {
+		if !validMethod(method) {
+			t.Skip()
+		}
+		signer := fuzzSigner(t)
+
+		req := newFuzzRequest(method, path, query, body)
+		if err := signer.SignRequest(req); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+		if hdrName != "" && !strings.HasPrefix(http.CanonicalHeaderKey(hdrName), "X-Lcc-") {
+			// Unsigned headers, repeated, do not affect verification
+			req.Header.Add(hdrName, hdrValue)
+			req.Header.Add(hdrName, hdrValue)
+		}
+
+		if err := VerifyRequest(req); err != nil {
+			t.Fatalf("VerifyRequest() of signed request error = %v", err)
+		}
+		if req.Body != nil {
+			if got, _ := io.ReadAll(req.Body); !bytes.Equal(got, body) {
+				t.Fatal("VerifyRequest() must restore the body")
+			}
+		}
+
+		tampered := func(name string, mutate func(r *http.Request)) {
+			r := req.Clone(req.Context())
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			mutate(r)
+			if err := VerifyRequest(r); err == nil {
+				t.Errorf("VerifyRequest() with %s changed should fail", name)
+			}
+		}
+		tampered("method", func(r *http.Request) { r.Method += "X" })
+		tampered("path", func(r *http.Request) { r.URL.Path += "x" })
+		tampered("body", func(r *http.Request) {
+			r.Body = io.NopCloser(bytes.NewReader(append(append([]byte{}, body...), 'x')))
+		})
+	})
+}
+
+// FuzzSignRequestWithBodyHash checks that streamed-body signatures verify
+// both buffered and streaming, and that the streaming verifier rejects a
+// body differing from the signed hash
+func FuzzSignRequestWithBodyHash(f *testing.F) {
+	f.Add("/api/v1/sdk/usage/batch", []byte(`[{"count":1}]`), []byte(`[{"count":2}]`))
+	f.Add("/", []byte{}, []byte{0})
+
+	f.Fuzz(func(t *testing.T, path string, body, other []byte) {
+		signer := fuzzSigner(t)
+		hash, err := ComputeBodyHashFromReader(bytes.NewReader(body))
+		if err != nil || hash != ComputeBodyHash(body) {
+			t.Fatalf("ComputeBodyHashFromReader() = %q, %v; want %q", hash, err, ComputeBodyHash(body))
+		}
+
+		req := newFuzzRequest("POST", path, "", body)
+		if err := signer.SignRequestWithBodyHash(req, hash); err != nil {
+			t.Fatalf("SignRequestWithBodyHash() error = %v", err)
+		}
+		if err := VerifyRequest(req); err != nil {
+			t.Fatalf("VerifyRequest() error = %v", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(other))
+		if err := VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true}); err != nil {
+			t.Fatalf("VerifyRequestWithOptions(StreamBody) error = %v", err)
+		}
+		_, err = io.ReadAll(req.Body)
+		if mismatch := errors.Is(err, ErrBodyHashMismatch); mismatch == bytes.Equal(body, other) {
+			t.Errorf("streamed body read error = %v, bodies equal = %v", err, bytes.Equal(body, other))
+		}
+	})
+}
+
+// FuzzVerifyRequest feeds arbitrary authentication headers to the
+// verifier: it must never panic and never accept a forged signature
+func FuzzVerifyRequest(f *testing.F) {
+	f.Add("", "1700000000", "n", "00", []byte{})
+	f.Add("LS0tLS1CRUdJTg==", "-1", "", "zz", []byte("{}"))
+	f.Add("!!!", "99999999999999999999", "nonce", strings.Repeat("ab", 256), []byte(nil))
+
+	f.Fuzz(func(t *testing.T, publicKey, timestamp, nonce, signature string, body []byte) {
+		req := newFuzzRequest("POST", "/api/v1/sdk/usage", "", body)
+		req.Header.Set("X-LCC-PublicKey", publicKey)
+		req.Header.Set("X-LCC-Timestamp", timestamp)
+		req.Header.Set("X-LCC-Nonce", nonce)
+		req.Header.Set("X-LCC-Signature", signature)
+		req.Header.Set(HeaderContentSHA256, signature)
+
+		if err := VerifyRequest(req); err == nil {
+			t.Fatal("VerifyRequest() accepted a forged request")
+		}
+		_ = VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true, Canonicalizer: PathCanonicalizer{TrustForwardedPrefix: true}})
+	})
+}
+
+// FuzzBuildCanonicalString checks that the canonical string is unambiguous:
+// method and path are recoverable from it, so distinct requests never
+// share a signature
+func FuzzBuildCanonicalString(f *testing.F) {
+	f.Add("GET", "/api/v1/sdk/features/x/check", int64(1700000000), "nonce")
+	f.Add("POST", "/a\nb", int64(-1), "")
+	f.Add("GET", "\n\n\n\n", int64(0), "\n")
+
+	f.Fuzz(func(t *testing.T, method, path string, timestamp int64, nonce string) {
+		if !validMethod(method) || strings.Contains(nonce, "\n") {
+			t.Skip()
+		}
+		bodyHash := ComputeBodyHash([]byte(path))
+		canonical := BuildCanonicalString(method, path, bodyHash, timestamp, nonce)
+
+		gotMethod, rest, ok := strings.Cut(canonical, "\n")
+		if !ok || gotMethod != method {
+			t.Fatalf("method not recoverable from %q", canonical)
+		}
+		// Body hash, timestamp and nonce contain no newline: the last three
+		// fields are fixed, and the path is what remains
+		fields := strings.Split(rest, "\n")
+		if len(fields) < 4 {
+			t.Fatalf("canonical string %q has too few fields", canonical)
+		}
+		n := len(fields)
+		if gotPath := strings.Join(fields[:n-3], "\n"); gotPath != path {
+			t.Errorf("path recovered as %q, want %q", gotPath, path)
+		}
+		if fields[n-3] != bodyHash || fields[n-1] != nonce {
+			t.Errorf("trailing fields = %q", fields[n-3:])
+		}
+	})
+}
+
+// FuzzStripPathPrefix checks the gateway prefix rules: the result is a
+// rooted path, and a stripped prefix ends on a segment boundary
+func FuzzStripPathPrefix(f *testing.F) {
+	f.Add("/lcc/api/v1", "/lcc")
+	f.Add("/lccx/api", "/lcc/")
+	f.Add("/", "/")
+	f.Add("/ü/ä", "/ü")
+
+	f.Fuzz(func(t *testing.T, path, prefix string) {
+		if !strings.HasPrefix(path, "/") {
+			t.Skip()
+		}
+		got := stripPathPrefix(path, prefix)
+		if !strings.HasPrefix(got, "/") {
+			t.Fatalf("stripPathPrefix(%q, %q) = %q, not rooted", path, prefix, got)
+		}
+		if got == path {
+			return
+		}
+		trimmed := strings.TrimRight(prefix, "/")
+		if got == "/" && path == trimmed {
+			return
+		}
+		if trimmed+got != path {
+			t.Errorf("stripPathPrefix(%q, %q) = %q, not a prefix removal", path, prefix, got)
+		}
+		if utf8.ValidString(path) && !utf8.ValidString(got) {
+			t.Errorf("stripPathPrefix(%q, %q) split a rune", path, prefix)
+		}
+	})
+}
+
+// TestSignVerify_LargeBody signs an 8 MiB body both buffered and streamed
+func TestSignVerify_LargeBody(t *testing.T) {
+	signer := fuzzSigner(t)
+	body := bytes.Repeat([]byte("0123456789abcdef"), 8<<16)
+
+	req := newFuzzRequest("POST", "/api/v1/sdk/usage/batch", "", body)
+	if err := signer.SignRequest(req); err != nil {
+		t.Fatalf("SignRequest() error = %v", err)
+	}
+	if err := VerifyRequest(req); err != nil {
+		t.Fatalf("VerifyRequest() error = %v", err)
+	}
+
+	sum := sha256.Sum256(body)
+	req = newFuzzRequest("POST", "/api/v1/sdk/usage/batch", "", body)
+	if err := signer.SignRequestWithBodyHash(req, hex.EncodeToString(sum[:])); err != nil {
+		t.Fatalf("SignRequestWithBodyHash() error = %v", err)
+	}
+	if err := VerifyRequestWithOptions(req, VerifyOptions{StreamBody: true}); err != nil {
+		t.Fatalf("VerifyRequestWithOptions() error = %v", err)
+	}
+	if n, err := io.Copy(io.Discard, req.Body); err != nil || n != int64(len(body)) {
+		t.Errorf("streamed body = %d bytes, %v", n, err)
+	}
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	// Registers crypto.SHA384 and crypto.SHA512
+	_ "crypto/sha512"
+)
+
+// Hash algorithms of request body hashes, signatures and fingerprints.
+// Requests announce theirs in the X-LCC-Hash header; requests without the
+// header use HashSHA256.
+const (
+	HashSHA256 = "sha256"
+	HashSHA384 = "sha384"
+	HashSHA512 = "sha512"
+)
+
+// Hash negotiation headers. A request signed with a hash other than
+// HashSHA256 announces it in HeaderHash. Servers list the algorithms they
+// accept, preferred first, in HeaderAcceptHash (see SetAcceptHash), which
+// clients use to pick the algorithm of their next requests.
+const (
+	HeaderHash       = "X-LCC-Hash"
+	HeaderAcceptHash = "X-LCC-Accept-Hash"
+)
+
+// HeaderContentHash carries the hex body hash of a request signed with
+// SignRequestWithBodyHash and a hash other than HashSHA256, which uses
+// HeaderContentSHA256
+const HeaderContentHash = "X-LCC-Content-Hash"
+
+// ErrHashNotAccepted is returned when a request is signed with a hash
+// algorithm the verifier does not accept (see VerifyOptions.HashAlgorithms)
+var ErrHashNotAccepted = errors.New("hash algorithm not accepted")
+
+// supportedHashes are the supported algorithms, strongest first
+var supportedHashes = []string{HashSHA512, HashSHA384, HashSHA256}
+
+// SupportedHashes returns the supported hash algorithms, strongest first
+func SupportedHashes() []string {
+	return append([]string(nil), supportedHashes...)
+}
+
+// HashFunc returns the crypto.Hash of algorithm; "" is HashSHA256
+func HashFunc(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case "", HashSHA256:
+		return crypto.SHA256, nil
+	case HashSHA384:
+		return crypto.SHA384, nil
+	case HashSHA512:
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported hash algorithm %q", algorithm)
+}
+
+// hashName returns the algorithm name of h
+func hashName(h crypto.Hash) string {
+	switch h {
+	case crypto.SHA384:
+		return HashSHA384
+	case crypto.SHA512:
+		return HashSHA512
+	}
+	return HashSHA256
+}
+
+// digest returns the h digest of data
+func digest(h crypto.Hash, data []byte) []byte {
+	d := h.New()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+// contentHashHeader returns the header carrying a declared body hash of
+// algorithm
+func contentHashHeader(algorithm string) string {
+	if algorithm == "" || algorithm == HashSHA256 {
+		return HeaderContentSHA256
+	}
+	return HeaderContentHash
+}
+
+// ComputeBodyHashWith computes the hex body hash of a request signed with
+// algorithm
+func ComputeBodyHashWith(algorithm string, body []byte) (string, error) {
+	h, err := HashFunc(algorithm)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest(h, body)), nil
+}
+
+// Fingerprint returns the hex digest with algorithm of kp's PKIX DER
+// public key. With HashSHA256 it is KeyPair.GetFingerprint.
+func Fingerprint(kp KeyPair, algorithm string) (string, error) {
+	h, err := HashFunc(algorithm)
+	if err != nil {
+		return "", err
+	}
+	der, err := kp.GetPublicKeyDER()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest(h, der)), nil
+}
+
+// DigestSigner is implemented by key pairs able to sign digests of any
+// supported hash, which signing with a hash other than HashSHA256 requires
+type DigestSigner interface {
+	// SignDigest signs a digest computed with opts.HashFunc(). For RSA
+	// keys *rsa.PSSOptions select RSA-PSS, any other opts PKCS#1 v1.5.
+	SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// SignDigest signs a digest with the private key: PKCS#1 v1.5, or RSA-PSS
+// for *rsa.PSSOptions
+func (kp *RSAKeyPair) SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if kp.privateKey == nil {
+		return nil, fmt.Errorf("private key is nil")
+	}
+	signature, err := kp.privateKey.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+	return signature, nil
+}
+
+// SignDigest signs a digest with the private key, returning an ASN.1 DER
+// signature
+func (kp *ECDSAKeyPair) SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if kp.privateKey == nil {
+		return nil, fmt.Errorf("private key is nil")
+	}
+	signature, err := kp.privateKey.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+	return signature, nil
+}
+
+// SignDigest has the signer sign a digest
+func (kp *SignerKeyPair) SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if kp.signer == nil {
+		return nil, fmt.Errorf("signer is nil")
+	}
+	signature, err := kp.signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+	return signature, nil
+}
+
+// WithHashAlgorithm selects the hash of body hashes and signatures
+// (default HashSHA256). Other algorithms require a key pair implementing
+// DigestSigner; use them once the servers verifying the requests accept
+// them (see HeaderAcceptHash).
+func WithHashAlgorithm(algorithm string) SignerOption {
+	return func(s *RequestSigner) {
+		s.hashAlgorithm = algorithm
+	}
+}
+
+// signDigestWithScheme signs the h digest of data with a DigestSigner in
+// the given scheme
+func signDigestWithScheme(kp KeyPair, scheme string, h crypto.Hash, data []byte) ([]byte, error) {
+	ds, ok := kp.(DigestSigner)
+	if !ok {
+		return nil, fmt.Errorf("key pair %T does not support hash %s", kp, hashName(h))
+	}
+	var opts crypto.SignerOpts = h
+	switch scheme {
+	case "", SchemeRSAPKCS1v15:
+	case SchemeRSAPSS:
+		if kp.Algorithm() != AlgorithmRSA {
+			return nil, fmt.Errorf("RSA-PSS requires an RSA key, have %s", kp.Algorithm())
+		}
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: h}
+	default:
+		return nil, fmt.Errorf("unsupported signature scheme %q", scheme)
+	}
+	return ds.SignDigest(digest(h, data), opts)
+}
+
+// ParseAcceptHash splits an X-LCC-Accept-Hash value into lower-case
+// algorithm names, in order
+func ParseAcceptHash(value string) []string {
+	var algorithms []string
+	for _, a := range strings.Split(value, ",") {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			algorithms = append(algorithms, a)
+		}
+	}
+	return algorithms
+}
+
+// NegotiateHash returns the first of preferred listed in accepted, an
+// X-LCC-Accept-Hash value, or "" if there is none
+func NegotiateHash(preferred []string, accepted string) string {
+	acceptedSet := make(map[string]bool)
+	for _, a := range ParseAcceptHash(accepted) {
+		acceptedSet[a] = true
+	}
+	for _, a := range preferred {
+		if acceptedSet[a] {
+			return a
+		}
+	}
+	return ""
+}
+
+// SetAcceptHash sets the X-LCC-Accept-Hash header of a server response to
+// the hash algorithms opts accepts
+func SetAcceptHash(h http.Header, opts VerifyOptions) {
+	h.Set(HeaderAcceptHash, strings.Join(opts.acceptedHashes(), ", "))
+}
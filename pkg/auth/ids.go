@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator returns a new unique ID, such as a request nonce
+type IDGenerator func() (string, error)
+
+// NewUUIDGenerator returns random (version 4) UUIDs read from r, e.g. a
+// certified DRBG for deployments with strict randomness sourcing
+// requirements. A nil r uses crypto/rand, like the default.
+func NewUUIDGenerator(r io.Reader) IDGenerator {
+	return func() (string, error) {
+		if r == nil {
+			id, err := uuid.NewRandom()
+			return id.String(), err
+		}
+		id, err := uuid.NewRandomFromReader(r)
+		return id.String(), err
+	}
+}
+
+// NewSequentialGenerator returns "<prefix>-1", "<prefix>-2", … for
+// deterministic test environments. Do not use it in production: the
+// sequence restarts with the process, and servers reject repeated nonces.
+func NewSequentialGenerator(prefix string) IDGenerator {
+	var n atomic.Uint64
+	return func() (string, error) {
+		return fmt.Sprintf("%s-%d", prefix, n.Add(1)), nil
+	}
+}
+
+// WithNonceSource sets the generator of request nonces (default: random
+// UUIDs from crypto/rand)
+func WithNonceSource(gen IDGenerator) SignerOption {
+	return func(s *RequestSigner) {
+		s.nonces = gen
+	}
+}
+
+// InstanceIDFunc derives an instance ID from the instance key pair
+type InstanceIDFunc func(kp KeyPair) (string, error)
+
+// FingerprintInstanceID is the default InstanceIDFunc: the key fingerprint
+func FingerprintInstanceID(kp KeyPair) (string, error) {
+	return kp.GetFingerprint()
+}
+
+// FingerprintInstanceIDWithHash derives the instance ID as the key
+// fingerprint with algorithm (see Fingerprint)
+func FingerprintInstanceIDWithHash(algorithm string) InstanceIDFunc {
+	return func(kp KeyPair) (string, error) {
+		return Fingerprint(kp, algorithm)
+	}
+}
+
+// SaltedInstanceID derives the instance ID as the hex SHA-256 of salt, a
+// zero byte and the PKIX DER public key, e.g. with a per-customer salt so
+// IDs cannot be correlated across customers
+func SaltedInstanceID(salt string) InstanceIDFunc {
+	return SaltedInstanceIDWithHash(salt, HashSHA256)
+}
+
+// SaltedInstanceIDWithHash is SaltedInstanceID with another hash algorithm
+func SaltedInstanceIDWithHash(salt, algorithm string) InstanceIDFunc {
+	return func(kp KeyPair) (string, error) {
+		hash, err := HashFunc(algorithm)
+		if err != nil {
+			return "", err
+		}
+		der, err := kp.GetPublicKeyDER()
+		if err != nil {
+			return "", err
+		}
+		h := hash.New()
+		h.Write([]byte(salt))
+		h.Write([]byte{0})
+		h.Write(der)
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+}
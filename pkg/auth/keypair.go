@@ -2,6 +2,8 @@ package auth
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -12,34 +14,89 @@ import (
 	"os"
 )
 
-// KeyPair represents an RSA key pair for self-signed authentication
-type KeyPair struct {
+// Key algorithms accepted by GenerateKeyPairForAlgorithm
+const (
+	AlgorithmRSA       = "rsa"
+	AlgorithmECDSAP256 = "ecdsa-p256"
+)
+
+// KeyPair is a private key used for self-signed authentication. All
+// implementations sign a SHA-256 digest of the data, so signatures from
+// any of them verify with VerifySignature.
+type KeyPair interface {
+	// Algorithm returns the key algorithm (AlgorithmRSA or AlgorithmECDSAP256)
+	Algorithm() string
+
+	// Sign signs data with the private key
+	Sign(data []byte) ([]byte, error)
+
+	// Verify verifies a signature made by Sign
+	Verify(data []byte, signature []byte) error
+
+	// GetPublicKeyPEM exports the public key as a PKIX "PUBLIC KEY" PEM block
+	GetPublicKeyPEM() (string, error)
+
+	// GetPublicKeyDER exports the public key in PKIX DER format
+	GetPublicKeyDER() ([]byte, error)
+
+	// GetFingerprint returns the hex SHA-256 of the PKIX DER public key
+	GetFingerprint() (string, error)
+
+	// ExportPrivateKeyPEM returns the private key in PEM format
+	ExportPrivateKeyPEM() (string, error)
+
+	// SavePrivateKeyPEMFile saves the private key PEM with 0600 perms
+	SavePrivateKeyPEMFile(path string) error
+
+	// Destroy wipes the private key from memory
+	Destroy()
+}
+
+// GenerateKeyPairForAlgorithm generates a key pair for algorithm; an empty
+// algorithm means AlgorithmRSA
+func GenerateKeyPairForAlgorithm(algorithm string) (KeyPair, error) {
+	switch algorithm {
+	case "", AlgorithmRSA:
+		return GenerateKeyPair()
+	case AlgorithmECDSAP256:
+		return GenerateECDSAKeyPair()
+	}
+	return nil, fmt.Errorf("unsupported key algorithm: %q", algorithm)
+}
+
+// RSAKeyPair represents an RSA key pair for self-signed authentication
+type RSAKeyPair struct {
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
 }
 
 // GenerateKeyPair generates a new RSA key pair
 // Key size is 2048 bits as per specification
-func GenerateKeyPair() (*KeyPair, error) {
+func GenerateKeyPair() (*RSAKeyPair, error) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
 	}
 
-	return &KeyPair{
+	return &RSAKeyPair{
 		privateKey: privateKey,
 		publicKey:  &privateKey.PublicKey,
 	}, nil
 }
 
 // NewKeyPairFromPrivateKey wraps an existing private key into KeyPair
-func NewKeyPairFromPrivateKey(priv *rsa.PrivateKey) *KeyPair {
+func NewKeyPairFromPrivateKey(priv *rsa.PrivateKey) *RSAKeyPair {
 	if priv == nil { return nil }
-	return &KeyPair{ privateKey: priv, publicKey: &priv.PublicKey }
+	return &RSAKeyPair{ privateKey: priv, publicKey: &priv.PublicKey }
+}
+
+// Algorithm returns AlgorithmRSA
+func (kp *RSAKeyPair) Algorithm() string {
+	return AlgorithmRSA
 }
 
 // ExportPrivateKeyPEM returns PKCS#1 PEM for the RSA private key
-func (kp *KeyPair) ExportPrivateKeyPEM() (string, error) {
+func (kp *RSAKeyPair) ExportPrivateKeyPEM() (string, error) {
 	if kp.privateKey == nil {
 		return "", fmt.Errorf("private key is nil")
 	}
@@ -66,23 +123,55 @@ func ParseRSAPrivateKeyFromPEM(pemData []byte) (*rsa.PrivateKey, error) {
 }
 
 // SavePrivateKeyPEMFile saves private key PEM to file with 0600 perms
-func (kp *KeyPair) SavePrivateKeyPEMFile(path string) error {
+func (kp *RSAKeyPair) SavePrivateKeyPEMFile(path string) error {
 	pemStr, err := kp.ExportPrivateKeyPEM()
 	if err != nil { return err }
 	return os.WriteFile(path, []byte(pemStr), 0600)
 }
 
-// LoadKeyPairFromPEMFile loads KeyPair from a PKCS#1 PEM private key file
-func LoadKeyPairFromPEMFile(path string) (*KeyPair, error) {
+// ParsePrivateKeyPEM parses an RSA (PKCS#1) or ECDSA P-256 (SEC1) private
+// key, or either in PKCS#8, into a KeyPair
+func ParsePrivateKeyPEM(pemData []byte) (KeyPair, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("invalid PEM type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch priv := key.(type) {
+	case *rsa.PrivateKey:
+		return NewKeyPairFromPrivateKey(priv), nil
+	case *ecdsa.PrivateKey:
+		return NewECDSAKeyPair(priv)
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+// LoadKeyPairFromPEMFile loads a KeyPair from a PEM private key file in any
+// format ParsePrivateKeyPEM accepts
+func LoadKeyPairFromPEMFile(path string) (KeyPair, error) {
 	b, err := os.ReadFile(path)
 	if err != nil { return nil, err }
-	priv, err := ParseRSAPrivateKeyFromPEM(b)
-	if err != nil { return nil, err }
-	return NewKeyPairFromPrivateKey(priv), nil
+	return ParsePrivateKeyPEM(b)
 }
 
 // Sign signs data using the private key with PKCS#1 v1.5 padding
-func (kp *KeyPair) Sign(data []byte) ([]byte, error) {
+func (kp *RSAKeyPair) Sign(data []byte) ([]byte, error) {
 	if kp.privateKey == nil {
 		return nil, fmt.Errorf("private key is nil")
 	}
@@ -100,7 +189,7 @@ func (kp *KeyPair) Sign(data []byte) ([]byte, error) {
 }
 
 // Verify verifies a signature using the public key
-func (kp *KeyPair) Verify(data []byte, signature []byte) error {
+func (kp *RSAKeyPair) Verify(data []byte, signature []byte) error {
 	if kp.publicKey == nil {
 		return fmt.Errorf("public key is nil")
 	}
@@ -118,7 +207,7 @@ func (kp *KeyPair) Verify(data []byte, signature []byte) error {
 }
 
 // GetPublicKeyPEM exports the public key in PEM format
-func (kp *KeyPair) GetPublicKeyPEM() (string, error) {
+func (kp *RSAKeyPair) GetPublicKeyPEM() (string, error) {
 	if kp.publicKey == nil {
 		return "", fmt.Errorf("public key is nil")
 	}
@@ -141,7 +230,7 @@ func (kp *KeyPair) GetPublicKeyPEM() (string, error) {
 }
 
 // GetPublicKeyDER exports the public key in DER format
-func (kp *KeyPair) GetPublicKeyDER() ([]byte, error) {
+func (kp *RSAKeyPair) GetPublicKeyDER() ([]byte, error) {
 	if kp.publicKey == nil {
 		return nil, fmt.Errorf("public key is nil")
 	}
@@ -151,7 +240,7 @@ func (kp *KeyPair) GetPublicKeyDER() ([]byte, error) {
 
 // GetFingerprint returns the SHA-256 fingerprint of the public key
 // This can be used as a unique identifier for the instance
-func (kp *KeyPair) GetFingerprint() (string, error) {
+func (kp *RSAKeyPair) GetFingerprint() (string, error) {
 	pubKeyDER, err := kp.GetPublicKeyDER()
 	if err != nil {
 		return "", err
@@ -161,7 +250,8 @@ func (kp *KeyPair) GetFingerprint() (string, error) {
 	return hex.EncodeToString(hash[:]), nil
 }
 
-// ParsePublicKeyFromPEM parses a public key from PEM format
+// ParsePublicKeyFromPEM parses an RSA public key from PEM format. Use
+// ParsePublicKeyPEM to accept ECDSA keys as well.
 func ParsePublicKeyFromPEM(pemData []byte) (*rsa.PublicKey, error) {
 	block, _ := pem.Decode(pemData)
 	if block == nil {
@@ -185,24 +275,76 @@ func ParsePublicKeyFromPEM(pemData []byte) (*rsa.PublicKey, error) {
 	return rsaPub, nil
 }
 
+// ParsePublicKeyPEM parses an RSA or ECDSA P-256 public key from PEM format
+func ParsePublicKeyPEM(pemData []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("invalid PEM type: %s", block.Type)
+	}
+
+	return parsePublicKeyDER(block.Bytes)
+}
+
 // VerifySignatureWithPublicKey verifies a signature using a public key in PEM format
 func VerifySignatureWithPublicKey(publicKeyPEM []byte, data []byte, signature []byte) error {
-	publicKey, err := ParsePublicKeyFromPEM(publicKeyPEM)
+	publicKey, err := ParsePublicKeyPEM(publicKeyPEM)
 	if err != nil {
 		return err
 	}
 
+	return VerifySignature(publicKey, data, signature)
+}
+
+// VerifySignature verifies a signature over the SHA-256 digest of data:
+// PKCS#1 v1.5 for RSA keys, ASN.1 DER for ECDSA P-256 keys
+func VerifySignature(publicKey crypto.PublicKey, data []byte, signature []byte) error {
 	hashed := sha256.Sum256(data)
-	err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature)
-	if err != nil {
+	if err := verifyDigest(publicKey, crypto.SHA256, hashed[:], signature); err != nil {
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
 
 	return nil
 }
 
+// verifyDigest verifies a signature over an h digest
+func verifyDigest(publicKey crypto.PublicKey, h crypto.Hash, hashed []byte, signature []byte) error {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, h, hashed, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, hashed, signature) {
+			return errECDSAVerify
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// parsePublicKeyDER parses a PKIX DER-encoded RSA or ECDSA P-256 public key
+func parsePublicKeyDER(der []byte) (crypto.PublicKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
 // Destroy securely wipes the private key from memory
-func (kp *KeyPair) Destroy() {
+func (kp *RSAKeyPair) Destroy() {
 	if kp.privateKey != nil {
 		// Zero out the private key components
 		// Note: This provides basic cleanup, but Go's GC may have made copies
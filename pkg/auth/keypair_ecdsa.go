@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// errECDSAVerify is the cause reported when an ECDSA signature does not
+// verify; ecdsa.VerifyASN1 only returns false
+var errECDSAVerify = errors.New("ecdsa: verification error")
+
+// ECDSAKeyPair is an ECDSA P-256 key pair for self-signed authentication,
+// for deployments that mandate FIPS-approved ECDSA keys. Signatures are
+// ASN.1 DER over the SHA-256 digest of the data.
+type ECDSAKeyPair struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  *ecdsa.PublicKey
+}
+
+// GenerateECDSAKeyPair generates a new ECDSA P-256 key pair
+func GenerateECDSAKeyPair() (*ECDSAKeyPair, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+
+	return &ECDSAKeyPair{
+		privateKey: privateKey,
+		publicKey:  &privateKey.PublicKey,
+	}, nil
+}
+
+// NewECDSAKeyPair wraps an existing P-256 private key into an ECDSAKeyPair
+func NewECDSAKeyPair(priv *ecdsa.PrivateKey) (*ECDSAKeyPair, error) {
+	if priv == nil {
+		return nil, fmt.Errorf("private key is nil")
+	}
+	if priv.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", priv.Curve.Params().Name)
+	}
+	return &ECDSAKeyPair{privateKey: priv, publicKey: &priv.PublicKey}, nil
+}
+
+// Algorithm returns AlgorithmECDSAP256
+func (kp *ECDSAKeyPair) Algorithm() string {
+	return AlgorithmECDSAP256
+}
+
+// ExportPrivateKeyPEM returns SEC1 ("EC PRIVATE KEY") PEM for the private key
+func (kp *ECDSAKeyPair) ExportPrivateKeyPEM() (string, error) {
+	if kp.privateKey == nil {
+		return "", fmt.Errorf("private key is nil")
+	}
+	b, err := x509.MarshalECPrivateKey(kp.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+	return string(pemBytes), nil
+}
+
+// SavePrivateKeyPEMFile saves private key PEM to file with 0600 perms
+func (kp *ECDSAKeyPair) SavePrivateKeyPEMFile(path string) error {
+	pemStr, err := kp.ExportPrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(pemStr), 0600)
+}
+
+// Sign signs the SHA-256 digest of data, returning an ASN.1 DER signature
+func (kp *ECDSAKeyPair) Sign(data []byte) ([]byte, error) {
+	if kp.privateKey == nil {
+		return nil, fmt.Errorf("private key is nil")
+	}
+
+	hashed := sha256.Sum256(data)
+	signature, err := ecdsa.SignASN1(rand.Reader, kp.privateKey, hashed[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+
+	return signature, nil
+}
+
+// Verify verifies an ASN.1 DER signature using the public key
+func (kp *ECDSAKeyPair) Verify(data []byte, signature []byte) error {
+	if kp.publicKey == nil {
+		return fmt.Errorf("public key is nil")
+	}
+
+	hashed := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(kp.publicKey, hashed[:], signature) {
+		return fmt.Errorf("signature verification failed: %w", errECDSAVerify)
+	}
+
+	return nil
+}
+
+// GetPublicKeyPEM exports the public key in PEM format
+func (kp *ECDSAKeyPair) GetPublicKeyPEM() (string, error) {
+	der, err := kp.GetPublicKeyDER()
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// GetPublicKeyDER exports the public key in PKIX DER format
+func (kp *ECDSAKeyPair) GetPublicKeyDER() ([]byte, error) {
+	if kp.publicKey == nil {
+		return nil, fmt.Errorf("public key is nil")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(kp.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return der, nil
+}
+
+// GetFingerprint returns the SHA-256 fingerprint of the public key, computed
+// like RSAKeyPair.GetFingerprint
+func (kp *ECDSAKeyPair) GetFingerprint() (string, error) {
+	der, err := kp.GetPublicKeyDER()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// Destroy wipes the private scalar from memory
+func (kp *ECDSAKeyPair) Destroy() {
+	if kp.privateKey != nil && kp.privateKey.D != nil {
+		// Basic cleanup; Go's GC may have made copies
+		kp.privateKey.D.SetInt64(0)
+	}
+	kp.privateKey = nil
+	kp.publicKey = nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrReadOnly is returned when saving to a key store that cannot be written
+var ErrReadOnly = errors.New("key store is read-only")
+
+// KeyProvider fetches and stores the private key PEM in an external secret
+// store, such as Vault or a cloud KMS-backed secret manager. Wrap it with
+// NewProviderKeyStore to use it as a KeyStore.
+type KeyProvider interface {
+	// GetPrivateKeyPEM returns the stored private key PEM, or ErrNoKey
+	GetPrivateKeyPEM() ([]byte, error)
+
+	// PutPrivateKeyPEM stores the private key PEM, replacing any previous
+	// one, or returns ErrReadOnly if the provider cannot store keys
+	PutPrivateKeyPEM(pemData []byte) error
+}
+
+// NewProviderKeyStore returns a KeyStore keeping the key pair in p
+func NewProviderKeyStore(p KeyProvider) KeyStore {
+	return providerKeyStore{p}
+}
+
+// providerKeyStore adapts a KeyProvider to a KeyStore
+type providerKeyStore struct {
+	p KeyProvider
+}
+
+// Load implements KeyStore
+func (s providerKeyStore) Load() (KeyPair, error) {
+	data, err := s.p.GetPrivateKeyPEM()
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+// Save implements KeyStore
+func (s providerKeyStore) Save(kp KeyPair) error {
+	keyPEM, err := kp.ExportPrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	return s.p.PutPrivateKeyPEM([]byte(keyPEM))
+}
+
+// NewEnvKeyStore returns a read-only KeyStore reading the private key from
+// the environment variable name, as PEM or as base64-encoded PEM (which
+// fits on one line). The key must be provisioned beforehand, e.g. from a
+// secret manager by the orchestrator; Save returns ErrReadOnly.
+func NewEnvKeyStore(name string) KeyStore {
+	return NewProviderKeyStore(envKeyProvider(name))
+}
+
+// envKeyProvider is a KeyProvider reading an environment variable
+type envKeyProvider string
+
+// GetPrivateKeyPEM implements KeyProvider
+func (name envKeyProvider) GetPrivateKeyPEM() ([]byte, error) {
+	value := strings.TrimSpace(os.Getenv(string(name)))
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set: %w", string(name), ErrNoKey)
+	}
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s is neither PEM nor base64: %w", string(name), err)
+	}
+	return data, nil
+}
+
+// PutPrivateKeyPEM implements KeyProvider
+func (name envKeyProvider) PutPrivateKeyPEM([]byte) error {
+	return fmt.Errorf("set environment variable %s to the private key PEM: %w", string(name), ErrReadOnly)
+}
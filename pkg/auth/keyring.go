@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrKeyringUnavailable is returned when the OS keyring is not supported on
+// this platform or its tooling is not installed
+var ErrKeyringUnavailable = errors.New("OS keyring unavailable")
+
+// NewKeyringStore returns a KeyStore keeping the private key in the OS
+// keyring under the given service and account names:
+//
+//   - macOS: a generic password in the login Keychain, via security(1)
+//   - Linux: the Secret Service (GNOME Keyring, KWallet), via secret-tool(1)
+//   - Windows: a file under the user's config directory, encrypted with
+//     DPAPI for the current user
+//
+// On other platforms, or if the tooling is missing, Load and Save return
+// ErrKeyringUnavailable.
+func NewKeyringStore(service, account string) KeyStore {
+	return NewProviderKeyStore(&keyringProvider{service: service, account: account})
+}
+
+// keyringProvider is a KeyProvider backed by the OS keyring; its methods
+// live in the keyring_<os>.go files
+type keyringProvider struct {
+	service string
+	account string
+}
+
+// String returns the service and account names, for errors
+func (k *keyringProvider) String() string {
+	return fmt.Sprintf("keyring item %s/%s", k.service, k.account)
+}
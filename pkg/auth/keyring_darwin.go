@@ -0,0 +1,57 @@
+//go:build darwin
+
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit status of security(1) for a missing item
+const errSecItemNotFound = 44
+
+// GetPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) GetPrivateKeyPEM() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("security", "find-generic-password", "-s", k.service, "-a", k.account, "-w")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(err, exec.ErrNotFound):
+			return nil, fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound:
+			return nil, ErrNoKey
+		}
+		return nil, fmt.Errorf("%s: %w: %s", k, err, strings.TrimSpace(stderr.String()))
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+}
+
+// PutPrivateKeyPEM implements KeyProvider. The command is fed to
+// "security -i" on stdin, so the key never appears in a process listing.
+func (k *keyringProvider) PutPrivateKeyPEM(pemData []byte) error {
+	for _, name := range []string{k.service, k.account} {
+		if strings.ContainsAny(name, "\"\\\n") {
+			return fmt.Errorf("%s: names must not contain quotes, backslashes or newlines", k)
+		}
+	}
+	command := fmt.Sprintf("add-generic-password -U -s \"%s\" -a \"%s\" -w \"%s\"\n",
+		k.service, k.account, base64.StdEncoding.EncodeToString(pemData))
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+		}
+		return fmt.Errorf("%s: %w: %s", k, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) GetPrivateKeyPEM() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", k.service, "account", k.account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(err, exec.ErrNotFound):
+			return nil, fmt.Errorf("%s: secret-tool not installed: %w", k, ErrKeyringUnavailable)
+		case errors.As(err, &exitErr) && stdout.Len() == 0 && stderr.Len() == 0:
+			// secret-tool exits 1 silently when nothing matches
+			return nil, ErrNoKey
+		}
+		return nil, fmt.Errorf("%s: %w: %s", k, err, strings.TrimSpace(stderr.String()))
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+}
+
+// PutPrivateKeyPEM implements KeyProvider. secret-tool reads the secret
+// from stdin, so the key never appears in a process listing.
+func (k *keyringProvider) PutPrivateKeyPEM(pemData []byte) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label", "LCC SDK key ("+k.service+")",
+		"service", k.service, "account", k.account)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(pemData))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s: secret-tool not installed: %w", k, ErrKeyringUnavailable)
+		}
+		return fmt.Errorf("%s: %w: %s", k, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
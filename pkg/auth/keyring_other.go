@@ -0,0 +1,15 @@
+//go:build !darwin && !linux && !windows
+
+package auth
+
+import "fmt"
+
+// GetPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) GetPrivateKeyPEM() ([]byte, error) {
+	return nil, fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+}
+
+// PutPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) PutPrivateKeyPEM([]byte) error {
+	return fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+}
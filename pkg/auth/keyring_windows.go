@@ -0,0 +1,100 @@
+//go:build windows
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	crypt32                = syscall.NewLazyDLL("crypt32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = crypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = kernel32.NewProc("LocalFree")
+)
+
+// cryptProtectUIForbidden fails instead of prompting the user
+const cryptProtectUIForbidden = 0x1
+
+// dataBlob is the DPAPI DATA_BLOB structure
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newDataBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{cbData: uint32(len(b)), pbData: &b[0]}
+}
+
+// bytes copies the blob out of the buffer DPAPI allocated, then frees it
+func (b *dataBlob) bytes() []byte {
+	out := make([]byte, b.cbData)
+	copy(out, unsafe.Slice(b.pbData, b.cbData))
+	procLocalFree.Call(uintptr(unsafe.Pointer(b.pbData)))
+	return out
+}
+
+// path returns the file holding the encrypted key
+func (k *keyringProvider) path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", k, err)
+	}
+	return filepath.Join(dir, k.service, k.account+".key.dpapi"), nil
+}
+
+// GetPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) GetPrivateKeyPEM() ([]byte, error) {
+	path, err := k.path()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := procCryptUnprotectData.Find(); err != nil {
+		return nil, fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+	}
+
+	var out dataBlob
+	r, _, callErr := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob(encrypted))), 0, 0, 0, 0,
+		cryptProtectUIForbidden, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, fmt.Errorf("%s: CryptUnprotectData: %w", k, callErr)
+	}
+	return out.bytes(), nil
+}
+
+// PutPrivateKeyPEM implements KeyProvider
+func (k *keyringProvider) PutPrivateKeyPEM(pemData []byte) error {
+	path, err := k.path()
+	if err != nil {
+		return err
+	}
+	if err := procCryptProtectData.Find(); err != nil {
+		return fmt.Errorf("%s: %w", k, ErrKeyringUnavailable)
+	}
+
+	var out dataBlob
+	r, _, callErr := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob(pemData))), 0, 0, 0, 0,
+		cryptProtectUIForbidden, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return fmt.Errorf("%s: CryptProtectData: %w", k, callErr)
+	}
+	return writeFileAtomic(path, out.bytes())
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoKey is returned by KeyStore.Load when no key has been saved yet
+var ErrNoKey = errors.New("no key stored")
+
+// KeyStore persists an instance's private key, so the instance ID (the key
+// fingerprint) survives restarts
+type KeyStore interface {
+	// Load returns the saved key pair, or ErrNoKey
+	Load() (KeyPair, error)
+
+	// Save replaces the saved key pair atomically
+	Save(kp KeyPair) error
+}
+
+// LoadOrCreateKeyPair loads the key pair saved in ks, or generates one with
+// algorithm (see GenerateKeyPairForAlgorithm) and saves it. A saved key is
+// used whatever its algorithm.
+func LoadOrCreateKeyPair(ks KeyStore, algorithm string) (KeyPair, error) {
+	kp, err := ks.Load()
+	switch {
+	case err == nil:
+		return kp, nil
+	case !errors.Is(err, ErrNoKey):
+		return nil, fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	kp, err = GenerateKeyPairForAlgorithm(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	if err := ks.Save(kp); err != nil {
+		return nil, fmt.Errorf("failed to save key pair: %w", err)
+	}
+	return kp, nil
+}
+
+// FileKeyStore keeps the private key as PEM in a file. Saves write a
+// temporary file with 0600 permissions, sync it and rename it over the old
+// one, so a crash never leaves a partial key behind.
+type FileKeyStore struct {
+	path string
+}
+
+// NewFileKeyStore returns a key store for the file at path. Its directory is
+// created (0700) on the first Save if it does not exist.
+func NewFileKeyStore(path string) *FileKeyStore {
+	return &FileKeyStore{path: path}
+}
+
+// Path returns the key file path
+func (s *FileKeyStore) Path() string {
+	return s.path
+}
+
+// Load implements KeyStore. The file may hold any format ParsePrivateKeyPEM
+// accepts.
+func (s *FileKeyStore) Load() (KeyPair, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	kp, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return kp, nil
+}
+
+// Save implements KeyStore
+func (s *FileKeyStore) Save(kp KeyPair) error {
+	keyPEM, err := kp.ExportPrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, []byte(keyPEM))
+}
+
+// writeFileAtomic writes data to a temporary file with 0600 permissions,
+// syncs it and renames it over path, creating the directory (0700) if needed
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
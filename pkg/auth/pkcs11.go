@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// PKCS#11 signing mechanisms used by PKCS11Signer
+const (
+	MechanismRSAPKCS uint = 0x00000001 // CKM_RSA_PKCS
+	MechanismECDSA   uint = 0x00001041 // CKM_ECDSA
+)
+
+// digestInfoPrefixes are the DER DigestInfo headers CKM_RSA_PKCS expects
+// in front of a digest
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {
+		0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01,
+		0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20,
+	},
+	crypto.SHA384: {
+		0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01,
+		0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30,
+	},
+	crypto.SHA512: {
+		0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01,
+		0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40,
+	},
+}
+
+// PKCS11Session is the part of a logged-in PKCS#11 session PKCS11Signer
+// needs. Implement it over a PKCS#11 binding (such as
+// github.com/miekg/pkcs11) with the private key object already found, so
+// the SDK itself does not depend on cgo.
+type PKCS11Session interface {
+	// Sign runs C_SignInit with mechanism on the private key, then C_Sign
+	// over data, and returns the raw signature
+	Sign(mechanism uint, data []byte) ([]byte, error)
+}
+
+// PKCS11Signer is a crypto.Signer backed by a private key on a PKCS#11
+// token (HSM, smart card). It signs SHA-2 digests with CKM_RSA_PKCS for
+// RSA keys and CKM_ECDSA for P-256 keys, converting ECDSA signatures to
+// ASN.1 DER. Wrap it with NewSignerKeyPair to sign requests.
+type PKCS11Signer struct {
+	session PKCS11Session
+	public  crypto.PublicKey
+}
+
+// NewPKCS11Signer returns a signer using session, whose private key matches
+// public (an *rsa.PublicKey or a P-256 *ecdsa.PublicKey, e.g. read from the
+// token or its certificate)
+func NewPKCS11Signer(session PKCS11Session, public crypto.PublicKey) (*PKCS11Signer, error) {
+	if session == nil {
+		return nil, fmt.Errorf("PKCS#11 session is nil")
+	}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+	return &PKCS11Signer{session: session, public: public}, nil
+}
+
+// Public implements crypto.Signer
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer for SHA-256, SHA-384 and SHA-512 digests.
+// rand is unused: the token supplies its own randomness.
+func (s *PKCS11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[opts.HashFunc()]
+	if _, pss := opts.(*rsa.PSSOptions); pss || !ok {
+		return nil, fmt.Errorf("PKCS#11 signer supports only SHA-2 PKCS#1 v1.5 and ECDSA signatures")
+	}
+	if len(digest) != opts.HashFunc().Size() {
+		return nil, fmt.Errorf("digest length %d, want %d", len(digest), opts.HashFunc().Size())
+	}
+
+	if _, ok := s.public.(*rsa.PublicKey); ok {
+		data := append(append([]byte{}, prefix...), digest...)
+		signature, err := s.session.Sign(MechanismRSAPKCS, data)
+		if err != nil {
+			return nil, fmt.Errorf("PKCS#11 sign failed: %w", err)
+		}
+		return signature, nil
+	}
+
+	raw, err := s.session.Sign(MechanismECDSA, digest)
+	if err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign failed: %w", err)
+	}
+	// CKM_ECDSA returns r and s as fixed-width big-endian halves
+	if len(raw) != 64 {
+		return nil, fmt.Errorf("PKCS#11 ECDSA signature length %d, want 64", len(raw))
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(raw[:32]),
+		S: new(big.Int).SetBytes(raw[32:]),
+	})
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// HeaderServerSignature carries the server's signature of a response, see
+// BuildResponseCanonicalString
+const HeaderServerSignature = "X-LCC-Server-Signature"
+
+// responseScheme prefixes the canonical string of a signed response
+const responseScheme = "lcc-response-v1"
+
+// ErrResponseSignature is returned when a response is unsigned or its
+// signature does not verify against the pinned server key
+var ErrResponseSignature = errors.New("invalid server response signature")
+
+// BuildResponseCanonicalString builds the string a server signs for a
+// response: "lcc-response-v1\nNONCE\nSTATUS\nBODY_SHA256", where NONCE is the
+// X-LCC-Nonce of the request being answered. Binding the nonce keeps a
+// recorded response from being replayed to another request.
+func BuildResponseCanonicalString(nonce string, status int, bodyHash string) string {
+	return responseScheme + "\n" + nonce + "\n" + strconv.Itoa(status) + "\n" + bodyHash
+}
+
+// SignResponse signs a response to the request carrying nonce and returns
+// the hex value of its X-LCC-Server-Signature header. It is the server side
+// of VerifyResponse.
+func SignResponse(kp KeyPair, nonce string, status int, body []byte) (string, error) {
+	canonical := BuildResponseCanonicalString(nonce, status, ComputeBodyHash(body))
+	signature, err := kp.Sign([]byte(canonical))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign response: %w", err)
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// VerifyResponse checks the X-LCC-Server-Signature header of resp against
+// the server public key. body is the response body, which the caller has
+// already read; the nonce is taken from resp.Request.
+func VerifyResponse(publicKey crypto.PublicKey, resp *http.Response, body []byte) error {
+	if resp.Request == nil {
+		return fmt.Errorf("%w: response has no request", ErrResponseSignature)
+	}
+	nonce := resp.Request.Header.Get("X-LCC-Nonce")
+	if nonce == "" {
+		return fmt.Errorf("%w: request is not signed", ErrResponseSignature)
+	}
+	signatureHex := resp.Header.Get(HeaderServerSignature)
+	if signatureHex == "" {
+		return fmt.Errorf("%w: missing %s header", ErrResponseSignature, HeaderServerSignature)
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature encoding: %v", ErrResponseSignature, err)
+	}
+
+	canonical := BuildResponseCanonicalString(nonce, resp.StatusCode, ComputeBodyHash(body))
+	if err := VerifySignature(publicKey, []byte(canonical), signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrResponseSignature, err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
@@ -9,21 +11,62 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// RequestSigner signs HTTP requests with RSA signatures
+// RequestSigner signs HTTP requests with the signatures of its KeyPair
 type RequestSigner struct {
-	keyPair *KeyPair
+	keyPair     KeyPair
+	canon       PathCanonicalizer
+	certificate string
+	nonces      IDGenerator
+	clock       Clock
+
+	// Signing scheme (0 is SignatureV1) and extra v2 signed headers
+	version       int
+	signedHeaders []string
+
+	// Signature scheme of RSA keys ("" is SchemeRSAPKCS1v15)
+	scheme string
+
+	// Hash of body hashes and signatures ("" is HashSHA256)
+	hashAlgorithm string
+}
+
+// SignerOption configures a RequestSigner
+type SignerOption func(*RequestSigner)
+
+// WithPathCanonicalizer sets how the request path is canonicalized before
+// signing (e.g., stripping a gateway prefix).
+func WithPathCanonicalizer(p PathCanonicalizer) SignerOption {
+	return func(s *RequestSigner) {
+		s.canon = p
+	}
 }
 
 // NewRequestSigner creates a new request signer with the given key pair
-func NewRequestSigner(keyPair *KeyPair) *RequestSigner {
-	return &RequestSigner{
+func NewRequestSigner(keyPair KeyPair, opts ...SignerOption) *RequestSigner {
+	s := &RequestSigner{
 		keyPair: keyPair,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewRequestSignerFromSigner creates a request signer whose private key is
+// held by signer, e.g. a PKCS11Signer or a cloud KMS client (see
+// NewSignerKeyPair)
+func NewRequestSignerFromSigner(signer crypto.Signer, opts ...SignerOption) (*RequestSigner, error) {
+	kp, err := NewSignerKeyPair(signer)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequestSigner(kp, opts...), nil
 }
 
 // SignRequest signs an HTTP request and adds authentication headers
@@ -32,11 +75,8 @@ func NewRequestSigner(keyPair *KeyPair) *RequestSigner {
 //   - X-LCC-Timestamp: Unix timestamp in seconds
 //   - X-LCC-Nonce: Unique nonce (UUID)
 //   - X-LCC-Signature: Hex-encoded signature
+//   - X-LCC-Hash: The hash algorithm, unless HashSHA256
 func (s *RequestSigner) SignRequest(req *http.Request) error {
-	// Generate timestamp and nonce
-	timestamp := time.Now().Unix()
-	nonce := uuid.New().String()
-
 	// Read and hash request body
 	var bodyHash string
 	if req.Body != nil {
@@ -45,31 +85,107 @@ func (s *RequestSigner) SignRequest(req *http.Request) error {
 			return fmt.Errorf("failed to read request body: %w", err)
 		}
 
-		// Compute SHA-256 of body
-		hash := sha256.Sum256(bodyBytes)
-		bodyHash = hex.EncodeToString(hash[:])
+		if bodyHash, err = s.BodyHash(bodyBytes); err != nil {
+			return err
+		}
 
 		// Restore body for actual request
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		req.ContentLength = int64(len(bodyBytes))
 	} else {
 		// Empty body hash
-		emptyHash := sha256.Sum256([]byte{})
-		bodyHash = hex.EncodeToString(emptyHash[:])
+		var err error
+		if bodyHash, err = s.BodyHash(nil); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return s.sign(req, bodyHash)
+}
+
+// SignRequestWithBodyHash signs a request using a body hash computed by the
+// caller, without reading req.Body. This supports large or streaming bodies
+// (e.g., multi-megabyte usage batches read from disk) that should not be
+// buffered in memory. Use ComputeBodyHashFromReader to hash the payload,
+// or BodyHash with a hash other than HashSHA256.
+//
+// The hash is also sent in the X-LCC-Content-SHA256 header (X-LCC-Content-Hash
+// for other hashes) so servers can verify the body while streaming it (see
+// VerifyOptions.StreamBody).
+func (s *RequestSigner) SignRequestWithBodyHash(req *http.Request, bodyHash string) error {
+	h, err := HashFunc(s.hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	if len(bodyHash) != h.Size()*2 {
+		return fmt.Errorf("invalid body hash: expected %d hex characters", h.Size()*2)
+	}
+	if _, err := hex.DecodeString(bodyHash); err != nil {
+		return fmt.Errorf("invalid body hash: %w", err)
+	}
+
+	req.Header.Set(contentHashHeader(s.hashAlgorithm), bodyHash)
+	return s.sign(req, bodyHash)
+}
+
+// HashAlgorithm returns the hash of the signer's body hashes and signatures
+func (s *RequestSigner) HashAlgorithm() string {
+	if s.hashAlgorithm == "" {
+		return HashSHA256
+	}
+	return s.hashAlgorithm
+}
+
+// BodyHash computes the hex hash of body with the signer's hash algorithm,
+// as SignRequestWithBodyHash expects it
+func (s *RequestSigner) BodyHash(body []byte) (string, error) {
+	return ComputeBodyHashWith(s.hashAlgorithm, body)
+}
+
+// sign computes the signature over the canonical string and sets auth headers
+func (s *RequestSigner) sign(req *http.Request, bodyHash string) error {
+	// Generate timestamp and nonce
+	timestamp := s.now().Unix()
+	nonce := uuid.New().String()
+	if s.nonces != nil {
+		var err error
+		if nonce, err = s.nonces(); err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
 	}
 
 	// Build canonical string
 	// Format: METHOD\nPATH\nBODY_SHA256\nTIMESTAMP\nNONCE
 	canonical := fmt.Sprintf("%s\n%s\n%s\n%d\n%s",
 		req.Method,
-		req.URL.Path,
+		s.canon.SignPath(req),
 		bodyHash,
 		timestamp,
 		nonce,
 	)
+	if s.version == SignatureV2 {
+		query, err := CanonicalQuery(req.URL.RawQuery)
+		if err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+		signedHeaders := s.signedHeaderNames(req.Header)
+		canonical = BuildCanonicalStringV2(req.Method, s.canon.SignPath(req), query, signedHeaders, req.Header, bodyHash, timestamp, nonce)
+		req.Header.Set(HeaderSigVersion, strconv.Itoa(SignatureV2))
+		req.Header.Set(HeaderSignedHeaders, strings.Join(signedHeaders, ";"))
+	}
 
 	// Sign canonical string
-	signature, err := s.keyPair.Sign([]byte(canonical))
+	h, err := HashFunc(s.hashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	var signature []byte
+	if h == crypto.SHA256 {
+		signature, err = signWithScheme(s.keyPair, s.scheme, []byte(canonical))
+	} else {
+		signature, err = signDigestWithScheme(s.keyPair, s.scheme, h, []byte(canonical))
+	}
 	if err != nil {
 		return fmt.Errorf("failed to sign request: %w", err)
 	}
@@ -88,14 +204,123 @@ func (s *RequestSigner) SignRequest(req *http.Request) error {
 	req.Header.Set("X-LCC-Timestamp", strconv.FormatInt(timestamp, 10))
 	req.Header.Set("X-LCC-Nonce", nonce)
 	req.Header.Set("X-LCC-Signature", hex.EncodeToString(signature))
-	req.Header.Set("Content-Type", "application/json")
+	if s.certificate != "" {
+		req.Header.Set(HeaderCertificate, s.certificate)
+	}
+	if s.scheme == SchemeRSAPSS {
+		req.Header.Set(HeaderSigScheme, s.scheme)
+	}
+	if h != crypto.SHA256 {
+		req.Header.Set(HeaderHash, hashName(h))
+	}
 
 	return nil
 }
 
+// VerifyOptions configures server-side request verification
+type VerifyOptions struct {
+	// Canonicalizer controls which path(s) the signature may cover
+	Canonicalizer PathCanonicalizer
+
+	// StreamBody verifies requests carrying X-LCC-Content-SHA256 without
+	// buffering the body: the signature is checked against the declared hash
+	// and req.Body is replaced with a reader that fails with
+	// ErrBodyHashMismatch at EOF if the streamed body does not match.
+	StreamBody bool
+
+	// MaxAge is how old a request timestamp may be (default
+	// DefaultMaxAge), MaxFutureSkew how far ahead of the verifier's clock
+	// (default DefaultMaxFutureSkew). Widen them for clients with poorly
+	// synchronized clocks, at the cost of a longer replay window.
+	MaxAge        time.Duration
+	MaxFutureSkew time.Duration
+
+	// Clock is the verifier's time source (default SystemClock)
+	Clock Clock
+
+	// MinSignatureVersion rejects requests signed with an older scheme,
+	// e.g. SignatureV2 once every client covers the query string. Requests
+	// announcing no version are SignatureV1.
+	MinSignatureVersion int
+
+	// RequirePSS rejects RSA signatures not announcing SchemeRSAPSS, once
+	// every client signs with it
+	RequirePSS bool
+
+	// HashAlgorithms are the accepted hash algorithms of body hashes and
+	// signatures, preferred first (default: all supported, see
+	// SupportedHashes). Drop HashSHA256 once every client signs with a
+	// stronger hash.
+	HashAlgorithms []string
+}
+
+// acceptedHashes returns the hash algorithms o accepts
+func (o VerifyOptions) acceptedHashes() []string {
+	if len(o.HashAlgorithms) == 0 {
+		return supportedHashes
+	}
+	return o.HashAlgorithms
+}
+
+// requestHash returns the hash algorithm announced by req and its
+// crypto.Hash, or an error if o does not accept it
+func (o VerifyOptions) requestHash(req *http.Request) (string, crypto.Hash, error) {
+	algorithm := strings.ToLower(req.Header.Get(HeaderHash))
+	if algorithm == "" {
+		algorithm = HashSHA256
+	}
+	h, err := HashFunc(algorithm)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, a := range o.acceptedHashes() {
+		if a == algorithm {
+			return algorithm, h, nil
+		}
+	}
+	return "", 0, fmt.Errorf("%w: %s", ErrHashNotAccepted, algorithm)
+}
+
+// checkTimestamp rejects a timestamp outside the accepted window
+func (o VerifyOptions) checkTimestamp(timestamp int64) error {
+	maxAge, maxFuture := o.MaxAge, o.MaxFutureSkew
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if maxFuture <= 0 {
+		maxFuture = DefaultMaxFutureSkew
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	now := clock.Now().Unix()
+	if now-timestamp > int64(maxAge/time.Second) || timestamp-now > int64(maxFuture/time.Second) {
+		return fmt.Errorf("timestamp out of range (diff: %d seconds)", now-timestamp)
+	}
+	return nil
+}
+
 // VerifyRequest verifies the signature of an HTTP request
 // This is used server-side to verify client requests
 func VerifyRequest(req *http.Request) error {
+	return VerifyRequestWithOptions(req, VerifyOptions{})
+}
+
+// VerifyRequestWithOptions verifies the signature of an HTTP request using
+// the given options, e.g. for servers deployed behind a path-rewriting proxy.
+func VerifyRequestWithOptions(req *http.Request, opts VerifyOptions) error {
+	return verifyRequest(req, opts, func(_ *http.Request, publicKeyPEM []byte) (crypto.PublicKey, error) {
+		return ParsePublicKeyPEM(publicKeyPEM)
+	})
+}
+
+// keyResolver turns the PEM presented in X-LCC-PublicKey into the key used
+// for verification
+type keyResolver func(req *http.Request, publicKeyPEM []byte) (crypto.PublicKey, error)
+
+// verifyRequest implements request verification with a pluggable key resolver
+func verifyRequest(req *http.Request, opts VerifyOptions, resolve keyResolver) error {
 	// Extract headers
 	publicKeyBase64 := req.Header.Get("X-LCC-PublicKey")
 	timestampStr := req.Header.Get("X-LCC-Timestamp")
@@ -112,44 +337,51 @@ func VerifyRequest(req *http.Request) error {
 		return fmt.Errorf("invalid timestamp: %w", err)
 	}
 
-	// Verify timestamp is recent (within 5 minutes)
-	now := time.Now().Unix()
-	if now-timestamp > 300 || timestamp-now > 60 {
-		return fmt.Errorf("timestamp out of range (diff: %d seconds)", now-timestamp)
+	// Verify timestamp is recent (within 5 minutes by default)
+	if err := opts.checkTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	// The hash algorithm must be accepted
+	algorithm, h, err := opts.requestHash(req)
+	if err != nil {
+		return err
 	}
 
 	// Read and hash request body
 	var bodyHash string
-	if req.Body != nil {
+	declaredHash := req.Header.Get(contentHashHeader(algorithm))
+	if opts.StreamBody && declaredHash != "" && req.Body != nil {
+		// Verified lazily as the handler reads the body
+		bodyHash = strings.ToLower(declaredHash)
+		req.Body = newVerifyingReader(req.Body, h, bodyHash)
+	} else if req.Body != nil {
 		bodyBytes, err := io.ReadAll(req.Body)
 		if err != nil {
 			return fmt.Errorf("failed to read request body: %w", err)
 		}
 
-		hash := sha256.Sum256(bodyBytes)
-		bodyHash = hex.EncodeToString(hash[:])
+		bodyHash = hex.EncodeToString(digest(h, bodyBytes))
 
 		// Restore body
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+		if declaredHash != "" && !strings.EqualFold(declaredHash, bodyHash) {
+			return ErrBodyHashMismatch
+		}
 	} else {
-		emptyHash := sha256.Sum256([]byte{})
-		bodyHash = hex.EncodeToString(emptyHash[:])
+		bodyHash = hex.EncodeToString(digest(h, nil))
 	}
 
-	// Rebuild canonical string
-	canonical := fmt.Sprintf("%s\n%s\n%s\n%s\n%s",
-		req.Method,
-		req.URL.Path,
-		bodyHash,
-		timestampStr,
-		nonce,
-	)
-
 	// Decode public key
 	publicKeyPEM, err := base64.StdEncoding.DecodeString(publicKeyBase64)
 	if err != nil {
 		return fmt.Errorf("failed to decode public key: %w", err)
 	}
+	publicKey, err := resolve(req, publicKeyPEM)
+	if err != nil {
+		return err
+	}
 
 	// Decode signature
 	signature, err := hex.DecodeString(signatureHex)
@@ -157,12 +389,52 @@ func VerifyRequest(req *http.Request) error {
 		return fmt.Errorf("failed to decode signature: %w", err)
 	}
 
-	// Verify signature
-	if err := VerifySignatureWithPublicKey(publicKeyPEM, []byte(canonical), signature); err != nil {
-		return fmt.Errorf("signature verification failed: %w", err)
+	// Build the canonical string of the announced scheme
+	canonicalFor := func(path string) string {
+		return fmt.Sprintf("%s\n%s\n%s\n%s\n%s",
+			req.Method,
+			path,
+			bodyHash,
+			timestampStr,
+			nonce,
+		)
+	}
+	version := SignatureV1
+	if v := req.Header.Get(HeaderSigVersion); v != "" {
+		if version, err = strconv.Atoi(v); err != nil || version < SignatureV1 || version > SignatureV2 {
+			return fmt.Errorf("unsupported signature version %q", v)
+		}
+	}
+	if version < opts.MinSignatureVersion {
+		return fmt.Errorf("signature version %d not accepted (minimum %d)", version, opts.MinSignatureVersion)
+	}
+	if version == SignatureV2 {
+		query, err := CanonicalQuery(req.URL.RawQuery)
+		if err != nil {
+			return err
+		}
+		signedHeaders := parseSignedHeaders(req.Header.Get(HeaderSignedHeaders))
+		canonicalFor = func(path string) string {
+			return canonicalV2(req.Method, path, query, signedHeaders, req.Header, bodyHash, timestampStr, nonce)
+		}
 	}
 
-	return nil
+	// RSA keys may announce PSS; the default stays PKCS#1 v1.5
+	scheme := req.Header.Get(HeaderSigScheme)
+	if _, isRSA := publicKey.(*rsa.PublicKey); isRSA && opts.RequirePSS && scheme != SchemeRSAPSS {
+		return fmt.Errorf("signature scheme %s required", SchemeRSAPSS)
+	}
+
+	// Verify signature against each acceptable canonical path
+	var verifyErr error
+	for _, path := range opts.Canonicalizer.VerifyPaths(req) {
+		hashed := digest(h, []byte(canonicalFor(path)))
+		if verifyErr = verifyDigestWithScheme(publicKey, scheme, h, hashed, signature); verifyErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature verification failed: %w", verifyErr)
 }
 
 // BuildCanonicalString builds the canonical string for signing
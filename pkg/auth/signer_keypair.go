@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrKeyNotExportable is returned when exporting a private key held by an
+// external signer, such as an HSM or a cloud KMS
+var ErrKeyNotExportable = errors.New("private key is not exportable")
+
+// SignerKeyPair is a KeyPair whose private key is held by a crypto.Signer,
+// such as a PKCS#11 token (see PKCS11Signer) or a cloud KMS client, so it
+// never exists in process memory. The signer must hold an RSA or ECDSA
+// P-256 key and produce the same signatures as RSAKeyPair and ECDSAKeyPair
+// for a SHA-256 digest: PKCS#1 v1.5 or ASN.1 DER.
+type SignerKeyPair struct {
+	signer    crypto.Signer
+	algorithm string
+}
+
+// NewSignerKeyPair wraps signer into a KeyPair
+func NewSignerKeyPair(signer crypto.Signer) (*SignerKeyPair, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signer is nil")
+	}
+	var algorithm string
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		algorithm = AlgorithmRSA
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+		}
+		algorithm = AlgorithmECDSAP256
+	default:
+		return nil, fmt.Errorf("unsupported signer public key type %T", pub)
+	}
+	return &SignerKeyPair{signer: signer, algorithm: algorithm}, nil
+}
+
+// Signer returns the wrapped signer
+func (kp *SignerKeyPair) Signer() crypto.Signer {
+	return kp.signer
+}
+
+// Algorithm returns AlgorithmRSA or AlgorithmECDSAP256, after the signer's
+// public key
+func (kp *SignerKeyPair) Algorithm() string {
+	return kp.algorithm
+}
+
+// Sign has the signer sign the SHA-256 digest of data
+func (kp *SignerKeyPair) Sign(data []byte) ([]byte, error) {
+	if kp.signer == nil {
+		return nil, fmt.Errorf("signer is nil")
+	}
+
+	hashed := sha256.Sum256(data)
+	signature, err := kp.signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign data: %w", err)
+	}
+
+	return signature, nil
+}
+
+// Verify verifies a signature using the signer's public key
+func (kp *SignerKeyPair) Verify(data []byte, signature []byte) error {
+	if kp.signer == nil {
+		return fmt.Errorf("signer is nil")
+	}
+	return VerifySignature(kp.signer.Public(), data, signature)
+}
+
+// GetPublicKeyPEM exports the public key in PEM format
+func (kp *SignerKeyPair) GetPublicKeyPEM() (string, error) {
+	der, err := kp.GetPublicKeyDER()
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// GetPublicKeyDER exports the public key in PKIX DER format
+func (kp *SignerKeyPair) GetPublicKeyDER() ([]byte, error) {
+	if kp.signer == nil {
+		return nil, fmt.Errorf("signer is nil")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(kp.signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return der, nil
+}
+
+// GetFingerprint returns the SHA-256 fingerprint of the public key, computed
+// like RSAKeyPair.GetFingerprint
+func (kp *SignerKeyPair) GetFingerprint() (string, error) {
+	der, err := kp.GetPublicKeyDER()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// ExportPrivateKeyPEM returns ErrKeyNotExportable
+func (kp *SignerKeyPair) ExportPrivateKeyPEM() (string, error) {
+	return "", ErrKeyNotExportable
+}
+
+// SavePrivateKeyPEMFile returns ErrKeyNotExportable
+func (kp *SignerKeyPair) SavePrivateKeyPEMFile(string) error {
+	return ErrKeyNotExportable
+}
+
+// Destroy drops the reference to the signer. Closing the underlying
+// session or client is left to its owner.
+func (kp *SignerKeyPair) Destroy() {
+	kp.signer = nil
+}
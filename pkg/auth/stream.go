@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// HeaderContentSHA256 carries the hex SHA-256 of a request body signed with
+// SignRequestWithBodyHash.
+const HeaderContentSHA256 = "X-LCC-Content-SHA256"
+
+// ErrBodyHashMismatch is returned when a request body does not match the
+// hash covered by its signature.
+var ErrBodyHashMismatch = errors.New("request body does not match signed hash")
+
+// ComputeBodyHashFromReader computes the hex SHA-256 of everything read from
+// r, without holding it in memory.
+func ComputeBodyHashFromReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to hash body: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyingReader hashes a body while it is read and reports
+// ErrBodyHashMismatch at EOF if it does not match the expected hash.
+type verifyingReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func newVerifyingReader(body io.ReadCloser, h crypto.Hash, expected string) *verifyingReader {
+	return &verifyingReader{body: body, hash: h.New(), expected: expected}
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		return n, ErrBodyHashMismatch
+	}
+	return n, err
+}
+
+func (r *verifyingReader) Close() error {
+	return r.body.Close()
+}
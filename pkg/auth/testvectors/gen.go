@@ -0,0 +1,59 @@
+//go:build ignore
+
+// gen regenerates vectors.json. It keeps the keys already published there
+// unless -new-keys is given.
+//
+// Usage (from this directory, or via go generate):
+//
+//	go run gen.go [-new-keys]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth/testvectors"
+)
+
+func main() {
+	newKeys := flag.Bool("new-keys", false, "generate new keys instead of keeping the published ones")
+	out := flag.String("o", "vectors.json", "output file")
+	flag.Parse()
+
+	if err := run(*out, *newKeys); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, newKeys bool) error {
+	var keys []testvectors.Key
+	if newKeys {
+		var err error
+		if keys, err = testvectors.GenerateKeys(); err != nil {
+			return err
+		}
+	} else {
+		published, err := testvectors.Load()
+		if err != nil {
+			return err
+		}
+		keys = published.Keys
+	}
+
+	set, err := testvectors.Generate(keys)
+	if err != nil {
+		return err
+	}
+	if err := set.Verify(); err != nil {
+		return fmt.Errorf("generated vectors do not verify: %w", err)
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, append(data, '\n'), 0644)
+}
@@ -0,0 +1,211 @@
+package testvectors
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// Key IDs of the published keys
+const (
+	keyRSA   = "rsa-2048"
+	keyECDSA = "ecdsa-p256"
+)
+
+// vectorTimestamp is the fixed signing time of the vectors
+// (2025-01-01T00:00:00Z)
+const vectorTimestamp = 1735689600
+
+// vectorCase describes a vector to generate. tamper, if set, alters the
+// request after signing, making the vector invalid.
+type vectorCase struct {
+	name        string
+	description string
+	key         string
+	method      string
+	url         string
+	stripPrefix string
+	body        string
+	nonce       string
+	tamper      func(v *Vector)
+}
+
+var cases = []vectorCase{
+	{
+		name:        "register",
+		description: "POST with a JSON body",
+		key:         keyRSA,
+		method:      http.MethodPost,
+		url:         "/api/v1/sdk/register",
+		body:        `{"product_id":"demo-app","product_version":"1.0.0"}`,
+		nonce:       "6f1b7c1e-2f0a-4c55-9f39-1d3c7a1b2e40",
+	},
+	{
+		name:        "empty-body",
+		description: "GET without a body signs the SHA-256 of the empty string",
+		key:         keyRSA,
+		method:      http.MethodGet,
+		url:         "/api/v1/sdk/product/status",
+		nonce:       "0b6c2f5e-8d41-4f7a-a1d2-5c9e3b7f6a10",
+	},
+	{
+		name:        "query-not-signed",
+		description: "the query string is not part of the canonical path",
+		key:         keyRSA,
+		method:      http.MethodGet,
+		url:         "/api/v1/sdk/features/export/check?variant=beta",
+		nonce:       "c3a9e0d4-71b2-4e8f-9b6a-2d4f8e1c5a37",
+	},
+	{
+		name:        "escaped-path",
+		description: "the canonical path is the percent-decoded path",
+		key:         keyRSA,
+		method:      http.MethodGet,
+		url:         "/api/v1/sdk/features/caf%C3%A9/check",
+		nonce:       "9e4d2b1a-3c5f-4a6e-8b7d-0f1e2d3c4b5a",
+	},
+	{
+		name:        "gateway-prefix",
+		description: "a gateway prefix is stripped from the canonical path",
+		key:         keyRSA,
+		method:      http.MethodPost,
+		url:         "/lcc/api/v1/sdk/usage",
+		stripPrefix: "/lcc",
+		body:        `{"feature_id":"export","amount":5}`,
+		nonce:       "5a7c9e1b-2d4f-4b6a-8c0e-1f3a5b7d9c2e",
+	},
+	{
+		name:        "ecdsa-register",
+		description: "POST signed with an ECDSA P-256 key (ASN.1 DER signature)",
+		key:         keyECDSA,
+		method:      http.MethodPost,
+		url:         "/api/v1/sdk/register",
+		body:        `{"product_id":"demo-app","product_version":"1.0.0"}`,
+		nonce:       "e2c4a6b8-1d3f-4e5a-9b7c-3d5f7a9b1c4e",
+	},
+	{
+		name:        "tampered-body",
+		description: "the body was changed after signing",
+		key:         keyRSA,
+		method:      http.MethodPost,
+		url:         "/api/v1/sdk/usage",
+		body:        `{"feature_id":"export","amount":5}`,
+		nonce:       "7d9f1b3e-5a7c-4e9b-8d1f-4a6c8e0b2d5f",
+		tamper:      func(v *Vector) { v.Body = `{"feature_id":"export","amount":1}` },
+	},
+	{
+		name:        "tampered-timestamp",
+		description: "the timestamp header was changed after signing",
+		key:         keyECDSA,
+		method:      http.MethodGet,
+		url:         "/api/v1/sdk/product/status",
+		nonce:       "1c3e5a7b-9d2f-4b4e-8a6c-5e7a9c1d3f6b",
+		tamper:      func(v *Vector) { v.Timestamp++ },
+	},
+}
+
+// Generate signs the vectors with keys, which must hold the private keys
+// of the key IDs the vectors use. Public keys and fingerprints are derived
+// from the private keys.
+func Generate(keys []Key) (*Set, error) {
+	s := &Set{Scheme: SchemeV1}
+	pairs := make(map[string]auth.KeyPair)
+	for _, k := range keys {
+		kp, err := auth.ParsePrivateKeyPEM([]byte(k.PrivateKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		k.Algorithm = kp.Algorithm()
+		if k.PublicKeyPEM, err = kp.GetPublicKeyPEM(); err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		if k.Fingerprint, err = kp.GetFingerprint(); err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		pairs[k.ID] = kp
+		s.Keys = append(s.Keys, k)
+	}
+
+	for _, c := range cases {
+		kp, ok := pairs[c.key]
+		if !ok {
+			return nil, fmt.Errorf("vector %s: no key %q", c.name, c.key)
+		}
+		v, err := sign(kp, c)
+		if err != nil {
+			return nil, fmt.Errorf("vector %s: %w", c.name, err)
+		}
+		s.Vectors = append(s.Vectors, v)
+	}
+	return s, nil
+}
+
+// GenerateKeys generates a new key for each published key ID
+func GenerateKeys() ([]Key, error) {
+	var keys []Key
+	for _, id := range []string{keyRSA, keyECDSA} {
+		algorithm := auth.AlgorithmRSA
+		if id == keyECDSA {
+			algorithm = auth.AlgorithmECDSAP256
+		}
+		kp, err := auth.GenerateKeyPairForAlgorithm(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := kp.ExportPrivateKeyPEM()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, Key{ID: id, PrivateKeyPEM: keyPEM})
+	}
+	return keys, nil
+}
+
+// sign builds and signs the vector for c, then applies its tampering
+func sign(kp auth.KeyPair, c vectorCase) (Vector, error) {
+	v := Vector{
+		Name:        c.name,
+		Description: c.description,
+		Key:         c.key,
+		Method:      c.method,
+		URL:         c.url,
+		StripPrefix: c.stripPrefix,
+		Body:        c.body,
+		Timestamp:   vectorTimestamp,
+		Nonce:       c.nonce,
+		Valid:       c.tamper == nil,
+	}
+	req, err := v.Request()
+	if err != nil {
+		return v, err
+	}
+	canonicalPath := auth.PathCanonicalizer{StripPrefix: c.stripPrefix}.SignPath(req)
+	canonical := auth.BuildCanonicalString(v.Method, canonicalPath, auth.ComputeBodyHash([]byte(v.Body)), v.Timestamp, v.Nonce)
+	signature, err := kp.Sign([]byte(canonical))
+	if err != nil {
+		return v, err
+	}
+	publicKeyPEM, err := kp.GetPublicKeyPEM()
+	if err != nil {
+		return v, err
+	}
+
+	if c.tamper != nil {
+		c.tamper(&v)
+	}
+	// The intermediate values are those a verifier computes for the
+	// (possibly tampered) request
+	v.CanonicalPath = canonicalPath
+	v.BodyHash = auth.ComputeBodyHash([]byte(v.Body))
+	v.Canonical = auth.BuildCanonicalString(v.Method, v.CanonicalPath, v.BodyHash, v.Timestamp, v.Nonce)
+	v.Headers = map[string]string{
+		"X-LCC-PublicKey": base64.StdEncoding.EncodeToString([]byte(publicKeyPEM)),
+		"X-LCC-Timestamp": fmt.Sprint(v.Timestamp),
+		"X-LCC-Nonce":     v.Nonce,
+		"X-LCC-Signature": hex.EncodeToString(signature),
+	}
+	return v, nil
+}
@@ -0,0 +1,209 @@
+// Package testvectors publishes request signing test vectors, so server
+// implementations in other languages can check that they verify requests
+// exactly as the SDK signs them.
+//
+// The vectors live in vectors.json next to this file. Each vector gives the
+// inputs of a signed request (method, URL, body, timestamp, nonce and key),
+// the intermediate values (canonical path, body hash, canonical string) and
+// the resulting X-LCC-* headers. A conforming verifier reproduces the
+// intermediate values from the inputs and accepts exactly the vectors marked
+// valid. The keys are test keys published with the vectors; never use them
+// for anything else.
+//
+// RSA signatures (PKCS#1 v1.5) are deterministic, so signers can compare
+// their output byte for byte. ECDSA signatures are randomized: check that
+// they verify instead.
+//
+// After a change to the signing scheme, regenerate the vectors with
+//
+//	go generate ./pkg/auth/testvectors
+//
+// which keeps the published keys and re-signs every vector.
+package testvectors
+
+//go:generate go run gen.go
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// SchemeV1 is the signing scheme of the vectors: the SHA-256 signature of
+// "METHOD\nPATH\nBODY_SHA256\nTIMESTAMP\nNONCE"
+const SchemeV1 = "lcc-request-v1"
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+// Set is a published set of keys and vectors
+type Set struct {
+	Scheme  string   `json:"scheme"`
+	Keys    []Key    `json:"keys"`
+	Vectors []Vector `json:"vectors"`
+}
+
+// Key is a test key. PrivateKeyPEM is PKCS#1 for RSA and SEC1 for ECDSA.
+type Key struct {
+	ID            string `json:"id"`
+	Algorithm     string `json:"algorithm"`
+	PrivateKeyPEM string `json:"private_key_pem"`
+	PublicKeyPEM  string `json:"public_key_pem"`
+	Fingerprint   string `json:"fingerprint"`
+}
+
+// Vector is one signed request
+type Vector struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Inputs
+	Key         string `json:"key"`
+	Method      string `json:"method"`
+	URL         string `json:"url"` // path and query as received by the server
+	StripPrefix string `json:"strip_prefix,omitempty"`
+	Body        string `json:"body"`
+	Timestamp   int64  `json:"timestamp"`
+	Nonce       string `json:"nonce"`
+
+	// Intermediate values
+	CanonicalPath string `json:"canonical_path"`
+	BodyHash      string `json:"body_hash"`
+	Canonical     string `json:"canonical"`
+
+	// Headers are the X-LCC-* headers of the request
+	Headers map[string]string `json:"headers"`
+
+	// Valid reports whether a verifier must accept the request
+	Valid bool `json:"valid"`
+}
+
+// Load returns the published vectors
+func Load() (*Set, error) {
+	var s Set
+	if err := json.Unmarshal(vectorsJSON, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse vectors: %w", err)
+	}
+	return &s, nil
+}
+
+// Key returns the key with the given ID
+func (s *Set) Key(id string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Verify checks every vector against this SDK's implementation of the
+// scheme and returns all mismatches
+func (s *Set) Verify() error {
+	if s.Scheme != SchemeV1 {
+		return fmt.Errorf("unsupported scheme %q", s.Scheme)
+	}
+	var errs []error
+	for _, k := range s.Keys {
+		if err := k.verify(); err != nil {
+			errs = append(errs, fmt.Errorf("key %s: %w", k.ID, err))
+		}
+	}
+	for _, v := range s.Vectors {
+		if err := s.VerifyVector(v); err != nil {
+			errs = append(errs, fmt.Errorf("vector %s: %w", v.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verify checks that the public key and fingerprint match the private key
+func (k Key) verify() error {
+	kp, err := auth.ParsePrivateKeyPEM([]byte(k.PrivateKeyPEM))
+	if err != nil {
+		return err
+	}
+	if kp.Algorithm() != k.Algorithm {
+		return fmt.Errorf("algorithm = %s, key is %s", k.Algorithm, kp.Algorithm())
+	}
+	if pub, _ := kp.GetPublicKeyPEM(); pub != k.PublicKeyPEM {
+		return fmt.Errorf("public key does not match the private key")
+	}
+	if fp, _ := kp.GetFingerprint(); fp != k.Fingerprint {
+		return fmt.Errorf("fingerprint = %s, want %s", k.Fingerprint, fp)
+	}
+	return nil
+}
+
+// VerifyVector recomputes v's intermediate values and headers from its
+// inputs and checks that its signature verifies if and only if v is valid
+func (s *Set) VerifyVector(v Vector) error {
+	k, ok := s.Key(v.Key)
+	if !ok {
+		return fmt.Errorf("unknown key %q", v.Key)
+	}
+
+	req, err := v.Request()
+	if err != nil {
+		return err
+	}
+	canonicalPath := auth.PathCanonicalizer{StripPrefix: v.StripPrefix}.SignPath(req)
+	if canonicalPath != v.CanonicalPath {
+		return fmt.Errorf("canonical_path = %q, want %q", v.CanonicalPath, canonicalPath)
+	}
+	bodyHash := auth.ComputeBodyHash([]byte(v.Body))
+	if bodyHash != v.BodyHash {
+		return fmt.Errorf("body_hash = %s, want %s", v.BodyHash, bodyHash)
+	}
+	canonical := auth.BuildCanonicalString(v.Method, canonicalPath, bodyHash, v.Timestamp, v.Nonce)
+	if canonical != v.Canonical {
+		return fmt.Errorf("canonical = %q, want %q", v.Canonical, canonical)
+	}
+
+	want := map[string]string{
+		"X-LCC-PublicKey": base64.StdEncoding.EncodeToString([]byte(k.PublicKeyPEM)),
+		"X-LCC-Timestamp": fmt.Sprint(v.Timestamp),
+		"X-LCC-Nonce":     v.Nonce,
+	}
+	for name, value := range want {
+		if v.Headers[name] != value {
+			return fmt.Errorf("header %s = %q, want %q", name, v.Headers[name], value)
+		}
+	}
+
+	signature, err := hex.DecodeString(v.Headers["X-LCC-Signature"])
+	if err != nil {
+		return fmt.Errorf("header X-LCC-Signature: %w", err)
+	}
+	pub, err := auth.ParsePublicKeyPEM([]byte(k.PublicKeyPEM))
+	if err != nil {
+		return err
+	}
+	err = auth.VerifySignature(pub, []byte(canonical), signature)
+	switch {
+	case v.Valid && err != nil:
+		return fmt.Errorf("valid vector does not verify: %w", err)
+	case !v.Valid && err == nil:
+		return fmt.Errorf("invalid vector verifies")
+	}
+	return nil
+}
+
+// Request returns the HTTP request described by v, with its headers
+func (v Vector) Request() (*http.Request, error) {
+	req, err := http.NewRequest(v.Method, "http://lcc.test"+v.URL, bytes.NewReader([]byte(v.Body)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	for name, value := range v.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
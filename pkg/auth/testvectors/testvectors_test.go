@@ -0,0 +1,99 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+func TestVectors(t *testing.T) {
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(s.Vectors) != len(cases) {
+		t.Errorf("published %d vectors, want %d", len(s.Vectors), len(cases))
+	}
+	if err := s.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+// TestVectors_UpToDate fails when the published vectors no longer match
+// what the generator produces, e.g. after a change to the cases
+func TestVectors_UpToDate(t *testing.T) {
+	published, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	regenerated, err := Generate(published.Keys)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(regenerated.Vectors) != len(published.Vectors) {
+		t.Fatalf("vectors.json is stale: run go generate ./pkg/auth/testvectors")
+	}
+	for i, want := range regenerated.Vectors {
+		got := published.Vectors[i]
+		if k, _ := published.Key(want.Key); k.Algorithm != auth.AlgorithmRSA {
+			// ECDSA signatures are randomized
+			got.Headers = copyWithout(got.Headers, "X-LCC-Signature")
+			want.Headers = copyWithout(want.Headers, "X-LCC-Signature")
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("vector %s is stale: run go generate ./pkg/auth/testvectors\ngot  %+v\nwant %+v", want.Name, got, want)
+		}
+	}
+}
+
+// TestVectors_RequestSigner checks that RequestSigner signs the canonical
+// string the vectors describe
+func TestVectors_RequestSigner(t *testing.T) {
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, v := range s.Vectors {
+		if !v.Valid {
+			continue
+		}
+		t.Run(v.Name, func(t *testing.T) {
+			k, _ := s.Key(v.Key)
+			kp, err := auth.ParsePrivateKeyPEM([]byte(k.PrivateKeyPEM))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(v.Method, "http://lcc.test"+v.URL, bytes.NewReader([]byte(v.Body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer := auth.NewRequestSigner(kp, auth.WithPathCanonicalizer(auth.PathCanonicalizer{StripPrefix: v.StripPrefix}))
+			if err := signer.SignRequest(req); err != nil {
+				t.Fatalf("SignRequest() error = %v", err)
+			}
+
+			ts, _ := strconv.ParseInt(req.Header.Get("X-LCC-Timestamp"), 10, 64)
+			canonical := auth.BuildCanonicalString(v.Method, v.CanonicalPath, v.BodyHash, ts, req.Header.Get("X-LCC-Nonce"))
+			signature, _ := hex.DecodeString(req.Header.Get("X-LCC-Signature"))
+			pub, _ := auth.ParsePublicKeyPEM([]byte(k.PublicKeyPEM))
+			if err := auth.VerifySignature(pub, []byte(canonical), signature); err != nil {
+				t.Errorf("RequestSigner signature does not match the vector's scheme: %v", err)
+			}
+		})
+	}
+}
+
+func copyWithout(m map[string]string, key string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
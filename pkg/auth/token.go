@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors returned when verifying entitlement tokens
+var (
+	ErrInvalidToken = errors.New("invalid entitlement token")
+	ErrTokenExpired = errors.New("entitlement token expired")
+
+	// ErrNoTrustPolicy is returned by VerifyEntitlementToken on a Verifier
+	// without a KeyLookup or trusted CAs
+	ErrNoTrustPolicy = errors.New("verifier has no key trust policy")
+)
+
+// tokenClockSkew is the tolerance applied to token issue/expiry times
+const tokenClockSkew = 30 * time.Second
+
+// EntitlementClaims asserts that an instance is licensed for a feature.
+// Tokens carrying these claims are signed with the instance key, so any
+// service can verify them with a Verifier without its own LCC registration.
+type EntitlementClaims struct {
+	// InstanceID is the issuing client's instance ID, as it reports it. It
+	// need not derive from the key (see SaltedInstanceID), so verification
+	// binds the token to the signing key, not to this ID.
+	InstanceID string `json:"iid"`
+	ProductID  string `json:"pid"`
+	FeatureID  string `json:"fid"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  int64  `json:"exp"`
+
+	// Signing key material, filled in by IssueEntitlementToken
+	PublicKey   string `json:"pub"`
+	Certificate string `json:"crt,omitempty"`
+
+	// KeyFingerprint is the SHA-256 fingerprint of the verified signing
+	// key, set by VerifyEntitlementToken
+	KeyFingerprint string `json:"-"`
+}
+
+// FeatureAssertion is a feature decision signed by the LCC server (or another
+// authority holding a trusted key), for services that enforce decisions made
+// elsewhere.
+type FeatureAssertion struct {
+	InstanceID string `json:"iid"`
+	ProductID  string `json:"pid"`
+	FeatureID  string `json:"fid"`
+	Enabled    bool   `json:"enabled"`
+	Reason     string `json:"reason,omitempty"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// IssueEntitlementToken signs claims with the signer's key. IssuedAt and
+// ExpiresAt are set from ttl. The token has the form
+// base64url(claims JSON) "." base64url(signature).
+func (s *RequestSigner) IssueEntitlementToken(claims EntitlementClaims, ttl time.Duration) (string, error) {
+	publicKeyPEM, err := s.keyPair.GetPublicKeyPEM()
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	now := s.now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+	claims.PublicKey = base64.StdEncoding.EncodeToString([]byte(publicKeyPEM))
+	claims.Certificate = s.certificate
+
+	return signToken(s.keyPair, claims)
+}
+
+// VerifyEntitlementToken verifies an entitlement token and returns its claims.
+// The token must be signed by its embedded key, and that key must satisfy
+// the Verifier's trust policy; claims.KeyFingerprint identifies it. The
+// instance ID is reported by the issuer and not checked against the key.
+// Tokens are bearer credentials that anyone can mint with a fresh key, so
+// the Verifier must have a KeyLookup or trusted CAs; otherwise every token
+// is rejected with ErrNoTrustPolicy.
+func (v *Verifier) VerifyEntitlementToken(token string) (*EntitlementClaims, error) {
+	if v.lookup == nil && v.roots == nil {
+		return nil, ErrNoTrustPolicy
+	}
+
+	var claims EntitlementClaims
+	encoded, signature, err := decodeToken(token, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyPEM, err := base64.StdEncoding.DecodeString(claims.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode public key: %v", ErrInvalidToken, err)
+	}
+	fp, key, err := v.trustedKey(publicKeyPEM, claims.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyToken(key, encoded, signature, claims.IssuedAt, claims.ExpiresAt); err != nil {
+		return nil, err
+	}
+	claims.KeyFingerprint = fp
+	return &claims, nil
+}
+
+// SignFeatureAssertion signs a feature decision with kp, valid for ttl.
+// The token format matches entitlement tokens.
+func SignFeatureAssertion(kp KeyPair, assertion FeatureAssertion, ttl time.Duration) (string, error) {
+	now := time.Now()
+	assertion.IssuedAt = now.Unix()
+	assertion.ExpiresAt = now.Add(ttl).Unix()
+	return signToken(kp, assertion)
+}
+
+// VerifyFeatureAssertion verifies an assertion signed with the key matching
+// authorityKey and returns it
+func VerifyFeatureAssertion(token string, authorityKey crypto.PublicKey) (*FeatureAssertion, error) {
+	var assertion FeatureAssertion
+	encoded, signature, err := decodeToken(token, &assertion)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyToken(authorityKey, encoded, signature, assertion.IssuedAt, assertion.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &assertion, nil
+}
+
+// signToken encodes claims and signs the encoded form
+func signToken(kp KeyPair, claims any) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := kp.Sign([]byte(encoded))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// decodeToken splits a token and decodes its claims into v. It returns the
+// encoded claims (the signed bytes) and the signature.
+func decodeToken(token string, v any) (string, []byte, error) {
+	encoded, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return encoded, signature, nil
+}
+
+// verifyToken checks a token signature and its validity window
+func verifyToken(key crypto.PublicKey, encoded string, signature []byte, issuedAt, expiresAt int64) error {
+	hashed := sha256.Sum256([]byte(encoded))
+	if err := verifyDigest(key, crypto.SHA256, hashed[:], signature); err != nil {
+		return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	now := time.Now()
+	if now.Add(tokenClockSkew).Unix() < issuedAt {
+		return fmt.Errorf("%w: issued in the future", ErrInvalidToken)
+	}
+	if now.Add(-tokenClockSkew).Unix() >= expiresAt {
+		return ErrTokenExpired
+	}
+	return nil
+}
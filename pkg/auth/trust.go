@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HeaderCertificate carries the base64-encoded PEM certificate chain (leaf
+// first) for the signing key. It is optional and only consulted by verifiers
+// configured with trusted CAs.
+const HeaderCertificate = "X-LCC-Certificate"
+
+// ErrKeyNotTrusted is returned when a Verifier with a trust policy receives a
+// key that is neither registered nor certified by a trusted CA.
+var ErrKeyNotTrusted = errors.New("public key not trusted")
+
+// WithCertificate attaches a PEM certificate chain (leaf first) for the
+// signer's key to every signed request, so servers that trust the issuing CA
+// accept the key without pre-registration.
+func WithCertificate(certPEM []byte) SignerOption {
+	return func(s *RequestSigner) {
+		s.certificate = base64.StdEncoding.EncodeToString(certPEM)
+	}
+}
+
+// WithTrustedCAs makes the Verifier accept keys certified by a CA in roots.
+// Combined with WithKeyLookup, a key is accepted if it is registered or
+// certified; with neither option any well-formed key is accepted.
+func WithTrustedCAs(roots *x509.CertPool) VerifierOption {
+	return func(v *Verifier) {
+		v.roots = roots
+	}
+}
+
+// KeyAllowlist is a KeyLookup over a fixed set of pre-registered keys.
+// It is safe for concurrent use.
+type KeyAllowlist struct {
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+// NewKeyAllowlist creates an allowlist from PEM-encoded public keys
+func NewKeyAllowlist(publicKeyPEMs ...[]byte) (*KeyAllowlist, error) {
+	a := &KeyAllowlist{
+		keys: make(map[string]crypto.PublicKey),
+	}
+	for _, p := range publicKeyPEMs {
+		if _, err := a.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Add registers a PEM-encoded public key and returns its fingerprint
+func (a *KeyAllowlist) Add(publicKeyPEM []byte) (string, error) {
+	fp, der, err := fingerprintPEM(publicKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	key, err := parsePublicKeyDER(der)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys[fp] = key
+	return fp, nil
+}
+
+// Remove unregisters a key. Verifiers that already cached it should be
+// purged with Verifier.Purge.
+func (a *KeyAllowlist) Remove(fingerprint string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.keys, fingerprint)
+}
+
+// LookupKey implements KeyLookup
+func (a *KeyAllowlist) LookupKey(fingerprint string) (crypto.PublicKey, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	key, ok := a.keys[fingerprint]
+	if !ok {
+		return nil, ErrKeyNotRegistered
+	}
+	return key, nil
+}
+
+// verifyCertificate checks that the certificate chain (base64 PEM, as sent in
+// X-LCC-Certificate) is issued by roots and certifies the presented key (PKIX
+// DER). It returns the leaf certificate's expiry.
+func verifyCertificate(header string, der []byte, roots *x509.CertPool) (time.Time, error) {
+	if header == "" {
+		return time.Time{}, fmt.Errorf("no certificate presented")
+	}
+	chainPEM, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	for rest := chainPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate in %s header", HeaderCertificate)
+	}
+
+	leaf := certs[0]
+	if !bytes.Equal(leaf.RawSubjectPublicKeyInfo, der) {
+		return time.Time{}, fmt.Errorf("certificate does not match public key")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	return leaf.NotAfter, nil
+}
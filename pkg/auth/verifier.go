@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"container/list"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultKeyCacheSize is the number of parsed public keys a Verifier keeps
+// by default
+const defaultKeyCacheSize = 1024
+
+// ErrKeyNotRegistered is returned when a KeyLookup does not know the
+// presented public key.
+var ErrKeyNotRegistered = errors.New("public key not registered")
+
+// KeyLookup resolves the trusted public key for a fingerprint (hex SHA-256
+// of the PKIX DER, as returned by KeyPair.GetFingerprint).
+//
+// Implementations return ErrKeyNotRegistered (or wrap it) for unknown keys,
+// which restricts a Verifier to keys registered in the implementation's store.
+type KeyLookup interface {
+	LookupKey(fingerprint string) (crypto.PublicKey, error)
+}
+
+// KeyLookupFunc adapts a function to the KeyLookup interface
+type KeyLookupFunc func(fingerprint string) (crypto.PublicKey, error)
+
+// LookupKey calls f(fingerprint)
+func (f KeyLookupFunc) LookupKey(fingerprint string) (crypto.PublicKey, error) {
+	return f(fingerprint)
+}
+
+// Verifier verifies signed requests, caching parsed public keys by
+// fingerprint. It is intended for embedding in high-throughput servers and
+// is safe for concurrent use.
+//
+// Without a KeyLookup or trusted CAs the Verifier trusts any well-formed key
+// presented in the request, like VerifyRequest, and rejects all entitlement
+// tokens. Otherwise only keys returned by the lookup or certified by a
+// trusted CA are accepted.
+type Verifier struct {
+	opts   VerifyOptions
+	lookup KeyLookup
+	roots  *x509.CertPool
+	cache  *keyCache
+}
+
+// VerifierOption configures a Verifier
+type VerifierOption func(*Verifier)
+
+// WithKeyLookup restricts verification to keys known to lookup
+func WithKeyLookup(lookup KeyLookup) VerifierOption {
+	return func(v *Verifier) {
+		v.lookup = lookup
+	}
+}
+
+// WithKeyCacheSize sets the maximum number of cached keys (default 1024)
+func WithKeyCacheSize(size int) VerifierOption {
+	return func(v *Verifier) {
+		v.cache = newKeyCache(size)
+	}
+}
+
+// WithVerifyOptions sets request verification options
+func WithVerifyOptions(opts VerifyOptions) VerifierOption {
+	return func(v *Verifier) {
+		v.opts = opts
+	}
+}
+
+// NewVerifier creates a request verifier
+func NewVerifier(opts ...VerifierOption) *Verifier {
+	v := &Verifier{
+		cache: newKeyCache(defaultKeyCacheSize),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify verifies the signature of an HTTP request
+func (v *Verifier) Verify(req *http.Request) error {
+	return verifyRequest(req, v.opts, v.resolveKey)
+}
+
+// Fingerprint returns the fingerprint of the key presented in req's
+// X-LCC-PublicKey header, without verifying the request.
+func (v *Verifier) Fingerprint(req *http.Request) (string, error) {
+	pemData, err := base64.StdEncoding.DecodeString(req.Header.Get("X-LCC-PublicKey"))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode public key: %w", err)
+	}
+	fp, _, err := fingerprintPEM(pemData)
+	return fp, err
+}
+
+// Purge removes a key from the cache, e.g. after it was revoked in the
+// lookup store.
+func (v *Verifier) Purge(fingerprint string) {
+	v.cache.remove(fingerprint)
+}
+
+// resolveKey returns the verification key for a request
+func (v *Verifier) resolveKey(req *http.Request, publicKeyPEM []byte) (crypto.PublicKey, error) {
+	_, key, err := v.trustedKey(publicKeyPEM, req.Header.Get(HeaderCertificate))
+	return key, err
+}
+
+// trustedKey returns the fingerprint and parsed key for a presented PEM,
+// enforcing the Verifier's trust policy. certHeader is the optional
+// base64-encoded certificate chain.
+func (v *Verifier) trustedKey(publicKeyPEM []byte, certHeader string) (string, crypto.PublicKey, error) {
+	fp, der, err := fingerprintPEM(publicKeyPEM)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if key, ok := v.cache.get(fp); ok {
+		return fp, key, nil
+	}
+
+	if v.lookup == nil && v.roots == nil {
+		key, err := parsePublicKeyDER(der)
+		if err != nil {
+			return "", nil, err
+		}
+		v.cache.add(fp, key, time.Time{})
+		return fp, key, nil
+	}
+
+	if v.lookup != nil {
+		key, err := v.lookup.LookupKey(fp)
+		switch {
+		case err == nil && key != nil:
+			v.cache.add(fp, key, time.Time{})
+			return fp, key, nil
+		case err != nil && !errors.Is(err, ErrKeyNotRegistered):
+			return "", nil, fmt.Errorf("key %s: %w", fp, err)
+		case v.roots == nil:
+			return "", nil, fmt.Errorf("key %s: %w", fp, ErrKeyNotRegistered)
+		}
+	}
+
+	expires, err := verifyCertificate(certHeader, der, v.roots)
+	if err != nil {
+		return "", nil, fmt.Errorf("key %s: %w: %v", fp, ErrKeyNotTrusted, err)
+	}
+	key, err := parsePublicKeyDER(der)
+	if err != nil {
+		return "", nil, err
+	}
+	// Certified keys are only cached until the certificate expires
+	v.cache.add(fp, key, expires)
+	return fp, key, nil
+}
+
+// fingerprintPEM decodes a PUBLIC KEY PEM block and returns its fingerprint
+// and DER bytes
+func fingerprintPEM(pemData []byte) (string, []byte, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return "", nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if block.Type != "PUBLIC KEY" {
+		return "", nil, fmt.Errorf("invalid PEM type: %s", block.Type)
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:]), block.Bytes, nil
+}
+
+// keyCache is a fixed-size LRU of parsed public keys keyed by fingerprint
+type keyCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type keyCacheEntry struct {
+	fingerprint string
+	key         crypto.PublicKey
+	expires     time.Time // zero means no expiry
+}
+
+func newKeyCache(size int) *keyCache {
+	if size <= 0 {
+		size = defaultKeyCacheSize
+	}
+	return &keyCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *keyCache) get(fp string) (crypto.PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[fp]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*keyCacheEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.items, fp)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.key, true
+}
+
+func (c *keyCache) add(fp string, key crypto.PublicKey, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[fp]; ok {
+		entry := el.Value.(*keyCacheEntry)
+		entry.key = key
+		entry.expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[fp] = c.order.PushFront(&keyCacheEntry{fingerprint: fp, key: key, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*keyCacheEntry).fingerprint)
+	}
+}
+
+func (c *keyCache) remove(fp string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[fp]; ok {
+		c.order.Remove(el)
+		delete(c.items, fp)
+	}
+}
+
+func (c *keyCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
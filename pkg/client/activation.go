@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/yourorg/lcc-sdk/pkg/activation"
+)
+
+// StageActivation is the name of the pipeline stage that answers checks
+// from an offline activation
+const StageActivation = "activation"
+
+// Reason codes produced by offline activation
+const (
+	ReasonActivated         = "activated"
+	ReasonNotActivated      = "feature_not_activated"
+	ReasonActivationInvalid = "activation_invalid"
+)
+
+// offlineActivation is a verified activation response and its entitlement
+type offlineActivation struct {
+	response    *activation.Response
+	entitlement *activation.Entitlement
+}
+
+// ActivationRequest builds an offline activation request for this instance.
+// Write it with Request.WriteFile and send the file to the vendor.
+func (c *Client) ActivationRequest() (*activation.Request, error) {
+	c.mu.RLock()
+	kp, instanceID := c.keyPair, c.instanceID
+	c.mu.RUnlock()
+
+	if kp == nil {
+		return nil, fmt.Errorf("client identity was destroyed")
+	}
+	req, err := activation.NewRequest(kp, c.productID, c.productVer)
+	if err != nil {
+		return nil, err
+	}
+	// The entitlement is issued for the instance ID, which differs from
+	// the key fingerprint with a custom derivation
+	req.Fingerprint = instanceID
+	return req, nil
+}
+
+// Activate verifies a vendor-signed activation response against vendorKey
+// and, on success, answers feature checks from its entitlement instead of
+// the LCC server. Registration is not required.
+//
+// The response must have been issued for this client's instance ID, so the
+// key pair must be persisted (see auth.KeyPair.SavePrivateKeyPEMFile or
+// NewClientWithStore).
+func (c *Client) Activate(resp *activation.Response, vendorKey crypto.PublicKey) error {
+	ent, err := resp.Verify(vendorKey, c.instanceID)
+	if err != nil {
+		return err
+	}
+	if ent.ProductID != c.productID {
+		return fmt.Errorf("activation is for product %q, not %q", ent.ProductID, c.productID)
+	}
+	if err := ent.Validate(c.licenseNow()); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.activation = &offlineActivation{response: resp, entitlement: ent}
+	c.mu.Unlock()
+
+	c.pipeline.mu.Lock()
+	if c.pipeline.indexOf(StageActivation) < 0 {
+		idx := c.pipeline.indexOf(StageRemote)
+		if idx < 0 {
+			idx = len(c.pipeline.stages)
+		}
+		c.pipeline.insert(idx, &activationStage{client: c})
+	}
+	c.pipeline.mu.Unlock()
+
+	c.cache.clear()
+	debugLogf("Activated offline: %d feature(s), expires %v", len(ent.Features), ent.ExpiresAt)
+	return nil
+}
+
+// LoadActivation reads a persisted activation response from path and
+// activates it. Use this on startup after a previous SaveActivation.
+func (c *Client) LoadActivation(path string, vendorKey crypto.PublicKey) error {
+	resp, err := activation.ReadResponseFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load activation: %w", err)
+	}
+	return c.Activate(resp, vendorKey)
+}
+
+// SaveActivation persists the current activation response to path
+func (c *Client) SaveActivation(path string) error {
+	c.mu.RLock()
+	act := c.activation
+	c.mu.RUnlock()
+
+	if act == nil {
+		return fmt.Errorf("client is not activated")
+	}
+	return act.response.WriteFile(path)
+}
+
+// Entitlement returns the active offline entitlement, or nil
+func (c *Client) Entitlement() *activation.Entitlement {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.activation == nil {
+		return nil
+	}
+	return c.activation.entitlement
+}
+
+// activationStage answers checks from the offline entitlement. It is
+// terminal: an activated client never contacts the LCC server for checks.
+type activationStage struct {
+	client *Client
+}
+
+func (s *activationStage) Name() string { return StageActivation }
+
+func (s *activationStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	ent := s.client.Entitlement()
+	if ent == nil {
+		return next(ctx, req)
+	}
+
+	if err := ent.Validate(s.client.licenseNow()); err != nil {
+		return &FeatureStatus{Enabled: false, Reason: ReasonActivationInvalid}, nil
+	}
+	if !ent.Allows(req.FeatureID) {
+		return &FeatureStatus{Enabled: false, Reason: ReasonNotActivated}, nil
+	}
+	return &FeatureStatus{Enabled: true, Reason: ReasonActivated}, nil
+}
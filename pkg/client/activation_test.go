@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/activation"
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_OfflineActivation(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	vendor, _ := auth.GenerateKeyPair()
+	vendorPEM, _ := vendor.GetPublicKeyPEM()
+	vendorKey, _ := auth.ParsePublicKeyFromPEM([]byte(vendorPEM))
+
+	c := newTestClient(t, srv.URL)
+
+	req, err := c.ActivationRequest()
+	if err != nil {
+		t.Fatalf("ActivationRequest() error = %v", err)
+	}
+	resp, err := activation.Issue(req, []string{"core"}, time.Hour, vendor)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if err := c.Activate(resp, vendorKey); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+
+	status, err := c.CheckFeature("core")
+	if err != nil || !status.Enabled || status.Reason != ReasonActivated {
+		t.Errorf("CheckFeature(core) = %+v, %v; want enabled", status, err)
+	}
+	status, err = c.CheckFeature("reports")
+	if err != nil || status.Enabled || status.Reason != ReasonNotActivated {
+		t.Errorf("CheckFeature(reports) = %+v, %v; want not activated", status, err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server received %d requests, want 0", n)
+	}
+
+	// Persisted activation is reloaded by a client with the same identity
+	path := filepath.Join(t.TempDir(), "activation.json")
+	if err := c.SaveActivation(path); err != nil {
+		t.Fatalf("SaveActivation() error = %v", err)
+	}
+	c2, err := NewClientWithKeyPair(&config.SDKConfig{LCCURL: srv.URL, ProductID: "test-app", ProductVersion: "1.0.0"}, c.keyPair)
+	if err != nil {
+		t.Fatalf("NewClientWithKeyPair() error = %v", err)
+	}
+	defer c2.Close()
+	if err := c2.LoadActivation(path, vendorKey); err != nil {
+		t.Fatalf("LoadActivation() error = %v", err)
+	}
+	if status, _ := c2.CheckFeature("core"); status == nil || !status.Enabled {
+		t.Errorf("reloaded CheckFeature(core) = %+v, want enabled", status)
+	}
+
+	// A different identity cannot use the activation
+	other := newTestClient(t, srv.URL)
+	if err := other.LoadActivation(path, vendorKey); err == nil {
+		t.Error("LoadActivation() should fail for another instance")
+	}
+}
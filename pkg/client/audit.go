@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultAuditSize is how many decisions the audit trail keeps by default
+const defaultAuditSize = 256
+
+// AuditKind classifies an AuditRecord
+type AuditKind string
+
+// Audit record kinds
+const (
+	// AuditConsumed: Consume allowed the units
+	AuditConsumed AuditKind = "consumed"
+
+	// AuditConsumeDenied: the quota refused the units
+	AuditConsumeDenied AuditKind = "consume_denied"
+
+	// AuditConsumeFailed: Consume failed for another reason, such as a
+	// TPS limit or an unreachable server
+	AuditConsumeFailed AuditKind = "consume_failed"
+
+	// AuditFeatureDenied: a feature check returned a disabled status
+	AuditFeatureDenied AuditKind = "feature_denied"
+)
+
+// AuditRecord is one consumption or denial decision kept in the client's
+// audit trail
+type AuditRecord struct {
+	Time      time.Time
+	Kind      AuditKind
+	FeatureID string
+	Amount    int
+	Remaining int
+
+	// Reason is the denial reason or the error, if any
+	Reason string
+
+	// Args is the JSON of the ConsumeWithContext args returned by the
+	// ArgsRedactor helper, for a denial
+	Args string
+}
+
+// auditRecordPayload is the upload encoding of an AuditRecord
+type auditRecordPayload struct {
+	Time      int64     `json:"time"` // Unix milliseconds
+	Kind      AuditKind `json:"kind"`
+	FeatureID string    `json:"feature_id"`
+	Amount    int       `json:"amount,omitempty"`
+	Remaining int       `json:"remaining,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Args      string    `json:"args,omitempty"`
+}
+
+// auditTrail is a ring buffer of the most recent decisions
+type auditTrail struct {
+	mu      sync.Mutex
+	records []AuditRecord
+	next    int // slot of the next record once the ring is full
+	size    int
+	dropped uint64 // records overwritten since the client started
+}
+
+func newAuditTrail(size int) *auditTrail {
+	return &auditTrail{size: size}
+}
+
+// add records r, overwriting the oldest record when the ring is full
+func (a *auditTrail) add(r AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.size <= 0 {
+		return
+	}
+	if len(a.records) < a.size {
+		a.records = append(a.records, r)
+		return
+	}
+	a.records[a.next] = r
+	a.next = (a.next + 1) % a.size
+	a.dropped++
+}
+
+// snapshot returns the records, oldest first, and the number dropped
+func (a *auditTrail) snapshot() ([]AuditRecord, uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]AuditRecord, 0, len(a.records))
+	out = append(out, a.records[a.next:]...)
+	out = append(out, a.records[:a.next]...)
+	return out, a.dropped
+}
+
+// resize keeps the newest n records
+func (a *auditTrail) resize(n int) {
+	records, _ := a.snapshot()
+	if n < 0 {
+		n = 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(records) > n {
+		a.dropped += uint64(len(records) - n)
+		records = records[len(records)-n:]
+	}
+	a.records, a.next, a.size = records, 0, n
+}
+
+// SetAuditSize sets how many recent consumption and denial decisions the
+// audit trail keeps (default 256). The oldest records are dropped first;
+// 0 disables the trail.
+func (c *Client) SetAuditSize(n int) {
+	c.audit.resize(n)
+}
+
+// AuditRecords returns the audit trail, oldest first
+func (c *Client) AuditRecords() []AuditRecord {
+	records, _ := c.audit.snapshot()
+	return records
+}
+
+// recordConsume adds a Consume outcome to the audit trail, with the
+// redacted helper args of a denial
+func (c *Client) recordConsume(ev ConsumeEvent, args []interface{}) {
+	r := AuditRecord{Time: time.Now(), Kind: AuditConsumed, FeatureID: productFeatureID, Amount: ev.Amount, Remaining: ev.Remaining}
+	switch {
+	case ev.Denied:
+		r.Kind = AuditConsumeDenied
+	case !ev.Allowed:
+		r.Kind = AuditConsumeFailed
+	}
+	if ev.Err != nil {
+		r.Reason = ev.Err.Error()
+	}
+	if ev.Denied && args != nil {
+		r.Args = c.redactArgs(args)
+	}
+	c.audit.add(r)
+}
+
+// UploadAudit uploads the audit trail to the server, so vendor support can
+// review the client's consumption and denial decisions during a dispute.
+// The server can also request an upload with an "upload_audit" heartbeat
+// command. Uploaded records stay in the trail.
+func (c *Client) UploadAudit(ctx context.Context) error {
+	return c.uploadAudit(ctx, "")
+}
+
+// uploadAudit uploads the audit trail, answering the heartbeat command
+// commandID if set
+func (c *Client) uploadAudit(ctx context.Context, commandID string) error {
+	if err := c.checkOpen("upload audit trail"); err != nil {
+		return err
+	}
+	if c.OfflineMode() {
+		return fmt.Errorf("cannot upload audit trail: offline mode")
+	}
+	if c.grpcTransport() != nil {
+		return fmt.Errorf("cannot upload audit trail: not supported with protocol grpc")
+	}
+
+	records, dropped := c.audit.snapshot()
+	payload := struct {
+		InstanceID string               `json:"instance_id"`
+		CommandID  string               `json:"command_id,omitempty"`
+		Dropped    uint64               `json:"dropped"`
+		Records    []auditRecordPayload `json:"records"`
+	}{InstanceID: c.instanceID, CommandID: commandID, Dropped: dropped, Records: make([]auditRecordPayload, len(records))}
+	for i, r := range records {
+		payload.Records[i] = auditRecordPayload{
+			Time:      r.Time.UnixMilli(),
+			Kind:      r.Kind,
+			FeatureID: r.FeatureID,
+			Amount:    r.Amount,
+			Remaining: r.Remaining,
+			Reason:    r.Reason,
+			Args:      r.Args,
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit trail: %w", err)
+	}
+
+	url := c.baseURL + "/api/v1/sdk/audit"
+	resp, err := c.doWithRetry(ctx, "UploadAudit", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", url, body)
+	})
+	if err != nil {
+		return fmt.Errorf("audit upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("audit upload failed: status=%d, body=%s", resp.StatusCode, string(respBody))
+	}
+	debugLogf("UploadAudit: uploaded %d records", len(records))
+	return nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// maxAuditArgs bounds the serialized helper args kept in an audit record
+const maxAuditArgs = 1024
+
+// auditArgsTruncated marks serialized args cut to maxAuditArgs
+const auditArgsTruncated = "...(truncated)"
+
+// redactArgs serializes args through the ArgsRedactor helper, returning ""
+// without one. A redactor that panics or returns a value that cannot be
+// marshaled records nothing rather than failing the denial.
+func (c *Client) redactArgs(args []interface{}) (out string) {
+	c.mu.RLock()
+	helpers := c.helpers
+	c.mu.RUnlock()
+	if helpers == nil || helpers.ArgsRedactor == nil {
+		return ""
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			debugLogf("WARNING: ArgsRedactor panicked: %v", r)
+			out = ""
+		}
+	}()
+	data, err := json.Marshal(helpers.ArgsRedactor(args...))
+	if err != nil {
+		debugLogf("WARNING: ArgsRedactor: %v", err)
+		return ""
+	}
+	return truncateArgs(string(data))
+}
+
+// truncateArgs cuts s to maxAuditArgs bytes on a rune boundary
+func truncateArgs(s string) string {
+	if len(s) <= maxAuditArgs {
+		return s
+	}
+	cut := maxAuditArgs - len(auditArgsTruncated)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + auditArgsTruncated
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestClient_ConsumeWithContextDenialArgs(t *testing.T) {
+	var exhausted atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/product/status" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": !exhausted.Load(), "reason": "over_limit"})
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	if err := c.RegisterHelpers(&HelperFunctions{
+		CapacityCounter: func() int { return 0 },
+		QuotaConsumer:   func(ctx context.Context, args ...interface{}) int { return args[0].(int) },
+		ArgsRedactor: func(args ...interface{}) interface{} {
+			// Keep the batch size and tenant, drop the API key
+			return map[string]interface{}{"batch_size": args[0], "tenant": args[1]}
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if allowed, _, err := c.ConsumeWithContext(ctx, 5, "acme", "secret-key"); !allowed {
+		t.Fatalf("ConsumeWithContext() error = %v", err)
+	}
+	exhausted.Store(true)
+	c.ClearCache()
+	if allowed, _, _ := c.ConsumeWithContext(ctx, 50, "acme", "secret-key"); allowed {
+		t.Fatal("ConsumeWithContext() allowed over the quota")
+	}
+
+	records := c.AuditRecords()
+	if len(records) != 2 {
+		t.Fatalf("AuditRecords() = %+v", records)
+	}
+	if records[0].Args != "" {
+		t.Errorf("allowed record args = %q, want none", records[0].Args)
+	}
+	if r := records[1]; r.Kind != AuditConsumeDenied || r.Args != `{"batch_size":50,"tenant":"acme"}` {
+		t.Errorf("denial record = %+v", r)
+	}
+}
+
+func TestClient_RedactArgsFailures(t *testing.T) {
+	c := newTestClient(t, "http://localhost:1")
+	if got := c.redactArgs([]interface{}{1}); got != "" {
+		t.Errorf("redactArgs() without a redactor = %q", got)
+	}
+
+	tests := []struct {
+		name     string
+		redactor func(args ...interface{}) interface{}
+	}{
+		{"panic", func(args ...interface{}) interface{} { panic("boom") }},
+		{"unmarshalable", func(args ...interface{}) interface{} { return make(chan int) }},
+	}
+	for _, tt := range tests {
+		if err := c.RegisterHelpers(&HelperFunctions{CapacityCounter: func() int { return 0 }, ArgsRedactor: tt.redactor}); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.redactArgs([]interface{}{1}); got != "" {
+			t.Errorf("%s: redactArgs() = %q, want none", tt.name, got)
+		}
+	}
+}
+
+func TestTruncateArgs(t *testing.T) {
+	if s := `{"n":1}`; truncateArgs(s) != s {
+		t.Errorf("truncateArgs(%q) changed a short value", s)
+	}
+	long := strings.Repeat("é", maxAuditArgs)
+	got := truncateArgs(long)
+	if len(got) > maxAuditArgs || !strings.HasSuffix(got, auditArgsTruncated) || !utf8.ValidString(got) {
+		t.Errorf("truncateArgs() = %d bytes, valid UTF-8 %v", len(got), utf8.ValidString(got))
+	}
+}
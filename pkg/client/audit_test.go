@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// auditUpload is the body of an audit trail upload
+type auditUpload struct {
+	InstanceID string               `json:"instance_id"`
+	CommandID  string               `json:"command_id"`
+	Dropped    uint64               `json:"dropped"`
+	Records    []auditRecordPayload `json:"records"`
+}
+
+func TestClient_AuditTrail(t *testing.T) {
+	var exhausted atomic.Bool
+	uploads := make(chan auditUpload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": !exhausted.Load(), "reason": "over_limit"})
+		case "/api/v1/sdk/features/reports/check":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "reports", "enabled": false, "reason": "feature_not_in_license"})
+		case "/api/v1/sdk/audit":
+			var body auditUpload
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			uploads <- body
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	c.Consume(3)
+	exhausted.Store(true)
+	c.ClearCache()
+	c.Consume(1)
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+
+	records := c.AuditRecords()
+	want := []AuditKind{AuditConsumed, AuditConsumeDenied, AuditFeatureDenied}
+	if len(records) != len(want) {
+		t.Fatalf("AuditRecords() = %+v, want %v", records, want)
+	}
+	for i, kind := range want {
+		if records[i].Kind != kind {
+			t.Errorf("record %d kind = %s, want %s", i, records[i].Kind, kind)
+		}
+	}
+	if r := records[2]; r.FeatureID != "reports" || r.Reason != "feature_not_in_license" {
+		t.Errorf("feature denial record = %+v", r)
+	}
+
+	if err := c.UploadAudit(context.Background()); err != nil {
+		t.Fatalf("UploadAudit() error = %v", err)
+	}
+	got := <-uploads
+	if got.InstanceID != c.GetInstanceID() || len(got.Records) != 3 || got.Records[0].Amount != 3 {
+		t.Errorf("uploaded %+v", got)
+	}
+	if got.Records[0].Time <= 0 {
+		t.Errorf("record time = %d, want Unix milliseconds", got.Records[0].Time)
+	}
+}
+
+func TestAuditTrail_Ring(t *testing.T) {
+	a := newAuditTrail(3)
+	for i := 1; i <= 5; i++ {
+		a.add(AuditRecord{Amount: i})
+	}
+	records, dropped := a.snapshot()
+	if dropped != 2 || len(records) != 3 || records[0].Amount != 3 || records[2].Amount != 5 {
+		t.Errorf("snapshot = %+v, dropped %d; want amounts 3..5, dropped 2", records, dropped)
+	}
+
+	a.resize(2)
+	records, dropped = a.snapshot()
+	if dropped != 3 || len(records) != 2 || records[0].Amount != 4 {
+		t.Errorf("after resize: %+v, dropped %d", records, dropped)
+	}
+	a.add(AuditRecord{Amount: 6})
+	if records, _ = a.snapshot(); records[0].Amount != 5 || records[1].Amount != 6 {
+		t.Errorf("after add: %+v", records)
+	}
+
+	a.resize(0)
+	a.add(AuditRecord{Amount: 7})
+	if records, _ = a.snapshot(); len(records) != 0 {
+		t.Errorf("disabled trail kept %+v", records)
+	}
+}
+
+func TestClient_AuditHeartbeatCommand(t *testing.T) {
+	uploads := make(chan auditUpload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/heartbeat":
+			_, _ = w.Write([]byte(`{"commands":[{"id":"cmd-7","type":"upload_audit"},{"id":"cmd-8","type":"reboot"}]}`))
+		case "/api/v1/sdk/audit":
+			var body auditUpload
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			uploads <- body
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	select {
+	case got := <-uploads:
+		if got.CommandID != "cmd-7" {
+			t.Errorf("upload command_id = %q, want cmd-7", got.CommandID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat command did not upload the audit trail")
+	}
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entitlements is a snapshot of what the license grants this instance, for
+// startup banners and /about pages
+type Entitlements struct {
+	ProductID      string `json:"product_id"`
+	ProductVersion string `json:"product_version"`
+	SDKVersion     string `json:"sdk_version"`
+	InstanceID     string `json:"instance_id"`
+
+	// Edition is the licensed edition reported at registration, if any
+	Edition string `json:"edition,omitempty"`
+
+	// License details; LicenseID and Customer are known for offline
+	// licenses only
+	Offline   bool      `json:"offline"`
+	LicenseID string    `json:"license_id,omitempty"`
+	Customer  string    `json:"customer,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// Features are the manifest features (or, offline without a manifest,
+	// the features the license names), sorted by ID
+	Features []FeatureEntitlement `json:"features"`
+
+	// Limits are the product limits and quota; nil if they could not be
+	// read, with the error in Error
+	Limits *FeatureStatus `json:"limits,omitempty"`
+	Error  string         `json:"error,omitempty"`
+
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// FeatureEntitlement is the licensed state of one feature
+type FeatureEntitlement struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Enabled bool   `json:"enabled"`
+	Variant string `json:"variant,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Enabled returns the number of enabled features
+func (e *Entitlements) Enabled() int {
+	n := 0
+	for _, f := range e.Features {
+		if f.Enabled {
+			n++
+		}
+	}
+	return n
+}
+
+// Entitlements checks every known feature and the product limits and
+// returns the result. Checks that fail are reported as disabled, with the
+// error as the reason.
+func (c *Client) Entitlements() *Entitlements {
+	c.mu.RLock()
+	e := &Entitlements{
+		ProductID:      c.productID,
+		ProductVersion: c.productVer,
+		SDKVersion:     SDKVersion(),
+		InstanceID:     c.instanceID,
+		Edition:        c.edition,
+		ExpiresAt:      c.licenseExpiresAt,
+		TakenAt:        time.Now(),
+	}
+	manifest := c.manifest
+	c.mu.RUnlock()
+
+	names := make(map[string]string)
+	if manifest != nil {
+		for _, f := range manifest.Features {
+			names[f.ID] = f.Name
+		}
+	}
+	if ol := c.loadedLicense(); ol != nil {
+		e.Offline = true
+		e.LicenseID = ol.license.LicenseID
+		e.Customer = ol.license.Customer
+		e.ExpiresAt = ol.license.ExpiresAt
+		if manifest == nil {
+			for _, f := range ol.license.Features {
+				if f.ID != "*" {
+					names[f.ID] = ""
+				}
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		f := FeatureEntitlement{ID: id, Name: names[id]}
+		status, err := c.CheckFeature(id)
+		if err != nil {
+			f.Reason = err.Error()
+		} else {
+			f.Enabled, f.Variant, f.Reason = status.Enabled, status.Variant, status.Reason
+		}
+		e.Features = append(e.Features, f)
+	}
+
+	if status, err := c.ProductStatus(); err != nil {
+		e.Error = err.Error()
+	} else {
+		e.Limits = status
+	}
+	return e
+}
+
+// Banner formats the snapshot as a multi-line report for startup logs:
+//
+//	myapp 2.3.0 (pro edition)
+//	License: lic-42 for ACME Corp, expires 2027-01-01 (in 77 days)
+//	Instance: 5f0c2a9e...
+//	Features: 2 of 3 enabled
+//	  + export
+//	  + reports (pro)
+//	  - sso: feature_not_in_license
+//	Limits: max_tps 100, max_concurrency 10, quota 990 of 1000 left
+//	LCC SDK v1.4.2
+func (e *Entitlements) Banner() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s", e.ProductID, e.ProductVersion)
+	if e.Edition != "" {
+		fmt.Fprintf(&b, " (%s edition)", e.Edition)
+	}
+	b.WriteByte('\n')
+
+	license := "online"
+	if e.Offline {
+		license = "offline"
+		if e.LicenseID != "" {
+			license = e.LicenseID
+		}
+		if e.Customer != "" {
+			license += " for " + e.Customer
+		}
+	}
+	if e.ExpiresAt.IsZero() {
+		fmt.Fprintf(&b, "License: %s, no expiry\n", license)
+	} else {
+		fmt.Fprintf(&b, "License: %s, expires %s (%s)\n", license, e.ExpiresAt.Format("2006-01-02"), untilExpiry(e.ExpiresAt, e.TakenAt))
+	}
+	fmt.Fprintf(&b, "Instance: %s\n", e.InstanceID)
+
+	fmt.Fprintf(&b, "Features: %d of %d enabled\n", e.Enabled(), len(e.Features))
+	for _, f := range e.Features {
+		switch {
+		case f.Enabled && f.Variant != "":
+			fmt.Fprintf(&b, "  + %s (%s)\n", f.ID, f.Variant)
+		case f.Enabled:
+			fmt.Fprintf(&b, "  + %s\n", f.ID)
+		default:
+			fmt.Fprintf(&b, "  - %s: %s\n", f.ID, f.Reason)
+		}
+	}
+
+	if e.Limits == nil {
+		fmt.Fprintf(&b, "Limits: unavailable (%s)\n", e.Error)
+	} else if limits := limitSummary(e.Limits); limits != "" {
+		fmt.Fprintf(&b, "Limits: %s\n", limits)
+	} else {
+		b.WriteString("Limits: none\n")
+	}
+	fmt.Fprintf(&b, "LCC SDK %s\n", e.SDKVersion)
+	return b.String()
+}
+
+// untilExpiry describes how far expiry is from now in days
+func untilExpiry(expiry, now time.Time) string {
+	d := expiry.Sub(now)
+	switch {
+	case d <= 0:
+		return "expired"
+	case d < 24*time.Hour:
+		return "in less than a day"
+	case d < 48*time.Hour:
+		return "in 1 day"
+	}
+	return fmt.Sprintf("in %d days", int(d/(24*time.Hour)))
+}
+
+// limitSummary lists the set product limits
+func limitSummary(s *FeatureStatus) string {
+	var parts []string
+	if s.MaxTPS > 0 {
+		parts = append(parts, fmt.Sprintf("max_tps %g", s.MaxTPS))
+	}
+	if s.MaxCapacity > 0 {
+		parts = append(parts, fmt.Sprintf("max_capacity %d", s.MaxCapacity))
+	}
+	if s.MaxConcurrency > 0 {
+		parts = append(parts, fmt.Sprintf("max_concurrency %d", s.MaxConcurrency))
+	}
+	if s.Quota != nil {
+		parts = append(parts, fmt.Sprintf("quota %d of %d left", s.Quota.Remaining, s.Quota.Limit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Banner returns the Entitlements banner, e.g. to log at startup
+func (c *Client) Banner() string {
+	return c.Entitlements().Banner()
+}
+
+// AboutHandler serves the Entitlements snapshot as JSON, or as the banner
+// text with ?format=text, for an /about endpoint
+//
+// Example:
+//
+//	mux.Handle("GET /about", client.AboutHandler())
+func (c *Client) AboutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := c.Entitlements()
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.URL.Query().Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(e.Banner()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(e)
+	})
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_Entitlements(t *testing.T) {
+	expiry := time.Now().Add(30*24*time.Hour + time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/register":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"edition": "pro", "license_expires_at": expiry.Unix()})
+		case "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled": true,
+				"limits":  map[string]interface{}{"max_tps": 100},
+				"quota":   map[string]interface{}{"limit": 1000, "remaining": 990},
+			})
+		case "/api/v1/sdk/features/export/check":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+		case "/api/v1/sdk/features/reports/check":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "variant": "pro"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false, "reason": "feature_not_in_license"})
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	c.SetManifest(&config.Manifest{Features: []config.FeatureConfig{
+		{ID: "sso", Name: "Single sign-on"}, {ID: "reports"}, {ID: "export"},
+	}})
+
+	e := c.Entitlements()
+	if e.Edition != "pro" || e.ExpiresAt.Unix() != expiry.Unix() || e.Offline {
+		t.Errorf("Entitlements() license = %q, %v, offline %v", e.Edition, e.ExpiresAt, e.Offline)
+	}
+	if len(e.Features) != 3 || e.Features[0].ID != "export" || e.Enabled() != 2 || e.Features[2].Name != "Single sign-on" {
+		t.Errorf("Entitlements().Features = %+v", e.Features)
+	}
+	if e.Limits == nil || e.Limits.MaxTPS != 100 {
+		t.Errorf("Entitlements().Limits = %+v (%s)", e.Limits, e.Error)
+	}
+
+	banner := e.Banner()
+	for _, want := range []string{
+		"(pro edition)\n",
+		"(in 30 days)\n",
+		"Instance: " + c.instanceID + "\n",
+		"Features: 2 of 3 enabled\n  + export\n  + reports (pro)\n  - sso: feature_not_in_license\n",
+		"Limits: max_tps 100, quota 990 of 1000 left\n",
+	} {
+		if !strings.Contains(banner, want) {
+			t.Errorf("Banner() = %q, missing %q", banner, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	c.AboutHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/about?format=text", nil))
+	if !strings.Contains(rec.Body.String(), "Features: 2 of 3 enabled") {
+		t.Errorf("AboutHandler() text = %q", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	c.AboutHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/about", nil))
+	var got Entitlements
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got.InstanceID != c.instanceID || len(got.Features) != 3 {
+		t.Errorf("AboutHandler() JSON = %+v, %v", got, err)
+	}
+}
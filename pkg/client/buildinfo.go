@@ -0,0 +1,51 @@
+package client
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// buildInfo describes the customer binary, reported at registration when
+// SDKConfig.ReportBuildInfo is set so vendors can correlate license issues
+// with specific builds.
+type buildInfo struct {
+	GoVersion     string `json:"go_version"`
+	GOOS          string `json:"goos"`
+	GOARCH        string `json:"goarch"`
+	ModulePath    string `json:"module_path,omitempty"`
+	ModuleVersion string `json:"module_version,omitempty"`
+	VCSRevision   string `json:"vcs_revision,omitempty"`
+	VCSTime       string `json:"vcs_time,omitempty"`
+	VCSModified   bool   `json:"vcs_modified,omitempty"`
+	CGOEnabled    bool   `json:"cgo_enabled"`
+}
+
+// collectBuildInfo reads build settings embedded by the Go toolchain
+func collectBuildInfo() *buildInfo {
+	info := &buildInfo{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.ModulePath = bi.Main.Path
+	info.ModuleVersion = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.VCSRevision = s.Value
+		case "vcs.time":
+			info.VCSTime = s.Value
+		case "vcs.modified":
+			info.VCSModified = s.Value == "true"
+		case "CGO_ENABLED":
+			info.CGOEnabled = s.Value == "1"
+		}
+	}
+	return info
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestClient_RegisterReportsBuildInfo(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		var metadata map[string]json.RawMessage
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/sdk/register" {
+				var body struct {
+					Metadata map[string]json.RawMessage `json:"metadata"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				metadata = body.Metadata
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		c := newTestClient(t, srv.URL)
+		c.reportBuildInfo = enabled
+		if err := c.Register(); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		c.Close()
+		srv.Close()
+
+		raw, ok := metadata["build"]
+		if ok != enabled {
+			t.Fatalf("build metadata present = %v, want %v", ok, enabled)
+		}
+		if !enabled {
+			continue
+		}
+
+		var info buildInfo
+		if err := json.Unmarshal(raw, &info); err != nil {
+			t.Fatalf("decode build metadata: %v", err)
+		}
+		if info.GoVersion != runtime.Version() || info.GOOS != runtime.GOOS || info.GOARCH != runtime.GOARCH {
+			t.Errorf("build metadata = %+v", info)
+		}
+	}
+}
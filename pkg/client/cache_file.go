@@ -0,0 +1,277 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// cacheFileVersion is the format version of persisted cache files
+const cacheFileVersion = 1
+
+// defaultCacheMaxStale is how long past expiry a persisted status is used
+// while the LCC server is unreachable
+const defaultCacheMaxStale = 15 * time.Minute
+
+// ErrCacheIntegrity is returned when a cache file fails HMAC verification,
+// e.g. because it was modified or written with a different key
+var ErrCacheIntegrity = errors.New("cache file integrity check failed")
+
+// cacheFile is the on-disk envelope: the snapshot and its HMAC-SHA256
+type cacheFile struct {
+	Payload json.RawMessage `json:"payload"`
+	MAC     string          `json:"mac"`
+}
+
+// cacheSnapshot is the persisted client state. Product ID and LCC URL bind
+// the file to one deployment.
+type cacheSnapshot struct {
+	Version   int                       `json:"version"`
+	ProductID string                    `json:"product_id"`
+	LCCURL    string                    `json:"lcc_url"`
+	SavedAt   time.Time                 `json:"saved_at"`
+	Features  map[string]persistedEntry `json:"features"`
+
+	// QuotaExhausted maps exhausted features to their reset time
+	QuotaExhausted map[string]time.Time `json:"quota_exhausted,omitempty"`
+}
+
+type persistedEntry struct {
+	Status    *FeatureStatus `json:"status"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// cacheStore persists the feature cache and quota exhaustion state as JSON,
+// to SDKConfig.CacheFile or under a key of the client's Store
+type cacheStore struct {
+	backend  store.Store
+	name     string // key in backend
+	location string // for log messages
+	key      []byte
+	fromKey  bool // key derived from the client private key
+	maxStale time.Duration
+
+	mu           sync.Mutex
+	savedVersion uint64
+}
+
+// newCacheStore persists to the file at path (see newCacheStoreIn)
+func newCacheStore(path, secret string, maxStale time.Duration, privateKeyPEM string) *cacheStore {
+	s := newCacheStoreIn(store.NewFileStore(filepath.Dir(path)), filepath.Base(path), secret, maxStale, privateKeyPEM)
+	s.location = path
+	return s
+}
+
+// cacheKeyMaterial returns the private key PEM the cache HMAC key is derived
+// from, or "" when the key comes from the secret cfg.CacheKey
+func cacheKeyMaterial(cfg *config.SDKConfig, kp auth.KeyPair) (string, error) {
+	if cfg.CacheKey != "" {
+		return "", nil
+	}
+	keyPEM, err := kp.ExportPrivateKeyPEM()
+	if errors.Is(err, auth.ErrKeyNotExportable) {
+		return "", fmt.Errorf("failed to derive cache key: set sdk.cache_key for a key held by an external signer: %w", err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to derive cache key: %w", err)
+	}
+	return keyPEM, nil
+}
+
+// newCacheStoreIn persists under name in backend. It derives the HMAC key
+// from secret, or from the client private key when secret is empty.
+func newCacheStoreIn(backend store.Store, name, secret string, maxStale time.Duration, privateKeyPEM string) *cacheStore {
+	material := secret
+	if material == "" {
+		material = privateKeyPEM
+	}
+	key := sha256.Sum256([]byte("lcc-sdk cache file\x00" + material))
+	if maxStale == 0 {
+		maxStale = defaultCacheMaxStale
+	}
+	return &cacheStore{backend: backend, name: name, location: "store key " + name, key: key[:], fromKey: secret == "", maxStale: maxStale}
+}
+
+// rekey derives the HMAC key from a new client private key, unless it comes
+// from a secret, so the snapshot keeps verifying after a key rotation. The
+// next SaveCache writes the snapshot with the new key.
+func (s *cacheStore) rekey(privateKeyPEM string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fromKey {
+		return
+	}
+	key := sha256.Sum256([]byte("lcc-sdk cache file\x00" + privateKeyPEM))
+	s.key = key[:]
+	s.savedVersion = ^uint64(0) // matches no cache version
+}
+
+func (s *cacheStore) mac(payload []byte) string {
+	m := hmac.New(sha256.New, s.key)
+	m.Write(payload)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// read loads and verifies the snapshot. A missing file is not an error.
+func (s *cacheStore) read() (*cacheSnapshot, error) {
+	data, err := s.backend.Get(s.name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode cache file: %w", err)
+	}
+	if !hmac.Equal([]byte(file.MAC), []byte(s.mac(file.Payload))) {
+		return nil, ErrCacheIntegrity
+	}
+
+	var snap cacheSnapshot
+	if err := json.Unmarshal(file.Payload, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode cache snapshot: %w", err)
+	}
+	if snap.Version != cacheFileVersion {
+		return nil, fmt.Errorf("unsupported cache file version %d", snap.Version)
+	}
+	return &snap, nil
+}
+
+// write stores the snapshot atomically (with owner-only permissions in a
+// file)
+func (s *cacheStore) write(snap *cacheSnapshot) error {
+	payload, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache snapshot: %w", err)
+	}
+	data, err := json.Marshal(cacheFile{Payload: payload, MAC: s.mac(payload)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache file: %w", err)
+	}
+
+	if err := s.backend.Put(s.name, data); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// loadCacheFile restores the feature cache and quota exhaustion state from
+// the cache file. A file for another product or server, or one that fails
+// verification, is ignored.
+func (c *Client) loadCacheFile() {
+	snap, err := c.cacheStore.read()
+	if err != nil {
+		debugLogf("Cache file %s ignored: %v", c.cacheStore.location, err)
+		return
+	}
+	if snap == nil {
+		return
+	}
+	if snap.ProductID != c.productID || snap.LCCURL != c.baseURL {
+		debugLogf("Cache file %s ignored: written for %s at %s", c.cacheStore.location, snap.ProductID, snap.LCCURL)
+		return
+	}
+
+	c.cache.restore(snap.Features, c.cacheStore.maxStale)
+	for featureID, resetAt := range snap.QuotaExhausted {
+		if time.Now().Before(resetAt) {
+			c.quotaResets.markExhausted(featureID, resetAt, c.handleQuotaReset)
+		}
+	}
+
+	c.cacheStore.mu.Lock()
+	c.cacheStore.savedVersion = c.cache.currentVersion()
+	c.cacheStore.mu.Unlock()
+	debugLogf("Cache file %s: restored %d feature statuses saved at %s", c.cacheStore.location, len(snap.Features), snap.SavedAt.Format(time.RFC3339))
+}
+
+// SaveCache writes the feature cache and quota exhaustion state to the
+// cache file (SDKConfig.CacheFile) if they changed since the last save. It
+// runs with every heartbeat and on Close; call it to persist at other
+// times. Without a cache file it does nothing.
+func (c *Client) SaveCache() error {
+	store := c.cacheStore
+	if store == nil {
+		return nil
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	features, version := c.cache.snapshot()
+	if version == store.savedVersion {
+		return nil
+	}
+	snap := &cacheSnapshot{
+		Version:        cacheFileVersion,
+		ProductID:      c.productID,
+		LCCURL:         c.baseURL,
+		SavedAt:        time.Now(),
+		Features:       features,
+		QuotaExhausted: c.quotaResets.snapshot(),
+	}
+	if err := store.write(snap); err != nil {
+		return err
+	}
+	store.savedVersion = version
+	return nil
+}
+
+// snapshot copies the cache for persistence
+func (fc *featureCache) snapshot() (map[string]persistedEntry, uint64) {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	out := make(map[string]persistedEntry, len(fc.data))
+	for id, entry := range fc.data {
+		out[id] = persistedEntry{Status: entry.status, ExpiresAt: entry.expiresAt}
+	}
+	return out, fc.version
+}
+
+// restore adds persisted entries not already cached, dropping those too
+// old to serve even as stale fallbacks
+func (fc *featureCache) restore(entries map[string]persistedEntry, maxStale time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	now := time.Now()
+	for id, e := range entries {
+		if _, ok := fc.data[id]; ok || e.Status == nil || now.After(e.ExpiresAt.Add(maxStale)) {
+			continue
+		}
+		fc.data[id] = &cacheEntry{status: e.Status, expiresAt: e.ExpiresAt}
+	}
+	fc.version++
+}
+
+func (fc *featureCache) currentVersion() uint64 {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	return fc.version
+}
+
+// staleWithin returns the cached status for featureID if it expired no
+// more than maxStale ago
+func (fc *featureCache) staleWithin(featureID string, maxStale time.Duration) *FeatureStatus {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	entry, ok := fc.data[featureID]
+	if !ok || time.Now().After(entry.expiresAt.Add(maxStale)) {
+		return nil
+	}
+	return entry.status
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func newCacheFileClient(t *testing.T, url, path, key string, kp auth.KeyPair) *Client {
+	t.Helper()
+	c, err := NewClientWithKeyPair(&config.SDKConfig{
+		LCCURL:         url,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        time.Second,
+		CacheTTL:       50 * time.Millisecond,
+		CacheFile:      path,
+		CacheKey:       key,
+	}, kp)
+	if err != nil {
+		t.Fatalf("NewClientWithKeyPair() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_CacheFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	url := srv.URL
+	path := filepath.Join(t.TempDir(), "lcc-cache.json")
+	kp, _ := auth.GenerateKeyPair()
+
+	first := newCacheFileClient(t, url, path, "s3cret", kp)
+	if status, err := first.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+	resetAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	first.noteQuota(productFeatureID, &QuotaInfo{ResetAt: resetAt.Unix()}, 0)
+	if err := first.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("cache file = %v, %v; want mode 0600", info, err)
+	}
+	first.Suspend() // Close would wipe kp, which is reused below
+
+	// The server goes away; a restarted instance with a new key pair but
+	// the same cache key serves the persisted state
+	srv.Close()
+	time.Sleep(60 * time.Millisecond) // past CacheTTL
+	kp2, _ := auth.GenerateKeyPair()
+	restarted := newCacheFileClient(t, url, path, "s3cret", kp2)
+	if status, err := restarted.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeature() after restart during outage = %+v, %v; want cached enabled", status, err)
+	}
+	if exhausted, until := restarted.QuotaExhausted(productFeatureID); !exhausted || !until.Equal(resetAt) {
+		t.Errorf("QuotaExhausted() after restart = %v, %v; want true until %v", exhausted, until, resetAt)
+	}
+	if _, err := restarted.CheckFeature("unknown"); err == nil {
+		t.Error("CheckFeature() of an uncached feature during outage should fail")
+	}
+
+	// A different key does not verify
+	other := newCacheFileClient(t, url, path, "other", kp2)
+	if _, err := other.CheckFeature("reports"); err == nil {
+		t.Error("cache file written with another key should be ignored")
+	}
+
+	// Without a cache key, the private key authenticates the file
+	first = newCacheFileClient(t, url, path, "", kp)
+	first.cache.set("reports", &FeatureStatus{Enabled: true})
+	if err := first.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if c := newCacheFileClient(t, url, path, "", kp); c.cache.stale("reports") == nil {
+		t.Error("cache file should load with the same key pair")
+	}
+	if c := newCacheFileClient(t, url, path, "", kp2); c.cache.stale("reports") != nil {
+		t.Error("cache file should not load with another key pair")
+	}
+}
+
+func TestClient_CacheFileTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lcc-cache.json")
+	kp, _ := auth.GenerateKeyPair()
+	url := "http://127.0.0.1:1"
+
+	c := newCacheFileClient(t, url, path, "k", kp)
+	c.cache.setWithTTL("sso", &FeatureStatus{Enabled: false}, time.Hour)
+	if err := c.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	tampered := bytes.Replace(data, []byte(`"enabled":false`), []byte(`"enabled":true`), 1)
+	if bytes.Equal(tampered, data) {
+		t.Fatal("test did not modify the cache file")
+	}
+	_ = os.WriteFile(path, tampered, 0o600)
+
+	store := newCacheStore(path, "k", 0, "")
+	if _, err := store.read(); err != ErrCacheIntegrity {
+		t.Errorf("read() of tampered file error = %v, want ErrCacheIntegrity", err)
+	}
+	if c := newCacheFileClient(t, url, path, "k", kp); c.cache.stale("sso") != nil {
+		t.Error("tampered cache file should be ignored")
+	}
+
+	// A file for another product is ignored too
+	_ = os.Remove(path)
+	c.productID = "other-app"
+	c.cache.set("sso", &FeatureStatus{Enabled: true})
+	_ = c.SaveCache()
+	if c := newCacheFileClient(t, url, path, "k", kp); c.cache.stale("sso") != nil {
+		t.Error("cache file of another product should be ignored")
+	}
+}
+
+func TestClient_CacheFileExternalSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp, err := auth.NewSignerKeyPair(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.SDKConfig{
+		LCCURL:         "http://127.0.0.1:1",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		CacheFile:      filepath.Join(t.TempDir(), "lcc-cache.json"),
+	}
+
+	// The cache key cannot be derived from a key that cannot be exported
+	if _, err := NewClientWithKeyPair(cfg, kp); !errors.Is(err, auth.ErrKeyNotExportable) {
+		t.Fatalf("NewClientWithKeyPair() error = %v, want ErrKeyNotExportable", err)
+	}
+	cfg.CacheKey = "s3cret"
+	c, err := NewClientWithKeyPair(cfg, kp)
+	if err != nil {
+		t.Fatalf("NewClientWithKeyPair() with cache_key error = %v", err)
+	}
+	defer c.Close()
+	if err := c.SaveCache(); err != nil {
+		t.Errorf("SaveCache() error = %v", err)
+	}
+}
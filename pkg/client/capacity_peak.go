@@ -0,0 +1,99 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// CapacityPeak is the highest capacity usage observed by CheckCapacity in a
+// reporting window. Windows end at each successful heartbeat, which reports
+// the peak so the server can bill on peak rather than instantaneous usage.
+type CapacityPeak struct {
+	Peak        int
+	PeakAt      time.Time
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
+
+// capacityPeakPayload is the heartbeat encoding of a CapacityPeak
+type capacityPeakPayload struct {
+	Peak        int   `json:"peak"`
+	PeakAt      int64 `json:"peak_at"`
+	WindowStart int64 `json:"window_start"`
+	WindowEnd   int64 `json:"window_end"`
+}
+
+// capacityPeakTracker records the high-water mark of capacity usage
+type capacityPeakTracker struct {
+	mu          sync.Mutex
+	observed    bool
+	last        int
+	peak        int
+	peakAt      time.Time
+	windowStart time.Time
+}
+
+func newCapacityPeakTracker() *capacityPeakTracker {
+	return &capacityPeakTracker{windowStart: time.Now()}
+}
+
+// observe records a capacity usage sample
+func (t *capacityPeakTracker) observe(used int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.last = used
+	if !t.observed || used > t.peak {
+		t.peak = used
+		t.peakAt = time.Now()
+	}
+	t.observed = true
+}
+
+// snapshot returns the current window, or false if nothing was observed
+func (t *capacityPeakTracker) snapshot() (CapacityPeak, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.observed {
+		return CapacityPeak{}, false
+	}
+	return CapacityPeak{
+		Peak:        t.peak,
+		PeakAt:      t.peakAt,
+		WindowStart: t.windowStart,
+		WindowEnd:   time.Now(),
+	}, true
+}
+
+// reported starts a new window after p was delivered. Usage still in place
+// carries over as the new window's starting peak, and a higher peak observed
+// while p was in flight is kept.
+func (t *capacityPeakTracker) reported(p CapacityPeak) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.windowStart = p.WindowEnd
+	if t.peak > p.Peak {
+		return
+	}
+	t.peak = t.last
+	t.peakAt = p.WindowEnd
+}
+
+// payload encodes p for the heartbeat
+func (p CapacityPeak) payload() capacityPeakPayload {
+	return capacityPeakPayload{
+		Peak:        p.Peak,
+		PeakAt:      p.PeakAt.Unix(),
+		WindowStart: p.WindowStart.Unix(),
+		WindowEnd:   p.WindowEnd.Unix(),
+	}
+}
+
+// CapacityPeak returns the capacity high-water mark of the current,
+// not yet reported window. It returns false if CheckCapacity has not been
+// called.
+func (c *Client) CapacityPeak() (CapacityPeak, bool) {
+	return c.capacityPeaks.snapshot()
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClient_CapacityPeakReporting(t *testing.T) {
+	var mu sync.Mutex
+	var reported []capacityPeakPayload
+	heartbeatStatus := http.StatusOK
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/heartbeat":
+			var body struct {
+				CapacityPeak *capacityPeakPayload `json:"capacity_peak"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			defer mu.Unlock()
+			if body.CapacityPeak != nil {
+				reported = append(reported, *body.CapacityPeak)
+			}
+			w.WriteHeader(heartbeatStatus)
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{"max_capacity": 100}})
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, ok := c.CapacityPeak(); ok {
+		t.Error("CapacityPeak() before any observation should report false")
+	}
+
+	// Nothing observed: no peak in the heartbeat
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+
+	for _, used := range []int{10, 42, 30} {
+		if _, _, err := c.CheckCapacity(used); err != nil {
+			t.Fatalf("CheckCapacity(%d) error = %v", used, err)
+		}
+	}
+	if peak, ok := c.CapacityPeak(); !ok || peak.Peak != 42 {
+		t.Errorf("CapacityPeak() = %+v, %v; want 42", peak, ok)
+	}
+
+	// A failed delivery keeps the window open
+	mu.Lock()
+	heartbeatStatus = http.StatusServiceUnavailable
+	mu.Unlock()
+	_ = c.sendHeartbeat()
+	if peak, _ := c.CapacityPeak(); peak.Peak != 42 {
+		t.Errorf("CapacityPeak() after failed heartbeat = %d, want 42", peak.Peak)
+	}
+
+	mu.Lock()
+	heartbeatStatus = http.StatusOK
+	mu.Unlock()
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+
+	// The new window starts from the usage still in place
+	if peak, _ := c.CapacityPeak(); peak.Peak != 30 {
+		t.Errorf("CapacityPeak() after report = %d, want 30 (last observed)", peak.Peak)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 2 || reported[1].Peak != 42 || reported[1].WindowStart > reported[1].WindowEnd {
+		t.Errorf("reported peaks = %+v; want 42 twice (failed and delivered)", reported)
+	}
+}
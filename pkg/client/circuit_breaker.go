@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultBreakerCooldown is how long an open breaker waits before probing
+const defaultBreakerCooldown = 30 * time.Second
+
+// BreakerState is the state of the circuit breaker around LCC server calls
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails calls immediately with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe call through after the cooldown
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen is returned (wrapped) by LCC server calls made while the
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("LCC server circuit breaker is open")
+
+// circuitBreaker opens after threshold consecutive failed server calls.
+// After the cooldown the next call is let through as a probe: success
+// closes the breaker, failure reopens it for another cooldown. Heartbeats
+// go through the breaker too, so a registered client probes periodically
+// even when the application makes no calls.
+//
+// The client's breaker also holds one breaker per feature check route, with
+// the same threshold and cooldown. A 5xx answer to a feature check counts
+// against that feature's breaker only, so one failing route on the server
+// does not open the breaker for every other call. Transport errors count
+// against the client's breaker.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+
+	// Per-feature breakers, created on first use
+	features map[string]*circuitBreaker
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may be made now
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		debugLogf("Circuit breaker half-open: probing LCC server")
+		return nil
+	case BreakerHalfOpen:
+		return ErrCircuitOpen // a probe is in flight
+	}
+	return nil
+}
+
+// success records a call that reached a healthy server
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerClosed {
+		debugLogf("Circuit breaker closed: LCC server reachable")
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+}
+
+// failure records a failed call
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			debugLogf("Circuit breaker open after %d consecutive failure(s)", b.failures)
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release ends a probe that did not reach the server, so the next call
+// probes again. Caller's allow returned nil.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.state = BreakerOpen
+	}
+}
+
+// feature returns the breaker for featureID's check route, or nil for ""
+func (b *circuitBreaker) feature(featureID string) *circuitBreaker {
+	if featureID == "" {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fb, ok := b.features[featureID]
+	if !ok {
+		if b.features == nil {
+			b.features = make(map[string]*circuitBreaker)
+		}
+		fb = &circuitBreaker{threshold: b.threshold, cooldown: b.cooldown}
+		b.features[featureID] = fb
+	}
+	return fb
+}
+
+// allowFeature reports whether a call scoped to feature breaker fb (nil
+// for unscoped calls) may be made now. A refused feature call is reported
+// with the feature's ID.
+func (b *circuitBreaker) allowFeature(fb *circuitBreaker, featureID string) error {
+	if fb != nil {
+		if err := fb.allow(); err != nil {
+			return fmt.Errorf("feature %s: %w", featureID, err)
+		}
+	}
+	if err := b.allow(); err != nil {
+		if fb != nil {
+			fb.release()
+		}
+		return err
+	}
+	return nil
+}
+
+// record counts the outcome of a call allowed by allowFeature: unreachable
+// for transport errors, serverError for a server-side failure response
+func (b *circuitBreaker) record(fb *circuitBreaker, unreachable, serverError bool) {
+	switch {
+	case unreachable:
+		b.failure()
+		if fb != nil {
+			fb.release()
+		}
+	case serverError && fb != nil:
+		b.success()
+		fb.failure()
+	case serverError:
+		b.failure()
+	default:
+		b.success()
+		if fb != nil {
+			fb.success()
+		}
+	}
+}
+
+func (b *circuitBreaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// SetCircuitBreaker opens the circuit breaker around LCC server calls after
+// threshold consecutive failures (transport errors and 5xx responses), so
+// that calls fail fast with ErrCircuitOpen instead of each waiting for a
+// timeout. Feature checks then answer from the last cached status, even if
+// expired, or the fail-open policy. After cooldown (default 30s) one call
+// probes the server. threshold 0 disables the breaker.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	b := newCircuitBreaker(threshold, cooldown)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.breaker = b
+}
+
+// BreakerState returns the current state of the circuit breaker
+func (c *Client) BreakerState() BreakerState {
+	c.mu.RLock()
+	b := c.breaker
+	c.mu.RUnlock()
+	return b.current()
+}
+
+// FeatureBreakerState returns the state of the breaker around featureID's
+// check route. While it is open, checks of featureID answer from the last
+// cached status or the fail-open policy, and other calls are unaffected.
+func (c *Client) FeatureBreakerState(featureID string) BreakerState {
+	c.mu.RLock()
+	b := c.breaker
+	c.mu.RUnlock()
+
+	b.mu.Lock()
+	fb := b.features[featureID]
+	b.mu.Unlock()
+	if fb == nil {
+		return BreakerClosed
+	}
+	return fb.current()
+}
+
+type breakerFeatureKey struct{}
+
+// withBreakerFeature scopes the server calls made with ctx to featureID's
+// breaker
+func withBreakerFeature(ctx context.Context, featureID string) context.Context {
+	return context.WithValue(ctx, breakerFeatureKey{}, featureID)
+}
+
+// breakerFeature returns the feature set with withBreakerFeature, or ""
+func breakerFeature(ctx context.Context) string {
+	featureID, _ := ctx.Value(breakerFeatureKey{}).(string)
+	return featureID
+}
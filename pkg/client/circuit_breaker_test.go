@@ -0,0 +1,160 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			// Drop the connection: the server is unreachable
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.cache.ttl = time.Millisecond
+	c.SetCircuitBreaker(2, 50*time.Millisecond)
+
+	if status, err := c.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+	time.Sleep(2 * time.Millisecond) // let the cached status expire
+
+	down.Store(true)
+	for i := 0; i < 2; i++ {
+		if _, err := c.CheckFeature("export"); err == nil {
+			t.Fatal("CheckFeature() against a failing server should fail")
+		}
+	}
+	if got := c.BreakerState(); got != BreakerOpen {
+		t.Fatalf("BreakerState() = %s, want open", got)
+	}
+
+	// Open: no server calls; expired cache is used, otherwise the error
+	before := calls.Load()
+	if status, err := c.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeature(reports) while open = %+v, %v; want stale cached status", status, err)
+	}
+	if _, err := c.CheckFeature("export"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("CheckFeature(export) while open error = %v, want ErrCircuitOpen", err)
+	}
+	if err := c.ReportUsage("reports", 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("ReportUsage() while open error = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load() - before; n != 0 {
+		t.Errorf("server calls while open = %d, want 0", n)
+	}
+
+	// A failed probe reopens the breaker
+	time.Sleep(60 * time.Millisecond)
+	_, _ = c.CheckFeature("export")
+	if got := c.BreakerState(); got != BreakerOpen {
+		t.Errorf("BreakerState() after failed probe = %s, want open", got)
+	}
+
+	// A successful probe closes it
+	down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Errorf("CheckFeature() probe error = %v", err)
+	}
+	if got := c.BreakerState(); got != BreakerClosed {
+		t.Errorf("BreakerState() after successful probe = %s, want closed", got)
+	}
+}
+
+func TestClient_FeatureCircuitBreaker(t *testing.T) {
+	var exportCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/features/export/check":
+			exportCalls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetCircuitBreaker(2, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.CheckFeature("export"); err == nil {
+			t.Fatal("CheckFeature(export) should fail")
+		}
+	}
+	if got := c.FeatureBreakerState("export"); got != BreakerOpen {
+		t.Errorf("FeatureBreakerState(export) = %s, want open", got)
+	}
+	if n := exportCalls.Load(); n != 2 {
+		t.Errorf("export calls = %d, want the open breaker to stop them after 2", n)
+	}
+	if _, err := c.CheckFeature("export"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("CheckFeature(export) error = %v, want ErrCircuitOpen", err)
+	}
+
+	// Other routes are unaffected
+	if got := c.BreakerState(); got != BreakerClosed {
+		t.Errorf("BreakerState() = %s, want closed", got)
+	}
+	if status, err := c.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeature(reports) = %+v, %v", status, err)
+	}
+	if err := c.ReportUsage("reports", 1); err != nil {
+		t.Errorf("ReportUsage() error = %v", err)
+	}
+	if got := c.FeatureBreakerState("reports"); got != BreakerClosed {
+		t.Errorf("FeatureBreakerState(reports) = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	b := newCircuitBreaker(3, time.Hour)
+
+	b.failure()
+	b.failure()
+	b.success() // resets the consecutive count
+	b.failure()
+	b.failure()
+	if got := b.current(); got != BreakerClosed {
+		t.Fatalf("state after non-consecutive failures = %s, want closed", got)
+	}
+	b.failure()
+	if got := b.current(); got != BreakerOpen {
+		t.Fatalf("state after 3 consecutive failures = %s, want open", got)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() during cooldown = %v, want ErrCircuitOpen", err)
+	}
+
+	b.openedAt = time.Now().Add(-2 * time.Hour)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after cooldown = %v, want a probe", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second allow() while probing = %v, want ErrCircuitOpen", err)
+	}
+
+	disabled := newCircuitBreaker(0, 0)
+	for i := 0; i < 10; i++ {
+		disabled.failure()
+	}
+	if got := disabled.current(); got != BreakerClosed {
+		t.Errorf("disabled breaker state = %s, want closed", got)
+	}
+}
@@ -1,19 +1,23 @@
 package client
 
 import (
-	"bytes"
 	"context"
+	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/yourorg/lcc-sdk/pkg/auth"
 	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/fingerprint"
+	"github.com/yourorg/lcc-sdk/pkg/store"
 )
 
 // Client represents an LCC client instance
@@ -30,19 +34,227 @@ type Client struct {
 	productVer string
 
 	httpClient *http.Client
-	keyPair    *auth.KeyPair
-	signer     *auth.RequestSigner
+
+	// HTTP client before transport middleware (see Use)
+	baseHTTPClient *http.Client
+	middleware     []Middleware
+
+	// Servers calls fail over between, nil with a single lcc_url
+	endpoints *endpointPool
+
+	// gRPC transport (nil unless Protocol is "grpc" or SetGRPCConn was called)
+	grpc *grpcTransport
+
+	retrier    *retrier
+	breaker    *circuitBreaker
+	keyPair    auth.KeyPair
+	signer     atomic.Pointer[auth.RequestSigner] // swapped by RotateKey
+	signerOpts []auth.SignerOption
+	keyStore   auth.KeyStore // where the key pair is persisted, if anywhere
+	ids        auth.IDGenerator // nonces and usage event IDs; nil for random UUIDs
 	cache      *featureCache
 	instanceID string
 
-	// Heartbeat management
+	// Time source of request timestamps (nil for the system clock), and the
+	// same clock while it is corrected from response Date headers
+	// (SetClockSync)
+	clock     auth.Clock
+	clockSync *auth.OffsetClock
+
+	// Pinned server public keys (SetServerPins), and the base transport
+	// checking them while any are set
+	pins   *pinSet
+	pinned *pinnedTransport
+
+	// Hashes the client may sign with, preferred first (nil for the
+	// defaults), and the one negotiated with the server ("" for SHA-256)
+	hashAlgorithms []string
+	hashAlgorithm  string
+
+	// Pinned server key verifying responses (SetServerPublicKey); nil
+	// accepts unsigned responses
+	serverKey crypto.PublicKey
+
+	// Version range granted by the license (nil if unconstrained)
+	licensedVersions *VersionRange
+
+	// License edition and expiry reported at registration, if any
+	edition          string
+	licenseExpiresAt time.Time
+
+	// Deployment environment declared at registration, and the environments
+	// the license is scoped to (empty if unscoped)
+	environment          string
+	licensedEnvironments []string
+
+	// Units consumed per quota dimension since the last feature check
+	dimensions dimensionUsage
+
+	// Cluster this instance registered under (SDKConfig.ClusterID) and the
+	// latest assignment from heartbeats
+	clusterID string
+	cluster   *clusterState
+
+	// Region and labels reported with the instance metadata
+	region string
+	labels map[string]string
+
+	// Feature manifest (optional, set via SetManifest)
+	manifest *config.Manifest
+
+	// Feature check pipeline (hooks → policy → limits → cache → remote by default)
+	pipeline *checkPipeline
+
+	// Feature queries in flight, shared by concurrent cache misses
+	queries flightGroup
+
+	// Enforcement policy: global fail-open plus per-feature overrides
+	failOpen bool
+	policies map[string]config.FeaturePolicy
+
+	// Background work: one scheduler runs the heartbeat, flush and refresh
+	// jobs, whose requests draw from a shared QPS budget
 	heartbeatInterval time.Duration
-	heartbeatCancel   context.CancelFunc
-	heartbeatOnce     sync.Once
+	heartbeatJitter   float64
+	refreshInterval   time.Duration
+	scheduler         *scheduler
+	background        *backgroundBudget
+	backgroundCancel  context.CancelFunc
+
+	// Lifecycle management. lifecycleMu serializes Register and Close;
+	// state itself is guarded by mu so it can be read cheaply.
+	lifecycleMu    sync.Mutex
+	state          LifecycleState
+	registerCancel context.CancelFunc
 
 	// Zero-intrusion API fields
-	helpers    *HelperFunctions
-	tpsTracker *tpsTracker
+	helpers     *HelperFunctions
+	helperGuard *helperGuard
+	tpsTracker  *tpsTracker
+
+	// Rate limits in longer windows from SDKConfig.Limits, merged with
+	// those granted by the server
+	rateLimits []config.RateLimit
+
+	// Schedule rules from SDKConfig.Limits, applied with those granted by
+	// the license
+	schedules []config.ScheduleRule
+
+	// Quota exhaustion state and reset notifications
+	quotaResets *quotaResetTracker
+
+	// Persistent copy of the cache (nil unless SDKConfig.CacheFile or a
+	// Store is set)
+	cacheStore *cacheStore
+
+	// Persistent SDK state (nil unless created with NewClientWithStore) and
+	// the latest time seen, kept there
+	store     store.Store
+	clockMark *store.Counter
+
+	// Feature status change notifications
+	statusChanges *statusChangeNotifier
+
+	// License downgrades in transition (SetDowngradePolicy)
+	downgrades *downgradeTracker
+
+	// Server-pushed feature updates (SDKConfig.Subscribe)
+	subscribe       bool
+	subscribeCancel context.CancelFunc
+	subscribed      atomic.Bool
+	featureChanges  *featureChangeNotifier
+
+	// Worker pool running registered callbacks
+	hooks *hookDispatcher
+
+	// Product quota reserved in blocks and drawn down locally (SetQuotaLease)
+	leases *quotaLeaser
+
+	// Quotas shared by several features (Manifest.QuotaPools)
+	pools *quotaPools
+
+	// Local product quota accounting (nil unless Limits.Quota is configured)
+	localEval  bool
+	localQuota *localQuota
+
+	// Offline activation (nil unless Activate succeeded)
+	activation *offlineActivation
+
+	// Offline mode: checks are answered from a signed license file
+	offlineMode bool
+	licenseFile string
+	license     *offlineLicense
+
+	// Set while network calls are forbidden (see SetEgressDisabled)
+	egressDisabled atomic.Bool
+
+	// Lifetime of entitlement tokens (0 means DefaultEntitlementTokenTTL)
+	tokenTTL time.Duration
+
+	// Include binary build info in the registration metadata
+	reportBuildInfo bool
+
+	// Host fingerprint sent at registration, collected from
+	// fingerprintSources on first use (nil sources: not collected)
+	hostFingerprint    *fingerprint.Fingerprint
+	fingerprintSources []fingerprint.Source
+
+	// Report the SDK version with heartbeats and record the server's advisory
+	sdkUpdateCheck bool
+	sdkAdvisory    *SDKAdvisory
+
+	// Per-instance TPS share assigned via heartbeat (partitioning mode)
+	tpsPartitioning bool
+	tpsShare        *tpsShareState
+
+	// Set when the server lacks the product status endpoint, so product
+	// limits are fetched via the legacy "__product__" feature check
+	productStatusUnsupported atomic.Bool
+
+	// Token-bucket limiter backed by the TPS limit (created by Limiter)
+	limiterOnce sync.Once
+	limiter     *Limiter
+
+	// Capacity high-water mark, reported with each heartbeat
+	capacityPeaks *capacityPeakTracker
+
+	// Recent consumption and denial decisions (see UploadAudit)
+	audit *auditTrail
+
+	// Set once the identity was retired by Deactivate
+	deactivation *Deactivation
+
+	// Overflow queue for concurrency slots (nil rejects immediately when full)
+	overflow *overflowQueue
+
+	// Callers of AcquireSlotWait queued for a product-level slot
+	slotWaits slotWaitQueue
+
+	// Current holders of product-level concurrency slots, by slot ID
+	slotHolders map[uint64]*SlotHolder
+	nextSlotID  uint64
+
+	// Releases of slots handed to a replacement client, by slot ID
+	// (see SwappableClient)
+	slotForwards map[uint64]ReleaseFunc
+
+	// Workload event log for Simulate (nil unless RecordWorkload was called)
+	workload atomic.Pointer[workloadRecorder]
+
+	// Per-feature check counts and last-use times
+	featureUsage *featureUsageTracker
+
+	// Hourly usage aggregates for compliance reports
+	compliance *complianceRecorder
+
+	// Usage events awaiting acknowledgement (nil unless EnableUsageLedger)
+	usageLedger *usageLedger
+
+	// OnConsume and OnHeartbeat callbacks
+	observers observers
+
+	// Connection state derived from heartbeat results
+	connection *connectionTracker
 
 	mu sync.RWMutex
 }
@@ -52,17 +264,48 @@ type FeatureStatus struct {
 	Enabled bool   `json:"enabled"`
 	Reason  string `json:"reason,omitempty"`
 
+	// Optional licensed variant of an enabled feature (e.g. "pro", "trial")
+	Variant string `json:"variant,omitempty"`
+
 	// Optional quota information (for consumption limits)
 	Quota *QuotaInfo `json:"quota_info,omitempty"`
 
+	// Quotas holds one quota per dimension for features metered along
+	// several (e.g. rows and jobs per day), each enforced independently by
+	// ConsumeDimension. See QuotaFor.
+	Quotas []QuotaInfo `json:"quotas,omitempty"`
+
+	// QuotaPool names the pool whose quota Quota reports, if the feature
+	// shares one with other features
+	QuotaPool string `json:"quota_pool,omitempty"`
+
 	// Optional demo limits for different control types
 	MaxCapacity    int     `json:"max_capacity,omitempty"`
 	MaxTPS         float64 `json:"max_tps,omitempty"`
 	MaxConcurrency int     `json:"max_concurrency,omitempty"`
+
+	// LimitScope tells whether the limits apply to each instance
+	// (LimitScopeInstance, the default) or to the whole cluster
+	// (LimitScopeCluster)
+	LimitScope string `json:"limit_scope,omitempty"`
+
+	// Optional rate limits in longer windows, enforced alongside MaxTPS
+	RateLimits []config.RateLimit `json:"rate_limits,omitempty"`
+
+	// Schedules granted by the license, evaluated locally on every check
+	Schedules []config.ScheduleRule `json:"schedules,omitempty"`
+
+	// ActiveSchedules names the schedule rules in effect for this check
+	// (set on returned statuses only, never cached)
+	ActiveSchedules []string `json:"active_schedules,omitempty"`
 }
 
 // QuotaInfo mirrors the server-side SDKQuotaInfo structure
 type QuotaInfo struct {
+	// Dimension names what the quota meters (e.g. "rows_per_day") in
+	// FeatureStatus.Quotas; "" for a feature's single quota
+	Dimension string `json:"dimension,omitempty"`
+
 	Limit     int   `json:"limit"`
 	Used      int   `json:"used"`
 	Remaining int   `json:"remaining"`
@@ -71,9 +314,13 @@ type QuotaInfo struct {
 
 // featureCache caches feature check results
 type featureCache struct {
-	data map[string]*cacheEntry
-	ttl  time.Duration
-	mu   sync.RWMutex
+	data    map[string]*cacheEntry
+	ttl     time.Duration
+	version uint64 // incremented on every change, for persistence
+	mu      sync.RWMutex
+
+	// Lookups answered and missed by the cache stage
+	hits, misses atomic.Uint64
 }
 
 type cacheEntry struct {
@@ -98,48 +345,405 @@ func debugLogf(format string, args ...interface{}) {
 	fmt.Printf("[LCC-SDK-DEBUG] "+format+"\n", args...)
 }
 
-// NewClient creates a new LCC client using a freshly generated key pair
+// NewClient creates a new LCC client. With SDKConfig.KeyFile, KeyEnv or
+// KeyringService set it reuses the key pair saved there (see
+// NewClientWithKeyStore); otherwise it uses a freshly generated key pair of
+// the configured KeyAlgorithm, and the instance ID changes on every start.
 func NewClient(cfg *config.SDKConfig) (*Client, error) {
-	kp, err := auth.GenerateKeyPair()
+	cfg, err := prepareConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if ks := configKeyStore(cfg); ks != nil {
+		return NewClientWithKeyStore(cfg, ks)
+	}
+	kp, err := auth.GenerateKeyPairForAlgorithm(cfg.KeyAlgorithm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate key pair: %w", err)
 	}
 	return NewClientWithKeyPair(cfg, kp)
 }
 
+// prepareConfig returns a copy of cfg with zero durations defaulted (see
+// config.SDKConfig.ApplyDurationDefaults), or the config.ValidationErrors
+// of an invalid configuration. The caller's cfg is not modified.
+func prepareConfig(cfg *config.SDKConfig) (*config.SDKConfig, error) {
+	if cfg == nil {
+		return nil, config.ValidationErrors{{Field: "sdk", Message: "configuration is nil"}}
+	}
+	prepared := *cfg
+	prepared.ApplyDurationDefaults()
+	if err := prepared.Validate(); err != nil {
+		return nil, err
+	}
+	return &prepared, nil
+}
+
+// NewClientWithKeyStore creates a client using the key pair saved in ks,
+// generating and saving one of the configured KeyAlgorithm on first use, so
+// the instance ID survives restarts
+func NewClientWithKeyStore(cfg *config.SDKConfig, ks auth.KeyStore) (*Client, error) {
+	if ks == nil {
+		return nil, fmt.Errorf("key store is nil")
+	}
+	cfg, err := prepareConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := auth.LoadOrCreateKeyPair(ks, cfg.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClientWithKeyPair(cfg, kp)
+	if err != nil {
+		return nil, err
+	}
+	c.keyStore = ks
+	return c, nil
+}
+
+// configKeyStore returns the key store configured in cfg, or nil
+func configKeyStore(cfg *config.SDKConfig) auth.KeyStore {
+	switch {
+	case cfg.KeyFile != "":
+		return auth.NewFileKeyStore(cfg.KeyFile)
+	case cfg.KeyEnv != "":
+		return auth.NewEnvKeyStore(cfg.KeyEnv)
+	case cfg.KeyringService != "":
+		return auth.NewKeyringStore(cfg.KeyringService, cfg.ProductID)
+	}
+	return nil
+}
+
 // NewClientWithKeyPair creates a client using the provided key pair
-func NewClientWithKeyPair(cfg *config.SDKConfig, keyPair *auth.KeyPair) (*Client, error) {
+func NewClientWithKeyPair(cfg *config.SDKConfig, keyPair auth.KeyPair) (*Client, error) {
+	cfg, err := prepareConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(cfg, keyPair, nil)
+}
+
+// newClient creates a client. With a Store (see NewClientWithStore) the
+// client keeps its persistent state there.
+func newClient(cfg *config.SDKConfig, keyPair auth.KeyPair, st store.Store) (*Client, error) {
 	if keyPair == nil {
 		return nil, fmt.Errorf("keyPair is nil")
 	}
-	instanceID, err := keyPair.GetFingerprint()
+	instanceIDFunc := auth.FingerprintInstanceID
+	if cfg.InstanceIDHash != "" {
+		instanceIDFunc = auth.FingerprintInstanceIDWithHash(cfg.InstanceIDHash)
+	}
+	if cfg.InstanceIDSalt != "" {
+		instanceIDFunc = auth.SaltedInstanceIDWithHash(cfg.InstanceIDSalt, cfg.InstanceIDHash)
+	}
+	instanceID, err := instanceIDFunc(keyPair)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get fingerprint: %w", err)
+		return nil, fmt.Errorf("failed to derive instance ID: %w", err)
+	}
+	signerOpts := []auth.SignerOption{
+		auth.WithPathCanonicalizer(auth.PathCanonicalizer{StripPrefix: cfg.GatewayPrefix}),
+	}
+	if cfg.CertificateFile != "" {
+		certPEM, err := os.ReadFile(cfg.CertificateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate: %w", err)
+		}
+		signerOpts = append(signerOpts, auth.WithCertificate(certPEM))
+	}
+	if cfg.SignatureVersion != 0 {
+		signerOpts = append(signerOpts, auth.WithSignatureVersion(cfg.SignatureVersion))
+	}
+	if cfg.SignatureScheme == config.SignatureSchemeRSAPSS {
+		if _, ok := keyPair.(auth.PSSSigner); !ok || keyPair.Algorithm() != auth.AlgorithmRSA {
+			return nil, fmt.Errorf("signature scheme %s requires an RSA instance key, have %s", cfg.SignatureScheme, keyPair.Algorithm())
+		}
+		signerOpts = append(signerOpts, auth.WithSignatureScheme(auth.SchemeRSAPSS))
 	}
+	var serverKey crypto.PublicKey
+	if cfg.ServerPublicKeyFile != "" {
+		keyPEM, err := os.ReadFile(cfg.ServerPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read server public key: %w", err)
+		}
+		if serverKey, err = auth.ParsePublicKeyPEM(keyPEM); err != nil {
+			return nil, fmt.Errorf("failed to parse server public key: %w", err)
+		}
+	}
+	// nil keeps the default transport, which honours HTTPS_PROXY
+	var transport http.RoundTripper
+	if cfg.Proxy != nil {
+		t, err := proxyTransport(nil, cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+	}
+
+	hooks := newHookDispatcher()
+	cacheTTL := cfg.CacheTTL
+	if cfg.NoCache {
+		cacheTTL = 0
+	}
+
 	client := &Client{
 		baseURL:    cfg.LCCURL,
 		productID:  cfg.ProductID,
 		productVer: cfg.ProductVersion,
 
-		httpClient: &http.Client{Timeout: cfg.Timeout},
+		httpClient: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		retrier:    newRetrier(RetryPolicy{MaxRetries: cfg.MaxRetries}),
+		breaker:    newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 		keyPair:   keyPair,
-		signer:    auth.NewRequestSigner(keyPair),
-		cache:     &featureCache{data: make(map[string]*cacheEntry), ttl: cfg.CacheTTL},
+		signerOpts: signerOpts,
+		cache:     &featureCache{data: make(map[string]*cacheEntry), ttl: cacheTTL},
 		instanceID:          instanceID,
+		serverKey:           serverKey,
+		failOpen:            cfg.FailOpen,
 		heartbeatInterval:   defaultHeartbeatInterval,
+		heartbeatJitter:     schedulerJitter,
+		scheduler:           newScheduler(),
+		background:          &backgroundBudget{},
 		tpsTracker:          newTPSTracker(),
+		hooks:               hooks,
+		helperGuard:         newHelperGuard(hooks),
+		quotaResets:         newQuotaResetTracker(hooks),
+		statusChanges:       &statusChangeNotifier{hooks: hooks},
+		downgrades:          newDowngradeTracker(hooks, DowngradePolicy{Mode: DowngradeImmediate}),
+		featureChanges:      &featureChangeNotifier{hooks: hooks},
+		connection:          newConnectionTracker(hooks),
+		subscribe:           cfg.Subscribe,
+		localEval:           cfg.LocalEval,
+		reportBuildInfo:     cfg.ReportBuildInfo,
+		sdkUpdateCheck:      cfg.SDKUpdateCheck,
+		tpsPartitioning:     cfg.TPSPartitioning,
+		environment:         cfg.Environment,
+		clusterID:           cfg.ClusterID,
+		region:              cfg.Region,
+		labels:              copyLabels(cfg.Labels),
+		capacityPeaks:       newCapacityPeakTracker(),
+		audit:               newAuditTrail(defaultAuditSize),
+		featureUsage:        newFeatureUsageTracker(),
+		compliance:          newComplianceRecorder(),
+		leases:              &quotaLeaser{},
+		pools:               newQuotaPools(),
+		offlineMode:         cfg.OfflineMode,
+		licenseFile:         cfg.LicenseFile,
+	}
+	client.signer.Store(auth.NewRequestSigner(keyPair, signerOpts...))
+	client.egressDisabled.Store(cfg.DisableEgress)
+	client.pipeline = newCheckPipeline(client)
+	client.statusChanges.onChange = client.emitLicenseChanged
+	client.connection.onChange = client.emitConnectionChanged
+	if cfg.DegradedAfter > 0 || cfg.DisconnectedAfter > 0 {
+		client.SetConnectionThresholds(cfg.DegradedAfter, cfg.DisconnectedAfter)
+	}
+	if cfg.DedupWindow > 0 {
+		client.SetDedupWindow(cfg.DedupWindow)
+	}
+	if cfg.ClockSync {
+		client.SetClockSync(true)
+	}
+	if len(cfg.HashAlgorithms) > 0 {
+		if err := client.SetHashAlgorithms(cfg.HashAlgorithms...); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HeartbeatInterval > 0 {
+		client.SetHeartbeatInterval(cfg.HeartbeatInterval)
+	}
+	if cfg.HeartbeatJitter > 0 {
+		client.SetHeartbeatJitter(cfg.HeartbeatJitter)
+	}
+	if cfg.BackgroundQPS > 0 {
+		client.SetBackgroundQPS(cfg.BackgroundQPS)
+	}
+	if cfg.BackgroundRefresh > 0 {
+		client.SetBackgroundRefresh(cfg.BackgroundRefresh)
+	}
+	if cfg.Fingerprint {
+		client.fingerprintSources = fingerprint.DefaultSources
+		if len(cfg.FingerprintSources) > 0 {
+			client.fingerprintSources = nil
+			for _, name := range cfg.FingerprintSources {
+				source, err := fingerprint.ParseSource(name)
+				if err != nil {
+					return nil, err
+				}
+				client.fingerprintSources = append(client.fingerprintSources, source)
+			}
+		}
+	}
+	if cfg.QuotaLease > 0 {
+		client.SetQuotaLease(cfg.QuotaLease)
+	}
+	if cfg.DowngradePolicy != "" {
+		client.SetDowngradePolicy(DowngradePolicy{Mode: DowngradeMode(cfg.DowngradePolicy), Grace: cfg.DowngradeGrace})
+	}
+
+	if cfg.Limits != nil && cfg.Limits.Quota != nil {
+		lq, err := newLocalQuota(cfg.Limits.Quota)
+		if err != nil {
+			return nil, err
+		}
+		client.localQuota = lq
+	}
+	if cfg.CacheFile != "" {
+		keyPEM, err := cacheKeyMaterial(cfg, keyPair)
+		if err != nil {
+			return nil, err
+		}
+		client.cacheStore = newCacheStore(cfg.CacheFile, cfg.CacheKey, cfg.CacheMaxStale, keyPEM)
+		client.loadCacheFile()
+	}
+	if cfg.UsageJournal != "" {
+		if err := client.EnableUsageLedger(cfg.UsageJournal); err != nil {
+			return nil, err
+		}
+	}
+	if st != nil {
+		if err := client.useStore(st, cfg); err != nil {
+			return nil, err
+		}
 	}
+	if cfg.Limits != nil && len(cfg.Limits.RateLimits) > 0 {
+		client.rateLimits = cfg.Limits.RateLimits
+		client.tpsTracker.windows.track(client.rateLimitsFor(&FeatureStatus{}))
+	}
+	if cfg.Limits != nil {
+		client.schedules = cfg.Limits.Schedules
+	}
+	if cfg.Limits != nil && len(cfg.Limits.Expressions) > 0 {
+		stage, err := newLimitExprStage(client, cfg.Limits)
+		if err != nil {
+			return nil, err
+		}
+		client.pipeline.insert(client.pipeline.indexOf(StageCache), stage)
+	}
+	if cfg.Limits != nil && cfg.Limits.OverflowQueue > 0 {
+		client.overflow = newOverflowQueue(cfg.Limits.OverflowQueue, cfg.Limits.MaxWait)
+	}
+
+	switch {
+	case cfg.Discovery != nil && !cfg.OfflineMode:
+		pool, err := newDiscoveryPool(cfg.Discovery, EndpointSelection(cfg.EndpointSelection), cfg.HealthCheckInterval)
+		if err != nil {
+			return nil, err
+		}
+		client.endpoints = pool
+		client.baseURL = pool.base.String()
+	case len(cfg.LCCURLs) > 0:
+		urls := append([]string{cfg.LCCURL}, cfg.LCCURLs...)
+		pool, err := newEndpointPool(cfg.LCCURL, urls, cfg.EndpointWeights, EndpointSelection(cfg.EndpointSelection), cfg.HealthCheckInterval)
+		if err != nil {
+			return nil, err
+		}
+		client.endpoints = pool
+	}
+
+	pins, err := parsePins(cfg.ServerPins)
+	if err != nil {
+		return nil, err
+	}
+	client.pins = &pinSet{}
+	client.pins.set(pins)
+	client.baseHTTPClient = client.httpClient
+	client.applyMiddlewareLocked()
+
+	if cfg.Protocol == config.ProtocolGRPC && !cfg.OfflineMode {
+		t, err := newGRPCTransport(cfg.LCCURL, cfg.Timeout, client.pins)
+		if err != nil {
+			return nil, err
+		}
+		client.grpc = t
+	}
+
 	return client, nil
 }
-// SetHTTPClient allows setting a custom HTTP client (e.g., for TLS config)
+// SetHTTPClient allows setting a custom HTTP client (e.g., for TLS config).
+// Middleware added with Use is applied on top of it. It is safe to call at
+// any time: requests in flight finish on the previous client, later ones
+// use the new one, and idle connections of the previous client are closed.
+// nil restores a default client with the current timeout.
 func (c *Client) SetHTTPClient(client *http.Client) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.httpClient = client
+	old := c.swapHTTPClient(func(old *http.Client) *http.Client {
+		if client == nil {
+			return &http.Client{Timeout: old.Timeout}
+		}
+		return client
+	})
+	if old != client {
+		closeIdleTransport(old)
+	}
 }
 
-// Register registers this application instance with LCC
+// Register registers this application instance with LCC.
+//
+// If the license constrains the allowed product versions and the configured
+// ProductVersion falls outside that range, Register returns a
+// *VersionNotLicensedError (matching ErrVersionNotLicensed) and does not
+// start the heartbeat loop.
+//
+// Register is idempotent: calling it on a registered client is a no-op, and
+// concurrent calls are serialized. Calling it on a closed client returns a
+// *StateError matching ErrClientClosed.
 func (c *Client) Register() error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	return c.registerLocked()
+}
+
+// registerLocked drives the New → Registered transition. Caller holds c.lifecycleMu.
+func (c *Client) registerLocked() error {
+	switch state := c.Lifecycle(); state {
+	case StateRegistered:
+		debugLogf("Register: already registered, ignoring")
+		return nil
+	case StateClosed, StateDeactivated:
+		return &StateError{Op: "register", State: state}
+	}
+
+	// Close cancels this context to abort an in-flight registration
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.registerCancel = cancel
+	c.mu.Unlock()
+	defer func() {
+		cancel()
+		c.mu.Lock()
+		c.registerCancel = nil
+		c.mu.Unlock()
+	}()
+
+	// Offline mode has no server to register with, only a license
+	if c.OfflineMode() {
+		if c.loadedLicense() == nil {
+			return ErrNoLicense
+		}
+		c.setState(StateRegistered)
+		c.emit(Event{Type: EventRegisterSucceeded})
+		return nil
+	}
+
+	c.setState(StateRegistering)
+	if err := c.register(ctx); err != nil {
+		c.setState(StateNew)
+		return err
+	}
+	c.setState(StateRegistered)
+	c.emit(Event{Type: EventRegisterSucceeded})
+
+	// Start background work after successful registration
+	c.startBackground()
+	c.startSubscription()
+
+	return nil
+}
+
+// register performs the registration request. Caller holds c.lifecycleMu.
+func (c *Client) register(ctx context.Context) error {
+	fp := c.collectFingerprint(ctx)
+	instance := c.InstanceMetadata()
 	c.mu.Lock()
 
 	debugLogf("Register called: baseURL=%s productID=%s version=%s", c.baseURL, c.productID, c.productVer)
@@ -150,48 +754,91 @@ func (c *Client) Register() error {
 		return fmt.Errorf("failed to export public key: %w", err)
 	}
 
-	// Get local IP and hostname for topology display
-	ip := getLocalIP()
-	hostname, _ := os.Hostname()
+	// Host, region and labels for topology display
+	metadata := instance.fields()
+	if c.reportBuildInfo {
+		metadata["build"] = collectBuildInfo()
+	}
+	if c.clusterID != "" {
+		metadata["cluster_id"] = c.clusterID
+	}
+	if fp != nil {
+		metadata["fingerprint"] = fp
+	}
 
 	reqBody := map[string]interface{}{
 		"product_id": c.productID,
 		"version":    c.productVer,
 		"public_key": pubPEM,
-		"metadata": map[string]interface{}{
-			"ip":       ip,
-			"hostname": hostname,
-		},
+		"metadata":   metadata,
+	}
+	if c.environment != "" {
+		reqBody["environment"] = c.environment
+	}
+	if c.clusterID != "" {
+		reqBody["cluster_id"] = c.clusterID
+	}
+	if fp != nil {
+		reqBody["fingerprint"] = fp.ID
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
+	c.mu.Unlock() // Release lock before the call to avoid blocking heartbeat goroutine
+
+	var result registerResponse
+	if c.grpc != nil {
+		result, err = c.registerGRPC(ctx, pubPEM, metadata)
+	} else {
+		result, err = c.registerHTTP(ctx, reqBody)
+	}
 	if err != nil {
-		c.mu.Unlock()
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return err
 	}
 
-	url := c.baseURL + "/api/v1/sdk/register"
-	debugLogf("Register: creating POST %s", url)
-
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
+	err = checkVersion(c.productVer, result.LicensedVersions)
+	if err == nil {
+		err = checkEnvironment(c.environment, result.LicensedEnvironments)
+	}
 	if err != nil {
-		c.mu.Unlock()
-		return fmt.Errorf("failed to create request: %w", err)
+		debugLogf("Register: %v", err)
+		// The server accepted the instance: release it, so an unlicensed
+		// version or environment does not hold a seat until heartbeats time out
+		if derr := c.deregister(ctx); derr != nil {
+			debugLogf("WARNING: Register: %v", derr)
+		}
+		return err
 	}
 
-	// Sign request
-	if err := c.signer.SignRequest(req); err != nil {
-		c.mu.Unlock()
-		return fmt.Errorf("failed to sign request: %w", err)
+	c.mu.Lock()
+	c.licensedVersions = result.LicensedVersions
+	c.licensedEnvironments = result.LicensedEnvironments
+	c.edition = result.Edition
+	c.licenseExpiresAt = time.Time{}
+	if result.LicenseExpiresAt > 0 {
+		c.licenseExpiresAt = time.Unix(result.LicenseExpiresAt, 0)
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// registerHTTP sends the registration request over HTTP
+func (c *Client) registerHTTP(ctx context.Context, reqBody map[string]interface{}) (registerResponse, error) {
+	var result registerResponse
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	c.mu.Unlock() // Release lock before HTTP call to avoid blocking heartbeat goroutine
+	url := c.baseURL + "/api/v1/sdk/register"
+	debugLogf("Register: creating POST %s", url)
 
-	debugLogf("Register: executing HTTP request (timeout=%s)...", c.httpClient.Timeout)
-	resp, err := c.httpClient.Do(req)
+	debugLogf("Register: executing HTTP request (timeout=%s)...", c.Timeout())
+	resp, err := c.doWithRetry(ctx, "Register", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", url, bodyBytes)
+	})
 	if err != nil {
 		debugLogf("Register: HTTP request error: %v", err)
-		return fmt.Errorf("request failed: %w", err)
+		return result, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -200,14 +847,31 @@ func (c *Client) Register() error {
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		debugLogf("Register: non-200 response body=%s", string(body))
-		return fmt.Errorf("registration failed: status=%d, body=%s", resp.StatusCode, string(body))
+		return result, fmt.Errorf("registration failed: status=%d, body=%s", resp.StatusCode, string(body))
 	}
 
-	// Start background heartbeat loop after successful registration
-	c.startHeartbeatLoop()
-	debugLogf("Register: heartbeat loop started for instance %s", c.instanceID)
+	// Older servers return an empty or unstructured body; only the optional
+	// licensed version range is of interest here.
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && err != io.EOF {
+		debugLogf("Register: ignoring undecodable response body: %v", err)
+	}
+	return result, nil
+}
 
-	return nil
+// registerResponse is the subset of the registration response used by the SDK
+type registerResponse struct {
+	LicensedVersions     *VersionRange `json:"licensed_versions,omitempty"`
+	LicensedEnvironments []string      `json:"licensed_environments,omitempty"`
+	Edition              string        `json:"edition,omitempty"`
+	LicenseExpiresAt     int64         `json:"license_expires_at,omitempty"`
+}
+
+// LicensedVersions returns the product version range granted by the license,
+// as reported at registration. Returns nil if the license is not version-locked.
+func (c *Client) LicensedVersions() *VersionRange {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.licensedVersions
 }
 
 // CheckFeature checks if a feature is enabled in the License.
@@ -224,22 +888,17 @@ func (c *Client) Register() error {
 // - Reason: explanation if disabled (e.g., "feature_not_in_license", "quota_exceeded")
 // - Quota: quota information if applicable
 // - Capacity/TPS/Concurrency: limits from license
+//
+// The decision is produced by the client's check pipeline (see Stage).
 func (c *Client) CheckFeature(featureID string) (*FeatureStatus, error) {
-	// Check cache first
-	if status := c.cache.get(featureID); status != nil {
-		return status, nil
-	}
+	return c.checkFeature(context.Background(), featureID)
+}
 
-	// Query LCC
-	status, err := c.queryFeature(featureID)
-	if err != nil {
-		return nil, err
+func (c *Client) checkFeature(ctx context.Context, featureID string) (*FeatureStatus, error) {
+	if featureID != productFeatureID {
+		c.recordEvent(WorkloadEvent{Kind: EventCheck, FeatureID: featureID})
 	}
-
-	// Cache result
-	c.cache.set(featureID, status)
-
-	return status, nil
+	return c.pipeline.run(ctx, &CheckRequest{FeatureID: featureID})
 }
 
 // RegisterHelpers registers helper functions for zero-intrusion API usage.
@@ -293,81 +952,154 @@ func (c *Client) getInternalTPS() float64 {
 // checkProductLimits checks product-level limits (not feature-specific)
 // This is used by the zero-intrusion API methods
 func (c *Client) checkProductLimits() (*FeatureStatus, error) {
-	// Use a special product-level feature ID
-	// The server should recognize this and return product-level limits
-	return c.CheckFeature("__product__")
+	// The product-level ID flows through the pipeline like any feature;
+	// the remote stage fetches it from the product status endpoint
+	return c.CheckFeature(productFeatureID)
 }
 
 // reportProductUsage reports usage at the product level
 func (c *Client) reportProductUsage(amount int) error {
-	return c.ReportUsage("__product__", float64(amount))
+	return c.ReportUsage(productFeatureID, float64(amount))
 }
 
-// SetHeartbeatInterval sets the heartbeat interval. Set to 0 to disable heartbeat.
+// SetHeartbeatInterval sets the heartbeat interval; 0 restores the default
+// (5s). Heartbeats already running are rescheduled. See
+// SDKConfig.HeartbeatInterval.
 func (c *Client) SetHeartbeatInterval(interval time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.heartbeatInterval = interval
+	if c.backgroundCancel != nil {
+		c.scheduleHeartbeatLocked()
+	}
 }
 
-// startHeartbeatLoop starts a background goroutine that periodically
-// sends heartbeat requests to LCC. Uses sync.Once to ensure only one
-// heartbeat goroutine is created per client instance.
-func (c *Client) startHeartbeatLoop() {
-	c.heartbeatOnce.Do(func() {
-		interval := c.heartbeatInterval
-		if interval <= 0 {
-			interval = defaultHeartbeatInterval
+// SetHeartbeatJitter spreads each heartbeat by up to ±jitter of the
+// interval (0 to 0.5; default 0.1), so a fleet restarted together does not
+// reach the server in waves. See SDKConfig.HeartbeatJitter.
+func (c *Client) SetHeartbeatJitter(jitter float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.heartbeatJitter = min(max(jitter, 0), 0.5)
+	if c.backgroundCancel != nil {
+		c.scheduleHeartbeatLocked()
+	}
+}
+
+// scheduleHeartbeatLocked (re)schedules the heartbeat and flush jobs.
+// Caller holds c.mu.
+func (c *Client) scheduleHeartbeatLocked() {
+	interval := c.heartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	c.scheduler.addJittered(jobHeartbeat, interval, c.heartbeatJitter, c.heartbeat)
+	c.scheduler.add(jobFlush, interval, c.flushBackground)
+}
+
+// startBackground starts the scheduler goroutine running the background
+// jobs: heartbeats and flushes every heartbeat interval, plus cache
+// refreshes, endpoint health checks and discovery if enabled. At most one scheduler goroutine runs per client;
+// Close stops it.
+func (c *Client) startBackground() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.backgroundCancel != nil {
+		return
+	}
+
+	c.scheduleHeartbeatLocked()
+	if c.endpoints != nil {
+		c.scheduler.add(jobHealthCheck, c.endpoints.interval, c.checkEndpoints)
+		if c.endpoints.resolver != nil {
+			c.scheduler.add(jobDiscovery, c.endpoints.refresh, c.discoverEndpoints)
 		}
+	}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		c.heartbeatCancel = cancel
-
-		go func() {
-			ticker := time.NewTicker(interval)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					_ = c.sendHeartbeat()
-				}
-			}
-		}()
+	ctx, cancel := context.WithCancel(context.Background())
+	c.backgroundCancel = cancel
+	go c.scheduler.run(ctx)
 
-		debugLogf("Heartbeat loop started for instance %s", c.instanceID)
-	})
+	debugLogf("Background jobs started for instance %s: %v", c.instanceID, c.scheduler.names())
+}
+
+// stopBackground stops the scheduler goroutine, if running
+func (c *Client) stopBackground() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.backgroundCancel != nil {
+		c.backgroundCancel()
+		c.backgroundCancel = nil
+	}
 }
 
 // sendHeartbeat sends a single heartbeat request to LCC.
 // Errors are returned to the caller but are not retried here.
 func (c *Client) sendHeartbeat() error {
+	if err := c.checkOpen("send heartbeat"); err != nil {
+		return err
+	}
+	if c.OfflineMode() {
+		return nil
+	}
+	if c.grpcTransport() != nil {
+		return c.heartbeatGRPC()
+	}
+
 	payload := map[string]interface{}{
 		"version": c.productVer,
 	}
+	if c.tpsPartitioning {
+		// Ask for a share of the fleet TPS limit and report local load
+		payload["tps_partitioning"] = true
+		if tps, err := c.getCurrentTPS(); err == nil {
+			payload["current_tps"] = tps
+		}
+	}
+	if c.clusterID != "" {
+		payload["cluster_id"] = c.clusterID
+	}
+	if c.sdkUpdateCheck {
+		payload["sdk_version"] = SDKVersion()
+	}
+	// Refreshed so the console follows IP and label changes
+	payload["metadata"] = c.InstanceMetadata().fields()
+	payload["telemetry"] = c.Telemetry()
+	// The capacity peak is a cluster-level metric; in a cluster only the
+	// elected reporter sends it
+	peak, hasPeak := c.capacityPeaks.snapshot()
+	hasPeak = hasPeak && c.IsClusterReporter()
+	if hasPeak {
+		payload["capacity_peak"] = peak.payload()
+	}
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal heartbeat payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/api/v1/sdk/heartbeat", bytes.NewReader(bodyBytes))
+	resp, err := c.doWithRetry(context.Background(), "Heartbeat", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", c.baseURL+"/api/v1/sdk/heartbeat", bodyBytes)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create heartbeat request: %w", err)
+		return fmt.Errorf("heartbeat request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if err := c.signer.SignRequest(req); err != nil {
-		return fmt.Errorf("failed to sign heartbeat request: %w", err)
+	if hasPeak && resp.StatusCode == http.StatusOK {
+		c.capacityPeaks.reported(peak)
 	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("heartbeat request failed: %w", err)
+	if resp.StatusCode == http.StatusOK {
+		c.applyHeartbeatResponse(resp.Body)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		// Counts towards a degraded connection (see State)
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("heartbeat failed: status=%d, body=%s", resp.StatusCode, string(body))
 	}
-	defer resp.Body.Close()
 
-	// Drain response body and ignore content; heartbeat is best-effort
+	// Drain response body; heartbeat is best-effort
 	_, _ = io.Copy(io.Discard, resp.Body)
 
 	return nil
@@ -375,19 +1107,25 @@ func (c *Client) sendHeartbeat() error {
 
 // queryFeature queries LCC for feature status
 func (c *Client) queryFeature(featureID string) (*FeatureStatus, error) {
-	url := fmt.Sprintf("%s/api/v1/sdk/features/%s/check", c.baseURL, featureID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if err := c.checkOpen("check feature"); err != nil {
+		return nil, err
+	}
+	if c.OfflineMode() {
+		return c.offlineStatus(featureID)
 	}
 
-	// Sign request
-	if err := c.signer.SignRequest(req); err != nil {
-		return nil, fmt.Errorf("failed to sign request: %w", err)
+	if featureID == productFeatureID && !c.productStatusUnsupported.Load() {
+		return c.queryProductStatus()
 	}
+	if c.grpcTransport() != nil {
+		return c.checkFeatureGRPC(featureID)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/sdk/features/%s/check", c.baseURL, featureID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(withBreakerFeature(context.Background(), featureID), "CheckFeature", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "GET", url, nil)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -397,30 +1135,53 @@ func (c *Client) queryFeature(featureID string) (*FeatureStatus, error) {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("feature check failed: status=%d, body=%s", resp.StatusCode, string(body))
 	}
-
-	var result struct {
-		FeatureID      string     `json:"feature_id"`
-		Enabled        bool       `json:"enabled"`
-		Reason         string     `json:"reason"`
-		QuotaInfo      *QuotaInfo `json:"quota_info,omitempty"`
-		MaxCapacity    int        `json:"max_capacity,omitempty"`
-		MaxTPS         float64    `json:"max_tps,omitempty"`
-		MaxConcurrency int        `json:"max_concurrency,omitempty"`
-		CacheTTL       int        `json:"cache_ttl"`
+	if err := c.verifyResponse(resp); err != nil {
+		return nil, err
 	}
 
+	var result featureCheckResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	return result.status(), nil
+}
+
+// featureCheckResponse is the feature status returned by the check endpoint
+// and pushed in subscription events
+type featureCheckResponse struct {
+	FeatureID      string     `json:"feature_id"`
+	Enabled        bool       `json:"enabled"`
+	Reason         string     `json:"reason"`
+	Variant        string     `json:"variant,omitempty"`
+	QuotaInfo      *QuotaInfo `json:"quota_info,omitempty"`
+	QuotaPool      string     `json:"quota_pool,omitempty"`
+	MaxCapacity    int        `json:"max_capacity,omitempty"`
+	MaxTPS         float64    `json:"max_tps,omitempty"`
+	MaxConcurrency int        `json:"max_concurrency,omitempty"`
+	CacheTTL       int        `json:"cache_ttl"`
+	LimitScope     string     `json:"limit_scope,omitempty"`
+
+	Quotas     []QuotaInfo           `json:"quotas,omitempty"`
+	RateLimits []config.RateLimit    `json:"rate_limits,omitempty"`
+	Schedules  []config.ScheduleRule `json:"schedules,omitempty"`
+}
+
+func (r *featureCheckResponse) status() *FeatureStatus {
 	return &FeatureStatus{
-		Enabled:        result.Enabled,
-		Reason:         result.Reason,
-		Quota:          result.QuotaInfo,
-		MaxCapacity:    result.MaxCapacity,
-		MaxTPS:         result.MaxTPS,
-		MaxConcurrency: result.MaxConcurrency,
-	}, nil
+		Enabled:        r.Enabled,
+		Reason:         r.Reason,
+		Variant:        r.Variant,
+		Quota:          r.QuotaInfo,
+		Quotas:         r.Quotas,
+		QuotaPool:      r.QuotaPool,
+		MaxCapacity:    r.MaxCapacity,
+		MaxTPS:         r.MaxTPS,
+		MaxConcurrency: r.MaxConcurrency,
+		LimitScope:     r.LimitScope,
+		RateLimits:     r.RateLimits,
+		Schedules:      r.Schedules,
+	}
 }
 
 // ========== Zero-Intrusion Product-Level API (New) ==========
@@ -439,20 +1200,66 @@ type ReleaseFunc func()
 //   - remaining: remaining quota after consumption
 //   - error: any error during the check
 //
+// When SDKConfig.LocalEval is set and Limits.Quota is configured, the
+// decision is made entirely from local windowed accounting. Without
+// LocalEval, local accounting is used only if the LCC server is unreachable.
+// In offline mode the quota granted by the license is used (see UseLicense).
+// With a quota lease (see SetQuotaLease), units are drawn from the lease
+// and remaining is the units left in it.
+//
 // Example:
 //   allowed, remaining, err := client.Consume(1)
 //   if err != nil || !allowed {
 //       return fmt.Errorf("quota exceeded")
 //   }
 func (c *Client) Consume(amount int) (bool, int, error) {
+	return c.consumeObserved(amount, nil)
+}
+
+// consumeObserved makes the Consume decision and reports it to the audit
+// trail and OnConsume callbacks. args are the helper args of a
+// ConsumeWithContext call, recorded with a denial.
+func (c *Client) consumeObserved(amount int, args []interface{}) (bool, int, error) {
+	allowed, remaining, err := c.consume(amount)
+	ev := newConsumeEvent(amount, allowed, remaining, err)
+	if ev.Allowed || ev.Denied {
+		c.compliance.use(productFeatureID, "", float64(amount), ev.Denied)
+	}
+	c.recordConsume(ev, args)
+	c.notifyConsume(ev)
+	return allowed, remaining, err
+}
+
+// consume makes the Consume decision
+func (c *Client) consume(amount int) (bool, int, error) {
 	// Record TPS for internal tracking
 	if c.tpsTracker != nil {
 		c.tpsTracker.RecordRequest()
 	}
+	c.recordEvent(WorkloadEvent{Kind: EventConsume, Amount: amount})
+
+	// Deny locally while the quota window is known to be exhausted
+	if resetAt, exhausted := c.quotaResets.exhaustedUntil(productFeatureID); exhausted {
+		return false, 0, fmt.Errorf("quota exceeded: %s (resets in %s)", ReasonQuotaExhausted, time.Until(resetAt).Round(time.Second))
+	}
+
+	if c.OfflineMode() {
+		return c.consumeOffline(amount)
+	}
+	if c.localQuota != nil && c.localEval {
+		return c.consumeLocal(amount)
+	}
+	if allowed, remaining, err := c.consumeLeased(amount); !errors.Is(err, errNoLease) {
+		return allowed, remaining, err
+	}
 
 	// Check product-level quota
 	status, err := c.checkProductLimits()
 	if err != nil {
+		if c.localQuota != nil {
+			debugLogf("Consume: server check failed, using local quota accounting: %v", err)
+			return c.consumeLocal(amount)
+		}
 		return false, 0, err
 	}
 
@@ -461,6 +1268,7 @@ func (c *Client) Consume(amount int) (bool, int, error) {
 		if status.Quota != nil {
 			remaining = status.Quota.Remaining
 		}
+		c.noteQuota(productFeatureID, status.Quota, 0)
 		return false, remaining, fmt.Errorf("quota exceeded: %s", status.Reason)
 	}
 
@@ -468,6 +1276,10 @@ func (c *Client) Consume(amount int) (bool, int, error) {
 	if err := c.reportProductUsage(amount); err != nil {
 		return false, 0, err
 	}
+	c.noteQuota(productFeatureID, status.Quota, amount)
+	if c.localQuota != nil {
+		c.localQuota.record(time.Now(), amount)
+	}
 
 	remaining := 0
 	if status.Quota != nil {
@@ -487,6 +1299,9 @@ func (c *Client) Consume(amount int) (bool, int, error) {
 // function passes the original function arguments to calculate dynamic
 // consumption amounts.
 //
+// With an ArgsRedactor helper, a denial's audit record keeps the redacted
+// args.
+//
 // Returns:
 //   - allowed: true if quota is available
 //   - remaining: remaining quota after consumption
@@ -506,8 +1321,13 @@ func (c *Client) ConsumeWithContext(ctx context.Context, args ...interface{}) (b
 		return false, 0, fmt.Errorf("QuotaConsumer helper not registered")
 	}
 
-	amount := helpers.QuotaConsumer(ctx, args...)
-	return c.Consume(amount)
+	amount, err := callHelper(c, ctx, HelperQuotaConsumer, 1, func(ctx context.Context) int {
+		return helpers.QuotaConsumer(ctx, args...)
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return c.consumeObserved(amount, args)
 }
 
 // ConsumeDeprecated performs a consumption-style check+usage for an event-based feature.
@@ -517,6 +1337,10 @@ func (c *Client) ConsumeWithContext(ctx context.Context, args ...interface{}) (b
 // DEPRECATED: Use product-level Consume() or ConsumeWithContext() instead.
 // This method is kept for backward compatibility only.
 func (c *Client) ConsumeDeprecated(featureID string, amount int, meta map[string]any) (bool, int, string, error) {
+	if _, exhausted := c.quotaResets.exhaustedUntil(featureID); exhausted {
+		return false, 0, ReasonQuotaExhausted, nil
+	}
+
 	status, err := c.CheckFeature(featureID)
 	if err != nil {
 		return false, 0, "check_error", err
@@ -526,6 +1350,7 @@ func (c *Client) ConsumeDeprecated(featureID string, amount int, meta map[string
 		if status.Quota != nil {
 			remaining = status.Quota.Remaining
 		}
+		c.noteQuota(featureID, status.Quota, 0)
 		return false, remaining, status.Reason, nil
 	}
 
@@ -533,6 +1358,7 @@ func (c *Client) ConsumeDeprecated(featureID string, amount int, meta map[string
 	if err := c.ReportUsage(featureID, float64(amount)); err != nil {
 		return false, 0, "usage_error", err
 	}
+	c.noteQuota(featureID, status.Quota, amount)
 
 	remaining := 0
 	if status.Quota != nil {
@@ -564,6 +1390,10 @@ func (c *Client) ConsumeDeprecated(featureID string, amount int, meta map[string
 //       return fmt.Errorf("capacity exceeded: %d/%d", currentUsers, max)
 //   }
 func (c *Client) CheckCapacity(currentUsed int) (bool, int, error) {
+	c.capacityPeaks.observe(currentUsed)
+	c.compliance.capacity(currentUsed)
+	c.recordEvent(WorkloadEvent{Kind: EventCapacity, Amount: currentUsed})
+
 	status, err := c.checkProductLimits()
 	if err != nil {
 		return false, 0, err
@@ -605,7 +1435,12 @@ func (c *Client) CheckCapacityWithHelper() (bool, int, error) {
 		return false, 0, fmt.Errorf("CapacityCounter helper not registered (required)")
 	}
 
-	currentUsed := helpers.CapacityCounter()
+	currentUsed, err := callHelper(c, context.Background(), HelperCapacityCounter, 0, func(context.Context) int {
+		return helpers.CapacityCounter()
+	})
+	if err != nil {
+		return false, 0, err
+	}
 	return c.CheckCapacity(currentUsed)
 }
 
@@ -639,9 +1474,14 @@ func (c *Client) CheckCapacityDeprecated(featureID string, currentUsed int) (boo
 // Uses the registered TPSProvider helper if available, otherwise uses
 // SDK internal TPS tracking.
 //
+// Rate limits in longer windows (ProductLimits.RateLimits and those granted
+// by the license) are checked too, against the Consume calls in each
+// trailing window; a violation is reported as a *RateWindowError naming the
+// window.
+//
 // Returns:
 //   - allowed: true if TPS is within limit
-//   - maxTPS: the maximum TPS limit
+//   - maxTPS: the maximum TPS limit (0 if none)
 //   - error: any error during the check
 //
 // Example:
@@ -651,7 +1491,10 @@ func (c *Client) CheckCapacityDeprecated(featureID string, currentUsed int) (boo
 //   }
 func (c *Client) CheckTPS() (bool, float64, error) {
 	// Get current TPS from helper or internal tracker
-	currentTPS := c.getCurrentTPS()
+	currentTPS, err := c.getCurrentTPS()
+	if err != nil {
+		return false, 0, err
+	}
 
 	// Check against product limit
 	status, err := c.checkProductLimits()
@@ -659,28 +1502,33 @@ func (c *Client) CheckTPS() (bool, float64, error) {
 		return false, 0, err
 	}
 
-	maxTPS := status.MaxTPS
-	if maxTPS <= 0 {
-		return true, 0, nil // No TPS limit configured
+	// With TPS partitioning the instance share is enforced instead
+	maxTPS := c.effectiveMaxTPS(c.clusterLimits(status).MaxTPS)
+	if maxTPS > 0 && currentTPS > maxTPS {
+		err := fmt.Errorf("TPS exceeded: %.2f > %.2f", currentTPS, maxTPS)
+		c.emit(Event{Type: EventTPSExceeded, FeatureID: productFeatureID, Err: err})
+		return false, maxTPS, err
 	}
-
-	if currentTPS > maxTPS {
-		return false, maxTPS, fmt.Errorf("TPS exceeded: %.2f > %.2f", currentTPS, maxTPS)
+	if err := c.tpsTracker.windows.exceeded(c.rateLimitsFor(status), time.Now(), false); err != nil {
+		c.emit(Event{Type: EventTPSExceeded, FeatureID: productFeatureID, Err: err})
+		return false, maxTPS, err
 	}
 
 	return true, maxTPS, nil
 }
 
 // getCurrentTPS gets TPS from helper or internal tracker
-func (c *Client) getCurrentTPS() float64 {
+func (c *Client) getCurrentTPS() (float64, error) {
 	c.mu.RLock()
 	helpers := c.helpers
 	c.mu.RUnlock()
 
 	if helpers != nil && helpers.TPSProvider != nil {
-		return helpers.TPSProvider()
+		return callHelper(c, context.Background(), HelperTPSProvider, 0, func(context.Context) float64 {
+			return helpers.TPSProvider()
+		})
 	}
-	return c.getInternalTPS()
+	return c.getInternalTPS(), nil
 }
 
 // CheckTPSDeprecated compares an APP-provided currentTPS against the license-defined
@@ -725,7 +1573,21 @@ func (c *Client) CheckTPSDeprecated(featureID string, currentTPS float64) (bool,
 //   defer release()
 //   // ... perform operation ...
 func (c *Client) AcquireSlot() (ReleaseFunc, bool, error) {
-	status, err := c.checkProductLimits()
+	return c.AcquireSlotContext(context.Background())
+}
+
+// AcquireSlotContext is AcquireSlot with a context bounding the time spent
+// waiting in the overflow queue (see SetOverflowQueue).
+func (c *Client) AcquireSlotContext(ctx context.Context) (ReleaseFunc, bool, error) {
+	return c.AcquireSlotAs(ctx, "", nil)
+}
+
+// AcquireSlotAs is AcquireSlotContext with an owner label and metadata
+// recorded for the slot while it is held (see ActiveSlots). meta is copied.
+func (c *Client) AcquireSlotAs(ctx context.Context, owner string, meta map[string]any) (ReleaseFunc, bool, error) {
+	holder := newSlotHolder(owner, meta)
+
+	status, err := c.checkSlotLimits()
 	if err != nil {
 		return func() {}, false, err
 	}
@@ -735,31 +1597,95 @@ func (c *Client) AcquireSlot() (ReleaseFunc, bool, error) {
 		return func() {}, false, fmt.Errorf("no concurrency limit configured")
 	}
 
-	// Acquire from product-level pool
+	c.mu.RLock()
+	queue := c.overflow
+	c.mu.RUnlock()
+
+	if queue == nil {
+		release, current, ok := c.tryAcquireProductSlot(maxConcurrency, holder)
+		if !ok {
+			c.recordEvent(WorkloadEvent{Kind: EventAcquire})
+			return func() {}, false, fmt.Errorf("concurrency exceeded: %d >= %d", current, maxConcurrency)
+		}
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire, SlotID: holder.ID})
+		return release, true, nil
+	}
+	release, ok, err := c.acquireQueued(ctx, queue, maxConcurrency, holder)
+	if ok {
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire, SlotID: holder.ID})
+	} else {
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire})
+	}
+	return release, ok, err
+}
+
+// tryAcquireProductSlot takes a slot from the product-level pool for holder
+// if one is free. It returns the current holder count when the pool is full.
+// Callers queued in AcquireSlotWait are served first.
+func (c *Client) tryAcquireProductSlot(maxConcurrency int, holder *SlotHolder) (ReleaseFunc, int, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := c.instanceID + "::__product__"
+	c.admitSlotWaitersLocked(maxConcurrency)
+
+	key := c.productSlotKey()
 	current := concurrencyState[key]
 
 	if current >= maxConcurrency {
-		return func() {}, false, fmt.Errorf("concurrency exceeded: %d >= %d", current, maxConcurrency)
+		return nil, current, false
 	}
 
 	concurrencyState[key] = current + 1
+	return c.newProductSlotLocked(holder), current + 1, true
+}
 
-	release := func() {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		cur := concurrencyState[key]
-		if cur <= 1 {
-			delete(concurrencyState, key)
-		} else {
-			concurrencyState[key] = cur - 1
-		}
-	}
+// productSlotKey is the concurrencyState key of the product-level pool
+func (c *Client) productSlotKey() string {
+	return c.instanceID + "::" + productFeatureID
+}
 
-	return release, true, nil
+// newProductSlotLocked records holder for a slot already counted in
+// concurrencyState and returns its release function. Releasing hands the
+// slot to the head AcquireSlotWait waiter if there is one; otherwise the
+// slot is freed and overflow queue waiters are woken. A slot moved to a
+// replacement client is released there. Releasing twice has no effect.
+// Caller holds c.mu.
+func (c *Client) newProductSlotLocked(holder *SlotHolder) ReleaseFunc {
+	key := c.productSlotKey()
+	id := c.addSlotHolder(holder)
+	holder.ID = id
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			if forward, ok := c.slotForwards[id]; ok {
+				delete(c.slotForwards, id)
+				c.mu.Unlock()
+				forward()
+				return
+			}
+			c.removeSlotHolder(id)
+			handedOff := c.handOffSlotLocked()
+			if !handedOff {
+				cur := concurrencyState[key]
+				if cur <= 1 {
+					delete(concurrencyState, key)
+				} else {
+					concurrencyState[key] = cur - 1
+				}
+			}
+			queue := c.overflow
+			c.mu.Unlock()
+
+			c.recordEvent(WorkloadEvent{Kind: EventRelease, SlotID: id})
+
+			// Wake callers waiting in the overflow queue
+			if queue != nil && !handedOff {
+				queue.signal()
+			}
+		})
+	}
 }
 
 // AcquireSlotDeprecated implements a simple in-process concurrency control based on
@@ -820,31 +1746,54 @@ func (c *Client) AcquireSlotDeprecated(featureID string, meta map[string]any) (f
 	return release, true, "ok", nil
 }
 
-// ReportUsage reports feature usage to LCC
+// ReportUsage reports feature usage to LCC. With a usage ledger (see
+// EnableUsageLedger) the report is recorded and delivered exactly once.
 func (c *Client) ReportUsage(featureID string, amount float64) error {
+	if err := c.checkOpen("report usage"); err != nil {
+		return err
+	}
+	pool := c.recordPoolUsage(featureID, int(amount))
+	if featureID != productFeatureID {
+		// Product usage is recorded by Consume, whatever path admits it
+		c.compliance.use(featureID, "", amount, false)
+	}
+	if c.OfflineMode() {
+		return nil // Usage is accounted locally against the license
+	}
+	return c.sendUsage(featureID, "", pool, int(amount))
+}
+
+// sendUsage reports usage of a feature, or of one of its quota dimensions,
+// through the usage ledger if enabled
+func (c *Client) sendUsage(featureID, dimension, pool string, amount int) error {
+	if l := c.ledger(); l != nil {
+		return c.reportUsageEvent(l, featureID, dimension, amount)
+	}
+	if c.grpcTransport() != nil {
+		return c.reportUsageGRPC(featureID, dimension, amount, time.Now(), "")
+	}
+
 	reqBody := map[string]interface{}{
 		"instance_id": c.instanceID,
 		"feature_id":  featureID,
-		"count":       int(amount),
+		"count":       amount,
 		"timestamp":   time.Now().Unix(),
 	}
+	if dimension != "" {
+		reqBody["dimension"] = dimension
+	}
+	if pool != "" {
+		reqBody["quota_pool"] = pool
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/api/v1/sdk/usage", bytes.NewReader(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Sign request
-	if err := c.signer.SignRequest(req); err != nil {
-		return fmt.Errorf("failed to sign request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(context.Background(), "ReportUsage", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", c.baseURL+"/api/v1/sdk/usage", bodyBytes)
+	})
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -855,7 +1804,7 @@ func (c *Client) ReportUsage(featureID string, amount float64) error {
 		return fmt.Errorf("usage report failed: status=%d, body=%s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	return c.verifyResponse(resp)
 }
 
 // GetInstanceID returns the instance ID (public key fingerprint)
@@ -863,25 +1812,77 @@ func (c *Client) GetInstanceID() string {
 	return c.instanceID
 }
 
-// Close cleans up the client resources
+// Close cleans up the client resources.
+// Close is idempotent and safe to call concurrently with Register; an
+// in-flight registration is aborted. A registered client is deregistered
+// first (see Deregister), so the server frees its share of the limits.
+//
+// Close then securely wipes the private key, so a closed client cannot be
+// reopened or reactivated. Use Suspend instead to keep the key for Reopen.
 func (c *Client) Close() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Stop heartbeat loop if running
-	if c.heartbeatCancel != nil {
-		c.heartbeatCancel()
-		c.heartbeatCancel = nil
+	if err := c.suspend(); err != nil {
+		return err
 	}
 
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.keyPair != nil {
 		c.keyPair.Destroy()
 		c.keyPair = nil
 	}
+	return nil
+}
+
+// Suspend releases the client's resources like Close but keeps its key
+// pair in memory, so Reopen can resume it under the same instance identity,
+// e.g. around a maintenance window. Call Close when done with the client to
+// wipe the key.
+func (c *Client) Suspend() error {
+	return c.suspend()
+}
+
+// suspend deregisters the client and releases its resources, keeping the
+// key pair
+func (c *Client) suspend() error {
+	c.mu.Lock()
+	if c.registerCancel != nil {
+		c.registerCancel()
+	}
+	c.mu.Unlock()
+
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	// A deactivated client has already released its resources
+	if state := c.Lifecycle(); state == StateClosed || state == StateDeactivated {
+		return nil
+	}
+
+	c.deregisterOnClose()
+	c.shutdown()
+	c.setState(StateClosed)
 
 	return nil
 }
 
+// shutdown stops background work and releases the lease and connections.
+// Caller holds c.lifecycleMu.
+func (c *Client) shutdown() {
+	c.stopBackground()
+	c.stopSubscription()
+	c.releaseLease(context.Background())
+	c.closeIdleConnections()
+	if t := c.grpcTransport(); t != nil {
+		t.close()
+	}
+	if err := c.SaveCache(); err != nil {
+		debugLogf("Close: %v", err)
+	}
+	c.quotaResets.stop()
+}
+
 // Cache methods
 
 func (fc *featureCache) get(featureID string) *FeatureStatus {
@@ -901,14 +1902,68 @@ func (fc *featureCache) get(featureID string) *FeatureStatus {
 	return entry.status
 }
 
+// stale returns the last cached status for featureID, even if expired
+func (fc *featureCache) stale(featureID string) *FeatureStatus {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	if entry, exists := fc.data[featureID]; exists {
+		return entry.status
+	}
+	return nil
+}
+
 func (fc *featureCache) set(featureID string, status *FeatureStatus) {
+	fc.setWithTTL(featureID, status, fc.ttl)
+}
+
+// setWithTTL stores status and returns the previously cached status for
+// featureID, expired or not (nil if none)
+func (fc *featureCache) setWithTTL(featureID string, status *FeatureStatus, ttl time.Duration) *FeatureStatus {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
+	var prev *FeatureStatus
+	if entry, ok := fc.data[featureID]; ok {
+		prev = entry.status
+	}
 	fc.data[featureID] = &cacheEntry{
 		status:    status,
-		expiresAt: time.Now().Add(fc.ttl),
+		expiresAt: time.Now().Add(ttl),
+	}
+	fc.version++
+	return prev
+}
+
+func (fc *featureCache) delete(featureID string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	delete(fc.data, featureID)
+	fc.version++
+}
+
+// expire marks the cached status of featureID as expired, keeping it as the
+// stale fallback and the baseline for status change notifications
+func (fc *featureCache) expire(featureID string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if entry, ok := fc.data[featureID]; ok {
+		entry.expiresAt = time.Time{}
+		fc.version++
+	}
+}
+
+// expireAll expires every cached status
+func (fc *featureCache) expireAll() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for _, entry := range fc.data {
+		entry.expiresAt = time.Time{}
 	}
+	fc.version++
 }
 
 func (fc *featureCache) clear() {
@@ -916,11 +1971,13 @@ func (fc *featureCache) clear() {
 	defer fc.mu.Unlock()
 
 	fc.data = make(map[string]*cacheEntry)
+	fc.version++
 }
 
 // ClearCache clears the feature cache
 func (c *Client) ClearCache() {
 	c.cache.clear()
+	c.clearDedup()
 }
 
 // getLocalIP returns the local non-loopback IP address
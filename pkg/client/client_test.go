@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestNewClient_ZeroValueDefaults(t *testing.T) {
+	cfg := &config.SDKConfig{LCCURL: "http://127.0.0.1:1", ProductID: "test-app", ProductVersion: "1.0.0"}
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.httpClient.Timeout != 5*time.Second {
+		t.Errorf("HTTP timeout = %v, want 5s", c.httpClient.Timeout)
+	}
+	if c.cache.ttl != 10*time.Second {
+		t.Errorf("cache TTL = %v, want 10s", c.cache.ttl)
+	}
+	if c.retrier.policy.MaxRetries != 0 {
+		t.Errorf("MaxRetries = %d, want 0 kept", c.retrier.policy.MaxRetries)
+	}
+	if cfg.Timeout != 0 || cfg.CacheTTL != 0 {
+		t.Errorf("NewClient() modified the caller's config: %+v", cfg)
+	}
+
+	cfg.NoCache = true
+	uncached, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer uncached.Close()
+	if uncached.cache.ttl != 0 {
+		t.Errorf("cache TTL with NoCache = %v, want 0", uncached.cache.ttl)
+	}
+}
+
+func TestNewClient_InvalidConfig(t *testing.T) {
+	var verrs config.ValidationErrors
+	if _, err := NewClient(nil); !errors.As(err, &verrs) {
+		t.Errorf("NewClient(nil) error = %v, want ValidationErrors", err)
+	}
+
+	_, err := NewClient(&config.SDKConfig{LCCURL: "http://127.0.0.1:1", Timeout: -time.Second})
+	if !errors.As(err, &verrs) {
+		t.Fatalf("NewClient() error = %v, want ValidationErrors", err)
+	}
+	fields := map[string]bool{}
+	for _, ve := range verrs {
+		fields[ve.Field] = true
+	}
+	for _, field := range []string{"sdk.product_id", "sdk.product_version", "sdk.timeout"} {
+		if !fields[field] {
+			t.Errorf("errors %v do not report %s", verrs, field)
+		}
+	}
+
+	if _, err := NewClientWithKeyPair(&config.SDKConfig{ProductID: "app", ProductVersion: "1.0.0"}, nil); !errors.As(err, &verrs) {
+		t.Errorf("NewClientWithKeyPair() without lcc_url error = %v, want ValidationErrors", err)
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// SetClock sets the time source of request timestamps, e.g. a clock
+// disciplined by the application; nil restores the system clock. It
+// replaces the clock corrected by SetClockSync. Call it before Register.
+func (c *Client) SetClock(clock auth.Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clock
+	c.clockSync = nil
+	if c.keyPair != nil {
+		c.signer.Store(c.newSignerLocked(c.keyPair))
+	}
+}
+
+// SetClockSync enables or disables correcting the request clock from the
+// Date header of server responses (see SDKConfig.ClockSync). While enabled,
+// requests are timestamped with the server's time as last seen, and a
+// request rejected with 401 after the correction changed is re-signed and
+// sent once more. Disabling it restores the system clock.
+func (c *Client) SetClockSync(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enabled == (c.clockSync != nil) {
+		return
+	}
+	c.clockSync = nil
+	c.clock = nil
+	if enabled {
+		c.clockSync = auth.NewOffsetClock(nil)
+		c.clock = c.clockSync
+	}
+	if c.keyPair != nil {
+		c.signer.Store(c.newSignerLocked(c.keyPair))
+	}
+}
+
+// ClockOffset returns the correction SetClockSync applies to the system
+// clock: the server's time minus the local time. It is 0 while clock sync
+// is disabled.
+func (c *Client) ClockOffset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.clockSync == nil {
+		return 0
+	}
+	return c.clockSync.Offset()
+}
+
+// syncClock corrects clock from resp's Date header and reports whether
+// the offset changed; a nil clock is not synced
+func syncClock(clock *auth.OffsetClock, resp *http.Response) bool {
+	if clock == nil {
+		return false
+	}
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return false
+	}
+	prev := clock.Offset()
+	offset, ok := clock.SyncDate(date)
+	if ok && offset != prev {
+		debugLogf("Clock: offset to server time is now %s", offset)
+	}
+	return ok && offset != prev
+}
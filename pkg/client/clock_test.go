@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// skewedClock runs a fixed offset from the system clock
+type skewedClock time.Duration
+
+func (c skewedClock) Now() time.Time { return time.Now().Add(time.Duration(c)) }
+
+// skewedServer verifies requests against a clock an hour ahead of the
+// client and sends its time in the Date header
+func skewedServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	serverClock := skewedClock(time.Hour)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Date", serverClock.Now().UTC().Format(http.TimeFormat))
+		if err := auth.VerifyRequestWithOptions(r, auth.VerifyOptions{Clock: serverClock}); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "export", "enabled": true})
+	}))
+}
+
+func TestClient_ClockSync(t *testing.T) {
+	var calls atomic.Int32
+	srv := skewedServer(t, &calls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, err := c.CheckFeature("export"); err == nil {
+		t.Fatal("CheckFeature() with a skewed clock should fail")
+	}
+
+	c.SetClockSync(true)
+	c.ClearCache()
+	calls.Store(0)
+	status, err := c.CheckFeature("export")
+	if err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() with clock sync = %+v, %v", status, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server calls = %d, want 2 (rejected, then re-signed)", n)
+	}
+	if offset := c.ClockOffset(); offset < time.Hour-2*time.Second || offset > time.Hour+2*time.Second {
+		t.Errorf("ClockOffset() = %v, want about 1h", offset)
+	}
+
+	// The corrected clock signs valid requests from the start
+	c.ClearCache()
+	calls.Store(0)
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server calls = %d, want 1", n)
+	}
+
+	c.SetClockSync(false)
+	if offset := c.ClockOffset(); offset != 0 {
+		t.Errorf("ClockOffset() after disabling = %v, want 0", offset)
+	}
+}
+
+func TestClient_SetClock(t *testing.T) {
+	var calls atomic.Int32
+	srv := skewedServer(t, &calls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetClock(skewedClock(time.Hour))
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() with the server's clock error = %v", err)
+	}
+}
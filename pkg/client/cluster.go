@@ -0,0 +1,103 @@
+package client
+
+import (
+	"time"
+)
+
+// Limit scopes reported in FeatureStatus.LimitScope
+const (
+	// LimitScopeInstance limits apply to each instance on its own (default)
+	LimitScopeInstance = "instance"
+
+	// LimitScopeCluster limits apply to all instances of a cluster together
+	// (see SDKConfig.ClusterID)
+	LimitScopeCluster = "cluster"
+)
+
+// ClusterInfo is this instance's view of its cluster, assigned by the LCC
+// server in heartbeat responses when SDKConfig.ClusterID is set
+type ClusterInfo struct {
+	// Instances is the number of live instances registered in the cluster
+	Instances int `json:"instances"`
+
+	// Reporter is true when this instance is the one elected to report
+	// cluster-level metrics
+	Reporter bool `json:"reporter"`
+
+	// ExpiresAt is when the assignment lapses (Unix seconds, optional)
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+// clusterState is the most recent cluster info and when it stops being
+// trusted
+type clusterState struct {
+	info       ClusterInfo
+	validUntil time.Time
+}
+
+// ClusterID returns the cluster this instance registered under, or "" when
+// it is not part of a cluster
+func (c *Client) ClusterID() string {
+	return c.clusterID
+}
+
+// Cluster returns the current cluster assignment. It returns false if no
+// ClusterID is set or no unexpired assignment has been received.
+func (c *Client) Cluster() (ClusterInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cluster == nil || !time.Now().Before(c.cluster.validUntil) {
+		return ClusterInfo{}, false
+	}
+	return c.cluster.info, true
+}
+
+// IsClusterReporter reports whether this instance should report
+// cluster-level metrics, such as the capacity peak. Outside a cluster, and
+// while no election result is known, every instance reports.
+func (c *Client) IsClusterReporter() bool {
+	info, ok := c.Cluster()
+	return !ok || info.Reporter
+}
+
+// applyCluster records a cluster assignment from a heartbeat response.
+// Without an explicit expiry it is trusted for three heartbeat intervals.
+func (c *Client) applyCluster(info ClusterInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interval := c.heartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	validUntil := time.Now().Add(3 * interval)
+	if info.ExpiresAt > 0 {
+		validUntil = time.Unix(info.ExpiresAt, 0)
+	}
+
+	if c.cluster == nil || c.cluster.info.Instances != info.Instances || c.cluster.info.Reporter != info.Reporter {
+		debugLogf("Cluster %s: %d instances, reporter=%v", c.clusterID, info.Instances, info.Reporter)
+	}
+	c.cluster = &clusterState{info: info, validUntil: validUntil}
+}
+
+// clusterLimits returns the limits this instance enforces locally. Limits
+// scoped to the cluster are split evenly across its live instances: the TPS
+// limit exactly, the concurrency limit rounded down to at least 1.
+func (c *Client) clusterLimits(status *FeatureStatus) *FeatureStatus {
+	if status.LimitScope != LimitScopeCluster {
+		return status
+	}
+	info, ok := c.Cluster()
+	if !ok || info.Instances <= 1 {
+		return status
+	}
+
+	local := *status
+	local.MaxTPS = status.MaxTPS / float64(info.Instances)
+	if status.MaxConcurrency > 0 {
+		local.MaxConcurrency = max(1, status.MaxConcurrency/info.Instances)
+	}
+	return &local
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_Cluster(t *testing.T) {
+	var mu sync.Mutex
+	var registered, beat map[string]interface{}
+	var reporter atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v1/sdk/register":
+			registered = body
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{})
+		case "/api/v1/sdk/heartbeat":
+			beat = body
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"cluster": map[string]interface{}{"instances": 4, "reporter": reporter.Load()},
+			})
+		case "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{
+				"max_tps": 100, "max_concurrency": 6, "scope": LimitScopeCluster,
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.clusterID = "checkout-eu"
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	mu.Lock()
+	metadata, _ := registered["metadata"].(map[string]interface{})
+	if registered["cluster_id"] != "checkout-eu" || metadata["cluster_id"] != "checkout-eu" {
+		t.Errorf("registration = %v, want cluster_id", registered)
+	}
+	mu.Unlock()
+
+	// Without an assignment every instance reports and enforces the full
+	// cluster limits
+	if !c.IsClusterReporter() {
+		t.Error("IsClusterReporter() before any heartbeat should be true")
+	}
+	if _, max, _ := c.CheckTPS(); max != 100 {
+		t.Errorf("CheckTPS() max before assignment = %v, want 100", max)
+	}
+
+	c.capacityPeaks.observe(10)
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	mu.Lock()
+	if beat["cluster_id"] != "checkout-eu" || beat["capacity_peak"] == nil {
+		t.Errorf("first heartbeat = %v, want cluster_id and capacity_peak", beat)
+	}
+	mu.Unlock()
+
+	info, ok := c.Cluster()
+	if !ok || info.Instances != 4 || info.Reporter || c.IsClusterReporter() {
+		t.Fatalf("Cluster() = %+v, %v; want 4 instances, not the reporter", info, ok)
+	}
+
+	// Another instance is the reporter
+	c.capacityPeaks.observe(12)
+	_ = c.sendHeartbeat()
+	mu.Lock()
+	if _, ok := beat["capacity_peak"]; ok {
+		t.Error("heartbeat of a non-reporter should not carry the capacity peak")
+	}
+	mu.Unlock()
+
+	// Cluster-scoped limits are split across the instances
+	if _, max, _ := c.CheckTPS(); max != 25 {
+		t.Errorf("CheckTPS() max = %v, want 25 (100 across 4)", max)
+	}
+	release, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	defer release()
+	if _, ok, _ := c.AcquireSlot(); ok {
+		t.Error("AcquireSlot() should allow 1 slot (6 across 4)")
+	}
+
+	reporter.Store(true)
+	_ = c.sendHeartbeat()
+	if !c.IsClusterReporter() {
+		t.Error("IsClusterReporter() after election should be true")
+	}
+}
+
+func TestClient_ClusterLimitsInstanceScope(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	c.clusterID = "c1"
+	c.applyCluster(ClusterInfo{Instances: 3})
+
+	status := &FeatureStatus{MaxTPS: 90, MaxConcurrency: 9}
+	if got := c.clusterLimits(status); got != status {
+		t.Errorf("clusterLimits() of instance-scoped limits = %+v, want unchanged", got)
+	}
+	status.LimitScope = LimitScopeCluster
+	if got := c.clusterLimits(status); got.MaxTPS != 30 || got.MaxConcurrency != 3 || status.MaxTPS != 90 {
+		t.Errorf("clusterLimits() = %+v, want 30 TPS and 3 slots without changing the input", got)
+	}
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/compliance"
+)
+
+// complianceRetention is how long usage is kept for compliance reports, a
+// quarter with room to spare
+const complianceRetention = 93 * 24 * time.Hour
+
+// complianceBucket is the usage measured in one hour
+type complianceBucket struct {
+	start    time.Time
+	features map[string]*compliance.FeatureUsage
+	usage    map[usageTotalKey]*compliance.UsageTotal
+	peaks    compliance.Peaks
+}
+
+type usageTotalKey struct {
+	featureID string
+	dimension string
+}
+
+// complianceRecorder aggregates usage in hourly buckets over the retention
+// period, so a report can cover any period the client was running
+type complianceRecorder struct {
+	mu      sync.Mutex
+	since   time.Time           // start of the records: client start or retention
+	buckets []*complianceBucket // oldest first
+}
+
+func newComplianceRecorder() *complianceRecorder {
+	return &complianceRecorder{since: time.Now()}
+}
+
+// bucketLocked returns the bucket of now, dropping those past retention.
+// Caller holds r.mu.
+func (r *complianceRecorder) bucketLocked(now time.Time) *complianceBucket {
+	start := now.Truncate(time.Hour)
+	if n := len(r.buckets); n > 0 && !r.buckets[n-1].start.Before(start) {
+		return r.buckets[n-1]
+	}
+	cutoff := now.Add(-complianceRetention)
+	for len(r.buckets) > 0 && r.buckets[0].start.Add(time.Hour).Before(cutoff) {
+		r.since = r.buckets[0].start.Add(time.Hour)
+		r.buckets = r.buckets[1:]
+	}
+	b := &complianceBucket{
+		start:    start,
+		features: make(map[string]*compliance.FeatureUsage),
+		usage:    make(map[usageTotalKey]*compliance.UsageTotal),
+	}
+	r.buckets = append(r.buckets, b)
+	return b
+}
+
+// check records a feature check
+func (r *complianceRecorder) check(featureID string, status *FeatureStatus, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.bucketLocked(time.Now())
+	u, ok := b.features[featureID]
+	if !ok {
+		u = &compliance.FeatureUsage{FeatureID: featureID}
+		b.features[featureID] = u
+	}
+	u.Checks++
+	switch {
+	case err != nil:
+	case status.Enabled:
+		u.Allowed++
+	default:
+		u.Denied++
+	}
+}
+
+// use records units used of a feature or dimension, or a call denied for
+// lack of quota
+func (r *complianceRecorder) use(featureID, dimension string, units float64, denied bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.bucketLocked(time.Now())
+	key := usageTotalKey{featureID, dimension}
+	u, ok := b.usage[key]
+	if !ok {
+		u = &compliance.UsageTotal{FeatureID: featureID, Dimension: dimension}
+		b.usage[key] = u
+	}
+	if denied {
+		u.Denied++
+		return
+	}
+	u.Units += units
+	u.Calls++
+}
+
+// capacity records capacity in use
+func (r *complianceRecorder) capacity(used int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	b := r.bucketLocked(now)
+	if used > b.peaks.Capacity {
+		b.peaks.Capacity, b.peaks.CapacityAt = used, now
+	}
+}
+
+// concurrency records the number of slots held
+func (r *complianceRecorder) concurrency(held int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	b := r.bucketLocked(now)
+	if held > b.peaks.Concurrency {
+		b.peaks.Concurrency, b.peaks.ConcurrencyAt = held, now
+	}
+}
+
+// aggregate fills in the measured part of report over its period. Hours
+// partly within the period count whole.
+func (r *complianceRecorder) aggregate(report *compliance.Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	period := report.Period
+	report.CoveredFrom = period.Start
+	if r.since.After(period.Start) {
+		report.CoveredFrom = r.since
+	}
+
+	features := make(map[string]*compliance.FeatureUsage)
+	usage := make(map[usageTotalKey]*compliance.UsageTotal)
+	for _, b := range r.buckets {
+		if !b.start.Before(period.End) || !b.start.Add(time.Hour).After(period.Start) {
+			continue
+		}
+		for id, u := range b.features {
+			total, ok := features[id]
+			if !ok {
+				total = &compliance.FeatureUsage{FeatureID: id}
+				features[id] = total
+			}
+			total.Checks += u.Checks
+			total.Allowed += u.Allowed
+			total.Denied += u.Denied
+		}
+		for key, u := range b.usage {
+			total, ok := usage[key]
+			if !ok {
+				total = &compliance.UsageTotal{FeatureID: key.featureID, Dimension: key.dimension}
+				usage[key] = total
+			}
+			total.Units += u.Units
+			total.Calls += u.Calls
+			total.Denied += u.Denied
+		}
+		if b.peaks.Capacity > report.Peaks.Capacity {
+			report.Peaks.Capacity, report.Peaks.CapacityAt = b.peaks.Capacity, b.peaks.CapacityAt
+		}
+		if b.peaks.Concurrency > report.Peaks.Concurrency {
+			report.Peaks.Concurrency, report.Peaks.ConcurrencyAt = b.peaks.Concurrency, b.peaks.ConcurrencyAt
+		}
+	}
+
+	report.Features = make([]compliance.FeatureUsage, 0, len(features))
+	for _, u := range features {
+		report.Features = append(report.Features, *u)
+	}
+	sort.Slice(report.Features, func(i, j int) bool { return report.Features[i].FeatureID < report.Features[j].FeatureID })
+	report.Usage = make([]compliance.UsageTotal, 0, len(usage))
+	for _, u := range usage {
+		report.Usage = append(report.Usage, *u)
+	}
+	sort.Slice(report.Usage, func(i, j int) bool {
+		a, b := report.Usage[i], report.Usage[j]
+		if a.FeatureID != b.FeatureID {
+			return a.FeatureID < b.FeatureID
+		}
+		return a.Dimension < b.Dimension
+	})
+}
+
+// entitlements returns the cached statuses as entitlements, sorted by
+// feature ID
+func (c *Client) entitlements() []compliance.Entitlement {
+	entries, _ := c.cache.snapshot()
+	out := make([]compliance.Entitlement, 0, len(entries))
+	for id, entry := range entries {
+		status := entry.Status
+		if status == nil {
+			continue
+		}
+		ent := compliance.Entitlement{
+			FeatureID:      id,
+			Enabled:        status.Enabled,
+			Reason:         status.Reason,
+			Variant:        status.Variant,
+			MaxTPS:         status.MaxTPS,
+			MaxCapacity:    status.MaxCapacity,
+			MaxConcurrency: status.MaxConcurrency,
+			CheckedUntil:   entry.ExpiresAt,
+		}
+		if q := status.Quota; q != nil {
+			ent.Quotas = append(ent.Quotas, compliance.Quota{Dimension: q.Dimension, Limit: q.Limit, Used: q.Used})
+		}
+		for _, q := range status.Quotas {
+			ent.Quotas = append(ent.Quotas, compliance.Quota{Dimension: q.Dimension, Limit: q.Limit, Used: q.Used})
+		}
+		out = append(out, ent)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FeatureID < out[j].FeatureID })
+	return out
+}
+
+// ComplianceReport produces a compliance report for period, signed with
+// the instance key, for customers to submit during license audits. It
+// combines the entitlements last seen by the client with the usage it
+// measured: feature checks, units consumed and reported, and capacity and
+// concurrency peaks. Usage is kept in hourly buckets for 93 days; the
+// report's CoveredFrom tells where the client's records start.
+//
+// Example:
+//
+//	end := time.Now().Truncate(24 * time.Hour)
+//	report, err := client.ComplianceReport(compliance.Period{Start: end.AddDate(0, -1, 0), End: end})
+//	if err == nil {
+//	    err = report.WriteFile("compliance-report.json")
+//	}
+func (c *Client) ComplianceReport(period compliance.Period) (*compliance.SignedReport, error) {
+	if err := period.Validate(); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	kp := c.keyPair
+	c.mu.RUnlock()
+	if kp == nil {
+		return nil, fmt.Errorf("client identity was destroyed")
+	}
+
+	report := &compliance.Report{
+		Version:        compliance.ReportVersion,
+		ProductID:      c.productID,
+		ProductVersion: c.productVer,
+		InstanceID:     c.instanceID,
+		Environment:    c.environment,
+		ClusterID:      c.clusterID,
+		Region:         c.region,
+		Period:         period,
+		GeneratedAt:    time.Now().UTC(),
+		Entitlements:   c.entitlements(),
+	}
+	c.compliance.aggregate(report)
+	return compliance.Sign(report, kp)
+}
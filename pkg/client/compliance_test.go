@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/compliance"
+)
+
+func TestClient_ComplianceReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_capacity": 100, "max_concurrency": 3},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := c.CheckFeature("reports"); err != nil {
+			t.Fatal(err)
+		}
+		if allowed, _, err := c.Consume(5); !allowed {
+			t.Fatalf("Consume() error = %v", err)
+		}
+	}
+	if err := c.ReportUsage("export", 2.5); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CheckCapacity(42); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		release, ok, err := c.AcquireSlot()
+		if !ok {
+			t.Fatalf("AcquireSlot() error = %v", err)
+		}
+		defer release()
+	}
+
+	signed, err := c.ComplianceReport(compliance.Period{Start: start.Add(-time.Hour), End: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("ComplianceReport() error = %v", err)
+	}
+	pemStr, _ := c.keyPair.GetPublicKeyPEM()
+	key, err := auth.ParsePublicKeyPEM([]byte(pemStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := signed.Verify(key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if report.InstanceID != c.instanceID || report.ProductID != "test-app" {
+		t.Errorf("report identity = %s/%s", report.ProductID, report.InstanceID)
+	}
+	if report.CoveredFrom.Before(start.Add(-time.Second)) {
+		t.Errorf("CoveredFrom = %v, want the client start", report.CoveredFrom)
+	}
+	var reports *compliance.FeatureUsage
+	for i := range report.Features {
+		if report.Features[i].FeatureID == "reports" {
+			reports = &report.Features[i]
+		}
+	}
+	if reports == nil || reports.Checks != 2 || reports.Allowed != 2 {
+		t.Errorf("Features = %+v, want 2 allowed checks of reports", report.Features)
+	}
+	want := []compliance.UsageTotal{
+		{FeatureID: productFeatureID, Units: 10, Calls: 2},
+		{FeatureID: "export", Units: 2.5, Calls: 1},
+	}
+	if len(report.Usage) != len(want) || report.Usage[0] != want[0] || report.Usage[1] != want[1] {
+		t.Errorf("Usage = %+v, want %+v", report.Usage, want)
+	}
+	if report.Peaks.Capacity != 42 || report.Peaks.Concurrency != 2 {
+		t.Errorf("Peaks = %+v, want capacity 42 and concurrency 2", report.Peaks)
+	}
+	var product *compliance.Entitlement
+	for i := range report.Entitlements {
+		if report.Entitlements[i].FeatureID == productFeatureID {
+			product = &report.Entitlements[i]
+		}
+	}
+	if product == nil || product.MaxCapacity != 100 || product.MaxConcurrency != 3 {
+		t.Errorf("Entitlements = %+v, want the product limits", report.Entitlements)
+	}
+
+	// A period before the client started has no usage
+	signed, err = c.ComplianceReport(compliance.Period{Start: start.AddDate(0, 0, -2), End: start.AddDate(0, 0, -1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, _ = signed.Verify(key); len(report.Features) != 0 || len(report.Usage) != 0 || !report.CoveredFrom.After(report.Period.End) {
+		t.Errorf("report before the client started = %+v", report)
+	}
+
+	if _, err := c.ComplianceReport(compliance.Period{Start: start}); err == nil {
+		t.Error("ComplianceReport() with an open period succeeded")
+	}
+}
+
+func TestComplianceRecorder_Retention(t *testing.T) {
+	r := newComplianceRecorder()
+	old := time.Now().Add(-complianceRetention - 2*time.Hour)
+	r.since = old
+	r.bucketLocked(old).usage[usageTotalKey{featureID: "export"}] = &compliance.UsageTotal{FeatureID: "export", Units: 7}
+	r.use("export", "", 1, false)
+
+	report := &compliance.Report{Period: compliance.Period{Start: old.Add(-time.Hour), End: time.Now().Add(time.Hour)}}
+	r.aggregate(report)
+	if len(report.Usage) != 1 || report.Usage[0].Units != 1 {
+		t.Errorf("Usage = %+v, want the bucket past retention dropped", report.Usage)
+	}
+	if !report.CoveredFrom.After(old) {
+		t.Errorf("CoveredFrom = %v, want after the dropped bucket", report.CoveredFrom)
+	}
+}
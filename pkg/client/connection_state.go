@@ -0,0 +1,163 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ConnectionState is the client's view of its connection to the LCC
+// server, derived from consecutive heartbeat failures:
+// Healthy → Degraded → Disconnected, and back to Healthy on the first
+// successful heartbeat.
+type ConnectionState int
+
+const (
+	// ConnectionHealthy: the last heartbeat succeeded (or none has failed)
+	ConnectionHealthy ConnectionState = iota
+	// ConnectionDegraded: heartbeats are failing; cached statuses are still
+	// served
+	ConnectionDegraded
+	// ConnectionDisconnected: the server has been unreachable for several
+	// heartbeats
+	ConnectionDisconnected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionHealthy:
+		return "healthy"
+	case ConnectionDegraded:
+		return "degraded"
+	case ConnectionDisconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(s))
+	}
+}
+
+// Default numbers of consecutive failed heartbeats before the connection
+// is degraded and disconnected
+const (
+	defaultDegradedAfter     = 1
+	defaultDisconnectedAfter = 3
+)
+
+// ConnectionStateChange describes a connection state transition
+type ConnectionStateChange struct {
+	Old ConnectionState
+	New ConnectionState
+
+	// Failures is the number of consecutive failed heartbeats, 0 on
+	// recovery
+	Failures int
+
+	// Err is the last heartbeat error, nil on recovery
+	Err error
+}
+
+// connectionTracker counts consecutive heartbeat failures and notifies
+// handlers of state transitions
+type connectionTracker struct {
+	mu                sync.Mutex
+	state             ConnectionState
+	failures          int
+	degradedAfter     int
+	disconnectedAfter int
+	hooks             *hookDispatcher
+	handlers          []namedHook[func(ConnectionStateChange)]
+
+	// onChange, if set, is called with every change before the handlers
+	// are dispatched
+	onChange func(ConnectionStateChange)
+}
+
+func newConnectionTracker(hooks *hookDispatcher) *connectionTracker {
+	return &connectionTracker{
+		hooks:             hooks,
+		degradedAfter:     defaultDegradedAfter,
+		disconnectedAfter: defaultDisconnectedAfter,
+	}
+}
+
+// record updates the state with a heartbeat result
+func (t *connectionTracker) record(err error) {
+	t.mu.Lock()
+	if err == nil {
+		t.failures = 0
+	} else {
+		t.failures++
+	}
+	state := ConnectionHealthy
+	switch {
+	case t.failures >= t.disconnectedAfter:
+		state = ConnectionDisconnected
+	case t.failures >= t.degradedAfter:
+		state = ConnectionDegraded
+	}
+	if state == t.state {
+		t.mu.Unlock()
+		return
+	}
+	change := ConnectionStateChange{Old: t.state, New: state, Failures: t.failures, Err: err}
+	t.state = state
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	debugLogf("Connection: %s -> %s after %d failed heartbeat(s)", change.Old, change.New, change.Failures)
+	if t.onChange != nil {
+		t.onChange(change)
+	}
+	for _, h := range handlers {
+		fn := h.fn
+		t.hooks.dispatch(h.name, func() { fn(change) })
+	}
+}
+
+// State returns the connection state derived from recent heartbeats. Clients
+// that do not send heartbeats, such as offline clients, stay
+// ConnectionHealthy.
+func (c *Client) State() ConnectionState {
+	c.connection.mu.Lock()
+	defer c.connection.mu.Unlock()
+	return c.connection.state
+}
+
+// SetConnectionThresholds sets how many consecutive failed heartbeats make
+// the connection degraded (default 1) and disconnected (default 3). Values
+// below 1 restore the defaults; disconnectedAfter is raised to
+// degradedAfter if lower. The new thresholds apply from the next heartbeat.
+func (c *Client) SetConnectionThresholds(degradedAfter, disconnectedAfter int) {
+	if degradedAfter < 1 {
+		degradedAfter = defaultDegradedAfter
+	}
+	if disconnectedAfter < 1 {
+		disconnectedAfter = defaultDisconnectedAfter
+	}
+	if disconnectedAfter < degradedAfter {
+		disconnectedAfter = degradedAfter
+	}
+	c.connection.mu.Lock()
+	defer c.connection.mu.Unlock()
+	c.connection.degradedAfter = degradedAfter
+	c.connection.disconnectedAfter = disconnectedAfter
+}
+
+// OnConnectionStateChange registers a callback fired when the connection
+// state changes, e.g. to show a "license server unreachable" warning while
+// disconnected and clear it on recovery.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy).
+func (c *Client) OnConnectionStateChange(fn func(ConnectionStateChange)) {
+	if fn == nil {
+		return
+	}
+	h := namedHook[func(ConnectionStateChange)]{name: c.hooks.name("OnConnectionStateChange"), fn: fn}
+	c.connection.mu.Lock()
+	defer c.connection.mu.Unlock()
+	c.connection.handlers = append(c.connection.handlers, h)
+}
+
+// emitConnectionChanged reports a connection state change as an event
+func (c *Client) emitConnectionChanged(change ConnectionStateChange) {
+	c.emit(Event{Type: EventConnectionChanged, Err: change.Err, Connection: &change})
+}
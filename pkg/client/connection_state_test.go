@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_ConnectionState(t *testing.T) {
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+	c.SetConnectionThresholds(2, 3)
+
+	var (
+		mu      sync.Mutex
+		changes []string
+		events  []*ConnectionStateChange
+	)
+	c.OnConnectionStateChange(func(ch ConnectionStateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, ch.Old.String()+"->"+ch.New.String())
+	})
+	c.OnEvent(func(ev Event) {
+		if ev.Type == EventConnectionChanged {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, ev.Connection)
+		}
+	})
+
+	if got := c.State(); got != ConnectionHealthy {
+		t.Fatalf("State() = %s, want healthy", got)
+	}
+	heartbeat := func() { c.notifyHeartbeat(c.sendHeartbeat()) }
+
+	down.Store(true)
+	heartbeat()
+	if got := c.State(); got != ConnectionHealthy {
+		t.Errorf("State() after 1 failure = %s, want healthy below the threshold", got)
+	}
+	heartbeat()
+	if got := c.State(); got != ConnectionDegraded {
+		t.Errorf("State() after 2 failures = %s, want degraded", got)
+	}
+	heartbeat()
+	heartbeat()
+	if got := c.State(); got != ConnectionDisconnected {
+		t.Errorf("State() after 4 failures = %s, want disconnected", got)
+	}
+	down.Store(false)
+	heartbeat()
+	if got := c.State(); got != ConnectionHealthy {
+		t.Errorf("State() after recovery = %s, want healthy", got)
+	}
+	c.hooks.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"healthy->degraded", "degraded->disconnected", "disconnected->healthy"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("transitions = %v, want %v", changes, want)
+	}
+	if len(events) != 3 || events[1].Failures != 3 || events[1].Err == nil || events[2].Err != nil {
+		t.Fatalf("ConnectionChanged events = %+v", events)
+	}
+}
+
+func TestClient_SetConnectionThresholds(t *testing.T) {
+	c := newTestClient(t, "http://localhost:1")
+	tests := []struct {
+		degraded, disconnected int
+		wantDeg, wantDisc      int
+	}{
+		{0, 0, defaultDegradedAfter, defaultDisconnectedAfter},
+		{2, 5, 2, 5},
+		{5, 0, 5, 5},
+		{4, 2, 4, 4},
+	}
+	for _, tt := range tests {
+		c.SetConnectionThresholds(tt.degraded, tt.disconnected)
+		if got, gotDisc := c.connection.degradedAfter, c.connection.disconnectedAfter; got != tt.wantDeg || gotDisc != tt.wantDisc {
+			t.Errorf("SetConnectionThresholds(%d, %d) = %d, %d; want %d, %d", tt.degraded, tt.disconnected, got, gotDisc, tt.wantDeg, tt.wantDisc)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"reflect"
+)
+
+// Built-in QuotaConsumer constructors for common batch patterns. Each returns
+// a function suitable for HelperFunctions.QuotaConsumer. When the expected
+// argument is missing or of the wrong type they fall back to consuming 1
+// unit, like the default consumer; negative amounts are clamped to 0.
+//
+// Example:
+//
+//	// func ImportRecords(ctx context.Context, records []Record) error
+//	client.RegisterHelpers(&client.HelperFunctions{
+//	    QuotaConsumer:   client.ByLen(0),
+//	    CapacityCounter: func() int { return 0 },
+//	})
+
+// Fixed consumes n units per call
+func Fixed(n int) func(ctx context.Context, args ...interface{}) int {
+	return func(ctx context.Context, args ...interface{}) int {
+		return clampAmount(n)
+	}
+}
+
+// ByArgIndex consumes the integer value of argument i (any integer kind)
+func ByArgIndex(i int) func(ctx context.Context, args ...interface{}) int {
+	return func(ctx context.Context, args ...interface{}) int {
+		if i < 0 || i >= len(args) || args[i] == nil {
+			return 1
+		}
+
+		v := reflect.ValueOf(args[i])
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return clampAmount(int(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return clampAmount(int(v.Uint()))
+		}
+		return 1
+	}
+}
+
+// ByLen consumes the length of argument i (slice, array, map, string or
+// channel), e.g. one unit per record in a batch
+func ByLen(i int) func(ctx context.Context, args ...interface{}) int {
+	return func(ctx context.Context, args ...interface{}) int {
+		if i < 0 || i >= len(args) || args[i] == nil {
+			return 1
+		}
+
+		v := reflect.ValueOf(args[i])
+		if v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+			return v.Len()
+		}
+		return 1
+	}
+}
+
+// ByField applies extract to the first argument of type T, so typed request
+// structs need no manual type assertions
+//
+// Example:
+//
+//	client.ByField(func(req *ExportRequest) int { return req.RowLimit })
+func ByField[T any](extract func(T) int) func(ctx context.Context, args ...interface{}) int {
+	return func(ctx context.Context, args ...interface{}) int {
+		for _, arg := range args {
+			if v, ok := arg.(T); ok {
+				return clampAmount(extract(v))
+			}
+		}
+		return 1
+	}
+}
+
+func clampAmount(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+type exportRequest struct {
+	Rows int
+}
+
+func TestQuotaConsumers(t *testing.T) {
+	ctx := context.Background()
+	records := []string{"a", "b", "c"}
+
+	tests := []struct {
+		name     string
+		consumer func(ctx context.Context, args ...interface{}) int
+		args     []interface{}
+		want     int
+	}{
+		{"fixed", Fixed(5), nil, 5},
+		{"fixed negative", Fixed(-2), nil, 0},
+		{"arg index int", ByArgIndex(1), []interface{}{"x", 42}, 42},
+		{"arg index int64", ByArgIndex(0), []interface{}{int64(7)}, 7},
+		{"arg index uint", ByArgIndex(0), []interface{}{uint16(9)}, 9},
+		{"arg index wrong type", ByArgIndex(0), []interface{}{"42"}, 1},
+		{"arg index missing", ByArgIndex(3), []interface{}{1}, 1},
+		{"len slice", ByLen(0), []interface{}{records}, 3},
+		{"len pointer to slice", ByLen(0), []interface{}{&records}, 3},
+		{"len map", ByLen(1), []interface{}{nil, map[string]int{"a": 1, "b": 2}}, 2},
+		{"len empty batch", ByLen(0), []interface{}{[]int{}}, 0},
+		{"len not a collection", ByLen(0), []interface{}{12}, 1},
+		{"len nil arg", ByLen(0), []interface{}{nil}, 1},
+		{"field pointer", ByField(func(r *exportRequest) int { return r.Rows }), []interface{}{ctx, &exportRequest{Rows: 250}}, 250},
+		{"field value", ByField(func(r exportRequest) int { return r.Rows }), []interface{}{exportRequest{Rows: 8}}, 8},
+		{"field absent", ByField(func(r *exportRequest) int { return r.Rows }), []interface{}{"x"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.consumer(ctx, tt.args...); got != tt.want {
+				t.Errorf("consumer() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
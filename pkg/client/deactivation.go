@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// Deactivation records the retirement of the client's identity by
+// Deactivate
+type Deactivation struct {
+	InstanceID string    `json:"instance_id"`
+	Reason     string    `json:"reason,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Deactivate releases the instance's license seat server-side, e.g. before
+// the product moves to new hardware, and retires the local identity:
+//
+//  1. pending usage is flushed and any quota lease is released
+//  2. the server is asked to free the instance's seat, with reason
+//  3. the client stops like Close and enters StateDeactivated, in which
+//     checks fail with ErrDeactivated
+//
+// With a Store (see NewClientWithStore) the retirement is kept there, so a
+// restarted client with the same identity starts deactivated instead of
+// taking a seat again. Reactivate undoes it.
+//
+// If the server refuses, the client keeps running. Deactivate on a
+// deactivated client does nothing. Offline and gRPC clients cannot
+// deactivate.
+func (c *Client) Deactivate(ctx context.Context, reason string) error {
+	c.mu.Lock()
+	if c.registerCancel != nil {
+		c.registerCancel()
+	}
+	c.mu.Unlock()
+
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	switch state := c.Lifecycle(); state {
+	case StateDeactivated:
+		return nil
+	case StateClosed:
+		return &StateError{Op: "deactivate", State: state}
+	}
+	c.mu.RLock()
+	destroyed, offline, grpc := c.keyPair == nil, c.offlineMode, c.grpc != nil
+	c.mu.RUnlock()
+	switch {
+	case destroyed:
+		return fmt.Errorf("cannot deactivate: client identity was wiped by Close")
+	case offline:
+		return fmt.Errorf("cannot deactivate: offline mode")
+	case grpc:
+		return fmt.Errorf("cannot deactivate: not supported with protocol grpc")
+	}
+
+	// Settle what the instance used before its seat is freed
+	if err := c.flushUsage(ctx); err != nil {
+		debugLogf("WARNING: Deactivate: %v", err)
+	}
+	c.releaseLease(ctx)
+
+	body, err := json.Marshal(map[string]string{"instance_id": c.instanceID, "reason": reason})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := c.postLifecycle(ctx, "Deactivate", "/api/v1/sdk/deactivate", body); err != nil {
+		return fmt.Errorf("deactivation failed: %w", err)
+	}
+
+	c.shutdown()
+	c.cache.clear()
+	record := Deactivation{InstanceID: c.instanceID, Reason: reason, Time: time.Now()}
+	c.mu.Lock()
+	c.deactivation = &record
+	c.mu.Unlock()
+	c.setState(StateDeactivated)
+	debugLogf("Deactivate: instance %s deactivated (%s)", c.instanceID, reason)
+
+	if c.store != nil {
+		data, err := json.Marshal(record)
+		if err == nil {
+			err = c.store.Put(retiredStoreKey, data)
+		}
+		if err != nil {
+			return fmt.Errorf("deactivated but not recorded in the store: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reactivate brings a deactivated client back: the server is asked to
+// restore the instance's seat, the retirement is removed from the Store,
+// and the client registers again with the same identity.
+func (c *Client) Reactivate(ctx context.Context) error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if state := c.Lifecycle(); state != StateDeactivated {
+		return &StateError{Op: "reactivate", State: state}
+	}
+	if err := c.reopenTransport("reactivate"); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"instance_id": c.instanceID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := c.postLifecycle(ctx, "Reactivate", "/api/v1/sdk/reactivate", body); err != nil {
+		return fmt.Errorf("reactivation failed: %w", err)
+	}
+
+	if c.store != nil {
+		if err := c.store.Delete(retiredStoreKey); err != nil {
+			return fmt.Errorf("reactivated but the store still records the deactivation: %w", err)
+		}
+	}
+	c.mu.Lock()
+	c.deactivation = nil
+	c.mu.Unlock()
+	c.quotaResets.clear()
+	c.setState(StateNew)
+
+	debugLogf("Reactivate: re-registering instance %s", c.instanceID)
+	return c.registerLocked()
+}
+
+// Deactivation returns the retirement of the client's identity, if it was
+// deactivated in this process or, with a Store, before a restart
+func (c *Client) Deactivation() (Deactivation, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.deactivation == nil {
+		return Deactivation{}, false
+	}
+	return *c.deactivation, true
+}
+
+// postLifecycle posts a signed Deactivate, Reactivate or Deregister
+// request to path
+func (c *Client) postLifecycle(ctx context.Context, op, path string, body []byte) error {
+	url := c.baseURL + path
+	resp, err := c.doWithRetry(ctx, op, func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", url, body)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status=%d, body=%s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// loadDeactivation restores a retirement of this identity recorded in the
+// Store, leaving the client deactivated
+func (c *Client) loadDeactivation() error {
+	data, err := c.store.Get(retiredStoreKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var record Deactivation
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("invalid deactivation record: %w", err)
+	}
+	if record.InstanceID != c.instanceID {
+		// A new identity is not bound by the old one's retirement
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deactivation = &record
+	c.state = StateDeactivated
+	return nil
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// seatServer tracks which instances hold a seat
+type seatServer struct {
+	mu      sync.Mutex
+	refuse  bool
+	seats   map[string]bool
+	reasons []string
+}
+
+func (s *seatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		InstanceID string `json:"instance_id"`
+		Reason     string `json:"reason"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v1/sdk/deactivate":
+		if s.refuse {
+			http.Error(w, "seat locked", http.StatusConflict)
+			return
+		}
+		delete(s.seats, body.InstanceID)
+		s.reasons = append(s.reasons, body.Reason)
+	case "/api/v1/sdk/reactivate":
+		s.seats[body.InstanceID] = true
+	case "/api/v1/sdk/register":
+		s.seats["registered"] = true
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}
+}
+
+func TestClient_Deactivate(t *testing.T) {
+	ss := &seatServer{seats: make(map[string]bool)}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+	st := store.NewMemoryStore()
+	cfg := &config.SDKConfig{LCCURL: srv.URL, ProductID: "test-app", ProductVersion: "1.0.0", Timeout: 5 * time.Second}
+
+	c, err := NewClientWithStore(cfg, st)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer c.Close()
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	ss.seats[c.GetInstanceID()] = true
+
+	if err := c.Deactivate(context.Background(), "moving to new hardware"); err != nil {
+		t.Fatalf("Deactivate() error = %v", err)
+	}
+	if ss.seats[c.GetInstanceID()] || len(ss.reasons) != 1 || ss.reasons[0] != "moving to new hardware" {
+		t.Errorf("server seats = %v, reasons = %v", ss.seats, ss.reasons)
+	}
+	if got := c.Lifecycle(); got != StateDeactivated {
+		t.Errorf("Lifecycle() = %s, want deactivated", got)
+	}
+	if _, err := c.CheckFeature("reports"); !errors.Is(err, ErrDeactivated) {
+		t.Errorf("CheckFeature() error = %v, want ErrDeactivated", err)
+	}
+	if err := c.Deactivate(context.Background(), "again"); err != nil || len(ss.reasons) != 1 {
+		t.Errorf("second Deactivate() = %v with %d requests, want a no-op", err, len(ss.reasons))
+	}
+
+	// A restart with the same identity stays deactivated
+	restarted, err := NewClientWithStore(cfg, st)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer restarted.Close()
+	if d, ok := restarted.Deactivation(); !ok || d.Reason != "moving to new hardware" {
+		t.Errorf("Deactivation() = %+v, %v", d, ok)
+	}
+	if err := restarted.Register(); !errors.Is(err, ErrDeactivated) {
+		t.Errorf("Register() after restart error = %v, want ErrDeactivated", err)
+	}
+
+	if err := restarted.Reactivate(context.Background()); err != nil {
+		t.Fatalf("Reactivate() error = %v", err)
+	}
+	if got := restarted.Lifecycle(); got != StateRegistered {
+		t.Errorf("Lifecycle() after Reactivate = %s, want registered", got)
+	}
+	if !ss.seats[restarted.GetInstanceID()] {
+		t.Error("server seat not restored")
+	}
+	if _, err := st.Get(retiredStoreKey); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("store still records the deactivation: %v", err)
+	}
+	if _, ok := restarted.Deactivation(); ok {
+		t.Error("Deactivation() still reported after Reactivate")
+	}
+}
+
+func TestClient_DeactivateRefused(t *testing.T) {
+	ss := &seatServer{seats: make(map[string]bool), refuse: true}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := c.Deactivate(context.Background(), "migration"); err == nil {
+		t.Fatal("Deactivate() succeeded though the server refused")
+	}
+	if got := c.Lifecycle(); got != StateRegistered {
+		t.Errorf("Lifecycle() = %s, want registered", got)
+	}
+	if err := c.Reactivate(context.Background()); err == nil {
+		t.Error("Reactivate() of an active client should fail")
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StageDedup is the name of the pipeline stage that suppresses identical
+// checks repeated within a short window
+const StageDedup = "dedup"
+
+// dedupStage answers repeated checks for the same feature from a
+// micro-cache for a few milliseconds, and lets concurrent identical checks
+// share one evaluation. It sits behind the policy stage, so synthesized
+// fail-open decisions are not reused, and in front of the TTL cache.
+type dedupStage struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	recent  map[string]dedupEntry
+	flights flightGroup
+}
+
+type dedupEntry struct {
+	status    *FeatureStatus
+	expiresAt time.Time
+}
+
+func newDedupStage(window time.Duration) *dedupStage {
+	return &dedupStage{
+		window: window,
+		recent: make(map[string]dedupEntry),
+	}
+}
+
+func (s *dedupStage) Name() string { return StageDedup }
+
+func (s *dedupStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	s.mu.Lock()
+	if e, ok := s.recent[req.FeatureID]; ok && time.Now().Before(e.expiresAt) {
+		s.mu.Unlock()
+		return e.status, nil
+	}
+	s.mu.Unlock()
+
+	// Concurrent identical checks share one evaluation; one that panics
+	// fails its waiters with errFlightPanicked
+	return s.flights.do(ctx, req.FeatureID, func() (*FeatureStatus, error) {
+		status, err := next(ctx, req)
+		if err == nil {
+			s.mu.Lock()
+			s.recent[req.FeatureID] = dedupEntry{status: status, expiresAt: time.Now().Add(s.window)}
+			s.mu.Unlock()
+		}
+		return status, err
+	})
+}
+
+// clear drops all recent results
+func (s *dedupStage) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = make(map[string]dedupEntry)
+}
+
+// SetDedupWindow answers identical CheckFeature calls made within window of
+// each other from a micro-cache independent of the TTL cache, and coalesces
+// concurrent ones into a single evaluation. This absorbs generated wrappers
+// that check the same feature several times per request. Keep the window to
+// tens of milliseconds; 0 disables suppression.
+func (c *Client) SetDedupWindow(window time.Duration) {
+	c.pipeline.mu.Lock()
+	defer c.pipeline.mu.Unlock()
+
+	if idx := c.pipeline.indexOf(StageDedup); idx >= 0 {
+		c.pipeline.stages = append(c.pipeline.stages[:idx], c.pipeline.stages[idx+1:]...)
+	}
+	if window <= 0 {
+		return
+	}
+
+	idx := c.pipeline.indexOf(StagePolicy) + 1 // front if policy was removed
+	c.pipeline.insert(idx, newDedupStage(window))
+}
+
+// clearDedup drops micro-cached results so the next check is evaluated
+func (c *Client) clearDedup() {
+	c.pipeline.mu.RLock()
+	defer c.pipeline.mu.RUnlock()
+
+	if idx := c.pipeline.indexOf(StageDedup); idx >= 0 {
+		if s, ok := c.pipeline.stages[idx].(*dedupStage); ok {
+			s.clear()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_DedupWindow(t *testing.T) {
+	var remoteCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteCalls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	// Without the TTL cache every check would reach the server
+	if err := c.RemoveStage(StageCache); err != nil {
+		t.Fatalf("RemoveStage() error = %v", err)
+	}
+	c.SetDedupWindow(time.Hour)
+
+	if got, want := c.Stages(), []string{StageHooks, StagePolicy, StageDedup, StageLimits, StageRemote}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Stages() = %v, want %v", got, want)
+	}
+
+	// Concurrent identical checks share one evaluation
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, err := c.CheckFeature("reports"); err != nil || !status.Enabled {
+				t.Errorf("CheckFeature() = %+v, %v", status, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Repeats within the window are answered from the micro-cache
+	for i := 0; i < 3; i++ {
+		if _, err := c.CheckFeature("reports"); err != nil {
+			t.Fatalf("CheckFeature() error = %v", err)
+		}
+	}
+	if n := remoteCalls.Load(); n != 1 {
+		t.Errorf("remote calls = %d, want 1", n)
+	}
+
+	c.ClearCache()
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if n := remoteCalls.Load(); n != 2 {
+		t.Errorf("remote calls after ClearCache = %d, want 2", n)
+	}
+
+	c.SetDedupWindow(0)
+	if got, want := c.Stages(), []string{StageHooks, StagePolicy, StageLimits, StageRemote}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Stages() after disabling = %v, want %v", got, want)
+	}
+}
+
+func TestDedupStage_WindowExpiry(t *testing.T) {
+	var calls int
+	next := func(_ context.Context, req *CheckRequest) (*FeatureStatus, error) {
+		calls++
+		return &FeatureStatus{Enabled: true}, nil
+	}
+
+	s := newDedupStage(20 * time.Millisecond)
+	req := &CheckRequest{FeatureID: "reports"}
+	for i := 0; i < 2; i++ {
+		if _, err := s.Check(context.Background(), req, next); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := s.Check(context.Background(), req, next); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("evaluations = %d, want 2 (one per window)", calls)
+	}
+}
+
+func TestDedupStage_Panic(t *testing.T) {
+	s := newDedupStage(time.Second)
+	req := &CheckRequest{FeatureID: "reports"}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	panicking := func(_ context.Context, req *CheckRequest) (*FeatureStatus, error) {
+		close(started)
+		<-release
+		panic("evaluation failed")
+	}
+	go func() {
+		defer func() { _ = recover() }()
+		_, _ = s.Check(context.Background(), req, panicking)
+	}()
+	<-started
+
+	waited := make(chan error, 1)
+	go func() {
+		_, err := s.Check(context.Background(), req, panicking)
+		waited <- err
+	}()
+	time.Sleep(10 * time.Millisecond) // let the waiter join the evaluation
+	close(release)
+	select {
+	case err := <-waited:
+		if !errors.Is(err, errFlightPanicked) {
+			t.Errorf("waiter error = %v, want errFlightPanicked", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after the evaluation panicked")
+	}
+
+	// The feature is evaluated again afterwards
+	ok := func(_ context.Context, req *CheckRequest) (*FeatureStatus, error) {
+		return &FeatureStatus{Enabled: true}, nil
+	}
+	if status, err := s.Check(context.Background(), req, ok); err != nil || !status.Enabled {
+		t.Errorf("Check() after a panic = %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReasonCheckFailed is the denial reason when the feature check itself
+// failed (e.g., the LCC server was unreachable)
+const ReasonCheckFailed = "check_failed"
+
+// ReasonStripped is the denial reason reported by stubs that lcc-strip
+// generates for features left out of an edition build
+const ReasonStripped = "stripped"
+
+// ErrFeatureNotLicensed is matched (via errors.Is) by the
+// *FeatureNotLicensedError returned when a feature may not be used, e.g. by
+// EntitlementToken and generated wrappers.
+var ErrFeatureNotLicensed = errors.New("feature not licensed")
+
+// FeatureNotLicensedError reports a feature check that denied access
+type FeatureNotLicensedError struct {
+	FeatureID string
+	Reason    string
+
+	// Status is the denying check result (nil if the check failed)
+	Status *FeatureStatus
+
+	// Err is the check error, if the check failed
+	Err error
+}
+
+// NewFeatureError builds the error for a denied check of featureID from its
+// status, or from the check error when the check failed
+func NewFeatureError(featureID string, status *FeatureStatus, err error) *FeatureNotLicensedError {
+	e := &FeatureNotLicensedError{FeatureID: featureID, Status: status, Err: err}
+	switch {
+	case status != nil:
+		e.Reason = status.Reason
+	case err != nil:
+		e.Reason = ReasonCheckFailed
+	}
+	return e
+}
+
+func (e *FeatureNotLicensedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s (%s: %v)", ErrFeatureNotLicensed, e.FeatureID, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("%s: %s (%s)", ErrFeatureNotLicensed, e.FeatureID, e.Reason)
+}
+
+// Is allows errors.Is(err, ErrFeatureNotLicensed) to match
+func (e *FeatureNotLicensedError) Is(target error) bool {
+	return target == ErrFeatureNotLicensed
+}
+
+// Unwrap returns the check error, if any
+func (e *FeatureNotLicensedError) Unwrap() error {
+	return e.Err
+}
+
+// Product-level limits reported in LimitExceededError.Limit
+const (
+	LimitConcurrency = "concurrency"
+	LimitQuota       = "quota"
+	LimitTPS         = "tps"
+	LimitCapacity    = "capacity"
+)
+
+// ErrLimitExceeded is matched (via errors.Is) by the *LimitExceededError
+// returned when a product-level limit denies a call
+var ErrLimitExceeded = errors.New("license limit exceeded")
+
+// LimitExceededError reports a call denied by a product-level limit. Status
+// carries the product limits and remaining quota so host applications can
+// translate the denial into an API error (e.g., 429 with Retry-After).
+type LimitExceededError struct {
+	Limit  string
+	Status *FeatureStatus
+
+	// Err is the error returned by the limit check, if any
+	Err error
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", ErrLimitExceeded, e.Limit, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", ErrLimitExceeded, e.Limit)
+}
+
+// Is allows errors.Is(err, ErrLimitExceeded) to match
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Unwrap returns the limit check error, if any
+func (e *LimitExceededError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError builds the error for a call denied by limit (LimitQuota,
+// LimitTPS, ...), attaching the current product status when available.
+// err is the error returned by the limit check, if any.
+func (c *Client) LimitError(limit string, err error) *LimitExceededError {
+	e := &LimitExceededError{Limit: limit, Err: err}
+	if status, statusErr := c.checkProductLimits(); statusErr == nil {
+		e.Status = status
+	}
+	return e
+}
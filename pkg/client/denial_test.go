@@ -0,0 +1,44 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewFeatureError(t *testing.T) {
+	denied := NewFeatureError("reports", &FeatureStatus{Enabled: false, Reason: "feature_not_in_license"}, nil)
+	if !errors.Is(denied, ErrFeatureNotLicensed) || denied.Reason != "feature_not_in_license" || denied.Status == nil {
+		t.Errorf("NewFeatureError(status) = %+v", denied)
+	}
+
+	checkErr := errors.New("connection refused")
+	failed := NewFeatureError("reports", nil, checkErr)
+	if failed.Reason != ReasonCheckFailed || !errors.Is(failed, checkErr) || !errors.Is(failed, ErrFeatureNotLicensed) {
+		t.Errorf("NewFeatureError(err) = %+v", failed)
+	}
+}
+
+func TestClient_LimitError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_tps": 10},
+			"quota":   map[string]interface{}{"limit": 100, "used": 100, "remaining": 0, "reset_at": 1700000000},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	var err error = c.LimitError(LimitQuota, nil)
+
+	var limitErr *LimitExceededError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("LimitError() = %v, want *LimitExceededError", err)
+	}
+	if limitErr.Limit != LimitQuota || limitErr.Status == nil || limitErr.Status.Quota.Remaining != 0 || limitErr.Status.MaxTPS != 10 {
+		t.Errorf("LimitError() = %+v", limitErr)
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// deregisterTimeout bounds the deregistration performed by Close
+const deregisterTimeout = 2 * time.Second
+
+// Deregister tells the LCC server this instance is going away, so the
+// capacity, concurrency and TPS share it holds are freed at once instead
+// of when its heartbeats time out:
+//
+//  1. pending usage is flushed and any quota lease is released
+//  2. the server is asked to drop the instance, with the number of
+//     concurrency slots it still holds
+//  3. background work stops and the client returns to StateNew
+//
+// ctx bounds the whole deregistration, including the flush and the lease
+// release. Register adds the instance back. Close deregisters a registered
+// client automatically, waiting at most two seconds. Offline clients have nothing
+// to deregister from.
+func (c *Client) Deregister(ctx context.Context) error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if state := c.Lifecycle(); state != StateRegistered {
+		return &StateError{Op: "deregister", State: state}
+	}
+	if err := c.deregister(ctx); err != nil {
+		return err
+	}
+	c.stopBackground()
+	c.stopSubscription()
+	c.setState(StateNew)
+	return nil
+}
+
+// deregister releases the instance's server-side state. Caller holds
+// c.lifecycleMu.
+func (c *Client) deregister(ctx context.Context) error {
+	c.mu.RLock()
+	offline, grpc, slots := c.offlineMode, c.grpc != nil, len(c.slotHolders)
+	c.mu.RUnlock()
+	if offline {
+		return nil
+	}
+
+	if err := c.flushUsage(ctx); err != nil {
+		debugLogf("WARNING: Deregister: %v", err)
+	}
+	c.releaseLease(ctx)
+
+	if grpc {
+		if err := c.deregisterGRPC(ctx, slots); err != nil {
+			return fmt.Errorf("deregistration failed: %w", err)
+		}
+		debugLogf("Deregister: instance %s deregistered (%d slots held)", c.instanceID, slots)
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"instance_id": c.instanceID, "held_slots": slots})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := c.postLifecycle(ctx, "Deregister", "/api/v1/sdk/deregister", body); err != nil {
+		return fmt.Errorf("deregistration failed: %w", err)
+	}
+	debugLogf("Deregister: instance %s deregistered (%d slots held)", c.instanceID, slots)
+	return nil
+}
+
+// deregisterOnClose deregisters a registered client before Close releases
+// its resources. Failures are logged: the server then forgets the instance
+// when its heartbeats time out. Caller holds c.lifecycleMu.
+func (c *Client) deregisterOnClose() {
+	if c.Lifecycle() != StateRegistered {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deregisterTimeout)
+	defer cancel()
+	if err := c.deregister(ctx); err != nil {
+		debugLogf("WARNING: Close: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// instanceServer records registrations and deregistrations
+type instanceServer struct {
+	mu        sync.Mutex
+	block     chan struct{} // holds deregistrations until closed
+	live      int
+	heldSlots []int
+}
+
+func (s *instanceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		HeldSlots int `json:"held_slots"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v1/sdk/register":
+		s.live++
+	case "/api/v1/sdk/deregister":
+		if s.block != nil {
+			s.mu.Unlock()
+			<-s.block
+			s.mu.Lock()
+		}
+		s.live--
+		s.heldSlots = append(s.heldSlots, body.HeldSlots)
+	case "/api/v1/sdk/product/status":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_concurrency": 5},
+		})
+	}
+}
+
+func TestClient_Deregister(t *testing.T) {
+	is := &instanceServer{}
+	srv := httptest.NewServer(is)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	var stateErr *StateError
+	if err := c.Deregister(context.Background()); !errors.As(err, &stateErr) {
+		t.Errorf("Deregister() before Register error = %v, want a *StateError", err)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	release, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	defer release()
+
+	if err := c.Deregister(context.Background()); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	if got := c.Lifecycle(); got != StateNew {
+		t.Errorf("Lifecycle() = %s, want new", got)
+	}
+
+	// Register adds the instance back; Close deregisters it again
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if is.live != 0 || !reflect.DeepEqual(is.heldSlots, []int{1, 1}) {
+		t.Errorf("server live = %d, held slots = %v", is.live, is.heldSlots)
+	}
+}
+
+func TestClient_CloseDeregisterTimeout(t *testing.T) {
+	is := &instanceServer{block: make(chan struct{})}
+	srv := httptest.NewServer(is)
+	defer srv.Close()
+	defer close(is.block)
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	start := time.Now()
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > deregisterTimeout+time.Second {
+		t.Errorf("Close() took %s with an unresponsive server", elapsed)
+	}
+	if got := c.Lifecycle(); got != StateClosed {
+		t.Errorf("Lifecycle() = %s, want closed", got)
+	}
+}
+
+func TestClient_CloseFlushTimeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/sdk/quota/lease":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"lease_id":   "l1",
+				"granted":    10,
+				"expires_at": time.Now().Add(time.Hour).Unix(),
+			})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/sdk/quota/lease/"):
+			// Reconciling the lease hangs
+			select {
+			case <-block:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	c.SetQuotaLease(10)
+	if allowed, _, err := c.Consume(1); !allowed {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > deregisterTimeout+time.Second {
+		t.Errorf("Close() took %s while flushing to an unresponsive server", elapsed)
+	}
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// DowngradeMode selects how a license downgrade takes effect
+type DowngradeMode string
+
+// Downgrade modes (see SetDowngradePolicy)
+const (
+	// DowngradeImmediate applies a downgraded status as soon as it is seen
+	DowngradeImmediate DowngradeMode = config.DowngradeImmediate
+
+	// DowngradeDrain lets work in flight finish: new product slots follow
+	// the downgraded limits at once, while other checks keep the previous
+	// status until the slots held at the downgrade are released
+	DowngradeDrain DowngradeMode = config.DowngradeDrain
+
+	// DowngradeGrace keeps the previous status for a grace window
+	DowngradeGrace DowngradeMode = config.DowngradeGrace
+)
+
+// DowngradePolicy is the transition policy for license downgrades
+type DowngradePolicy struct {
+	Mode DowngradeMode
+
+	// Grace is the window of DowngradeGrace. With DowngradeDrain it bounds
+	// how long draining may take (0 = until every slot is released).
+	Grace time.Duration
+}
+
+// DowngradePhase is the stage of a downgrade reported in DowngradeEvent
+type DowngradePhase string
+
+// Downgrade phases
+const (
+	DowngradeStarted   DowngradePhase = "started"   // previous status kept for now
+	DowngradeApplied   DowngradePhase = "applied"   // downgraded status in effect
+	DowngradeCancelled DowngradePhase = "cancelled" // license restored before it applied
+)
+
+// DowngradeEvent reports a license downgrade of a feature: it was disabled
+// or one of its limits (capacity, TPS, concurrency, quota) was reduced
+type DowngradeEvent struct {
+	FeatureID string
+	Phase     DowngradePhase
+	Mode      DowngradeMode
+
+	// Old is the status before the downgrade, New the downgraded one
+	Old *FeatureStatus
+	New *FeatureStatus
+
+	// Deadline is when the downgrade applies at the latest; zero when it
+	// waits for slots to drain without a bound
+	Deadline time.Time
+}
+
+// pendingDowngrade is a downgrade whose previous status is still served
+type pendingDowngrade struct {
+	old, new *FeatureStatus
+	mode     DowngradeMode
+	deadline time.Time
+
+	// drainBefore is the last slot ID held when the downgrade was seen
+	drainBefore uint64
+}
+
+// downgradeTracker holds downgrades in transition and their handlers
+type downgradeTracker struct {
+	active atomic.Int32 // len(pending), for a lock-free fast path
+
+	mu       sync.Mutex
+	policy   DowngradePolicy
+	pending  map[string]*pendingDowngrade
+	hooks    *hookDispatcher
+	handlers []namedHook[func(DowngradeEvent)]
+}
+
+func newDowngradeTracker(hooks *hookDispatcher, policy DowngradePolicy) *downgradeTracker {
+	return &downgradeTracker{
+		hooks:   hooks,
+		policy:  policy,
+		pending: make(map[string]*pendingDowngrade),
+	}
+}
+
+// notify dispatches ev to every handler
+func (t *downgradeTracker) notify(ev DowngradeEvent) {
+	t.mu.Lock()
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	debugLogf("Feature %s downgrade %s (%s)", ev.FeatureID, ev.Phase, ev.Mode)
+	for _, h := range handlers {
+		fn := h.fn
+		t.hooks.dispatch(h.name, func() { fn(ev) })
+	}
+}
+
+// isDowngrade reports whether new disables old or reduces one of its
+// limits. A feature disabled because its quota ran out is not downgraded;
+// that is ordinary enforcement.
+func isDowngrade(old, new *FeatureStatus) bool {
+	if old.Enabled && !new.Enabled && new.Reason != ReasonQuotaExhausted {
+		return true
+	}
+	reduced := func(a, b float64) bool { return a > 0 && b > 0 && b < a }
+	return reduced(float64(old.MaxCapacity), float64(new.MaxCapacity)) ||
+		reduced(old.MaxTPS, new.MaxTPS) ||
+		reduced(float64(old.MaxConcurrency), float64(new.MaxConcurrency)) ||
+		reduced(float64(quotaLimit(old)), float64(quotaLimit(new)))
+}
+
+// observeDowngrade looks at a refreshed status. A downgrade from prev
+// starts a transition under the policy; a status that no longer downgrades
+// a transition's previous status cancels it.
+func (c *Client) observeDowngrade(featureID string, prev, status *FeatureStatus) {
+	t := c.downgrades
+	if status == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if p, ok := t.pending[featureID]; ok {
+		if isDowngrade(p.old, status) {
+			p.new = status
+			t.mu.Unlock()
+			return
+		}
+		delete(t.pending, featureID)
+		t.active.Add(-1)
+		t.mu.Unlock()
+		t.notify(DowngradeEvent{FeatureID: featureID, Phase: DowngradeCancelled, Mode: p.mode, Old: p.old, New: status, Deadline: p.deadline})
+		return
+	}
+	if prev == nil || !isDowngrade(prev, status) {
+		t.mu.Unlock()
+		return
+	}
+
+	policy := t.policy
+	p := &pendingDowngrade{old: prev, new: status, mode: policy.Mode}
+	if policy.Grace > 0 {
+		p.deadline = time.Now().Add(policy.Grace)
+	}
+	switch policy.Mode {
+	case DowngradeGrace:
+		if policy.Grace <= 0 {
+			p = nil
+		}
+	case DowngradeDrain:
+		c.mu.RLock()
+		p.drainBefore = c.nextSlotID
+		c.mu.RUnlock()
+		if c.slotsDrained(p.drainBefore) {
+			p = nil
+		}
+	default:
+		p = nil
+	}
+
+	if p == nil {
+		t.mu.Unlock()
+		t.notify(DowngradeEvent{FeatureID: featureID, Phase: DowngradeApplied, Mode: policy.Mode, Old: prev, New: status})
+		return
+	}
+	t.pending[featureID] = p
+	t.active.Add(1)
+	t.mu.Unlock()
+	t.notify(DowngradeEvent{FeatureID: featureID, Phase: DowngradeStarted, Mode: p.mode, Old: prev, New: status, Deadline: p.deadline})
+}
+
+// effectiveStatus returns the status to serve for featureID: the previous
+// status while a downgrade is in transition, status otherwise
+func (c *Client) effectiveStatus(featureID string, status *FeatureStatus) *FeatureStatus {
+	t := c.downgrades
+	if t.active.Load() == 0 {
+		return status
+	}
+
+	t.mu.Lock()
+	p, ok := t.pending[featureID]
+	t.mu.Unlock()
+	if !ok {
+		return status
+	}
+
+	due := !p.deadline.IsZero() && !time.Now().Before(p.deadline)
+	if !due && p.mode == DowngradeDrain {
+		due = c.slotsDrained(p.drainBefore)
+	}
+	if !due {
+		return p.old
+	}
+
+	t.mu.Lock()
+	if t.pending[featureID] != p {
+		t.mu.Unlock()
+		return status // applied or cancelled concurrently
+	}
+	delete(t.pending, featureID)
+	t.active.Add(-1)
+	t.mu.Unlock()
+	t.notify(DowngradeEvent{FeatureID: featureID, Phase: DowngradeApplied, Mode: p.mode, Old: p.old, New: p.new, Deadline: p.deadline})
+	return status
+}
+
+// drainingStatus returns the downgraded status of a feature being drained,
+// or nil. New product slots are granted against it.
+func (c *Client) drainingStatus(featureID string) *FeatureStatus {
+	t := c.downgrades
+	if t.active.Load() == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.pending[featureID]; ok && p.mode == DowngradeDrain {
+		return p.new
+	}
+	return nil
+}
+
+// slotsDrained reports whether every product slot with an ID up to id has
+// been released
+func (c *Client) slotsDrained(id uint64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for held := range c.slotHolders {
+		if held <= id {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSlotLimits returns the product limits that new concurrency slots
+// are granted against: this instance's part of cluster-scoped limits, and
+// the downgraded limits while draining
+func (c *Client) checkSlotLimits() (*FeatureStatus, error) {
+	status, err := c.checkProductLimits()
+	if err != nil {
+		return nil, err
+	}
+	if draining := c.drainingStatus(productFeatureID); draining != nil {
+		status = draining
+	}
+	return c.clusterLimits(status), nil
+}
+
+// SetDowngradePolicy sets how a license downgrade seen on refresh (a
+// feature disabled, or its capacity, TPS, concurrency or quota limit
+// reduced) takes effect, so a mid-cycle downgrade does not cut off work in
+// flight:
+//
+//   - DowngradeImmediate (default) applies it at once.
+//   - DowngradeDrain denies new product slots beyond the reduced limits at
+//     once, but answers every other check with the previous status until
+//     the slots held at the downgrade are released (or Grace elapses).
+//   - DowngradeGrace answers with the previous status for Grace.
+//
+// A refresh that restores the license during the transition cancels it.
+// Only features checked before are compared; a cleared cache resets the
+// baseline. Downgrades already in transition keep their policy.
+//
+// Example:
+//
+//	client.SetDowngradePolicy(client.DowngradePolicy{
+//	    Mode:  client.DowngradeGrace,
+//	    Grace: 10 * time.Minute,
+//	})
+func (c *Client) SetDowngradePolicy(policy DowngradePolicy) {
+	if policy.Mode == "" {
+		policy.Mode = DowngradeImmediate
+	}
+	c.downgrades.mu.Lock()
+	defer c.downgrades.mu.Unlock()
+	c.downgrades.policy = policy
+}
+
+// OnDowngrade registers a callback fired when a license downgrade is seen
+// (DowngradeStarted, or DowngradeApplied under DowngradeImmediate), when it
+// takes effect (DowngradeApplied, with the first check after the grace
+// window or drain) and when a restored license cancels it
+// (DowngradeCancelled).
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy), in order for
+// each callback.
+func (c *Client) OnDowngrade(fn func(DowngradeEvent)) {
+	if fn == nil {
+		return
+	}
+	t := c.downgrades
+	h := namedHook[func(DowngradeEvent)]{name: t.hooks.name("OnDowngrade"), fn: fn}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, h)
+}
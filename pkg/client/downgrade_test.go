@@ -0,0 +1,167 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newDowngradeServer serves response, which the test mutates, for every
+// feature and product status request
+func newDowngradeServer(t *testing.T, response map[string]interface{}) (*httptest.Server, func(map[string]interface{})) {
+	t.Helper()
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(update map[string]interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		for k, v := range update {
+			response[k] = v
+		}
+	}
+}
+
+func recordDowngrades(c *Client) func() []DowngradeEvent {
+	var mu sync.Mutex
+	var events []DowngradeEvent
+	c.OnDowngrade(func(ev DowngradeEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	return func() []DowngradeEvent {
+		c.hooks.wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]DowngradeEvent(nil), events...)
+	}
+}
+
+func checkEnabled(t *testing.T, c *Client, featureID string) bool {
+	t.Helper()
+	c.cache.expire(featureID) // refresh on every check
+	status, err := c.CheckFeature(featureID)
+	if err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	return status.Enabled
+}
+
+func TestClient_DowngradeImmediate(t *testing.T) {
+	srv, update := newDowngradeServer(t, map[string]interface{}{"enabled": true})
+	c := newTestClient(t, srv.URL)
+	events := recordDowngrades(c)
+
+	checkEnabled(t, c, "reports")
+	update(map[string]interface{}{"enabled": false, "reason": ReasonQuotaExhausted})
+	checkEnabled(t, c, "reports")
+	if got := events(); len(got) != 0 {
+		t.Errorf("events after quota exhaustion = %+v, want none", got)
+	}
+
+	update(map[string]interface{}{"enabled": true, "reason": ""})
+	checkEnabled(t, c, "reports")
+	update(map[string]interface{}{"enabled": false, "reason": ReasonLicenseExpired})
+	if checkEnabled(t, c, "reports") {
+		t.Error("CheckFeature() after downgrade should deny at once")
+	}
+	got := events()
+	if len(got) != 1 || got[0].Phase != DowngradeApplied || got[0].Mode != DowngradeImmediate || !got[0].Old.Enabled || got[0].New.Enabled {
+		t.Errorf("events = %+v, want one applied downgrade", got)
+	}
+}
+
+func TestClient_DowngradeGrace(t *testing.T) {
+	srv, update := newDowngradeServer(t, map[string]interface{}{"enabled": true, "max_tps": 100})
+	c := newTestClient(t, srv.URL)
+	c.SetDowngradePolicy(DowngradePolicy{Mode: DowngradeGrace, Grace: 50 * time.Millisecond})
+	events := recordDowngrades(c)
+
+	checkEnabled(t, c, "reports")
+	update(map[string]interface{}{"max_tps": 10})
+	c.cache.expire("reports")
+	if status, _ := c.CheckFeature("reports"); status.MaxTPS != 100 {
+		t.Errorf("MaxTPS during grace = %v, want previous 100", status.MaxTPS)
+	}
+	update(map[string]interface{}{"enabled": false, "reason": ReasonLicenseExpired})
+	if !checkEnabled(t, c, "reports") {
+		t.Error("CheckFeature() during grace should keep the previous status")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if checkEnabled(t, c, "reports") {
+		t.Error("CheckFeature() after grace should deny")
+	}
+	got := events()
+	if len(got) != 2 || got[0].Phase != DowngradeStarted || got[0].Deadline.IsZero() || got[1].Phase != DowngradeApplied || got[1].New.Enabled {
+		t.Fatalf("events = %+v, want started then applied", got)
+	}
+
+	// A restored license cancels a downgrade in transition
+	update(map[string]interface{}{"enabled": true, "reason": "", "max_tps": 100})
+	checkEnabled(t, c, "reports")
+	update(map[string]interface{}{"enabled": false, "reason": ReasonLicenseExpired})
+	checkEnabled(t, c, "reports")
+	update(map[string]interface{}{"enabled": true, "reason": ""})
+	checkEnabled(t, c, "reports")
+	time.Sleep(60 * time.Millisecond)
+	if !checkEnabled(t, c, "reports") {
+		t.Error("CheckFeature() after a cancelled downgrade should allow")
+	}
+	if got := events(); len(got) != 4 || got[2].Phase != DowngradeStarted || got[3].Phase != DowngradeCancelled {
+		t.Errorf("events = %+v, want started then cancelled", got[2:])
+	}
+}
+
+func TestClient_DowngradeDrain(t *testing.T) {
+	srv, update := newDowngradeServer(t, map[string]interface{}{
+		"enabled": true,
+		"limits":  map[string]interface{}{"max_concurrency": 3, "max_tps": 100},
+	})
+	c := newTestClient(t, srv.URL)
+	c.SetDowngradePolicy(DowngradePolicy{Mode: DowngradeDrain})
+	events := recordDowngrades(c)
+
+	r1, _, _ := c.AcquireSlot()
+	r2, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+
+	update(map[string]interface{}{"limits": map[string]interface{}{"max_concurrency": 1, "max_tps": 10}})
+	c.cache.expire(productFeatureID)
+	if status, _ := c.ProductStatus(); status.MaxTPS != 100 {
+		t.Errorf("MaxTPS while draining = %v, want previous 100", status.MaxTPS)
+	}
+	if _, ok, _ := c.AcquireSlot(); ok {
+		t.Error("AcquireSlot() while draining should follow the reduced limit")
+	}
+
+	r1()
+	if status, _ := c.ProductStatus(); status.MaxTPS != 100 {
+		t.Errorf("MaxTPS with a slot still held = %v, want previous 100", status.MaxTPS)
+	}
+	r2()
+	if status, _ := c.ProductStatus(); status.MaxTPS != 10 {
+		t.Errorf("MaxTPS after drain = %v, want 10", status.MaxTPS)
+	}
+	got := events()
+	if len(got) != 2 || got[0].Phase != DowngradeStarted || got[0].Mode != DowngradeDrain || got[1].Phase != DowngradeApplied {
+		t.Errorf("events = %+v, want started then applied", got)
+	}
+
+	// With no slots held, a drain applies at once
+	update(map[string]interface{}{"limits": map[string]interface{}{"max_concurrency": 1, "max_tps": 5}})
+	c.cache.expire(productFeatureID)
+	if status, _ := c.ProductStatus(); status.MaxTPS != 5 {
+		t.Errorf("MaxTPS after downgrade without slots = %v, want 5", status.MaxTPS)
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// ErrEgressDisabled is returned (wrapped) by LCC server calls while
+// network egress is disabled (see SetEgressDisabled)
+var ErrEgressDisabled = errors.New("network egress disabled")
+
+// SetEgressDisabled forbids or allows network calls, like
+// SDKConfig.DisableEgress. While disabled, calls to the LCC server fail at
+// once with ErrEgressDisabled without connecting or retrying, so checks
+// are decided locally: from the license in offline mode, otherwise from
+// the cache, including expired statuses, or the fail-open policy. The
+// subscription stream is closed, and endpoint health checks and discovery
+// pause. Allowing egress again reopens the stream of a registered client.
+func (c *Client) SetEgressDisabled(disabled bool) {
+	if c.egressDisabled.Swap(disabled) == disabled {
+		return
+	}
+	if disabled {
+		debugLogf("Egress: network calls disabled")
+		c.stopSubscription()
+		c.closeIdleConnections()
+		return
+	}
+	debugLogf("Egress: network calls enabled")
+	if c.Lifecycle() == StateRegistered {
+		c.startSubscription()
+	}
+}
+
+// EgressDisabled reports whether network calls are forbidden
+func (c *Client) EgressDisabled() bool {
+	return c.egressDisabled.Load()
+}
+
+// checkEgress fails op if network calls are forbidden
+func (c *Client) checkEgress(op string) error {
+	if c.egressDisabled.Load() {
+		return fmt.Errorf("%s: %w", op, ErrEgressDisabled)
+	}
+	return nil
+}
+
+// SetProxy routes LCC server calls through a proxy, like SDKConfig.Proxy.
+// nil restores the proxy named by HTTPS_PROXY, HTTP_PROXY and NO_PROXY; a
+// ProxyConfig with no URL connects directly. New connections use the
+// proxy, and idle ones are closed. The transport of the configured HTTP
+// client is cloned, so it must be an *http.Transport (or nil, for the
+// default transport). gRPC connections are configured with SetGRPCConn.
+func (c *Client) SetProxy(p *config.ProxyConfig) error {
+	var err error
+	old := c.swapHTTPClient(func(old *http.Client) *http.Client {
+		var transport *http.Transport
+		transport, err = proxyTransport(old.Transport, p)
+		if err != nil {
+			return old
+		}
+		updated := *old
+		updated.Transport = transport
+		return &updated
+	})
+	if err == nil {
+		closeIdleTransport(old)
+	}
+	return err
+}
+
+// proxyTransport returns a clone of base (see cloneTransport) using the
+// proxy p
+func proxyTransport(base http.RoundTripper, p *config.ProxyConfig) (*http.Transport, error) {
+	proxy, err := proxyFunc(p)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := cloneTransport(base, "proxy")
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+	return transport, nil
+}
+
+// proxyFunc returns the http.Transport.Proxy function of p: the
+// environment's proxy for nil, no proxy for an empty URL
+func proxyFunc(p *config.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if p == nil {
+		return http.ProxyFromEnvironment, nil
+	}
+	if p.URL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: must be an http, https or socks5 URL", p.URL)
+	}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  u.String(),
+		HTTPSProxy: u.String(),
+		NoProxy:    strings.Join(p.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_Proxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := (&http.Request{Header: http.Header{"Authorization": r.Header["Proxy-Authorization"]}}).BasicAuth()
+		if !ok || user != "svc" || pass != "secret" {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			http.Error(w, "proxy auth required", http.StatusProxyAuthRequired)
+			return
+		}
+		if r.URL.Host != "lcc.example.test" {
+			http.Error(w, "unexpected target "+r.URL.Host, http.StatusBadGateway)
+			return
+		}
+		proxied.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer proxy.Close()
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         "http://lcc.example.test",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		Proxy:          &config.ProxyConfig{URL: proxy.URL, Username: "svc", Password: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() through the proxy = %+v, %v", status, err)
+	}
+	if n := proxied.Load(); n != 1 {
+		t.Errorf("proxied calls = %d, want 1", n)
+	}
+
+	// Wrong credentials are refused by the proxy
+	if err := c.SetProxy(&config.ProxyConfig{URL: proxy.URL, Username: "svc", Password: "wrong"}); err != nil {
+		t.Fatal(err)
+	}
+	c.ClearCache()
+	if _, err := c.CheckFeature("export"); err == nil {
+		t.Error("CheckFeature() with wrong proxy credentials succeeded")
+	}
+
+	if err := c.SetProxy(&config.ProxyConfig{URL: "ftp://proxy.example.com"}); err == nil {
+		t.Error("SetProxy() accepted an ftp proxy")
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxy, err := proxyFunc(&config.ProxyConfig{
+		URL:     "http://proxy.example.com:3128",
+		NoProxy: []string{"internal.example.com", ".corp", "10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for target, want := range map[string]bool{
+		"https://lcc.example.com/api":          true,
+		"https://internal.example.com/api":     false,
+		"https://lcc.internal.example.com/api": false,
+		"https://lcc.corp/api":                 false,
+		"https://10.1.2.3/api":                 false,
+	} {
+		u, _ := url.Parse(target)
+		got, err := proxy(&http.Request{URL: u})
+		if err != nil {
+			t.Fatalf("proxy(%s) error = %v", target, err)
+		}
+		if (got != nil) != want {
+			t.Errorf("proxy(%s) = %v, want proxied %v", target, got, want)
+		}
+	}
+
+	if direct, err := proxyFunc(&config.ProxyConfig{}); err != nil || direct != nil {
+		t.Errorf("proxyFunc() without URL error = %v, want direct connections", err)
+	}
+}
+
+func TestClient_EgressDisabled(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetEgressDisabled(true)
+	if !c.EgressDisabled() {
+		t.Fatal("EgressDisabled() = false")
+	}
+	calls.Store(0)
+
+	// An expired status is still served; an unknown feature fails at once
+	c.cache.expire("export")
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeature() of a cached feature = %+v, %v", status, err)
+	}
+	if _, err := c.CheckFeature("reports"); !errors.Is(err, ErrEgressDisabled) {
+		t.Errorf("CheckFeature() error = %v, want ErrEgressDisabled", err)
+	}
+	if err := c.ReportUsage("export", 1); !errors.Is(err, ErrEgressDisabled) {
+		t.Errorf("ReportUsage() error = %v, want ErrEgressDisabled", err)
+	}
+	if err := c.Register(); !errors.Is(err, ErrEgressDisabled) {
+		t.Errorf("Register() error = %v, want ErrEgressDisabled", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("server calls while egress is disabled = %d, want 0", n)
+	}
+
+	c.SetEgressDisabled(false)
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Errorf("CheckFeature() after enabling egress error = %v", err)
+	}
+}
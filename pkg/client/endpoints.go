@@ -0,0 +1,419 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/discovery"
+)
+
+// EndpointSelection picks the LCC server for each call when several are
+// configured (SDKConfig.LCCURLs) or discovered (SDKConfig.Discovery)
+type EndpointSelection string
+
+const (
+	// EndpointFailover sends every call to the first healthy server in
+	// configuration order, lcc_url first
+	EndpointFailover EndpointSelection = config.EndpointFailover
+	// EndpointRoundRobin rotates calls over the healthy servers
+	EndpointRoundRobin EndpointSelection = config.EndpointRoundRobin
+	// EndpointWeighted spreads calls over the healthy servers in proportion
+	// to their weights
+	EndpointWeighted EndpointSelection = config.EndpointWeighted
+)
+
+// defaultHealthCheckInterval is how often the servers are probed, and
+// defaultDiscoveryRefresh how often discovered servers are looked up again
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultDiscoveryRefresh    = 30 * time.Second
+)
+
+// healthCheckPath is probed on each server. Any response below 500 counts
+// as healthy: the probe checks the server answers, not what it answers.
+const healthCheckPath = "/health"
+
+// EndpointStatus is the health of one LCC server, see Client.Endpoints
+type EndpointStatus struct {
+	URL     string
+	Weight  int
+	Healthy bool
+
+	// Failures is the number of consecutive failed calls and health
+	// checks, 0 while healthy
+	Failures int
+
+	// LastErr is the last failure, nil while healthy
+	LastErr error
+}
+
+type endpoint struct {
+	url      *url.URL
+	raw      string
+	weight   int
+	current  int // smooth weighted round robin state
+	healthy  bool
+	failures int
+	lastErr  error
+}
+
+// endpointPool spreads LCC calls over several servers serving the same
+// licenses. Requests are built against the base URL (lcc_url, or the
+// discovered service name) and redirected by endpointTransport, so signing,
+// retries and the breaker are unaware of the pool: the servers must share
+// the base URL's path, which is signed.
+type endpointPool struct {
+	base      *url.URL
+	selection EndpointSelection
+	interval  time.Duration
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int // round robin position
+
+	// With discovery, the endpoints are replaced by the resolver's results
+	// every refresh
+	resolver    discovery.Resolver
+	refresh     time.Duration
+	refreshMu   sync.Mutex // serializes lookups
+	refreshedAt time.Time
+}
+
+// newEndpointPool builds a pool routing requests addressed to base. weights
+// may be shorter than urls; missing weights are 1.
+func newEndpointPool(base string, urls []string, weights []int, selection EndpointSelection, interval time.Duration) (*endpointPool, error) {
+	if selection == "" {
+		selection = EndpointFailover
+	}
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid LCC endpoint %q", base)
+	}
+	p := &endpointPool{base: baseURL, selection: selection, interval: interval}
+	found := make([]discovery.Endpoint, len(urls))
+	for i, raw := range urls {
+		found[i] = discovery.Endpoint{URL: raw, Weight: 1}
+		if i < len(weights) && weights[i] > 0 {
+			found[i].Weight = weights[i]
+		}
+	}
+	if err := p.setEndpoints(found); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// setEndpoints replaces the endpoints, keeping the health of those still
+// present
+func (p *endpointPool) setEndpoints(found []discovery.Endpoint) error {
+	endpoints := make([]*endpoint, 0, len(found))
+	for _, f := range found {
+		u, err := url.Parse(f.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid LCC endpoint %q", f.URL)
+		}
+		endpoints = append(endpoints, &endpoint{url: u, raw: f.URL, weight: max(f.Weight, 1), healthy: true})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range endpoints {
+		for _, old := range p.endpoints {
+			if old.raw == ep.raw {
+				ep.healthy, ep.failures, ep.lastErr, ep.current = old.healthy, old.failures, old.lastErr, old.current
+			}
+		}
+	}
+	p.endpoints = endpoints
+	return nil
+}
+
+// list returns the current endpoints
+func (p *endpointPool) list() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*endpoint(nil), p.endpoints...)
+}
+
+// discover looks the endpoints up again with the resolver. A failed or
+// empty lookup keeps the previous endpoints, so a DNS or API server outage
+// does not cut the client off from servers that still answer.
+func (p *endpointPool) discover(ctx context.Context) error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	found, err := p.resolver.Resolve(ctx)
+	p.refreshedAt = time.Now()
+	if err != nil {
+		debugLogf("WARNING: LCC discovery failed, keeping %d known server(s): %v", len(p.list()), err)
+		return err
+	}
+	if len(found) == 0 {
+		debugLogf("WARNING: LCC discovery found no server for %s, keeping %d known server(s)", p.resolver.Name(), len(p.list()))
+		return nil
+	}
+	if err := p.setEndpoints(found); err != nil {
+		return err
+	}
+	debugLogf("LCC discovery: %d server(s) for %s", len(found), p.resolver.Name())
+	return nil
+}
+
+// discoverIfStale looks the endpoints up within a call when none are known
+// yet, or when the refresh job is not keeping them current (the client is
+// not registered). Lookups run at most once per refresh interval, or twice
+// while servers are known, so a failing resolver is not hit by every call.
+func (p *endpointPool) discoverIfStale(ctx context.Context) {
+	if p.resolver == nil {
+		return
+	}
+	maxAge := p.refresh
+	if len(p.list()) > 0 {
+		maxAge *= 2
+	}
+	p.refreshMu.Lock()
+	fresh := time.Since(p.refreshedAt) < maxAge
+	p.refreshMu.Unlock()
+	if !fresh {
+		_ = p.discover(ctx)
+	}
+}
+
+// order returns the endpoints to try for a call: the selected one first,
+// then the other healthy ones in configuration order. With no healthy
+// endpoint left, all are tried in configuration order.
+func (p *endpointPool) order() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var healthy []*endpoint
+	for _, ep := range p.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		return append([]*endpoint(nil), p.endpoints...)
+	}
+
+	first := 0
+	switch p.selection {
+	case EndpointRoundRobin:
+		first = p.next % len(healthy)
+		p.next++
+	case EndpointWeighted:
+		// Smooth weighted round robin: deterministic and evenly interleaved
+		total := 0
+		for i, ep := range healthy {
+			ep.current += ep.weight
+			total += ep.weight
+			if ep.current > healthy[first].current {
+				first = i
+			}
+		}
+		healthy[first].current -= total
+	}
+	out := make([]*endpoint, 0, len(healthy))
+	out = append(out, healthy[first])
+	for i, ep := range healthy {
+		if i != first {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+// record updates the health of ep with the result of a call or probe
+func (p *endpointPool) record(ep *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		if !ep.healthy {
+			debugLogf("LCC endpoint %s is healthy again", ep.raw)
+		}
+		ep.healthy, ep.failures, ep.lastErr = true, 0, nil
+		return
+	}
+	if ep.healthy {
+		debugLogf("WARNING: LCC endpoint %s marked unhealthy: %v", ep.raw, err)
+	}
+	ep.healthy = false
+	ep.failures++
+	ep.lastErr = err
+}
+
+// routes reports whether u is addressed to the base URL, which the client
+// builds its requests against
+func (p *endpointPool) routes(u *url.URL) bool {
+	return u.Scheme == p.base.Scheme && u.Host == p.base.Host
+}
+
+func (p *endpointPool) statuses() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]EndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		out[i] = EndpointStatus{URL: ep.raw, Weight: ep.weight, Healthy: ep.healthy, Failures: ep.failures, LastErr: ep.lastErr}
+	}
+	return out
+}
+
+// endpointPinKey marks a request context whose request must go to the
+// endpoint it is addressed to, e.g. a health check
+type endpointPinKey struct{}
+
+// endpointTransport sends requests addressed to the primary endpoint to the
+// endpoint the pool selects, failing over to the next one on a transport
+// error or a 5xx response. It sits below the middleware chain, so
+// middleware sees the server actually called.
+type endpointTransport struct {
+	pool *endpointPool
+	next http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(endpointPinKey{}) != nil || !t.pool.routes(req.URL) {
+		return t.next.RoundTrip(req)
+	}
+
+	t.pool.discoverIfStale(req.Context())
+	candidates := t.pool.order()
+	for i, ep := range candidates {
+		out := req.Clone(req.Context())
+		out.URL.Scheme, out.URL.Host = ep.url.Scheme, ep.url.Host
+		out.Host = ep.url.Host
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			out.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(out)
+		failure := err
+		if err == nil && resp.StatusCode >= 500 {
+			failure = fmt.Errorf("status %d", resp.StatusCode)
+		}
+		t.pool.record(ep, failure)
+
+		// Fail over unless this was the last endpoint, the body cannot be
+		// sent again, or the caller gave up
+		last := i == len(candidates)-1 || (req.Body != nil && req.GetBody == nil) || req.Context().Err() != nil
+		if failure == nil || last {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		debugLogf("WARNING: %s %s failed on %s (%v), trying %s", req.Method, req.URL.Path, ep.raw, failure, candidates[i+1].raw)
+	}
+	return nil, fmt.Errorf("no LCC server found for %s", t.pool.base.Host)
+}
+
+// CloseIdleConnections passes through to the wrapped transport
+func (t *endpointTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// Endpoints returns the health of each configured or discovered LCC
+// server, preferred first, or nil with a single lcc_url
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return nil
+	}
+	return c.endpoints.statuses()
+}
+
+// newDiscoveryPool builds a pool whose endpoints are looked up with the
+// resolver selected by d. Nothing is looked up until the first call or
+// refresh.
+func newDiscoveryPool(d *config.DiscoveryConfig, selection EndpointSelection, interval time.Duration) (*endpointPool, error) {
+	var resolver discovery.Resolver
+	if d.SRV != "" {
+		resolver = discovery.NewSRV(d.SRV, d.Scheme, d.Path)
+	} else {
+		name, namespace, _ := strings.Cut(d.KubernetesService, ".")
+		k, err := discovery.InCluster(name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes discovery of %s: %w", d.KubernetesService, err)
+		}
+		k.Port, k.Scheme, k.Path = d.Port, d.Scheme, d.Path
+		resolver = k
+	}
+
+	scheme := d.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	base := url.URL{Scheme: scheme, Host: resolver.Name(), Path: d.Path}
+	p, err := newEndpointPool(base.String(), nil, nil, selection, interval)
+	if err != nil {
+		return nil, err
+	}
+	p.resolver = resolver
+	p.refresh = d.Refresh
+	if p.refresh <= 0 {
+		p.refresh = defaultDiscoveryRefresh
+	}
+	return p, nil
+}
+
+// discoverEndpoints is the discovery refresh job
+func (c *Client) discoverEndpoints(ctx context.Context) {
+	if c.EgressDisabled() {
+		return
+	}
+	_ = c.endpoints.discover(ctx)
+}
+
+// checkEndpoints is the health check job: it probes every server, so a
+// failed server rejoins once it answers again and a failed standby is
+// noticed before it is needed
+func (c *Client) checkEndpoints(ctx context.Context) {
+	if c.EgressDisabled() {
+		return
+	}
+	c.mu.RLock()
+	httpClient := c.httpClient
+	c.mu.RUnlock()
+
+	for _, ep := range c.endpoints.list() {
+		if ctx.Err() != nil {
+			return
+		}
+		c.endpoints.record(ep, probeEndpoint(ctx, httpClient, ep))
+	}
+}
+
+// probeEndpoint sends a health check to ep
+func probeEndpoint(ctx context.Context, httpClient *http.Client, ep *endpoint) error {
+	ctx = context.WithValue(ctx, endpointPinKey{}, ep)
+	req, err := http.NewRequestWithContext(ctx, "GET", ep.url.JoinPath(healthCheckPath).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("health check status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/discovery"
+)
+
+// endpointServer is an LCC server counting feature checks that fails with
+// 503 while down is set
+type endpointServer struct {
+	*httptest.Server
+	checks atomic.Int32
+	down   atomic.Bool
+}
+
+func newEndpointServer(t *testing.T) *endpointServer {
+	t.Helper()
+	s := &endpointServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/api/v1/sdk/features/reports/check" {
+			s.checks.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newEndpointClient(t *testing.T, selection string, weights []int, servers ...*endpointServer) *Client {
+	t.Helper()
+	cfg := &config.SDKConfig{
+		LCCURL:            servers[0].URL,
+		ProductID:         "test-app",
+		ProductVersion:    "1.0.0",
+		Timeout:           5 * time.Second,
+		NoCache:           true,
+		EndpointSelection: selection,
+		EndpointWeights:   weights,
+	}
+	for _, s := range servers[1:] {
+		cfg.LCCURLs = append(cfg.LCCURLs, s.URL)
+	}
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func checkReports(t *testing.T, c *Client, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		status, err := c.CheckFeature("reports")
+		if err != nil || !status.Enabled {
+			t.Fatalf("CheckFeature() = %+v, %v", status, err)
+		}
+	}
+}
+
+func TestClient_EndpointFailover(t *testing.T) {
+	primary, standby := newEndpointServer(t), newEndpointServer(t)
+	c := newEndpointClient(t, "", nil, primary, standby)
+
+	checkReports(t, c, 2)
+	if primary.checks.Load() != 2 || standby.checks.Load() != 0 {
+		t.Fatalf("checks = %d/%d, want all on the primary", primary.checks.Load(), standby.checks.Load())
+	}
+
+	primary.down.Store(true)
+	checkReports(t, c, 2)
+	if standby.checks.Load() != 2 {
+		t.Fatalf("standby checks = %d, want 2 after failover", standby.checks.Load())
+	}
+	if st := c.Endpoints(); st[0].Healthy || st[0].Failures != 1 || !st[1].Healthy {
+		t.Fatalf("Endpoints() = %+v, want the primary unhealthy after one failure", st)
+	}
+
+	// The primary rejoins once a health check succeeds
+	primary.down.Store(false)
+	c.checkEndpoints(context.Background())
+	checkReports(t, c, 1)
+	if primary.checks.Load() != 3 {
+		t.Errorf("primary checks = %d, want 3 after recovery", primary.checks.Load())
+	}
+	if st := c.Endpoints(); !st[0].Healthy || st[0].LastErr != nil {
+		t.Errorf("Endpoints() = %+v, want the primary healthy", st)
+	}
+}
+
+func TestClient_EndpointsAllDown(t *testing.T) {
+	primary, standby := newEndpointServer(t), newEndpointServer(t)
+	primary.down.Store(true)
+	standby.down.Store(true)
+	c := newEndpointClient(t, "", nil, primary, standby)
+
+	if _, err := c.CheckFeature("reports"); err == nil {
+		t.Fatal("CheckFeature() succeeded with every server down")
+	}
+	// With no healthy server left every one is tried again
+	standby.down.Store(false)
+	checkReports(t, c, 1)
+}
+
+func TestClient_EndpointSelection(t *testing.T) {
+	for _, tt := range []struct {
+		selection string
+		weights   []int
+		want      [2]int32
+	}{
+		{config.EndpointRoundRobin, nil, [2]int32{4, 4}},
+		{config.EndpointWeighted, []int{3, 1}, [2]int32{6, 2}},
+	} {
+		a, b := newEndpointServer(t), newEndpointServer(t)
+		c := newEndpointClient(t, tt.selection, tt.weights, a, b)
+		checkReports(t, c, 8)
+		if got := [2]int32{a.checks.Load(), b.checks.Load()}; got != tt.want {
+			t.Errorf("%s: checks = %v, want %v", tt.selection, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointPool_WeightedOrder(t *testing.T) {
+	p, err := newEndpointPool("http://a", []string{"http://a", "http://b", "http://c"}, []int{5, 1, 1}, EndpointWeighted, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for i := 0; i < 7; i++ {
+		got += p.order()[0].url.Host
+	}
+	// Smooth weighted round robin interleaves the heavy endpoint
+	if want := "aabacaa"; got != want {
+		t.Errorf("selection = %s, want %s", got, want)
+	}
+}
+
+// staticResolver is a discovery.Resolver returning a fixed result
+type staticResolver struct {
+	mu        sync.Mutex
+	endpoints []discovery.Endpoint
+	err       error
+	lookups   int
+}
+
+func (r *staticResolver) Resolve(context.Context) ([]discovery.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	return r.endpoints, r.err
+}
+
+func (r *staticResolver) Name() string { return "_lcc._tcp.example.com" }
+
+func (r *staticResolver) set(endpoints []discovery.Endpoint, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints, r.err = endpoints, err
+}
+
+func TestClient_EndpointDiscovery(t *testing.T) {
+	a, b := newEndpointServer(t), newEndpointServer(t)
+	c, err := NewClient(&config.SDKConfig{
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		NoCache:        true,
+		Discovery:      &config.DiscoveryConfig{SRV: "_lcc._tcp.example.com", Refresh: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	resolver := &staticResolver{endpoints: []discovery.Endpoint{{URL: a.URL, Weight: 1}, {URL: b.URL, Weight: 1}}}
+	c.endpoints.resolver = resolver
+
+	// The first call looks the servers up
+	checkReports(t, c, 2)
+	if a.checks.Load() != 2 || resolver.lookups != 1 {
+		t.Fatalf("checks on a = %d after %d lookup(s), want 2 after 1", a.checks.Load(), resolver.lookups)
+	}
+
+	// A failed or empty refresh keeps the known servers
+	resolver.set(nil, errors.New("SERVFAIL"))
+	_ = c.endpoints.discover(context.Background())
+	resolver.set(nil, nil)
+	_ = c.endpoints.discover(context.Background())
+	if n := len(c.Endpoints()); n != 2 {
+		t.Fatalf("%d endpoints after failed refreshes, want 2", n)
+	}
+
+	// A refresh replaces them
+	resolver.set([]discovery.Endpoint{{URL: b.URL, Weight: 1}}, nil)
+	c.discoverEndpoints(context.Background())
+	checkReports(t, c, 1)
+	if st := c.Endpoints(); len(st) != 1 || st[0].URL != b.URL || b.checks.Load() != 1 {
+		t.Errorf("Endpoints() = %+v with %d check(s) on b, want b only", st, b.checks.Load())
+	}
+}
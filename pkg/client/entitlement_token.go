@@ -0,0 +1,58 @@
+package client
+
+import (
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// DefaultEntitlementTokenTTL is the lifetime of tokens from EntitlementToken
+const DefaultEntitlementTokenTTL = 5 * time.Minute
+
+// SetEntitlementTokenTTL sets the lifetime of tokens from EntitlementToken.
+// Non-positive values restore DefaultEntitlementTokenTTL.
+func (c *Client) SetEntitlementTokenTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenTTL = ttl
+}
+
+// EntitlementToken checks featureID and, if enabled, returns a short-lived
+// token signed with the instance key asserting that this instance is
+// licensed for it. Downstream services verify it with
+// auth.Verifier.VerifyEntitlementToken without their own LCC registration,
+// trusting the instance keys registered with LCC or certified by their CA.
+//
+// Example:
+//
+//	token, err := lccClient.EntitlementToken("advanced_analytics")
+//	if err != nil {
+//	    return err
+//	}
+//	req.Header.Set("X-LCC-Entitlement", token)
+func (c *Client) EntitlementToken(featureID string) (string, error) {
+	if err := c.checkOpen("issue entitlement token"); err != nil {
+		return "", err
+	}
+
+	status, err := c.CheckFeature(featureID)
+	if err != nil {
+		return "", err
+	}
+	if !status.Enabled {
+		return "", NewFeatureError(featureID, status, nil)
+	}
+
+	c.mu.RLock()
+	ttl := c.tokenTTL
+	c.mu.RUnlock()
+	if ttl <= 0 {
+		ttl = DefaultEntitlementTokenTTL
+	}
+
+	return c.signer.Load().IssueEntitlementToken(auth.EntitlementClaims{
+		InstanceID: c.instanceID,
+		ProductID:  c.productID,
+		FeatureID:  featureID,
+	}, ttl)
+}
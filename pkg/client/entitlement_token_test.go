@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_EntitlementToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"feature_id": id,
+			"enabled":    id == "analytics",
+			"reason":     "feature_not_in_license",
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetEntitlementTokenTTL(time.Minute)
+
+	token, err := c.EntitlementToken("analytics")
+	if err != nil {
+		t.Fatalf("EntitlementToken() error = %v", err)
+	}
+
+	publicKeyPEM, _ := c.keyPair.GetPublicKeyPEM()
+	trusted, _ := auth.NewKeyAllowlist([]byte(publicKeyPEM))
+	claims, err := auth.NewVerifier(auth.WithKeyLookup(trusted)).VerifyEntitlementToken(token)
+	if err != nil {
+		t.Fatalf("VerifyEntitlementToken() error = %v", err)
+	}
+	if claims.InstanceID != c.GetInstanceID() || claims.FeatureID != "analytics" || claims.ProductID != "test-app" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if ttl := claims.ExpiresAt - claims.IssuedAt; ttl != 60 {
+		t.Errorf("token lifetime = %ds, want 60s", ttl)
+	}
+
+	_, err = c.EntitlementToken("reports")
+	if !errors.Is(err, ErrFeatureNotLicensed) {
+		t.Errorf("EntitlementToken(reports) error = %v, want ErrFeatureNotLicensed", err)
+	}
+}
+
+func TestClient_EntitlementTokenSaltedInstanceID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		InstanceIDSalt: "customer-a",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	fp, _ := c.keyPair.GetFingerprint()
+	if c.GetInstanceID() == fp {
+		t.Fatal("salted instance ID equals the key fingerprint")
+	}
+
+	token, err := c.EntitlementToken("analytics")
+	if err != nil {
+		t.Fatalf("EntitlementToken() error = %v", err)
+	}
+	publicKeyPEM, _ := c.keyPair.GetPublicKeyPEM()
+	trusted, _ := auth.NewKeyAllowlist([]byte(publicKeyPEM))
+	claims, err := auth.NewVerifier(auth.WithKeyLookup(trusted)).VerifyEntitlementToken(token)
+	if err != nil {
+		t.Fatalf("VerifyEntitlementToken() of a salted-ID client's token error = %v", err)
+	}
+	if claims.InstanceID != c.GetInstanceID() || claims.KeyFingerprint != fp {
+		t.Errorf("claims = %+v, want instance %s signed by key %s", claims, c.GetInstanceID(), fp)
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReasonEnvironmentMismatch is reported by feature checks when the license
+// is scoped to environments other than the one this client declared
+const ReasonEnvironmentMismatch = "environment_mismatch"
+
+// ErrEnvironmentNotLicensed is returned (wrapped in an
+// *EnvironmentNotLicensedError) by Register when the license is scoped to
+// environments that do not include SDKConfig.Environment. The instance is
+// deregistered.
+var ErrEnvironmentNotLicensed = errors.New("environment not licensed")
+
+// EnvironmentNotLicensedError reports that the declared environment is not
+// covered by the license. It matches ErrEnvironmentNotLicensed via errors.Is.
+type EnvironmentNotLicensedError struct {
+	Environment string
+	Licensed    []string
+}
+
+func (e *EnvironmentNotLicensedError) Error() string {
+	env := e.Environment
+	if env == "" {
+		env = "(none)"
+	}
+	return fmt.Sprintf("%s: environment %s is not in licensed environments [%s]",
+		ErrEnvironmentNotLicensed, env, strings.Join(e.Licensed, ", "))
+}
+
+// Is allows errors.Is(err, ErrEnvironmentNotLicensed) to match
+func (e *EnvironmentNotLicensedError) Is(target error) bool {
+	return target == ErrEnvironmentNotLicensed
+}
+
+// checkEnvironment validates env against the licensed environments.
+// Labels compare case-insensitively; an empty list means unscoped.
+func checkEnvironment(env string, licensed []string) error {
+	if len(licensed) == 0 {
+		return nil
+	}
+	for _, l := range licensed {
+		if strings.EqualFold(l, env) {
+			return nil
+		}
+	}
+	return &EnvironmentNotLicensedError{Environment: env, Licensed: licensed}
+}
+
+// Environment returns the deployment environment declared at registration
+func (c *Client) Environment() string {
+	return c.environment
+}
+
+// LicensedEnvironments returns the environments the license is scoped to,
+// as reported at registration. Returns nil if the license is unscoped.
+func (c *Client) LicensedEnvironments() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.licensedEnvironments
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		licensed []string
+		wantErr  bool
+	}{
+		{"unscoped", "staging", nil, false},
+		{"match", "prod", []string{"prod"}, false},
+		{"case-insensitive", "Prod", []string{"prod", "dr"}, false},
+		{"mismatch", "staging", []string{"prod"}, true},
+		{"undeclared", "", []string{"prod"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEnvironment(tt.env, tt.licensed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkEnvironment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrEnvironmentNotLicensed) {
+				t.Errorf("errors.Is(err, ErrEnvironmentNotLicensed) = false, err = %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_RegisterEnvironment(t *testing.T) {
+	var declared string
+	var deregistered int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/deregister" {
+			deregistered++
+		}
+		if r.URL.Path == "/api/v1/sdk/register" {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			declared, _ = body["environment"].(string)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"licensed_environments": []string{"prod"}})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.environment = "staging"
+
+	err := c.Register()
+	var envErr *EnvironmentNotLicensedError
+	if !errors.As(err, &envErr) || envErr.Environment != "staging" {
+		t.Fatalf("Register() error = %v, want *EnvironmentNotLicensedError for staging", err)
+	}
+	if declared != "staging" {
+		t.Errorf("registration declared environment %q, want staging", declared)
+	}
+	if got := c.Lifecycle(); got != StateNew {
+		t.Errorf("Lifecycle() = %s, want new", got)
+	}
+	if deregistered != 1 {
+		t.Errorf("deregistrations after a rejected environment = %d, want 1", deregistered)
+	}
+
+	c.environment = "prod"
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if got := c.LicensedEnvironments(); len(got) != 1 || got[0] != "prod" {
+		t.Errorf("LicensedEnvironments() = %v", got)
+	}
+}
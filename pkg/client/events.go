@@ -0,0 +1,111 @@
+package client
+
+import "time"
+
+// EventType identifies the kind of an Event
+type EventType string
+
+// Event types reported to OnEvent callbacks
+const (
+	// EventQuotaExceeded: Consume was denied by the quota
+	EventQuotaExceeded EventType = "quota_exceeded"
+
+	// EventTPSExceeded: CheckTPS found the rate over a TPS or rate limit
+	EventTPSExceeded EventType = "tps_exceeded"
+
+	// EventFeatureDenied: a feature check returned a disabled status
+	EventFeatureDenied EventType = "feature_denied"
+
+	// EventHeartbeatFailed: a background heartbeat failed
+	EventHeartbeatFailed EventType = "heartbeat_failed"
+
+	// EventRegisterSucceeded: the client registered (or, offline, loaded
+	// its license)
+	EventRegisterSucceeded EventType = "register_succeeded"
+
+	// EventLicenseChanged: a refreshed feature status differs from the
+	// cached one
+	EventLicenseChanged EventType = "license_changed"
+
+	// EventSDKDeprecated: the server reported that this SDK version is
+	// older than the minimum it supports
+	EventSDKDeprecated EventType = "sdk_deprecated"
+
+	// EventConnectionChanged: heartbeat results moved the connection to
+	// another ConnectionState
+	EventConnectionChanged EventType = "connection_changed"
+)
+
+// Event is a license decision or client state change reported to OnEvent
+// callbacks. Fields not relevant to the Type are left zero.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// FeatureID is the feature concerned, "__product__" for product limits
+	FeatureID string
+
+	// Reason is the denial reason (FeatureDenied)
+	Reason string
+
+	// Err is the error behind the event (QuotaExceeded, TPSExceeded,
+	// HeartbeatFailed, ConnectionChanged)
+	Err error
+
+	// Change describes the new status (LicenseChanged)
+	Change *FeatureStatusChange
+
+	// Advisory is the server's SDK version advisory (SDKDeprecated)
+	Advisory *SDKAdvisory
+
+	// Connection describes the transition (ConnectionChanged)
+	Connection *ConnectionStateChange
+}
+
+// OnEvent registers a callback fired for license decisions and client state
+// changes, so applications can alert, log or degrade gracefully in one
+// place instead of at every call site.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy), in order for
+// each callback.
+//
+// Example:
+//
+//	client.OnEvent(func(ev client.Event) {
+//	    switch ev.Type {
+//	    case client.EventQuotaExceeded, client.EventTPSExceeded:
+//	        alerts.Warn("license limit reached", ev.Err)
+//	    case client.EventHeartbeatFailed:
+//	        health.SetDegraded(ev.Err)
+//	    }
+//	})
+func (c *Client) OnEvent(fn func(Event)) {
+	if fn == nil {
+		return
+	}
+	h := namedHook[func(Event)]{name: c.hooks.name("OnEvent"), fn: fn}
+	c.observers.mu.Lock()
+	defer c.observers.mu.Unlock()
+	c.observers.event = append(c.observers.event, h)
+}
+
+// emit dispatches ev to the OnEvent callbacks
+func (c *Client) emit(ev Event) {
+	c.observers.mu.Lock()
+	handlers := c.observers.event
+	c.observers.mu.Unlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	ev.Time = time.Now()
+	for _, h := range handlers {
+		fn := h.fn
+		c.hooks.dispatch(h.name, func() { fn(ev) })
+	}
+}
+
+// emitLicenseChanged reports a feature status change as an event
+func (c *Client) emitLicenseChanged(change FeatureStatusChange) {
+	c.emit(Event{Type: EventLicenseChanged, FeatureID: change.FeatureID, Change: &change})
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_OnEvent(t *testing.T) {
+	var denied atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled": !denied.Load(), "reason": "over_limit",
+				"limits": map[string]interface{}{"max_tps": 0.5},
+			})
+		case "/api/v1/sdk/features/reports/check":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": !denied.Load(), "reason": "not_licensed"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	var mu sync.Mutex
+	var events []Event
+	c.OnEvent(func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	c.Consume(1)
+	c.Consume(1)
+	if ok, _, _ := c.CheckTPS(); ok {
+		t.Fatal("CheckTPS() should exceed a 0.5 TPS limit")
+	}
+	denied.Store(true)
+	c.cache.expireAll()
+	c.Consume(1)
+	c.CheckFeature("reports")
+	srv.Close()
+	c.notifyHeartbeat(c.sendHeartbeat())
+	c.hooks.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	var got []EventType
+	byType := make(map[EventType]Event)
+	for _, ev := range events {
+		got = append(got, ev.Type)
+		byType[ev.Type] = ev
+		if ev.Time.IsZero() {
+			t.Errorf("event %s has no time", ev.Type)
+		}
+	}
+	for _, typ := range []EventType{EventRegisterSucceeded, EventTPSExceeded, EventQuotaExceeded, EventFeatureDenied, EventLicenseChanged, EventHeartbeatFailed} {
+		if _, ok := byType[typ]; !ok {
+			t.Errorf("events = %v, missing %s", got, typ)
+		}
+	}
+	if ev := byType[EventFeatureDenied]; ev.FeatureID != "reports" || ev.Reason != "not_licensed" {
+		t.Errorf("FeatureDenied event = %+v", ev)
+	}
+	if ev := byType[EventLicenseChanged]; ev.Change == nil || !ev.Change.EnabledChanged() {
+		t.Errorf("LicenseChanged event = %+v", ev)
+	}
+	if ev := byType[EventQuotaExceeded]; ev.FeatureID != productFeatureID || ev.Err == nil {
+		t.Errorf("QuotaExceeded event = %+v", ev)
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FeatureFlagsJSON returns the licensed state of every manifest feature (see
+// SetManifest) as a compact JSON object for web frontends, so UIs hide
+// unlicensed functionality consistently with backend enforcement. Each
+// feature maps to false when it is not usable, to true when it is, or to its
+// variant name when the license grants one:
+//
+//	{"export":true,"reports":"pro","sso":false}
+//
+// Features that cannot be checked are reported as false. Keys are sorted, so
+// identical states produce identical bytes; etag is a strong ETag of the
+// body.
+func (c *Client) FeatureFlagsJSON() (body []byte, etag string, err error) {
+	c.mu.RLock()
+	manifest := c.manifest
+	c.mu.RUnlock()
+
+	if manifest == nil {
+		return nil, "", fmt.Errorf("manifest not set (call SetManifest first)")
+	}
+
+	flags := make(map[string]interface{}, len(manifest.Features))
+	for _, feature := range manifest.Features {
+		status, err := c.CheckFeature(feature.ID)
+		switch {
+		case err != nil || !status.Enabled:
+			flags[feature.ID] = false
+		case status.Variant != "":
+			flags[feature.ID] = status.Variant
+		default:
+			flags[feature.ID] = true
+		}
+	}
+
+	body, err = json.Marshal(flags)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal feature flags: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return body, `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// FeatureFlagsHandler serves FeatureFlagsJSON for UIs to load and refresh.
+// Responses carry an ETag and Cache-Control: no-cache, so browsers revalidate
+// each time; a request whose If-None-Match matches is answered 304 Not
+// Modified without a body. Flags follow the client's feature cache, so a
+// refresh reflects license changes within CacheTTL.
+//
+// Example:
+//
+//	mux.Handle("GET /api/feature-flags", client.FeatureFlagsHandler())
+func (c *Client) FeatureFlagsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, etag, err := c.FeatureFlagsJSON()
+		if err != nil {
+			debugLogf("FeatureFlagsHandler: %v", err)
+			http.Error(w, "feature flags unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(body)
+	})
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// validators (W/) compare equal to their strong form, as the header requires.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_FeatureFlags(t *testing.T) {
+	var mu sync.Mutex
+	statuses := map[string]map[string]interface{}{
+		"reports": {"enabled": true, "variant": "pro"},
+		"export":  {"enabled": true},
+		"sso":     {"enabled": false, "reason": ReasonNotInLicense},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		mu.Lock()
+		defer mu.Unlock()
+		if status, ok := statuses[id]; ok {
+			_ = json.NewEncoder(w).Encode(status)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, _, err := c.FeatureFlagsJSON(); err == nil {
+		t.Error("FeatureFlagsJSON() without a manifest should fail")
+	}
+	c.SetManifest(&config.Manifest{Features: []config.FeatureConfig{
+		{ID: "sso"}, {ID: "reports"}, {ID: "export"}, {ID: "broken"},
+	}})
+
+	body, etag, err := c.FeatureFlagsJSON()
+	if err != nil {
+		t.Fatalf("FeatureFlagsJSON() error = %v", err)
+	}
+	if want := `{"broken":false,"export":true,"reports":"pro","sso":false}`; string(body) != want {
+		t.Errorf("FeatureFlagsJSON() = %s, want %s", body, want)
+	}
+	if _, etag2, _ := c.FeatureFlagsJSON(); etag2 != etag || !strings.HasPrefix(etag, `"`) {
+		t.Errorf("ETag = %s then %s, want a stable quoted ETag", etag, etag2)
+	}
+
+	h := c.FeatureFlagsHandler()
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/flags", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	if rec.Code != http.StatusOK || rec.Body.String() != string(body) || rec.Header().Get("ETag") != etag {
+		t.Errorf("GET = %d %s (ETag %s)", rec.Code, rec.Body, rec.Header().Get("ETag"))
+	}
+	if rec := get(`"stale", W/` + etag); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("GET with matching If-None-Match = %d, want 304 without body", rec.Code)
+	}
+
+	// A license change yields a new ETag once the cache refreshes
+	mu.Lock()
+	statuses["sso"] = map[string]interface{}{"enabled": true}
+	mu.Unlock()
+	c.ClearCache()
+	if rec := get(etag); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"sso":true`) {
+		t.Errorf("GET after license change = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/flags", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", rec.Code)
+	}
+}
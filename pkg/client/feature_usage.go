@@ -0,0 +1,124 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FeatureUsage is the runtime usage of one feature since the client was
+// created (or ResetFeatureUsage was called)
+type FeatureUsage struct {
+	FeatureID string
+
+	// InManifest is true for features declared in the manifest
+	InManifest bool
+
+	// Checks counts CheckFeature calls; Allowed and Denied split them by
+	// outcome (failed checks count as neither)
+	Checks  int64
+	Allowed int64
+	Denied  int64
+
+	// Zero if the feature was never checked or allowed
+	LastChecked time.Time
+	LastAllowed time.Time
+}
+
+// Used reports whether the feature was allowed at least once
+func (u FeatureUsage) Used() bool {
+	return u.Allowed > 0
+}
+
+// featureUsageTracker counts feature checks by outcome
+type featureUsageTracker struct {
+	mu    sync.Mutex
+	usage map[string]*FeatureUsage
+}
+
+func newFeatureUsageTracker() *featureUsageTracker {
+	return &featureUsageTracker{usage: make(map[string]*FeatureUsage)}
+}
+
+// record accounts for one check of featureID
+func (t *featureUsageTracker) record(featureID string, status *FeatureStatus, err error) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u, ok := t.usage[featureID]
+	if !ok {
+		u = &FeatureUsage{FeatureID: featureID}
+		t.usage[featureID] = u
+	}
+	u.Checks++
+	u.LastChecked = now
+	switch {
+	case err != nil:
+	case status.Enabled:
+		u.Allowed++
+		u.LastAllowed = now
+	default:
+		u.Denied++
+	}
+}
+
+func (t *featureUsageTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage = make(map[string]*FeatureUsage)
+}
+
+// FeatureUsage reports how often each feature was checked and allowed, and
+// when it was last checked and used, sorted by feature ID. With a manifest
+// (see SetManifest) every declared feature is included, so licensed
+// features that are never used show up with zero counts.
+func (c *Client) FeatureUsage() []FeatureUsage {
+	c.mu.RLock()
+	manifest := c.manifest
+	c.mu.RUnlock()
+
+	c.featureUsage.mu.Lock()
+	byID := make(map[string]FeatureUsage, len(c.featureUsage.usage))
+	for id, u := range c.featureUsage.usage {
+		byID[id] = *u
+	}
+	c.featureUsage.mu.Unlock()
+
+	if manifest != nil {
+		for _, f := range manifest.Features {
+			u, ok := byID[f.ID]
+			if !ok {
+				u = FeatureUsage{FeatureID: f.ID}
+			}
+			u.InManifest = true
+			byID[f.ID] = u
+		}
+	}
+
+	report := make([]FeatureUsage, 0, len(byID))
+	for _, u := range byID {
+		report = append(report, u)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].FeatureID < report[j].FeatureID })
+	return report
+}
+
+// UnusedFeatures returns the manifest features that were never allowed,
+// i.e. candidates for dead code or for removal from the license
+func (c *Client) UnusedFeatures() []string {
+	var unused []string
+	for _, u := range c.FeatureUsage() {
+		if u.InManifest && !u.Used() {
+			unused = append(unused, u.FeatureID)
+		}
+	}
+	return unused
+}
+
+// ResetFeatureUsage clears the usage counters, e.g. at the start of a
+// reporting period
+func (c *Client) ResetFeatureUsage() {
+	c.featureUsage.reset()
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_FeatureUsage(t *testing.T) {
+	licensed := map[string]bool{"reports": true, "export": false}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		if id == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": id, "enabled": licensed[id]})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetManifest(&config.Manifest{Features: []config.FeatureConfig{
+		{ID: "reports"}, {ID: "export"}, {ID: "dashboards"},
+	}})
+
+	for i := 0; i < 3; i++ {
+		_, _ = c.CheckFeature("reports")
+	}
+	_, _ = c.CheckFeature("export")
+	_, _ = c.CheckFeature("broken")
+	_, _, _ = c.CheckCapacity(1) // product checks are not feature usage
+
+	usage := c.FeatureUsage()
+	byID := make(map[string]FeatureUsage)
+	var ids []string
+	for _, u := range usage {
+		byID[u.FeatureID] = u
+		ids = append(ids, u.FeatureID)
+	}
+	if want := []string{"broken", "dashboards", "export", "reports"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("FeatureUsage() features = %v, want %v", ids, want)
+	}
+
+	tests := []struct {
+		id                      string
+		checks, allowed, denied int64
+		inManifest              bool
+	}{
+		{"reports", 3, 3, 0, true},
+		{"export", 1, 0, 1, true},
+		{"dashboards", 0, 0, 0, true},
+		{"broken", 1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		u := byID[tt.id]
+		if u.Checks != tt.checks || u.Allowed != tt.allowed || u.Denied != tt.denied || u.InManifest != tt.inManifest {
+			t.Errorf("usage[%s] = %+v, want checks=%d allowed=%d denied=%d manifest=%v", tt.id, u, tt.checks, tt.allowed, tt.denied, tt.inManifest)
+		}
+	}
+	if r := byID["reports"]; r.LastChecked.IsZero() || r.LastAllowed.IsZero() {
+		t.Errorf("reports timestamps not set: %+v", r)
+	}
+	if e := byID["export"]; e.LastChecked.IsZero() || !e.LastAllowed.IsZero() {
+		t.Errorf("export timestamps = %+v, want checked but never allowed", e)
+	}
+
+	if got, want := c.UnusedFeatures(), []string{"dashboards", "export"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnusedFeatures() = %v, want %v", got, want)
+	}
+
+	c.ResetFeatureUsage()
+	if u := c.FeatureUsage(); len(u) != 3 || u[2].Checks != 0 {
+		t.Errorf("FeatureUsage() after reset = %+v, want manifest features only, zeroed", u)
+	}
+}
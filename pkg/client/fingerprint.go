@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+
+	"github.com/yourorg/lcc-sdk/pkg/fingerprint"
+)
+
+// SetFingerprint sets the host fingerprint sent at registration, e.g. one
+// collected with custom options; nil sends none. Call it before Register.
+func (c *Client) SetFingerprint(fp *fingerprint.Fingerprint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hostFingerprint = fp
+	c.fingerprintSources = nil
+}
+
+// Fingerprint returns the host fingerprint sent at registration, or nil
+// if none is set or it has not been collected yet
+func (c *Client) Fingerprint() *fingerprint.Fingerprint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hostFingerprint
+}
+
+// collectFingerprint returns the fingerprint to register with, collecting
+// it on first use when SDKConfig.Fingerprint is set. A host where no
+// component can be read registers without one.
+func (c *Client) collectFingerprint(ctx context.Context) *fingerprint.Fingerprint {
+	c.mu.RLock()
+	fp, sources := c.hostFingerprint, c.fingerprintSources
+	c.mu.RUnlock()
+	if fp != nil || sources == nil {
+		return fp
+	}
+
+	fp, err := fingerprint.Collect(ctx, fingerprint.WithSources(sources...))
+	if err != nil {
+		debugLogf("WARNING: registering without a host fingerprint: %v", err)
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hostFingerprint = fp
+	return fp
+}
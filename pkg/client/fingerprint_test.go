@@ -0,0 +1,77 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/fingerprint"
+)
+
+func TestClient_RegisterSendsFingerprint(t *testing.T) {
+	var body struct {
+		Fingerprint string `json:"fingerprint"`
+		Metadata    struct {
+			Fingerprint *fingerprint.Fingerprint `json:"fingerprint"`
+		} `json:"metadata"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/register" {
+			body.Fingerprint, body.Metadata.Fingerprint = "", nil
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// None by default
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if body.Fingerprint != "" || body.Metadata.Fingerprint != nil || c.Fingerprint() != nil {
+		t.Errorf("fingerprint sent by default: %+v", body)
+	}
+
+	// Collected from the configured sources
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:             srv.URL,
+		ProductID:          "test-app",
+		ProductVersion:     "1.0.0",
+		Timeout:            5 * time.Second,
+		Fingerprint:        true,
+		FingerprintSources: []string{"hostname"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	want, err := fingerprint.Collect(t.Context(), fingerprint.WithSources(fingerprint.SourceHostname))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if body.Fingerprint != want.ID || !reflect.DeepEqual(body.Metadata.Fingerprint, want) {
+		t.Errorf("registered fingerprint = %+v, want %+v", body, want)
+	}
+	if got := c.Fingerprint(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fingerprint() = %+v, want %+v", got, want)
+	}
+
+	// Set by the application
+	custom := &fingerprint.Fingerprint{ID: "abc", Components: map[fingerprint.Source]string{fingerprint.SourceMachineID: "def"}}
+	c = newTestClient(t, srv.URL)
+	c.SetFingerprint(custom)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if body.Fingerprint != "abc" || !reflect.DeepEqual(body.Metadata.Fingerprint, custom) {
+		t.Errorf("registered fingerprint = %+v, want %+v", body, custom)
+	}
+}
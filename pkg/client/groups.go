@@ -0,0 +1,101 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownGroup is returned by CheckGroup for a group not defined in the
+// manifest
+var ErrUnknownGroup = errors.New("unknown feature group")
+
+// GroupState summarizes the features of a group
+type GroupState string
+
+// Group states reported by CheckGroup
+const (
+	// GroupEnabled means every feature in the group is enabled
+	GroupEnabled GroupState = "enabled"
+	// GroupPartial means some, but not all, features are enabled
+	GroupPartial GroupState = "partial"
+	// GroupDisabled means no feature in the group is enabled
+	GroupDisabled GroupState = "disabled"
+)
+
+// GroupStatus is the result of a feature group check
+type GroupStatus struct {
+	GroupID string
+	State   GroupState
+
+	// Enabled lists enabled features in manifest order
+	Enabled []string
+
+	// Disabled maps each unusable feature to its denial reason (or check error)
+	Disabled map[string]string
+}
+
+// AllEnabled reports whether every feature in the group is enabled
+func (s *GroupStatus) AllEnabled() bool {
+	return s.State == GroupEnabled
+}
+
+// AnyEnabled reports whether at least one feature in the group is enabled
+func (s *GroupStatus) AnyEnabled() bool {
+	return s.State != GroupDisabled
+}
+
+// CheckGroup checks every feature of a manifest group (see
+// config.Manifest.Groups) and reports whether the group is enabled,
+// partially enabled or disabled. Feature check errors count as disabled.
+//
+// Example:
+//
+//	status, err := client.CheckGroup("reporting_suite")
+//	if err == nil && status.AllEnabled() {
+//	    mountReportingRoutes()
+//	}
+func (c *Client) CheckGroup(groupID string) (*GroupStatus, error) {
+	c.mu.RLock()
+	manifest := c.manifest
+	c.mu.RUnlock()
+
+	if manifest == nil {
+		return nil, fmt.Errorf("manifest not set (call SetManifest first)")
+	}
+	featureIDs, ok := manifest.GroupFeatures(groupID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGroup, groupID)
+	}
+
+	status := &GroupStatus{
+		GroupID:  groupID,
+		Disabled: make(map[string]string),
+	}
+	for _, id := range featureIDs {
+		fs, err := c.CheckFeature(id)
+		switch {
+		case err != nil:
+			status.Disabled[id] = "check_error: " + err.Error()
+		case !fs.Enabled:
+			reason := fs.Reason
+			if reason == "" {
+				reason = "disabled"
+			}
+			status.Disabled[id] = reason
+		default:
+			status.Enabled = append(status.Enabled, id)
+		}
+	}
+
+	switch {
+	case len(status.Disabled) == 0:
+		status.State = GroupEnabled
+	case len(status.Enabled) == 0:
+		status.State = GroupDisabled
+	default:
+		status.State = GroupPartial
+	}
+
+	debugLogf("CheckGroup %s: %s (%d/%d enabled)", groupID, status.State, len(status.Enabled), len(featureIDs))
+	return status, nil
+}
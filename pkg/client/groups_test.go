@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_CheckGroup(t *testing.T) {
+	licensed := map[string]bool{"reports": true, "dashboards": true, "export": false}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"feature_id": id,
+			"enabled":    licensed[id],
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, err := c.CheckGroup("reporting"); err == nil {
+		t.Error("CheckGroup() without manifest should fail")
+	}
+
+	c.SetManifest(&config.Manifest{
+		Groups: map[string][]string{
+			"viewing":   {"reports", "dashboards"},
+			"reporting": {"reports", "export"},
+			"bulk":      {"export"},
+		},
+	})
+
+	tests := []struct {
+		group string
+		want  GroupState
+	}{
+		{"viewing", GroupEnabled},
+		{"reporting", GroupPartial},
+		{"bulk", GroupDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			status, err := c.CheckGroup(tt.group)
+			if err != nil {
+				t.Fatalf("CheckGroup() error = %v", err)
+			}
+			if status.State != tt.want {
+				t.Errorf("State = %s, want %s", status.State, tt.want)
+			}
+			if len(status.Enabled)+len(status.Disabled) != len(c.manifest.Groups[tt.group]) {
+				t.Errorf("status = %+v does not cover every feature", status)
+			}
+		})
+	}
+
+	if _, err := c.CheckGroup("missing"); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("CheckGroup(missing) error = %v, want ErrUnknownGroup", err)
+	}
+}
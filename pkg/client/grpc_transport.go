@@ -0,0 +1,417 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/yourorg/lcc-sdk/pkg/lccpb"
+)
+
+// grpcTransport carries SDK calls over gRPC (SDKConfig.Protocol "grpc")
+// instead of HTTP. Calls go through the same retry policy, retry budget and
+// circuit breaker as HTTP calls.
+type grpcTransport struct {
+	mu      sync.Mutex
+	target  string
+	opts    []grpc.DialOption
+	conn    *grpc.ClientConn
+	owned   bool // conn was dialed by the SDK and is closed by Close
+	timeout time.Duration
+}
+
+// newGRPCTransport prepares a connection to target. "http://" selects
+// plaintext; a bare "host:port" or "https://" uses TLS, checking the
+// server key against pins. No I/O happens until the first call.
+func newGRPCTransport(target string, timeout time.Duration, pins *pinSet) (*grpcTransport, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12, VerifyConnection: pins.verifyConnection})
+	switch {
+	case strings.HasPrefix(target, "http://"):
+		target = strings.TrimPrefix(target, "http://")
+		creds = insecure.NewCredentials()
+	case strings.HasPrefix(target, "https://"):
+		target = strings.TrimPrefix(target, "https://")
+	}
+	target = strings.TrimRight(target, "/")
+
+	t := &grpcTransport{
+		target:  target,
+		opts:    []grpc.DialOption{grpc.WithTransportCredentials(creds)},
+		owned:   true,
+		timeout: timeout,
+	}
+	if err := t.dial(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *grpcTransport) dial() error {
+	conn, err := grpc.NewClient(t.target, t.opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client for %s: %w", t.target, err)
+	}
+	t.mu.Lock()
+	t.conn = conn
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *grpcTransport) current() *grpc.ClientConn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn
+}
+
+func (t *grpcTransport) callTimeout() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timeout
+}
+
+func (t *grpcTransport) setTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timeout = timeout
+}
+
+// close closes an SDK-dialed connection
+func (t *grpcTransport) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.owned && t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
+
+// reopen re-dials a connection closed by close
+func (t *grpcTransport) reopen() error {
+	if !t.owned || t.current() != nil {
+		return nil
+	}
+	return t.dial()
+}
+
+// SetGRPCConn switches the client to the gRPC protocol over conn, e.g. a
+// connection with mesh credentials or interceptors. The SDK does not close
+// conn.
+func (c *Client) SetGRPCConn(conn *grpc.ClientConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.grpc != nil {
+		c.grpc.close()
+	}
+	c.grpc = &grpcTransport{conn: conn, timeout: c.baseHTTPClient.Timeout}
+}
+
+func (c *Client) grpcTransport() *grpcTransport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.grpc
+}
+
+// signGRPC signs a gRPC request like an HTTP request: POST to the full
+// method name, over the deterministic serialization of req
+func (c *Client) signGRPC(method string, req proto.Message) (metadata.MD, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	hreq, err := http.NewRequest("POST", method, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	signer := c.signer.Load()
+	bodyHash, err := signer.BodyHash(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	if err := signer.SignRequestWithBodyHash(hreq, bodyHash); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	md := metadata.MD{}
+	for k, v := range hreq.Header {
+		if strings.HasPrefix(strings.ToLower(k), "x-lcc-") {
+			md.Set(k, v...)
+		}
+	}
+	return md, nil
+}
+
+// invokeGRPC is doWithRetry for gRPC calls: it signs every attempt and
+// retries UNAVAILABLE, RESOURCE_EXHAUSTED and DEADLINE_EXCEEDED.
+func (c *Client) invokeGRPC(ctx context.Context, op, method string, req, resp proto.Message, md metadata.MD) error {
+	if err := c.checkEgress(op); err != nil {
+		return err
+	}
+	t := c.grpcTransport()
+	c.mu.RLock()
+	r := c.retrier
+	breaker := c.breaker
+	c.mu.RUnlock()
+	featureID := breakerFeature(ctx)
+	featureBreaker := breaker.feature(featureID)
+
+	for attempt := 0; ; attempt++ {
+		if err := breaker.allowFeature(featureBreaker, featureID); err != nil {
+			return err
+		}
+		signed, err := c.signGRPC(method, req)
+		if err != nil {
+			breaker.record(featureBreaker, true, false)
+			return err
+		}
+		conn := t.current()
+		if conn == nil {
+			return &StateError{Op: strings.ToLower(op), State: StateClosed}
+		}
+
+		callCtx := metadata.NewOutgoingContext(ctx, metadata.Join(signed, md))
+		cancel := func() {}
+		if timeout := t.callTimeout(); timeout > 0 {
+			callCtx, cancel = context.WithTimeout(callCtx, timeout)
+		}
+		err = conn.Invoke(callCtx, method, req, resp)
+		cancel()
+
+		code := status.Code(err)
+		breaker.record(featureBreaker, grpcUnreachable(code), grpcServerFailure(code))
+
+		if !grpcRetryable(code) {
+			if err == nil {
+				r.succeeded()
+			}
+			return err
+		}
+		if attempt >= r.policy.MaxRetries {
+			return err
+		}
+		if !r.allow() {
+			debugLogf("%s: retry budget exhausted", op)
+			return err
+		}
+		delay := r.policy.backoff(attempt)
+		debugLogf("%s: transient failure (attempt %d), retrying in %s", op, attempt+1, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// grpcRetryable reports whether a gRPC status is transient (the
+// equivalents of 429, 502, 503 and 504)
+func grpcRetryable(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// grpcServerFailure reports whether a gRPC status counts as a server
+// failure for the circuit breaker (the equivalents of 5xx)
+func grpcServerFailure(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// grpcUnreachable reports whether a gRPC status means the server could not
+// be reached, rather than a failure of the called method
+func grpcUnreachable(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// registerGRPC is registerHTTP over gRPC
+func (c *Client) registerGRPC(ctx context.Context, pubPEM string, meta map[string]interface{}) (registerResponse, error) {
+	var result registerResponse
+
+	// Round-trip through JSON so nested values (build info) become a Struct
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return result, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	md, err := structpb.NewStruct(fields)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	req := &lccpb.RegisterRequest{
+		ProductId:   c.productID,
+		Version:     c.productVer,
+		PublicKey:   pubPEM,
+		Metadata:    md,
+		Environment: c.environment,
+	}
+	resp := &lccpb.RegisterResponse{}
+	if err := c.invokeGRPC(ctx, "Register", lccpb.SDKService_Register_FullMethodName, req, resp, nil); err != nil {
+		debugLogf("Register: gRPC error: %v", err)
+		return result, fmt.Errorf("registration failed: %w", err)
+	}
+
+	if v := resp.GetLicensedVersions(); v != nil {
+		result.LicensedVersions = &VersionRange{Min: v.GetMinVersion(), Max: v.GetMaxVersion()}
+	}
+	result.LicensedEnvironments = resp.GetLicensedEnvironments()
+	return result, nil
+}
+
+// heartbeatGRPC is the gRPC heartbeat
+func (c *Client) heartbeatGRPC() error {
+	req := &lccpb.HeartbeatRequest{Version: c.productVer}
+	if c.tpsPartitioning {
+		req.TpsPartitioning = true
+		if tps, err := c.getCurrentTPS(); err == nil {
+			req.CurrentTps = tps
+		}
+	}
+	peak, hasPeak := c.capacityPeaks.snapshot()
+	hasPeak = hasPeak && c.IsClusterReporter()
+	if hasPeak {
+		p := peak.payload()
+		req.CapacityPeak = &lccpb.CapacityPeak{
+			Peak:        int64(p.Peak),
+			PeakAt:      p.PeakAt,
+			WindowStart: p.WindowStart,
+			WindowEnd:   p.WindowEnd,
+		}
+	}
+
+	resp := &lccpb.HeartbeatResponse{}
+	if err := c.invokeGRPC(context.Background(), "Heartbeat", lccpb.SDKService_Heartbeat_FullMethodName, req, resp, nil); err != nil {
+		return fmt.Errorf("heartbeat request failed: %w", err)
+	}
+	if hasPeak {
+		c.capacityPeaks.reported(peak)
+	}
+	if share := resp.GetTpsShare(); c.tpsPartitioning && share != nil {
+		c.applyTPSShare(TPSShare{
+			MaxTPS:    share.GetMaxTps(),
+			Instances: int(share.GetInstances()),
+			ExpiresAt: share.GetExpiresAt(),
+		})
+	}
+	return nil
+}
+
+// checkFeatureGRPC is queryFeature over gRPC
+func (c *Client) checkFeatureGRPC(featureID string) (*FeatureStatus, error) {
+	resp := &lccpb.FeatureStatus{}
+	req := &lccpb.CheckFeatureRequest{FeatureId: featureID}
+	if err := c.invokeGRPC(withBreakerFeature(context.Background(), featureID), "CheckFeature", lccpb.SDKService_CheckFeature_FullMethodName, req, resp, nil); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, fmt.Errorf("feature check failed: %w", err)
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return featureStatusFromProto(resp), nil
+}
+
+// productStatusGRPC is queryProductStatus over gRPC
+func (c *Client) productStatusGRPC() (*FeatureStatus, error) {
+	resp := &lccpb.FeatureStatus{}
+	err := c.invokeGRPC(withBreakerFeature(context.Background(), productFeatureID), "ProductStatus", lccpb.SDKService_GetProductStatus_FullMethodName, &lccpb.ProductStatusRequest{}, resp, nil)
+	switch code := status.Code(err); {
+	case err == nil:
+		return featureStatusFromProto(resp), nil
+	case code == codes.Unimplemented || code == codes.NotFound:
+		debugLogf("Product status RPC not available, falling back to %s feature check", productFeatureID)
+		c.productStatusUnsupported.Store(true)
+		return c.queryFeature(productFeatureID)
+	default:
+		return nil, fmt.Errorf("product status failed: %w", err)
+	}
+}
+
+func featureStatusFromProto(s *lccpb.FeatureStatus) *FeatureStatus {
+	status := &FeatureStatus{
+		Enabled:        s.GetEnabled(),
+		Reason:         s.GetReason(),
+		MaxCapacity:    int(s.GetMaxCapacity()),
+		MaxTPS:         s.GetMaxTps(),
+		MaxConcurrency: int(s.GetMaxConcurrency()),
+	}
+	if q := s.GetQuota(); q != nil {
+		status.Quota = &QuotaInfo{
+			Limit:     int(q.GetLimit()),
+			Used:      int(q.GetUsed()),
+			Remaining: int(q.GetRemaining()),
+			ResetAt:   q.GetResetAt(),
+		}
+	}
+	return status
+}
+
+// reportUsageGRPC reports usage over gRPC. eventID, if set, is sent as the
+// idempotency key; ALREADY_EXISTS means the server counted it before.
+// UsageReport has no dimension field, so a quota dimension travels in the
+// quota-dimension metadata.
+func (c *Client) reportUsageGRPC(featureID, dimension string, count int, timestamp time.Time, eventID string) error {
+	req := &lccpb.UsageReport{
+		InstanceId: c.instanceID,
+		FeatureId:  featureID,
+		Count:      int64(count),
+		Timestamp:  timestamp.Unix(),
+		EventId:    eventID,
+	}
+	var md metadata.MD
+	if eventID != "" {
+		md = metadata.Pairs("idempotency-key", eventID)
+	}
+	if dimension != "" {
+		md = metadata.Join(md, metadata.Pairs("quota-dimension", dimension))
+	}
+	err := c.invokeGRPC(context.Background(), "ReportUsage", lccpb.SDKService_ReportUsage_FullMethodName, req, &lccpb.UsageAck{}, md)
+	if err == nil || status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return fmt.Errorf("usage report failed: %w", err)
+	}
+	return fmt.Errorf("request failed: %w", err)
+}
+
+// deregisterGRPC is the gRPC deregistration
+func (c *Client) deregisterGRPC(ctx context.Context, heldSlots int) error {
+	req := &lccpb.DeregisterRequest{InstanceId: c.instanceID, HeldSlots: int64(heldSlots)}
+	return c.invokeGRPC(ctx, "Deregister", lccpb.SDKService_Deregister_FullMethodName, req, &lccpb.DeregisterResponse{}, nil)
+}
+
+// grpcPermanent reports whether a usage delivery error will not succeed on
+// redelivery: the server answered with a non-transient status. Errors that
+// never reached the server (open breaker, closed client) are transient.
+func grpcPermanent(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	code := s.Code()
+	return !grpcRetryable(code) && !grpcServerFailure(code) && code != codes.Aborted && code != codes.Canceled
+}
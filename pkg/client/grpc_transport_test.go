@@ -0,0 +1,251 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/lccpb"
+)
+
+type fakeSDKServer struct {
+	lccpb.UnimplementedSDKServiceServer
+
+	mu           sync.Mutex
+	registered   *lccpb.RegisterRequest
+	heartbeats   int
+	usage        []*lccpb.UsageReport
+	deregistered []*lccpb.DeregisterRequest
+	seenEvents   map[string]bool
+	unavailable  int // CheckFeature calls to fail before answering
+	featureCalls int
+}
+
+func (s *fakeSDKServer) Register(_ context.Context, req *lccpb.RegisterRequest) (*lccpb.RegisterResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registered = req
+	return &lccpb.RegisterResponse{}, nil
+}
+
+func (s *fakeSDKServer) Heartbeat(context.Context, *lccpb.HeartbeatRequest) (*lccpb.HeartbeatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats++
+	return &lccpb.HeartbeatResponse{TpsShare: &lccpb.TPSShare{MaxTps: 25, Instances: 4}}, nil
+}
+
+func (s *fakeSDKServer) CheckFeature(_ context.Context, req *lccpb.CheckFeatureRequest) (*lccpb.FeatureStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.featureCalls++
+	if s.unavailable > 0 {
+		s.unavailable--
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	switch req.GetFeatureId() {
+	case "export":
+		return &lccpb.FeatureStatus{FeatureId: "export", Enabled: true, Quota: &lccpb.QuotaInfo{Limit: 100, Used: 40, Remaining: 60}}, nil
+	case productFeatureID:
+		return &lccpb.FeatureStatus{Enabled: true, MaxCapacity: 50}, nil
+	}
+	return &lccpb.FeatureStatus{FeatureId: req.GetFeatureId(), Reason: ReasonNotInLicense}, nil
+}
+
+func (s *fakeSDKServer) ReportUsage(_ context.Context, req *lccpb.UsageReport) (*lccpb.UsageAck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id := req.GetEventId(); id != "" {
+		if s.seenEvents[id] {
+			return nil, status.Error(codes.AlreadyExists, "duplicate event")
+		}
+		s.seenEvents[id] = true
+	}
+	s.usage = append(s.usage, req)
+	return &lccpb.UsageAck{}, nil
+}
+
+func (s *fakeSDKServer) Deregister(_ context.Context, req *lccpb.DeregisterRequest) (*lccpb.DeregisterResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deregistered = append(s.deregistered, req)
+	return &lccpb.DeregisterResponse{}, nil
+}
+
+// verifySignature checks the x-lcc-* metadata the way the LCC server does
+func verifySignature(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+	hreq, _ := http.NewRequest("POST", info.FullMethod, bytes.NewReader(body))
+	for k, v := range md {
+		for _, vv := range v {
+			hreq.Header.Add(k, vv)
+		}
+	}
+	if err := auth.VerifyRequest(hreq); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return handler(ctx, req)
+}
+
+func startGRPCServer(t *testing.T) (*fakeSDKServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	fake := &fakeSDKServer{seenEvents: make(map[string]bool)}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(verifySignature))
+	lccpb.RegisterSDKServiceServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return fake, "http://" + lis.Addr().String()
+}
+
+func newGRPCTestClient(t *testing.T, target string) *Client {
+	t.Helper()
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:          target,
+		Protocol:        config.ProtocolGRPC,
+		ProductID:       "test-app",
+		ProductVersion:  "1.0.0",
+		Timeout:         5 * time.Second,
+		CacheTTL:        time.Minute,
+		TPSPartitioning: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_GRPC(t *testing.T) {
+	fake, target := startGRPCServer(t)
+	c := newGRPCTestClient(t, target)
+
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if fake.registered.GetProductId() != "test-app" || fake.registered.GetMetadata().GetFields()["hostname"] == nil {
+		t.Errorf("registration = %v", fake.registered)
+	}
+
+	status, err := c.CheckFeature("export")
+	if err != nil || !status.Enabled || status.Quota == nil || status.Quota.Remaining != 60 {
+		t.Fatalf("CheckFeature(export) = %+v, %v", status, err)
+	}
+	if status, _ := c.CheckFeature("reports"); status.Enabled || status.Reason != ReasonNotInLicense {
+		t.Errorf("CheckFeature(reports) = %+v, want denied", status)
+	}
+
+	// GetProductStatus is unimplemented: fall back to the __product__ feature
+	if allowed, max, err := c.CheckCapacity(10); !allowed || max != 50 || err != nil {
+		t.Errorf("CheckCapacity() = %v, %d, %v", allowed, max, err)
+	}
+	if !c.productStatusUnsupported.Load() {
+		t.Error("product status should be marked unsupported")
+	}
+
+	if err := c.ReportUsage("export", 3); err != nil {
+		t.Fatalf("ReportUsage() error = %v", err)
+	}
+	if len(fake.usage) != 1 || fake.usage[0].GetCount() != 3 || fake.usage[0].GetInstanceId() != c.GetInstanceID() {
+		t.Errorf("usage = %v", fake.usage)
+	}
+
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	if share, ok := c.TPSShare(); !ok || share.MaxTPS != 25 || share.Instances != 4 {
+		t.Errorf("TPSShare() = %+v, %v", share, ok)
+	}
+
+	if err := c.Deregister(context.Background()); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	if len(fake.deregistered) != 1 || fake.deregistered[0].GetInstanceId() != c.GetInstanceID() {
+		t.Errorf("deregistrations = %v", fake.deregistered)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() after Deregister error = %v", err)
+	}
+
+	// Suspend deregisters like Close
+	if err := c.Suspend(); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if len(fake.deregistered) != 2 {
+		t.Errorf("deregistrations after Suspend = %d, want 2", len(fake.deregistered))
+	}
+	if err := c.Reopen(); err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Errorf("CheckFeature() after Reopen error = %v", err)
+	}
+}
+
+func TestClient_GRPCRetry(t *testing.T) {
+	fake, target := startGRPCServer(t)
+	c := newGRPCTestClient(t, target)
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	fake.unavailable = 2
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+	if fake.featureCalls != 3 {
+		t.Errorf("feature calls = %d, want 3", fake.featureCalls)
+	}
+
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 0})
+	fake.unavailable = 1
+	c.cache.clear()
+	if _, err := c.queryFeature("export"); status.Code(err) != codes.Unavailable {
+		t.Errorf("queryFeature() error = %v, want Unavailable", err)
+	}
+}
+
+func TestClient_GRPCUsageLedger(t *testing.T) {
+	fake, target := startGRPCServer(t)
+	c := newGRPCTestClient(t, target)
+	if err := c.EnableUsageLedger(t.TempDir() + "/usage.jsonl"); err != nil {
+		t.Fatalf("EnableUsageLedger() error = %v", err)
+	}
+
+	if err := c.ReportUsage("export", 2); err != nil {
+		t.Fatalf("ReportUsage() error = %v", err)
+	}
+	events := c.UsageEvents(UsageAcked)
+	if len(events) != 1 || len(fake.usage) != 1 || fake.usage[0].GetEventId() != events[0].ID {
+		t.Fatalf("acked events = %+v, server usage = %v", events, fake.usage)
+	}
+
+	// Redelivery of a counted event is answered ALREADY_EXISTS and acked
+	if err := c.reportUsageGRPC("export", "", 2, time.Now(), events[0].ID); err != nil {
+		t.Errorf("duplicate delivery error = %v", err)
+	}
+	if len(fake.usage) != 1 {
+		t.Errorf("server usage = %d reports, want 1", len(fake.usage))
+	}
+
+	if !grpcPermanent(status.Error(codes.InvalidArgument, "bad")) || grpcPermanent(status.Error(codes.Unavailable, "down")) || grpcPermanent(ErrCircuitOpen) {
+		t.Error("grpcPermanent() misclassifies errors")
+	}
+}
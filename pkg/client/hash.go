@@ -0,0 +1,82 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// defaultHashAlgorithms are the hashes a client negotiates without
+// SetHashAlgorithms: SHA-256 while servers accept it, then the stronger ones
+var defaultHashAlgorithms = []string{auth.HashSHA256, auth.HashSHA384, auth.HashSHA512}
+
+// SetHashAlgorithms sets the hashes the client may use for body hashes and
+// request signatures, preferred first (see SDKConfig.HashAlgorithms).
+// Requests are signed with the first until a server's X-LCC-Accept-Hash
+// header selects another; a request rejected with 401 after the choice
+// changed is re-signed and sent once more. No algorithms restores the
+// default: SHA-256, or a stronger hash once a server no longer accepts it.
+func (c *Client) SetHashAlgorithms(algorithms ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, a := range algorithms {
+		if _, err := auth.HashFunc(a); err != nil || a == "" {
+			return fmt.Errorf("invalid hash algorithm %q", a)
+		}
+		if _, ok := c.keyPair.(auth.DigestSigner); !ok && a != auth.HashSHA256 {
+			return fmt.Errorf("hash algorithm %s requires a key pair implementing auth.DigestSigner, have %T", a, c.keyPair)
+		}
+	}
+	c.hashAlgorithms = append([]string(nil), algorithms...)
+	c.hashAlgorithm = ""
+	if len(algorithms) > 0 {
+		c.hashAlgorithm = algorithms[0]
+	}
+	if c.keyPair != nil {
+		c.signer.Store(c.newSignerLocked(c.keyPair))
+	}
+	return nil
+}
+
+// HashAlgorithm returns the hash algorithm requests are currently signed
+// with
+func (c *Client) HashAlgorithm() string {
+	return c.signer.Load().HashAlgorithm()
+}
+
+// negotiateHash switches to the preferred hash algorithm the server lists
+// in resp's X-LCC-Accept-Hash header and reports whether it changed
+func (c *Client) negotiateHash(resp *http.Response) bool {
+	accepted := resp.Header.Get(auth.HeaderAcceptHash)
+	if accepted == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	preferred := c.hashAlgorithms
+	if len(preferred) == 0 {
+		preferred = defaultHashAlgorithms
+		if _, ok := c.keyPair.(auth.DigestSigner); !ok {
+			preferred = preferred[:1]
+		}
+	}
+	current := c.hashAlgorithm
+	if current == "" {
+		current = auth.HashSHA256
+	}
+	next := auth.NegotiateHash(preferred, accepted)
+	if next == "" {
+		debugLogf("WARNING: server accepts hash algorithms %q, none of %v", accepted, preferred)
+		return false
+	}
+	if next == current {
+		return false
+	}
+	debugLogf("Hash: server accepts %q, signing with %s", accepted, next)
+	c.hashAlgorithm = next
+	if c.keyPair != nil {
+		c.signer.Store(c.newSignerLocked(c.keyPair))
+	}
+	return true
+}
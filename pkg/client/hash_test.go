@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// hashServer verifies requests accepting only the given hash algorithms,
+// which it lists in X-LCC-Accept-Hash
+func hashServer(t *testing.T, calls *atomic.Int32, algorithms ...string) *httptest.Server {
+	t.Helper()
+	opts := auth.VerifyOptions{HashAlgorithms: algorithms}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		auth.SetAcceptHash(w.Header(), opts)
+		if err := auth.VerifyRequestWithOptions(r, opts); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "export", "enabled": true})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_HashNegotiation(t *testing.T) {
+	var calls atomic.Int32
+	srv := hashServer(t, &calls, auth.HashSHA384)
+
+	c := newTestClient(t, srv.URL)
+	if got := c.HashAlgorithm(); got != auth.HashSHA256 {
+		t.Fatalf("HashAlgorithm() = %q, want %q", got, auth.HashSHA256)
+	}
+	status, err := c.CheckFeature("export")
+	if err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server calls = %d, want 2 (rejected, then re-signed)", n)
+	}
+	if got := c.HashAlgorithm(); got != auth.HashSHA384 {
+		t.Errorf("HashAlgorithm() = %q, want %q", got, auth.HashSHA384)
+	}
+
+	// The negotiated hash signs valid requests from the start
+	c.ClearCache()
+	calls.Store(0)
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server calls = %d, want 1", n)
+	}
+}
+
+func TestClient_SetHashAlgorithms(t *testing.T) {
+	var calls atomic.Int32
+	srv := hashServer(t, &calls, auth.HashSHA512, auth.HashSHA256)
+
+	c := newTestClient(t, srv.URL)
+	if err := c.SetHashAlgorithms("md5"); err == nil {
+		t.Error("SetHashAlgorithms() accepted md5")
+	}
+	if err := c.SetHashAlgorithms(auth.HashSHA384, auth.HashSHA256); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.HashAlgorithm(); got != auth.HashSHA384 {
+		t.Fatalf("HashAlgorithm() = %q, want %q", got, auth.HashSHA384)
+	}
+
+	// SHA-512 is not allowed: the server's other choice is taken
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if got := c.HashAlgorithm(); got != auth.HashSHA256 {
+		t.Errorf("HashAlgorithm() = %q, want %q", got, auth.HashSHA256)
+	}
+}
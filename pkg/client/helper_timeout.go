@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Helper names accepted by SetHelperTimeout and reported to OnSlowHelper
+const (
+	HelperQuotaConsumer   = "QuotaConsumer"
+	HelperTPSProvider     = "TPSProvider"
+	HelperCapacityCounter = "CapacityCounter"
+)
+
+// HelperFallback selects what a check uses when a helper times out
+type HelperFallback string
+
+// Helper fallbacks
+const (
+	// HelperFallbackCached uses the helper's last successful result, failing
+	// closed if it has never returned. QuotaConsumer results depend on the
+	// check's arguments and are never reused, so it always fails closed.
+	// This is the default.
+	HelperFallbackCached HelperFallback = "cached"
+	// HelperFallbackFailOpen uses a neutral value: zero capacity in use,
+	// zero TPS, or the default of 1 quota unit
+	HelperFallbackFailOpen HelperFallback = "fail_open"
+	// HelperFallbackFailClosed denies the check with a *HelperTimeoutError
+	HelperFallbackFailClosed HelperFallback = "fail_closed"
+)
+
+// HelperTimeout bounds a single helper invocation
+type HelperTimeout struct {
+	// Timeout is the longest a check waits for the helper (0 waits forever).
+	// A timed-out helper keeps running in the background. Until it returns,
+	// further CapacityCounter and TPSProvider checks use the fallback without
+	// invoking it again; QuotaConsumer is invoked by every check.
+	Timeout time.Duration
+
+	// Fallback applies when the helper times out
+	Fallback HelperFallback
+
+	// SlowThreshold reports invocations slower than this to OnSlowHelper
+	// handlers (0 uses Timeout)
+	SlowThreshold time.Duration
+}
+
+// ErrHelperTimeout is matched (via errors.Is) by the *HelperTimeoutError
+// returned when a helper times out and no fallback value is available
+var ErrHelperTimeout = errors.New("helper timed out")
+
+// HelperTimeoutError reports a helper that did not return in time
+type HelperTimeoutError struct {
+	Helper  string
+	Timeout time.Duration
+}
+
+func (e *HelperTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s after %v", e.Helper, ErrHelperTimeout, e.Timeout)
+}
+
+// Is allows errors.Is(err, ErrHelperTimeout) to match
+func (e *HelperTimeoutError) Is(target error) bool {
+	return target == ErrHelperTimeout
+}
+
+// helperGuard holds per-helper timeouts and invocation state
+type helperGuard struct {
+	mu       sync.Mutex
+	timeouts map[string]HelperTimeout
+	stuck    map[string]int // timed-out invocations still running
+	last     map[string]any
+	hooks    *hookDispatcher
+	onSlow   []namedHook[func(helper string, elapsed time.Duration)]
+}
+
+func newHelperGuard(hooks *hookDispatcher) *helperGuard {
+	return &helperGuard{
+		hooks:    hooks,
+		timeouts: make(map[string]HelperTimeout),
+		stuck:    make(map[string]int),
+		last:     make(map[string]any),
+	}
+}
+
+// SetHelperTimeout bounds invocations of the named helper (HelperQuotaConsumer,
+// HelperTPSProvider or HelperCapacityCounter). A zero HelperTimeout removes
+// the limit.
+//
+// Example:
+//
+//	client.SetHelperTimeout(client.HelperCapacityCounter, client.HelperTimeout{
+//	    Timeout:       500 * time.Millisecond,
+//	    Fallback:      client.HelperFallbackCached,
+//	    SlowThreshold: 200 * time.Millisecond,
+//	})
+func (c *Client) SetHelperTimeout(helper string, t HelperTimeout) {
+	c.helperGuard.mu.Lock()
+	defer c.helperGuard.mu.Unlock()
+
+	if t == (HelperTimeout{}) {
+		delete(c.helperGuard.timeouts, helper)
+		return
+	}
+	c.helperGuard.timeouts[helper] = t
+}
+
+// OnSlowHelper registers a callback fired when a helper invocation exceeds
+// its slow threshold. It runs on the hook worker pool (see SetHookPolicy).
+func (c *Client) OnSlowHelper(fn func(helper string, elapsed time.Duration)) {
+	if fn == nil {
+		return
+	}
+	h := namedHook[func(helper string, elapsed time.Duration)]{name: c.hooks.name("OnSlowHelper"), fn: fn}
+	c.helperGuard.mu.Lock()
+	defer c.helperGuard.mu.Unlock()
+	c.helperGuard.onSlow = append(c.helperGuard.onSlow, h)
+}
+
+// helperCall is the state of one timed helper invocation, guarded by
+// helperGuard.mu
+type helperCall struct {
+	finished bool
+	timedOut bool
+}
+
+// argumentHelper reports whether the named helper's result depends on the
+// check's arguments, so it is neither reused nor skipped
+func argumentHelper(name string) bool {
+	return name == HelperQuotaConsumer
+}
+
+// callHelper invokes fn under the named helper's timeout. open is the value
+// used by HelperFallbackFailOpen. Concurrent calls invoke fn concurrently,
+// each falling back only when its own timeout expires; while a timed-out
+// CapacityCounter or TPSProvider invocation is still running, calls use the
+// fallback at once so a hung helper does not pile up goroutines.
+func callHelper[T any](c *Client, ctx context.Context, name string, open T, fn func(context.Context) T) (T, error) {
+	g := c.helperGuard
+
+	g.mu.Lock()
+	cfg := g.timeouts[name]
+	stuck := g.stuck[name] > 0 && !argumentHelper(name)
+	g.mu.Unlock()
+
+	if cfg.Timeout <= 0 {
+		start := time.Now()
+		v := fn(ctx)
+		g.finish(name, v, time.Since(start), cfg, nil)
+		return v, nil
+	}
+	if stuck {
+		debugLogf("%s: timed-out invocation still running, using fallback", name)
+		return fallbackValue(g, name, cfg, open)
+	}
+
+	call := &helperCall{}
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	done := make(chan T, 1)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		v := fn(ctx)
+		g.finish(name, v, time.Since(start), cfg, call)
+		done <- v
+	}()
+
+	timer := time.NewTimer(cfg.Timeout)
+	defer timer.Stop()
+
+	select {
+	case v := <-done:
+		return v, nil
+	case <-timer.C:
+	}
+
+	g.mu.Lock()
+	if call.finished {
+		// Returned as the timer fired
+		g.mu.Unlock()
+		return <-done, nil
+	}
+	call.timedOut = true
+	g.stuck[name]++
+	g.mu.Unlock()
+
+	debugLogf("%s: timed out after %v, using %s fallback", name, cfg.Timeout, cfg.Fallback)
+	return fallbackValue(g, name, cfg, open)
+}
+
+// finish records a completed invocation and reports it if slow. call is
+// nil for untimed invocations.
+func (g *helperGuard) finish(name string, v any, elapsed time.Duration, cfg HelperTimeout, call *helperCall) {
+	threshold := cfg.SlowThreshold
+	if threshold <= 0 {
+		threshold = cfg.Timeout
+	}
+
+	g.mu.Lock()
+	if !argumentHelper(name) {
+		g.last[name] = v
+	}
+	if call != nil {
+		call.finished = true
+		if call.timedOut {
+			if g.stuck[name] <= 1 {
+				delete(g.stuck, name)
+			} else {
+				g.stuck[name]--
+			}
+		}
+	}
+	handlers := g.onSlow
+	g.mu.Unlock()
+
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	debugLogf("WARNING: %s helper took %v (threshold %v)", name, elapsed, threshold)
+	for _, h := range handlers {
+		fn := h.fn
+		g.hooks.dispatch(h.name, func() { fn(name, elapsed) })
+	}
+}
+
+// fallbackValue applies cfg.Fallback for a timed-out helper
+func fallbackValue[T any](g *helperGuard, name string, cfg HelperTimeout, open T) (T, error) {
+	var zero T
+	timeoutErr := &HelperTimeoutError{Helper: name, Timeout: cfg.Timeout}
+
+	switch cfg.Fallback {
+	case HelperFallbackFailOpen:
+		return open, nil
+	case HelperFallbackFailClosed:
+		return zero, timeoutErr
+	default:
+		g.mu.Lock()
+		last, ok := g.last[name].(T)
+		g.mu.Unlock()
+		if !ok {
+			return zero, timeoutErr
+		}
+		return last, nil
+	}
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_HelperTimeoutFallbacks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{"max_capacity": 10}})
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		fallback    HelperFallback
+		warm        bool // a fast call succeeds before the slow one
+		wantAllowed bool
+		wantTimeout bool
+	}{
+		{"cached with previous value", HelperFallbackCached, true, false, false},
+		{"cached without previous value", HelperFallbackCached, false, false, true},
+		{"fail open", HelperFallbackFailOpen, false, true, false},
+		{"fail closed", HelperFallbackFailClosed, true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, srv.URL)
+			release := make(chan struct{})
+			defer close(release)
+
+			var slow atomic.Bool
+			if err := c.RegisterHelpers(&HelperFunctions{
+				CapacityCounter: func() int {
+					if slow.Load() {
+						<-release
+					}
+					return 10 // at the limit
+				},
+			}); err != nil {
+				t.Fatalf("RegisterHelpers() error = %v", err)
+			}
+			c.SetHelperTimeout(HelperCapacityCounter, HelperTimeout{Timeout: 20 * time.Millisecond, Fallback: tt.fallback})
+
+			if tt.warm {
+				if allowed, _, _ := c.CheckCapacityWithHelper(); allowed {
+					t.Fatal("CheckCapacityWithHelper() at the limit should be denied")
+				}
+			}
+
+			slow.Store(true)
+			allowed, _, err := c.CheckCapacityWithHelper()
+			if allowed != tt.wantAllowed {
+				t.Errorf("CheckCapacityWithHelper() allowed = %v, want %v (err = %v)", allowed, tt.wantAllowed, err)
+			}
+			if got := errors.Is(err, ErrHelperTimeout); got != tt.wantTimeout {
+				t.Errorf("CheckCapacityWithHelper() error = %v, want helper timeout %v", err, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestClient_HelperStillRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{"max_tps": 100}})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	if err := c.RegisterHelpers(&HelperFunctions{
+		TPSProvider: func() float64 {
+			calls.Add(1)
+			<-release
+			return 1
+		},
+		CapacityCounter: func() int { return 0 },
+	}); err != nil {
+		t.Fatalf("RegisterHelpers() error = %v", err)
+	}
+	c.SetHelperTimeout(HelperTPSProvider, HelperTimeout{Timeout: 10 * time.Millisecond, Fallback: HelperFallbackFailOpen})
+
+	slowCalls := make(chan time.Duration, 1)
+	c.OnSlowHelper(func(helper string, elapsed time.Duration) {
+		if helper == HelperTPSProvider {
+			slowCalls <- elapsed
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		if ok, _, err := c.CheckTPS(); !ok || err != nil {
+			t.Fatalf("CheckTPS() = %v, %v; want fail-open", ok, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("TPSProvider invoked %d times while hung, want 1", n)
+	}
+
+	close(release)
+	select {
+	case elapsed := <-slowCalls:
+		if elapsed < 10*time.Millisecond {
+			t.Errorf("slow helper elapsed = %v", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnSlowHelper was not called")
+	}
+}
+
+func TestClient_HelperConcurrentCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{"max_capacity": 10}})
+	}))
+	defer srv.Close()
+
+	const n = 5
+	c := newTestClient(t, srv.URL)
+
+	// Every invocation waits until all n are running, so a call that
+	// fell back instead of invoking the helper would hang the others
+	var consumers, counters sync.WaitGroup
+	consumers.Add(n)
+	counters.Add(n)
+	if err := c.RegisterHelpers(&HelperFunctions{
+		QuotaConsumer: func(ctx context.Context, args ...interface{}) int {
+			consumers.Done()
+			consumers.Wait()
+			return args[0].(int)
+		},
+		CapacityCounter: func() int {
+			counters.Done()
+			counters.Wait()
+			return 3
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, helper := range []string{HelperQuotaConsumer, HelperCapacityCounter} {
+		c.SetHelperTimeout(helper, HelperTimeout{Timeout: 5 * time.Second, Fallback: HelperFallbackFailOpen})
+	}
+
+	var mu sync.Mutex
+	var amounts []int
+	consumed := make(chan struct{}, n)
+	c.OnConsume(func(ev ConsumeEvent) {
+		mu.Lock()
+		amounts = append(amounts, ev.Amount)
+		mu.Unlock()
+		consumed <- struct{}{}
+	})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= n; i++ {
+		wg.Add(2)
+		go func(amount int) {
+			defer wg.Done()
+			if _, _, err := c.ConsumeWithContext(context.Background(), amount*100); err != nil {
+				t.Errorf("ConsumeWithContext(%d) error = %v", amount*100, err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if allowed, _, err := c.CheckCapacityWithHelper(); !allowed || err != nil {
+				t.Errorf("CheckCapacityWithHelper() = %v, %v", allowed, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-consumed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("OnConsume callback not called")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Ints(amounts)
+	for i, amount := range amounts {
+		if want := (i + 1) * 100; amount != want {
+			t.Errorf("consumed amounts = %v, want each call charged its own amount", amounts)
+			break
+		}
+	}
+}
+
+func TestClient_HelperQuotaConsumerNotReused(t *testing.T) {
+	c := newTestClient(t, "http://localhost:1")
+	release := make(chan struct{})
+	defer close(release)
+
+	c.SetHelperTimeout(HelperQuotaConsumer, HelperTimeout{Timeout: 10 * time.Millisecond, Fallback: HelperFallbackCached})
+
+	ctx := context.Background()
+	if _, err := callHelper(c, ctx, HelperQuotaConsumer, 1, func(context.Context) int { return 7 }); err != nil {
+		t.Fatal(err)
+	}
+	hung := func(context.Context) int {
+		<-release
+		return 500
+	}
+	if v, err := callHelper(c, ctx, HelperQuotaConsumer, 1, hung); !errors.Is(err, ErrHelperTimeout) {
+		t.Errorf("timed-out QuotaConsumer = %d, %v; want a helper timeout, not another call's result", v, err)
+	}
+}
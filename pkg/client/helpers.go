@@ -49,6 +49,22 @@ type HelperFunctions struct {
 	//       return database.CountActiveUsers()
 	//   }
 	CapacityCounter func() int
+
+	// ArgsRedactor (Optional): Sanitize QuotaConsumer args for the audit trail
+	// When ConsumeWithContext is denied, the returned value is serialized to
+	// JSON, cut to 1 KiB, and kept in the denial's AuditRecord.Args, so
+	// support can see which batch size or tenant triggered it. Return only
+	// what is safe to upload.
+	// If not provided, args are not recorded.
+	//
+	// Example:
+	//   ArgsRedactor: func(args ...interface{}) interface{} {
+	//       return map[string]interface{}{"batch_size": args[0]}
+	//   }
+	ArgsRedactor func(args ...interface{}) interface{}
+
+	// internalTPS is set when TPSProvider is the client's internal tracker
+	internalTPS bool
 }
 
 // Validate validates the helper functions configuration
@@ -77,6 +93,7 @@ func (h *HelperFunctions) SetDefaults(client *Client) {
 		h.TPSProvider = func() float64 {
 			return client.getInternalTPS()
 		}
+		h.internalTPS = true
 	}
 }
 
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHookWorkers   = 4
+	defaultHookQueueSize = 256
+	defaultHookTimeout   = 5 * time.Second
+)
+
+// HookPolicy bounds how registered callbacks (OnFeatureStatusChange,
+// OnQuotaReset, OnSlowHelper) run. Callbacks never run on the SDK's own
+// goroutines: they are queued to a small worker pool, so a slow or
+// panicking callback cannot stall checks, timers or the heartbeat loop.
+type HookPolicy struct {
+	// Workers is the number of callbacks run concurrently (default 4).
+	// Invocations of one callback always run one at a time, in order.
+	Workers int
+
+	// QueueSize is the number of pending invocations kept; further ones are
+	// dropped and counted in HookStats.Dropped (default 256)
+	QueueSize int
+
+	// Timeout abandons an invocation that has not returned, freeing its
+	// worker (default 5s). The callback goroutine is left to finish.
+	Timeout time.Duration
+}
+
+func (p HookPolicy) withDefaults() HookPolicy {
+	if p.Workers <= 0 {
+		p.Workers = defaultHookWorkers
+	}
+	if p.QueueSize <= 0 {
+		p.QueueSize = defaultHookQueueSize
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = defaultHookTimeout
+	}
+	return p
+}
+
+// HookStats counts callback invocations by outcome
+type HookStats struct {
+	// Queued invocations are waiting for a worker
+	Queued int
+
+	Dispatched int64
+	Completed  int64
+	Dropped    int64 // queue full
+	Panicked   int64 // recovered
+	TimedOut   int64
+}
+
+// namedHook is a registered callback with the name it is dispatched under
+type namedHook[F any] struct {
+	name string
+	fn   F
+}
+
+type hookTask struct {
+	hook string
+	fn   func()
+}
+
+// hookDispatcher runs callback invocations on a bounded pool of workers,
+// started on demand and exiting when the queue is empty. Invocations of the
+// same hook are serialized so they are delivered in order.
+type hookDispatcher struct {
+	mu      sync.Mutex
+	idle    *sync.Cond
+	policy  HookPolicy
+	queue   []hookTask
+	busy    map[string]bool
+	running int
+	stats   HookStats
+	names   int
+}
+
+func newHookDispatcher() *hookDispatcher {
+	d := &hookDispatcher{
+		policy: HookPolicy{}.withDefaults(),
+		busy:   make(map[string]bool),
+	}
+	d.idle = sync.NewCond(&d.mu)
+	return d
+}
+
+// name returns a unique dispatch name for a callback registered with kind
+func (d *hookDispatcher) name(kind string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.names++
+	return fmt.Sprintf("%s#%d", kind, d.names)
+}
+
+// dispatch queues an invocation of hook, dropping it if the queue is full
+func (d *hookDispatcher) dispatch(hook string, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.queue) >= d.policy.QueueSize {
+		d.stats.Dropped++
+		debugLogf("WARNING: hook queue full, dropped %s invocation", hook)
+		return
+	}
+	d.queue = append(d.queue, hookTask{hook: hook, fn: fn})
+	d.stats.Dispatched++
+	if d.running < d.policy.Workers {
+		d.running++
+		go d.work()
+	}
+}
+
+func (d *hookDispatcher) work() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		i := d.nextLocked()
+		if i < 0 {
+			d.running--
+			d.idle.Broadcast()
+			return
+		}
+		task := d.queue[i]
+		d.queue = append(d.queue[:i], d.queue[i+1:]...)
+		d.busy[task.hook] = true
+		timeout := d.policy.Timeout
+		d.mu.Unlock()
+
+		panicked, timedOut := runHook(task, timeout)
+
+		d.mu.Lock()
+		delete(d.busy, task.hook)
+		switch {
+		case panicked:
+			d.stats.Panicked++
+		case timedOut:
+			d.stats.TimedOut++
+		default:
+			d.stats.Completed++
+		}
+		d.idle.Broadcast()
+	}
+}
+
+// nextLocked returns the index of the oldest task whose hook is not
+// running, or -1. Caller holds d.mu.
+func (d *hookDispatcher) nextLocked() int {
+	for i, task := range d.queue {
+		if !d.busy[task.hook] {
+			return i
+		}
+	}
+	return -1
+}
+
+// runHook runs task with panic recovery, waiting at most timeout
+func runHook(task hookTask, timeout time.Duration) (panicked, timedOut bool) {
+	done := make(chan bool, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				debugLogf("WARNING: hook %s panicked: %v\n%s", task.hook, r, debug.Stack())
+				done <- true
+			}
+		}()
+		task.fn()
+		done <- false
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case panicked = <-done:
+		return panicked, false
+	case <-timer.C:
+		debugLogf("WARNING: hook %s still running after %v, abandoning it", task.hook, timeout)
+		return false, true
+	}
+}
+
+// wait blocks until no invocations are queued or running
+func (d *hookDispatcher) wait() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for len(d.queue) > 0 || d.running > 0 {
+		d.idle.Wait()
+	}
+}
+
+// SetHookPolicy configures the worker pool that runs registered callbacks.
+// Zero fields use the defaults.
+func (c *Client) SetHookPolicy(p HookPolicy) {
+	p = p.withDefaults()
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.policy = p
+}
+
+// HookStats returns callback invocation counters, e.g. to export the number
+// of dropped or panicking callbacks as metrics
+func (c *Client) HookStats() HookStats {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	stats := c.hooks.stats
+	stats.Queued = len(c.hooks.queue)
+	return stats
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHookDispatcher_Isolation(t *testing.T) {
+	d := newHookDispatcher()
+	d.policy = HookPolicy{Workers: 4, QueueSize: 4, Timeout: 50 * time.Millisecond}
+
+	// Invocations of one hook stay ordered across workers
+	var mu sync.Mutex
+	var order []int
+	ordered := d.name("ordered")
+	for i := 0; i < 3; i++ {
+		i := i
+		d.dispatch(ordered, func() {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		})
+	}
+	d.wait()
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("invocations of one hook ran as %v, want in order", order)
+	}
+
+	// With the only worker busy, the queue fills and further calls drop
+	d.policy.Workers = 1
+	started, gate := make(chan struct{}), make(chan struct{})
+	d.dispatch(d.name("gate"), func() { close(started); <-gate })
+	<-started
+	d.dispatch(d.name("panics"), func() { panic("boom") })
+	for i := 0; i < 4; i++ {
+		d.dispatch(d.name("filler"), func() {})
+	}
+	close(gate)
+	d.wait()
+
+	// A hung hook is abandoned after the timeout
+	block := make(chan struct{})
+	defer close(block)
+	start := time.Now()
+	d.dispatch(d.name("hangs"), func() { <-block })
+	d.wait()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hung hook held a worker for %v, want the 50ms timeout", elapsed)
+	}
+
+	// Completed: 3 ordered, the gate and the 3 fillers that fit
+	want := HookStats{Dispatched: 9, Completed: 7, Dropped: 1, Panicked: 1, TimedOut: 1}
+	if d.stats != want {
+		t.Errorf("stats = %+v, want %+v", d.stats, want)
+	}
+}
+
+func TestClient_HookPanicDoesNotBreakChecks(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:0")
+	c.SetHookPolicy(HookPolicy{Timeout: time.Second})
+
+	reset := make(chan string, 1)
+	c.OnQuotaReset(func(string) { panic("callback bug") })
+	c.OnQuotaReset(func(featureID string) { reset <- featureID })
+
+	c.handleQuotaReset("export")
+	select {
+	case id := <-reset:
+		if id != "export" {
+			t.Errorf("OnQuotaReset featureID = %s", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("healthy callback not called after another one panicked")
+	}
+	c.hooks.wait()
+	if s := c.HookStats(); s.Panicked != 1 || s.Completed != 1 {
+		t.Errorf("HookStats() = %+v, want 1 panicked, 1 completed", s)
+	}
+}
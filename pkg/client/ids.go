@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// SetIDGenerator sets the source of request nonces and usage event IDs,
+// e.g. auth.NewUUIDGenerator over a certified DRBG, or
+// auth.NewSequentialGenerator for deterministic tests. nil restores random
+// UUIDs from crypto/rand. Call it before Register.
+func (c *Client) SetIDGenerator(gen auth.IDGenerator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids = gen
+	if c.keyPair != nil {
+		c.signer.Store(c.newSignerLocked(c.keyPair))
+	}
+}
+
+// newSignerLocked returns a request signer for kp with the client's signer
+// options, nonce source, clock and hash algorithm. Caller holds c.mu.
+func (c *Client) newSignerLocked(kp auth.KeyPair) *auth.RequestSigner {
+	opts := c.signerOpts
+	if c.ids != nil {
+		opts = append(opts[:len(opts):len(opts)], auth.WithNonceSource(c.ids))
+	}
+	if c.clock != nil {
+		opts = append(opts[:len(opts):len(opts)], auth.WithClock(c.clock))
+	}
+	if c.hashAlgorithm != "" {
+		opts = append(opts[:len(opts):len(opts)], auth.WithHashAlgorithm(c.hashAlgorithm))
+	}
+	return auth.NewRequestSigner(kp, opts...)
+}
+
+// newID returns a unique ID from the configured IDGenerator
+func (c *Client) newID() (string, error) {
+	c.mu.RLock()
+	gen := c.ids
+	c.mu.RUnlock()
+	if gen == nil {
+		return uuid.New().String(), nil
+	}
+	id, err := gen()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return id, nil
+}
+
+// SetInstanceIDFunc replaces how the instance ID is derived from the key
+// pair (default: its fingerprint; see also SDKConfig.InstanceIDSalt). It
+// must be called before the client is registered or used for checks.
+func (c *Client) SetInstanceIDFunc(fn auth.InstanceIDFunc) error {
+	if fn == nil {
+		fn = auth.FingerprintInstanceID
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != StateNew {
+		return &StateError{Op: "set instance ID function", State: c.state}
+	}
+	if c.keyPair == nil {
+		return fmt.Errorf("client identity was destroyed")
+	}
+	id, err := fn(c.keyPair)
+	if err != nil {
+		return fmt.Errorf("failed to derive instance ID: %w", err)
+	}
+	if id == "" {
+		return fmt.Errorf("failed to derive instance ID: empty ID")
+	}
+	c.instanceID = id
+	return nil
+}
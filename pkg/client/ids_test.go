@@ -0,0 +1,126 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_SetIDGenerator(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		nonces   []string
+		eventIDs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EventID string `json:"event_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		nonces = append(nonces, r.Header.Get("X-LCC-Nonce"))
+		if body.EventID != "" {
+			eventIDs = append(eventIDs, body.EventID)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetIDGenerator(auth.NewSequentialGenerator("id"))
+	if err := c.EnableUsageLedger(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := c.ReportUsage("export", 1); err != nil {
+		t.Fatalf("ReportUsage() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The usage event ID is drawn before its request is signed
+	if want := []string{"id-1", "id-3"}; !reflect.DeepEqual(nonces, want) {
+		t.Errorf("nonces = %v, want %v", nonces, want)
+	}
+	if want := []string{"id-2"}; !reflect.DeepEqual(eventIDs, want) {
+		t.Errorf("event IDs = %v, want %v", eventIDs, want)
+	}
+}
+
+func TestClient_IDGeneratorError(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	c.SetIDGenerator(func() (string, error) { return "", errors.New("no entropy") })
+	if err := c.EnableUsageLedger(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReportUsage("export", 1); err == nil {
+		t.Error("ReportUsage() succeeded without an event ID")
+	}
+}
+
+func TestClient_InstanceIDDerivation(t *testing.T) {
+	cfg := &config.SDKConfig{
+		LCCURL:         "http://127.0.0.1:1",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+	}
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if fp, _ := c.keyPair.GetFingerprint(); c.GetInstanceID() != fp {
+		t.Errorf("instance ID = %s, want the key fingerprint %s", c.GetInstanceID(), fp)
+	}
+
+	salted, _ := auth.SaltedInstanceID("customer-a")(c.keyPair)
+	if err := c.SetInstanceIDFunc(auth.SaltedInstanceID("customer-a")); err != nil {
+		t.Fatalf("SetInstanceIDFunc() error = %v", err)
+	}
+	if c.GetInstanceID() != salted {
+		t.Errorf("instance ID = %s, want %s", c.GetInstanceID(), salted)
+	}
+	if err := c.SetInstanceIDFunc(func(auth.KeyPair) (string, error) { return "", nil }); err == nil {
+		t.Error("SetInstanceIDFunc() accepted an empty ID")
+	}
+
+	cfg.InstanceIDSalt = "customer-b"
+	other, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer other.Close()
+	want, _ := auth.SaltedInstanceID("customer-b")(other.keyPair)
+	if other.GetInstanceID() != want {
+		t.Errorf("instance ID with instance_id_salt = %s, want %s", other.GetInstanceID(), want)
+	}
+}
+
+func TestClient_SetInstanceIDFuncAfterRegister(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	var se *StateError
+	if err := c.SetInstanceIDFunc(auth.SaltedInstanceID("late")); !errors.As(err, &se) {
+		t.Errorf("SetInstanceIDFunc() after Register error = %v, want a StateError", err)
+	}
+}
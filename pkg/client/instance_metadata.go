@@ -0,0 +1,104 @@
+package client
+
+import (
+	"os"
+	"strings"
+)
+
+// InstanceMetadata identifies an instance to the LCC console, which lists
+// and groups instances by host, region, Kubernetes namespace and label. It
+// is sent at registration and refreshed with each heartbeat.
+type InstanceMetadata struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+
+	// Region is SDKConfig.Region, or read from the cloud provider's
+	// environment variables (AWS_REGION, ...)
+	Region string `json:"region,omitempty"`
+
+	// Namespace and Pod are set when running in Kubernetes, from the
+	// POD_NAMESPACE and POD_NAME variables (downward API) or the service
+	// account namespace and the pod hostname
+	Namespace string `json:"k8s_namespace,omitempty"`
+	Pod       string `json:"k8s_pod,omitempty"`
+
+	// Labels are SDKConfig.Labels, or set with SetLabels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// regionEnv are the environment variables holding the region, in order
+// of preference
+var regionEnv = []string{"AWS_REGION", "AWS_DEFAULT_REGION", "GOOGLE_CLOUD_REGION", "AZURE_REGION"}
+
+// k8sNamespaceFile holds the namespace of the pod's service account
+var k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// InstanceMetadata returns the metadata this instance reports, read from
+// the host now
+func (c *Client) InstanceMetadata() InstanceMetadata {
+	c.mu.RLock()
+	md := InstanceMetadata{Region: c.region, Labels: copyLabels(c.labels)}
+	c.mu.RUnlock()
+
+	md.Hostname, _ = os.Hostname()
+	md.IP = getLocalIP()
+	for _, name := range regionEnv {
+		if md.Region != "" {
+			break
+		}
+		md.Region = os.Getenv(name)
+	}
+	md.Namespace = os.Getenv("POD_NAMESPACE")
+	md.Pod = os.Getenv("POD_NAME")
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if md.Namespace == "" {
+			if b, err := os.ReadFile(k8sNamespaceFile); err == nil {
+				md.Namespace = strings.TrimSpace(string(b))
+			}
+		}
+		if md.Pod == "" {
+			md.Pod = md.Hostname
+		}
+	}
+	return md
+}
+
+// SetLabels replaces the labels reported with the instance metadata. They
+// reach the server with the next heartbeat.
+func (c *Client) SetLabels(labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = copyLabels(labels)
+}
+
+// fields returns the metadata as registration and heartbeat metadata fields
+func (m InstanceMetadata) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"ip":       m.IP,
+		"hostname": m.Hostname,
+	}
+	if m.Region != "" {
+		fields["region"] = m.Region
+	}
+	if m.Namespace != "" {
+		fields["k8s_namespace"] = m.Namespace
+	}
+	if m.Pod != "" {
+		fields["k8s_pod"] = m.Pod
+	}
+	if len(m.Labels) > 0 {
+		fields["labels"] = m.Labels
+	}
+	return fields
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
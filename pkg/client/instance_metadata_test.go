@@ -0,0 +1,133 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_InstanceMetadata(t *testing.T) {
+	for _, name := range regionEnv {
+		t.Setenv(name, "")
+	}
+	t.Setenv("POD_NAME", "")
+	t.Setenv("POD_NAMESPACE", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	c := newTestClient(t, "http://localhost:7086")
+	hostname, _ := os.Hostname()
+	want := InstanceMetadata{Hostname: hostname, IP: getLocalIP()}
+	if got := c.InstanceMetadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InstanceMetadata() = %+v, want %+v", got, want)
+	}
+
+	// Region from the environment, namespace from the service account
+	t.Setenv("AWS_DEFAULT_REGION", "us-east-2")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	k8sNamespaceFile = filepath.Join(t.TempDir(), "namespace")
+	defer func() { k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace" }()
+	if err := os.WriteFile(k8sNamespaceFile, []byte("billing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want.Region, want.Namespace, want.Pod = "us-east-2", "billing", hostname
+	if got := c.InstanceMetadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InstanceMetadata() = %+v, want %+v", got, want)
+	}
+
+	// Downward API variables and configured region take precedence
+	t.Setenv("POD_NAME", "api-7d9f8-x2x4q")
+	t.Setenv("POD_NAMESPACE", "payments")
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         "http://localhost:7086",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Region:         "eu-west-1",
+		Labels:         map[string]string{"team": "payments"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	want = InstanceMetadata{
+		Hostname:  hostname,
+		IP:        getLocalIP(),
+		Region:    "eu-west-1",
+		Namespace: "payments",
+		Pod:       "api-7d9f8-x2x4q",
+		Labels:    map[string]string{"team": "payments"},
+	}
+	if got := c.InstanceMetadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InstanceMetadata() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_InstanceMetadataSent(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		registered map[string]interface{}
+		heartbeat  map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		switch r.URL.Path {
+		case "/api/v1/sdk/register":
+			registered = body.Metadata
+		case "/api/v1/sdk/heartbeat":
+			heartbeat = body.Metadata
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		Region:         "eu-west-1",
+		Labels:         map[string]string{"team": "payments"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	mu.Lock()
+	if registered["region"] != "eu-west-1" || !reflect.DeepEqual(registered["labels"], map[string]interface{}{"team": "payments"}) {
+		t.Errorf("registration metadata = %v", registered)
+	}
+	if registered["hostname"] == nil || registered["ip"] == nil {
+		t.Errorf("registration metadata lacks the host: %v", registered)
+	}
+	mu.Unlock()
+
+	// Label changes reach the server with the next heartbeat
+	c.SetLabels(map[string]string{"team": "billing", "canary": "true"})
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := map[string]interface{}{"team": "billing", "canary": "true"}; !reflect.DeepEqual(heartbeat["labels"], want) {
+		t.Errorf("heartbeat labels = %v, want %v", heartbeat["labels"], want)
+	}
+	if heartbeat["region"] != "eu-west-1" {
+		t.Errorf("heartbeat metadata = %v", heartbeat)
+	}
+}
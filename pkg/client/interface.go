@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// LCCClient is the runtime surface of Client that applications call to
+// gate features and enforce limits. Code that depends on LCCClient instead
+// of *Client can be unit tested with clienttest.MockClient; setup-time
+// configuration (SetX, UseX, OnX) stays on *Client.
+type LCCClient interface {
+	Register() error
+	Close() error
+	GetInstanceID() string
+	State() ConnectionState
+
+	CheckFeature(featureID string) (*FeatureStatus, error)
+	CheckFeatureCtx(ctx context.Context, featureID string) (*FeatureStatus, error)
+	CheckGroup(groupID string) (*GroupStatus, error)
+	ProductStatus() (*FeatureStatus, error)
+
+	Consume(amount int) (bool, int, error)
+	ConsumeDimension(featureID, dimension string, n int) (bool, int, error)
+	ConsumeWithContext(ctx context.Context, args ...interface{}) (bool, int, error)
+	CheckCapacity(currentUsed int) (bool, int, error)
+	CheckCapacityWithHelper() (bool, int, error)
+	CheckTPS() (bool, float64, error)
+	AcquireSlot() (ReleaseFunc, bool, error)
+	AcquireSlotContext(ctx context.Context) (ReleaseFunc, bool, error)
+	AcquireSlotWait(ctx context.Context, maxWait time.Duration) (ReleaseFunc, bool, error)
+	ReportUsage(featureID string, amount float64) error
+
+	RegisterHelpers(helpers *HelperFunctions) error
+	LimitError(limit string, err error) *LimitExceededError
+}
+
+var _ LCCClient = (*Client)(nil)
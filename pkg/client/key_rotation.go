@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// keyRotationProof is the prefix of the string the new key signs to prove
+// possession: "lcc-rotate-key\n<instance ID>\n<new key fingerprint>"
+const keyRotationProof = "lcc-rotate-key"
+
+// RotateKey replaces the client's key pair without downtime:
+//
+//  1. a new key pair of the same algorithm is generated
+//  2. its public key is registered with the server in a request signed by
+//     the old key, with a proof signed by the new one
+//  3. requests from then on are signed with the new key
+//  4. the new key is saved to the client's key store (see
+//     NewClientWithKeyStore, NewClientWithStore and SDKConfig.KeyFile)
+//
+// The instance ID is unchanged until the client restarts, when it becomes
+// the new key's fingerprint; the server links both to the same instance.
+// If the server rejects the rotation, the old key stays in use. If saving
+// the new key fails, the client keeps using it and the error is returned,
+// since the server may no longer accept the old one.
+//
+// RotateKey requires a registered, online client using the HTTP protocol,
+// whose key is not held by an external signer (auth.SignerKeyPair).
+func (c *Client) RotateKey(ctx context.Context) error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if state := c.Lifecycle(); state != StateRegistered {
+		return &StateError{Op: "rotate key", State: state}
+	}
+	c.mu.RLock()
+	oldKey, offline, activated, grpc := c.keyPair, c.offlineMode, c.activation != nil, c.grpc != nil
+	c.mu.RUnlock()
+	_, external := oldKey.(*auth.SignerKeyPair)
+	switch {
+	case oldKey == nil:
+		return fmt.Errorf("cannot rotate key: client identity was destroyed")
+	case external:
+		return fmt.Errorf("cannot rotate key: the key is held by an external signer")
+	case offline || activated:
+		return fmt.Errorf("cannot rotate key: offline instances are bound to their key")
+	case grpc:
+		return fmt.Errorf("cannot rotate key: not supported with protocol grpc")
+	}
+
+	newKey, err := auth.GenerateKeyPairForAlgorithm(oldKey.Algorithm())
+	if err != nil {
+		return fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	if err := c.registerKey(ctx, newKey); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.keyPair = newKey
+	c.signer.Store(c.newSignerLocked(newKey))
+	c.mu.Unlock()
+	// The old key is not destroyed: requests in flight may still be
+	// signing with it
+
+	fingerprint, _ := newKey.GetFingerprint()
+	debugLogf("RotateKey: instance %s now signs with key %s", c.instanceID, fingerprint)
+
+	if c.cacheStore != nil {
+		if keyPEM, err := newKey.ExportPrivateKeyPEM(); err == nil {
+			c.cacheStore.rekey(keyPEM)
+		}
+	}
+	if c.keyStore != nil {
+		if err := c.keyStore.Save(newKey); err != nil {
+			return fmt.Errorf("key rotated but not saved: %w", err)
+		}
+	}
+	if err := c.SaveCache(); err != nil {
+		debugLogf("RotateKey: failed to save cache: %v", err)
+	}
+	return nil
+}
+
+// registerKey registers newKey for this instance with a request signed by
+// the current key
+func (c *Client) registerKey(ctx context.Context, newKey auth.KeyPair) error {
+	pubPEM, err := newKey.GetPublicKeyPEM()
+	if err != nil {
+		return fmt.Errorf("failed to export public key: %w", err)
+	}
+	fingerprint, err := newKey.GetFingerprint()
+	if err != nil {
+		return fmt.Errorf("failed to get fingerprint: %w", err)
+	}
+	proof, err := newKey.Sign([]byte(keyRotationProof + "\n" + c.instanceID + "\n" + fingerprint))
+	if err != nil {
+		return fmt.Errorf("failed to sign rotation proof: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"instance_id": c.instanceID,
+		"public_key":  pubPEM,
+		"proof":       hex.EncodeToString(proof),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.baseURL + "/api/v1/sdk/rotate-key"
+	resp, err := c.doWithRetry(ctx, "RotateKey", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", url, body)
+	})
+	if err != nil {
+		return fmt.Errorf("key rotation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("key rotation failed: status=%d, body=%s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
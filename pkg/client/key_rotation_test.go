@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// rotationServer records the key each request was signed with and accepts
+// or rejects key rotations
+type rotationServer struct {
+	mu        sync.Mutex
+	reject    bool
+	signedBy  map[string]string // path -> fingerprint of the last signer
+	rotatedTo string
+}
+
+func (s *rotationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := auth.VerifyRequest(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	fp, _ := auth.NewVerifier().Fingerprint(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signedBy[r.URL.Path] = fp
+
+	if r.URL.Path == "/api/v1/sdk/rotate-key" {
+		if s.reject {
+			http.Error(w, "rotation disabled", http.StatusConflict)
+			return
+		}
+		var req struct {
+			InstanceID string `json:"instance_id"`
+			PublicKey  string `json:"public_key"`
+			Proof      string `json:"proof"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		newKey, err := auth.ParsePublicKeyPEM([]byte(req.PublicKey))
+		if err != nil || req.InstanceID != fp {
+			http.Error(w, "bad rotation request", http.StatusBadRequest)
+			return
+		}
+		block, _ := pem.Decode([]byte(req.PublicKey))
+		sum := sha256.Sum256(block.Bytes)
+		newFP := hex.EncodeToString(sum[:])
+		proof, _ := hex.DecodeString(req.Proof)
+		if err := auth.VerifySignature(newKey, []byte(keyRotationProof+"\n"+req.InstanceID+"\n"+newFP), proof); err != nil {
+			http.Error(w, "bad proof", http.StatusBadRequest)
+			return
+		}
+		s.rotatedTo = newFP
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"enabled":true}`))
+}
+
+func TestClient_RotateKey(t *testing.T) {
+	rs := &rotationServer{signedBy: make(map[string]string)}
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+	s := store.NewMemoryStore()
+	cfg := &config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		KeyAlgorithm:   config.KeyAlgorithmECDSAP256,
+	}
+
+	c, err := NewClientWithStore(cfg, s)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer c.Close()
+
+	if err := c.RotateKey(context.Background()); err == nil {
+		t.Error("RotateKey() before Register should fail")
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	oldID := c.GetInstanceID()
+
+	if err := c.RotateKey(context.Background()); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	if got := rs.signedBy["/api/v1/sdk/rotate-key"]; got != oldID {
+		t.Errorf("rotation signed by %s, want the old key %s", got, oldID)
+	}
+	if rs.rotatedTo == "" || rs.rotatedTo == oldID {
+		t.Fatalf("server rotated to %q, want a new key", rs.rotatedTo)
+	}
+	if c.GetInstanceID() != oldID {
+		t.Errorf("instance ID changed to %s before restart", c.GetInstanceID())
+	}
+	if alg := c.keyPair.Algorithm(); alg != auth.AlgorithmECDSAP256 {
+		t.Errorf("new key algorithm = %s, want %s", alg, auth.AlgorithmECDSAP256)
+	}
+
+	// Requests are signed with the new key
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if got := rs.signedBy["/api/v1/sdk/features/export/check"]; got != rs.rotatedTo {
+		t.Errorf("check signed by %s, want the new key %s", got, rs.rotatedTo)
+	}
+
+	// The new key was saved: a restarted client uses it
+	restarted, err := NewClientWithStore(cfg, s)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer restarted.Close()
+	if restarted.GetInstanceID() != rs.rotatedTo {
+		t.Errorf("restarted instance ID = %s, want the new key %s", restarted.GetInstanceID(), rs.rotatedTo)
+	}
+}
+
+func TestClient_RotateKeyRejected(t *testing.T) {
+	rs := &rotationServer{signedBy: make(map[string]string), reject: true}
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	oldID := c.GetInstanceID()
+
+	if err := c.RotateKey(context.Background()); err == nil {
+		t.Fatal("RotateKey() error = nil, want the server's rejection")
+	}
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if got := rs.signedBy["/api/v1/sdk/features/export/check"]; got != oldID {
+		t.Errorf("check signed by %s after a rejected rotation, want the old key %s", got, oldID)
+	}
+}
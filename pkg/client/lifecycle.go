@@ -0,0 +1,149 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// LifecycleState is the client's position in its lifecycle:
+// New → Registering → Registered → Closed, or Deactivated once its license
+// has been released with Deactivate.
+type LifecycleState int
+
+const (
+	// StateNew is a client that has not registered yet
+	StateNew LifecycleState = iota
+	// StateRegistering is a client with a registration request in flight
+	StateRegistering
+	// StateRegistered is a registered client with a running heartbeat loop
+	StateRegistered
+	// StateClosed is a client whose resources have been released
+	StateClosed
+	// StateDeactivated is a client whose license was released with
+	// Deactivate; only Reactivate brings it back
+	StateDeactivated
+)
+
+func (s LifecycleState) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateRegistering:
+		return "registering"
+	case StateRegistered:
+		return "registered"
+	case StateClosed:
+		return "closed"
+	case StateDeactivated:
+		return "deactivated"
+	default:
+		return fmt.Sprintf("LifecycleState(%d)", int(s))
+	}
+}
+
+// ErrClientClosed is matched (via errors.Is) by the *StateError returned
+// when an operation is attempted on a closed client.
+var ErrClientClosed = errors.New("client is closed")
+
+// ErrDeactivated is matched (via errors.Is) by the *StateError returned when
+// an operation is attempted on a deactivated client.
+var ErrDeactivated = errors.New("client is deactivated")
+
+// StateError reports an operation that is not valid in the client's current
+// lifecycle state.
+type StateError struct {
+	Op    string
+	State LifecycleState
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("cannot %s: client is %s", e.Op, e.State)
+}
+
+// Is allows errors.Is(err, ErrClientClosed) and errors.Is(err,
+// ErrDeactivated) to match closed- and deactivated-state errors
+func (e *StateError) Is(target error) bool {
+	return (target == ErrClientClosed && e.State == StateClosed) ||
+		(target == ErrDeactivated && e.State == StateDeactivated)
+}
+
+// Lifecycle returns the client's current lifecycle state
+func (c *Client) Lifecycle() LifecycleState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// setState updates the lifecycle state. Caller holds c.lifecycleMu.
+func (c *Client) setState(state LifecycleState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	debugLogf("Lifecycle: %s -> %s", c.state, state)
+	c.state = state
+}
+
+// checkOpen returns a *StateError if the client has been closed or
+// deactivated
+func (c *Client) checkOpen(op string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.state == StateClosed || c.state == StateDeactivated {
+		return &StateError{Op: op, State: c.state}
+	}
+	return nil
+}
+
+// Reopen resumes a client stopped with Suspend: it rebuilds transport
+// state, drops cached decisions and registers again with the same key
+// pair, so the instance ID is unchanged. Helpers, pipeline stages, policies
+// and callbacks registered before Suspend are kept.
+//
+// Reopen on a client that is not suspended behaves like Register. It fails
+// after Close, which wipes the key pair.
+func (c *Client) Reopen() error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if c.Lifecycle() != StateClosed {
+		return c.registerLocked()
+	}
+
+	if err := c.reopenTransport("reopen"); err != nil {
+		return err
+	}
+
+	c.cache.clear()
+	c.quotaResets.clear()
+	c.setState(StateNew)
+
+	debugLogf("Reopen: re-registering instance %s", c.instanceID)
+	return c.registerLocked()
+}
+
+// reopenTransport rebuilds the transport state released by shutdown. It
+// fails if the client was destroyed. Caller holds c.lifecycleMu.
+func (c *Client) reopenTransport(op string) error {
+	c.mu.Lock()
+	if c.keyPair == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("cannot %s: client identity was wiped by Close", op)
+	}
+	// Fresh http.Client sharing the configured transport; idle connections
+	// were already closed by Close.
+	c.baseHTTPClient = &http.Client{
+		Transport:     c.baseHTTPClient.Transport,
+		CheckRedirect: c.baseHTTPClient.CheckRedirect,
+		Jar:           c.baseHTTPClient.Jar,
+		Timeout:       c.baseHTTPClient.Timeout,
+	}
+	c.applyMiddlewareLocked()
+	c.tpsShare = nil
+	gt := c.grpc
+	c.mu.Unlock()
+
+	if gt != nil {
+		return gt.reopen()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_LifecycleIdempotent(t *testing.T) {
+	var registrations int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/register" {
+			atomic.AddInt32(&registrations, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if got := c.Lifecycle(); got != StateNew {
+		t.Fatalf("Lifecycle() = %s, want new", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Register(); err != nil {
+				t.Errorf("Register() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&registrations); n != 1 {
+		t.Errorf("registrations = %d, want 1", n)
+	}
+	if got := c.Lifecycle(); got != StateRegistered {
+		t.Errorf("Lifecycle() = %s, want registered", got)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second Close() error = %v, want nil", err)
+	}
+
+	err := c.Register()
+	if !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Register() after Close error = %v, want ErrClientClosed", err)
+	}
+	var stateErr *StateError
+	if !errors.As(err, &stateErr) || stateErr.State != StateClosed {
+		t.Errorf("Register() after Close error = %#v, want *StateError", err)
+	}
+
+	if _, err := c.CheckFeature("anything"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("CheckFeature() after Close error = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestClient_Reopen(t *testing.T) {
+	var registrations int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/register" {
+			atomic.AddInt32(&registrations, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	id := c.GetInstanceID()
+
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := c.Suspend(); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if got := c.Lifecycle(); got != StateClosed {
+		t.Errorf("Lifecycle() after Suspend = %s, want closed", got)
+	}
+
+	if err := c.Reopen(); err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+	if got := c.Lifecycle(); got != StateRegistered {
+		t.Errorf("Lifecycle() = %s, want registered", got)
+	}
+	if c.GetInstanceID() != id {
+		t.Error("instance ID changed across Reopen")
+	}
+	if n := atomic.LoadInt32(&registrations); n != 2 {
+		t.Errorf("registrations = %d, want 2", n)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if c.keyPair != nil {
+		t.Error("Close() kept the key pair")
+	}
+	if err := c.Reopen(); err == nil {
+		t.Error("Reopen() after Close should fail")
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// StageLimitExprs is the name of the pipeline stage resolving limit
+// expressions, present when SDKConfig.Limits has Expressions
+const StageLimitExprs = "limit_exprs"
+
+// limitBaseVar is the expression variable holding the granted limit
+const limitBaseVar = "base"
+
+// limitExprStage replaces product limits granted by the server or license
+// with the values of their expressions. It runs before the cache, so the
+// cache keeps the granted limits and label changes apply at once.
+type limitExprStage struct {
+	client *Client
+	exprs  map[string]*config.LimitExpr
+
+	// usesBase holds the limits whose expression refers to base
+	usesBase map[string]bool
+}
+
+// newLimitExprStage parses the expressions of limits
+func newLimitExprStage(c *Client, limits *config.ProductLimits) (*limitExprStage, error) {
+	s := &limitExprStage{client: c, exprs: make(map[string]*config.LimitExpr), usesBase: make(map[string]bool)}
+	for limit, src := range limits.Expressions {
+		expr, err := config.ParseLimitExpr(src)
+		if err != nil {
+			return nil, fmt.Errorf("limits.%s: invalid expression: %w", limit, err)
+		}
+		s.exprs[limit] = expr
+		for _, v := range expr.Vars() {
+			s.usesBase[limit] = s.usesBase[limit] || v == limitBaseVar
+		}
+	}
+	return s, nil
+}
+
+func (s *limitExprStage) Name() string { return StageLimitExprs }
+
+func (s *limitExprStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	status, err := next(ctx, req)
+	if err != nil || status == nil || req.FeatureID != productFeatureID {
+		return status, err
+	}
+	return s.resolve(status), nil
+}
+
+// resolve returns a copy of status with each limit set to its expression's
+// value. The variables are base, the granted limit, and every instance
+// label with a numeric value. An unlimited (0) grant stays unlimited for
+// expressions that use base; an expression that fails or is not positive
+// keeps the granted limit.
+func (s *limitExprStage) resolve(status *FeatureStatus) *FeatureStatus {
+	out := *status
+	vars := s.client.limitVars()
+	for limit, expr := range s.exprs {
+		var base float64
+		switch limit {
+		case config.LimitMaxTPS:
+			base = out.MaxTPS
+		case config.LimitMaxCapacity:
+			base = float64(out.MaxCapacity)
+		case config.LimitMaxConcurrency:
+			base = float64(out.MaxConcurrency)
+		}
+		if base == 0 && s.usesBase[limit] {
+			continue
+		}
+		vars[limitBaseVar] = base
+		v, err := expr.Eval(vars)
+		if err == nil && v <= 0 {
+			err = fmt.Errorf("%s = %v, not positive", expr, v)
+		}
+		if err != nil {
+			debugLogf("WARNING: limits.%s: keeping granted limit %v: %v", limit, base, err)
+			continue
+		}
+		switch limit {
+		case config.LimitMaxTPS:
+			out.MaxTPS = v
+		case config.LimitMaxCapacity:
+			out.MaxCapacity = int(math.Floor(v))
+		case config.LimitMaxConcurrency:
+			out.MaxConcurrency = int(math.Floor(v))
+		}
+	}
+	return &out
+}
+
+// limitVars returns the numeric instance labels as expression variables
+func (c *Client) limitVars() map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	vars := make(map[string]float64, len(c.labels)+1)
+	for k, v := range c.labels {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			vars[k] = f
+		}
+	}
+	return vars
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_LimitExpressions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/product/status" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled": true,
+				"limits":  map[string]interface{}{"max_tps": 10, "max_concurrency": 4},
+			})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Labels:         map[string]string{"instances": "3", "team": "payments"},
+		Limits: &config.ProductLimits{Expressions: map[string]string{
+			config.LimitMaxTPS:         "base * instances",
+			config.LimitMaxConcurrency: "min(base * instances, 8)",
+			config.LimitMaxCapacity:    "base * instances", // unlimited stays unlimited
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if got := c.Stages(); len(got) != 6 || got[3] != StageLimitExprs {
+		t.Errorf("Stages() = %v, want %s before the cache", got, StageLimitExprs)
+	}
+
+	status, err := c.ProductStatus()
+	if err != nil {
+		t.Fatalf("ProductStatus() error = %v", err)
+	}
+	if status.MaxTPS != 30 || status.MaxConcurrency != 8 || status.MaxCapacity != 0 {
+		t.Errorf("limits = %v TPS, %d concurrency, %d capacity; want 30, 8, 0", status.MaxTPS, status.MaxConcurrency, status.MaxCapacity)
+	}
+
+	// Label changes apply without a refetch; the cache keeps the grant
+	c.SetLabels(map[string]string{"instances": "1.5"})
+	status, _ = c.ProductStatus()
+	if status.MaxTPS != 15 || status.MaxConcurrency != 6 {
+		t.Errorf("after SetLabels: %v TPS, %d concurrency; want 15 and 6", status.MaxTPS, status.MaxConcurrency)
+	}
+
+	// Without the variable, the granted limits apply
+	c.SetLabels(nil)
+	status, _ = c.ProductStatus()
+	if status.MaxTPS != 10 || status.MaxConcurrency != 4 {
+		t.Errorf("without instances: %v TPS, %d concurrency; want the granted 10 and 4", status.MaxTPS, status.MaxConcurrency)
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// limiterRefresh is how often a Limiter re-reads the product TPS limit
+const limiterRefresh = time.Second
+
+// Limiter is a token bucket enforcing the license TPS limit on individual
+// requests. Its rate is the product MaxTPS (or this instance's share of it
+// with TPS partitioning) and its burst one second's worth of tokens, at
+// least 1. Limits are re-read at most once per second, so license changes
+// and rebalanced TPS shares take effect without recreating the limiter.
+// Without a TPS limit every request is allowed.
+//
+// Rate limits in longer windows (e.g. 50000 per hour, see
+// ProductLimits.RateLimits) are enforced on the requests the limiter admits.
+// A request that would exceed one is denied rather than delayed; Wait reports
+// the window in a *RateWindowError.
+//
+// Unlike CheckTPS, which compares a measured rate against the limit after
+// the fact, a Limiter admits or delays each request before it runs.
+type Limiter struct {
+	c *Client
+
+	mu          sync.Mutex
+	rate        float64 // tokens per second; 0 means unlimited
+	burst       float64
+	tokens      float64
+	last        time.Time // last token refill
+	refreshedAt time.Time
+	loaded      bool
+
+	// Rate limits in longer windows and the admitted requests in each
+	windows []rateWindow
+	counts  rateCounters
+}
+
+// Limiter returns the client's token-bucket limiter. All callers share one
+// bucket, so it bounds the total rate of the instance.
+//
+// Example:
+//
+//	if !client.Limiter().Allow() {
+//	    http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+//	    return
+//	}
+func (c *Client) Limiter() *Limiter {
+	c.limiterOnce.Do(func() {
+		c.limiter = &Limiter{c: c}
+	})
+	return c.limiter
+}
+
+// Allow reports whether a request may run now, taking a token if so
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if err := l.refreshLocked(now); err != nil {
+		return false
+	}
+	if l.counts.exceeded(l.windows, now, true) != nil {
+		return false
+	}
+	if l.rate > 0 {
+		if l.tokens < 1 {
+			return false
+		}
+		l.tokens--
+	}
+	l.counts.record(now, 1)
+	return true
+}
+
+// Wait blocks until a request may run or ctx is done. It fails immediately
+// with a *LimitExceededError (LimitTPS) if the wait would outlast the ctx
+// deadline, or if a longer rate limit window is exhausted.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if err := l.refreshLocked(now); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	if err := l.counts.exceeded(l.windows, now, true); err != nil {
+		l.mu.Unlock()
+		return l.c.LimitError(LimitTPS, err)
+	}
+	l.counts.record(now, 1)
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	// Reserve a token, possibly driving the bucket negative
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.tokens++
+		l.counts.record(now, -1)
+		l.mu.Unlock()
+		return l.c.LimitError(LimitTPS, fmt.Errorf("rate limit wait of %s exceeds context deadline", delay.Round(time.Millisecond)))
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.counts.record(now, -1)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Rate returns the enforced rate in requests per second and the burst;
+// 0 means unlimited
+func (l *Limiter) Rate() (rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.refreshLocked(time.Now())
+	return l.rate, int(l.burst)
+}
+
+// refreshLocked refills tokens and, once per limiterRefresh, re-reads the
+// TPS limit. A failed read keeps the previous limit; it is an error only if
+// no limit was ever read. Caller holds l.mu.
+func (l *Limiter) refreshLocked(now time.Time) error {
+	if !l.loaded || now.Sub(l.refreshedAt) >= limiterRefresh {
+		status, err := l.c.checkProductLimits()
+		switch {
+		case err == nil:
+			l.setRateLocked(l.c.effectiveMaxTPS(l.c.clusterLimits(status).MaxTPS), now)
+			l.windows = l.c.rateLimitsFor(status)
+			l.loaded = true
+			l.refreshedAt = now
+		case !l.loaded:
+			return err
+		default:
+			debugLogf("Limiter: keeping %.2f TPS, limit refresh failed: %v", l.rate, err)
+			l.refreshedAt = now
+		}
+	}
+
+	if l.rate > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	return nil
+}
+
+// setRateLocked applies a new rate. A limiter that was unlimited starts
+// with a full bucket. Caller holds l.mu.
+func (l *Limiter) setRateLocked(rate float64, now time.Time) {
+	if rate == l.rate {
+		return
+	}
+	debugLogf("Limiter: rate %.2f -> %.2f TPS", l.rate, rate)
+	wasUnlimited := l.rate <= 0
+	l.rate = rate
+	l.burst = math.Max(1, math.Floor(rate))
+	if wasUnlimited {
+		l.tokens = l.burst
+		l.last = now
+	}
+	l.tokens = math.Min(l.tokens, l.burst)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	var maxTPS atomic.Int64
+	maxTPS.Store(4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_tps": maxTPS.Load()},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	l := c.Limiter()
+	if c.Limiter() != l {
+		t.Error("Limiter() should return the same limiter")
+	}
+
+	// Burst of one second's worth, then denied until tokens refill
+	for i := 0; i < 4; i++ {
+		if !l.Allow() {
+			t.Fatalf("Allow() #%d denied within burst", i+1)
+		}
+	}
+	if l.Allow() {
+		t.Error("Allow() beyond burst should be denied")
+	}
+
+	// Wait with a deadline shorter than the refill time fails fast
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Wait() error = %v, want ErrLimitExceeded", err)
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Error("Wait() should fail without waiting")
+	}
+
+	// Wait without a deadline blocks for about 1/rate
+	start = time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if waited := time.Since(start); waited < 150*time.Millisecond {
+		t.Errorf("Wait() returned after %v, want ~250ms", waited)
+	}
+
+	// A license change is picked up on the next refresh
+	maxTPS.Store(0)
+	c.cache.clear()
+	l.mu.Lock()
+	l.refreshedAt = time.Time{}
+	l.mu.Unlock()
+	if rate, _ := l.Rate(); rate != 0 {
+		t.Errorf("Rate() after limit removed = %v, want 0 (unlimited)", rate)
+	}
+	for i := 0; i < 100; i++ {
+		if !l.Allow() {
+			t.Fatal("Allow() without a TPS limit should always allow")
+		}
+	}
+}
+
+func TestLimiter_TPSShare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_tps": 100},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.applyTPSShare(TPSShare{MaxTPS: 2.5, Instances: 40})
+
+	if rate, burst := c.Limiter().Rate(); rate != 2.5 || burst != 2 {
+		t.Errorf("Rate() = %v, %d; want the instance share 2.5, burst 2", rate, burst)
+	}
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// localQuotaBuckets is the number of buckets a sliding window is divided into.
+// Usage older than one bucket's span is expired in bucket-sized steps.
+const localQuotaBuckets = 60
+
+// localQuota performs product-level quota accounting in-process, based on
+// ProductQuotaConfig. Sliding, fixed and calendar-aligned windows are
+// supported. It is used in local-eval mode and as a fallback when the LCC
+// server cannot be reached.
+type localQuota struct {
+	mu      sync.Mutex
+	max     int
+	window  time.Duration
+	sliding bool
+
+	// Sliding window: usage per bucket number (unix nanos / bucketSize)
+	bucketSize time.Duration
+	buckets    map[int64]int
+
+	// Fixed and calendar windows: usage in [windowStart, windowEnd)
+	windowStart time.Time
+	windowEnd   time.Time
+	used        int
+
+	// Calendar windows: period name and timezone (calendar is "" otherwise)
+	calendar string
+	loc      *time.Location
+}
+
+// newLocalQuota creates local quota accounting from a product quota config
+func newLocalQuota(cfg *config.ProductQuotaConfig) (*localQuota, error) {
+	if cfg.IsCalendar() {
+		loc, err := cfg.Location()
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := config.CalendarWindow(cfg.Window, time.Now().In(loc)); err != nil {
+			return nil, fmt.Errorf("invalid quota window: %w", err)
+		}
+		return &localQuota{max: cfg.Max, calendar: cfg.Window, loc: loc}, nil
+	}
+
+	window, err := cfg.WindowDuration()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota window: %w", err)
+	}
+
+	q := &localQuota{
+		max:     cfg.Max,
+		window:  window,
+		sliding: cfg.Type != config.WindowFixed,
+	}
+	if q.sliding {
+		q.bucketSize = window / localQuotaBuckets
+		if q.bucketSize <= 0 {
+			q.bucketSize = window
+		}
+		q.buckets = make(map[int64]int)
+	}
+
+	return q, nil
+}
+
+// consume attempts to take amount units at time now.
+// The returned QuotaInfo reflects state after the attempt.
+func (q *localQuota) consume(now time.Time, amount int) (bool, *QuotaInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	used := q.usedLocked(now)
+	if used+amount > q.max {
+		return false, q.infoLocked(now, used)
+	}
+
+	q.addLocked(now, amount)
+	return true, q.infoLocked(now, used+amount)
+}
+
+// record adds usage without enforcing the limit. It keeps local state
+// current while the server is authoritative, so that a fallback to local
+// accounting starts from realistic numbers.
+func (q *localQuota) record(now time.Time, amount int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.usedLocked(now)
+	q.addLocked(now, amount)
+}
+
+// snapshot returns the current quota state
+func (q *localQuota) snapshot(now time.Time) *QuotaInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.infoLocked(now, q.usedLocked(now))
+}
+
+// usedLocked expires old usage and returns the amount used in the current window
+func (q *localQuota) usedLocked(now time.Time) int {
+	if !q.sliding {
+		if q.windowEnd.IsZero() || !now.Before(q.windowEnd) {
+			q.startWindowLocked(now)
+			q.used = 0
+		}
+		return q.used
+	}
+
+	oldest := q.bucketOf(now) - localQuotaBuckets + 1
+	used := 0
+	for k, n := range q.buckets {
+		if k < oldest {
+			delete(q.buckets, k)
+			continue
+		}
+		used += n
+	}
+	return used
+}
+
+// startWindowLocked begins the fixed or calendar window containing now
+func (q *localQuota) startWindowLocked(now time.Time) {
+	if q.calendar != "" {
+		// Period was validated in newLocalQuota
+		q.windowStart, q.windowEnd, _ = config.CalendarWindow(q.calendar, now.In(q.loc))
+		return
+	}
+	q.windowStart = now
+	q.windowEnd = now.Add(q.window)
+}
+
+func (q *localQuota) addLocked(now time.Time, amount int) {
+	if q.sliding {
+		q.buckets[q.bucketOf(now)] += amount
+		return
+	}
+	q.used += amount
+}
+
+// infoLocked builds a QuotaInfo. For sliding windows ResetAt is the time the
+// oldest recorded usage leaves the window.
+func (q *localQuota) infoLocked(now time.Time, used int) *QuotaInfo {
+	remaining := q.max - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var resetAt time.Time
+	if q.sliding {
+		first := int64(-1)
+		for k := range q.buckets {
+			if first < 0 || k < first {
+				first = k
+			}
+		}
+		if first >= 0 {
+			resetAt = time.Unix(0, (first+localQuotaBuckets)*int64(q.bucketSize))
+		}
+	} else {
+		resetAt = q.windowEnd
+	}
+
+	info := &QuotaInfo{Limit: q.max, Used: used, Remaining: remaining}
+	if !resetAt.IsZero() {
+		// Round up so the reset is never reported early
+		info.ResetAt = resetAt.Add(time.Second - 1).Unix()
+	}
+	return info
+}
+
+func (q *localQuota) bucketOf(t time.Time) int64 {
+	return t.UnixNano() / int64(q.bucketSize)
+}
+
+// consumeLocal makes a product-level Consume decision from local accounting
+func (c *Client) consumeLocal(amount int) (bool, int, error) {
+	allowed, info := c.localQuota.consume(time.Now(), amount)
+	c.noteQuota(productFeatureID, info, 0)
+
+	if !allowed {
+		return false, info.Remaining, fmt.Errorf("quota exceeded: %s (local)", ReasonQuotaExhausted)
+	}
+	return true, info.Remaining, nil
+}
+
+// LocalQuota returns the state of local product quota accounting.
+// Returns false if no product quota is configured in SDKConfig.Limits.
+func (c *Client) LocalQuota() (*QuotaInfo, bool) {
+	if c.localQuota == nil {
+		return nil, false
+	}
+	return c.localQuota.snapshot(time.Now()), true
+}
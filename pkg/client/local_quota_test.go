@@ -0,0 +1,90 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestLocalQuota_Sliding(t *testing.T) {
+	q, err := newLocalQuota(&config.ProductQuotaConfig{Max: 10, Window: "60s"})
+	if err != nil {
+		t.Fatalf("newLocalQuota() error = %v", err)
+	}
+
+	start := time.Unix(1_700_000_000, 0)
+	if ok, _ := q.consume(start, 6); !ok {
+		t.Fatal("consume(6) should be allowed")
+	}
+	if ok, _ := q.consume(start.Add(30*time.Second), 4); !ok {
+		t.Fatal("consume(4) should be allowed")
+	}
+	ok, info := q.consume(start.Add(45*time.Second), 1)
+	if ok {
+		t.Fatal("consume(1) should be denied when window is full")
+	}
+	if info.Remaining != 0 || info.Used != 10 {
+		t.Errorf("info = %+v, want used=10 remaining=0", info)
+	}
+	if want := start.Add(61 * time.Second).Unix(); info.ResetAt > want {
+		t.Errorf("ResetAt = %d, want <= %d", info.ResetAt, want)
+	}
+
+	// The first 6 units slide out of the window
+	ok, info = q.consume(start.Add(61*time.Second), 5)
+	if !ok {
+		t.Fatal("consume(5) should be allowed after oldest usage expires")
+	}
+	if info.Used != 9 {
+		t.Errorf("Used = %d, want 9", info.Used)
+	}
+}
+
+func TestLocalQuota_Fixed(t *testing.T) {
+	q, err := newLocalQuota(&config.ProductQuotaConfig{Max: 5, Window: "1h", Type: config.WindowFixed})
+	if err != nil {
+		t.Fatalf("newLocalQuota() error = %v", err)
+	}
+
+	start := time.Unix(1_700_000_000, 0)
+	if ok, _ := q.consume(start, 5); !ok {
+		t.Fatal("consume(5) should be allowed")
+	}
+	if ok, _ := q.consume(start.Add(59*time.Minute), 1); ok {
+		t.Fatal("consume(1) should be denied within the same window")
+	}
+	ok, info := q.consume(start.Add(time.Hour), 1)
+	if !ok || info.Remaining != 4 {
+		t.Errorf("new window: ok=%v info=%+v", ok, info)
+	}
+}
+
+func TestLocalQuota_CalendarMonthly(t *testing.T) {
+	q, err := newLocalQuota(&config.ProductQuotaConfig{
+		Max:      100,
+		Window:   config.PeriodMonthly,
+		Type:     config.WindowCalendar,
+		Timezone: "America/New_York",
+	})
+	if err != nil {
+		t.Fatalf("newLocalQuota() error = %v", err)
+	}
+
+	loc, _ := time.LoadLocation("America/New_York")
+	lastDay := time.Date(2026, time.March, 31, 23, 0, 0, 0, loc)
+	ok, info := q.consume(lastDay, 100)
+	if !ok {
+		t.Fatal("consume(100) should be allowed")
+	}
+	if want := time.Date(2026, time.April, 1, 0, 0, 0, 0, loc).Unix(); info.ResetAt != want {
+		t.Errorf("ResetAt = %v, want first of month %v", time.Unix(info.ResetAt, 0), time.Unix(want, 0))
+	}
+
+	if ok, _ := q.consume(lastDay.Add(59*time.Minute), 1); ok {
+		t.Error("consume(1) should be denied before month boundary")
+	}
+	if ok, _ := q.consume(lastDay.Add(time.Hour), 1); !ok {
+		t.Error("consume(1) should be allowed after month boundary")
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"strings"
+	"sync"
+)
+
+// ConsumeEvent reports the outcome of a Consume (or ConsumeWithContext)
+// call
+type ConsumeEvent struct {
+	Amount    int
+	Allowed   bool
+	Remaining int
+
+	// Denied is true when the quota refused the units. Allowed and Denied
+	// both false means Consume failed with Err.
+	Denied bool
+
+	// Err is the error Consume returned, also set for a denial
+	Err error
+}
+
+// newConsumeEvent describes the outcome of a Consume call
+func newConsumeEvent(amount int, allowed bool, remaining int, err error) ConsumeEvent {
+	denied := !allowed && err != nil && strings.HasPrefix(err.Error(), "quota exceeded")
+	return ConsumeEvent{Amount: amount, Allowed: allowed, Remaining: remaining, Denied: denied, Err: err}
+}
+
+// observers holds callbacks for Consume outcomes, heartbeats and events
+type observers struct {
+	mu        sync.Mutex
+	consume   []namedHook[func(ConsumeEvent)]
+	heartbeat []namedHook[func(error)]
+	event     []namedHook[func(Event)]
+}
+
+// OnConsume registers a callback fired after every Consume call with its
+// outcome, e.g. to count allowed and denied consumption.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy).
+func (c *Client) OnConsume(fn func(ConsumeEvent)) {
+	if fn == nil {
+		return
+	}
+	h := namedHook[func(ConsumeEvent)]{name: c.hooks.name("OnConsume"), fn: fn}
+	c.observers.mu.Lock()
+	defer c.observers.mu.Unlock()
+	c.observers.consume = append(c.observers.consume, h)
+}
+
+// OnHeartbeat registers a callback fired after every background heartbeat
+// with its error, nil when the heartbeat succeeded.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy).
+func (c *Client) OnHeartbeat(fn func(err error)) {
+	if fn == nil {
+		return
+	}
+	h := namedHook[func(error)]{name: c.hooks.name("OnHeartbeat"), fn: fn}
+	c.observers.mu.Lock()
+	defer c.observers.mu.Unlock()
+	c.observers.heartbeat = append(c.observers.heartbeat, h)
+}
+
+func (c *Client) notifyConsume(ev ConsumeEvent) {
+	c.observers.mu.Lock()
+	handlers := c.observers.consume
+	c.observers.mu.Unlock()
+
+	for _, h := range handlers {
+		fn := h.fn
+		c.hooks.dispatch(h.name, func() { fn(ev) })
+	}
+	if ev.Denied {
+		c.emit(Event{Type: EventQuotaExceeded, FeatureID: productFeatureID, Err: ev.Err})
+	}
+}
+
+func (c *Client) notifyHeartbeat(err error) {
+	c.observers.mu.Lock()
+	handlers := c.observers.heartbeat
+	c.observers.mu.Unlock()
+
+	if err != nil {
+		debugLogf("Heartbeat failed: %v", err)
+		c.emit(Event{Type: EventHeartbeatFailed, Err: err})
+	}
+	c.connection.record(err)
+	for _, h := range handlers {
+		fn := h.fn
+		c.hooks.dispatch(h.name, func() { fn(err) })
+	}
+}
+
+// CurrentTPS returns the rate CheckTPS compares against the TPS limit: the
+// value of the TPSProvider helper if registered, otherwise the rate of
+// Consume calls measured by the client
+func (c *Client) CurrentTPS() (float64, error) {
+	return c.getCurrentTPS()
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_OnConsume(t *testing.T) {
+	var exhausted atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/product/status" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": !exhausted.Load(), "reason": "over_limit"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	var mu sync.Mutex
+	var events []ConsumeEvent
+	c.OnConsume(func(ev ConsumeEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+
+	c.Consume(3)
+	exhausted.Store(true)
+	c.ClearCache()
+	c.Consume(1)
+	c.hooks.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2", events)
+	}
+	if ev := events[0]; !ev.Allowed || ev.Denied || ev.Amount != 3 || ev.Err != nil {
+		t.Errorf("allowed event = %+v", ev)
+	}
+	if ev := events[1]; ev.Allowed || !ev.Denied || ev.Err == nil {
+		t.Errorf("denied event = %+v", ev)
+	}
+}
+
+func TestClient_OnHeartbeat(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	var got []error
+	c.OnHeartbeat(func(err error) { got = append(got, err) })
+
+	c.notifyHeartbeat(c.sendHeartbeat())
+	c.hooks.wait()
+	if len(got) != 1 || got[0] == nil {
+		t.Errorf("OnHeartbeat() errors = %v, want one failure", got)
+	}
+}
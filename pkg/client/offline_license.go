@@ -0,0 +1,177 @@
+package client
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/lcc-sdk/pkg/license"
+)
+
+// Reason codes produced by offline license checks
+const (
+	ReasonLicensed           = "licensed"
+	ReasonNotInLicense       = "feature_not_in_license"
+	ReasonLicenseExpired     = "license_expired"
+	ReasonLicenseNotYetValid = "license_not_yet_valid"
+)
+
+// ErrNoLicense is returned by checks in offline mode before a license has
+// been loaded
+var ErrNoLicense = errors.New("offline mode: no license loaded")
+
+// offlineLicense is a verified license and its local quota accounting
+type offlineLicense struct {
+	license *license.License
+
+	// Product quota granted by the license (nil if unlimited)
+	quota *localQuota
+}
+
+// LoadLicense reads the signed license file at path (SDKConfig.LicenseFile
+// if empty), verifies it against vendorKey and switches the client to
+// offline mode (see UseLicense).
+func (c *Client) LoadLicense(path string, vendorKey crypto.PublicKey) error {
+	if path == "" {
+		path = c.licenseFile
+	}
+	if path == "" {
+		return fmt.Errorf("no license file configured")
+	}
+
+	lic, err := license.Load(path, vendorKey)
+	if err != nil {
+		return fmt.Errorf("failed to load license: %w", err)
+	}
+	return c.UseLicense(lic)
+}
+
+// UseLicense switches the client to offline mode with a verified license.
+// Every feature check, product limit and Consume decision is then made from
+// the license; registration, heartbeats and usage reports make no HTTP
+// calls. The product quota is accounted locally from the moment the license
+// is loaded.
+func (c *Client) UseLicense(lic *license.License) error {
+	if lic.ProductID != c.productID {
+		return fmt.Errorf("license is for product %q, not %q", lic.ProductID, c.productID)
+	}
+	if err := lic.Validate(c.licenseNow()); err != nil {
+		return err
+	}
+
+	ol := &offlineLicense{license: lic}
+	if lic.Limits.Quota != nil {
+		q, err := newLocalQuota(lic.Limits.Quota.Config())
+		if err != nil {
+			return fmt.Errorf("license quota: %w", err)
+		}
+		ol.quota = q
+	}
+	if err := c.pools.setLicense(lic.Pools); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.offlineMode = true
+	c.license = ol
+	c.mu.Unlock()
+
+	c.ClearCache()
+	debugLogf("Offline license %s loaded: %d feature(s), expires %v", lic.LicenseID, len(lic.Features), lic.ExpiresAt)
+	return nil
+}
+
+// OfflineMode reports whether checks are answered from a local license
+func (c *Client) OfflineMode() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offlineMode
+}
+
+// License returns the license used in offline mode, or nil
+func (c *Client) License() *license.License {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.license == nil {
+		return nil
+	}
+	return c.license.license
+}
+
+func (c *Client) loadedLicense() *offlineLicense {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.license
+}
+
+// offlineStatus answers a feature or product check from the license
+func (c *Client) offlineStatus(featureID string) (*FeatureStatus, error) {
+	ol := c.loadedLicense()
+	if ol == nil {
+		return nil, ErrNoLicense
+	}
+
+	now := c.licenseNow()
+	if err := ol.license.Validate(now); err != nil {
+		reason := ReasonLicenseNotYetValid
+		if errors.Is(err, license.ErrExpired) {
+			reason = ReasonLicenseExpired
+		}
+		return &FeatureStatus{Enabled: false, Reason: reason}, nil
+	}
+
+	if featureID == productFeatureID {
+		limits := ol.license.Limits
+		status := &FeatureStatus{
+			Enabled:        true,
+			Reason:         ReasonLicensed,
+			MaxCapacity:    limits.MaxCapacity,
+			MaxTPS:         limits.MaxTPS,
+			MaxConcurrency: limits.MaxConcurrency,
+			RateLimits:     limits.RateLimits,
+			Schedules:      limits.Schedules,
+		}
+		if ol.quota != nil {
+			status.Quota = ol.quota.snapshot(now)
+		}
+		return status, nil
+	}
+
+	feat, ok := ol.license.Feature(featureID)
+	if !ok {
+		return &FeatureStatus{Enabled: false, Reason: ReasonNotInLicense}, nil
+	}
+	return &FeatureStatus{
+		Enabled:        true,
+		Reason:         ReasonLicensed,
+		MaxCapacity:    feat.MaxCapacity,
+		MaxTPS:         feat.MaxTPS,
+		MaxConcurrency: feat.MaxConcurrency,
+		Schedules:      ol.license.Limits.Schedules,
+	}, nil
+}
+
+// consumeOffline makes a product-level Consume decision from the license
+// quota. A license without a quota grants unlimited consumption.
+func (c *Client) consumeOffline(amount int) (bool, int, error) {
+	ol := c.loadedLicense()
+	if ol == nil {
+		return false, 0, ErrNoLicense
+	}
+
+	now := c.licenseNow()
+	if err := ol.license.Validate(now); err != nil {
+		return false, 0, err
+	}
+	if ol.quota == nil {
+		return true, 0, nil
+	}
+
+	allowed, info := ol.quota.consume(now, amount)
+	c.noteQuota(productFeatureID, info, 0)
+	if !allowed {
+		return false, info.Remaining, fmt.Errorf("quota exceeded: %s (license)", ReasonQuotaExhausted)
+	}
+	return true, info.Remaining, nil
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/license"
+)
+
+func TestClient_OfflineLicense(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	vendor, _ := auth.GenerateKeyPair()
+	vendorPEM, _ := vendor.GetPublicKeyPEM()
+	vendorKey, _ := auth.ParsePublicKeyFromPEM([]byte(vendorPEM))
+
+	f, err := license.Sign(&license.License{
+		LicenseID: "lic-1",
+		ProductID: "test-app",
+		Features:  []license.Feature{{ID: "reports", MaxCapacity: 5}},
+		Limits:    license.Limits{MaxConcurrency: 2, Quota: &license.Quota{Max: 3, Window: "1h"}},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, vendor)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "license.json")
+	if err := f.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		CacheTTL:       time.Minute,
+		OfflineMode:    true,
+		LicenseFile:    path,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	// Offline mode never falls back to the server, even before a license
+	if _, err := c.CheckFeature("reports"); !errors.Is(err, ErrNoLicense) {
+		t.Errorf("CheckFeature() before LoadLicense error = %v, want ErrNoLicense", err)
+	}
+	if err := c.Register(); !errors.Is(err, ErrNoLicense) {
+		t.Errorf("Register() before LoadLicense error = %v, want ErrNoLicense", err)
+	}
+
+	if err := c.LoadLicense("", vendorKey); err != nil {
+		t.Fatalf("LoadLicense() error = %v", err)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	status, err := c.CheckFeature("reports")
+	if err != nil || !status.Enabled || status.Reason != ReasonLicensed || status.MaxCapacity != 5 {
+		t.Errorf("CheckFeature(reports) = %+v, %v; want licensed with capacity 5", status, err)
+	}
+	status, err = c.CheckFeature("export")
+	if err != nil || status.Enabled || status.Reason != ReasonNotInLicense {
+		t.Errorf("CheckFeature(export) = %+v, %v; want not in license", status, err)
+	}
+
+	for i, want := range []bool{true, true, true, false} {
+		allowed, _, _ := c.Consume(1)
+		if allowed != want {
+			t.Errorf("Consume() #%d allowed = %v, want %v", i+1, allowed, want)
+		}
+	}
+
+	release, allowed, err := c.AcquireSlot()
+	if err != nil || !allowed {
+		t.Fatalf("AcquireSlot() = %v, %v; want a slot from the license limit", allowed, err)
+	}
+	release()
+
+	if err := c.sendHeartbeat(); err != nil {
+		t.Errorf("sendHeartbeat() error = %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server received %d requests, want 0", n)
+	}
+}
+
+func TestClient_UseLicenseRejects(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:0")
+
+	tests := []struct {
+		name    string
+		lic     *license.License
+		wantErr error
+	}{
+		{"other product", &license.License{ProductID: "other"}, nil},
+		{"expired", &license.License{ProductID: "test-app", ExpiresAt: time.Now().Add(-time.Minute)}, license.ErrExpired},
+		{"bad quota window", &license.License{ProductID: "test-app", Limits: license.Limits{Quota: &license.Quota{Max: 1, Window: "often"}}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.UseLicense(tt.lic)
+			if err == nil {
+				t.Fatal("UseLicense() error = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UseLicense() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if c.OfflineMode() || c.License() != nil {
+		t.Error("rejected license switched the client to offline mode")
+	}
+}
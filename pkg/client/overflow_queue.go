@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Errors returned by AcquireSlot when the overflow queue cannot absorb a
+// caller
+var (
+	ErrOverflowQueueFull = errors.New("concurrency exceeded: overflow queue full")
+	ErrSlotWaitTimeout   = errors.New("concurrency exceeded: timed out waiting for a slot")
+)
+
+// OverflowQueueStats reports overflow queue activity since the queue was
+// configured
+type OverflowQueueStats struct {
+	// Configuration
+	Depth   int
+	MaxWait time.Duration
+
+	// Waiting is the number of callers currently queued; PeakWaiting is the
+	// highest value observed
+	Waiting     int
+	PeakWaiting int
+
+	// Enqueued callers end up either Admitted or TimedOut (which includes
+	// canceled contexts). Rejected callers found the queue full.
+	Enqueued uint64
+	Admitted uint64
+	TimedOut uint64
+	Rejected uint64
+
+	// TotalWait is the time admitted callers spent queued
+	TotalWait time.Duration
+}
+
+// AverageWait returns the mean queue time of admitted callers
+func (s OverflowQueueStats) AverageWait() time.Duration {
+	if s.Admitted == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Admitted)
+}
+
+// overflowQueue lets callers wait briefly for a concurrency slot instead of
+// failing as soon as MaxConcurrency is reached. Waiters are woken on every
+// release and race for the freed slot, so admission is not strictly FIFO.
+type overflowQueue struct {
+	depth   int
+	maxWait time.Duration
+
+	mu    sync.Mutex
+	freed chan struct{} // closed and replaced on every release
+	stats OverflowQueueStats
+}
+
+func newOverflowQueue(depth int, maxWait time.Duration) *overflowQueue {
+	return &overflowQueue{
+		depth:   depth,
+		maxWait: maxWait,
+		freed:   make(chan struct{}),
+		stats:   OverflowQueueStats{Depth: depth, MaxWait: maxWait},
+	}
+}
+
+// slotFreed returns a channel closed by the next release. Obtain it before
+// trying to acquire so a release in between is not missed.
+func (q *overflowQueue) slotFreed() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.freed
+}
+
+// signal wakes every waiter after a slot is released
+func (q *overflowQueue) signal() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	close(q.freed)
+	q.freed = make(chan struct{})
+}
+
+// enter reserves a place in the queue, or reports that it is full
+func (q *overflowQueue) enter() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stats.Waiting >= q.depth {
+		q.stats.Rejected++
+		return false
+	}
+	q.stats.Waiting++
+	q.stats.Enqueued++
+	if q.stats.Waiting > q.stats.PeakWaiting {
+		q.stats.PeakWaiting = q.stats.Waiting
+	}
+	return true
+}
+
+// leave removes a waiter, recording whether it got a slot
+func (q *overflowQueue) leave(waited time.Duration, admitted bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stats.Waiting--
+	if admitted {
+		q.stats.Admitted++
+		q.stats.TotalWait += waited
+	} else {
+		q.stats.TimedOut++
+	}
+}
+
+func (q *overflowQueue) snapshot() OverflowQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// acquireQueued takes a product-level slot, queuing for up to the queue's
+// max wait (or until ctx is done) when none is free
+func (c *Client) acquireQueued(ctx context.Context, q *overflowQueue, maxConcurrency int, holder *SlotHolder) (ReleaseFunc, bool, error) {
+	freed := q.slotFreed()
+	if release, _, ok := c.tryAcquireProductSlot(maxConcurrency, holder); ok {
+		return release, true, nil
+	}
+
+	if !q.enter() {
+		return func() {}, false, ErrOverflowQueueFull
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(q.maxWait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-freed:
+			freed = q.slotFreed()
+			if release, _, ok := c.tryAcquireProductSlot(maxConcurrency, holder); ok {
+				waited := time.Since(start)
+				q.leave(waited, true)
+				debugLogf("AcquireSlot: admitted from overflow queue after %v", waited)
+				return release, true, nil
+			}
+		case <-timer.C:
+			q.leave(time.Since(start), false)
+			return func() {}, false, fmt.Errorf("%w (%v)", ErrSlotWaitTimeout, q.maxWait)
+		case <-ctx.Done():
+			q.leave(time.Since(start), false)
+			return func() {}, false, ctx.Err()
+		}
+	}
+}
+
+// SetOverflowQueue lets up to depth callers wait up to maxWait for a
+// concurrency slot when MaxConcurrency is reached, instead of failing
+// immediately. A depth <= 0 disables queuing. Statistics are reset.
+func (c *Client) SetOverflowQueue(depth int, maxWait time.Duration) {
+	c.mu.Lock()
+	old := c.overflow
+	if depth <= 0 {
+		c.overflow = nil
+	} else {
+		c.overflow = newOverflowQueue(depth, maxWait)
+	}
+	c.mu.Unlock()
+
+	// Waiters on the old queue retry against the current pool
+	if old != nil {
+		old.signal()
+	}
+}
+
+// OverflowQueueStats returns overflow queue metrics. It returns the zero
+// value when no overflow queue is configured.
+func (c *Client) OverflowQueueStats() OverflowQueueStats {
+	c.mu.RLock()
+	q := c.overflow
+	c.mu.RUnlock()
+
+	if q == nil {
+		return OverflowQueueStats{}
+	}
+	return q.snapshot()
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newConcurrencyServer(t *testing.T, maxConcurrency int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_concurrency": maxConcurrency},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_AcquireSlotWithoutQueue(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 1).URL)
+
+	release, ok, err := c.AcquireSlot()
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlot() = %v, %v", ok, err)
+	}
+	defer release()
+
+	if _, ok, err := c.AcquireSlot(); ok || err == nil {
+		t.Errorf("AcquireSlot() over limit = %v, %v; want immediate rejection", ok, err)
+	}
+	if stats := c.OverflowQueueStats(); stats != (OverflowQueueStats{}) {
+		t.Errorf("OverflowQueueStats() = %+v, want zero value", stats)
+	}
+}
+
+func TestClient_AcquireSlotOverflowQueue(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 1).URL)
+	c.SetOverflowQueue(1, time.Second)
+
+	release, ok, err := c.AcquireSlot()
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlot() = %v, %v", ok, err)
+	}
+
+	type result struct {
+		release ReleaseFunc
+		ok      bool
+		err     error
+	}
+	queued := make(chan result, 1)
+	go func() {
+		r, ok, err := c.AcquireSlot()
+		queued <- result{r, ok, err}
+	}()
+
+	// Wait for the caller to be queued, then fill the queue
+	deadline := time.Now().Add(time.Second)
+	for c.OverflowQueueStats().Waiting != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("caller was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok, err := c.AcquireSlot(); ok || !errors.Is(err, ErrOverflowQueueFull) {
+		t.Errorf("AcquireSlot() with full queue = %v, %v; want ErrOverflowQueueFull", ok, err)
+	}
+
+	release()
+	r := <-queued
+	if r.err != nil || !r.ok {
+		t.Fatalf("queued AcquireSlot() = %v, %v; want admitted", r.ok, r.err)
+	}
+	r.release()
+
+	stats := c.OverflowQueueStats()
+	if stats.Enqueued != 1 || stats.Admitted != 1 || stats.Rejected != 1 || stats.Waiting != 0 || stats.PeakWaiting != 1 {
+		t.Errorf("OverflowQueueStats() = %+v", stats)
+	}
+	if stats.AverageWait() <= 0 {
+		t.Errorf("AverageWait() = %v, want > 0", stats.AverageWait())
+	}
+}
+
+func TestClient_AcquireSlotOverflowTimeout(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 1).URL)
+	c.SetOverflowQueue(4, 20*time.Millisecond)
+
+	release, _, err := c.AcquireSlot()
+	if err != nil {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	defer release()
+
+	if _, ok, err := c.AcquireSlot(); ok || !errors.Is(err, ErrSlotWaitTimeout) {
+		t.Errorf("AcquireSlot() = %v, %v; want ErrSlotWaitTimeout", ok, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok, err := c.AcquireSlotContext(ctx); ok || !errors.Is(err, context.Canceled) {
+		t.Errorf("AcquireSlotContext(canceled) = %v, %v; want context.Canceled", ok, err)
+	}
+
+	if stats := c.OverflowQueueStats(); stats.TimedOut != 2 || stats.Waiting != 0 {
+		t.Errorf("OverflowQueueStats() = %+v; want 2 timed out, none waiting", stats)
+	}
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrCertificatePin is returned when the LCC server's certificate chain
+// contains none of the pinned public keys (see SetServerPins)
+var ErrCertificatePin = errors.New("server certificate does not match any pinned public key")
+
+// pinPrefix prefixes a pin in its canonical form
+const pinPrefix = "sha256/"
+
+// SPKIPin returns the pin of cert's public key: "sha256/" followed by the
+// base64 SHA-256 of its DER SubjectPublicKeyInfo, the form of HPKP and curl
+// --pinnedpubkey
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// parsePin returns pin in its canonical form. The "sha256/" prefix is
+// optional.
+func parsePin(pin string) (string, error) {
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(pin), pinPrefix))
+	if err != nil || len(digest) != sha256.Size {
+		return "", fmt.Errorf("invalid server pin %q: want the base64 SHA-256 of a public key", pin)
+	}
+	return pinPrefix + base64.StdEncoding.EncodeToString(digest), nil
+}
+
+// parsePins returns pins in their canonical form
+func parsePins(pins []string) ([]string, error) {
+	parsed := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		p, err := parsePin(pin)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// pinSet holds the accepted server key pins; an empty set pins nothing
+type pinSet struct {
+	mu   sync.RWMutex
+	pins map[string]bool
+}
+
+func (p *pinSet) set(pins []string) {
+	m := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		m[pin] = true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pins = m
+}
+
+// list returns the pins, sorted
+func (p *pinSet) list() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	pins := make([]string, 0, len(p.pins))
+	for pin := range p.pins {
+		pins = append(pins, pin)
+	}
+	sort.Strings(pins)
+	return pins
+}
+
+func (p *pinSet) active() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.pins) > 0
+}
+
+// verifyConnection accepts a connection whose verified chains contain a
+// pinned key. Without verified chains (InsecureSkipVerify) only the leaf
+// certificate counts, since other presented certificates prove nothing.
+func (p *pinSet) verifyConnection(cs tls.ConnectionState) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.pins) == 0 {
+		return nil
+	}
+	chains := cs.VerifiedChains
+	if len(chains) == 0 && len(cs.PeerCertificates) > 0 {
+		chains = [][]*x509.Certificate{cs.PeerCertificates[:1]}
+	}
+	for _, chain := range chains {
+		for _, cert := range chain {
+			if p.pins[SPKIPin(cert)] {
+				return nil
+			}
+		}
+	}
+	return ErrCertificatePin
+}
+
+// pinnedTransport refuses LCC calls to servers not presenting a pinned
+// key. Over an *http.Transport the key is checked during the TLS handshake,
+// before anything is sent; other transports are checked on the response.
+type pinnedTransport struct {
+	next http.RoundTripper
+	pins *pinSet
+
+	// handshake is set when next is a clone of from (nil for the default
+	// transport) checking the pins during the TLS handshake
+	handshake bool
+	from      *http.Transport
+}
+
+func newPinnedTransport(base http.RoundTripper, pins *pinSet) *pinnedTransport {
+	t := &pinnedTransport{next: base, pins: pins}
+	var transport *http.Transport
+	switch b := base.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = b.Clone()
+		t.from = b
+	}
+	if transport != nil {
+		tlsConfig := transport.TLSClientConfig.Clone()
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		verify := tlsConfig.VerifyConnection
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+			return pins.verifyConnection(cs)
+		}
+		transport.TLSClientConfig = tlsConfig
+		t.next = transport
+		t.handshake = true
+	}
+	return t
+}
+
+// wraps reports whether t is the pinned form of base. Transports other
+// than *http.Transport may not be comparable and are always wrapped anew.
+func (t *pinnedTransport) wraps(base http.RoundTripper) bool {
+	if !t.handshake {
+		return false
+	}
+	if base == nil {
+		return t.from == nil
+	}
+	b, ok := base.(*http.Transport)
+	return ok && b == t.from
+}
+
+func (t *pinnedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.pins.active() && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s is not https", ErrCertificatePin, req.URL.Host)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || t.handshake || !t.pins.active() {
+		return resp, err
+	}
+	if resp.TLS == nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: no TLS connection state", ErrCertificatePin)
+	}
+	if err := t.pins.verifyConnection(*resp.TLS); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the pinned transport
+func (t *pinnedTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// SetServerPins pins the public keys the LCC server may present, like
+// SDKConfig.ServerPins: connections whose verified certificate chain
+// contains none of them fail with ErrCertificatePin, even if a trusted CA
+// issued the certificate. List the current and the next key to rotate
+// without downtime. Pins are in SPKIPin form; the "sha256/" prefix is
+// optional. New connections use the pins, and idle ones are closed. No
+// pins disables pinning.
+func (c *Client) SetServerPins(pins ...string) error {
+	parsed, err := parsePins(pins)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.pins.set(parsed)
+	c.applyMiddlewareLocked()
+	c.mu.Unlock()
+	c.closeIdleConnections()
+	return nil
+}
+
+// ServerPins returns the pinned server public keys in SPKIPin form
+func (c *Client) ServerPins() []string {
+	return c.pins.list()
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// otherPin is a valid pin of no key in these tests
+var otherPin = func() string {
+	sum := sha256.Sum256([]byte("another key"))
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}()
+
+func newPinnedServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_ServerPins(t *testing.T) {
+	var calls atomic.Int32
+	srv := newPinnedServer(t, &calls)
+	pin := SPKIPin(srv.Certificate())
+
+	c := newTestClient(t, srv.URL)
+	c.SetHTTPClient(srv.Client())
+	if err := c.SetServerPins(otherPin); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CA is trusted, but the key is not pinned: nothing is sent
+	if _, err := c.queryFeature("reports"); !errors.Is(err, ErrCertificatePin) {
+		t.Fatalf("queryFeature() error = %v, want ErrCertificatePin", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("server calls = %d, want 0", n)
+	}
+
+	// Rotation: the current and the next key are both accepted
+	if err := c.SetServerPins(otherPin, pin[len("sha256/"):]); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := c.queryFeature("reports"); err != nil || !status.Enabled {
+		t.Fatalf("queryFeature() with the pinned key = %+v, %v", status, err)
+	}
+	if got := c.ServerPins(); len(got) != 2 || (got[0] != pin && got[1] != pin) {
+		t.Errorf("ServerPins() = %v, want %s in canonical form", got, pin)
+	}
+
+	if err := c.SetServerPins("not-a-pin"); err == nil {
+		t.Error("SetServerPins() accepted an invalid pin")
+	}
+}
+
+func TestClient_ServerPinsCustomTransport(t *testing.T) {
+	var calls atomic.Int32
+	srv := newPinnedServer(t, &calls)
+
+	c := newTestClient(t, srv.URL)
+	transport := srv.Client().Transport
+	c.SetHTTPClient(&http.Client{Transport: roundTripFunc(transport.RoundTrip)})
+	if err := c.SetServerPins(otherPin); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other transports are checked on the response
+	if _, err := c.queryFeature("reports"); !errors.Is(err, ErrCertificatePin) {
+		t.Fatalf("queryFeature() error = %v, want ErrCertificatePin", err)
+	}
+	if err := c.SetServerPins(SPKIPin(srv.Certificate())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.queryFeature("reports"); err != nil {
+		t.Fatalf("queryFeature() with the pinned key error = %v", err)
+	}
+
+	// Disabling pinning restores the plain transport
+	if err := c.SetServerPins(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.queryFeature("reports"); err != nil {
+		t.Fatalf("queryFeature() without pins error = %v", err)
+	}
+}
+
+func TestClient_ServerPinsPlaintext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.SetServerPins(otherPin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.queryFeature("reports"); !errors.Is(err, ErrCertificatePin) {
+		t.Errorf("queryFeature() over http error = %v, want ErrCertificatePin", err)
+	}
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in pipeline stage names (see also StagePolicy)
+const (
+	StageHooks  = "hooks"
+	StageLimits = "limits"
+	StageCache  = "cache"
+	StageRemote = "remote"
+)
+
+// CheckRequest describes a single enforcement decision flowing through the
+// check pipeline.
+type CheckRequest struct {
+	FeatureID string
+}
+
+// CheckFunc evaluates a check request. Inside a Stage it represents the
+// remainder of the pipeline.
+type CheckFunc func(ctx context.Context, req *CheckRequest) (*FeatureStatus, error)
+
+// Stage is a middleware in the feature check pipeline.
+//
+// A stage may short-circuit by returning a status without calling next
+// (e.g., an allowlist override for emergency operations), or call next and
+// inspect/modify the result on the way back out.
+//
+// The default pipeline is: hooks → policy → limits → cache → remote.
+//
+//   - hooks reports the final decision to usage statistics, the compliance
+//     report, the audit log and OnEvent callbacks
+//   - policy applies fail-open and monitor-only policies
+//   - limits applies local limits: schedule rules and quota pools
+//   - cache serves and stores statuses
+//   - remote queries the LCC server, or the license in offline mode
+//
+// Custom stages are inserted with UseStage, InsertStageBefore or
+// InsertStageAfter. Capacity, TPS and concurrency limits are product-level
+// and are enforced by CheckCapacity, CheckTPS and AcquireSlot, not by this
+// pipeline.
+type Stage interface {
+	Name() string
+	Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error)
+}
+
+// StageFunc builds a Stage from a name and a function
+//
+// Example:
+//
+//	override := client.StageFunc("emergency_allow", func(ctx context.Context, req *client.CheckRequest, next client.CheckFunc) (*client.FeatureStatus, error) {
+//	    if emergencyMode.Load() && req.FeatureID == "bulk_export" {
+//	        return &client.FeatureStatus{Enabled: true, Reason: "emergency_override"}, nil
+//	    }
+//	    return next(ctx, req)
+//	})
+//	c.UseStage(override)
+func StageFunc(name string, fn func(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error)) Stage {
+	return &funcStage{name: name, fn: fn}
+}
+
+type funcStage struct {
+	name string
+	fn   func(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error)
+}
+
+func (s *funcStage) Name() string { return s.name }
+
+func (s *funcStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	return s.fn(ctx, req, next)
+}
+
+// checkPipeline holds the ordered list of stages
+type checkPipeline struct {
+	mu     sync.RWMutex
+	stages []Stage
+}
+
+// newCheckPipeline creates the default pipeline for a client
+func newCheckPipeline(c *Client) *checkPipeline {
+	return &checkPipeline{
+		stages: []Stage{
+			&hooksStage{client: c},
+			&policyStage{client: c},
+			&limitsStage{client: c},
+			&cacheStage{client: c},
+			&remoteStage{client: c},
+		},
+	}
+}
+
+// run executes the pipeline for the given request
+func (p *checkPipeline) run(ctx context.Context, req *CheckRequest) (*FeatureStatus, error) {
+	p.mu.RLock()
+	stages := make([]Stage, len(p.stages))
+	copy(stages, p.stages)
+	p.mu.RUnlock()
+
+	next := CheckFunc(func(ctx context.Context, req *CheckRequest) (*FeatureStatus, error) {
+		return nil, fmt.Errorf("check pipeline produced no decision for feature %s", req.FeatureID)
+	})
+
+	for i := len(stages) - 1; i >= 0; i-- {
+		stage, inner := stages[i], next
+		next = func(ctx context.Context, req *CheckRequest) (*FeatureStatus, error) {
+			return stage.Check(ctx, req, inner)
+		}
+	}
+
+	return next(ctx, req)
+}
+
+// indexOf returns the position of the named stage, or -1. Caller holds p.mu.
+func (p *checkPipeline) indexOf(name string) int {
+	for i, s := range p.stages {
+		if s.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert places stage at position idx. Caller holds p.mu.
+func (p *checkPipeline) insert(idx int, stage Stage) {
+	p.stages = append(p.stages, nil)
+	copy(p.stages[idx+1:], p.stages[idx:])
+	p.stages[idx] = stage
+}
+
+// UseStage adds a stage at the front of the check pipeline, so it sees every
+// request before the policy and the cache. It goes behind the hooks stage,
+// so its decisions are still reported.
+func (c *Client) UseStage(stage Stage) {
+	c.pipeline.mu.Lock()
+	defer c.pipeline.mu.Unlock()
+	c.pipeline.insert(c.pipeline.indexOf(StageHooks)+1, stage) // front if hooks was removed
+}
+
+// InsertStageBefore inserts a stage immediately before the named stage
+func (c *Client) InsertStageBefore(name string, stage Stage) error {
+	c.pipeline.mu.Lock()
+	defer c.pipeline.mu.Unlock()
+
+	idx := c.pipeline.indexOf(name)
+	if idx < 0 {
+		return fmt.Errorf("pipeline stage %q not found", name)
+	}
+	c.pipeline.insert(idx, stage)
+	return nil
+}
+
+// InsertStageAfter inserts a stage immediately after the named stage
+func (c *Client) InsertStageAfter(name string, stage Stage) error {
+	c.pipeline.mu.Lock()
+	defer c.pipeline.mu.Unlock()
+
+	idx := c.pipeline.indexOf(name)
+	if idx < 0 {
+		return fmt.Errorf("pipeline stage %q not found", name)
+	}
+	c.pipeline.insert(idx+1, stage)
+	return nil
+}
+
+// RemoveStage removes the named stage from the pipeline.
+// Built-in stages may be removed too (e.g., removing "cache" disables caching).
+func (c *Client) RemoveStage(name string) error {
+	c.pipeline.mu.Lock()
+	defer c.pipeline.mu.Unlock()
+
+	idx := c.pipeline.indexOf(name)
+	if idx < 0 {
+		return fmt.Errorf("pipeline stage %q not found", name)
+	}
+	c.pipeline.stages = append(c.pipeline.stages[:idx], c.pipeline.stages[idx+1:]...)
+	return nil
+}
+
+// Stages returns the names of the pipeline stages in execution order
+func (c *Client) Stages() []string {
+	c.pipeline.mu.RLock()
+	defer c.pipeline.mu.RUnlock()
+
+	names := make([]string, len(c.pipeline.stages))
+	for i, s := range c.pipeline.stages {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// hooksStage reports feature decisions once the rest of the pipeline has
+// made them. The product-level status is not reported. A denial waived by a
+// monitor-only policy or schedule rule is audited and emitted as a denial.
+type hooksStage struct {
+	client *Client
+}
+
+func (s *hooksStage) Name() string { return StageHooks }
+
+func (s *hooksStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	status, err := next(ctx, req)
+	if req.FeatureID == productFeatureID {
+		return status, err
+	}
+
+	c := s.client
+	c.featureUsage.record(req.FeatureID, status, err)
+	c.compliance.check(req.FeatureID, status, err)
+	if err == nil && (!status.Enabled || waivedDenial(status)) {
+		c.audit.add(AuditRecord{Time: time.Now(), Kind: AuditFeatureDenied, FeatureID: req.FeatureID, Reason: status.Reason})
+		c.emit(Event{Type: EventFeatureDenied, FeatureID: req.FeatureID, Reason: status.Reason})
+	}
+	return status, err
+}
+
+// waivedDenial reports whether status is a denial let through by a
+// monitor-only policy or schedule rule
+func waivedDenial(status *FeatureStatus) bool {
+	return strings.HasPrefix(status.Reason, ReasonMonitorOnlyPrefix) || strings.HasPrefix(status.Reason, ReasonSchedulePrefix)
+}
+
+// limitsStage applies schedule rules and quota pools to the status
+// returned by the rest of the pipeline
+type limitsStage struct {
+	client *Client
+}
+
+func (s *limitsStage) Name() string { return StageLimits }
+
+func (s *limitsStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	status, err := next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	status = s.client.applySchedules(req.FeatureID, status, now)
+	return s.client.applyQuotaPool(req.FeatureID, status, now), nil
+}
+
+// cacheStage serves results from the feature cache and stores fresh results
+type cacheStage struct {
+	client *Client
+}
+
+func (s *cacheStage) Name() string { return StageCache }
+
+func (s *cacheStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	if status := s.client.cache.get(req.FeatureID); status != nil {
+		s.client.cache.hits.Add(1)
+		return s.client.effectiveStatus(req.FeatureID, status), nil
+	}
+	s.client.cache.misses.Add(1)
+
+	status, err := next(ctx, req)
+	if err != nil {
+		// While the server is unreachable, the last known status beats a
+		// fail-open or fail-closed guess
+		if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrEgressDisabled) {
+			if stale := s.client.cache.stale(req.FeatureID); stale != nil {
+				return s.client.effectiveStatus(req.FeatureID, stale), nil
+			}
+		}
+		// With a cache file, a recently expired status (possibly restored
+		// after a restart) bridges a short outage
+		if store := s.client.cacheStore; store != nil {
+			if stale := s.client.cache.staleWithin(req.FeatureID, store.maxStale); stale != nil {
+				debugLogf("Check %s: serving cached status, server unavailable: %v", req.FeatureID, err)
+				return s.client.effectiveStatus(req.FeatureID, stale), nil
+			}
+		}
+		return nil, err
+	}
+
+	prev := s.client.cache.setWithTTL(req.FeatureID, status, s.client.policyFor(req.FeatureID).cacheTTL)
+	s.client.statusChanges.compare(req.FeatureID, prev, status)
+	s.client.observeDowngrade(req.FeatureID, prev, status)
+	return s.client.effectiveStatus(req.FeatureID, status), nil
+}
+
+// remoteStage queries the LCC server. It is terminal and never calls next.
+// Concurrent checks of the same feature share one query.
+type remoteStage struct {
+	client *Client
+}
+
+func (s *remoteStage) Name() string { return StageRemote }
+
+func (s *remoteStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	return s.client.queries.do(ctx, req.FeatureID, func() (*FeatureStatus, error) {
+		return s.client.queryFeature(req.FeatureID)
+	})
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_PipelineCustomStage(t *testing.T) {
+	var remoteCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&remoteCalls, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false, "reason": "feature_not_in_license"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+
+	override := StageFunc("allowlist", func(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+		if req.FeatureID == "ops_export" {
+			return &FeatureStatus{Enabled: true, Reason: "emergency_override"}, nil
+		}
+		return next(ctx, req)
+	})
+	c.UseStage(override)
+
+	if got, want := c.Stages(), []string{StageHooks, "allowlist", StagePolicy, StageLimits, StageCache, StageRemote}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Stages() = %v, want %v", got, want)
+	}
+
+	status, err := c.CheckFeature("ops_export")
+	if err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if !status.Enabled || status.Reason != "emergency_override" {
+		t.Errorf("override not applied: %+v", status)
+	}
+	if n := atomic.LoadInt32(&remoteCalls); n != 0 {
+		t.Errorf("remote calls = %d, want 0", n)
+	}
+
+	// Non-overridden features go through cache and remote
+	for i := 0; i < 2; i++ {
+		status, err = c.CheckFeature("reports")
+		if err != nil {
+			t.Fatalf("CheckFeature() error = %v", err)
+		}
+		if status.Enabled {
+			t.Error("reports should be disabled")
+		}
+	}
+	if n := atomic.LoadInt32(&remoteCalls); n != 1 {
+		t.Errorf("remote calls = %d, want 1 (second call cached)", n)
+	}
+
+	if err := c.InsertStageAfter("missing", override); err == nil {
+		t.Error("InsertStageAfter() with unknown stage should fail")
+	}
+}
+
+func TestClient_PipelineHooksStage(t *testing.T) {
+	c := newTestClient(t, "http://localhost:1")
+	c.UseStage(StageFunc("deny", func(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+		return &FeatureStatus{Enabled: false, Reason: "maintenance"}, nil
+	}))
+
+	// A decision made by a custom stage is reported
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	records := c.AuditRecords()
+	if len(records) != 1 || records[0].Kind != AuditFeatureDenied || records[0].Reason != "maintenance" {
+		t.Fatalf("AuditRecords() = %+v", records)
+	}
+
+	// Without the hooks stage decisions are not reported
+	if err := c.RemoveStage(StageHooks); err != nil {
+		t.Fatalf("RemoveStage() error = %v", err)
+	}
+	if _, err := c.CheckFeature("reports"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if n := len(c.AuditRecords()); n != 1 {
+		t.Errorf("AuditRecords() after removing the hooks stage = %d records, want 1", n)
+	}
+}
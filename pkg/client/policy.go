@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// StagePolicy is the name of the built-in per-feature policy stage
+const StagePolicy = "policy"
+
+// Reason codes produced by enforcement policies
+const (
+	ReasonFailOpen          = "fail_open"
+	ReasonMonitorOnlyPrefix = "monitor_only:"
+)
+
+// effectivePolicy is a FeaturePolicy resolved against global settings
+type effectivePolicy struct {
+	failOpen    bool
+	cacheTTL    time.Duration
+	monitorOnly bool
+}
+
+// SetFeaturePolicy sets a programmatic policy override for a feature.
+// Programmatic overrides take precedence over the manifest's policy block,
+// which in turn takes precedence over global SDKConfig settings.
+func (c *Client) SetFeaturePolicy(featureID string, policy config.FeaturePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.policies == nil {
+		c.policies = make(map[string]config.FeaturePolicy)
+	}
+	c.policies[featureID] = policy
+}
+
+// policyFor resolves the effective policy for a feature
+func (c *Client) policyFor(featureID string) effectivePolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p := effectivePolicy{
+		failOpen: c.failOpen,
+		cacheTTL: c.cache.ttl,
+	}
+	enforcePercent := 100
+
+	apply := func(fp *config.FeaturePolicy) {
+		if fp == nil {
+			return
+		}
+		if fp.FailOpen != nil {
+			p.failOpen = *fp.FailOpen
+		}
+		if fp.CacheTTL > 0 {
+			p.cacheTTL = fp.CacheTTL
+		}
+		if fp.MonitorOnly {
+			p.monitorOnly = true
+		}
+		if fp.EnforcePercent != nil {
+			enforcePercent = *fp.EnforcePercent
+		}
+	}
+
+	if c.manifest != nil {
+		if f := c.manifest.FindFeature(featureID); f != nil {
+			apply(f.Policy)
+		}
+	}
+	if fp, ok := c.policies[featureID]; ok {
+		apply(&fp)
+	}
+	if enforcementBucket(c.instanceID) >= enforcePercent {
+		p.monitorOnly = true
+	}
+
+	return p
+}
+
+// EnforcementBucket returns the instance's bucket, 0 to 99, for staged
+// enforcement: the instance enforces a feature whose policy sets
+// EnforcePercent if the bucket is below it
+func (c *Client) EnforcementBucket() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return enforcementBucket(c.instanceID)
+}
+
+// enforcementBucket hashes an instance ID into one of 100 buckets. The
+// bucket does not depend on the feature, so the same instances enforce
+// first across rollouts.
+func enforcementBucket(instanceID string) int {
+	sum := sha256.Sum256([]byte(instanceID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+// policyStage applies fail-open and monitor-only policies. It runs in
+// front of the cache so that synthesized decisions are never cached.
+type policyStage struct {
+	client *Client
+}
+
+func (s *policyStage) Name() string { return StagePolicy }
+
+func (s *policyStage) Check(ctx context.Context, req *CheckRequest, next CheckFunc) (*FeatureStatus, error) {
+	policy := s.client.policyFor(req.FeatureID)
+
+	status, err := next(ctx, req)
+	if err != nil {
+		if policy.failOpen {
+			debugLogf("Policy: failing open for feature %s: %v", req.FeatureID, err)
+			return &FeatureStatus{Enabled: true, Reason: ReasonFailOpen}, nil
+		}
+		return nil, err
+	}
+
+	if policy.monitorOnly && !status.Enabled {
+		debugLogf("Policy: monitor-only feature %s would be denied: %s", req.FeatureID, status.Reason)
+		monitored := *status
+		monitored.Enabled = true
+		monitored.Reason = ReasonMonitorOnlyPrefix + status.Reason
+		return &monitored, nil
+	}
+
+	return status, nil
+}
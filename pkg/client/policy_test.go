@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_FeaturePolicies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/features/payments/check" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false, "reason": "feature_not_in_license"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	failOpen := true
+	c.SetManifest(&config.Manifest{
+		Features: []config.FeatureConfig{
+			{ID: "theme", Policy: &config.FeaturePolicy{MonitorOnly: true}},
+		},
+	})
+
+	// Global default is fail-closed
+	if _, err := c.CheckFeature("payments"); err == nil {
+		t.Fatal("CheckFeature() should fail closed by default")
+	}
+
+	c.SetFeaturePolicy("payments", config.FeaturePolicy{FailOpen: &failOpen})
+	status, err := c.CheckFeature("payments")
+	if err != nil {
+		t.Fatalf("CheckFeature() error = %v, want fail-open", err)
+	}
+	if !status.Enabled || status.Reason != ReasonFailOpen {
+		t.Errorf("fail-open status = %+v", status)
+	}
+
+	status, err = c.CheckFeature("theme")
+	if err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if !status.Enabled || status.Reason != ReasonMonitorOnlyPrefix+"feature_not_in_license" {
+		t.Errorf("monitor-only status = %+v", status)
+	}
+
+	// The waived denial is still audited and emitted
+	var events []Event
+	c.OnEvent(func(ev Event) { events = append(events, ev) })
+	if _, err := c.CheckFeature("theme"); err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	c.hooks.wait()
+	var audited []AuditRecord
+	for _, r := range c.AuditRecords() {
+		if r.FeatureID == "theme" {
+			audited = append(audited, r)
+		}
+	}
+	if len(audited) != 2 || audited[1].Kind != AuditFeatureDenied || audited[1].Reason != ReasonMonitorOnlyPrefix+"feature_not_in_license" {
+		t.Errorf("audit records for theme = %+v", audited)
+	}
+	if len(events) != 1 || events[0].Type != EventFeatureDenied || events[0].FeatureID != "theme" {
+		t.Errorf("events = %+v", events)
+	}
+
+	// The cached decision must remain the real one
+	if cached := c.cache.get("theme"); cached == nil || cached.Enabled {
+		t.Errorf("cached status = %+v, want real denial", cached)
+	}
+}
+
+func TestClient_EnforcePercent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false, "reason": "quota_exceeded"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	bucket := c.EnforcementBucket()
+	if bucket < 0 || bucket > 99 {
+		t.Fatalf("EnforcementBucket() = %d, want 0-99", bucket)
+	}
+	if again := c.EnforcementBucket(); again != bucket {
+		t.Errorf("EnforcementBucket() = %d then %d, want stable", bucket, again)
+	}
+
+	tests := []struct {
+		percent int
+		enforce bool
+	}{
+		{0, false},
+		{bucket, false},
+		{bucket + 1, true},
+		{100, true},
+	}
+	for _, tt := range tests {
+		percent := tt.percent
+		c.SetFeaturePolicy("export", config.FeaturePolicy{EnforcePercent: &percent})
+		status, err := c.CheckFeature("export")
+		if err != nil {
+			t.Fatalf("CheckFeature() error = %v", err)
+		}
+		if status.Enabled == tt.enforce {
+			t.Errorf("enforce_percent %d with bucket %d: status = %+v, want enforce %v", percent, bucket, status, tt.enforce)
+		}
+		if !tt.enforce && status.Reason != ReasonMonitorOnlyPrefix+"quota_exceeded" {
+			t.Errorf("enforce_percent %d: reason = %q", percent, status.Reason)
+		}
+	}
+}
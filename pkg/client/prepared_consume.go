@@ -0,0 +1,200 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// preparedRefresh is how often a PreparedConsume reports the units it
+// admitted and re-reads its feature's status
+const preparedRefresh = time.Second
+
+// PreparedConsume admits units of one feature's quota from a status read
+// ahead of time, for per-item calls in tight loops. Allow makes no network
+// call: the admitted units are reported in one batch, and the status
+// re-read, at most once per second, when the quota budget read with the
+// status runs out, and on Flush and Close.
+//
+// Between refreshes the budget is the remaining quota of the last status,
+// less the units admitted since, so other instances consuming the same
+// quota can overshoot it by up to a second's worth of their usage. Allow
+// calls are not recorded for CheckTPS and do not fire OnConsume callbacks.
+// A PreparedConsume is safe for concurrent use.
+type PreparedConsume struct {
+	c         *Client
+	featureID string
+
+	mu          sync.Mutex
+	status      *FeatureStatus
+	refreshedAt time.Time
+	used        int // units admitted since status was read
+	pending     int // units admitted but not yet reported
+	closed      bool
+}
+
+// PrepareConsume returns a handle admitting units of featureID's quota
+// without a server round trip per call. An empty featureID prepares the
+// product quota used by Consume. It fails if the status cannot be read.
+//
+// Example:
+//
+//	pc, err := client.PrepareConsume("export")
+//	if err != nil {
+//	    return err
+//	}
+//	defer pc.Close()
+//	for _, rec := range records {
+//	    if !pc.Allow(1) {
+//	        break
+//	    }
+//	    process(rec)
+//	}
+func (c *Client) PrepareConsume(featureID string) (*PreparedConsume, error) {
+	if featureID == "" {
+		featureID = productFeatureID
+	}
+	p := &PreparedConsume{c: c, featureID: featureID}
+
+	status, err := c.CheckFeature(featureID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare consume for %s: %w", featureID, err)
+	}
+	p.status = status
+	p.refreshedAt = time.Now()
+	return p, nil
+}
+
+// Allow reports whether n units may be consumed now, admitting them if so
+func (p *PreparedConsume) Allow(n int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	now := time.Now()
+	if now.Sub(p.refreshedAt) >= preparedRefresh {
+		p.refreshLocked(now)
+	}
+	if !p.status.Enabled {
+		return false
+	}
+	if _, exhausted := p.c.quotaResets.exhaustedUntil(p.featureID); exhausted {
+		return false
+	}
+	if remaining, ok := p.remainingLocked(); ok && n > remaining {
+		// The budget may be stale; re-read it once per refresh interval
+		if p.pending == 0 || now.Equal(p.refreshedAt) {
+			return false
+		}
+		p.refreshLocked(now)
+		if remaining, ok := p.remainingLocked(); !p.status.Enabled || (ok && n > remaining) {
+			return false
+		}
+	}
+
+	p.used += n
+	p.pending += n
+	return true
+}
+
+// Remaining returns the quota budget left until the next refresh, and false
+// if the feature has no quota
+func (p *PreparedConsume) Remaining() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.remainingLocked()
+}
+
+// Flush reports the admitted units and re-reads the feature's status
+func (p *PreparedConsume) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	return p.refreshLocked(time.Now())
+}
+
+// Close reports the admitted units. Allow denies everything afterwards.
+// Close is idempotent.
+func (p *PreparedConsume) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.reportLocked()
+}
+
+// remainingLocked returns the budget left from the last status. Caller
+// holds p.mu.
+func (p *PreparedConsume) remainingLocked() (int, bool) {
+	if p.status.Quota == nil {
+		return 0, false
+	}
+	if remaining := p.status.Quota.Remaining - p.used; remaining > 0 {
+		return remaining, true
+	}
+	return 0, true
+}
+
+// refreshLocked reports pending units and re-reads the status. A failed
+// read keeps the previous status. Caller holds p.mu.
+func (p *PreparedConsume) refreshLocked(now time.Time) error {
+	p.refreshedAt = now
+	if err := p.reportLocked(); err != nil {
+		debugLogf("PreparedConsume %s: keeping %d units to report: %v", p.featureID, p.pending, err)
+		return err
+	}
+	if p.used > 0 {
+		// The cached quota predates the units reported
+		p.c.cache.expire(p.featureID)
+	}
+
+	status, err := p.c.CheckFeature(p.featureID)
+	if err != nil {
+		debugLogf("PreparedConsume %s: keeping the previous status, refresh failed: %v", p.featureID, err)
+		return err
+	}
+	p.status = status
+	p.used = 0
+	if status.Quota != nil {
+		p.c.noteQuota(p.featureID, status.Quota, 0)
+	}
+	return nil
+}
+
+// reportLocked reports the pending units the way Consume accounts usage.
+// Caller holds p.mu.
+func (p *PreparedConsume) reportLocked() error {
+	if p.pending == 0 {
+		return nil
+	}
+	n := p.pending
+	c := p.c
+
+	if p.featureID == productFeatureID && c.OfflineMode() {
+		// The units were admitted already; a denial only means the
+		// license quota ran out in the meantime
+		if _, _, err := c.consumeOffline(n); err != nil {
+			debugLogf("PreparedConsume: %d units over the license quota: %v", n, err)
+		}
+		c.compliance.use(productFeatureID, "", float64(n), false)
+		p.pending = 0
+		return nil
+	}
+	if err := c.ReportUsage(p.featureID, float64(n)); err != nil {
+		return err
+	}
+	if p.featureID == productFeatureID {
+		c.compliance.use(productFeatureID, "", float64(n), false)
+		if c.localQuota != nil {
+			c.localQuota.record(time.Now(), n)
+		}
+	}
+	p.pending = 0
+	return nil
+}
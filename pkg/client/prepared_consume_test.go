@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClient_PrepareConsume(t *testing.T) {
+	var mu sync.Mutex
+	used, checks, reports := 0, 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v1/sdk/features/export/check":
+			checks++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled":    true,
+				"quota_info": map[string]interface{}{"limit": 10, "used": used, "remaining": 10 - used},
+			})
+		case "/api/v1/sdk/usage":
+			var body struct {
+				Count int `json:"count"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			used += body.Count
+			reports++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	pc, err := c.PrepareConsume("export")
+	if err != nil {
+		t.Fatalf("PrepareConsume() error = %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		if !pc.Allow(1) {
+			t.Fatalf("Allow(1) #%d denied within the quota", i+1)
+		}
+	}
+	if pc.Allow(5) {
+		t.Error("Allow(5) with 4 units left should be denied")
+	}
+	if remaining, ok := pc.Remaining(); !ok || remaining != 4 {
+		t.Errorf("Remaining() = %d, %v; want 4", remaining, ok)
+	}
+	mu.Lock()
+	if checks != 2 || reports != 1 || used != 6 {
+		t.Errorf("checks, reports, used = %d, %d, %d; want the denial to report the batch and re-read the quota once", checks, reports, used)
+	}
+	mu.Unlock()
+
+	if !pc.Allow(4) || pc.Allow(1) {
+		t.Error("Allow() should admit exactly the remaining 4 units")
+	}
+	if err := pc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := pc.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if pc.Allow(1) {
+		t.Error("Allow() after Close() should be denied")
+	}
+	mu.Lock()
+	if used != 10 || reports != 2 {
+		t.Errorf("used = %d after %d reports, want all 10 units reported by Close", used, reports)
+	}
+	mu.Unlock()
+}
+
+func TestClient_PrepareConsumeFails(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	if _, err := c.PrepareConsume(""); err == nil {
+		t.Error("PrepareConsume() without a reachable server should fail")
+	}
+}
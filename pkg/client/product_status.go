@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// productFeatureID is the feature ID under which product-level limits are
+// cached, reported and, on servers without the product status endpoint,
+// checked
+const productFeatureID = "__product__"
+
+// productStatusResponse is the response of GET /api/v1/sdk/product/status
+type productStatusResponse struct {
+	ProductID string `json:"product_id"`
+	Enabled   bool   `json:"enabled"`
+	Reason    string `json:"reason"`
+	Limits    struct {
+		MaxTPS         float64 `json:"max_tps,omitempty"`
+		MaxCapacity    int     `json:"max_capacity,omitempty"`
+		MaxConcurrency int     `json:"max_concurrency,omitempty"`
+		Scope          string  `json:"scope,omitempty"`
+
+		RateLimits []config.RateLimit    `json:"rate_limits,omitempty"`
+		Schedules  []config.ScheduleRule `json:"schedules,omitempty"`
+	} `json:"limits"`
+	Quota *QuotaInfo `json:"quota,omitempty"`
+}
+
+// ProductStatus returns the product-level limits and quota used by the
+// zero-intrusion API (Consume, CheckTPS, CheckCapacity, AcquireSlot).
+// Results are cached like feature checks.
+func (c *Client) ProductStatus() (*FeatureStatus, error) {
+	return c.checkProductLimits()
+}
+
+// queryProductStatus fetches every product limit and the product quota in
+// one call. Servers that predate the endpoint answer 404; the client then
+// falls back to the "__product__" feature check for its lifetime.
+func (c *Client) queryProductStatus() (*FeatureStatus, error) {
+	if c.grpcTransport() != nil {
+		return c.productStatusGRPC()
+	}
+
+	url := fmt.Sprintf("%s/api/v1/sdk/product/status", c.baseURL)
+
+	resp, err := c.doWithRetry(withBreakerFeature(context.Background(), productFeatureID), "ProductStatus", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "GET", url, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		debugLogf("Product status endpoint not available, falling back to %s feature check", productFeatureID)
+		c.productStatusUnsupported.Store(true)
+		return c.queryFeature(productFeatureID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("product status failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+	if err := c.verifyResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result productStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &FeatureStatus{
+		Enabled:        result.Enabled,
+		Reason:         result.Reason,
+		Quota:          result.Quota,
+		MaxCapacity:    result.Limits.MaxCapacity,
+		MaxTPS:         result.Limits.MaxTPS,
+		MaxConcurrency: result.Limits.MaxConcurrency,
+		LimitScope:     result.Limits.Scope,
+		RateLimits:     result.Limits.RateLimits,
+		Schedules:      result.Limits.Schedules,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_ProductStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   bool // server implements /api/v1/sdk/product/status
+		wantLegacy int32
+	}{
+		{"product status endpoint", true, 0},
+		{"legacy fallback", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var productCalls, legacyCalls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/sdk/product/status":
+					productCalls.Add(1)
+					if !tt.endpoint {
+						http.NotFound(w, r)
+						return
+					}
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{
+						"product_id": "test-app",
+						"enabled":    true,
+						"limits":     map[string]interface{}{"max_tps": 50, "max_capacity": 10, "max_concurrency": 4},
+						"quota":      map[string]interface{}{"limit": 1000, "used": 10, "remaining": 990},
+					})
+				case "/api/v1/sdk/features/__product__/check":
+					legacyCalls.Add(1)
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{
+						"enabled":         true,
+						"max_tps":         50,
+						"max_capacity":    10,
+						"max_concurrency": 4,
+						"quota_info":      map[string]interface{}{"limit": 1000, "used": 10, "remaining": 990},
+					})
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv.URL)
+			status, err := c.ProductStatus()
+			if err != nil {
+				t.Fatalf("ProductStatus() error = %v", err)
+			}
+			if !status.Enabled || status.MaxTPS != 50 || status.MaxCapacity != 10 || status.MaxConcurrency != 4 {
+				t.Errorf("ProductStatus() = %+v", status)
+			}
+			if status.Quota == nil || status.Quota.Remaining != 990 {
+				t.Errorf("ProductStatus().Quota = %+v", status.Quota)
+			}
+
+			// A refresh does not probe the missing endpoint again
+			c.ClearCache()
+			if _, err := c.ProductStatus(); err != nil {
+				t.Fatalf("ProductStatus() error = %v", err)
+			}
+			wantProduct := int32(2)
+			if !tt.endpoint {
+				wantProduct = 1
+			}
+			if n := productCalls.Load(); n != wantProduct {
+				t.Errorf("product status calls = %d, want %d", n, wantProduct)
+			}
+			if n := legacyCalls.Load(); n != tt.wantLegacy*2 {
+				t.Errorf("legacy calls = %d, want %d", n, tt.wantLegacy*2)
+			}
+		})
+	}
+}
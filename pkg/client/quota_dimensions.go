@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownDimension is returned by ConsumeDimension when the feature's
+// status carries no quota for the dimension
+var ErrUnknownDimension = errors.New("unknown quota dimension")
+
+// QuotaFor returns the quota of a dimension (e.g. "rows_per_day"), or nil.
+// The dimension "" is the feature's single quota, Quota.
+func (s *FeatureStatus) QuotaFor(dimension string) *QuotaInfo {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Quotas {
+		if s.Quotas[i].Dimension == dimension {
+			return &s.Quotas[i]
+		}
+	}
+	if s.Quota != nil && s.Quota.Dimension == dimension {
+		return s.Quota
+	}
+	return nil
+}
+
+// dimensionUsage counts the units consumed per feature and dimension since
+// the quota was last reported by the server. A new QuotaInfo from a fresh
+// check restarts the count.
+type dimensionUsage struct {
+	mu   sync.Mutex
+	used map[dimensionKey]*dimensionCount
+}
+
+type dimensionKey struct {
+	featureID, dimension string
+}
+
+type dimensionCount struct {
+	base *QuotaInfo // quota the count applies to
+	used int
+}
+
+// reserve consumes n units of quota if they remain, and returns the
+// remaining quota after the attempt
+func (d *dimensionUsage) reserve(featureID, dimension string, quota *QuotaInfo, n int) (bool, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := dimensionKey{featureID, dimension}
+	count := d.used[key]
+	if count == nil || count.base != quota {
+		count = &dimensionCount{base: quota}
+		if d.used == nil {
+			d.used = make(map[dimensionKey]*dimensionCount)
+		}
+		d.used[key] = count
+	}
+	remaining := quota.Remaining - count.used
+	if remaining < n {
+		if remaining < 0 {
+			remaining = 0
+		}
+		return false, remaining
+	}
+	count.used += n
+	return true, remaining - n
+}
+
+// release returns n units reserved against quota, after a failed report
+func (d *dimensionUsage) release(featureID, dimension string, quota *QuotaInfo, n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if count := d.used[dimensionKey{featureID, dimension}]; count != nil && count.base == quota {
+		count.used -= n
+	}
+}
+
+// ConsumeDimension consumes n units of one quota dimension of a feature
+// metered along several (e.g. "rows_per_day" and "jobs_per_day", see
+// FeatureStatus.Quotas). Each dimension is enforced independently: n is
+// checked against the dimension's remaining quota, as reported by the
+// latest feature check less the units consumed since, and reported to the
+// LCC server as usage of that dimension.
+//
+// Returns whether the units were granted and the dimension's remaining
+// quota. A disabled feature returns a *FeatureNotLicensedError, and a
+// dimension the feature has no quota for ErrUnknownDimension.
+func (c *Client) ConsumeDimension(featureID, dimension string, n int) (bool, int, error) {
+	status, err := c.CheckFeature(featureID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !status.Enabled {
+		return false, 0, NewFeatureError(featureID, status, nil)
+	}
+	quota := status.QuotaFor(dimension)
+	if quota == nil {
+		return false, 0, fmt.Errorf("%w: %s of feature %s", ErrUnknownDimension, dimension, featureID)
+	}
+
+	allowed, remaining := c.dimensions.reserve(featureID, dimension, quota, n)
+	if !allowed {
+		c.compliance.use(featureID, dimension, 0, true)
+		return false, remaining, fmt.Errorf("quota exceeded: %s of feature %s", dimension, featureID)
+	}
+	if c.OfflineMode() {
+		c.compliance.use(featureID, dimension, float64(n), false)
+		return true, remaining, nil
+	}
+	if err := c.sendUsage(featureID, dimension, "", n); err != nil {
+		c.dimensions.release(featureID, dimension, quota, n)
+		return false, 0, err
+	}
+	c.compliance.use(featureID, dimension, float64(n), false)
+	return true, remaining, nil
+}
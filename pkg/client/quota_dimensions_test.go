@@ -0,0 +1,118 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClient_ConsumeDimension(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported []string
+		failing  bool
+		checks   int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v1/sdk/features/"):
+			checks++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"feature_id": "etl",
+				"enabled":    true,
+				"quotas": []QuotaInfo{
+					{Dimension: "rows_per_day", Limit: 1000, Used: 900, Remaining: 100},
+					{Dimension: "jobs_per_day", Limit: 10, Used: 8, Remaining: 2},
+				},
+			})
+		case r.URL.Path == "/api/v1/sdk/usage":
+			if failing {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			var body struct {
+				Dimension string `json:"dimension"`
+				Count     int    `json:"count"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			reported = append(reported, fmt.Sprintf("%s=%d", body.Dimension, body.Count))
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	tests := []struct {
+		dimension string
+		n         int
+		allowed   bool
+		remaining int
+	}{
+		{"rows_per_day", 60, true, 40},
+		{"rows_per_day", 50, false, 40}, // over the rows quota...
+		{"jobs_per_day", 1, true, 1},    // ...jobs still granted
+		{"jobs_per_day", 2, false, 1},
+		{"rows_per_day", 40, true, 0},
+	}
+	for i, tt := range tests {
+		allowed, remaining, err := c.ConsumeDimension("etl", tt.dimension, tt.n)
+		if allowed != tt.allowed || remaining != tt.remaining {
+			t.Errorf("#%d ConsumeDimension(%s, %d) = %v, %d, %v; want %v, %d", i, tt.dimension, tt.n, allowed, remaining, err, tt.allowed, tt.remaining)
+		}
+		if !allowed && err == nil {
+			t.Errorf("#%d ConsumeDimension() denied without an error", i)
+		}
+	}
+
+	mu.Lock()
+	if want := []string{"rows_per_day=60", "jobs_per_day=1", "rows_per_day=40"}; !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported usage = %v, want %v", reported, want)
+	}
+	if checks != 1 {
+		t.Errorf("feature checked %d times, want 1 (cached)", checks)
+	}
+	failing = true
+	mu.Unlock()
+
+	// A failed report gives the units back
+	if allowed, _, err := c.ConsumeDimension("etl", "jobs_per_day", 1); allowed || err == nil {
+		t.Errorf("ConsumeDimension() with a failing server = %v, %v", allowed, err)
+	}
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	if allowed, remaining, err := c.ConsumeDimension("etl", "jobs_per_day", 1); !allowed || remaining != 0 {
+		t.Errorf("ConsumeDimension() after a failed report = %v, %d, %v", allowed, remaining, err)
+	}
+
+	if _, _, err := c.ConsumeDimension("etl", "bytes_per_day", 1); !errors.Is(err, ErrUnknownDimension) {
+		t.Errorf("ConsumeDimension() of an unknown dimension error = %v", err)
+	}
+}
+
+func TestFeatureStatus_QuotaFor(t *testing.T) {
+	single := &QuotaInfo{Limit: 5, Remaining: 5}
+	status := &FeatureStatus{
+		Quota:  single,
+		Quotas: []QuotaInfo{{Dimension: "rows", Limit: 10}, {Dimension: "jobs", Limit: 2}},
+	}
+	if q := status.QuotaFor("jobs"); q == nil || q.Limit != 2 {
+		t.Errorf("QuotaFor(jobs) = %+v", q)
+	}
+	if q := status.QuotaFor(""); q != single {
+		t.Errorf("QuotaFor(\"\") = %+v, want the single quota", q)
+	}
+	if q := status.QuotaFor("bytes"); q != nil {
+		t.Errorf("QuotaFor(bytes) = %+v, want nil", q)
+	}
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultQuotaLeaseTTL is how long a lease is used when the server does not
+// say when it expires
+const defaultQuotaLeaseTTL = time.Minute
+
+// errNoLease makes Consume fall back to a server check per call
+var errNoLease = errors.New("no quota lease available")
+
+// errLeaseGone is returned when the server no longer knows a lease
+var errLeaseGone = errors.New("quota lease expired or revoked")
+
+// QuotaLease is a block of product quota reserved by this instance. Consume
+// draws it down locally and the client reconciles the units used with the
+// server on every flush and when the lease is released.
+type QuotaLease struct {
+	// ID identifies the lease on the server
+	ID string
+
+	// Granted is the number of units reserved
+	Granted int
+
+	// Used is the number of units consumed so far
+	Used int
+
+	// ExpiresAt is when the server reclaims the lease
+	ExpiresAt time.Time
+}
+
+// Remaining returns the units still available in the lease
+func (l QuotaLease) Remaining() int {
+	if l.Used >= l.Granted {
+		return 0
+	}
+	return l.Granted - l.Used
+}
+
+// quotaLeaseResponse is the response of POST /api/v1/sdk/quota/lease
+type quotaLeaseResponse struct {
+	LeaseID   string `json:"lease_id"`
+	Granted   int    `json:"granted"`
+	ExpiresAt int64  `json:"expires_at"`
+	Reason    string `json:"reason,omitempty"`
+	ResetAt   int64  `json:"reset_at,omitempty"`
+}
+
+// quotaLeaser holds the current lease. mu serializes consumption and lease
+// acquisition; reconciliation sends cumulative usage, so it runs without
+// holding mu.
+type quotaLeaser struct {
+	units       atomic.Int64 // units reserved per lease; 0 disables leasing
+	unsupported atomic.Bool  // server lacks the lease endpoint
+
+	mu         sync.Mutex
+	lease      *QuotaLease
+	reconciled int // lease.Used last acknowledged by the server
+}
+
+// SetQuotaLease makes Consume reserve product quota in blocks of units and
+// draw them down locally, instead of checking and reporting usage on the
+// LCC server for every call. Used units are reconciled with each heartbeat
+// and FlushUsage, and the lease is released when it runs out, expires or
+// the client is closed. 0 disables leasing and releases the current lease.
+//
+// Leasing requires server support; servers without the lease endpoint are
+// detected and Consume keeps checking per call.
+func (c *Client) SetQuotaLease(units int) {
+	if units < 0 {
+		units = 0
+	}
+	c.leases.units.Store(int64(units))
+	if units == 0 {
+		c.releaseLease(context.Background())
+	}
+}
+
+// QuotaLease returns the current lease. It returns false if leasing is
+// disabled or no unexpired lease is held.
+func (c *Client) QuotaLease() (QuotaLease, bool) {
+	q := c.leases
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.lease == nil || !time.Now().Before(q.lease.ExpiresAt) {
+		return QuotaLease{}, false
+	}
+	return *q.lease, true
+}
+
+// consumeLeased takes amount units from the current lease, acquiring a new
+// one when it is exhausted or expired. remaining is the units left in the
+// lease. It returns errNoLease when leasing is unavailable, so the caller
+// checks with the server instead.
+func (c *Client) consumeLeased(amount int) (bool, int, error) {
+	q := c.leases
+	units := int(q.units.Load())
+	if units <= 0 || q.unsupported.Load() || c.grpcTransport() != nil {
+		return false, 0, errNoLease
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if l := q.lease; l != nil && (!time.Now().Before(l.ExpiresAt) || l.Remaining() < amount) {
+		c.settleLeaseLocked(context.Background())
+	}
+
+	if q.lease == nil {
+		resp, err := c.acquireLease(max(units, amount))
+		if err != nil {
+			debugLogf("Consume: quota lease unavailable, checking per call: %v", err)
+			return false, 0, errNoLease
+		}
+		if resp.Granted <= 0 {
+			reason := resp.Reason
+			if reason == "" {
+				reason = ReasonQuotaExhausted
+			}
+			c.noteQuota(productFeatureID, &QuotaInfo{ResetAt: resp.ResetAt}, 0)
+			return false, 0, fmt.Errorf("quota exceeded: %s", reason)
+		}
+
+		expiresAt := time.Unix(resp.ExpiresAt, 0)
+		if resp.ExpiresAt <= 0 {
+			expiresAt = time.Now().Add(defaultQuotaLeaseTTL)
+		}
+		q.lease = &QuotaLease{ID: resp.LeaseID, Granted: resp.Granted, ExpiresAt: expiresAt}
+		q.reconciled = 0
+		debugLogf("Quota lease %s: %d units until %s", resp.LeaseID, resp.Granted, expiresAt.Format(time.RFC3339))
+	}
+
+	// A partial grant smaller than amount stays leased for smaller calls
+	if q.lease.Remaining() < amount {
+		return false, q.lease.Remaining(), fmt.Errorf("quota exceeded: %s", ReasonQuotaExhausted)
+	}
+	q.lease.Used += amount
+	if c.localQuota != nil {
+		c.localQuota.record(time.Now(), amount)
+	}
+	return true, q.lease.Remaining(), nil
+}
+
+// settleLeaseLocked releases the current lease, reporting its final usage.
+// A lease that could not be released is settled by the server when it
+// expires. Caller holds c.leases.mu.
+func (c *Client) settleLeaseLocked(ctx context.Context) {
+	q := c.leases
+	l := q.lease
+	q.lease = nil
+	if l == nil {
+		return
+	}
+	if err := c.reconcileLease(ctx, l.ID, l.Used, true); err != nil && !errors.Is(err, errLeaseGone) {
+		debugLogf("Quota lease %s: release failed, server settles it at expiry: %v", l.ID, err)
+	}
+}
+
+// releaseLease releases the current lease, if any
+func (c *Client) releaseLease(ctx context.Context) {
+	c.leases.mu.Lock()
+	defer c.leases.mu.Unlock()
+	c.settleLeaseLocked(ctx)
+}
+
+// leaseUnreconciled reports whether flushLease has units to report
+func (c *Client) leaseUnreconciled() bool {
+	q := c.leases
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.lease != nil && q.lease.Used > q.reconciled
+}
+
+// flushLease reports units used from the current lease since the last
+// reconciliation, releasing it instead if it has expired
+func (c *Client) flushLease(ctx context.Context) error {
+	q := c.leases
+	q.mu.Lock()
+	l := q.lease
+	if l == nil {
+		q.mu.Unlock()
+		return nil
+	}
+	if !time.Now().Before(l.ExpiresAt) {
+		c.settleLeaseLocked(ctx)
+		q.mu.Unlock()
+		return nil
+	}
+	id, used := l.ID, l.Used
+	pending := used > q.reconciled
+	q.mu.Unlock()
+
+	if !pending {
+		return nil
+	}
+	err := c.reconcileLease(ctx, id, used, false)
+	if err != nil && !errors.Is(err, errLeaseGone) {
+		return fmt.Errorf("quota lease %s: %w", id, err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.lease == nil || q.lease.ID != id {
+		return nil
+	}
+	if err != nil {
+		// The server settled the lease early; the next Consume leases anew
+		debugLogf("Quota lease %s: %v", id, err)
+		q.lease = nil
+		return nil
+	}
+	if used > q.reconciled {
+		q.reconciled = used
+	}
+	return nil
+}
+
+// acquireLease reserves up to units of product quota. Servers that predate
+// the endpoint answer 404; leasing is then disabled for the client lifetime.
+func (c *Client) acquireLease(units int) (*quotaLeaseResponse, error) {
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"instance_id": c.instanceID,
+		"units":       units,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.doWithRetry(context.Background(), "QuotaLease", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", c.baseURL+"/api/v1/sdk/quota/lease", bodyBytes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		debugLogf("Quota lease endpoint not available, checking quota per call")
+		c.leases.unsupported.Store(true)
+		return nil, errNoLease
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("quota lease failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+	if err := c.verifyResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result quotaLeaseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Granted > 0 && result.LeaseID == "" {
+		return nil, fmt.Errorf("quota lease granted without a lease_id")
+	}
+	return &result, nil
+}
+
+// reconcileLease reports the cumulative units used from a lease, and with
+// release returns the unused remainder to the server. Usage is cumulative,
+// so a retried or repeated report is not counted twice. A lease the server
+// no longer knows (404 or 410) has already been settled: errLeaseGone.
+func (c *Client) reconcileLease(ctx context.Context, id string, used int, release bool) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"instance_id": c.instanceID,
+		"used":        used,
+		"release":     release,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/sdk/quota/lease/%s/reconcile", c.baseURL, url.PathEscape(id))
+	resp, err := c.doWithRetry(ctx, "QuotaLeaseReconcile", func(ctx context.Context) (*http.Request, error) {
+		return c.newSignedRequest(ctx, "POST", endpoint, bodyBytes)
+	})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound, http.StatusGone:
+		return errLeaseGone
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("quota lease reconcile failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// leaseServer grants leases of up to grant units and records reconciliations
+type leaseServer struct {
+	grant    int
+	resetAt  int64
+	endpoint bool // implements /api/v1/sdk/quota/lease
+
+	mu         sync.Mutex
+	leases     int
+	reconciles []map[string]interface{}
+	perCall    int // product status and usage requests
+}
+
+func (s *leaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/v1/sdk/quota/lease" && s.endpoint:
+		var body struct {
+			Units int `json:"units"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.leases++
+		if s.grant == 0 {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"granted": 0, "reset_at": s.resetAt})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":   "l" + strings.Repeat("x", s.leases),
+			"granted":    min(body.Units, s.grant),
+			"expires_at": time.Now().Add(time.Hour).Unix(),
+		})
+	case strings.HasPrefix(r.URL.Path, "/api/v1/sdk/quota/lease/") && s.endpoint:
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body["lease_id"] = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/quota/lease/"), "/reconcile")
+		s.reconciles = append(s.reconciles, body)
+	case r.URL.Path == "/api/v1/sdk/product/status":
+		s.perCall++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	case r.URL.Path == "/api/v1/sdk/usage":
+		s.perCall++
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *leaseServer) lastReconcile() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.reconciles) == 0 {
+		return nil
+	}
+	return s.reconciles[len(s.reconciles)-1]
+}
+
+func TestQuotaLease(t *testing.T) {
+	ls := &leaseServer{grant: 100, endpoint: true}
+	srv := httptest.NewServer(ls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetQuotaLease(100)
+
+	for i := 0; i < 50; i++ {
+		if allowed, _, err := c.Consume(1); !allowed {
+			t.Fatalf("Consume() #%d error = %v", i+1, err)
+		}
+	}
+	allowed, remaining, err := c.Consume(10)
+	if !allowed || remaining != 40 || err != nil {
+		t.Errorf("Consume(10) = %v, %d, %v; want true, 40 left in the lease", allowed, remaining, err)
+	}
+	if ls.leases != 1 || ls.perCall != 0 {
+		t.Errorf("server saw %d leases and %d per-call requests, want 1 and 0", ls.leases, ls.perCall)
+	}
+
+	lease, ok := c.QuotaLease()
+	if !ok || lease.Granted != 100 || lease.Used != 60 || lease.Remaining() != 40 {
+		t.Errorf("QuotaLease() = %+v, %v", lease, ok)
+	}
+
+	// Flush reconciles cumulative usage and keeps the lease
+	if err := c.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+	if got := ls.lastReconcile(); got["used"] != 60.0 || got["release"] != false {
+		t.Errorf("reconcile = %v, want used 60 without release", got)
+	}
+	_ = c.FlushUsage()
+	if n := len(ls.reconciles); n != 1 {
+		t.Errorf("FlushUsage() without new usage sent %d reconciles, want 1", n)
+	}
+
+	// A call larger than the lease remainder releases it and leases anew
+	if allowed, _, _ := c.Consume(41); !allowed {
+		t.Fatal("Consume(41) should be allowed from a new lease")
+	}
+	if got := ls.lastReconcile(); got["used"] != 60.0 || got["release"] != true || got["lease_id"] != "lx" {
+		t.Errorf("release = %v, want lease lx released with 60 used", got)
+	}
+	if ls.leases != 2 {
+		t.Errorf("server saw %d leases, want 2", ls.leases)
+	}
+
+	// Close releases the current lease
+	c.Close()
+	if got := ls.lastReconcile(); got["used"] != 41.0 || got["release"] != true || got["lease_id"] != "lxx" {
+		t.Errorf("release on Close = %v, want lease lxx released with 41 used", got)
+	}
+	if _, ok := c.QuotaLease(); ok {
+		t.Error("QuotaLease() after Close should report no lease")
+	}
+}
+
+func TestQuotaLease_Exhausted(t *testing.T) {
+	ls := &leaseServer{endpoint: true, resetAt: time.Now().Add(time.Hour).Unix()}
+	srv := httptest.NewServer(ls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetQuotaLease(10)
+
+	if allowed, _, err := c.Consume(1); allowed || err == nil || !strings.Contains(err.Error(), ReasonQuotaExhausted) {
+		t.Errorf("Consume() with nothing granted = %v, %v; want quota exhausted", allowed, err)
+	}
+	// Denied locally until the reset, without asking for another lease
+	if allowed, _, _ := c.Consume(1); allowed || ls.leases != 1 {
+		t.Errorf("Consume() while exhausted: allowed = %v, leases = %d", allowed, ls.leases)
+	}
+}
+
+func TestQuotaLease_Unsupported(t *testing.T) {
+	ls := &leaseServer{}
+	srv := httptest.NewServer(ls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetQuotaLease(10)
+
+	for i := 0; i < 2; i++ {
+		if allowed, _, err := c.Consume(1); !allowed {
+			t.Fatalf("Consume() error = %v", err)
+		}
+	}
+	if !c.leases.unsupported.Load() {
+		t.Error("404 from the lease endpoint should disable leasing")
+	}
+	// One product status check (cached) and two usage reports
+	if ls.perCall != 3 {
+		t.Errorf("per-call requests = %d, want 3", ls.perCall)
+	}
+}
+
+func TestQuotaLease_Expired(t *testing.T) {
+	ls := &leaseServer{grant: 10, endpoint: true}
+	srv := httptest.NewServer(ls)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetQuotaLease(10)
+	_, _, _ = c.Consume(3)
+
+	c.leases.mu.Lock()
+	c.leases.lease.ExpiresAt = time.Now().Add(-time.Second)
+	c.leases.mu.Unlock()
+
+	if _, ok := c.QuotaLease(); ok {
+		t.Error("QuotaLease() should not report an expired lease")
+	}
+	if err := c.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+	if got := ls.lastReconcile(); got["used"] != 3.0 || got["release"] != true {
+		t.Errorf("reconcile of expired lease = %v, want release with 3 used", got)
+	}
+
+	// Disabling releases nothing further and stops leasing
+	c.SetQuotaLease(0)
+	_, _, _ = c.Consume(1)
+	if ls.leases != 1 || ls.perCall != 2 {
+		t.Errorf("after SetQuotaLease(0): leases = %d, per-call = %d; want 1, 2", ls.leases, ls.perCall)
+	}
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/license"
+)
+
+// ReasonPoolExhausted is the reason a feature is denied when the quota
+// pool it shares is used up
+const ReasonPoolExhausted = "quota_pool_exhausted"
+
+// QuotaPoolStatus is the local accounting of a quota pool
+type QuotaPoolStatus struct {
+	Name     string
+	Features []string
+
+	// Quota is the state of the pool's local quota, or nil if the LCC
+	// server enforces the pool
+	Quota *QuotaInfo
+
+	// Used counts the units reported for the pool's features since the
+	// client started, in total and per feature
+	Used          int
+	UsedByFeature map[string]int
+}
+
+// quotaPool is one pool's membership and accounting
+type quotaPool struct {
+	name      string
+	features  []string
+	cfg       *config.ProductQuotaConfig // nil if enforced by the server
+	quota     *localQuota
+	used      int
+	byFeature map[string]int
+}
+
+// quotaPools tracks the pools declared by the manifest, the offline
+// license and check responses. License pools take precedence over manifest
+// pools of the same name or sharing a feature.
+type quotaPools struct {
+	mu        sync.Mutex
+	manifest  map[string]config.QuotaPool
+	license   []license.Pool
+	learned   map[string]string // feature ID -> pool named by the server
+	pools     map[string]*quotaPool
+	byFeature map[string]*quotaPool
+}
+
+func newQuotaPools() *quotaPools {
+	return &quotaPools{
+		learned:   make(map[string]string),
+		pools:     make(map[string]*quotaPool),
+		byFeature: make(map[string]*quotaPool),
+	}
+}
+
+// setManifest declares the manifest's pools
+func (p *quotaPools) setManifest(pools map[string]config.QuotaPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.manifest = pools
+	p.rebuildLocked()
+}
+
+// setLicense declares the offline license's pools
+func (p *quotaPools) setLicense(pools []license.Pool) error {
+	for _, pool := range pools {
+		if _, err := newLocalQuota(pool.Quota.Config()); err != nil {
+			return fmt.Errorf("license pool %s: %w", pool.Name, err)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.license = pools
+	p.rebuildLocked()
+	return nil
+}
+
+// learn records that the server accounts featureID in the named pool. Pools
+// declared locally take precedence.
+func (p *quotaPools) learn(featureID, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.learned[featureID] == name {
+		return
+	}
+	p.learned[featureID] = name
+	p.rebuildLocked()
+}
+
+// rebuildLocked recomputes pool membership from the declarations. Pools
+// keep their usage, and their local quota window if its config is
+// unchanged. Caller holds p.mu.
+func (p *quotaPools) rebuildLocked() {
+	prev := p.pools
+	p.pools = make(map[string]*quotaPool)
+	p.byFeature = make(map[string]*quotaPool)
+
+	declare := func(name string, features []string, cfg *config.ProductQuotaConfig) {
+		if _, ok := p.pools[name]; ok {
+			return
+		}
+		pool := &quotaPool{name: name, cfg: cfg, byFeature: make(map[string]int)}
+		if old, ok := prev[name]; ok {
+			pool.used, pool.byFeature = old.used, old.byFeature
+			if cfg != nil && old.cfg != nil && *cfg == *old.cfg {
+				pool.quota = old.quota
+			}
+		}
+		if cfg != nil && pool.quota == nil {
+			q, err := newLocalQuota(cfg)
+			if err != nil {
+				debugLogf("WARNING: quota pool %s is not enforced locally: %v", name, err)
+				pool.cfg = nil
+			}
+			pool.quota = q
+		}
+		for _, id := range features {
+			if _, taken := p.byFeature[id]; !taken {
+				pool.features = append(pool.features, id)
+				p.byFeature[id] = pool
+			}
+		}
+		p.pools[name] = pool
+	}
+
+	for _, pool := range p.license {
+		declare(pool.Name, pool.Features, pool.Quota.Config())
+	}
+	names := make([]string, 0, len(p.manifest))
+	for name := range p.manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		declare(name, p.manifest[name].Features, p.manifest[name].Quota)
+	}
+	for _, id := range sortedFeatureIDs(p.learned) {
+		if _, declared := p.byFeature[id]; declared {
+			continue
+		}
+		name := p.learned[id]
+		if pool, ok := p.pools[name]; ok {
+			pool.features = append(pool.features, id)
+			p.byFeature[id] = pool
+			continue
+		}
+		declare(name, []string{id}, nil)
+	}
+}
+
+// sortedFeatureIDs returns the keys of m in sorted order
+func sortedFeatureIDs(m map[string]string) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// record accounts n units of featureID against its pool and returns the
+// pool, or nil if the feature is not pooled
+func (p *quotaPools) record(featureID string, n int, now time.Time) *QuotaPoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pool, ok := p.byFeature[featureID]
+	if !ok {
+		return nil
+	}
+	pool.used += n
+	pool.byFeature[featureID] += n
+	if pool.quota != nil {
+		pool.quota.record(now, n)
+	}
+	return pool.statusLocked(now)
+}
+
+// poolOf returns the name of featureID's pool, or ""
+func (p *quotaPools) poolOf(featureID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pool, ok := p.byFeature[featureID]; ok {
+		return pool.name
+	}
+	return ""
+}
+
+// status returns the pool of featureID, or nil if the feature is not pooled
+func (p *quotaPools) status(featureID string, now time.Time) *QuotaPoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pool, ok := p.byFeature[featureID]
+	if !ok {
+		return nil
+	}
+	return pool.statusLocked(now)
+}
+
+// all returns every pool, sorted by name
+func (p *quotaPools) all(now time.Time) []QuotaPoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]QuotaPoolStatus, 0, len(p.pools))
+	for _, pool := range p.pools {
+		out = append(out, *pool.statusLocked(now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// statusLocked snapshots the pool. Caller holds the pools' lock.
+func (pool *quotaPool) statusLocked(now time.Time) *QuotaPoolStatus {
+	s := &QuotaPoolStatus{
+		Name:          pool.name,
+		Features:      append([]string(nil), pool.features...),
+		Used:          pool.used,
+		UsedByFeature: make(map[string]int, len(pool.byFeature)),
+	}
+	for id, n := range pool.byFeature {
+		s.UsedByFeature[id] = n
+	}
+	if pool.quota != nil {
+		s.Quota = pool.quota.snapshot(now)
+	}
+	return s
+}
+
+// QuotaPool returns the local accounting of the named quota pool
+func (c *Client) QuotaPool(name string) (QuotaPoolStatus, bool) {
+	for _, pool := range c.pools.all(time.Now()) {
+		if pool.Name == name {
+			return pool, true
+		}
+	}
+	return QuotaPoolStatus{}, false
+}
+
+// QuotaPools returns every quota pool known to the client: those declared
+// in the manifest (Manifest.QuotaPools), in the offline license, and those
+// named by the server in feature checks
+func (c *Client) QuotaPools() []QuotaPoolStatus {
+	return c.pools.all(time.Now())
+}
+
+// applyQuotaPool reports a pooled feature's quota as its pool's. A pool
+// enforced locally denies its features once used up. The status is copied,
+// never modified.
+func (c *Client) applyQuotaPool(featureID string, status *FeatureStatus, now time.Time) *FeatureStatus {
+	if status.QuotaPool != "" {
+		c.pools.learn(featureID, status.QuotaPool)
+	}
+	pool := c.pools.status(featureID, now)
+	if pool == nil {
+		return status
+	}
+
+	pooled := *status
+	pooled.QuotaPool = pool.Name
+	if pool.Quota != nil {
+		pooled.Quota = pool.Quota
+		if pooled.Enabled && pool.Quota.Remaining <= 0 {
+			pooled.Enabled = false
+			pooled.Reason = ReasonPoolExhausted
+		}
+	}
+	return &pooled
+}
+
+// recordPoolUsage accounts reported usage against the feature's pool and
+// returns the pool name, or "" if the feature is not pooled. The cached
+// statuses of the other members of a server-enforced pool are expired, as
+// their quota changed too.
+func (c *Client) recordPoolUsage(featureID string, n int) string {
+	pool := c.pools.record(featureID, n, time.Now())
+	if pool == nil {
+		return ""
+	}
+	if pool.Quota == nil {
+		for _, id := range pool.Features {
+			if id != featureID {
+				c.cache.expire(id)
+			}
+		}
+	}
+	return pool.Name
+}
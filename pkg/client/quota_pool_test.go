@@ -0,0 +1,133 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_QuotaPoolLocal(t *testing.T) {
+	var mu sync.Mutex
+	var reports []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/usage" {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			reports = append(reports, body)
+			mu.Unlock()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetManifest(&config.Manifest{
+		QuotaPools: map[string]config.QuotaPool{
+			"exports": {
+				Features: []string{"export_pdf", "export_excel"},
+				Quota:    &config.ProductQuotaConfig{Max: 3, Window: "1h"},
+			},
+		},
+	})
+
+	status, err := c.CheckFeature("export_pdf")
+	if err != nil {
+		t.Fatalf("CheckFeature() error = %v", err)
+	}
+	if !status.Enabled || status.QuotaPool != "exports" || status.Quota == nil || status.Quota.Remaining != 3 {
+		t.Errorf("CheckFeature(export_pdf) = %+v", status)
+	}
+
+	if err := c.ReportUsage("export_pdf", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReportUsage("export_excel", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both features drew from the one pool
+	for _, id := range []string{"export_pdf", "export_excel"} {
+		status, err := c.CheckFeature(id)
+		if err != nil {
+			t.Fatalf("CheckFeature(%s) error = %v", id, err)
+		}
+		if status.Enabled || status.Reason != ReasonPoolExhausted || status.Quota.Remaining != 0 {
+			t.Errorf("CheckFeature(%s) after the pool ran out = %+v", id, status)
+		}
+	}
+	if status, _ := c.CheckFeature("reports"); !status.Enabled || status.QuotaPool != "" {
+		t.Errorf("CheckFeature(reports) outside the pool = %+v", status)
+	}
+
+	pool, ok := c.QuotaPool("exports")
+	if !ok {
+		t.Fatal("QuotaPool(exports) not found")
+	}
+	want := map[string]int{"export_pdf": 2, "export_excel": 1}
+	if pool.Used != 3 || !reflect.DeepEqual(pool.UsedByFeature, want) {
+		t.Errorf("QuotaPool(exports) used = %d %v, want 3 %v", pool.Used, pool.UsedByFeature, want)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 2 || reports[0]["quota_pool"] != "exports" || reports[1]["quota_pool"] != "exports" {
+		t.Errorf("usage reports = %v, want quota_pool exports", reports)
+	}
+}
+
+func TestClient_QuotaPoolFromServer(t *testing.T) {
+	var mu sync.Mutex
+	checks := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/usage" {
+			return
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		mu.Lock()
+		checks[id]++
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"feature_id": id,
+			"enabled":    true,
+			"quota_pool": "exports",
+			"quota_info": map[string]interface{}{"limit": 100, "used": 10, "remaining": 90},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	for _, id := range []string{"export_pdf", "export_excel"} {
+		if status, err := c.CheckFeature(id); err != nil || status.QuotaPool != "exports" {
+			t.Fatalf("CheckFeature(%s) = %+v, %v", id, status, err)
+		}
+	}
+	if err := c.ReportUsage("export_pdf", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sibling's cached quota is stale after usage of the pool
+	if _, err := c.CheckFeature("export_excel"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if checks["export_excel"] != 2 {
+		t.Errorf("export_excel checks = %d, want 2", checks["export_excel"])
+	}
+	mu.Unlock()
+
+	pools := c.QuotaPools()
+	if len(pools) != 1 || pools[0].Name != "exports" || pools[0].Quota != nil || pools[0].Used != 5 {
+		t.Errorf("QuotaPools() = %+v", pools)
+	}
+	if !reflect.DeepEqual(pools[0].Features, []string{"export_excel", "export_pdf"}) {
+		t.Errorf("pool features = %v", pools[0].Features)
+	}
+}
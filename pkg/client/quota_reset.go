@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ReasonQuotaExhausted is reported when a call is denied locally because the
+// quota window is known to be exhausted until its reset time.
+const ReasonQuotaExhausted = "quota_exhausted"
+
+// ResetTime returns the quota window reset time, or the zero time if the
+// server did not report one.
+func (q *QuotaInfo) ResetTime() time.Time {
+	if q == nil || q.ResetAt <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(q.ResetAt, 0)
+}
+
+// TimeUntilReset returns how long until the quota window resets.
+// Returns 0 if the reset time is unknown or already passed.
+func (q *QuotaInfo) TimeUntilReset() time.Duration {
+	reset := q.ResetTime()
+	if reset.IsZero() {
+		return 0
+	}
+	if d := time.Until(reset); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// QuotaStateChange reports a quota entering or leaving the exhausted state
+type QuotaStateChange struct {
+	// FeatureID is "__product__" for the product quota used by Consume
+	FeatureID string
+
+	// Exhausted is true when calls will be denied until ResetAt, and false
+	// once the quota is available again
+	Exhausted bool
+	ResetAt   time.Time
+}
+
+// quotaResetTracker remembers which features have exhausted their quota and
+// clears that state when the quota window resets.
+type quotaResetTracker struct {
+	mu        sync.Mutex
+	exhausted map[string]time.Time
+	timers    map[string]*time.Timer
+	available map[string]chan struct{} // closed when a feature leaves exhaustion
+	hooks     *hookDispatcher
+	handlers  []namedHook[func(featureID string)]
+	states    []namedHook[func(QuotaStateChange)]
+}
+
+func newQuotaResetTracker(hooks *hookDispatcher) *quotaResetTracker {
+	return &quotaResetTracker{
+		hooks:     hooks,
+		exhausted: make(map[string]time.Time),
+		timers:    make(map[string]*time.Timer),
+		available: make(map[string]chan struct{}),
+	}
+}
+
+// markExhausted records that featureID is exhausted until resetAt and
+// schedules onReset to run at that time. Re-marking with the same reset time
+// is a no-op; a feature that was not exhausted fires a state change.
+func (t *quotaResetTracker) markExhausted(featureID string, resetAt time.Time, onReset func(featureID string)) {
+	t.mu.Lock()
+	cur, wasExhausted := t.exhausted[featureID]
+	if wasExhausted && cur.Equal(resetAt) {
+		t.mu.Unlock()
+		return
+	}
+	if timer, ok := t.timers[featureID]; ok {
+		timer.Stop()
+	}
+
+	t.exhausted[featureID] = resetAt
+	t.timers[featureID] = time.AfterFunc(time.Until(resetAt), func() {
+		t.mu.Lock()
+		if cur, ok := t.exhausted[featureID]; !ok || !cur.Equal(resetAt) {
+			t.mu.Unlock()
+			return
+		}
+		t.leaveLocked(featureID)
+		t.mu.Unlock()
+
+		t.notifyState(QuotaStateChange{FeatureID: featureID})
+		onReset(featureID)
+	})
+	t.mu.Unlock()
+
+	if !wasExhausted {
+		t.notifyState(QuotaStateChange{FeatureID: featureID, Exhausted: true, ResetAt: resetAt})
+	}
+}
+
+// leaveLocked forgets featureID's exhaustion and wakes its waiters. Caller
+// holds t.mu.
+func (t *quotaResetTracker) leaveLocked(featureID string) {
+	delete(t.exhausted, featureID)
+	delete(t.timers, featureID)
+	if ch, ok := t.available[featureID]; ok {
+		close(ch)
+		delete(t.available, featureID)
+	}
+}
+
+// availableCh returns a channel closed when featureID leaves exhaustion, or
+// nil if it is not exhausted
+func (t *quotaResetTracker) availableCh(featureID string) <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resetAt, ok := t.exhausted[featureID]
+	if !ok || !time.Now().Before(resetAt) {
+		return nil
+	}
+	ch, ok := t.available[featureID]
+	if !ok {
+		ch = make(chan struct{})
+		t.available[featureID] = ch
+	}
+	return ch
+}
+
+// addStateHandler registers a state change callback
+func (t *quotaResetTracker) addStateHandler(fn func(QuotaStateChange)) {
+	h := namedHook[func(QuotaStateChange)]{name: t.hooks.name("OnQuotaStateChange"), fn: fn}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states = append(t.states, h)
+}
+
+// notifyState dispatches a state change to the registered callbacks
+func (t *quotaResetTracker) notifyState(change QuotaStateChange) {
+	t.mu.Lock()
+	handlers := t.states
+	t.mu.Unlock()
+
+	debugLogf("Quota for %s exhausted=%v", change.FeatureID, change.Exhausted)
+	for _, h := range handlers {
+		fn := h.fn
+		t.hooks.dispatch(h.name, func() { fn(change) })
+	}
+}
+
+// snapshot copies the exhaustion state for persistence
+func (t *quotaResetTracker) snapshot() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]time.Time, len(t.exhausted))
+	for id, resetAt := range t.exhausted {
+		out[id] = resetAt
+	}
+	return out
+}
+
+// exhaustedUntil returns the reset time if featureID is currently exhausted
+func (t *quotaResetTracker) exhaustedUntil(featureID string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resetAt, ok := t.exhausted[featureID]
+	if !ok || !time.Now().Before(resetAt) {
+		return time.Time{}, false
+	}
+	return resetAt, true
+}
+
+// addHandler registers a reset callback
+func (t *quotaResetTracker) addHandler(fn func(featureID string)) {
+	h := namedHook[func(featureID string)]{name: t.hooks.name("OnQuotaReset"), fn: fn}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, h)
+}
+
+// notify dispatches a reset to the registered callbacks
+func (t *quotaResetTracker) notify(featureID string) {
+	t.mu.Lock()
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	for _, h := range handlers {
+		fn := h.fn
+		t.hooks.dispatch(h.name, func() { fn(featureID) })
+	}
+}
+
+// stop cancels all pending reset timers and wakes waiters, which then find
+// the client closed
+func (t *quotaResetTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, timer := range t.timers {
+		timer.Stop()
+		delete(t.timers, id)
+	}
+	for id, ch := range t.available {
+		close(ch)
+		delete(t.available, id)
+	}
+}
+
+// clear forgets all exhaustion state, keeping registered callbacks. Each
+// feature that was exhausted fires a state change.
+func (t *quotaResetTracker) clear() {
+	t.mu.Lock()
+	var left []string
+	for _, timer := range t.timers {
+		timer.Stop()
+	}
+	for id := range t.exhausted {
+		left = append(left, id)
+		t.leaveLocked(id)
+	}
+	t.mu.Unlock()
+
+	for _, id := range left {
+		t.notifyState(QuotaStateChange{FeatureID: id})
+	}
+}
+
+// OnQuotaReset registers a callback fired when a previously exhausted quota
+// window resets. For product-level quota the featureID is "__product__".
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy).
+//
+// Example:
+//
+//	client.OnQuotaReset(func(featureID string) {
+//	    ingest.Resume()
+//	})
+func (c *Client) OnQuotaReset(fn func(featureID string)) {
+	if fn == nil {
+		return
+	}
+	c.quotaResets.addHandler(fn)
+}
+
+// OnQuotaStateChange registers a callback fired when a quota becomes
+// exhausted and again when it is available, so producers such as ingestion
+// pipelines can pause intake instead of making calls that will all be
+// denied. Exhaustion is tracked when the server reports the reset time; the
+// quota is available again at that time, or when Reopen clears the state.
+// For product-level quota the featureID is "__product__".
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy).
+//
+// Example:
+//
+//	client.OnQuotaStateChange(func(s client.QuotaStateChange) {
+//	    if s.Exhausted {
+//	        ingest.Pause()
+//	    } else {
+//	        ingest.Resume()
+//	    }
+//	})
+func (c *Client) OnQuotaStateChange(fn func(QuotaStateChange)) {
+	if fn == nil {
+		return
+	}
+	c.quotaResets.addStateHandler(fn)
+}
+
+// WaitForQuota blocks while the product quota is exhausted: it returns nil
+// at once if Consume may succeed, otherwise when the quota window resets or
+// ctx is done. It fails if the client is closed while waiting.
+func (c *Client) WaitForQuota(ctx context.Context) error {
+	ch := c.quotaResets.availableCh(productFeatureID)
+	if ch == nil {
+		return nil
+	}
+	select {
+	case <-ch:
+		return c.checkOpen("wait for quota")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QuotaExhausted reports whether the quota for featureID is known to be
+// exhausted, and if so when it resets.
+func (c *Client) QuotaExhausted(featureID string) (bool, time.Time) {
+	resetAt, ok := c.quotaResets.exhaustedUntil(featureID)
+	return ok, resetAt
+}
+
+// TimeUntilQuotaReset returns how long until the quota window for featureID
+// resets, based on the most recent cached status. Returns false if unknown.
+func (c *Client) TimeUntilQuotaReset(featureID string) (time.Duration, bool) {
+	if resetAt, ok := c.quotaResets.exhaustedUntil(featureID); ok {
+		return time.Until(resetAt), true
+	}
+
+	status := c.cache.get(featureID)
+	if status == nil || status.Quota == nil || status.Quota.ResetAt <= 0 {
+		return 0, false
+	}
+	return status.Quota.TimeUntilReset(), true
+}
+
+// noteQuota records exhaustion for featureID when remaining quota (after
+// consuming `consumed` units) reaches zero and the reset time is known.
+func (c *Client) noteQuota(featureID string, quota *QuotaInfo, consumed int) {
+	if quota == nil || quota.Remaining-consumed > 0 {
+		return
+	}
+
+	resetAt := quota.ResetTime()
+	if resetAt.IsZero() || !time.Now().Before(resetAt) {
+		return
+	}
+
+	debugLogf("Quota exhausted for %s until %s", featureID, resetAt.Format(time.RFC3339))
+	c.quotaResets.markExhausted(featureID, resetAt, c.handleQuotaReset)
+}
+
+// handleQuotaReset clears cached state for a reset feature and notifies callbacks
+func (c *Client) handleQuotaReset(featureID string) {
+	debugLogf("Quota window reset for %s", featureID)
+	c.cache.delete(featureID)
+
+	c.quotaResets.notify(featureID)
+}
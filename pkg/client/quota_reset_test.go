@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_QuotaResetNotification(t *testing.T) {
+	c, err := NewClient(&config.SDKConfig{LCCURL: "http://127.0.0.1:0", ProductID: "test-app", ProductVersion: "1.0.0", CacheTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	reset := make(chan string, 1)
+	c.OnQuotaReset(func(featureID string) { reset <- featureID })
+
+	quota := &QuotaInfo{Limit: 10, Used: 10, Remaining: 0, ResetAt: time.Now().Add(time.Second).Unix()}
+	c.cache.set("export", &FeatureStatus{Enabled: false, Quota: quota})
+	c.noteQuota("export", quota, 0)
+
+	if exhausted, _ := c.QuotaExhausted("export"); !exhausted {
+		t.Fatal("QuotaExhausted() = false, want true")
+	}
+	if d, ok := c.TimeUntilQuotaReset("export"); !ok || d > time.Second {
+		t.Errorf("TimeUntilQuotaReset() = %v, %v", d, ok)
+	}
+
+	select {
+	case id := <-reset:
+		if id != "export" {
+			t.Errorf("OnQuotaReset featureID = %s, want export", id)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnQuotaReset was not fired")
+	}
+
+	if exhausted, _ := c.QuotaExhausted("export"); exhausted {
+		t.Error("QuotaExhausted() = true after reset")
+	}
+	if c.cache.get("export") != nil {
+		t.Error("cached status should be cleared at reset")
+	}
+}
+
+func TestClient_QuotaStateChange(t *testing.T) {
+	c, err := NewClient(&config.SDKConfig{LCCURL: "http://127.0.0.1:0", ProductID: "test-app", ProductVersion: "1.0.0", CacheTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	changes := make(chan QuotaStateChange, 4)
+	c.OnQuotaStateChange(func(s QuotaStateChange) { changes <- s })
+	next := func() QuotaStateChange {
+		t.Helper()
+		select {
+		case s := <-changes:
+			return s
+		case <-time.After(3 * time.Second):
+			t.Fatal("OnQuotaStateChange was not fired")
+			return QuotaStateChange{}
+		}
+	}
+
+	if err := c.WaitForQuota(context.Background()); err != nil {
+		t.Fatalf("WaitForQuota() with quota available error = %v", err)
+	}
+
+	resetAt := time.Now().Add(time.Second).Unix()
+	quota := &QuotaInfo{Limit: 10, Used: 10, ResetAt: resetAt}
+	c.noteQuota(productFeatureID, quota, 0)
+	c.noteQuota(productFeatureID, quota, 0) // still exhausted: no second event
+	if s := next(); !s.Exhausted || s.FeatureID != productFeatureID || s.ResetAt.Unix() != resetAt {
+		t.Errorf("state change = %+v, want product exhausted until reset", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.WaitForQuota(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForQuota() while exhausted = %v, want context.DeadlineExceeded", err)
+	}
+
+	if err := c.WaitForQuota(context.Background()); err != nil {
+		t.Fatalf("WaitForQuota() until reset error = %v", err)
+	}
+	if s := next(); s.Exhausted {
+		t.Errorf("state change at reset = %+v, want available", s)
+	}
+
+	// Clearing the state (Reopen) makes exhausted quotas available
+	c.noteQuota(productFeatureID, &QuotaInfo{ResetAt: time.Now().Add(time.Hour).Unix()}, 0)
+	if s := next(); !s.Exhausted {
+		t.Errorf("state change = %+v, want exhausted", s)
+	}
+	c.quotaResets.clear()
+	if s := next(); s.Exhausted {
+		t.Errorf("state change after clear = %+v, want available", s)
+	}
+}
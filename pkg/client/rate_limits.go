@@ -0,0 +1,158 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// windowBuckets is how many buckets a rate window is counted in. Counts are
+// exact to within one bucket (window/60), and an hour-long window takes as
+// little memory as a one-second one.
+const windowBuckets = 60
+
+// RateWindowError reports a call denied by a rate limit other than MaxTPS
+// (see ProductLimits.RateLimits). It is returned by CheckTPS and wrapped in
+// the *LimitExceededError of Limiter.Wait.
+type RateWindowError struct {
+	// Limit and Window are the exceeded rate limit ("50000 per 1h")
+	Limit  int
+	Window string
+
+	// Count is the number of calls in the trailing window
+	Count int
+}
+
+func (e *RateWindowError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: %d calls in the last %s (limit %d per %s)", e.Count, e.Window, e.Limit, e.Window)
+}
+
+// rateWindow is a parsed rate limit
+type rateWindow struct {
+	limit  int
+	window time.Duration
+	label  string
+}
+
+// rateLimitsFor merges the rate limits granted in status with those in the
+// SDK config. For a window in both the lower limit applies. The result is
+// ordered by window, shortest first, so the tightest window is reported.
+func (c *Client) rateLimitsFor(status *FeatureStatus) []rateWindow {
+	if len(c.rateLimits) == 0 && len(status.RateLimits) == 0 {
+		return nil
+	}
+
+	byWindow := make(map[time.Duration]rateWindow)
+	add := func(rl config.RateLimit) {
+		d, err := rl.WindowDuration()
+		if err != nil || rl.Limit <= 0 {
+			debugLogf("Ignoring rate limit %d per %q: %v", rl.Limit, rl.Window, err)
+			return
+		}
+		if cur, ok := byWindow[d]; !ok || rl.Limit < cur.limit {
+			byWindow[d] = rateWindow{limit: rl.Limit, window: d, label: rl.Window}
+		}
+	}
+	for _, rl := range c.rateLimits {
+		add(rl)
+	}
+	for _, rl := range status.RateLimits {
+		add(rl)
+	}
+
+	windows := make([]rateWindow, 0, len(byWindow))
+	for _, w := range byWindow {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].window < windows[j].window })
+	return windows
+}
+
+// windowCounter counts calls in a trailing window as a ring of buckets
+type windowCounter struct {
+	width  time.Duration
+	epochs [windowBuckets]int64 // bucket number each slot currently counts
+	counts [windowBuckets]int
+}
+
+func newWindowCounter(window time.Duration) *windowCounter {
+	return &windowCounter{width: max(window/windowBuckets, time.Nanosecond)}
+}
+
+func (w *windowCounter) add(now time.Time, n int) {
+	epoch := now.UnixNano() / int64(w.width)
+	i := epoch % windowBuckets
+	if w.epochs[i] != epoch {
+		w.epochs[i] = epoch
+		w.counts[i] = 0
+	}
+	w.counts[i] += n
+}
+
+func (w *windowCounter) count(now time.Time) int {
+	epoch := now.UnixNano() / int64(w.width)
+	total := 0
+	for i, e := range w.epochs {
+		if e > epoch-windowBuckets {
+			total += w.counts[i]
+		}
+	}
+	return total
+}
+
+// rateCounters counts calls for every rate limit window in use. Windows
+// from the SDK config are counted from the start; a window granted by the
+// server is counted from its first check.
+type rateCounters struct {
+	mu       sync.Mutex
+	counters map[time.Duration]*windowCounter
+}
+
+// record counts n calls in every window
+func (r *rateCounters) record(now time.Time, n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.counters {
+		w.add(now, n)
+	}
+}
+
+// track starts counting calls in windows not counted yet
+func (r *rateCounters) track(windows []rateWindow) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trackLocked(windows)
+}
+
+func (r *rateCounters) trackLocked(windows []rateWindow) {
+	if r.counters == nil {
+		r.counters = make(map[time.Duration]*windowCounter)
+	}
+	for _, w := range windows {
+		if _, ok := r.counters[w.window]; !ok {
+			r.counters[w.window] = newWindowCounter(w.window)
+		}
+	}
+}
+
+// exceeded returns the first window whose count is over its limit, or
+// with admit at it (so one more call would exceed it)
+func (r *rateCounters) exceeded(windows []rateWindow, now time.Time, admit bool) *RateWindowError {
+	if len(windows) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trackLocked(windows)
+	for _, w := range windows {
+		count := r.counters[w.window].count(now)
+		if count > w.limit || (admit && count >= w.limit) {
+			return &RateWindowError{Limit: w.limit, Window: w.label, Count: count}
+		}
+	}
+	return nil
+}
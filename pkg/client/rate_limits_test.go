@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func newRateLimitServer(t *testing.T, limits map[string]interface{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/product/status" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": limits})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWindowCounter(t *testing.T) {
+	w := newWindowCounter(time.Minute) // 1s buckets
+	start := time.Unix(1_700_000_000, 0)
+
+	w.add(start, 2)
+	w.add(start.Add(30*time.Second), 3)
+	if n := w.count(start.Add(30 * time.Second)); n != 5 {
+		t.Errorf("count() within the window = %d, want 5", n)
+	}
+	if n := w.count(start.Add(61 * time.Second)); n != 3 {
+		t.Errorf("count() after the first calls left the window = %d, want 3", n)
+	}
+	w.add(start.Add(2*time.Minute), 1) // reuses a bucket
+	if n := w.count(start.Add(2 * time.Minute)); n != 1 {
+		t.Errorf("count() two windows later = %d, want 1", n)
+	}
+}
+
+func TestClient_RateLimitsFor(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	c.rateLimits = []config.RateLimit{{Limit: 5, Window: "1h"}, {Limit: 10, Window: "bogus"}}
+
+	got := c.rateLimitsFor(&FeatureStatus{RateLimits: []config.RateLimit{
+		{Limit: 50000, Window: "1d"},
+		{Limit: 3, Window: "60m"},
+		{Limit: 100, Window: "1m"},
+	}})
+	want := []rateWindow{
+		{limit: 100, window: time.Minute, label: "1m"},
+		{limit: 3, window: time.Hour, label: "60m"},
+		{limit: 50000, window: 24 * time.Hour, label: "1d"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rateLimitsFor() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rateLimitsFor()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestClient_CheckTPSRateWindows(t *testing.T) {
+	srv := newRateLimitServer(t, map[string]interface{}{
+		"max_tps":     1000,
+		"rate_limits": []map[string]interface{}{{"limit": 3, "window": "1h"}},
+	})
+	c := newTestClient(t, srv.URL)
+
+	if ok, _, err := c.CheckTPS(); !ok {
+		t.Fatalf("CheckTPS() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _, err := c.Consume(1); !ok {
+			t.Fatalf("Consume() error = %v", err)
+		}
+	}
+	if ok, _, err := c.CheckTPS(); !ok {
+		t.Errorf("CheckTPS() at the window limit error = %v", err)
+	}
+
+	c.Consume(1)
+	ok, maxTPS, err := c.CheckTPS()
+	var windowErr *RateWindowError
+	if ok || !errors.As(err, &windowErr) {
+		t.Fatalf("CheckTPS() over the hourly limit = %v, %v; want *RateWindowError", ok, err)
+	}
+	if windowErr.Window != "1h" || windowErr.Limit != 3 || windowErr.Count != 4 || maxTPS != 1000 {
+		t.Errorf("RateWindowError = %+v, maxTPS %v", windowErr, maxTPS)
+	}
+}
+
+func TestLimiter_RateWindows(t *testing.T) {
+	srv := newRateLimitServer(t, map[string]interface{}{})
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        time.Second,
+		Limits:         &config.ProductLimits{RateLimits: []config.RateLimit{{Limit: 2, Window: "1m"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	l := c.Limiter()
+	if !l.Allow() || l.Wait(context.Background()) != nil {
+		t.Fatal("first two requests should be admitted")
+	}
+	if l.Allow() {
+		t.Error("Allow() over the per-minute limit should deny")
+	}
+
+	err = l.Wait(context.Background())
+	var limitErr *LimitExceededError
+	var windowErr *RateWindowError
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitTPS || !errors.As(err, &windowErr) || windowErr.Window != "1m" {
+		t.Errorf("Wait() over the per-minute limit error = %v; want LimitTPS naming the 1m window", err)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// featureMemo holds the feature decisions made during one request
+type featureMemo struct {
+	entries sync.Map // featureMemoKey → *FeatureStatus
+}
+
+// featureMemoKey scopes entries to a client, so two clients checking the
+// same feature ID within one request do not share decisions
+type featureMemoKey struct {
+	client    *Client
+	featureID string
+}
+
+type featureMemoCtxKey struct{}
+
+// WithFeatureMemo returns a context carrying an empty per-request feature
+// memo. Install it once at the start of a request, typically in HTTP or RPC
+// middleware; CheckFeatureCtx calls made with the context (or contexts
+// derived from it) then check each feature at most once.
+//
+// Example:
+//
+//	func memo(next http.Handler) http.Handler {
+//	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	        next.ServeHTTP(w, r.WithContext(client.WithFeatureMemo(r.Context())))
+//	    })
+//	}
+func WithFeatureMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, featureMemoCtxKey{}, &featureMemo{})
+}
+
+// CheckFeatureCtx is CheckFeature with a per-request memo: within a context
+// prepared with WithFeatureMemo, the first check of a feature goes through
+// the check pipeline and later checks return the same decision without
+// touching the shared cache. Decisions therefore stay consistent for the
+// whole request even if the license changes meanwhile. Errors are not
+// memoized. Without a memo in ctx it behaves like CheckFeature.
+func (c *Client) CheckFeatureCtx(ctx context.Context, featureID string) (*FeatureStatus, error) {
+	memo, _ := ctx.Value(featureMemoCtxKey{}).(*featureMemo)
+	if memo == nil {
+		return c.checkFeature(ctx, featureID)
+	}
+
+	key := featureMemoKey{client: c, featureID: featureID}
+	if status, ok := memo.entries.Load(key); ok {
+		return status.(*FeatureStatus), nil
+	}
+
+	status, err := c.checkFeature(ctx, featureID)
+	if err != nil {
+		return status, err
+	}
+	actual, _ := memo.entries.LoadOrStore(key, status)
+	return actual.(*FeatureStatus), nil
+}
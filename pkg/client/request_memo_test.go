@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_CheckFeatureCtx(t *testing.T) {
+	var calls, failing atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() > 0 {
+			failing.Add(-1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	other := newTestClient(t, srv.URL)
+
+	ctx := WithFeatureMemo(context.Background())
+	first, err := c.CheckFeatureCtx(ctx, "export")
+	if err != nil || !first.Enabled {
+		t.Fatalf("CheckFeatureCtx() = %+v, %v", first, err)
+	}
+
+	// Memo hits bypass the shared cache entirely
+	c.cache.clear()
+	if again, _ := c.CheckFeatureCtx(ctx, "export"); again != first {
+		t.Error("second CheckFeatureCtx() in the same request should return the memoized status")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %d, want 1", got)
+	}
+
+	// Memo entries are per client
+	if _, err := other.CheckFeatureCtx(ctx, "export"); err != nil || calls.Load() != 2 {
+		t.Errorf("other client CheckFeatureCtx() = %v, server calls %d; want a fresh check", err, calls.Load())
+	}
+
+	// A new request starts with an empty memo; without a memo every call checks
+	c.cache.clear()
+	_, _ = c.CheckFeatureCtx(WithFeatureMemo(context.Background()), "export")
+	if calls.Load() != 3 {
+		t.Errorf("server calls = %d, want 3", calls.Load())
+	}
+	c.cache.clear()
+	_, _ = c.CheckFeatureCtx(context.Background(), "export")
+	if calls.Load() != 4 {
+		t.Errorf("server calls = %d, want 4", calls.Load())
+	}
+
+	// Errors are not memoized
+	ctx = WithFeatureMemo(context.Background())
+	c.cache.clear()
+	failing.Store(1)
+	if _, err := c.CheckFeatureCtx(ctx, "reports"); err == nil {
+		t.Fatal("CheckFeatureCtx() during outage should fail")
+	}
+	if status, err := c.CheckFeatureCtx(ctx, "reports"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeatureCtx() after outage = %+v, %v", status, err)
+	}
+}
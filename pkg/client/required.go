@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// ErrRequiredFeaturesUnlicensed is matched (via errors.Is) by the
+// *RequiredFeaturesError returned from EnsureRequired.
+var ErrRequiredFeaturesUnlicensed = errors.New("required features not licensed")
+
+// RequiredFeaturesError lists every required manifest feature that is not
+// usable, keyed by feature ID with the denial reason (or check error) as value.
+type RequiredFeaturesError struct {
+	Features map[string]string
+}
+
+func (e *RequiredFeaturesError) Error() string {
+	ids := make([]string, 0, len(e.Features))
+	for id := range e.Features {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%s (%s)", id, e.Features[id])
+	}
+
+	return fmt.Sprintf("%s: %s", ErrRequiredFeaturesUnlicensed, strings.Join(parts, ", "))
+}
+
+// Is allows errors.Is(err, ErrRequiredFeaturesUnlicensed) to match
+func (e *RequiredFeaturesError) Is(target error) bool {
+	return target == ErrRequiredFeaturesUnlicensed
+}
+
+// SetManifest attaches the feature manifest to the client.
+// The manifest is used by manifest-driven helpers such as EnsureRequired.
+func (c *Client) SetManifest(manifest *config.Manifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifest = manifest
+	var pools map[string]config.QuotaPool
+	if manifest != nil {
+		pools = manifest.QuotaPools
+	}
+	c.pools.setManifest(pools)
+}
+
+// EnsureRequired checks every manifest feature marked Required and returns a
+// single *RequiredFeaturesError listing all that are disabled or could not be
+// checked. Applications call it at startup to fail fast with one clear message
+// instead of failing mid-request. If ctx ends first, ctx.Err() is returned.
+//
+// Example:
+//
+//	client.SetManifest(manifest)
+//	if err := client.EnsureRequired(ctx); err != nil {
+//	    log.Fatalf("license check failed: %v", err)
+//	}
+func (c *Client) EnsureRequired(ctx context.Context) error {
+	c.mu.RLock()
+	manifest := c.manifest
+	c.mu.RUnlock()
+
+	if manifest == nil {
+		return fmt.Errorf("manifest not set (call SetManifest first)")
+	}
+
+	missing := make(map[string]string)
+	for _, feature := range manifest.GetRequiredFeatures() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status, err := c.checkFeature(ctx, feature.ID)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			missing[feature.ID] = "check_error: " + err.Error()
+			continue
+		}
+		if !status.Enabled {
+			reason := status.Reason
+			if reason == "" {
+				reason = "disabled"
+			}
+			missing[feature.ID] = reason
+		}
+	}
+
+	if len(missing) > 0 {
+		return &RequiredFeaturesError{Features: missing}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// newTestClient creates a client pointed at the given test server
+func newTestClient(t *testing.T, serverURL string) *Client {
+	t.Helper()
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         serverURL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		CacheTTL:       time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_EnsureRequired(t *testing.T) {
+	licensed := map[string]bool{"core": true, "reports": false}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+		enabled := licensed[id]
+		reason := ""
+		if !enabled {
+			reason = "feature_not_in_license"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"feature_id": id,
+			"enabled":    enabled,
+			"reason":     reason,
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetManifest(&config.Manifest{
+		Features: []config.FeatureConfig{
+			{ID: "core", Required: true},
+			{ID: "reports", Required: true},
+			{ID: "export", Required: true},
+			{ID: "optional", Required: false},
+		},
+	})
+
+	err := c.EnsureRequired(t.Context())
+	if !errors.Is(err, ErrRequiredFeaturesUnlicensed) {
+		t.Fatalf("EnsureRequired() error = %v, want ErrRequiredFeaturesUnlicensed", err)
+	}
+
+	var reqErr *RequiredFeaturesError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("errors.As(err, *RequiredFeaturesError) = false")
+	}
+	if len(reqErr.Features) != 2 {
+		t.Errorf("missing features = %v, want reports and export", reqErr.Features)
+	}
+	if _, ok := reqErr.Features["core"]; ok {
+		t.Error("licensed feature core should not be reported")
+	}
+}
+
+func TestClient_EnsureRequiredContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetTimeout(200 * time.Millisecond) // the shared server query outlives ctx
+	c.SetManifest(&config.Manifest{
+		Features: []config.FeatureConfig{{ID: "core", Required: true}},
+	})
+
+	// A check cut short by ctx reports ctx's error, not a missing feature
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.EnsureRequired(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EnsureRequired() error = %v, want context.DeadlineExceeded", err)
+	}
+}
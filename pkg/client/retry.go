@@ -0,0 +1,251 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retry defaults used when RetryPolicy fields are unset
+const (
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 5 * time.Second
+	defaultRetryBudget      = 10
+	defaultRetryBudgetRatio = 0.1
+)
+
+// RetryPolicy controls how SDK HTTP calls (registration, feature and
+// product checks, usage reports and heartbeats) are retried.
+//
+// Only transient failures are retried: timeouts and 429, 502, 503 and 504
+// responses. Delays grow exponentially from BaseDelay up to MaxDelay with
+// jitter; a Retry-After header replaces the computed delay, and a
+// Retry-After longer than MaxDelay ends the retries.
+//
+// Retries draw from a budget shared by all calls of the client: it holds up
+// to Budget retries and each successful call refills BudgetRatio of one, so
+// a sustained outage degrades to single attempts instead of multiplying the
+// load on the LCC server.
+type RetryPolicy struct {
+	MaxRetries  int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Budget      int
+	BudgetRatio float64
+}
+
+// withDefaults fills in unset fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultRetryBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultRetryMaxDelay
+	}
+	if p.Budget <= 0 {
+		p.Budget = defaultRetryBudget
+	}
+	if p.BudgetRatio <= 0 {
+		p.BudgetRatio = defaultRetryBudgetRatio
+	}
+	return p
+}
+
+// backoff returns the jittered delay before retry number attempt (0-based):
+// a random value in [d/2, d] where d = BaseDelay * 2^attempt, capped at
+// MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxDelay
+	if attempt < 30 {
+		if exp := p.BaseDelay << attempt; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retrier applies a RetryPolicy with its shared budget
+type retrier struct {
+	mu     sync.Mutex
+	policy RetryPolicy
+	tokens float64
+}
+
+func newRetrier(p RetryPolicy) *retrier {
+	p = p.withDefaults()
+	return &retrier{policy: p, tokens: float64(p.Budget)}
+}
+
+// succeeded refills the budget after a successful call
+func (r *retrier) succeeded() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens += r.policy.BudgetRatio
+	if max := float64(r.policy.Budget); r.tokens > max {
+		r.tokens = max
+	}
+}
+
+// allow takes one retry from the budget
+func (r *retrier) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// SetRetryPolicy replaces the retry policy of SDK HTTP calls and resets the
+// retry budget. The default policy retries SDKConfig.MaxRetries times;
+// MaxRetries 0 disables retries.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	r := newRetrier(p)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retrier = r
+}
+
+// doWithRetry sends the request built by newReq, rebuilding (and thereby
+// re-signing) it for every attempt. The last response or error is returned;
+// the caller handles non-transient statuses as before. Attempts go through
+// the circuit breaker, which fails them with ErrCircuitOpen while open, and
+// through a feature's breaker for a ctx from withBreakerFeature. While
+// egress is disabled, calls fail at once with ErrEgressDisabled.
+func (c *Client) doWithRetry(ctx context.Context, op string, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	if err := c.checkEgress(op); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	r := c.retrier
+	breaker := c.breaker
+	httpClient := c.httpClient
+	clockSync := c.clockSync
+	c.mu.RUnlock()
+	featureID := breakerFeature(ctx)
+	featureBreaker := breaker.feature(featureID)
+	resynced, renegotiated := false, false
+
+	for attempt := 0; ; attempt++ {
+		if err := breaker.allowFeature(featureBreaker, featureID); err != nil {
+			return nil, err
+		}
+		req, err := newReq(ctx)
+		if err != nil {
+			breaker.record(featureBreaker, true, false)
+			return nil, err
+		}
+		resp, err := httpClient.Do(req)
+		breaker.record(featureBreaker, err != nil, err == nil && resp.StatusCode >= 500)
+
+		if err == nil && syncClock(clockSync, resp) && resp.StatusCode == http.StatusUnauthorized && !resynced {
+			// The timestamp was likely rejected: re-sign with the corrected clock
+			debugLogf("%s: clock corrected by %s, retrying", op, clockSync.Offset())
+			resynced = true
+			resp.Body.Close()
+			attempt--
+			continue
+		}
+		if err == nil && c.negotiateHash(resp) && resp.StatusCode == http.StatusUnauthorized && !renegotiated {
+			// The hash algorithm was likely rejected: re-sign with the new one
+			debugLogf("%s: hash algorithm renegotiated, retrying", op)
+			renegotiated = true
+			resp.Body.Close()
+			attempt--
+			continue
+		}
+
+		if !retryable(resp, err) {
+			if err == nil && resp.StatusCode < 500 {
+				r.succeeded()
+			}
+			return resp, err
+		}
+		if attempt >= r.policy.MaxRetries {
+			return resp, err
+		}
+
+		delay := r.policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if after > r.policy.MaxDelay {
+					debugLogf("%s: Retry-After %s exceeds max delay, not retrying", op, after)
+					return resp, nil
+				}
+				delay = after
+			}
+		}
+		if !r.allow() {
+			debugLogf("%s: retry budget exhausted", op)
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		debugLogf("%s: transient failure (attempt %d), retrying in %s", op, attempt+1, delay)
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable reports whether a call failed transiently
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// newSignedRequest builds and signs an SDK request; body may be nil
+func (c *Client) newSignedRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.signer.Load().SignRequest(req); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	return req, nil
+}
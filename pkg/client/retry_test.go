@@ -0,0 +1,103 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RetryTransientFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   []int // statuses returned before a 200
+		retryAfter string
+		policy     RetryPolicy
+		wantCalls  int32
+		wantErr    bool
+	}{
+		{"recovers after 503s", []int{503, 503}, "", RetryPolicy{MaxRetries: 3}, 3, false},
+		{"retries 429 and 502", []int{429, 502}, "", RetryPolicy{MaxRetries: 3}, 3, false},
+		{"gives up after max retries", []int{503, 503, 503}, "", RetryPolicy{MaxRetries: 1}, 2, true},
+		{"does not retry 500", []int{500}, "", RetryPolicy{MaxRetries: 3}, 1, true},
+		{"does not retry 403", []int{403}, "", RetryPolicy{MaxRetries: 3}, 1, true},
+		{"disabled", []int{503}, "", RetryPolicy{}, 1, true},
+		{"honors Retry-After", []int{429}, "0", RetryPolicy{MaxRetries: 1, BaseDelay: time.Hour, MaxDelay: time.Hour}, 2, false},
+		{"Retry-After beyond max delay", []int{429}, "120", RetryPolicy{MaxRetries: 3}, 1, true},
+		{"budget exhausted", []int{503, 503}, "", RetryPolicy{MaxRetries: 3, Budget: 1}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				if n <= len(tt.failures) {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.failures[n-1])
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv.URL)
+			policy := tt.policy
+			if policy.BaseDelay == 0 {
+				policy.BaseDelay = time.Millisecond
+			}
+			c.SetRetryPolicy(policy)
+
+			_, err := c.queryFeature("reports")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("queryFeature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < want/2 || d > want {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", attempt, d, want/2, want)
+			}
+		}
+	}
+	if d := p.backoff(100); d > time.Second {
+		t.Errorf("backoff(100) = %s, want capped at 1s", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+		{"-1", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// ReasonSchedulePrefix prefixes the reason of a denial waived by a
+// monitor-only schedule rule, e.g. "schedule:maintenance:quota_exhausted"
+const ReasonSchedulePrefix = "schedule:"
+
+// applySchedules returns status with the schedule rules in effect at now
+// applied: rules from SDKConfig.Limits first, then those granted with the
+// status, each overriding the limits it sets. status itself is not
+// modified, so cached statuses are evaluated afresh on every check.
+func (c *Client) applySchedules(featureID string, status *FeatureStatus, now time.Time) *FeatureStatus {
+	if len(c.schedules) == 0 && len(status.Schedules) == 0 {
+		return status
+	}
+
+	product := featureID == productFeatureID
+	var scheduled *FeatureStatus
+	for _, rules := range [][]config.ScheduleRule{c.schedules, status.Schedules} {
+		for i := range rules {
+			rule := &rules[i]
+			if !rule.AppliesTo(featureID, product) {
+				continue
+			}
+			_, _, active, err := rule.Window(now)
+			if err != nil {
+				debugLogf("Schedule %s ignored: %v", rule.Name, err)
+				continue
+			}
+			if !active {
+				continue
+			}
+
+			if scheduled == nil {
+				copied := *status
+				copied.ActiveSchedules = nil
+				scheduled = &copied
+			}
+			scheduled.ActiveSchedules = append(scheduled.ActiveSchedules, rule.Name)
+			if rule.MaxTPS > 0 {
+				scheduled.MaxTPS = rule.MaxTPS
+			}
+			if rule.MaxCapacity > 0 {
+				scheduled.MaxCapacity = rule.MaxCapacity
+			}
+			if rule.MaxConcurrency > 0 {
+				scheduled.MaxConcurrency = rule.MaxConcurrency
+			}
+			if rule.MonitorOnly && !scheduled.Enabled {
+				debugLogf("Schedule %s: feature %s would be denied: %s", rule.Name, featureID, scheduled.Reason)
+				scheduled.Enabled = true
+				scheduled.Reason = ReasonSchedulePrefix + rule.Name + ":" + scheduled.Reason
+			}
+		}
+	}
+	if scheduled == nil {
+		return status
+	}
+	return scheduled
+}
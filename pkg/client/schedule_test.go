@@ -0,0 +1,62 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_ApplySchedules(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:1")
+	c.schedules = []config.ScheduleRule{
+		{Name: "batch", Start: "22:00", End: "06:00", MaxTPS: 500, MaxConcurrency: 20},
+	}
+	status := &FeatureStatus{
+		Enabled: true, MaxTPS: 50, MaxConcurrency: 4,
+		Schedules: []config.ScheduleRule{
+			{Name: "burst", Start: "23:00", End: "23:30", MaxTPS: 1000},
+			{Name: "maintenance", Start: "02:00", End: "03:00", Features: []string{"export"}, MonitorOnly: true},
+		},
+	}
+
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if got := c.applySchedules(productFeatureID, status, day); got != status {
+		t.Errorf("applySchedules() outside every window = %+v, want the status unchanged", got)
+	}
+
+	got := c.applySchedules(productFeatureID, status, time.Date(2026, 10, 16, 23, 10, 0, 0, time.UTC))
+	if got.MaxTPS != 1000 || got.MaxConcurrency != 20 || !reflect.DeepEqual(got.ActiveSchedules, []string{"batch", "burst"}) {
+		t.Errorf("applySchedules() at 23:10 = %+v, want both rules, the license one last", got)
+	}
+	if status.MaxTPS != 50 || status.ActiveSchedules != nil {
+		t.Errorf("applySchedules() modified its input: %+v", status)
+	}
+
+	denied := &FeatureStatus{Enabled: false, Reason: ReasonQuotaExhausted, Schedules: status.Schedules}
+	got = c.applySchedules("export", denied, time.Date(2026, 10, 17, 2, 30, 0, 0, time.UTC))
+	if !got.Enabled || got.Reason != "schedule:maintenance:"+ReasonQuotaExhausted {
+		t.Errorf("applySchedules() in a maintenance window = %+v, want the denial waived", got)
+	}
+	if got := c.applySchedules("reports", denied, time.Date(2026, 10, 17, 2, 30, 0, 0, time.UTC)); got.Enabled {
+		t.Error("a rule for export should not apply to other features")
+	}
+}
+
+func TestClient_ScheduleRelaxesTPS(t *testing.T) {
+	srv := newRateLimitServer(t, map[string]interface{}{"max_tps": 10})
+	c := newTestClient(t, srv.URL)
+	c.schedules = []config.ScheduleRule{{Name: "all-day", Start: "00:00", End: "00:00", MaxTPS: 100}}
+
+	if _, max, _ := c.CheckTPS(); max != 100 {
+		t.Errorf("CheckTPS() max = %v, want the scheduled 100", max)
+	}
+	status, err := c.ProductStatus()
+	if err != nil || status.MaxTPS != 100 || len(status.ActiveSchedules) != 1 {
+		t.Errorf("ProductStatus() = %+v, %v; want the schedule surfaced", status, err)
+	}
+	if cached := c.cache.stale(productFeatureID); cached == nil || cached.MaxTPS != 10 {
+		t.Errorf("cached status = %+v, want the licensed limit", cached)
+	}
+}
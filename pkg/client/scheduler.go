@@ -0,0 +1,296 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Background jobs run by the scheduler
+const (
+	jobHeartbeat   = "heartbeat"
+	jobFlush       = "flush"
+	jobRefresh     = "refresh"
+	jobHealthCheck = "health_check"
+	jobDiscovery   = "discovery"
+)
+
+// schedulerJitter spreads each run of a job by up to ±10% of its interval,
+// so instances started together do not call the server in lockstep
+const schedulerJitter = 0.1
+
+// jittered returns d spread by up to ±jitter of it
+func jittered(d time.Duration, jitter float64) time.Duration {
+	spread := int64(float64(d) * jitter)
+	if spread <= 0 {
+		return d
+	}
+	return d - time.Duration(spread) + time.Duration(rand.Int64N(2*spread+1))
+}
+
+// backgroundBudget is a token bucket bounding the rate of background
+// requests, with a burst of one second's worth. It is safe for concurrent
+// use.
+type backgroundBudget struct {
+	mu     sync.Mutex
+	qps    float64 // 0: unlimited
+	tokens float64
+	last   time.Time
+}
+
+// setQPS sets the rate and refills the bucket
+func (b *backgroundBudget) setQPS(qps float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.qps = qps
+	b.tokens = b.burstLocked()
+	b.last = time.Now()
+}
+
+func (b *backgroundBudget) burstLocked() float64 {
+	if b.qps < 1 {
+		return 1
+	}
+	return b.qps
+}
+
+// wait blocks until a background request may be sent, or ctx is done
+func (b *backgroundBudget) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.qps <= 0 {
+			b.mu.Unlock()
+			return ctx.Err()
+		}
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.qps
+		if burst := b.burstLocked(); b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.qps * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// scheduledJob is a job run every interval
+type scheduledJob struct {
+	interval time.Duration
+	jitter   float64
+	next     time.Time
+	run      func(ctx context.Context)
+}
+
+// scheduler runs the client's background jobs one at a time from a single
+// goroutine. Jobs can be added and removed while it runs.
+type scheduler struct {
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	wake chan struct{}
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{jobs: make(map[string]*scheduledJob), wake: make(chan struct{}, 1)}
+}
+
+// add schedules run every interval, first one interval from now, spread
+// by schedulerJitter. It replaces a job of the same name.
+func (s *scheduler) add(name string, interval time.Duration, run func(ctx context.Context)) {
+	s.addJittered(name, interval, schedulerJitter, run)
+}
+
+// addJittered is add with runs spread by up to ±jitter of the interval
+func (s *scheduler) addJittered(name string, interval time.Duration, jitter float64, run func(ctx context.Context)) {
+	s.mu.Lock()
+	s.jobs[name] = &scheduledJob{interval: interval, jitter: jitter, next: time.Now().Add(jittered(interval, jitter)), run: run}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// remove unschedules a job; a run in progress completes
+func (s *scheduler) remove(name string) {
+	s.mu.Lock()
+	delete(s.jobs, name)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// names returns the scheduled jobs, sorted
+func (s *scheduler) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// due returns the job to run next and how long until it is due, or nil
+func (s *scheduler) due(now time.Time) (*scheduledJob, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next *scheduledJob
+	for _, job := range s.jobs {
+		if next == nil || job.next.Before(next.next) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, 0
+	}
+	return next, next.next.Sub(now)
+}
+
+// run runs jobs as they come due until ctx is done. A job is rescheduled
+// one jittered interval after its run ends, so a slow run delays it
+// rather than piling up.
+func (s *scheduler) run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		job, wait := s.due(time.Now())
+		if job != nil && wait <= 0 {
+			job.run(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			s.mu.Lock()
+			job.next = time.Now().Add(jittered(job.interval, job.jitter))
+			s.mu.Unlock()
+			continue
+		}
+		if job == nil {
+			wait = time.Hour
+		}
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// SetBackgroundQPS bounds the rate of requests sent by background work
+// (heartbeats, usage redelivery, lease reconciliation, cache refreshes,
+// audit uploads and event stream reconnects) to qps, shared by all of them,
+// so a large feature catalog does not make the SDK exceed the server's rate
+// limits. Calls made by the application are not limited. 0 (the default)
+// removes the bound. See SDKConfig.BackgroundQPS.
+func (c *Client) SetBackgroundQPS(qps float64) {
+	if qps < 0 {
+		qps = 0
+	}
+	c.background.setQPS(qps)
+}
+
+// SetBackgroundRefresh refreshes cached feature statuses in the background
+// every interval, before they expire, so checks keep hitting the cache. Each
+// status refreshed is one request against the background QPS budget. 0
+// disables refreshing. See SDKConfig.BackgroundRefresh.
+func (c *Client) SetBackgroundRefresh(interval time.Duration) {
+	c.mu.Lock()
+	c.refreshInterval = interval
+	c.mu.Unlock()
+	if interval > 0 {
+		c.scheduler.add(jobRefresh, interval, c.refreshCache)
+	} else {
+		c.scheduler.remove(jobRefresh)
+	}
+}
+
+// heartbeat is the heartbeat job
+func (c *Client) heartbeat(ctx context.Context) {
+	if c.background.wait(ctx) != nil {
+		return
+	}
+	c.notifyHeartbeat(c.sendHeartbeat())
+}
+
+// flushBackground is the flush job: it reconciles the quota lease,
+// redelivers pending usage events and saves the cache file
+func (c *Client) flushBackground(ctx context.Context) {
+	if c.leaseUnreconciled() && c.background.wait(ctx) != nil {
+		return
+	}
+	if err := c.flushLease(ctx); err != nil {
+		debugLogf("Flush: %v", err)
+	}
+	if l := c.ledger(); l != nil {
+		for _, ev := range l.list(UsagePending) {
+			if c.background.wait(ctx) != nil {
+				return
+			}
+			_ = c.deliverUsage(ctx, l, ev.ID)
+		}
+	}
+	if err := c.SaveCache(); err != nil {
+		debugLogf("Flush: %v", err)
+	}
+}
+
+// refreshCache is the refresh job: it refetches the cached statuses that
+// would expire before its next run, soonest first
+func (c *Client) refreshCache(ctx context.Context) {
+	c.mu.RLock()
+	interval := c.refreshInterval
+	c.mu.RUnlock()
+
+	for _, featureID := range c.cache.expiringWithin(time.Now(), interval+time.Duration(float64(interval)*schedulerJitter)) {
+		if c.background.wait(ctx) != nil {
+			return
+		}
+		status, err := c.queryFeature(featureID)
+		if err != nil {
+			debugLogf("Refresh %s: %v", featureID, err)
+			continue
+		}
+		prev := c.cache.setWithTTL(featureID, status, c.policyFor(featureID).cacheTTL)
+		c.clearDedup()
+		c.statusChanges.compare(featureID, prev, status)
+		c.observeDowngrade(featureID, prev, status)
+	}
+}
+
+// expiringWithin returns the features whose cached status is still valid
+// at now but expires within d, soonest first
+func (fc *featureCache) expiringWithin(now time.Time, d time.Duration) []string {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	var ids []string
+	for id, entry := range fc.data {
+		if entry.expiresAt.After(now) && entry.expiresAt.Before(now.Add(d)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return fc.data[ids[i]].expiresAt.Before(fc.data[ids[j]].expiresAt)
+	})
+	return ids
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJittered(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := jittered(time.Second, schedulerJitter); d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("jittered(1s) = %v", d)
+		}
+		if d := jittered(time.Second, 0.5); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("jittered(1s, 0.5) = %v", d)
+		}
+	}
+	if d := jittered(5, schedulerJitter); d != 5 {
+		t.Errorf("jittered(5ns) = %v, want 5ns", d)
+	}
+	if d := jittered(time.Second, 0); d != time.Second {
+		t.Errorf("jittered(1s, 0) = %v, want 1s", d)
+	}
+}
+
+func TestBackgroundBudget(t *testing.T) {
+	b := &backgroundBudget{}
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("unlimited budget waited %v", elapsed)
+	}
+
+	// A burst of 20, then 20 per second
+	b.setQPS(20)
+	start = time.Now()
+	for i := 0; i < 25; i++ {
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > time.Second {
+		t.Errorf("25 requests at 20 QPS took %v, want about 250ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.wait(ctx); err == nil {
+		t.Error("wait() on a cancelled context succeeded")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := newScheduler()
+	var fast, slow atomic.Int32
+	s.add("fast", 5*time.Millisecond, func(context.Context) { fast.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(60 * time.Millisecond)
+	s.add("slow", 30*time.Millisecond, func(context.Context) { slow.Add(1) })
+	s.remove("fast")
+	n := fast.Load()
+	if n < 3 {
+		t.Errorf("fast job ran %d times in 60ms", n)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if fast.Load() > n+1 {
+		t.Errorf("removed job kept running: %d runs after %d", fast.Load(), n)
+	}
+	if slow.Load() == 0 {
+		t.Error("job added while running never ran")
+	}
+	if got := s.names(); len(got) != 1 || got[0] != "slow" {
+		t.Errorf("names() = %v, want [slow]", got)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run() did not return after cancel")
+	}
+}
+
+func TestClient_BackgroundQPS(t *testing.T) {
+	var heartbeats atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/heartbeat" {
+			heartbeats.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetHeartbeatInterval(time.Millisecond)
+	c.SetBackgroundQPS(10)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	c.Close()
+
+	// A burst of 10, then 10 per second
+	if n := heartbeats.Load(); n < 5 || n > 16 {
+		t.Errorf("heartbeats in 300ms at 10 QPS = %d, want about 13", n)
+	}
+}
+
+func TestClient_BackgroundRefresh(t *testing.T) {
+	var enabled atomic.Bool
+	var checks atomic.Int32
+	enabled.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/sdk/features/") {
+			checks.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "export", "enabled": enabled.Load()})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.cache.ttl = 200 * time.Millisecond
+	c.SetBackgroundRefresh(50 * time.Millisecond)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+
+	// The refresh picks up the change before the cached status expires
+	enabled.Store(false)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if status := c.cache.get("export"); status != nil && !status.Enabled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cached status was not refreshed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if status, err := c.CheckFeature("export"); err != nil || status.Enabled {
+		t.Errorf("CheckFeature() after refresh = %+v, %v", status, err)
+	}
+
+	c.SetBackgroundRefresh(0)
+	time.Sleep(20 * time.Millisecond)
+	n := checks.Load()
+	time.Sleep(300 * time.Millisecond)
+	if got := checks.Load(); got != n {
+		t.Errorf("checks after disabling refresh = %d, want %d", got, n)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// sdkModulePath is the module path of this SDK in the binary's build info
+const sdkModulePath = "github.com/yourorg/lcc-sdk"
+
+// sdkDevelVersion is reported when the SDK version is unknown, e.g. in a
+// binary built from the SDK's own source tree
+const sdkDevelVersion = "devel"
+
+// SDKVersion returns the version of this SDK as recorded in the binary's
+// build info (e.g. "v1.4.2"), or "devel" if it is not known
+func SDKVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return sdkDevelVersion
+	}
+	version := ""
+	if bi.Main.Path == sdkModulePath {
+		version = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == sdkModulePath {
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+		}
+	}
+	if version == "" || version == "(devel)" {
+		return sdkDevelVersion
+	}
+	return version
+}
+
+// SDKAdvisory is the server's view of supported SDK versions, returned in
+// heartbeat responses when SDKConfig.SDKUpdateCheck is set
+type SDKAdvisory struct {
+	// MinVersion is the oldest SDK version the server supports
+	MinVersion string `json:"min_version"`
+
+	// LatestVersion is the newest SDK release, if the server reports it
+	LatestVersion string `json:"latest_version,omitempty"`
+
+	// Message is a human-readable note, e.g. what is being removed
+	Message string `json:"message,omitempty"`
+
+	// RemovalAt is when (Unix seconds) versions older than MinVersion stop
+	// working; 0 if not scheduled
+	RemovalAt int64 `json:"removal_at,omitempty"`
+}
+
+// Outdated reports whether version is older than MinVersion. Versions that
+// cannot be compared, such as "devel", are not outdated.
+func (a SDKAdvisory) Outdated(version string) bool {
+	if a.MinVersion == "" {
+		return false
+	}
+	cmp, err := compareVersions(version, a.MinVersion)
+	return err == nil && cmp < 0
+}
+
+// RemovalTime returns RemovalAt as a time, or the zero time if unset
+func (a SDKAdvisory) RemovalTime() time.Time {
+	if a.RemovalAt <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(a.RemovalAt, 0)
+}
+
+// SDKAdvisory returns the most recent advisory received with a heartbeat.
+// It returns false if SDK update checks are disabled or the server has not
+// sent one.
+func (c *Client) SDKAdvisory() (SDKAdvisory, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.sdkAdvisory == nil {
+		return SDKAdvisory{}, false
+	}
+	return *c.sdkAdvisory, true
+}
+
+// applySDKAdvisory records an advisory and, the first time an advisory
+// applies to version, warns and emits EventSDKDeprecated
+func (c *Client) applySDKAdvisory(adv SDKAdvisory, version string) {
+	c.mu.Lock()
+	prev := c.sdkAdvisory
+	c.sdkAdvisory = &adv
+	c.mu.Unlock()
+
+	if !adv.Outdated(version) || (prev != nil && *prev == adv) {
+		return
+	}
+	if removal := adv.RemovalTime(); !removal.IsZero() {
+		debugLogf("WARNING: SDK %s is older than the minimum supported %s and stops working on %s: %s",
+			version, adv.MinVersion, removal.Format(time.RFC3339), adv.Message)
+	} else {
+		debugLogf("WARNING: SDK %s is older than the minimum supported %s: %s", version, adv.MinVersion, adv.Message)
+	}
+	c.emit(Event{Type: EventSDKDeprecated, Advisory: &adv})
+}
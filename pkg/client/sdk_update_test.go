@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSDKAdvisory_Outdated(t *testing.T) {
+	adv := SDKAdvisory{MinVersion: "1.4.0"}
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.3.9", true},
+		{"v1.4.0", false},
+		{"v2.0.0", false},
+		{sdkDevelVersion, false},
+	}
+	for _, tt := range tests {
+		if got := adv.Outdated(tt.version); got != tt.want {
+			t.Errorf("Outdated(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+	if (SDKAdvisory{}).Outdated("v0.1.0") {
+		t.Error("an advisory without a minimum should not mark versions outdated")
+	}
+}
+
+func TestClient_SDKUpdateCheck(t *testing.T) {
+	var sentVersion any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sentVersion = body["sdk_version"]
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sdk_advisory": map[string]any{"min_version": "1.4.0", "message": "v1 signatures are removed in 1.4"},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	if _, ok := c.SDKAdvisory(); ok || sentVersion != nil {
+		t.Fatal("without SDKUpdateCheck the advisory should be ignored and no version sent")
+	}
+
+	c.sdkUpdateCheck = true
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	if sentVersion != SDKVersion() {
+		t.Errorf("heartbeat sdk_version = %v, want %s", sentVersion, SDKVersion())
+	}
+	if adv, ok := c.SDKAdvisory(); !ok || adv.MinVersion != "1.4.0" {
+		t.Errorf("SDKAdvisory() = %+v, %v", adv, ok)
+	}
+
+	var mu sync.Mutex
+	var events []Event
+	c.OnEvent(func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	adv := SDKAdvisory{MinVersion: "1.5.0", RemovalAt: 1_900_000_000}
+	c.applySDKAdvisory(adv, "v1.4.2")
+	c.applySDKAdvisory(adv, "v1.4.2")
+	c.hooks.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 || events[0].Type != EventSDKDeprecated || events[0].Advisory.MinVersion != "1.5.0" {
+		t.Errorf("events = %+v, want one SDKDeprecated event for a repeated advisory", events)
+	}
+}
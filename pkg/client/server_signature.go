@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// maxSignedResponse bounds the response body read for verification
+const maxSignedResponse = 4 << 20
+
+// SetServerPublicKey pins the LCC server's public key (see
+// SDKConfig.ServerPublicKeyFile). Successful feature check, product status,
+// quota lease and usage responses must then carry an X-LCC-Server-Signature
+// made with the matching private key, binding the response body and status
+// to the nonce of the request; otherwise the call fails with an error
+// matching auth.ErrResponseSignature. Such failures count as server errors,
+// so FailOpen still applies to them.
+//
+// Subscription events are not signed: with a pinned key, a pushed feature
+// status only invalidates the cached one, which the next check fetches.
+//
+// Responses over gRPC are not verified. A nil key disables verification.
+func (c *Client) SetServerPublicKey(key crypto.PublicKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.serverKey = key
+}
+
+// serverPublicKey returns the pinned server key, or nil
+func (c *Client) serverPublicKey() crypto.PublicKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.serverKey
+}
+
+// verifyResponse checks the server signature of resp when a server key is
+// pinned. The body is read and replaced, so the caller can decode it as
+// usual.
+func (c *Client) verifyResponse(resp *http.Response) error {
+	key := c.serverPublicKey()
+	if key == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSignedResponse+1))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) > maxSignedResponse {
+		return fmt.Errorf("%w: response exceeds %d bytes", auth.ErrResponseSignature, maxSignedResponse)
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := auth.VerifyResponse(key, resp, body); err != nil {
+		debugLogf("WARNING: rejected server response: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// signingServer answers feature checks for "export", signing responses with
+// key; tamper, if set, alters the signature header
+func signingServer(t *testing.T, key auth.KeyPair, tamper func(nonce string) string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/sdk/features/export/check" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := json.Marshal(map[string]interface{}{"feature_id": "export", "enabled": true})
+		nonce := r.Header.Get("X-LCC-Nonce")
+		if tamper != nil {
+			nonce = tamper(nonce)
+		}
+		signature, err := auth.SignResponse(key, nonce, http.StatusOK, body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set(auth.HeaderServerSignature, signature)
+		w.Write(body)
+	}))
+}
+
+func TestClient_ServerSignature(t *testing.T) {
+	serverKey, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, _ := serverKey.GetPublicKeyPEM()
+	pub, _ := auth.ParsePublicKeyPEM([]byte(pubPEM))
+
+	var recorded atomic.Value
+	recorded.Store("")
+
+	tests := []struct {
+		name    string
+		key     auth.KeyPair
+		tamper  func(nonce string) string
+		wantErr bool
+	}{
+		{"signed", serverKey, nil, false},
+		{"other key", otherKey, nil, true},
+		{"replayed", serverKey, func(nonce string) string {
+			// Every response carries the signature made for the first request
+			recorded.CompareAndSwap("", nonce)
+			return recorded.Load().(string)
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := signingServer(t, tt.key, tt.tamper)
+			defer srv.Close()
+
+			c := newTestClient(t, srv.URL)
+			c.SetServerPublicKey(pub)
+			if tt.name == "replayed" {
+				// The first request sets the replayed signature
+				c.CheckFeature("export")
+				c.ClearCache()
+			}
+			status, err := c.CheckFeature("export")
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrResponseSignature) {
+					t.Errorf("CheckFeature() error = %v, want ErrResponseSignature", err)
+				}
+				return
+			}
+			if err != nil || !status.Enabled {
+				t.Errorf("CheckFeature() = %+v, %v", status, err)
+			}
+		})
+	}
+}
+
+func TestClient_ServerSignatureUnsigned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "export", "enabled": true})
+	}))
+	defer srv.Close()
+
+	// Unsigned responses are accepted until a key is pinned
+	c := newTestClient(t, srv.URL)
+	if _, err := c.CheckFeature("export"); err != nil {
+		t.Fatalf("CheckFeature() without a pinned key error = %v", err)
+	}
+
+	serverKey, _ := auth.GenerateECDSAKeyPair()
+	pubPEM, _ := serverKey.GetPublicKeyPEM()
+	keyFile := filepath.Join(t.TempDir(), "server.pem")
+	if err := os.WriteFile(keyFile, []byte(pubPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:              srv.URL,
+		ProductID:           "test-app",
+		ProductVersion:      "1.0.0",
+		Timeout:             5 * time.Second,
+		ServerPublicKeyFile: keyFile,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if _, err := c.CheckFeature("export"); !errors.Is(err, auth.ErrResponseSignature) {
+		t.Errorf("CheckFeature() of an unsigned response error = %v, want ErrResponseSignature", err)
+	}
+	if err := c.ReportUsage("export", 1); !errors.Is(err, auth.ErrResponseSignature) {
+		t.Errorf("ReportUsage() of an unsigned response error = %v, want ErrResponseSignature", err)
+	}
+}
+
+func TestClient_ServerSignaturePushedStatus(t *testing.T) {
+	c := newTestClient(t, "http://127.0.0.1:0")
+	serverKey, _ := auth.GenerateECDSAKeyPair()
+	pubPEM, _ := serverKey.GetPublicKeyPEM()
+	pub, _ := auth.ParsePublicKeyPEM([]byte(pubPEM))
+	c.SetServerPublicKey(pub)
+
+	c.cache.set("export", &FeatureStatus{Enabled: false, Reason: "not licensed"})
+	c.applyEvent(sseFeature, `{"feature_id":"export","enabled":true}`)
+	if status := c.cache.get("export"); status != nil {
+		t.Errorf("pushed status with a pinned key cached as %+v, want invalidation", status)
+	}
+}
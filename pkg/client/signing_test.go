@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_SignatureOptions(t *testing.T) {
+	// The server only accepts v2 signatures made with RSA-PSS
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		opts := auth.VerifyOptions{MinSignatureVersion: auth.SignatureV2, RequirePSS: true}
+		if err := auth.VerifyRequestWithOptions(r, opts); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"feature_id": "export", "enabled": true})
+	}))
+	defer srv.Close()
+
+	cfg := config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+	}
+
+	c := newTestClient(t, srv.URL)
+	if _, err := c.CheckFeature("export"); err == nil {
+		t.Error("CheckFeature() with default signatures should fail")
+	}
+
+	cfg.SignatureVersion = 2
+	cfg.SignatureScheme = config.SignatureSchemeRSAPSS
+	c, err := NewClient(&cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Errorf("CheckFeature() = %+v, %v", status, err)
+	}
+
+	ec, err := auth.GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClientWithKeyPair(&cfg, ec); err == nil {
+		t.Error("NewClientWithKeyPair() accepted rsa-pss with an ECDSA key")
+	}
+}
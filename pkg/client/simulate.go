@@ -0,0 +1,152 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// SimulationReport is the outcome of replaying a workload against limits
+type SimulationReport struct {
+	// Requests is the number of limited calls replayed (consume, capacity
+	// and acquire events); Denied of them would have been denied
+	Requests int
+	Denied   int
+
+	// DeniedBy counts denials per limit (LimitTPS, LimitQuota, ...)
+	DeniedBy map[string]int
+
+	// Peak demand observed in the workload, regardless of the limits
+	PeakTPS         float64
+	PeakConcurrency int
+	PeakCapacity    int
+
+	// FeatureChecks counts check events per feature
+	FeatureChecks map[string]int
+}
+
+// DenialRate returns the fraction of requests that would have been denied
+func (r *SimulationReport) DenialRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Denied) / float64(r.Requests)
+}
+
+// Simulate replays a recorded workload (see RecordWorkload) against
+// hypothetical product limits and reports how many requests they would have
+// denied, so MaxTPS, quota and the other limits can be tuned before they are
+// enforced. Nothing is sent to the LCC server.
+//
+// The replay follows the client's enforcement: consume events are limited
+// by MaxTPS (consume calls in the trailing second) and then by the quota
+// window; capacity events by MaxCapacity; acquire events by MaxConcurrency,
+// holding the slot until its release event. Acquires denied while recording
+// have no release and, if admitted, are released immediately. Unset limits
+// deny nothing.
+func Simulate(events []WorkloadEvent, limits *config.ProductLimits) (*SimulationReport, error) {
+	if limits == nil {
+		limits = &config.ProductLimits{}
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	var quota *localQuota
+	if limits.Quota != nil {
+		q, err := newLocalQuota(limits.Quota)
+		if err != nil {
+			return nil, err
+		}
+		quota = q
+	}
+
+	sorted := make([]WorkloadEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	report := &SimulationReport{
+		DeniedBy:      make(map[string]int),
+		FeatureChecks: make(map[string]int),
+	}
+	deny := func(limit string) {
+		report.Denied++
+		report.DeniedBy[limit]++
+	}
+
+	var recent []time.Time        // consume times in the trailing second
+	held := make(map[uint64]bool) // slots held under the simulated limit
+	demand := make(map[uint64]bool)
+
+	for _, ev := range sorted {
+		switch ev.Kind {
+		case EventCheck:
+			report.FeatureChecks[ev.FeatureID]++
+
+		case EventConsume:
+			report.Requests++
+			recent = trimWindow(append(recent, ev.Time), ev.Time, time.Second)
+			tps := float64(len(recent))
+			if tps > report.PeakTPS {
+				report.PeakTPS = tps
+			}
+			if limits.MaxTPS > 0 && tps > limits.MaxTPS {
+				deny(LimitTPS)
+				continue
+			}
+			if quota != nil {
+				if ok, _ := quota.consume(ev.Time, ev.Amount); !ok {
+					deny(LimitQuota)
+				}
+			}
+
+		case EventCapacity:
+			report.Requests++
+			if ev.Amount > report.PeakCapacity {
+				report.PeakCapacity = ev.Amount
+			}
+			if limits.MaxCapacity > 0 && ev.Amount >= limits.MaxCapacity {
+				deny(LimitCapacity)
+			}
+
+		case EventAcquire:
+			report.Requests++
+			n := len(demand) + 1 // a denied acquire is counted momentarily
+			if ev.SlotID != 0 {
+				demand[ev.SlotID] = true
+				n = len(demand)
+			}
+			if n > report.PeakConcurrency {
+				report.PeakConcurrency = n
+			}
+			if limits.MaxConcurrency > 0 && len(held) >= limits.MaxConcurrency {
+				deny(LimitConcurrency)
+				continue
+			}
+			if ev.SlotID != 0 {
+				held[ev.SlotID] = true
+			}
+
+		case EventRelease:
+			delete(held, ev.SlotID)
+			delete(demand, ev.SlotID)
+
+		default:
+			return nil, fmt.Errorf("unknown workload event kind %q", ev.Kind)
+		}
+	}
+
+	return report, nil
+}
+
+// trimWindow drops times older than window before now from the sorted slice
+func trimWindow(times []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_RecordWorkload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": true,
+			"limits":  map[string]interface{}{"max_capacity": 100, "max_concurrency": 1},
+		})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	var log bytes.Buffer
+	c.RecordWorkload(&log)
+
+	_, _ = c.CheckFeature("reports")
+	_, _, _ = c.Consume(2)
+	_, _, _ = c.CheckCapacity(7)
+	release, ok, _ := c.AcquireSlot()
+	if !ok {
+		t.Fatal("AcquireSlot() denied")
+	}
+	_, denied, _ := c.AcquireSlot()
+	release()
+	c.RecordWorkload(nil)
+	_, _, _ = c.Consume(1) // not recorded
+
+	events, err := ReadWorkload(&log)
+	if err != nil {
+		t.Fatalf("ReadWorkload() error = %v", err)
+	}
+	var kinds []string
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []string{EventCheck, EventConsume, EventCapacity, EventAcquire, EventAcquire, EventRelease}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("recorded kinds = %v, want %v", kinds, want)
+	}
+	if denied || events[4].SlotID != 0 || events[3].SlotID == 0 || events[5].SlotID != events[3].SlotID {
+		t.Errorf("acquire/release slot IDs = %d, %d, %d", events[3].SlotID, events[4].SlotID, events[5].SlotID)
+	}
+	if events[1].Amount != 2 || events[2].Amount != 7 || events[0].FeatureID != "reports" {
+		t.Errorf("recorded events = %+v", events)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// 5 consumes within one second, then 2 more a second later
+	var events []WorkloadEvent
+	for i := 0; i < 5; i++ {
+		events = append(events, WorkloadEvent{Time: at(i * 100), Kind: EventConsume, Amount: 1})
+	}
+	events = append(events,
+		WorkloadEvent{Time: at(2000), Kind: EventConsume, Amount: 1},
+		WorkloadEvent{Time: at(2100), Kind: EventConsume, Amount: 1},
+		WorkloadEvent{Time: at(100), Kind: EventCheck, FeatureID: "reports"},
+		WorkloadEvent{Time: at(100), Kind: EventCapacity, Amount: 40},
+		WorkloadEvent{Time: at(200), Kind: EventAcquire, SlotID: 1},
+		WorkloadEvent{Time: at(300), Kind: EventAcquire, SlotID: 2},
+		WorkloadEvent{Time: at(400), Kind: EventAcquire},
+		WorkloadEvent{Time: at(500), Kind: EventRelease, SlotID: 1},
+		WorkloadEvent{Time: at(600), Kind: EventRelease, SlotID: 2},
+	)
+
+	tests := []struct {
+		name       string
+		limits     *config.ProductLimits
+		wantDenied map[string]int
+	}{
+		{"no limits", nil, map[string]int{}},
+		{"max tps", &config.ProductLimits{MaxTPS: 3}, map[string]int{LimitTPS: 2}},
+		{"quota", &config.ProductLimits{Quota: &config.ProductQuotaConfig{Max: 4, Window: "1h", Type: config.WindowFixed}}, map[string]int{LimitQuota: 3}},
+		{"capacity", &config.ProductLimits{MaxCapacity: 40}, map[string]int{LimitCapacity: 1}},
+		{"concurrency", &config.ProductLimits{MaxConcurrency: 1}, map[string]int{LimitConcurrency: 2}},
+		{"generous", &config.ProductLimits{MaxTPS: 10, MaxCapacity: 50, MaxConcurrency: 3}, map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Simulate(events, tt.limits)
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+			if report.Requests != 11 {
+				t.Errorf("Requests = %d, want 11", report.Requests)
+			}
+			total := 0
+			for limit, want := range tt.wantDenied {
+				total += want
+				if got := report.DeniedBy[limit]; got != want {
+					t.Errorf("DeniedBy[%s] = %d, want %d", limit, got, want)
+				}
+			}
+			if report.Denied != total {
+				t.Errorf("Denied = %d, want %d (%v)", report.Denied, total, report.DeniedBy)
+			}
+			if report.PeakTPS != 5 || report.PeakConcurrency != 3 || report.PeakCapacity != 40 {
+				t.Errorf("peaks = tps %v, concurrency %d, capacity %d; want 5, 3, 40", report.PeakTPS, report.PeakConcurrency, report.PeakCapacity)
+			}
+			if report.FeatureChecks["reports"] != 1 {
+				t.Errorf("FeatureChecks = %v", report.FeatureChecks)
+			}
+		})
+	}
+
+	if _, err := Simulate([]WorkloadEvent{{Kind: "bogus"}}, nil); err == nil {
+		t.Error("Simulate() with unknown event kind should fail")
+	}
+	if _, err := Simulate(nil, &config.ProductLimits{MaxTPS: -1}); err == nil {
+		t.Error("Simulate() with invalid limits should fail")
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup lets concurrent cache misses for one feature share a single
+// server query instead of each sending its own
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a query in flight and, once done is closed, its result
+type flightCall struct {
+	done   chan struct{}
+	status *FeatureStatus
+	err    error
+}
+
+var errFlightPanicked = errors.New("shared feature query panicked")
+
+// do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call's result. A waiter whose ctx is done stops
+// waiting; the call itself runs to completion for the others.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*FeatureStatus, error)) (*FeatureStatus, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.status, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call := &flightCall{done: make(chan struct{}), err: errFlightPanicked}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.status, call.err = fn()
+	return call.status, call.err
+}
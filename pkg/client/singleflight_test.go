@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_ConcurrentChecksShareQuery(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL)
+
+	var wg sync.WaitGroup
+	var enabled atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, err := c.CheckFeature("reports"); err == nil && status.Enabled {
+				enabled.Add(1)
+			}
+		}()
+	}
+	waitFor(t, "first query", func() bool { return requests.Load() == 1 })
+	time.Sleep(20 * time.Millisecond) // let the other checks join
+	close(release)
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server received %d queries for one feature, want 1", n)
+	}
+	if n := enabled.Load(); n != 20 {
+		t.Errorf("%d of 20 checks saw the shared result", n)
+	}
+}
+
+func TestFlightGroup_WaiterContext(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	go g.do(context.Background(), "f", func() (*FeatureStatus, error) {
+		close(started)
+		<-finish
+		return &FeatureStatus{Enabled: true}, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.do(ctx, "f", nil); err != context.DeadlineExceeded {
+		t.Errorf("do() of a waiter with an expired context error = %v", err)
+	}
+
+	// Other keys are not held up
+	if status, err := g.do(context.Background(), "g", func() (*FeatureStatus, error) {
+		return &FeatureStatus{}, nil
+	}); err != nil || status == nil {
+		t.Errorf("do() for another key = %v, %v", status, err)
+	}
+	close(finish)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"sort"
+	"time"
+)
+
+// SlotHolder describes a held product-level concurrency slot
+type SlotHolder struct {
+	// ID identifies the slot for as long as it is held
+	ID uint64
+
+	// Owner and Metadata are the values passed to AcquireSlotAs
+	Owner    string
+	Metadata map[string]any
+
+	AcquiredAt time.Time
+}
+
+// HeldFor returns how long the slot has been held
+func (h SlotHolder) HeldFor() time.Duration {
+	return time.Since(h.AcquiredAt)
+}
+
+func newSlotHolder(owner string, meta map[string]any) *SlotHolder {
+	h := &SlotHolder{Owner: owner}
+	if len(meta) > 0 {
+		h.Metadata = make(map[string]any, len(meta))
+		for k, v := range meta {
+			h.Metadata[k] = v
+		}
+	}
+	return h
+}
+
+// ActiveSlots lists the current holders of product-level concurrency
+// slots, oldest first. Use it to see what is occupying the licensed
+// concurrency when AcquireSlot reports the limit exceeded.
+func (c *Client) ActiveSlots() []SlotHolder {
+	c.mu.RLock()
+	holders := make([]SlotHolder, 0, len(c.slotHolders))
+	for _, h := range c.slotHolders {
+		holders = append(holders, *h)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(holders, func(i, j int) bool {
+		if !holders[i].AcquiredAt.Equal(holders[j].AcquiredAt) {
+			return holders[i].AcquiredAt.Before(holders[j].AcquiredAt)
+		}
+		return holders[i].ID < holders[j].ID
+	})
+	return holders
+}
+
+// addSlotHolder records a copy of holder as owning a newly acquired slot
+// and returns the slot ID. Caller holds c.mu.
+func (c *Client) addSlotHolder(holder *SlotHolder) uint64 {
+	if c.slotHolders == nil {
+		c.slotHolders = make(map[uint64]*SlotHolder)
+	}
+	c.nextSlotID++
+
+	h := *holder
+	h.ID = c.nextSlotID
+	h.AcquiredAt = time.Now()
+	c.slotHolders[h.ID] = &h
+	c.compliance.concurrency(len(c.slotHolders))
+	return h.ID
+}
+
+// removeSlotHolder forgets a released slot. Caller holds c.mu.
+func (c *Client) removeSlotHolder(id uint64) {
+	delete(c.slotHolders, id)
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_ActiveSlots(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 2).URL)
+
+	meta := map[string]any{"job": 42}
+	releaseA, ok, err := c.AcquireSlotAs(context.Background(), "export", meta)
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlotAs() = %v, %v", ok, err)
+	}
+	meta["job"] = 43 // the holder keeps its own copy
+
+	releaseB, ok, err := c.AcquireSlot()
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlot() = %v, %v", ok, err)
+	}
+
+	slots := c.ActiveSlots()
+	if len(slots) != 2 {
+		t.Fatalf("ActiveSlots() = %d holders, want 2", len(slots))
+	}
+	if slots[0].Owner != "export" || slots[0].Metadata["job"] != 42 || slots[0].AcquiredAt.IsZero() {
+		t.Errorf("ActiveSlots()[0] = %+v", slots[0])
+	}
+	if slots[1].Owner != "" || slots[1].ID == slots[0].ID {
+		t.Errorf("ActiveSlots()[1] = %+v", slots[1])
+	}
+
+	releaseA()
+	slots = c.ActiveSlots()
+	if len(slots) != 1 || slots[0].Owner != "" {
+		t.Errorf("ActiveSlots() after release = %+v", slots)
+	}
+
+	releaseB()
+	if slots := c.ActiveSlots(); len(slots) != 0 {
+		t.Errorf("ActiveSlots() after releasing all = %+v", slots)
+	}
+}
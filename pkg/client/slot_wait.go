@@ -0,0 +1,171 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"time"
+)
+
+// SlotWaitStats reports AcquireSlotWait activity
+type SlotWaitStats struct {
+	// Waiting is the number of callers currently queued; PeakWaiting is the
+	// highest value observed
+	Waiting     int
+	PeakWaiting int
+
+	// Enqueued callers end up either Admitted or TimedOut (which includes
+	// canceled contexts)
+	Enqueued uint64
+	Admitted uint64
+	TimedOut uint64
+
+	// TotalWait is the time admitted callers spent queued; LongestWait the
+	// longest single wait
+	TotalWait   time.Duration
+	LongestWait time.Duration
+}
+
+// AverageWait returns the mean queue time of admitted callers
+func (s SlotWaitStats) AverageWait() time.Duration {
+	if s.Admitted == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Admitted)
+}
+
+// slotWaiter is a caller of AcquireSlotWait queued for a slot
+type slotWaiter struct {
+	holder  *SlotHolder
+	ready   chan struct{} // closed when a slot is handed over
+	release ReleaseFunc
+	elem    *list.Element // nil once dequeued
+}
+
+// slotWaitQueue holds AcquireSlotWait callers in arrival order. It is
+// guarded by c.mu, so a release can hand its slot straight to the head
+// waiter without the slot ever appearing free.
+type slotWaitQueue struct {
+	waiters list.List
+	limit   int // MaxConcurrency seen by the most recent acquire
+	stats   SlotWaitStats
+}
+
+// AcquireSlotWait is AcquireSlot that waits for a slot when the concurrency
+// limit is reached, for up to maxWait (0 waits until ctx is done). Waiters
+// are admitted in FIFO order: a released slot is handed to the longest
+// waiting caller, and new callers do not overtake queued ones. It fails with
+// ErrSlotWaitTimeout after maxWait, or with the context error.
+//
+// Example:
+//
+//	release, ok, err := client.AcquireSlotWait(ctx, 2*time.Second)
+//	if !ok {
+//	    return err
+//	}
+//	defer release()
+func (c *Client) AcquireSlotWait(ctx context.Context, maxWait time.Duration) (ReleaseFunc, bool, error) {
+	holder := newSlotHolder("", nil)
+
+	status, err := c.checkSlotLimits()
+	if err != nil {
+		return func() {}, false, err
+	}
+	maxConcurrency := status.MaxConcurrency
+	if maxConcurrency <= 0 {
+		return func() {}, false, fmt.Errorf("no concurrency limit configured")
+	}
+
+	if release, _, ok := c.tryAcquireProductSlot(maxConcurrency, holder); ok {
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire, SlotID: holder.ID})
+		return release, true, nil
+	}
+
+	waitCtx := ctx
+	if maxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, maxWait)
+		defer cancel()
+	}
+
+	w := &slotWaiter{holder: holder, ready: make(chan struct{})}
+	start := time.Now()
+	c.mu.Lock()
+	q := &c.slotWaits
+	w.elem = q.waiters.PushBack(w)
+	q.stats.Waiting++
+	q.stats.Enqueued++
+	q.stats.PeakWaiting = max(q.stats.PeakWaiting, q.stats.Waiting)
+	c.admitSlotWaitersLocked(maxConcurrency) // a slot may have freed meanwhile
+	c.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		waited := time.Since(start)
+		c.mu.Lock()
+		q.stats.Waiting--
+		q.stats.Admitted++
+		q.stats.TotalWait += waited
+		q.stats.LongestWait = max(q.stats.LongestWait, waited)
+		c.mu.Unlock()
+
+		debugLogf("AcquireSlotWait: admitted after %v", waited)
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire, SlotID: w.holder.ID})
+		return w.release, true, nil
+
+	case <-waitCtx.Done():
+		c.mu.Lock()
+		granted := w.elem == nil
+		if !granted {
+			q.waiters.Remove(w.elem)
+			w.elem = nil
+		}
+		q.stats.Waiting--
+		q.stats.TimedOut++
+		c.mu.Unlock()
+
+		// A slot handed over as the wait expired goes to the next waiter
+		if granted {
+			w.release()
+		}
+		c.recordEvent(WorkloadEvent{Kind: EventAcquire})
+		if ctx.Err() != nil {
+			return func() {}, false, ctx.Err()
+		}
+		return func() {}, false, fmt.Errorf("%w (%v)", ErrSlotWaitTimeout, maxWait)
+	}
+}
+
+// SlotWaitStats returns AcquireSlotWait queue metrics
+func (c *Client) SlotWaitStats() SlotWaitStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.slotWaits.stats
+}
+
+// admitSlotWaitersLocked hands free slots to queued waiters, e.g. after the
+// limit was raised. Caller holds c.mu.
+func (c *Client) admitSlotWaitersLocked(maxConcurrency int) {
+	c.slotWaits.limit = maxConcurrency
+	key := c.productSlotKey()
+	for c.slotWaits.waiters.Len() > 0 && concurrencyState[key] < maxConcurrency {
+		concurrencyState[key]++
+		c.handOffSlotLocked()
+	}
+}
+
+// handOffSlotLocked gives a held slot to the head waiter, reporting false
+// if none is queued or the limit has since been lowered below the slots in
+// use. Caller holds c.mu.
+func (c *Client) handOffSlotLocked() bool {
+	q := &c.slotWaits
+	front := q.waiters.Front()
+	if front == nil || concurrencyState[c.productSlotKey()] > q.limit {
+		return false
+	}
+	w := q.waiters.Remove(front).(*slotWaiter)
+	w.elem = nil
+	w.release = c.newProductSlotLocked(w.holder)
+	close(w.ready)
+	return true
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitForSlotWaiters(t *testing.T, c *Client, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for c.SlotWaitStats().Waiting != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers queued, want %d", c.SlotWaitStats().Waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClient_AcquireSlotWaitFIFO(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 1).URL)
+
+	release, ok, err := c.AcquireSlotWait(context.Background(), time.Second)
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlotWait() with a free slot = %v, %v", ok, err)
+	}
+
+	// Queue three waiters in a known order
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, ok, err := c.AcquireSlotWait(context.Background(), 5*time.Second)
+			if !ok {
+				t.Errorf("waiter %d: %v", i, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			r()
+		}(i)
+		waitForSlotWaiters(t, c, i+1)
+	}
+
+	// A new caller does not overtake the queue
+	if _, ok, _ := c.AcquireSlot(); ok {
+		t.Error("AcquireSlot() should not take a slot ahead of queued waiters")
+	}
+
+	release()
+	release() // releasing twice has no effect
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("admission order = %v, want [0 1 2]", order)
+	}
+	stats := c.SlotWaitStats()
+	if stats.Enqueued != 3 || stats.Admitted != 3 || stats.Waiting != 0 || stats.PeakWaiting != 3 {
+		t.Errorf("SlotWaitStats() = %+v", stats)
+	}
+	if stats.AverageWait() <= 0 || stats.LongestWait < stats.AverageWait() {
+		t.Errorf("AverageWait() = %v, LongestWait = %v", stats.AverageWait(), stats.LongestWait)
+	}
+
+	// Every slot was returned
+	r, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() after all releases error = %v", err)
+	}
+	r()
+}
+
+func TestClient_AcquireSlotWaitTimeout(t *testing.T) {
+	c := newTestClient(t, newConcurrencyServer(t, 1).URL)
+
+	release, _, err := c.AcquireSlot()
+	if err != nil {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	defer release()
+
+	start := time.Now()
+	if _, ok, err := c.AcquireSlotWait(context.Background(), 20*time.Millisecond); ok || !errors.Is(err, ErrSlotWaitTimeout) {
+		t.Errorf("AcquireSlotWait() = %v, %v; want ErrSlotWaitTimeout", ok, err)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("AcquireSlotWait() returned after %v, want >= 20ms", waited)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok, err := c.AcquireSlotWait(ctx, 0); ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AcquireSlotWait(ctx deadline) = %v, %v; want context.DeadlineExceeded", ok, err)
+	}
+
+	if stats := c.SlotWaitStats(); stats.TimedOut != 2 || stats.Waiting != 0 || stats.Admitted != 0 {
+		t.Errorf("SlotWaitStats() = %+v; want 2 timed out", stats)
+	}
+}
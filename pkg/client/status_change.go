@@ -0,0 +1,130 @@
+package client
+
+import "sync"
+
+// Fields reported in FeatureStatusChange.Changed
+const (
+	FieldEnabled        = "enabled"
+	FieldReason         = "reason"
+	FieldVariant        = "variant"
+	FieldMaxCapacity    = "max_capacity"
+	FieldMaxTPS         = "max_tps"
+	FieldMaxConcurrency = "max_concurrency"
+	FieldQuotaLimit     = "quota_limit"
+)
+
+// FeatureStatusChange describes a refreshed feature status that differs
+// from the previously cached one, e.g. after a license upgrade or downgrade
+type FeatureStatusChange struct {
+	FeatureID string
+	Old       *FeatureStatus
+	New       *FeatureStatus
+
+	// Changed lists the differing fields (FieldEnabled, FieldMaxTPS, ...)
+	Changed []string
+}
+
+// EnabledChanged reports whether the feature was enabled or disabled
+func (c *FeatureStatusChange) EnabledChanged() bool {
+	return c.Old.Enabled != c.New.Enabled
+}
+
+// statusChangeNotifier dispatches feature status changes to handlers
+type statusChangeNotifier struct {
+	mu       sync.Mutex
+	hooks    *hookDispatcher
+	handlers []namedHook[func(FeatureStatusChange)]
+
+	// onChange, if set, is called with every change before the handlers
+	// are dispatched
+	onChange func(FeatureStatusChange)
+}
+
+func (n *statusChangeNotifier) addHandler(fn func(FeatureStatusChange)) {
+	h := namedHook[func(FeatureStatusChange)]{name: n.hooks.name("OnFeatureStatusChange"), fn: fn}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handlers = append(n.handlers, h)
+}
+
+// compare notifies handlers when status differs from prev. Quota usage is
+// not compared since it changes with every call.
+func (n *statusChangeNotifier) compare(featureID string, prev, status *FeatureStatus) {
+	if prev == nil || status == nil {
+		return
+	}
+	changed := diffStatus(prev, status)
+	if len(changed) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	handlers := n.handlers
+	n.mu.Unlock()
+
+	debugLogf("Feature %s status changed: %v", featureID, changed)
+	change := FeatureStatusChange{FeatureID: featureID, Old: prev, New: status, Changed: changed}
+	if n.onChange != nil {
+		n.onChange(change)
+	}
+	for _, h := range handlers {
+		fn := h.fn
+		n.hooks.dispatch(h.name, func() { fn(change) })
+	}
+}
+
+// diffStatus returns the fields that differ between two statuses
+func diffStatus(old, new *FeatureStatus) []string {
+	var changed []string
+	if old.Enabled != new.Enabled {
+		changed = append(changed, FieldEnabled)
+	}
+	if old.Reason != new.Reason {
+		changed = append(changed, FieldReason)
+	}
+	if old.Variant != new.Variant {
+		changed = append(changed, FieldVariant)
+	}
+	if old.MaxCapacity != new.MaxCapacity {
+		changed = append(changed, FieldMaxCapacity)
+	}
+	if old.MaxTPS != new.MaxTPS {
+		changed = append(changed, FieldMaxTPS)
+	}
+	if old.MaxConcurrency != new.MaxConcurrency {
+		changed = append(changed, FieldMaxConcurrency)
+	}
+	if quotaLimit(old) != quotaLimit(new) {
+		changed = append(changed, FieldQuotaLimit)
+	}
+	return changed
+}
+
+func quotaLimit(s *FeatureStatus) int {
+	if s.Quota == nil {
+		return 0
+	}
+	return s.Quota.Limit
+}
+
+// OnFeatureStatusChange registers a callback fired when a refreshed feature
+// status differs from the cached one (enabled flipped, reason or limits
+// changed). Only features checked before are compared; clearing the cache
+// resets the baseline.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy), in order for
+// each callback.
+//
+// Example:
+//
+//	client.OnFeatureStatusChange(func(ch client.FeatureStatusChange) {
+//	    if ch.FeatureID == "bulk_export" && ch.EnabledChanged() {
+//	        exporter.SetEnabled(ch.New.Enabled)
+//	    }
+//	})
+func (c *Client) OnFeatureStatusChange(fn func(FeatureStatusChange)) {
+	if fn == nil {
+		return
+	}
+	c.statusChanges.addHandler(fn)
+}
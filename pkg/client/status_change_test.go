@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_OnFeatureStatusChange(t *testing.T) {
+	var mu sync.Mutex
+	response := map[string]interface{}{"enabled": true, "max_tps": 10, "quota_info": map[string]interface{}{"limit": 100, "used": 1}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	var changes []FeatureStatusChange
+	c.OnFeatureStatusChange(func(ch FeatureStatusChange) {
+		changes = append(changes, ch)
+	})
+
+	refresh := func(update map[string]interface{}) {
+		t.Helper()
+		mu.Lock()
+		for k, v := range update {
+			response[k] = v
+		}
+		mu.Unlock()
+
+		// Expire the cached entry so the next check refreshes it
+		c.cache.mu.Lock()
+		if entry, ok := c.cache.data["reports"]; ok {
+			entry.expiresAt = time.Time{}
+		}
+		c.cache.mu.Unlock()
+
+		if _, err := c.CheckFeature("reports"); err != nil {
+			t.Fatalf("CheckFeature() error = %v", err)
+		}
+		c.hooks.wait() // callbacks run on the hook workers
+	}
+
+	refresh(nil) // initial state is not a change
+	refresh(map[string]interface{}{"quota_info": map[string]interface{}{"limit": 100, "used": 50}})
+	if len(changes) != 0 {
+		t.Fatalf("changes after initial check and usage update = %+v, want none", changes)
+	}
+
+	refresh(map[string]interface{}{"max_tps": 50, "quota_info": map[string]interface{}{"limit": 1000, "used": 50}})
+	refresh(map[string]interface{}{"enabled": false, "reason": "license_expired"})
+
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	if got, want := changes[0].Changed, []string{FieldMaxTPS, FieldQuotaLimit}; !reflect.DeepEqual(got, want) {
+		t.Errorf("upgrade Changed = %v, want %v", got, want)
+	}
+	if changes[0].Old.MaxTPS != 10 || changes[0].New.MaxTPS != 50 || changes[0].EnabledChanged() {
+		t.Errorf("upgrade change = %+v", changes[0])
+	}
+	if !changes[1].EnabledChanged() || changes[1].New.Reason != "license_expired" || changes[1].FeatureID != "reports" {
+		t.Errorf("downgrade change = %+v", changes[1])
+	}
+}
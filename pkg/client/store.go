@@ -0,0 +1,146 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// Keys the client keeps its state under in a Store
+const (
+	keyPairStoreKey   = "keypair"
+	cacheStoreKey     = "cache"
+	usageStoreKey     = "usage"
+	clockMarkStoreKey = "clock"
+	retiredStoreKey   = "retired"
+)
+
+// clockMarkInterval is how often the clock mark is advanced while time
+// moves forward
+const clockMarkInterval = time.Minute
+
+// NewClientWithStore creates a client that keeps its state in s:
+//
+//   - the key pair, generated and saved on first use, so the instance ID
+//     survives restarts, unless SDKConfig.KeyFile, KeyEnv or KeyringService
+//     is set
+//   - a snapshot of the feature cache and quota exhaustion state (see
+//     SaveCache), unless SDKConfig.CacheFile is set
+//   - usage events until the server acknowledges them (see
+//     EnableUsageLedger), unless SDKConfig.UsageJournal is set
+//   - a mark of the latest time seen, so setting the system clock back
+//     does not revive an expired license or activation
+//   - the retirement of the identity by Deactivate, so a restarted client
+//     stays deactivated until Reactivate
+//
+// Use store.NewFileStore for a state directory, or supply a Store backed by
+// whatever storage the environment offers.
+func NewClientWithStore(cfg *config.SDKConfig, s store.Store) (*Client, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store is nil")
+	}
+	cfg, err := prepareConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var keys auth.KeyStore = storeKeys{s}
+	if ks := configKeyStore(cfg); ks != nil {
+		keys = ks
+	}
+	kp, err := auth.LoadOrCreateKeyPair(keys, cfg.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newClient(cfg, kp, s)
+	if err != nil {
+		return nil, err
+	}
+	c.keyStore = keys
+	return c, nil
+}
+
+// storeKeys is a KeyStore keeping the key pair in a Store
+type storeKeys struct {
+	s store.Store
+}
+
+// Load implements auth.KeyStore
+func (k storeKeys) Load() (auth.KeyPair, error) {
+	data, err := k.s.Get(keyPairStoreKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, auth.ErrNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return auth.ParsePrivateKeyPEM(data)
+}
+
+// Save implements auth.KeyStore
+func (k storeKeys) Save(kp auth.KeyPair) error {
+	keyPEM, err := kp.ExportPrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	return k.s.Put(keyPairStoreKey, []byte(keyPEM))
+}
+
+// useStore restores the client state kept in s
+func (c *Client) useStore(s store.Store, cfg *config.SDKConfig) error {
+	c.store = s
+
+	if c.cacheStore == nil {
+		keyPEM, err := cacheKeyMaterial(cfg, c.keyPair)
+		if err != nil {
+			return err
+		}
+		c.cacheStore = newCacheStoreIn(s, cacheStoreKey, cfg.CacheKey, cfg.CacheMaxStale, keyPEM)
+		c.loadCacheFile()
+	}
+	if c.ledger() == nil {
+		l, err := openUsageLedgerStore(s)
+		if err != nil {
+			return err
+		}
+		c.setUsageLedger(l, "store")
+	}
+
+	mark, err := store.NewCounter(s, clockMarkStoreKey)
+	if err != nil {
+		return err
+	}
+	c.clockMark = mark
+	c.licenseNow()
+	return c.loadDeactivation()
+}
+
+// Store returns the store the client keeps its state in, or nil if it was
+// not created with NewClientWithStore
+func (c *Client) Store() store.Store {
+	return c.store
+}
+
+// licenseNow returns the time licenses and activations are validated
+// against. With a Store it never goes back before the latest time recorded
+// there.
+func (c *Client) licenseNow() time.Time {
+	now := time.Now()
+	if c.clockMark == nil {
+		return now
+	}
+
+	mark := time.Unix(int64(c.clockMark.Value()), 0)
+	if now.Before(mark) {
+		return mark
+	}
+	if now.Sub(mark) >= clockMarkInterval {
+		if _, err := c.clockMark.Advance(uint64(now.Unix())); err != nil {
+			debugLogf("Clock mark: %v", err)
+		}
+	}
+	return now
+}
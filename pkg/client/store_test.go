@@ -0,0 +1,201 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+	"github.com/yourorg/lcc-sdk/pkg/license"
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+func newStoreClient(t *testing.T, url string, s store.Store) *Client {
+	t.Helper()
+	c, err := NewClientWithStore(&config.SDKConfig{
+		LCCURL:         url,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		CacheTTL:       time.Minute,
+	}, s)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_Store(t *testing.T) {
+	us := newUsageServer()
+	us.status.Store(http.StatusBadGateway)
+	srv := httptest.NewServer(us)
+	defer srv.Close()
+	s := store.NewMemoryStore()
+
+	first := newStoreClient(t, srv.URL, s)
+	first.cache.setWithTTL("reports", &FeatureStatus{Enabled: true}, time.Hour)
+	if err := first.SaveCache(); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	_ = first.ReportUsage("export", 5)
+	pending := first.UsageEvents(UsagePending)
+	if len(pending) != 1 {
+		t.Fatalf("pending events = %+v, want 1", pending)
+	}
+
+	// A restarted client keeps its identity, cache and undelivered usage
+	restarted := newStoreClient(t, srv.URL, s)
+	if restarted.instanceID != first.instanceID {
+		t.Errorf("instance ID changed across restarts: %s, %s", first.instanceID, restarted.instanceID)
+	}
+	if status := restarted.cache.stale("reports"); status == nil || !status.Enabled {
+		t.Errorf("restored cache entry = %+v, want enabled", status)
+	}
+	if events := restarted.UsageEvents(UsagePending); len(events) != 1 || events[0].ID != pending[0].ID {
+		t.Fatalf("recovered events = %+v, want %s", events, pending[0].ID)
+	}
+
+	us.status.Store(0)
+	if err := restarted.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+	if got := us.count("export"); got != 5 {
+		t.Errorf("server count = %d, want 5", got)
+	}
+	if events := newStoreClient(t, srv.URL, s).UsageEvents(""); len(events) != 0 {
+		t.Errorf("acknowledged events were kept in the store: %+v", events)
+	}
+}
+
+func TestClient_StoreClockMark(t *testing.T) {
+	s := store.NewMemoryStore()
+	lic := &license.License{
+		LicenseID: "lic-1",
+		ProductID: "test-app",
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+	}
+
+	c := newStoreClient(t, "http://127.0.0.1:1", s)
+	if c.Store() != s {
+		t.Error("Store() should return the client's store")
+	}
+	if err := c.UseLicense(lic); err != nil {
+		t.Fatalf("UseLicense() error = %v", err)
+	}
+
+	// The clock was seen an hour ahead before it was set back
+	ahead := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	_ = s.Put(clockMarkStoreKey, []byte(ahead))
+	rolledBack := newStoreClient(t, "http://127.0.0.1:1", s)
+	if err := rolledBack.UseLicense(lic); !errors.Is(err, license.ErrExpired) {
+		t.Errorf("UseLicense() after the clock was set back error = %v, want ErrExpired", err)
+	}
+}
+
+func TestClient_StoreECDSAKey(t *testing.T) {
+	verified := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/register" {
+			verified <- auth.VerifyRequest(r)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	s := store.NewMemoryStore()
+	cfg := &config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		KeyAlgorithm:   config.KeyAlgorithmECDSAP256,
+	}
+
+	c, err := NewClientWithStore(cfg, s)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer c.Close()
+	if alg := c.keyPair.Algorithm(); alg != auth.AlgorithmECDSAP256 {
+		t.Fatalf("key algorithm = %s, want %s", alg, auth.AlgorithmECDSAP256)
+	}
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := <-verified; err != nil {
+		t.Errorf("server could not verify the ECDSA-signed request: %v", err)
+	}
+
+	// The saved key is reused, even if the configured algorithm changes
+	cfg.KeyAlgorithm = config.KeyAlgorithmRSA
+	restarted, err := NewClientWithStore(cfg, s)
+	if err != nil {
+		t.Fatalf("NewClientWithStore() error = %v", err)
+	}
+	defer restarted.Close()
+	if restarted.instanceID != c.instanceID {
+		t.Errorf("instance ID changed across restarts: %s, %s", c.instanceID, restarted.instanceID)
+	}
+}
+
+func TestNewClient_KeyFile(t *testing.T) {
+	cfg := &config.SDKConfig{
+		LCCURL:         "http://127.0.0.1:1",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		KeyFile:        filepath.Join(t.TempDir(), "instance.pem"),
+	}
+
+	first, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	first.Close()
+	restarted, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer restarted.Close()
+	if restarted.instanceID != first.instanceID {
+		t.Errorf("instance ID changed across restarts: %s, %s", first.instanceID, restarted.instanceID)
+	}
+
+	// Without a key file every client is a new instance
+	cfg.KeyFile = ""
+	fresh, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer fresh.Close()
+	if fresh.instanceID == first.instanceID {
+		t.Error("client without a key file reused the saved key")
+	}
+}
+
+func TestNewClient_KeyEnv(t *testing.T) {
+	kp, err := auth.GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, _ := kp.ExportPrivateKeyPEM()
+	t.Setenv("LCC_TEST_PRIVATE_KEY", keyPEM)
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:         "http://127.0.0.1:1",
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		KeyEnv:         "LCC_TEST_PRIVATE_KEY",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if want, _ := kp.GetFingerprint(); c.GetInstanceID() != want {
+		t.Errorf("instance ID = %s, want the provisioned key's %s", c.GetInstanceID(), want)
+	}
+}
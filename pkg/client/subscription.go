@@ -0,0 +1,278 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types on the subscription stream
+const (
+	// sseFeature carries a feature status (the body of a feature check,
+	// with feature_id set). Product-level limits use feature_id
+	// "__product__".
+	sseFeature = "feature"
+
+	// sseInvalidate carries {"feature_ids": [...]}; an empty list
+	// invalidates every cached feature
+	sseInvalidate = "invalidate"
+)
+
+// subscribeBackoff paces reconnects when the server does not send a retry
+// field
+var subscribeBackoff = RetryPolicy{BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+// FeatureChangeEvent is a feature update pushed by the LCC server
+type FeatureChangeEvent struct {
+	// FeatureID is the updated feature; empty when the server invalidated
+	// every feature
+	FeatureID string
+
+	// Status is the pushed status, already stored in the cache. It is nil
+	// when the server only invalidated the cached status, which is then
+	// re-checked on next use.
+	Status *FeatureStatus
+}
+
+// featureChangeNotifier dispatches pushed feature updates to handlers
+type featureChangeNotifier struct {
+	mu       sync.Mutex
+	hooks    *hookDispatcher
+	handlers []namedHook[func(FeatureChangeEvent)]
+}
+
+func (n *featureChangeNotifier) addHandler(fn func(FeatureChangeEvent)) {
+	h := namedHook[func(FeatureChangeEvent)]{name: n.hooks.name("OnFeatureChanged"), fn: fn}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handlers = append(n.handlers, h)
+}
+
+func (n *featureChangeNotifier) notify(ev FeatureChangeEvent) {
+	n.mu.Lock()
+	handlers := n.handlers
+	n.mu.Unlock()
+
+	for _, h := range handlers {
+		fn := h.fn
+		n.hooks.dispatch(h.name, func() { fn(ev) })
+	}
+}
+
+// OnFeatureChanged registers a callback fired for every feature update the
+// LCC server pushes on the subscription stream (SDKConfig.Subscribe). Unlike
+// OnFeatureStatusChange, which fires only when a refreshed status differs
+// from the cached one, it fires for every pushed update and invalidation.
+//
+// Callbacks run on the hook worker pool (see SetHookPolicy), in order for
+// each callback.
+func (c *Client) OnFeatureChanged(fn func(FeatureChangeEvent)) {
+	if fn == nil {
+		return
+	}
+	c.featureChanges.addHandler(fn)
+}
+
+// Subscribed reports whether the subscription stream is currently connected
+func (c *Client) Subscribed() bool {
+	return c.subscribed.Load()
+}
+
+// startSubscription opens the event stream in the background if
+// SDKConfig.Subscribe is set. The stream reconnects until Close.
+func (c *Client) startSubscription() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.subscribe || c.subscribeCancel != nil || c.offlineMode || c.grpc != nil || c.egressDisabled.Load() {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.subscribeCancel = cancel
+	go c.subscriptionLoop(ctx)
+}
+
+// stopSubscription closes the event stream
+func (c *Client) stopSubscription() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscribeCancel != nil {
+		c.subscribeCancel()
+		c.subscribeCancel = nil
+	}
+}
+
+// subscriptionState is carried across reconnects
+type subscriptionState struct {
+	lastEventID string
+	retry       time.Duration // server-requested reconnect delay
+	connected   bool          // a stream was established before
+}
+
+func (c *Client) subscriptionLoop(ctx context.Context) {
+	var st subscriptionState
+	for attempt := 0; ; attempt++ {
+		if c.background.wait(ctx) != nil {
+			return
+		}
+		err := c.streamEvents(ctx, &st, func() { attempt = 0 })
+		c.subscribed.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := st.retry
+		if delay <= 0 {
+			delay = subscribeBackoff.backoff(attempt)
+		}
+		debugLogf("Subscription: stream ended (%v), reconnecting in %s", err, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// streamEvents connects once and applies events until the stream ends
+func (c *Client) streamEvents(ctx context.Context, st *subscriptionState, connected func()) error {
+	if err := c.checkEgress("Subscribe"); err != nil {
+		return err
+	}
+	req, err := c.newSignedRequest(ctx, "GET", c.baseURL+"/api/v1/sdk/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if st.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", st.lastEventID)
+	}
+
+	// The stream is long-lived: keep the transport but drop the timeout
+	c.mu.RLock()
+	hc := *c.httpClient
+	c.mu.RUnlock()
+	hc.Timeout = 0
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("subscription failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	// Updates may have been missed while disconnected
+	if st.connected {
+		c.cache.expireAll()
+		c.clearDedup()
+	}
+	st.connected = true
+	c.subscribed.Store(true)
+	connected()
+	debugLogf("Subscription: connected")
+
+	return readEvents(resp.Body, st, c.applyEvent)
+}
+
+// readEvents parses a text/event-stream body, calling apply for each event
+func readEvents(r io.Reader, st *subscriptionState, apply func(event, data string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if len(data) > 0 {
+				apply(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			st.lastEventID = value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				st.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// applyEvent updates the cache from a pushed event and notifies handlers
+func (c *Client) applyEvent(event, data string) {
+	switch event {
+	case sseFeature:
+		var result featureCheckResponse
+		if err := json.Unmarshal([]byte(data), &result); err != nil || result.FeatureID == "" {
+			debugLogf("Subscription: ignoring malformed %s event: %v", event, err)
+			return
+		}
+		if c.serverPublicKey() != nil {
+			// Pushed statuses are unsigned: refetch instead of trusting them
+			c.clearDedup()
+			c.cache.expire(result.FeatureID)
+			c.featureChanges.notify(FeatureChangeEvent{FeatureID: result.FeatureID})
+			return
+		}
+		status := result.status()
+		prev := c.cache.setWithTTL(result.FeatureID, status, c.policyFor(result.FeatureID).cacheTTL)
+		c.clearDedup()
+		c.statusChanges.compare(result.FeatureID, prev, status)
+		c.observeDowngrade(result.FeatureID, prev, status)
+		c.featureChanges.notify(FeatureChangeEvent{FeatureID: result.FeatureID, Status: status})
+
+	case sseInvalidate:
+		var result struct {
+			FeatureIDs []string `json:"feature_ids"`
+		}
+		if err := json.Unmarshal([]byte(data), &result); err != nil {
+			debugLogf("Subscription: ignoring malformed %s event: %v", event, err)
+			return
+		}
+		c.clearDedup()
+		if len(result.FeatureIDs) == 0 {
+			c.cache.expireAll()
+			c.featureChanges.notify(FeatureChangeEvent{})
+			return
+		}
+		for _, id := range result.FeatureIDs {
+			c.cache.expire(id)
+			c.featureChanges.notify(FeatureChangeEvent{FeatureID: id})
+		}
+
+	default:
+		debugLogf("Subscription: ignoring %q event", event)
+	}
+}
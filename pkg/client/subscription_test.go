@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sseServer answers feature checks and streams events pushed to it
+type sseServer struct {
+	*httptest.Server
+	events      chan string // raw event blocks; "" ends the stream
+	checks      atomic.Int32
+	streams     atomic.Int32
+	lastEventID atomic.Value
+}
+
+func newSSEServer(t *testing.T) *sseServer {
+	s := &sseServer{events: make(chan string, 16)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/sdk/events":
+			if r.Header.Get("X-LCC-Signature") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.streams.Add(1)
+			s.lastEventID.Store(r.Header.Get("Last-Event-ID"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": connected\nretry: 10\n\n")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case ev := <-s.events:
+					if ev == "" {
+						return
+					}
+					fmt.Fprint(w, ev)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		case strings.HasSuffix(r.URL.Path, "/check"):
+			s.checks.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestClient_Subscription(t *testing.T) {
+	srv := newSSEServer(t)
+	c := newTestClient(t, srv.URL)
+	c.subscribe = true
+
+	pushed := make(chan FeatureChangeEvent, 8)
+	c.OnFeatureChanged(func(ev FeatureChangeEvent) { pushed <- ev })
+	var statusChanges atomic.Int32
+	c.OnFeatureStatusChange(func(FeatureStatusChange) { statusChanges.Add(1) })
+
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	waitFor(t, "subscription", c.Subscribed)
+
+	if status, err := c.CheckFeature("export"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+
+	// A pushed status replaces the cached one without a server check
+	srv.events <- "id: 7\nevent: feature\ndata: {\"feature_id\":\"export\",\"enabled\":false,\n" +
+		"data: \"reason\":\"feature_not_in_license\"}\n\n"
+	ev := <-pushed
+	if ev.FeatureID != "export" || ev.Status == nil || ev.Status.Enabled {
+		t.Fatalf("pushed event = %+v", ev)
+	}
+	if status, _ := c.CheckFeature("export"); status.Enabled {
+		t.Error("CheckFeature() after push should return the pushed status")
+	}
+	if got := srv.checks.Load(); got != 1 {
+		t.Errorf("server checks = %d, want 1", got)
+	}
+	c.hooks.wait()
+	if got := statusChanges.Load(); got != 1 {
+		t.Errorf("status change callbacks = %d, want 1", got)
+	}
+
+	// An invalidation makes the next check go to the server
+	srv.events <- "event: invalidate\ndata: {\"feature_ids\":[\"export\"]}\n\n"
+	if ev := <-pushed; ev.FeatureID != "export" || ev.Status != nil {
+		t.Fatalf("invalidate event = %+v", ev)
+	}
+	if status, _ := c.CheckFeature("export"); !status.Enabled || srv.checks.Load() != 2 {
+		t.Errorf("CheckFeature() after invalidate = %+v, server checks %d", status, srv.checks.Load())
+	}
+
+	// A dropped stream is re-established with the last event ID
+	srv.events <- ""
+	waitFor(t, "reconnect", func() bool { return srv.streams.Load() == 2 && c.Subscribed() })
+	if got := srv.lastEventID.Load(); got != "7" {
+		t.Errorf("Last-Event-ID = %v, want 7", got)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	waitFor(t, "disconnect", func() bool { return !c.Subscribed() })
+}
+
+func TestReadEvents(t *testing.T) {
+	var got []string
+	st := &subscriptionState{}
+	stream := ": keep-alive\r\nevent: feature\r\ndata: a\r\ndata: b\r\n\r\ndata: c\nid: 42\nretry: 250\n\nevent: empty\n\n"
+	err := readEvents(strings.NewReader(stream), st, func(event, data string) {
+		got = append(got, event+"="+data)
+	})
+	if err == nil {
+		t.Error("readEvents() should report the end of the stream")
+	}
+	if want := []string{"feature=a\nb", "=c"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	if st.lastEventID != "42" || st.retry != 250*time.Millisecond {
+		t.Errorf("state = %+v", st)
+	}
+}
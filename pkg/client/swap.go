@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// SwappableClient holds the Client a service enforces its license with and
+// replaces it without a gap in enforcement, e.g. to migrate to a new
+// configuration, LCC server or key pair. It implements LCCClient by calling
+// the current Client, so code written against LCCClient follows swaps.
+//
+// Example:
+//
+//	sc := client.NewSwappableClient(c)
+//	handler := api.New(sc) // takes a client.LCCClient
+//
+//	// later, on a configuration change
+//	if err := sc.SwapConfig(newCfg); err != nil {
+//	    log.Printf("license client not replaced: %v", err)
+//	}
+type SwappableClient struct {
+	swapMu  sync.Mutex // serializes swaps
+	current atomic.Pointer[Client]
+}
+
+var _ LCCClient = (*SwappableClient)(nil)
+
+// NewSwappableClient returns a SwappableClient whose current client is c
+func NewSwappableClient(c *Client) *SwappableClient {
+	s := &SwappableClient{}
+	s.current.Store(c)
+	return s
+}
+
+// Current returns the current client. Configure it with SetX, UseX and OnX
+// methods; to change what the next swap carries over, register on it.
+func (s *SwappableClient) Current() *Client {
+	return s.current.Load()
+}
+
+// SwapConfig builds a client from cfg, registers it if the current client
+// is registered, and swaps it in (see Swap). The new client is closed if
+// the swap fails.
+func (s *SwappableClient) SwapConfig(cfg *config.SDKConfig) error {
+	next, err := NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	if s.Current().Lifecycle() == StateRegistered {
+		if err := next.Register(); err != nil {
+			next.Close()
+			return fmt.Errorf("swap: %w", err)
+		}
+	}
+	if err := s.Swap(next); err != nil {
+		next.Close()
+		return err
+	}
+	return nil
+}
+
+// Swap replaces the current client with next, which the caller has built
+// and usually registered:
+//
+//  1. next's cache is warmed by checking every feature cached by the
+//     current client; if a check fails, Swap returns the error and the
+//     current client stays in place
+//  2. helpers (RegisterHelpers), callbacks (OnX) and transport middleware
+//     (Use) registered on the current client are added to next
+//  3. next becomes current
+//  4. held product-level slots move to next, counting against its limit
+//     with their owners and acquisition times; releasing them through the
+//     functions the old client returned frees them on next
+//  5. the old client is closed, which deregisters it
+//
+// Settings (SetX), pipeline stages and AcquireSlotWait callers still queued
+// on the old client are not carried over. Callers holding the old client
+// itself, rather than the SwappableClient, should stop using it.
+func (s *SwappableClient) Swap(next *Client) error {
+	if next == nil {
+		return errors.New("swap: next client is nil")
+	}
+	s.swapMu.Lock()
+	defer s.swapMu.Unlock()
+
+	old := s.current.Load()
+	if next == old {
+		return errors.New("swap: next client is the current client")
+	}
+	if state := next.Lifecycle(); state == StateClosed || state == StateDeactivated {
+		return &StateError{Op: "swap", State: state}
+	}
+
+	start := time.Now()
+	warmed, err := old.warmCache(next)
+	if err != nil {
+		return err
+	}
+	old.transferRegistrations(next)
+	s.current.Store(next)
+	slots := old.handOffSlots(next)
+	if err := old.Close(); err != nil {
+		debugLogf("WARNING: Swap: closing the old client: %v", err)
+	}
+
+	debugLogf("Swap: instance %s replaced by %s in %s (%d features warmed, %d slots moved)",
+		old.instanceID, next.instanceID, time.Since(start).Round(time.Millisecond), warmed, slots)
+	return nil
+}
+
+// warmCache checks on next every feature cached by c and returns their
+// number
+func (c *Client) warmCache(next *Client) (int, error) {
+	entries, _ := c.cache.snapshot()
+	for featureID := range entries {
+		if _, err := next.CheckFeature(featureID); err != nil {
+			return 0, fmt.Errorf("swap: warming feature %s: %w", featureID, err)
+		}
+	}
+	return len(entries), nil
+}
+
+// transferRegistrations registers c's helpers, callbacks and middleware
+// on next
+func (c *Client) transferRegistrations(next *Client) {
+	c.mu.RLock()
+	helpers := c.helpers
+	middleware := append([]Middleware(nil), c.middleware...)
+	c.mu.RUnlock()
+
+	if helpers != nil {
+		h := *helpers
+		if h.internalTPS {
+			// Track TPS on next, not on the retired client
+			h.TPSProvider = nil
+			h.internalTPS = false
+		}
+		if err := next.RegisterHelpers(&h); err != nil {
+			debugLogf("WARNING: Swap: %v", err)
+		}
+	}
+	if len(middleware) > 0 {
+		next.Use(middleware...)
+	}
+
+	for _, fn := range hookFuncs(&c.connection.mu, &c.connection.handlers) {
+		next.OnConnectionStateChange(fn)
+	}
+	for _, fn := range hookFuncs(&c.downgrades.mu, &c.downgrades.handlers) {
+		next.OnDowngrade(fn)
+	}
+	for _, fn := range hookFuncs(&c.helperGuard.mu, &c.helperGuard.onSlow) {
+		next.OnSlowHelper(fn)
+	}
+	for _, fn := range hookFuncs(&c.observers.mu, &c.observers.consume) {
+		next.OnConsume(fn)
+	}
+	for _, fn := range hookFuncs(&c.observers.mu, &c.observers.heartbeat) {
+		next.OnHeartbeat(fn)
+	}
+	for _, fn := range hookFuncs(&c.observers.mu, &c.observers.event) {
+		next.OnEvent(fn)
+	}
+	for _, fn := range hookFuncs(&c.quotaResets.mu, &c.quotaResets.handlers) {
+		next.OnQuotaReset(fn)
+	}
+	for _, fn := range hookFuncs(&c.quotaResets.mu, &c.quotaResets.states) {
+		next.OnQuotaStateChange(fn)
+	}
+	for _, fn := range hookFuncs(&c.statusChanges.mu, &c.statusChanges.handlers) {
+		next.OnFeatureStatusChange(fn)
+	}
+	for _, fn := range hookFuncs(&c.featureChanges.mu, &c.featureChanges.handlers) {
+		next.OnFeatureChanged(fn)
+	}
+}
+
+// hookFuncs returns the callbacks of *hooks, read under mu
+func hookFuncs[F any](mu sync.Locker, hooks *[]namedHook[F]) []F {
+	mu.Lock()
+	defer mu.Unlock()
+	fns := make([]F, len(*hooks))
+	for i, h := range *hooks {
+		fns[i] = h.fn
+	}
+	return fns
+}
+
+// handOffSlots moves c's held product-level slots to next and returns
+// their number. Their release functions then release them on next.
+func (c *Client) handOffSlots(next *Client) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next.mu.Lock()
+	defer next.mu.Unlock()
+
+	if len(c.slotHolders) == 0 {
+		return 0
+	}
+	if c.slotForwards == nil {
+		c.slotForwards = make(map[uint64]ReleaseFunc)
+	}
+	key, nextKey := c.productSlotKey(), next.productSlotKey()
+	moved := 0
+	for id, holder := range c.slotHolders {
+		h := *holder
+		concurrencyState[nextKey]++
+		release := next.newProductSlotLocked(&h)
+		next.slotHolders[h.ID].AcquiredAt = holder.AcquiredAt
+		c.slotForwards[id] = release
+
+		c.removeSlotHolder(id)
+		if cur := concurrencyState[key]; cur <= 1 {
+			delete(concurrencyState, key)
+		} else {
+			concurrencyState[key] = cur - 1
+		}
+		moved++
+	}
+	return moved
+}
+
+// Register registers the current client (see Client.Register)
+func (s *SwappableClient) Register() error { return s.Current().Register() }
+
+// Close closes the current client (see Client.Close)
+func (s *SwappableClient) Close() error { return s.Current().Close() }
+
+// GetInstanceID returns the instance ID of the current client
+func (s *SwappableClient) GetInstanceID() string { return s.Current().GetInstanceID() }
+
+// State returns the connection state of the current client
+func (s *SwappableClient) State() ConnectionState { return s.Current().State() }
+
+// CheckFeature checks a feature with the current client
+func (s *SwappableClient) CheckFeature(featureID string) (*FeatureStatus, error) {
+	return s.Current().CheckFeature(featureID)
+}
+
+// CheckFeatureCtx checks a feature with the current client
+func (s *SwappableClient) CheckFeatureCtx(ctx context.Context, featureID string) (*FeatureStatus, error) {
+	return s.Current().CheckFeatureCtx(ctx, featureID)
+}
+
+// CheckGroup checks a feature group with the current client
+func (s *SwappableClient) CheckGroup(groupID string) (*GroupStatus, error) {
+	return s.Current().CheckGroup(groupID)
+}
+
+// ProductStatus returns the product status from the current client
+func (s *SwappableClient) ProductStatus() (*FeatureStatus, error) {
+	return s.Current().ProductStatus()
+}
+
+// Consume consumes product quota with the current client
+func (s *SwappableClient) Consume(amount int) (bool, int, error) {
+	return s.Current().Consume(amount)
+}
+
+// ConsumeDimension consumes a quota dimension with the current client
+func (s *SwappableClient) ConsumeDimension(featureID, dimension string, n int) (bool, int, error) {
+	return s.Current().ConsumeDimension(featureID, dimension, n)
+}
+
+// ConsumeWithContext consumes product quota with the current client
+func (s *SwappableClient) ConsumeWithContext(ctx context.Context, args ...interface{}) (bool, int, error) {
+	return s.Current().ConsumeWithContext(ctx, args...)
+}
+
+// CheckCapacity checks the capacity limit with the current client
+func (s *SwappableClient) CheckCapacity(currentUsed int) (bool, int, error) {
+	return s.Current().CheckCapacity(currentUsed)
+}
+
+// CheckCapacityWithHelper checks the capacity limit with the current client
+func (s *SwappableClient) CheckCapacityWithHelper() (bool, int, error) {
+	return s.Current().CheckCapacityWithHelper()
+}
+
+// CheckTPS checks the TPS limit with the current client
+func (s *SwappableClient) CheckTPS() (bool, float64, error) {
+	return s.Current().CheckTPS()
+}
+
+// AcquireSlot acquires a slot from the current client. A slot still held
+// when the client is swapped moves to its replacement.
+func (s *SwappableClient) AcquireSlot() (ReleaseFunc, bool, error) {
+	return s.Current().AcquireSlot()
+}
+
+// AcquireSlotContext acquires a slot from the current client
+func (s *SwappableClient) AcquireSlotContext(ctx context.Context) (ReleaseFunc, bool, error) {
+	return s.Current().AcquireSlotContext(ctx)
+}
+
+// AcquireSlotWait acquires a slot from the current client
+func (s *SwappableClient) AcquireSlotWait(ctx context.Context, maxWait time.Duration) (ReleaseFunc, bool, error) {
+	return s.Current().AcquireSlotWait(ctx, maxWait)
+}
+
+// ReportUsage reports usage with the current client
+func (s *SwappableClient) ReportUsage(featureID string, amount float64) error {
+	return s.Current().ReportUsage(featureID, amount)
+}
+
+// RegisterHelpers registers helpers on the current client; later swaps
+// carry them over
+func (s *SwappableClient) RegisterHelpers(helpers *HelperFunctions) error {
+	return s.Current().RegisterHelpers(helpers)
+}
+
+// LimitError builds a LimitExceededError with the current client
+func (s *SwappableClient) LimitError(limit string, err error) *LimitExceededError {
+	return s.Current().LimitError(limit, err)
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSwappableClient_Swap(t *testing.T) {
+	old := newTestClient(t, newConcurrencyServer(t, 2).URL)
+	consumed := make(chan ConsumeEvent, 1)
+	old.OnConsume(func(ev ConsumeEvent) { consumed <- ev })
+	if err := old.RegisterHelpers(&HelperFunctions{CapacityCounter: func() int { return 3 }}); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := NewSwappableClient(old)
+	release, ok, err := old.AcquireSlotAs(context.Background(), "export", nil)
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlotAs() = %v, %v", ok, err)
+	}
+	acquiredAt := old.ActiveSlots()[0].AcquiredAt
+
+	var mu sync.Mutex
+	var paths []string
+	srv := newConcurrencyServer(t, 2)
+	next := newTestClient(t, srv.URL)
+	next.Use(func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			paths = append(paths, r.URL.Path)
+			mu.Unlock()
+			return rt.RoundTrip(r)
+		})
+	})
+
+	if err := sc.Swap(next); err != nil {
+		t.Fatalf("Swap() error = %v", err)
+	}
+	if sc.Current() != next {
+		t.Fatal("Current() is not the new client")
+	}
+	if state := old.Lifecycle(); state != StateClosed {
+		t.Errorf("old client Lifecycle() = %v, want closed", state)
+	}
+	mu.Lock()
+	warmed := len(paths)
+	mu.Unlock()
+	if warmed == 0 {
+		t.Error("Swap() did not warm the new client's cache")
+	}
+
+	// The held slot moved and counts against the new client's limit
+	slots := next.ActiveSlots()
+	if len(slots) != 1 || slots[0].Owner != "export" || !slots[0].AcquiredAt.Equal(acquiredAt) {
+		t.Fatalf("new client ActiveSlots() = %+v", slots)
+	}
+	if n := len(old.ActiveSlots()); n != 0 {
+		t.Errorf("old client ActiveSlots() = %d holders, want 0", n)
+	}
+	releaseB, ok, err := sc.AcquireSlot()
+	if err != nil || !ok {
+		t.Fatalf("AcquireSlot() = %v, %v", ok, err)
+	}
+	defer releaseB()
+	if _, ok, _ := sc.AcquireSlot(); ok {
+		t.Error("AcquireSlot() over the limit succeeded")
+	}
+	release()
+	if n := len(next.ActiveSlots()); n != 1 {
+		t.Errorf("ActiveSlots() after releasing the moved slot = %d holders, want 1", n)
+	}
+
+	// Registrations were carried over
+	if n := next.helpers.CapacityCounter(); n != 3 {
+		t.Errorf("CapacityCounter() = %d, want 3", n)
+	}
+	sc.Consume(1)
+	select {
+	case <-consumed:
+	case <-time.After(2 * time.Second):
+		t.Error("OnConsume callback not called on the new client")
+	}
+}
+
+func TestSwappableClient_SwapWarmFailure(t *testing.T) {
+	old := newTestClient(t, newConcurrencyServer(t, 2).URL)
+	if _, err := old.CheckFeature("export"); err != nil {
+		t.Fatal(err)
+	}
+	sc := NewSwappableClient(old)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+	next := newTestClient(t, srv.URL)
+
+	if err := sc.Swap(next); err == nil {
+		t.Fatal("Swap() to an unusable client succeeded")
+	}
+	if sc.Current() != old {
+		t.Error("Current() changed after a failed swap")
+	}
+	if state := old.Lifecycle(); state == StateClosed {
+		t.Error("old client closed after a failed swap")
+	}
+	if err := sc.Swap(old); err == nil {
+		t.Error("Swap() to the current client succeeded")
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import "time"
+
+// Telemetry is the live state of the client sent with each heartbeat, so
+// the LCC dashboard can show load and buffering per instance. Counters are
+// totals since the client started.
+type Telemetry struct {
+	// Valid cached feature statuses, and checks answered and missed by
+	// the cache
+	CacheEntries int    `json:"cache_entries"`
+	CacheHits    uint64 `json:"cache_hits"`
+	CacheMisses  uint64 `json:"cache_misses"`
+
+	// CurrentTPS is the TPSProvider helper's value, or the rate measured
+	// by the SDK
+	CurrentTPS float64 `json:"current_tps"`
+
+	// HeldSlots is the number of concurrency slots held
+	HeldSlots int `json:"held_slots"`
+
+	// Usage not yet acknowledged by the server: pending usage ledger
+	// events, and units used from the quota lease but not reconciled
+	PendingUsageEvents int `json:"pending_usage_events"`
+	UnreconciledUnits  int `json:"unreconciled_units"`
+}
+
+// Telemetry returns the telemetry the next heartbeat reports
+func (c *Client) Telemetry() Telemetry {
+	t := Telemetry{
+		CacheEntries: c.cache.size(time.Now()),
+		CacheHits:    c.cache.hits.Load(),
+		CacheMisses:  c.cache.misses.Load(),
+	}
+	if tps, err := c.getCurrentTPS(); err == nil {
+		t.CurrentTPS = tps
+	}
+
+	c.mu.RLock()
+	t.HeldSlots = len(c.slotHolders)
+	c.mu.RUnlock()
+
+	if l := c.ledger(); l != nil {
+		t.PendingUsageEvents = len(l.list(UsagePending))
+	}
+	c.leases.mu.Lock()
+	if l := c.leases.lease; l != nil && l.Used > c.leases.reconciled {
+		t.UnreconciledUnits = l.Used - c.leases.reconciled
+	}
+	c.leases.mu.Unlock()
+	return t
+}
+
+// size returns the number of statuses still valid at now
+func (fc *featureCache) size(now time.Time) int {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	n := 0
+	for _, entry := range fc.data {
+		if entry.expiresAt.After(now) {
+			n++
+		}
+	}
+	return n
+}
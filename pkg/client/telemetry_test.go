@@ -0,0 +1,110 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func TestClient_HeartbeatTelemetry(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		telemetry Telemetry
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/sdk/heartbeat":
+			var body struct {
+				Telemetry Telemetry `json:"telemetry"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			telemetry = body.Telemetry
+			mu.Unlock()
+		case r.URL.Path == "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled": true,
+				"limits":  map[string]interface{}{"max_concurrency": 3},
+			})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/sdk/features/"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.RegisterHelpers(&HelperFunctions{
+		CapacityCounter: func() int { return 0 },
+		TPSProvider:     func() float64 { return 42 },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.CheckFeature("reports"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	defer release()
+
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if telemetry.CacheEntries < 1 || telemetry.CacheHits < 2 || telemetry.CacheMisses < 1 {
+		t.Errorf("cache telemetry = %+v", telemetry)
+	}
+	if telemetry.CurrentTPS != 42 || telemetry.HeldSlots != 1 {
+		t.Errorf("telemetry = %+v, want 42 TPS and 1 held slot", telemetry)
+	}
+}
+
+func TestClient_HeartbeatInterval(t *testing.T) {
+	var heartbeats atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/sdk/heartbeat" {
+			heartbeats.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(&config.SDKConfig{
+		LCCURL:            srv.URL,
+		ProductID:         "test-app",
+		ProductVersion:    "1.0.0",
+		HeartbeatInterval: time.Hour,
+		HeartbeatJitter:   0.5,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := heartbeats.Load(); n != 0 {
+		t.Fatalf("%d heartbeats within the configured hour", n)
+	}
+
+	// A new interval reschedules the running heartbeat
+	c.SetHeartbeatInterval(10 * time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for heartbeats.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := heartbeats.Load(); n < 3 {
+		t.Errorf("%d heartbeats after SetHeartbeatInterval(10ms), want at least 3", n)
+	}
+}
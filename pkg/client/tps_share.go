@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// TPSShare is this instance's portion of a fleet-wide TPS limit, assigned by
+// the LCC server in heartbeat responses when TPS partitioning is enabled.
+// The server rebalances shares as instances join and leave.
+type TPSShare struct {
+	// MaxTPS is the TPS this instance may use
+	MaxTPS float64 `json:"max_tps"`
+
+	// Instances is the number of live instances the limit is split across
+	Instances int `json:"instances,omitempty"`
+
+	// ExpiresAt is when the assignment lapses (Unix seconds, optional)
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+// heartbeatResponse is the optional body of a heartbeat response
+type heartbeatResponse struct {
+	TPSShare *TPSShare    `json:"tps_share"`
+	Cluster  *ClusterInfo `json:"cluster"`
+
+	SDKAdvisory *SDKAdvisory `json:"sdk_advisory"`
+
+	// Commands the server asks the instance to run
+	Commands []heartbeatCommand `json:"commands"`
+}
+
+// heartbeatCommand is a server request carried by a heartbeat response
+type heartbeatCommand struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Heartbeat command types
+const (
+	// commandUploadAudit asks for the audit trail (see UploadAudit)
+	commandUploadAudit = "upload_audit"
+)
+
+// runHeartbeatCommand runs cmd in the background, so the heartbeat loop
+// is not held up
+func (c *Client) runHeartbeatCommand(cmd heartbeatCommand) {
+	switch cmd.Type {
+	case commandUploadAudit:
+		go func() {
+			ctx := context.Background()
+			if err := c.background.wait(ctx); err != nil {
+				return
+			}
+			if err := c.uploadAudit(ctx, cmd.ID); err != nil {
+				debugLogf("WARNING: heartbeat command %s (%s) failed: %v", cmd.ID, cmd.Type, err)
+			}
+		}()
+	default:
+		debugLogf("Ignoring unknown heartbeat command %s (%s)", cmd.ID, cmd.Type)
+	}
+}
+
+// tpsShareState is the most recent share and when it stops being trusted
+type tpsShareState struct {
+	share      TPSShare
+	validUntil time.Time
+}
+
+// TPSShare returns the current per-instance TPS assignment. It returns false
+// if partitioning is disabled or no unexpired assignment has been received.
+func (c *Client) TPSShare() (TPSShare, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tpsShare == nil || !time.Now().Before(c.tpsShare.validUntil) {
+		return TPSShare{}, false
+	}
+	return c.tpsShare.share, true
+}
+
+// effectiveMaxTPS returns the TPS limit the local limiter enforces: the
+// instance share when one is assigned, otherwise the fleet-wide limit.
+func (c *Client) effectiveMaxTPS(fleetMax float64) float64 {
+	share, ok := c.TPSShare()
+	if !ok {
+		return fleetMax
+	}
+	if fleetMax > 0 && share.MaxTPS > fleetMax {
+		return fleetMax
+	}
+	return share.MaxTPS
+}
+
+// applyHeartbeatResponse records a TPS share, cluster assignment and SDK
+// advisory from a heartbeat response body
+func (c *Client) applyHeartbeatResponse(body io.Reader) {
+	var resp heartbeatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return
+	}
+	if resp.TPSShare != nil && c.tpsPartitioning {
+		c.applyTPSShare(*resp.TPSShare)
+	}
+	if resp.Cluster != nil && c.clusterID != "" {
+		c.applyCluster(*resp.Cluster)
+	}
+	if resp.SDKAdvisory != nil && c.sdkUpdateCheck {
+		c.applySDKAdvisory(*resp.SDKAdvisory, SDKVersion())
+	}
+	for _, cmd := range resp.Commands {
+		c.runHeartbeatCommand(cmd)
+	}
+}
+
+// applyTPSShare records a TPS share assigned by the server. Without an
+// explicit expiry a share is trusted for three heartbeat intervals, after
+// which the fleet-wide limit applies again.
+func (c *Client) applyTPSShare(share TPSShare) {
+	if share.MaxTPS <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interval := c.heartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	validUntil := time.Now().Add(3 * interval)
+	if share.ExpiresAt > 0 {
+		validUntil = time.Unix(share.ExpiresAt, 0)
+	}
+
+	if c.tpsShare == nil || c.tpsShare.share.MaxTPS != share.MaxTPS {
+		debugLogf("TPS share assigned: %.2f (%d instances)", share.MaxTPS, share.Instances)
+	}
+	c.tpsShare = &tpsShareState{share: share, validUntil: validUntil}
+}
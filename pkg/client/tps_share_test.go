@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_TPSPartitioning(t *testing.T) {
+	var instances atomic.Int32
+	instances.Store(4)
+	var reported atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/heartbeat":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["current_tps"]; ok && body["tps_partitioning"] == true {
+				reported.Store(true)
+			}
+			n := instances.Load()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"tps_share": map[string]interface{}{"max_tps": 100 / float64(n), "instances": n},
+			})
+		case "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "limits": map[string]interface{}{"max_tps": 100}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.tpsPartitioning = true
+	current := 40.0
+	if err := c.RegisterHelpers(&HelperFunctions{
+		TPSProvider:     func() float64 { return current },
+		CapacityCounter: func() int { return 0 },
+	}); err != nil {
+		t.Fatalf("RegisterHelpers() error = %v", err)
+	}
+
+	// Before any assignment the fleet-wide limit applies
+	if ok, max, _ := c.CheckTPS(); !ok || max != 100 {
+		t.Errorf("CheckTPS() before share = %v, %v; want allowed at 100", ok, max)
+	}
+
+	if err := c.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat() error = %v", err)
+	}
+	if !reported.Load() {
+		t.Error("heartbeat did not request partitioning or report current TPS")
+	}
+	share, ok := c.TPSShare()
+	if !ok || share.MaxTPS != 25 || share.Instances != 4 {
+		t.Fatalf("TPSShare() = %+v, %v; want 25 TPS across 4", share, ok)
+	}
+	if ok, max, _ := c.CheckTPS(); ok || max != 25 {
+		t.Errorf("CheckTPS() with share = %v, %v; want denied at 25", ok, max)
+	}
+
+	// An instance leaves; the next heartbeat rebalances
+	instances.Store(2)
+	_ = c.sendHeartbeat()
+	if ok, max, _ := c.CheckTPS(); !ok || max != 50 {
+		t.Errorf("CheckTPS() after rebalance = %v, %v; want allowed at 50", ok, max)
+	}
+
+	// An expired share falls back to the fleet-wide limit
+	c.mu.Lock()
+	c.tpsShare.validUntil = time.Now().Add(-time.Second)
+	c.mu.Unlock()
+	if _, ok := c.TPSShare(); ok {
+		t.Error("TPSShare() should report expired share as absent")
+	}
+	if ok, max, _ := c.CheckTPS(); !ok || max != 100 {
+		t.Errorf("CheckTPS() after expiry = %v, %v; want allowed at 100", ok, max)
+	}
+}
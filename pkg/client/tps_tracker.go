@@ -15,6 +15,9 @@ type tpsTracker struct {
 	mu       sync.RWMutex
 	requests []time.Time
 	window   time.Duration
+
+	// Counts for rate limits in longer windows
+	windows rateCounters
 }
 
 // newTPSTracker creates a new TPS tracker with a 1-second window
@@ -33,6 +36,7 @@ func (t *tpsTracker) RecordRequest() {
 
 	now := time.Now()
 	t.requests = append(t.requests, now)
+	t.windows.record(now, 1)
 
 	// Clean old requests outside the window
 	// This prevents unbounded memory growth
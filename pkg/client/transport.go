@@ -0,0 +1,174 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Middleware wraps the transport used for LCC server calls, e.g. to add
+// tracing spans, auth headers or request logging
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Use adds transport middleware. The first middleware added is the
+// outermost: it sees each request first and each response last. Middleware
+// runs below the SDK's retry loop, so it sees every attempt, and after
+// request signing, so headers it adds are not covered by the signature.
+//
+// The middleware wraps the transport of the configured HTTP client
+// (SetHTTPClient), keeping its TLS and connection settings; the client
+// passed to SetHTTPClient is not modified.
+//
+// Example:
+//
+//	client.Use(func(next http.RoundTripper) http.RoundTripper {
+//	    return otelhttp.NewTransport(next)
+//	})
+func (c *Client) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range mw {
+		if m != nil {
+			c.middleware = append(c.middleware, m)
+		}
+	}
+	c.applyMiddlewareLocked()
+}
+
+// applyMiddlewareLocked rebuilds c.httpClient from c.baseHTTPClient, the
+// server pins, the endpoint pool and the middleware chain. Caller holds
+// c.mu.
+func (c *Client) applyMiddlewareLocked() {
+	pinned := c.pins.active()
+	if !pinned && c.pinned != nil {
+		c.pinned.CloseIdleConnections()
+		c.pinned = nil
+	}
+	if len(c.middleware) == 0 && c.endpoints == nil && !pinned {
+		c.httpClient = c.baseHTTPClient
+		return
+	}
+
+	transport := c.baseHTTPClient.Transport
+	if pinned {
+		// Reuse the pinned clone while the base transport is unchanged
+		if c.pinned == nil || !c.pinned.wraps(transport) {
+			if c.pinned != nil {
+				c.pinned.CloseIdleConnections()
+			}
+			c.pinned = newPinnedTransport(transport, c.pins)
+		}
+		transport = c.pinned
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if c.endpoints != nil {
+		transport = &endpointTransport{pool: c.endpoints, next: transport}
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+
+	wrapped := *c.baseHTTPClient
+	wrapped.Transport = transport
+	c.httpClient = &wrapped
+}
+
+// closeIdleConnections closes idle connections of the underlying transport,
+// which middleware wrappers generally do not pass through
+func (c *Client) closeIdleConnections() {
+	c.mu.RLock()
+	base, current, pinned := c.baseHTTPClient, c.httpClient, c.pinned
+	c.mu.RUnlock()
+
+	current.CloseIdleConnections()
+	if base != current {
+		base.CloseIdleConnections()
+	}
+	if pinned != nil {
+		pinned.CloseIdleConnections()
+	}
+}
+
+// SetTimeout changes the timeout of LCC server calls at runtime, for HTTP
+// and gRPC alike. Requests in flight keep the timeout they started with.
+// The client passed to SetHTTPClient is not modified.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.swapHTTPClient(func(old *http.Client) *http.Client {
+		updated := *old
+		updated.Timeout = timeout
+		return &updated
+	})
+	if t := c.grpcTransport(); t != nil {
+		t.setTimeout(timeout)
+	}
+}
+
+// Timeout returns the timeout of LCC server calls
+func (c *Client) Timeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseHTTPClient.Timeout
+}
+
+// SetTLSConfig replaces the TLS configuration of HTTP calls to the LCC
+// server, e.g. to rotate client certificates or trusted CAs. New
+// connections use cfg; pooled connections made with the previous
+// configuration are closed once idle. The transport of the configured HTTP
+// client is cloned, so it must be an *http.Transport (or nil, for the
+// default transport). gRPC connections are configured with SetGRPCConn.
+func (c *Client) SetTLSConfig(cfg *tls.Config) error {
+	var err error
+	old := c.swapHTTPClient(func(old *http.Client) *http.Client {
+		var transport *http.Transport
+		transport, err = cloneTransport(old.Transport, "TLS config")
+		if err != nil {
+			return old
+		}
+		transport.TLSClientConfig = cfg.Clone()
+
+		updated := *old
+		updated.Transport = transport
+		return &updated
+	})
+	if err == nil {
+		closeIdleTransport(old)
+	}
+	return err
+}
+
+// cloneTransport clones base, an *http.Transport or nil for the default
+// transport, to change its setting
+func cloneTransport(base http.RoundTripper, setting string) (*http.Transport, error) {
+	switch b := base.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return b.Clone(), nil
+	}
+	return nil, fmt.Errorf("cannot set %s on transport %T", setting, base)
+}
+
+// swapHTTPClient replaces the base HTTP client with update(current),
+// rebuilds the middleware chain and returns the previous base client. Calls
+// in flight hold their own reference (see doWithRetry) and finish on the
+// previous client.
+func (c *Client) swapHTTPClient(update func(old *http.Client) *http.Client) *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.baseHTTPClient
+	c.baseHTTPClient = update(old)
+	c.applyMiddlewareLocked()
+	return old
+}
+
+// closeIdleTransport closes idle connections of a replaced client's
+// transport. Connections in use are unaffected. The shared default
+// transport is left alone.
+func closeIdleTransport(old *http.Client) {
+	if old.Transport != nil {
+		old.CloseIdleConnections()
+	}
+}
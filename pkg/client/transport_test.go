@@ -0,0 +1,176 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestClient_Use(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "trace-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond})
+	custom := &http.Client{Timeout: 5 * time.Second}
+	c.SetHTTPClient(custom)
+
+	var mu sync.Mutex
+	var trace []string
+	named := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				mu.Lock()
+				trace = append(trace, name)
+				mu.Unlock()
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	c.Use(named("outer"), func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Trace-Id", "trace-1")
+			return next.RoundTrip(r)
+		})
+	})
+	c.Use(named("inner"))
+
+	if status, err := c.CheckFeature("reports"); err != nil || !status.Enabled {
+		t.Fatalf("CheckFeature() = %+v, %v", status, err)
+	}
+	want := []string{"outer", "inner", "outer", "inner"} // one pass per attempt
+	if len(trace) != len(want) {
+		t.Fatalf("middleware trace = %v, want %v", trace, want)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("middleware trace = %v, want %v", trace, want)
+		}
+	}
+	if custom.Transport != nil {
+		t.Error("Use modified the client passed to SetHTTPClient")
+	}
+	if c.httpClient.Timeout != custom.Timeout {
+		t.Errorf("wrapped client timeout = %v, want %v", c.httpClient.Timeout, custom.Timeout)
+	}
+}
+
+func TestClient_SetTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, err := c.queryFeature("reports"); err == nil {
+		t.Fatal("queryFeature() should fail before the server CA is trusted")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	if err := c.SetTLSConfig(&tls.Config{RootCAs: roots}); err != nil {
+		t.Fatalf("SetTLSConfig() error = %v", err)
+	}
+	if status, err := c.queryFeature("reports"); err != nil || !status.Enabled {
+		t.Fatalf("queryFeature() after SetTLSConfig = %+v, %v", status, err)
+	}
+
+	custom := roundTripFunc(http.DefaultTransport.RoundTrip)
+	c.SetHTTPClient(&http.Client{Transport: custom})
+	if err := c.SetTLSConfig(&tls.Config{}); err == nil {
+		t.Error("SetTLSConfig() on a custom RoundTripper should fail")
+	}
+}
+
+func TestClient_SetTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	custom := &http.Client{Timeout: 5 * time.Second}
+	c.SetHTTPClient(custom)
+	c.SetTimeout(20 * time.Millisecond)
+	if c.Timeout() != 20*time.Millisecond || custom.Timeout != 5*time.Second {
+		t.Errorf("Timeout() = %v, custom client timeout = %v", c.Timeout(), custom.Timeout)
+	}
+	if _, err := c.queryFeature("reports"); err == nil {
+		t.Error("queryFeature() should time out")
+	}
+
+	c.SetHTTPClient(nil)
+	if c.Timeout() != 20*time.Millisecond {
+		t.Errorf("SetHTTPClient(nil) timeout = %v, want the current 20ms", c.Timeout())
+	}
+}
+
+// TestClient_ReconfigureConcurrent swaps transports while calls and the
+// heartbeat are running; run with -race
+func TestClient_ReconfigureConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "status": "ok"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	c.SetHeartbeatInterval(time.Millisecond)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_, _ = c.queryFeature("reports")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		switch i % 3 {
+		case 0:
+			c.SetHTTPClient(&http.Client{Timeout: time.Second, Transport: http.DefaultTransport.(*http.Transport).Clone()})
+		case 1:
+			c.SetTimeout(time.Duration(i) * time.Second)
+		case 2:
+			_ = c.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if status, err := c.queryFeature("reports"); err != nil || !status.Enabled {
+		t.Errorf("queryFeature() after reconfiguration = %+v, %v", status, err)
+	}
+}
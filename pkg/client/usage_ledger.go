@@ -0,0 +1,509 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/store"
+)
+
+// UsageEventState is the delivery state of a usage event
+type UsageEventState string
+
+// Usage event delivery states
+const (
+	// UsagePending events are recorded but not yet acknowledged
+	UsagePending UsageEventState = "pending"
+	// UsageAcked events were acknowledged by the server
+	UsageAcked UsageEventState = "acked"
+	// UsageFailed events were rejected or ran out of delivery attempts
+	UsageFailed UsageEventState = "failed"
+)
+
+const (
+	// defaultUsageMaxAttempts is the number of deliveries before an event
+	// is marked failed
+	defaultUsageMaxAttempts = 10
+
+	// maxAckedUsageEvents bounds acknowledged events kept for UsageEvents
+	maxAckedUsageEvents = 1000
+)
+
+// UsageEvent is a usage report tracked by the usage ledger. ID is sent as
+// the idempotency key, so redelivering an event the server already counted
+// does not count it twice.
+type UsageEvent struct {
+	ID        string          `json:"id"`
+	FeatureID string          `json:"feature_id"`
+	Dimension string          `json:"dimension,omitempty"`
+	Amount    int             `json:"amount"`
+	Timestamp time.Time       `json:"timestamp"`
+	State     UsageEventState `json:"state"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	AckedAt   time.Time       `json:"acked_at,omitempty"`
+}
+
+// usageLedger tracks usage events until the server acknowledges them. With
+// a journal, every state change is appended to a JSON-lines file so pending
+// events survive a crash and are redelivered with the same ID. With a
+// Store, the unacknowledged events are rewritten under usageStoreKey on
+// every change instead.
+type usageLedger struct {
+	mu          sync.Mutex
+	journal     string // "" keeps the ledger in memory only
+	backend     store.Store
+	maxAttempts int
+	events      map[string]*UsageEvent
+	order       []string // event IDs in recording order
+	delivering  map[string]bool
+}
+
+// openUsageLedger loads the journal at path, keeping events that are not
+// acknowledged, and compacts it
+func openUsageLedger(path string) (*usageLedger, error) {
+	l := &usageLedger{
+		journal:     path,
+		maxAttempts: defaultUsageMaxAttempts,
+		events:      make(map[string]*UsageEvent),
+		delivering:  make(map[string]bool),
+	}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to open usage journal: %w", err)
+	default:
+		err := l.replay(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// openUsageLedgerStore loads the events saved in s, keeping those that are
+// not acknowledged
+func openUsageLedgerStore(s store.Store) (*usageLedger, error) {
+	l, _ := openUsageLedger("")
+	l.backend = s
+
+	data, err := s.Get(usageStoreKey)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to load usage events: %w", err)
+	default:
+		if err := l.replay(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// replay applies journal records; the last record of an event wins
+func (l *usageLedger) replay(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var ev UsageEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			// A torn final write after a crash; earlier records are intact
+			debugLogf("Usage journal: skipping unreadable record: %v", err)
+			continue
+		}
+		if _, seen := l.events[ev.ID]; !seen {
+			l.order = append(l.order, ev.ID)
+		}
+		l.events[ev.ID] = &ev
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read usage journal: %w", err)
+	}
+
+	// Acknowledged events need no redelivery
+	kept := l.order[:0]
+	for _, id := range l.order {
+		if l.events[id].State == UsageAcked {
+			delete(l.events, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	l.order = kept
+	return nil
+}
+
+// compact rewrites the journal with the current events
+func (l *usageLedger) compact() error {
+	tmp := l.journal + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to compact usage journal: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	for _, id := range l.order {
+		if err := enc.Encode(l.events[id]); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to compact usage journal: %w", err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to compact usage journal: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to compact usage journal: %w", err)
+	}
+	return os.Rename(tmp, filepath.Clean(l.journal))
+}
+
+// persistLocked appends a snapshot of ev to the journal, or saves the
+// ledger to its Store. Caller holds l.mu.
+func (l *usageLedger) persistLocked(ev *UsageEvent) error {
+	if l.backend != nil {
+		return l.saveLocked(ev)
+	}
+	if l.journal == "" {
+		return nil
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.journal, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write usage journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage journal: %w", err)
+	}
+	return f.Sync()
+}
+
+// saveLocked writes the unacknowledged events, including ev if it is not
+// recorded yet, to the Store. Caller holds l.mu.
+func (l *usageLedger) saveLocked(ev *UsageEvent) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	events := make([]*UsageEvent, 0, len(l.order)+1)
+	for _, id := range l.order {
+		events = append(events, l.events[id])
+	}
+	if _, ok := l.events[ev.ID]; !ok {
+		events = append(events, ev)
+	}
+	for _, e := range events {
+		if e.State == UsageAcked {
+			continue
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	if err := l.backend.Put(usageStoreKey, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write usage events: %w", err)
+	}
+	return nil
+}
+
+// add records a new pending event with the given ID durably
+func (l *usageLedger) add(id, featureID, dimension string, amount int) (*UsageEvent, error) {
+	ev := &UsageEvent{
+		ID:        id,
+		FeatureID: featureID,
+		Dimension: dimension,
+		Amount:    amount,
+		Timestamp: time.Now().UTC(),
+		State:     UsagePending,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.persistLocked(ev); err != nil {
+		return nil, err
+	}
+	l.events[ev.ID] = ev
+	l.order = append(l.order, ev.ID)
+	return ev, nil
+}
+
+// claim marks a pending event as being delivered and returns a copy, or
+// false if it is not pending or another delivery is in flight
+func (l *usageLedger) claim(id string) (UsageEvent, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ev, ok := l.events[id]
+	if !ok || ev.State != UsagePending || l.delivering[id] {
+		return UsageEvent{}, false
+	}
+	l.delivering[id] = true
+	return *ev, true
+}
+
+// settle records the outcome of a delivery attempt
+func (l *usageLedger) settle(id string, acked, permanent bool, deliveryErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.delivering, id)
+
+	ev, ok := l.events[id]
+	if !ok {
+		return
+	}
+	ev.Attempts++
+	switch {
+	case acked:
+		ev.State = UsageAcked
+		ev.AckedAt = time.Now().UTC()
+		ev.LastError = ""
+	case permanent || ev.Attempts >= l.maxAttempts:
+		ev.State = UsageFailed
+		ev.LastError = deliveryErr.Error()
+	default:
+		ev.LastError = deliveryErr.Error()
+	}
+	if err := l.persistLocked(ev); err != nil {
+		debugLogf("Usage journal: %v", err)
+	}
+	if acked {
+		l.pruneAckedLocked()
+	}
+}
+
+// pruneAckedLocked forgets the oldest acknowledged events beyond the
+// retention bound. Caller holds l.mu.
+func (l *usageLedger) pruneAckedLocked() {
+	acked := 0
+	for _, id := range l.order {
+		if l.events[id].State == UsageAcked {
+			acked++
+		}
+	}
+	if acked <= maxAckedUsageEvents {
+		return
+	}
+
+	kept := l.order[:0]
+	for _, id := range l.order {
+		if acked > maxAckedUsageEvents && l.events[id].State == UsageAcked {
+			delete(l.events, id)
+			acked--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	l.order = kept
+}
+
+// list returns copies of the events in state (all if state is ""), in
+// recording order
+func (l *usageLedger) list(state UsageEventState) []UsageEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var events []UsageEvent
+	for _, id := range l.order {
+		if ev := l.events[id]; state == "" || ev.State == state {
+			events = append(events, *ev)
+		}
+	}
+	return events
+}
+
+func (l *usageLedger) get(id string) (UsageEvent, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ev, ok := l.events[id]
+	if !ok {
+		return UsageEvent{}, false
+	}
+	return *ev, true
+}
+
+// EnableUsageLedger makes ReportUsage (and Consume) deliver usage
+// exactly-once-effectively: each report becomes an event with a unique ID,
+// sent as the idempotency key and kept until the server acknowledges it.
+// Undelivered events are retried by FlushUsage and with every heartbeat.
+//
+// With a journal path, events are persisted before delivery, so pending
+// events left by a crash are redelivered (with their original ID) once the
+// ledger is reopened. An empty path keeps the ledger in memory.
+func (c *Client) EnableUsageLedger(path string) error {
+	l, err := openUsageLedger(path)
+	if err != nil {
+		return err
+	}
+	c.setUsageLedger(l, path)
+	return nil
+}
+
+func (c *Client) setUsageLedger(l *usageLedger, source string) {
+	c.mu.Lock()
+	c.usageLedger = l
+	c.mu.Unlock()
+
+	if n := len(l.list(UsagePending)); n > 0 {
+		debugLogf("Usage ledger: %d pending event(s) recovered from %s", n, source)
+	}
+}
+
+func (c *Client) ledger() *usageLedger {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.usageLedger
+}
+
+// UsageEvents returns the tracked usage events in state (all if state is
+// ""), oldest first. Acknowledged events are retained up to a bound.
+func (c *Client) UsageEvents(state UsageEventState) []UsageEvent {
+	l := c.ledger()
+	if l == nil {
+		return nil
+	}
+	return l.list(state)
+}
+
+// UsageEvent returns the tracked usage event with the given ID
+func (c *Client) UsageEvent(id string) (UsageEvent, bool) {
+	l := c.ledger()
+	if l == nil {
+		return UsageEvent{}, false
+	}
+	return l.get(id)
+}
+
+// FlushUsage delivers every pending usage event and reconciles units used
+// from the quota lease. It returns an error if events are still pending
+// afterwards or the lease could not be reconciled.
+func (c *Client) FlushUsage() error {
+	return c.flushUsage(context.Background())
+}
+
+// flushUsage is FlushUsage bounded by ctx: events not delivered when ctx
+// is done stay pending
+func (c *Client) flushUsage(ctx context.Context) error {
+	leaseErr := c.flushLease(ctx)
+	l := c.ledger()
+	if l == nil {
+		return leaseErr
+	}
+	for _, ev := range l.list(UsagePending) {
+		if ctx.Err() != nil {
+			break
+		}
+		_ = c.deliverUsage(ctx, l, ev.ID)
+	}
+	if n := len(l.list(UsagePending)); n > 0 {
+		return fmt.Errorf("%d usage event(s) still pending", n)
+	}
+	return leaseErr
+}
+
+// reportUsageEvent records usage in the ledger and attempts delivery. Once
+// the event is recorded, only a permanent rejection is reported as an
+// error; transient failures leave it pending for redelivery.
+func (c *Client) reportUsageEvent(l *usageLedger, featureID, dimension string, amount int) error {
+	id, err := c.newID()
+	if err != nil {
+		return err
+	}
+	ev, err := l.add(id, featureID, dimension, amount)
+	if err != nil {
+		return err
+	}
+	if err := c.deliverUsage(context.Background(), l, ev.ID); err != nil {
+		if settled, _ := l.get(ev.ID); settled.State == UsageFailed {
+			return err
+		}
+		debugLogf("Usage event %s pending: %v", ev.ID, err)
+	}
+	return nil
+}
+
+// deliverUsage sends one pending event and settles its state
+func (c *Client) deliverUsage(ctx context.Context, l *usageLedger, id string) error {
+	ev, ok := l.claim(id)
+	if !ok {
+		return nil
+	}
+
+	if c.grpcTransport() != nil {
+		err := c.reportUsageGRPC(ev.FeatureID, ev.Dimension, ev.Amount, ev.Timestamp, ev.ID)
+		l.settle(id, err == nil, err != nil && grpcPermanent(err), err)
+		return err
+	}
+
+	reqBody := map[string]interface{}{
+		"instance_id": c.instanceID,
+		"feature_id":  ev.FeatureID,
+		"count":       ev.Amount,
+		"timestamp":   ev.Timestamp.Unix(),
+		"event_id":    ev.ID,
+	}
+	if ev.Dimension != "" {
+		reqBody["dimension"] = ev.Dimension
+	}
+	if pool := c.pools.poolOf(ev.FeatureID); pool != "" {
+		reqBody["quota_pool"] = pool
+	}
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		l.settle(id, false, true, err)
+		return err
+	}
+
+	resp, err := c.doWithRetry(ctx, "ReportUsage", func(ctx context.Context) (*http.Request, error) {
+		req, err := c.newSignedRequest(ctx, "POST", c.baseURL+"/api/v1/sdk/usage", body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Idempotency-Key", ev.ID)
+		return req, nil
+	})
+	if err != nil {
+		err = fmt.Errorf("request failed: %w", err)
+		l.settle(id, false, false, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK, resp.StatusCode == http.StatusCreated,
+		resp.StatusCode == http.StatusAccepted, resp.StatusCode == http.StatusConflict:
+		// 409: the server already counted this idempotency key
+		if err := c.verifyResponse(resp); err != nil {
+			l.settle(id, false, false, err)
+			return err
+		}
+		l.settle(id, true, false, nil)
+		return nil
+	default:
+		msg, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("usage report failed: status=%d, body=%s", resp.StatusCode, string(msg))
+		transient := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		l.settle(id, false, !transient, err)
+		return err
+	}
+}
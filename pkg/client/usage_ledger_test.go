@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// usageServer counts usage once per idempotency key and answers with the
+// given status while status is non-zero
+type usageServer struct {
+	status atomic.Int32
+
+	mu     sync.Mutex
+	counts map[string]int // feature -> counted amount
+	seen   map[string]bool
+}
+
+func newUsageServer() *usageServer {
+	return &usageServer{counts: make(map[string]int), seen: make(map[string]bool)}
+}
+
+func (s *usageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if code := s.status.Load(); code != 0 {
+		w.WriteHeader(int(code))
+		return
+	}
+	var body struct {
+		FeatureID string `json:"feature_id"`
+		Count     int    `json:"count"`
+		EventID   string `json:"event_id"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	key := r.Header.Get("Idempotency-Key")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key != body.EventID {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if s.seen[key] {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	s.seen[key] = true
+	s.counts[body.FeatureID] += body.Count
+}
+
+func (s *usageServer) count(featureID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[featureID]
+}
+
+func TestClient_UsageLedger(t *testing.T) {
+	us := newUsageServer()
+	srv := httptest.NewServer(us)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.EnableUsageLedger(""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ReportUsage("export", 2); err != nil {
+		t.Fatalf("ReportUsage() error = %v", err)
+	}
+
+	// Transient failure: the event stays pending, no error
+	us.status.Store(http.StatusServiceUnavailable)
+	if err := c.ReportUsage("export", 3); err != nil {
+		t.Fatalf("ReportUsage() with server down error = %v, want nil (pending)", err)
+	}
+	pending := c.UsageEvents(UsagePending)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("pending events = %+v, want one with 1 attempt", pending)
+	}
+	if err := c.FlushUsage(); err == nil {
+		t.Error("FlushUsage() with server down should report pending events")
+	}
+
+	// Permanent rejection: failed, error returned
+	us.status.Store(http.StatusBadRequest)
+	if err := c.ReportUsage("export", 7); err == nil {
+		t.Error("ReportUsage() rejected by the server should fail")
+	}
+
+	us.status.Store(0)
+	if err := c.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+	if got := us.count("export"); got != 5 {
+		t.Errorf("server count = %d, want 5", got)
+	}
+
+	states := map[UsageEventState]int{}
+	for _, ev := range c.UsageEvents("") {
+		states[ev.State]++
+	}
+	if states[UsageAcked] != 2 || states[UsageFailed] != 1 || states[UsagePending] != 0 {
+		t.Errorf("event states = %v, want 2 acked, 1 failed", states)
+	}
+
+	acked := c.UsageEvents(UsageAcked)[1]
+	if ev, ok := c.UsageEvent(acked.ID); !ok || ev.State != UsageAcked || ev.AckedAt.IsZero() || ev.Attempts != 3 {
+		t.Errorf("UsageEvent(%s) = %+v, %v", acked.ID, ev, ok)
+	}
+}
+
+func TestClient_UsageLedger_CrashRecovery(t *testing.T) {
+	us := newUsageServer()
+	srv := httptest.NewServer(us)
+	defer srv.Close()
+	journal := filepath.Join(t.TempDir(), "usage.jsonl")
+
+	// First process: one event delivered, one left pending by an outage
+	c1 := newTestClient(t, srv.URL)
+	if err := c1.EnableUsageLedger(journal); err != nil {
+		t.Fatal(err)
+	}
+	_ = c1.ReportUsage("export", 1)
+	us.status.Store(http.StatusBadGateway)
+	_ = c1.ReportUsage("export", 10)
+	pendingID := c1.UsageEvents(UsagePending)[0].ID
+
+	// Second process recovers only the pending event, with its original ID
+	us.status.Store(0)
+	c2 := newTestClient(t, srv.URL)
+	if err := c2.EnableUsageLedger(journal); err != nil {
+		t.Fatal(err)
+	}
+	events := c2.UsageEvents("")
+	if len(events) != 1 || events[0].ID != pendingID || events[0].State != UsagePending {
+		t.Fatalf("recovered events = %+v, want pending %s", events, pendingID)
+	}
+	if err := c2.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+
+	// The server acknowledged a delivery whose response the first process
+	// never saw; redelivering from an older journal must not double count
+	c3 := newTestClient(t, srv.URL)
+	l, _ := openUsageLedger("")
+	ev := events[0]
+	l.events[ev.ID], l.order = &ev, []string{ev.ID}
+	c3.usageLedger = l
+	if err := c3.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() of a duplicate error = %v", err)
+	}
+	if got, _ := c3.UsageEvent(ev.ID); got.State != UsageAcked {
+		t.Errorf("duplicate delivery state = %s, want acked", got.State)
+	}
+	if got := us.count("export"); got != 11 {
+		t.Errorf("server count = %d, want 11", got)
+	}
+}
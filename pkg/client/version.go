@@ -0,0 +1,161 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrVersionNotLicensed is returned (wrapped in a *VersionNotLicensedError)
+// by Register when the product version declared in SDKConfig falls outside
+// the version range granted by the license. The instance is deregistered.
+var ErrVersionNotLicensed = errors.New("product version not licensed")
+
+// VersionRange describes the product versions a license allows.
+// Both bounds are inclusive; an empty bound means "unbounded".
+//
+// Vendors selling version-locked perpetual licenses typically set only Max
+// (e.g., "2.99.99"), while subscription licenses leave the range empty.
+type VersionRange struct {
+	Min string `json:"min_version,omitempty"`
+	Max string `json:"max_version,omitempty"`
+}
+
+// IsEmpty reports whether the range places no constraint on the version
+func (r *VersionRange) IsEmpty() bool {
+	return r == nil || (r.Min == "" && r.Max == "")
+}
+
+// Contains reports whether version lies within the range.
+// Versions are compared numerically segment by segment ("1.10.0" > "1.9.3");
+// a leading "v" and any pre-release/build suffix ("-beta", "+build") are ignored.
+func (r *VersionRange) Contains(version string) (bool, error) {
+	if r.IsEmpty() {
+		return true, nil
+	}
+
+	if r.Min != "" {
+		cmp, err := compareVersions(version, r.Min)
+		if err != nil {
+			return false, err
+		}
+		if cmp < 0 {
+			return false, nil
+		}
+	}
+
+	if r.Max != "" {
+		cmp, err := compareVersions(version, r.Max)
+		if err != nil {
+			return false, err
+		}
+		if cmp > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// String returns a human-readable representation such as "[1.0.0, 2.99.99]"
+func (r *VersionRange) String() string {
+	if r.IsEmpty() {
+		return "[*, *]"
+	}
+	lo, hi := r.Min, r.Max
+	if lo == "" {
+		lo = "*"
+	}
+	if hi == "" {
+		hi = "*"
+	}
+	return fmt.Sprintf("[%s, %s]", lo, hi)
+}
+
+// VersionNotLicensedError reports that the running product version is not
+// covered by the license. It matches ErrVersionNotLicensed via errors.Is.
+type VersionNotLicensedError struct {
+	Version string
+	Range   VersionRange
+}
+
+func (e *VersionNotLicensedError) Error() string {
+	return fmt.Sprintf("%s: version %s is outside licensed range %s", ErrVersionNotLicensed, e.Version, e.Range.String())
+}
+
+// Is allows errors.Is(err, ErrVersionNotLicensed) to match
+func (e *VersionNotLicensedError) Is(target error) bool {
+	return target == ErrVersionNotLicensed
+}
+
+// checkVersion validates version against the licensed range
+func checkVersion(version string, licensed *VersionRange) error {
+	if licensed.IsEmpty() {
+		return nil
+	}
+
+	ok, err := licensed.Contains(version)
+	if err != nil {
+		return fmt.Errorf("failed to compare product version: %w", err)
+	}
+	if !ok {
+		return &VersionNotLicensedError{Version: version, Range: *licensed}
+	}
+
+	return nil
+}
+
+// compareVersions compares two dotted version strings numerically.
+// Missing segments are treated as zero, so "1.2" == "1.2.0".
+func compareVersions(a, b string) (int, error) {
+	as, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x < y {
+			return -1, nil
+		}
+		if x > y {
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// parseVersion splits a version string into numeric segments
+func parseVersion(v string) ([]int, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("invalid version %q", v)
+	}
+
+	parts := strings.Split(s, ".")
+	segments := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		segments[i] = n
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionRange_Contains(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       *VersionRange
+		version string
+		want    bool
+		wantErr bool
+	}{
+		{"nil range", nil, "1.0.0", true, false},
+		{"empty range", &VersionRange{}, "9.9.9", true, false},
+		{"within range", &VersionRange{Min: "1.0.0", Max: "2.99.99"}, "2.3.1", true, false},
+		{"at min bound", &VersionRange{Min: "1.0.0", Max: "2.0.0"}, "1.0.0", true, false},
+		{"at max bound", &VersionRange{Min: "1.0.0", Max: "2.0.0"}, "2.0", true, false},
+		{"below min", &VersionRange{Min: "1.2.0"}, "1.1.9", false, false},
+		{"above max", &VersionRange{Max: "2.99.99"}, "3.0.0", false, false},
+		{"numeric not lexical", &VersionRange{Max: "1.9.0"}, "1.10.0", false, false},
+		{"v prefix and suffix", &VersionRange{Min: "v1.0.0", Max: "v2.0.0"}, "v1.5.0-beta+abc", true, false},
+		{"invalid version", &VersionRange{Max: "2.0.0"}, "latest", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.r.Contains(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Contains() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVersion_TypedError(t *testing.T) {
+	err := checkVersion("3.0.0", &VersionRange{Max: "2.99.99"})
+	if err == nil {
+		t.Fatal("checkVersion() should fail for unlicensed version")
+	}
+
+	if !errors.Is(err, ErrVersionNotLicensed) {
+		t.Errorf("errors.Is(err, ErrVersionNotLicensed) = false, err = %v", err)
+	}
+
+	var vErr *VersionNotLicensedError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("errors.As(err, *VersionNotLicensedError) = false")
+	}
+	if vErr.Version != "3.0.0" || vErr.Range.Max != "2.99.99" {
+		t.Errorf("unexpected error details: %+v", vErr)
+	}
+
+	if err := checkVersion("2.0.0", &VersionRange{Max: "2.99.99"}); err != nil {
+		t.Errorf("checkVersion() error = %v, want nil", err)
+	}
+}
+
+func TestClient_RegisterVersionNotLicensed(t *testing.T) {
+	var live int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/register":
+			live++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"licensed_versions": map[string]string{"max_version": "0.9.0"}})
+		case "/api/v1/sdk/deregister":
+			live--
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.Register(); !errors.Is(err, ErrVersionNotLicensed) {
+		t.Fatalf("Register() error = %v, want ErrVersionNotLicensed", err)
+	}
+	if got := c.Lifecycle(); got != StateNew {
+		t.Errorf("Lifecycle() = %s, want new", got)
+	}
+	if live != 0 {
+		t.Errorf("server live instances = %d, want the rejected instance deregistered", live)
+	}
+}
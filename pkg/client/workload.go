@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Workload event kinds
+const (
+	EventCheck    = "check"    // CheckFeature for a manifest feature
+	EventConsume  = "consume"  // Consume of Amount units
+	EventCapacity = "capacity" // CheckCapacity with Amount in use
+	EventAcquire  = "acquire"  // AcquireSlot; SlotID is 0 if it was denied
+	EventRelease  = "release"  // release of slot SlotID
+)
+
+// WorkloadEvent is one call in a recorded workload (see RecordWorkload).
+// Logs are JSON lines, one event per line.
+type WorkloadEvent struct {
+	Time      time.Time `json:"ts"`
+	Kind      string    `json:"kind"`
+	FeatureID string    `json:"feature_id,omitempty"`
+	Amount    int       `json:"amount,omitempty"`
+	SlotID    uint64    `json:"slot_id,omitempty"`
+}
+
+// workloadRecorder writes workload events as JSON lines
+type workloadRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (r *workloadRecorder) record(ev WorkloadEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(ev); err != nil {
+		debugLogf("Workload recording failed: %v", err)
+	}
+}
+
+// RecordWorkload writes every Consume, CheckCapacity, AcquireSlot (and
+// release) and manifest feature check to w as a JSON-lines event log, for
+// replay with Simulate. Writes are serialized; pass nil to stop recording.
+func (c *Client) RecordWorkload(w io.Writer) {
+	if w == nil {
+		c.workload.Store(nil)
+		return
+	}
+	c.workload.Store(&workloadRecorder{enc: json.NewEncoder(w)})
+}
+
+// recordEvent appends ev to the workload log, if recording
+func (c *Client) recordEvent(ev WorkloadEvent) {
+	r := c.workload.Load()
+	if r == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	r.record(ev)
+}
+
+// ReadWorkload reads a JSON-lines event log written by RecordWorkload.
+// Blank lines are skipped.
+func ReadWorkload(r io.Reader) ([]WorkloadEvent, error) {
+	var events []WorkloadEvent
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var ev WorkloadEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("workload line %d: %w", line, err)
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,593 @@
+// Package clienttest provides test doubles for code that depends on the
+// LCC client: MockClient, an in-memory client.LCCClient, and FakeServer, an
+// httptest server speaking the SDK protocol for tests of a real *Client.
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// MockInstanceID is the instance ID reported by MockClient
+const MockInstanceID = "mock-instance"
+
+// Call is one recorded MockClient method call
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockClient is an in-memory client.LCCClient. Features, groups and the
+// product status (limits and quota) are programmed with the Set methods;
+// limit checks behave like *client.Client against a server with that
+// state, and every call is recorded for assertions.
+//
+// Unknown features are denied with client.ReasonNotInLicense. The zero
+// product status is enabled with no limits configured.
+type MockClient struct {
+	mu sync.Mutex
+
+	features    map[string]*client.FeatureStatus
+	featureErrs map[string]error
+	groups      map[string][]string
+	product     client.FeatureStatus
+	err         error
+	currentTPS  float64
+	active      int
+	helpers     *client.HelperFunctions
+
+	usage    map[string]float64
+	consumed int
+	calls    []Call
+
+	registered bool
+	closed     bool
+	connection client.ConnectionState
+}
+
+var _ client.LCCClient = (*MockClient)(nil)
+
+// NewMockClient creates a MockClient with no features
+func NewMockClient() *MockClient {
+	return &MockClient{
+		features:    make(map[string]*client.FeatureStatus),
+		featureErrs: make(map[string]error),
+		groups:      make(map[string][]string),
+		product:     client.FeatureStatus{Enabled: true},
+		usage:       make(map[string]float64),
+	}
+}
+
+// ========== Programming ==========
+
+// SetFeature enables or disables a feature
+func (m *MockClient) SetFeature(featureID string, enabled bool) {
+	status := &client.FeatureStatus{Enabled: enabled}
+	if !enabled {
+		status.Reason = client.ReasonNotInLicense
+	}
+	m.SetFeatureStatus(featureID, status)
+}
+
+// SetFeatureStatus sets the status returned for a feature.
+// ConsumeDimension draws down status.Quotas.
+func (m *MockClient) SetFeatureStatus(featureID string, status *client.FeatureStatus) {
+	s := *status
+	s.Quotas = append([]client.QuotaInfo(nil), s.Quotas...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.features[featureID] = &s
+	delete(m.featureErrs, featureID)
+}
+
+// SetFeatureError makes checks of a feature fail with err
+func (m *MockClient) SetFeatureError(featureID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.featureErrs[featureID] = err
+}
+
+// SetGroup defines a feature group for CheckGroup
+func (m *MockClient) SetGroup(groupID string, featureIDs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups[groupID] = append([]string(nil), featureIDs...)
+}
+
+// SetProductStatus sets the product-level limits and quota used by
+// Consume, CheckCapacity, CheckTPS and AcquireSlot. Consume draws down
+// status.Quota.
+func (m *MockClient) SetProductStatus(status *client.FeatureStatus) {
+	s := *status
+	if s.Quota != nil {
+		q := *s.Quota
+		s.Quota = &q
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.product = s
+}
+
+// SetQuota sets a product quota of limit units with used already consumed
+func (m *MockClient) SetQuota(limit, used int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.product.Quota = &client.QuotaInfo{Limit: limit, Used: used, Remaining: limit - used}
+}
+
+// SetCurrentTPS sets the TPS CheckTPS compares against the limit when no
+// TPSProvider helper is registered
+func (m *MockClient) SetCurrentTPS(tps float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.currentTPS = tps
+}
+
+// SetConnectionState sets the state returned by State
+func (m *MockClient) SetConnectionState(state client.ConnectionState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connection = state
+}
+
+// SetError makes every check fail with err, as if the LCC server were
+// unreachable. nil clears it.
+func (m *MockClient) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// ========== Inspection ==========
+
+// Calls returns the recorded calls in order
+func (m *MockClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how many times method was called
+func (m *MockClient) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, c := range m.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Usage returns the usage reported for a feature with ReportUsage
+func (m *MockClient) Usage(featureID string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage[featureID]
+}
+
+// Consumed returns the product quota units consumed by allowed Consume calls
+func (m *MockClient) Consumed() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.consumed
+}
+
+// ActiveSlots returns the number of concurrency slots held
+func (m *MockClient) ActiveSlots() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+// Registered reports whether Register was called
+func (m *MockClient) Registered() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.registered
+}
+
+// Closed reports whether Close was called
+func (m *MockClient) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// Reset clears recorded calls, usage and held slots, keeping the
+// programmed state
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.usage = make(map[string]float64)
+	m.consumed = 0
+	m.active = 0
+}
+
+// recordLocked appends a call. Caller holds m.mu.
+func (m *MockClient) recordLocked(method string, args ...interface{}) {
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// closedLocked returns the error a closed client returns for op. Caller
+// holds m.mu.
+func (m *MockClient) closedLocked(op string) error {
+	if m.closed {
+		return &client.StateError{Op: op, State: client.StateClosed}
+	}
+	return nil
+}
+
+// ========== client.LCCClient ==========
+
+// Register marks the mock registered
+func (m *MockClient) Register() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("Register")
+	if err := m.closedLocked("register"); err != nil {
+		return err
+	}
+	m.registered = true
+	return nil
+}
+
+// Close marks the mock closed; later calls fail like a closed client
+func (m *MockClient) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("Close")
+	m.closed = true
+	return nil
+}
+
+// GetInstanceID returns MockInstanceID
+func (m *MockClient) GetInstanceID() string {
+	return MockInstanceID
+}
+
+// State returns the connection state set with SetConnectionState,
+// ConnectionHealthy by default
+func (m *MockClient) State() client.ConnectionState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.connection
+}
+
+// CheckFeature returns the programmed status of a feature
+func (m *MockClient) CheckFeature(featureID string) (*client.FeatureStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("CheckFeature", featureID)
+	return m.featureLocked(featureID)
+}
+
+// CheckFeatureCtx returns the programmed status of a feature. The mock does
+// not memoize: every call reflects the current SetFeature state.
+func (m *MockClient) CheckFeatureCtx(_ context.Context, featureID string) (*client.FeatureStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("CheckFeatureCtx", featureID)
+	return m.featureLocked(featureID)
+}
+
+func (m *MockClient) featureLocked(featureID string) (*client.FeatureStatus, error) {
+	if err := m.closedLocked("check feature"); err != nil {
+		return nil, err
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	if err := m.featureErrs[featureID]; err != nil {
+		return nil, err
+	}
+	status, ok := m.features[featureID]
+	if !ok {
+		return &client.FeatureStatus{Enabled: false, Reason: client.ReasonNotInLicense}, nil
+	}
+	s := *status
+	s.Quotas = append([]client.QuotaInfo(nil), s.Quotas...)
+	return &s, nil
+}
+
+// CheckGroup checks the features of a group defined with SetGroup
+func (m *MockClient) CheckGroup(groupID string) (*client.GroupStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("CheckGroup", groupID)
+	featureIDs, ok := m.groups[groupID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", client.ErrUnknownGroup, groupID)
+	}
+
+	status := &client.GroupStatus{GroupID: groupID, Disabled: make(map[string]string)}
+	for _, id := range featureIDs {
+		fs, err := m.featureLocked(id)
+		switch {
+		case err != nil:
+			status.Disabled[id] = "check_error: " + err.Error()
+		case !fs.Enabled:
+			reason := fs.Reason
+			if reason == "" {
+				reason = "disabled"
+			}
+			status.Disabled[id] = reason
+		default:
+			status.Enabled = append(status.Enabled, id)
+		}
+	}
+	switch {
+	case len(status.Disabled) == 0:
+		status.State = client.GroupEnabled
+	case len(status.Enabled) == 0:
+		status.State = client.GroupDisabled
+	default:
+		status.State = client.GroupPartial
+	}
+	return status, nil
+}
+
+// ProductStatus returns the programmed product status
+func (m *MockClient) ProductStatus() (*client.FeatureStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("ProductStatus")
+	return m.productLocked()
+}
+
+func (m *MockClient) productLocked() (*client.FeatureStatus, error) {
+	if err := m.closedLocked("check feature"); err != nil {
+		return nil, err
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	s := m.product
+	if s.Quota != nil {
+		q := *s.Quota
+		s.Quota = &q
+	}
+	return &s, nil
+}
+
+// Consume draws amount from the product quota, if one is set
+func (m *MockClient) Consume(amount int) (bool, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("Consume", amount)
+	return m.consumeLocked(amount)
+}
+
+func (m *MockClient) consumeLocked(amount int) (bool, int, error) {
+	status, err := m.productLocked()
+	if err != nil {
+		return false, 0, err
+	}
+	if !status.Enabled {
+		return false, 0, fmt.Errorf("quota exceeded: %s", status.Reason)
+	}
+
+	q := m.product.Quota
+	if q == nil {
+		m.consumed += amount
+		return true, 0, nil
+	}
+	if q.Remaining < amount {
+		return false, q.Remaining, fmt.Errorf("quota exceeded: %s", client.ReasonQuotaExhausted)
+	}
+	q.Used += amount
+	q.Remaining -= amount
+	m.consumed += amount
+	return true, q.Remaining, nil
+}
+
+// ConsumeDimension draws n units from a quota dimension of the programmed
+// feature status
+func (m *MockClient) ConsumeDimension(featureID, dimension string, n int) (bool, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("ConsumeDimension", featureID, dimension, n)
+	status, err := m.featureLocked(featureID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !status.Enabled {
+		return false, 0, client.NewFeatureError(featureID, status, nil)
+	}
+
+	var q *client.QuotaInfo
+	for i, quota := range m.features[featureID].Quotas {
+		if quota.Dimension == dimension {
+			q = &m.features[featureID].Quotas[i]
+		}
+	}
+	if q == nil {
+		return false, 0, fmt.Errorf("%w: %s of feature %s", client.ErrUnknownDimension, dimension, featureID)
+	}
+	if q.Remaining < n {
+		return false, q.Remaining, fmt.Errorf("quota exceeded: %s of feature %s", dimension, featureID)
+	}
+	q.Used += n
+	q.Remaining -= n
+	return true, q.Remaining, nil
+}
+
+// ConsumeWithContext consumes the amount computed by the registered
+// QuotaConsumer helper
+func (m *MockClient) ConsumeWithContext(ctx context.Context, args ...interface{}) (bool, int, error) {
+	m.mu.Lock()
+	m.recordLocked("ConsumeWithContext", args...)
+	helpers := m.helpers
+	m.mu.Unlock()
+
+	if helpers == nil || helpers.QuotaConsumer == nil {
+		return false, 0, fmt.Errorf("QuotaConsumer helper not registered")
+	}
+	amount := helpers.QuotaConsumer(ctx, args...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.consumeLocked(amount)
+}
+
+// CheckCapacity compares currentUsed against the product MaxCapacity
+func (m *MockClient) CheckCapacity(currentUsed int) (bool, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("CheckCapacity", currentUsed)
+	return m.checkCapacityLocked(currentUsed)
+}
+
+func (m *MockClient) checkCapacityLocked(currentUsed int) (bool, int, error) {
+	status, err := m.productLocked()
+	if err != nil {
+		return false, 0, err
+	}
+	if status.MaxCapacity <= 0 {
+		return false, 0, fmt.Errorf("no capacity limit configured")
+	}
+	if currentUsed >= status.MaxCapacity {
+		return false, status.MaxCapacity, fmt.Errorf("capacity exceeded: %d >= %d", currentUsed, status.MaxCapacity)
+	}
+	return true, status.MaxCapacity, nil
+}
+
+// CheckCapacityWithHelper checks the count from the registered
+// CapacityCounter helper
+func (m *MockClient) CheckCapacityWithHelper() (bool, int, error) {
+	m.mu.Lock()
+	m.recordLocked("CheckCapacityWithHelper")
+	helpers := m.helpers
+	m.mu.Unlock()
+
+	if helpers == nil || helpers.CapacityCounter == nil {
+		return false, 0, fmt.Errorf("CapacityCounter helper not registered (required)")
+	}
+	currentUsed := helpers.CapacityCounter()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkCapacityLocked(currentUsed)
+}
+
+// CheckTPS compares the current TPS (from the TPSProvider helper or
+// SetCurrentTPS) against the product MaxTPS
+func (m *MockClient) CheckTPS() (bool, float64, error) {
+	m.mu.Lock()
+	m.recordLocked("CheckTPS")
+	helpers, current := m.helpers, m.currentTPS
+	m.mu.Unlock()
+
+	if helpers != nil && helpers.TPSProvider != nil {
+		current = helpers.TPSProvider()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, err := m.productLocked()
+	if err != nil {
+		return false, 0, err
+	}
+	if status.MaxTPS <= 0 {
+		return true, 0, nil
+	}
+	if current > status.MaxTPS {
+		return false, status.MaxTPS, fmt.Errorf("TPS exceeded: %.2f > %.2f", current, status.MaxTPS)
+	}
+	return true, status.MaxTPS, nil
+}
+
+// AcquireSlot takes a slot from the product MaxConcurrency pool
+func (m *MockClient) AcquireSlot() (client.ReleaseFunc, bool, error) {
+	return m.acquireSlot("AcquireSlot")
+}
+
+// AcquireSlotContext is AcquireSlot; the mock never queues
+func (m *MockClient) AcquireSlotContext(ctx context.Context) (client.ReleaseFunc, bool, error) {
+	return m.acquireSlot("AcquireSlotContext")
+}
+
+// AcquireSlotWait is AcquireSlot; the mock never waits
+func (m *MockClient) AcquireSlotWait(ctx context.Context, maxWait time.Duration) (client.ReleaseFunc, bool, error) {
+	return m.acquireSlot("AcquireSlotWait")
+}
+
+func (m *MockClient) acquireSlot(method string) (client.ReleaseFunc, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked(method)
+	status, err := m.productLocked()
+	if err != nil {
+		return func() {}, false, err
+	}
+	if status.MaxConcurrency <= 0 {
+		return func() {}, false, fmt.Errorf("no concurrency limit configured")
+	}
+	if m.active >= status.MaxConcurrency {
+		return func() {}, false, fmt.Errorf("concurrency exceeded: %d >= %d", m.active, status.MaxConcurrency)
+	}
+
+	m.active++
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.active > 0 {
+				m.active--
+			}
+		})
+	}
+	return release, true, nil
+}
+
+// ReportUsage accumulates usage per feature (see Usage)
+func (m *MockClient) ReportUsage(featureID string, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("ReportUsage", featureID, amount)
+	if err := m.closedLocked("report usage"); err != nil {
+		return err
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.usage[featureID] += amount
+	return nil
+}
+
+// RegisterHelpers validates and stores helpers
+func (m *MockClient) RegisterHelpers(helpers *client.HelperFunctions) error {
+	if helpers == nil {
+		return fmt.Errorf("helpers cannot be nil")
+	}
+	if err := helpers.Validate(); err != nil {
+		return fmt.Errorf("helper validation failed: %w", err)
+	}
+	if helpers.QuotaConsumer == nil {
+		helpers.QuotaConsumer = func(context.Context, ...interface{}) int { return 1 }
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked("RegisterHelpers", helpers)
+	m.helpers = helpers
+	return nil
+}
+
+// LimitError builds a limit error carrying the programmed product status
+func (m *MockClient) LimitError(limit string, err error) *client.LimitExceededError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &client.LimitExceededError{Limit: limit, Err: err}
+	if status, statusErr := m.productLocked(); statusErr == nil {
+		e.Status = status
+	}
+	return e
+}
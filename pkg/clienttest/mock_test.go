@@ -0,0 +1,142 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// exportReport is application code depending on the interface
+func exportReport(c client.LCCClient, rows int) error {
+	status, err := c.CheckFeature("export")
+	if err != nil {
+		return err
+	}
+	if !status.Enabled {
+		return client.NewFeatureError("export", status, nil)
+	}
+	if allowed, _, err := c.Consume(rows); !allowed {
+		return c.LimitError(client.LimitQuota, err)
+	}
+	return c.ReportUsage("export", float64(rows))
+}
+
+func TestMockClient_Features(t *testing.T) {
+	m := NewMockClient()
+	m.SetFeature("export", true)
+	m.SetQuota(10, 0)
+
+	if err := exportReport(m, 4); err != nil {
+		t.Fatalf("exportReport() error = %v", err)
+	}
+	if err := exportReport(m, 7); !errors.Is(err, client.ErrLimitExceeded) {
+		t.Errorf("exportReport() over quota error = %v, want ErrLimitExceeded", err)
+	}
+	if got := m.Consumed(); got != 4 {
+		t.Errorf("Consumed() = %d, want 4", got)
+	}
+	if got := m.Usage("export"); got != 4 {
+		t.Errorf("Usage(export) = %v, want 4", got)
+	}
+	if got := m.CallCount("CheckFeature"); got != 2 {
+		t.Errorf("CallCount(CheckFeature) = %d, want 2", got)
+	}
+
+	m.SetFeature("export", false)
+	if err := exportReport(m, 1); !errors.Is(err, client.ErrFeatureNotLicensed) {
+		t.Errorf("exportReport() disabled error = %v, want ErrFeatureNotLicensed", err)
+	}
+	if status, _ := m.CheckFeature("unknown"); status.Enabled || status.Reason != client.ReasonNotInLicense {
+		t.Errorf("CheckFeature(unknown) = %+v, want denied", status)
+	}
+
+	outage := errors.New("server unreachable")
+	m.SetError(outage)
+	if _, err := m.CheckFeature("export"); !errors.Is(err, outage) {
+		t.Errorf("CheckFeature() with SetError = %v", err)
+	}
+	m.SetError(nil)
+
+	m.SetGroup("suite", "export", "reports")
+	m.SetFeature("reports", true)
+	if g, err := m.CheckGroup("suite"); err != nil || g.State != client.GroupPartial {
+		t.Errorf("CheckGroup() = %+v, %v; want partial", g, err)
+	}
+
+	_ = m.Close()
+	if _, err := m.CheckFeature("reports"); !errors.Is(err, client.ErrClientClosed) {
+		t.Errorf("CheckFeature() after Close error = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestMockClient_Limits(t *testing.T) {
+	m := NewMockClient()
+
+	if _, _, err := m.AcquireSlot(); err == nil {
+		t.Error("AcquireSlot() without a concurrency limit should fail")
+	}
+	m.SetProductStatus(&client.FeatureStatus{Enabled: true, MaxConcurrency: 1, MaxCapacity: 5, MaxTPS: 10})
+
+	release, ok, err := m.AcquireSlotContext(context.Background())
+	if !ok || err != nil {
+		t.Fatalf("AcquireSlot() = %v, %v", ok, err)
+	}
+	if _, ok, _ := m.AcquireSlot(); ok {
+		t.Error("second AcquireSlot() should be denied")
+	}
+	release()
+	release() // idempotent
+	if got := m.ActiveSlots(); got != 0 {
+		t.Errorf("ActiveSlots() after release = %d", got)
+	}
+
+	tests := []struct {
+		name    string
+		used    int
+		allowed bool
+	}{
+		{"below", 4, true},
+		{"at limit", 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allowed, max, _ := m.CheckCapacity(tt.used); allowed != tt.allowed || max != 5 {
+				t.Errorf("CheckCapacity(%d) = %v, %d", tt.used, allowed, max)
+			}
+		})
+	}
+
+	m.SetCurrentTPS(12)
+	if allowed, _, _ := m.CheckTPS(); allowed {
+		t.Error("CheckTPS() above MaxTPS should be denied")
+	}
+	_ = m.RegisterHelpers(&client.HelperFunctions{
+		CapacityCounter: func() int { return 2 },
+		TPSProvider:     func() float64 { return 3 },
+	})
+	if allowed, _, _ := m.CheckTPS(); !allowed {
+		t.Error("CheckTPS() with TPSProvider below MaxTPS should be allowed")
+	}
+	if allowed, _, err := m.CheckCapacityWithHelper(); !allowed {
+		t.Errorf("CheckCapacityWithHelper() error = %v", err)
+	}
+	if allowed, _, err := m.ConsumeWithContext(context.Background(), "batch"); !allowed || m.Consumed() != 1 {
+		t.Errorf("ConsumeWithContext() = %v, %v; consumed %d", allowed, err, m.Consumed())
+	}
+
+	m.SetFeatureStatus("etl", &client.FeatureStatus{
+		Enabled: true,
+		Quotas:  []client.QuotaInfo{{Dimension: "rows", Limit: 10, Remaining: 10}, {Dimension: "jobs", Limit: 1, Remaining: 1}},
+	})
+	if allowed, remaining, err := m.ConsumeDimension("etl", "rows", 4); !allowed || remaining != 6 {
+		t.Errorf("ConsumeDimension(rows) = %v, %d, %v", allowed, remaining, err)
+	}
+	if allowed, _, _ := m.ConsumeDimension("etl", "jobs", 2); allowed {
+		t.Error("ConsumeDimension() past the jobs quota should be denied")
+	}
+	if _, _, err := m.ConsumeDimension("etl", "bytes", 1); !errors.Is(err, client.ErrUnknownDimension) {
+		t.Errorf("ConsumeDimension() of an unknown dimension error = %v", err)
+	}
+}
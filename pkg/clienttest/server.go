@@ -0,0 +1,403 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// productFeatureID is the feature ID the client reports product-level
+// (Consume) usage under
+const productFeatureID = "__product__"
+
+// FakeServer is an httptest LCC server implementing the SDK endpoints
+// (registration, heartbeat, feature checks, product status and usage), for
+// tests that exercise a real *client.Client. Request signatures are not
+// verified. Like MockClient, unknown features are denied and the product
+// is enabled with no limits until programmed.
+//
+// Product usage reported by Consume draws down the product quota; once it
+// is exhausted the product status is denied with client.ReasonQuotaExhausted.
+// Usage reports are counted once per Idempotency-Key. Quota leases are
+// granted from the remaining quota, and usage reconciled from a lease
+// draws it down like a usage report.
+type FakeServer struct {
+	// URL is the base URL of the server, for config.SDKConfig.LCCURL
+	URL string
+
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	features   map[string]client.FeatureStatus
+	product    client.FeatureStatus
+	failStatus int
+	usage      map[string]int
+	seenKeys   map[string]bool
+	leases     map[string]*fakeLease
+	nextLease  int
+
+	registrations   int
+	deregistrations int
+	heartbeats      int
+	requests        []string
+}
+
+// NewFakeServer starts a FakeServer; call Close when done
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		features: make(map[string]client.FeatureStatus),
+		product:  client.FeatureStatus{Enabled: true},
+		usage:    make(map[string]int),
+		seenKeys: make(map[string]bool),
+		leases:   make(map[string]*fakeLease),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sdk/register", s.handleRegister)
+	mux.HandleFunc("/api/v1/sdk/deregister", s.handleDeregister)
+	mux.HandleFunc("/api/v1/sdk/heartbeat", s.handleHeartbeat)
+	mux.HandleFunc("/api/v1/sdk/features/", s.handleFeatureCheck)
+	mux.HandleFunc("/api/v1/sdk/product/status", s.handleProductStatus)
+	mux.HandleFunc("/api/v1/sdk/usage", s.handleUsage)
+	mux.HandleFunc("/api/v1/sdk/quota/lease", s.handleLease)
+	mux.HandleFunc("/api/v1/sdk/quota/lease/", s.handleLeaseReconcile)
+
+	s.srv = httptest.NewServer(s.intercept(mux))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *FakeServer) Close() {
+	s.srv.Close()
+}
+
+// Config returns an SDK configuration pointing at the server. Caching is
+// disabled so programmed changes are seen by the next check.
+func (s *FakeServer) Config() *config.SDKConfig {
+	return &config.SDKConfig{
+		LCCURL:         s.URL,
+		ProductID:      "test-product",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		NoCache:        true,
+	}
+}
+
+// ========== Programming ==========
+
+// SetFeature enables or disables a feature
+func (s *FakeServer) SetFeature(featureID string, enabled bool) {
+	status := client.FeatureStatus{Enabled: enabled}
+	if !enabled {
+		status.Reason = client.ReasonNotInLicense
+	}
+	s.SetFeatureStatus(featureID, &status)
+}
+
+// SetFeatureStatus sets the check response for a feature. Usage reported
+// for a quota dimension draws down the matching entry of status.Quotas.
+func (s *FakeServer) SetFeatureStatus(featureID string, status *client.FeatureStatus) {
+	f := *status
+	f.Quotas = append([]client.QuotaInfo(nil), f.Quotas...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.features[featureID] = f
+}
+
+// SetProductStatus sets the product limits and quota
+func (s *FakeServer) SetProductStatus(status *client.FeatureStatus) {
+	p := *status
+	if p.Quota != nil {
+		q := *p.Quota
+		p.Quota = &q
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.product = p
+}
+
+// SetQuota sets a product quota of limit units with used already consumed
+func (s *FakeServer) SetQuota(limit, used int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.product.Quota = &client.QuotaInfo{Limit: limit, Used: used, Remaining: limit - used}
+}
+
+// FailWith answers every request with status, e.g. 503 to simulate an
+// outage. 0 restores normal responses.
+func (s *FakeServer) FailWith(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failStatus = status
+}
+
+// ========== Inspection ==========
+
+// Usage returns the usage reported for a feature
+func (s *FakeServer) Usage(featureID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage[featureID]
+}
+
+// ProductUsage returns the quota units reported by Consume
+func (s *FakeServer) ProductUsage() int {
+	return s.Usage(productFeatureID)
+}
+
+// Registrations returns the number of successful registrations
+func (s *FakeServer) Registrations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.registrations
+}
+
+// Deregistrations returns the number of deregistrations, including the
+// one sent by Close
+func (s *FakeServer) Deregistrations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deregistrations
+}
+
+// Heartbeats returns the number of heartbeats received
+func (s *FakeServer) Heartbeats() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heartbeats
+}
+
+// Requests returns every request received as "METHOD /path", in order
+func (s *FakeServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ========== Handlers ==========
+
+// intercept records requests and applies FailWith
+func (s *FakeServer) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		fail := s.failStatus
+		s.mu.Unlock()
+
+		if fail != 0 {
+			http.Error(w, http.StatusText(fail), fail)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *FakeServer) handleRegister(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.registrations++
+	s.mu.Unlock()
+	writeJSON(w, map[string]interface{}{"status": "registered"})
+}
+
+func (s *FakeServer) handleDeregister(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.deregistrations++
+	s.mu.Unlock()
+	writeJSON(w, map[string]interface{}{"status": "deregistered"})
+}
+
+func (s *FakeServer) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.heartbeats++
+	s.mu.Unlock()
+	writeJSON(w, map[string]interface{}{"status": "ok"})
+}
+
+func (s *FakeServer) handleFeatureCheck(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/features/"), "/check")
+
+	s.mu.Lock()
+	status, ok := s.features[id]
+	status.Quotas = append([]client.QuotaInfo(nil), status.Quotas...)
+	if id == productFeatureID {
+		status, ok = s.productLocked(), true
+	}
+	s.mu.Unlock()
+	if !ok {
+		status = client.FeatureStatus{Enabled: false, Reason: client.ReasonNotInLicense}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"feature_id":      id,
+		"enabled":         status.Enabled,
+		"reason":          status.Reason,
+		"variant":         status.Variant,
+		"quota_info":      status.Quota,
+		"quotas":          status.Quotas,
+		"max_capacity":    status.MaxCapacity,
+		"max_tps":         status.MaxTPS,
+		"max_concurrency": status.MaxConcurrency,
+	})
+}
+
+func (s *FakeServer) handleProductStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status := s.productLocked()
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"product_id": "test-product",
+		"enabled":    status.Enabled,
+		"reason":     status.Reason,
+		"limits": map[string]interface{}{
+			"max_tps":         status.MaxTPS,
+			"max_capacity":    status.MaxCapacity,
+			"max_concurrency": status.MaxConcurrency,
+		},
+		"quota": status.Quota,
+	})
+}
+
+// productLocked returns the product status, denied once the quota is
+// exhausted. Caller holds s.mu.
+func (s *FakeServer) productLocked() client.FeatureStatus {
+	status := s.product
+	if q := status.Quota; q != nil {
+		qc := *q
+		status.Quota = &qc
+		if status.Enabled && q.Remaining <= 0 {
+			status.Enabled = false
+			status.Reason = client.ReasonQuotaExhausted
+		}
+	}
+	return status
+}
+
+func (s *FakeServer) handleUsage(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FeatureID string `json:"feature_id"`
+		Dimension string `json:"dimension"`
+		Count     int    `json:"count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if s.seenKeys[key] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.seenKeys[key] = true
+	}
+	s.usage[body.FeatureID] += body.Count
+	for i, q := range s.features[body.FeatureID].Quotas {
+		if body.Dimension != "" && q.Dimension == body.Dimension {
+			q.Used += body.Count
+			q.Remaining = max(q.Limit-q.Used, 0)
+			s.features[body.FeatureID].Quotas[i] = q
+		}
+	}
+	if q := s.product.Quota; q != nil && body.FeatureID == productFeatureID {
+		q.Used += body.Count
+		q.Remaining = q.Limit - q.Used
+		if q.Remaining < 0 {
+			q.Remaining = 0
+		}
+	}
+	writeJSON(w, map[string]interface{}{"status": "ok"})
+}
+
+// fakeLease is a block of product quota reserved by a client
+type fakeLease struct {
+	granted int
+	used    int
+}
+
+func (s *FakeServer) handleLease(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Units int `json:"units"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Units <= 0 {
+		http.Error(w, "invalid lease request", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	granted := body.Units
+	var resetAt int64
+	if q := s.product.Quota; q != nil {
+		available := q.Remaining
+		for _, l := range s.leases {
+			available -= l.granted - l.used
+		}
+		granted = min(granted, max(available, 0))
+		resetAt = q.ResetAt
+	}
+	if granted == 0 {
+		writeJSON(w, map[string]interface{}{
+			"granted":  0,
+			"reason":   client.ReasonQuotaExhausted,
+			"reset_at": resetAt,
+		})
+		return
+	}
+
+	s.nextLease++
+	id := "lease-" + strconv.Itoa(s.nextLease)
+	s.leases[id] = &fakeLease{granted: granted}
+	writeJSON(w, map[string]interface{}{
+		"lease_id":   id,
+		"granted":    granted,
+		"expires_at": time.Now().Add(time.Minute).Unix(),
+	})
+}
+
+func (s *FakeServer) handleLeaseReconcile(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/sdk/quota/lease/"), "/reconcile")
+	var body struct {
+		Used    int  `json:"used"`
+		Release bool `json:"release"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lease, ok := s.leases[id]
+	if !ok {
+		http.Error(w, "unknown lease", http.StatusGone)
+		return
+	}
+	// Usage is cumulative: count only what was not reconciled before
+	if delta := min(body.Used, lease.granted) - lease.used; delta > 0 {
+		lease.used += delta
+		s.usage[productFeatureID] += delta
+		if q := s.product.Quota; q != nil {
+			q.Used += delta
+			q.Remaining = max(q.Limit-q.Used, 0)
+		}
+	}
+	if body.Release {
+		delete(s.leases, id)
+	}
+	writeJSON(w, map[string]interface{}{"status": "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,143 @@
+package clienttest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+func TestFakeServer(t *testing.T) {
+	fs := NewFakeServer()
+	defer fs.Close()
+	fs.SetFeature("reports", true)
+	fs.SetProductStatus(&client.FeatureStatus{Enabled: true, MaxConcurrency: 2})
+	fs.SetQuota(3, 0)
+
+	c, err := client.NewClient(fs.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if fs.Registrations() != 1 {
+		t.Errorf("Registrations() = %d, want 1", fs.Registrations())
+	}
+
+	tests := []struct {
+		feature string
+		enabled bool
+	}{
+		{"reports", true},
+		{"export", false},
+	}
+	for _, tt := range tests {
+		status, err := c.CheckFeature(tt.feature)
+		if err != nil || status.Enabled != tt.enabled {
+			t.Errorf("CheckFeature(%s) = %+v, %v; want enabled=%v", tt.feature, status, err, tt.enabled)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if allowed, _, err := c.Consume(1); !allowed {
+			t.Fatalf("Consume() #%d error = %v", i+1, err)
+		}
+	}
+	if allowed, _, _ := c.Consume(1); allowed {
+		t.Error("Consume() past the quota should be denied")
+	}
+	if got := fs.ProductUsage(); got != 3 {
+		t.Errorf("ProductUsage() = %d, want 3", got)
+	}
+
+	release, ok, err := c.AcquireSlot()
+	if !ok {
+		t.Fatalf("AcquireSlot() error = %v", err)
+	}
+	release()
+
+	if err := c.ReportUsage("reports", 2); err != nil || fs.Usage("reports") != 2 {
+		t.Errorf("ReportUsage() = %v, server usage %d", err, fs.Usage("reports"))
+	}
+
+	fs.FailWith(http.StatusServiceUnavailable)
+	if _, err := c.CheckFeature("reports"); err == nil {
+		t.Error("CheckFeature() during an outage should fail")
+	}
+
+	fs.FailWith(0)
+	if err := c.Close(); err != nil || fs.Deregistrations() != 1 {
+		t.Errorf("Close() = %v, deregistrations %d", err, fs.Deregistrations())
+	}
+}
+
+func TestFakeServer_QuotaDimensions(t *testing.T) {
+	fs := NewFakeServer()
+	defer fs.Close()
+	fs.SetFeatureStatus("etl", &client.FeatureStatus{
+		Enabled: true,
+		Quotas: []client.QuotaInfo{
+			{Dimension: "rows_per_day", Limit: 100, Remaining: 100},
+			{Dimension: "jobs_per_day", Limit: 1, Remaining: 1},
+		},
+	})
+
+	c, err := client.NewClient(fs.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if allowed, remaining, err := c.ConsumeDimension("etl", "rows_per_day", 30); !allowed || remaining != 70 {
+		t.Errorf("ConsumeDimension(rows) = %v, %d, %v", allowed, remaining, err)
+	}
+	if allowed, _, err := c.ConsumeDimension("etl", "jobs_per_day", 1); !allowed {
+		t.Errorf("ConsumeDimension(jobs) error = %v", err)
+	}
+	if allowed, _, _ := c.ConsumeDimension("etl", "jobs_per_day", 1); allowed {
+		t.Error("ConsumeDimension() past the jobs quota should be denied")
+	}
+	status, err := c.CheckFeature("etl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := status.QuotaFor("rows_per_day"); rows == nil || rows.Used != 30 || rows.Remaining != 70 {
+		t.Errorf("rows quota = %+v, want 30 used", rows)
+	}
+}
+
+func TestFakeServer_QuotaLease(t *testing.T) {
+	fs := NewFakeServer()
+	defer fs.Close()
+	fs.SetQuota(25, 0)
+
+	cfg := fs.Config()
+	cfg.QuotaLease = 10
+	c, err := client.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 25; i++ {
+		if allowed, _, err := c.Consume(1); !allowed {
+			t.Fatalf("Consume() #%d error = %v", i+1, err)
+		}
+	}
+
+	// Two leases were released when used up; the third is reconciled
+	if got := fs.ProductUsage(); got != 20 {
+		t.Errorf("ProductUsage() before flush = %d, want 20", got)
+	}
+	if err := c.FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+	if got := fs.ProductUsage(); got != 25 {
+		t.Errorf("ProductUsage() after flush = %d, want 25", got)
+	}
+	if allowed, _, _ := c.Consume(1); allowed {
+		t.Error("Consume() past the quota should be denied")
+	}
+}
@@ -109,8 +109,8 @@ func (g *Generator) buildFunctionTemplate(feature config.FeatureConfig) (Functio
 		fallbackCall = fmt.Sprintf("return %s(args...)", fallbackFunc)
 	}
 
-	// Build error return
-	errorReturn := `return nil, fmt.Errorf("feature not licensed")`
+	// Build error return: the SDK's typed error carries the denying status
+	errorReturn := fmt.Sprintf(`return nil, client.NewFeatureError(%q, status, err)`, feature.ID)
 
 	return FunctionTemplate{
 		OriginalName: funcName,
@@ -238,8 +238,8 @@ func (g *Generator) buildZeroIntrusionTemplate(feature config.FeatureConfig) (Ze
 		fallbackCall = fmt.Sprintf("return %s(args...)", fallbackFunc)
 	}
 
-	// Build error return
-	errorReturn := `return nil, fmt.Errorf("license limit exceeded")`
+	// Build error return; %s is replaced by the exceeded limit in the template
+	errorReturn := `return nil, _lccClient.LimitError(%s, err)`
 
 	// Determine which limits to inject based on ProductLimits
 	hasConcurrency := limits.MaxConcurrency > 0
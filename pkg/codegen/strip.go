@@ -0,0 +1,474 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+const (
+	// stripTagPrefix prefixes the build tag that strips a feature
+	stripTagPrefix = "lcc_strip_"
+
+	// File name prefixes for the guarded original and its stub
+	fullFilePrefix = "lcc_full_"
+	stubFilePrefix = "lcc_stub_"
+
+	clientImportPath = "github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// StripTag returns the build tag that replaces featureID's function with a
+// stub, e.g. "lcc_strip_advanced_analytics"
+func StripTag(featureID string) string {
+	var b strings.Builder
+	b.WriteString(stripTagPrefix)
+	for _, r := range strings.ToLower(featureID) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// Stripper prepares a source tree for edition-specific builds.
+//
+// For each stripped feature, the intercepted function is moved into its own
+// file guarded by "//go:build !<tag>" and a stub with the same signature is
+// generated behind "//go:build <tag>". Building with the tags of an edition
+// (see EditionTags) leaves the unlicensed code out of the binary; building
+// without tags produces the full product.
+type Stripper struct {
+	manifest *config.Manifest
+}
+
+// NewStripper creates a stripper for the features in manifest
+func NewStripper(manifest *config.Manifest) *Stripper {
+	return &Stripper{manifest: manifest}
+}
+
+// Excluded returns the features not granted by allows, in manifest order
+func (s *Stripper) Excluded(allows func(featureID string) bool) []config.FeatureConfig {
+	var excluded []config.FeatureConfig
+	for _, f := range s.manifest.Features {
+		if !allows(f.ID) {
+			excluded = append(excluded, f)
+		}
+	}
+	return excluded
+}
+
+// EditionTags returns the build tags that strip every feature not granted
+// by allows
+func (s *Stripper) EditionTags(allows func(featureID string) bool) []string {
+	var tags []string
+	for _, f := range s.Excluded(allows) {
+		tags = append(tags, StripTag(f.ID))
+	}
+	return tags
+}
+
+// Prepare splits each feature not granted by allows behind its build tag.
+// Package directories are resolved like the wrapper generator does, as the
+// last element of intercept.package under srcDir. Prepare is idempotent; it
+// returns the files it wrote.
+func (s *Stripper) Prepare(srcDir string, allows func(featureID string) bool) ([]string, error) {
+	var written []string
+	for _, f := range s.Excluded(allows) {
+		files, err := s.prepareFeature(srcDir, f)
+		if err != nil {
+			return written, fmt.Errorf("failed to strip feature %s: %w", f.ID, err)
+		}
+		written = append(written, files...)
+	}
+	return written, nil
+}
+
+// prepareFeature moves the feature function into its guarded file, if not
+// done before, and (re)generates its stub
+func (s *Stripper) prepareFeature(srcDir string, f config.FeatureConfig) ([]string, error) {
+	dir := filepath.Join(srcDir, filepath.Base(f.Intercept.Package))
+	fset := token.NewFileSet()
+
+	filename, file, decl, err := findFunc(fset, dir, f.Intercept.Function)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := StripTag(f.ID)
+	base := fileBaseName(f.Intercept.Function)
+	fullPath := filepath.Join(dir, fullFilePrefix+base+".go")
+	stubPath := filepath.Join(dir, stubFilePrefix+base+".go")
+	var written []string
+
+	// Generated files get the mode of the file the function came from
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	mode := info.Mode().Perm()
+
+	if filename != fullPath {
+		if err := extractFunc(fset, filename, file, decl, fullPath, tag, mode); err != nil {
+			return nil, err
+		}
+		written = append(written, filename, fullPath)
+	}
+
+	stub, err := renderStub(fset, file, decl, dir, f.ID, tag)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileMode(stubPath, stub, mode); err != nil {
+		return nil, fmt.Errorf("failed to write stub: %w", err)
+	}
+	return append(written, stubPath), nil
+}
+
+// findFunc locates the declaration of name ("Func" or "Type.Method") among
+// the non-test, non-stub Go files of dir
+func findFunc(fset *token.FileSet, dir, name string) (string, *ast.File, *ast.FuncDecl, error) {
+	recv, fn := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		recv, fn = name[:i], name[i+1:]
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() || !strings.HasSuffix(n, ".go") || strings.HasSuffix(n, "_test.go") || strings.HasPrefix(n, stubFilePrefix) {
+			continue
+		}
+		filename := filepath.Join(dir, n)
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if ok && decl.Name.Name == fn && receiverName(decl) == recv {
+				return filename, file, decl, nil
+			}
+		}
+	}
+	return "", nil, nil, fmt.Errorf("function %s not found in %s", name, dir)
+}
+
+// receiverName returns the receiver type name of a method, or ""
+func receiverName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	t := decl.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			return id.Name
+		}
+	case *ast.IndexListExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			return id.Name
+		}
+	}
+	return ""
+}
+
+// fileBaseName turns "Type.Method" into a file name fragment
+func fileBaseName(function string) string {
+	return strings.ToLower(strings.ReplaceAll(function, ".", "_"))
+}
+
+// extractFunc moves decl (with its doc comment) from filename into a new
+// file guarded by !tag, carrying over the imports it uses and dropping those
+// the original file no longer needs. Both files are written with mode.
+func extractFunc(fset *token.FileSet, filename string, file *ast.File, decl *ast.FuncDecl, fullPath, tag string, mode os.FileMode) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	start := decl.Pos()
+	if decl.Doc != nil {
+		start = decl.Doc.Pos()
+	}
+	from, to := fset.Position(start).Offset, fset.Position(decl.End()).Offset
+	declSrc := src[from:to]
+
+	rest := append(append([]byte{}, src[:from]...), src[to:]...)
+	if rest, err = dropUnusedImports(rest, filepath.Dir(filename)); err != nil {
+		return fmt.Errorf("failed to rewrite %s: %w", filename, err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "//go:build !%s\n\npackage %s\n\n", tag, file.Name.Name)
+	writeImports(&buf, usedImports(file, decl, filepath.Dir(filename)))
+	buf.Write(declSrc)
+	buf.WriteByte('\n')
+
+	full, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", fullPath, err)
+	}
+	if err := writeFileMode(fullPath, full, mode); err != nil {
+		return err
+	}
+	return writeFileMode(filename, rest, mode)
+}
+
+// writeFileMode writes data to name with mode, also when name exists
+// (os.WriteFile only applies the mode to new files)
+func writeFileMode(name string, data []byte, mode os.FileMode) error {
+	if err := os.WriteFile(name, data, mode); err != nil {
+		return err
+	}
+	return os.Chmod(name, mode)
+}
+
+// renderStub generates a function with decl's signature that returns zero
+// values and, if the last result is an error, a FeatureNotLicensedError
+func renderStub(fset *token.FileSet, file *ast.File, decl *ast.FuncDecl, dir, featureID, tag string) ([]byte, error) {
+	var sig bytes.Buffer
+	sig.WriteString("func ")
+	if decl.Recv != nil {
+		sig.WriteString("(" + nodeString(fset, decl.Recv) + ")")
+		sig.WriteByte(' ')
+	}
+	sig.WriteString(decl.Name.Name)
+	if decl.Type.TypeParams != nil {
+		sig.WriteString("[" + nodeString(fset, fieldList(decl.Type.TypeParams)) + "]")
+	}
+	sig.WriteString("(" + nodeString(fset, fieldList(decl.Type.Params)) + ")")
+
+	// Name the results so the stub can return zero values of any type
+	var results []string
+	returnsError := false
+	if decl.Type.Results != nil {
+		for _, field := range decl.Type.Results.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, fmt.Sprintf("lccR%d %s", len(results), nodeString(fset, field.Type)))
+			}
+		}
+		last := decl.Type.Results.List[len(decl.Type.Results.List)-1]
+		if id, ok := last.Type.(*ast.Ident); ok && id.Name == "error" {
+			returnsError = true
+		}
+	}
+	if len(results) > 0 {
+		sig.WriteString(" (" + strings.Join(results, ", ") + ")")
+	}
+
+	imports := usedImports(file, &ast.FuncDecl{Recv: decl.Recv, Type: decl.Type}, dir)
+	if returnsError {
+		imports[clientImportPath] = ""
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by lcc-strip. DO NOT EDIT.\n\n//go:build %s\n\npackage %s\n\n", tag, file.Name.Name)
+	writeImports(&buf, imports)
+	fmt.Fprintf(&buf, "// %s is stripped from this edition: feature %s is not licensed.\n", decl.Name.Name, featureID)
+	fmt.Fprintf(&buf, "%s {\n", sig.String())
+	if returnsError {
+		fmt.Fprintf(&buf, "\tlccR%d = client.NewFeatureError(%q, &client.FeatureStatus{Reason: client.ReasonStripped}, nil)\n", len(results)-1, featureID)
+	}
+	buf.WriteString("\treturn\n}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// fieldList returns fl, or an empty list for a missing one
+func fieldList(fl *ast.FieldList) *ast.FieldList {
+	if fl == nil {
+		return &ast.FieldList{}
+	}
+	return fl
+}
+
+// nodeString prints a field list's contents (without brackets) or a node
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	if fl, ok := node.(*ast.FieldList); ok {
+		parts := make([]string, 0, len(fl.List))
+		for _, field := range fl.List {
+			var names []string
+			for _, n := range field.Names {
+				names = append(names, n.Name)
+			}
+			typ := nodeString(fset, field.Type)
+			if len(names) > 0 {
+				parts = append(parts, strings.Join(names, ", ")+" "+typ)
+			} else {
+				parts = append(parts, typ)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	var buf bytes.Buffer
+	_ = format.Node(&buf, fset, node)
+	return buf.String()
+}
+
+// usedImports returns the imports of file (path → explicit name) referenced
+// by node
+func usedImports(file *ast.File, node ast.Node, dir string) map[string]string {
+	names := selectorPackages(node)
+	used := make(map[string]string)
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		name := importName(spec, dir)
+		if names[name] {
+			explicit := ""
+			if spec.Name != nil {
+				explicit = spec.Name.Name
+			}
+			used[p] = explicit
+		}
+	}
+	return used
+}
+
+// selectorPackages collects identifiers used as the X of selector
+// expressions, i.e. candidate package names
+func selectorPackages(node ast.Node) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				names[id.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// importName returns the name an import is referred to by
+func importName(spec *ast.ImportSpec, dir string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, _ := strconv.Unquote(spec.Path.Value)
+	if dir != "" {
+		if pkg, err := build.Import(p, dir, 0); err == nil && pkg.Name != "" {
+			return pkg.Name
+		}
+	}
+	// Fall back to the conventional name: last element without a
+	// major-version suffix ("gopkg.in/yaml.v3" → "yaml", ".../v2" → parent)
+	base := path.Base(p)
+	if strings.HasPrefix(base, "v") && strings.Trim(base[1:], "0123456789") == "" && path.Dir(p) != "." {
+		base = path.Base(path.Dir(p))
+	}
+	if i := strings.Index(base, ".v"); i > 0 {
+		base = base[:i]
+	}
+	return strings.TrimPrefix(base, "go-")
+}
+
+// dropUnusedImports removes imports no longer referenced by src
+func dropUnusedImports(src []byte, dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	names := selectorPackages(file)
+	kept := file.Imports[:0]
+	for _, spec := range file.Imports {
+		name := importName(spec, dir)
+		if name == "_" || name == "." || names[name] {
+			kept = append(kept, spec)
+		}
+	}
+	if len(kept) == len(file.Imports) {
+		return format.Source(src)
+	}
+
+	keep := make(map[*ast.ImportSpec]bool, len(kept))
+	for _, spec := range kept {
+		keep[spec] = true
+	}
+	var decls []ast.Decl
+	for _, d := range file.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, d)
+			continue
+		}
+		var specs []ast.Spec
+		for _, s := range gen.Specs {
+			if keep[s.(*ast.ImportSpec)] {
+				specs = append(specs, s)
+			}
+		}
+		if len(specs) > 0 {
+			gen.Specs = specs
+			decls = append(decls, gen)
+		}
+	}
+	file.Decls = decls
+	file.Imports = kept
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// writeImports writes an import block for imports (path → explicit name)
+func writeImports(buf *bytes.Buffer, imports map[string]string) {
+	if len(imports) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	// Standard library first, as goimports groups them
+	isStd := func(p string) bool { return !strings.Contains(strings.Split(p, "/")[0], ".") }
+	sort.Slice(paths, func(i, j int) bool {
+		if isStd(paths[i]) != isStd(paths[j]) {
+			return isStd(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+
+	buf.WriteString("import (\n")
+	for i, p := range paths {
+		if i > 0 && isStd(paths[i-1]) && !isStd(p) {
+			buf.WriteByte('\n')
+		}
+		if name := imports[p]; name != "" {
+			fmt.Fprintf(buf, "\t%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(buf, "\t%q\n", p)
+		}
+	}
+	buf.WriteString(")\n\n")
+}
@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/strip/golden")
+
+// stripManifest names the functions of testdata/strip/shop. Summary is
+// licensed and stays in place.
+var stripManifest = &config.Manifest{Features: []config.FeatureConfig{
+	{ID: "export", Intercept: config.InterceptConfig{Package: "example.com/app/shop", Function: "Export"}},
+	{ID: "bulk-import", Intercept: config.InterceptConfig{Package: "example.com/app/shop", Function: "Store.Bulk"}},
+	{ID: "merge", Intercept: config.InterceptConfig{Package: "example.com/app/shop", Function: "Merge"}},
+	{ID: "summary", Intercept: config.InterceptConfig{Package: "example.com/app/shop", Function: "Summary"}},
+}}
+
+func allowsSummary(featureID string) bool { return featureID == "summary" }
+
+func TestStripTag(t *testing.T) {
+	tests := map[string]string{
+		"export":             "lcc_strip_export",
+		"Advanced-Analytics": "lcc_strip_advanced_analytics",
+		"reports.v2":         "lcc_strip_reports_v2",
+	}
+	for featureID, want := range tests {
+		if got := StripTag(featureID); got != want {
+			t.Errorf("StripTag(%q) = %q, want %q", featureID, got, want)
+		}
+	}
+
+	got := NewStripper(stripManifest).EditionTags(allowsSummary)
+	want := []string{"lcc_strip_export", "lcc_strip_bulk_import", "lcc_strip_merge"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EditionTags() = %v, want %v", got, want)
+	}
+}
+
+func TestStripper_Prepare(t *testing.T) {
+	src := t.TempDir()
+	dir := copyShop(t, src, 0600)
+	s := NewStripper(stripManifest)
+
+	written, err := s.Prepare(src, allowsSummary)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	wantWritten := []string{
+		"lcc_full_export.go", "lcc_full_merge.go", "lcc_full_store_bulk.go",
+		"lcc_stub_export.go", "lcc_stub_merge.go", "lcc_stub_store_bulk.go",
+		"shop.go", "shop.go", "store.go",
+	}
+	if got := baseNames(written); !reflect.DeepEqual(got, wantWritten) {
+		t.Errorf("Prepare() wrote %v, want %v", got, wantWritten)
+	}
+	checkGolden(t, dir)
+
+	// The original file mode is kept
+	for _, name := range []string{"shop.go", "lcc_full_export.go", "lcc_stub_export.go"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s mode = %v, want 0600", name, mode)
+		}
+	}
+
+	// A second run only regenerates the stubs
+	written, err = s.Prepare(src, allowsSummary)
+	if err != nil {
+		t.Fatalf("second Prepare() error = %v", err)
+	}
+	wantWritten = []string{"lcc_stub_export.go", "lcc_stub_merge.go", "lcc_stub_store_bulk.go"}
+	if got := baseNames(written); !reflect.DeepEqual(got, wantWritten) {
+		t.Errorf("second Prepare() wrote %v, want %v", got, wantWritten)
+	}
+	checkGolden(t, dir)
+}
+
+func TestStripper_PrepareMissingFunction(t *testing.T) {
+	src := t.TempDir()
+	copyShop(t, src, 0644)
+	s := NewStripper(&config.Manifest{Features: []config.FeatureConfig{
+		{ID: "audit", Intercept: config.InterceptConfig{Package: "example.com/app/shop", Function: "Audit"}},
+	}})
+	if _, err := s.Prepare(src, allowsSummary); err == nil || !strings.Contains(err.Error(), "Audit not found") {
+		t.Errorf("Prepare() error = %v, want function not found", err)
+	}
+}
+
+// TestStripper_PrepareBuilds builds and tests the prepared package as the
+// full product and as the stripped edition
+func TestStripper_PrepareBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds with the go command")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	// Inside the module, so the stubs resolve the client package
+	src, err := os.MkdirTemp(".", "striptest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(src) })
+	copyShop(t, src, 0644)
+
+	s := NewStripper(stripManifest)
+	if _, err := s.Prepare(src, allowsSummary); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	pkg := "./" + filepath.ToSlash(filepath.Join(src, "shop"))
+	tags := strings.Join(s.EditionTags(allowsSummary), ",")
+	for _, args := range [][]string{
+		{"test", pkg},
+		{"test", "-tags", tags, pkg},
+	} {
+		cmd := exec.Command("go", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+// copyShop copies testdata/strip/shop into dir with mode and returns the
+// copy's directory
+func copyShop(t *testing.T, dir string, mode os.FileMode) string {
+	t.Helper()
+	from := filepath.Join("testdata", "strip", "shop")
+	to := filepath.Join(dir, "shop")
+	if err := os.MkdirAll(to, 0755); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(from, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(to, e.Name()), data, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return to
+}
+
+// checkGolden compares the non-test Go files of dir with
+// testdata/strip/golden, or rewrites the golden files with -update
+func checkGolden(t *testing.T, dir string) {
+	t.Helper()
+	goldenDir := filepath.Join("testdata", "strip", "golden")
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, m := range matches {
+		name := filepath.Base(m)
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		names = append(names, name)
+		got, err := os.ReadFile(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join(goldenDir, name+".golden")
+		if *update {
+			if err := os.WriteFile(golden, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs from %s:\n%s", name, golden, got)
+		}
+	}
+
+	goldens, err := filepath.Glob(filepath.Join(goldenDir, "*.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := baseNames(goldens); !*update && len(got) != len(names) {
+		t.Errorf("prepared files %v, want one per golden file %v", names, got)
+	}
+}
+
+// baseNames returns the sorted base names of paths
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = filepath.Base(p)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -153,7 +153,7 @@ var {{.OriginalName}}_Original = {{.OriginalName}}
 			{{if .HasFallback}}
 			{{.FallbackCall}}
 			{{else}}
-			{{.ErrorReturn}}
+			{{printf .ErrorReturn "client.LimitConcurrency"}}
 			{{end}}
 		}
 		defer release()
@@ -176,7 +176,7 @@ var {{.OriginalName}}_Original = {{.OriginalName}}
 			{{if .HasFallback}}
 			{{.FallbackCall}}
 			{{else}}
-			{{.ErrorReturn}}
+			{{printf .ErrorReturn "client.LimitQuota"}}
 			{{end}}
 		}
 	}
@@ -191,7 +191,7 @@ var {{.OriginalName}}_Original = {{.OriginalName}}
 			{{if .HasFallback}}
 			{{.FallbackCall}}
 			{{else}}
-			{{.ErrorReturn}}
+			{{printf .ErrorReturn "client.LimitTPS"}}
 			{{end}}
 		}
 	}
@@ -206,7 +206,7 @@ var {{.OriginalName}}_Original = {{.OriginalName}}
 			{{if .HasFallback}}
 			{{.FallbackCall}}
 			{{else}}
-			{{.ErrorReturn}}
+			{{printf .ErrorReturn "client.LimitCapacity"}}
 			{{end}}
 		}
 	}
@@ -230,7 +230,7 @@ type ZeroIntrusionFunctionTemplate struct {
 	PassArgs       bool   // Whether to pass args to QuotaConsumer
 	HasFallback    bool
 	FallbackCall   string
-	ErrorReturn    string
+	ErrorReturn    string // format with one %s for the exceeded limit
 	OriginalCall   string
 }
 
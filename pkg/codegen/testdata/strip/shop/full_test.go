@@ -0,0 +1,5 @@
+//go:build !lcc_strip_export
+
+package shop
+
+const stripped = false
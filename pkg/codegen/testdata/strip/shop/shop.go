@@ -0,0 +1,47 @@
+package shop
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Order is a customer order
+type Order struct {
+	ID    string
+	Total float64
+}
+
+// Summary describes orders for humans
+func Summary(orders []Order) string {
+	return fmt.Sprintf("%d orders", len(orders))
+}
+
+// Export writes orders as CSV and returns the number of rows written
+func Export(ctx context.Context, orders []Order) (string, int, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	for _, o := range orders {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		if err := w.Write([]string{o.ID, fmt.Sprint(o.Total)}); err != nil {
+			return "", 0, err
+		}
+	}
+	w.Flush()
+	return b.String(), len(orders), w.Error()
+}
+
+// Merge combines two lookup tables, b winning on conflicts
+func Merge[K comparable, V any](a, b map[K]V) map[K]V {
+	out := make(map[K]V, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
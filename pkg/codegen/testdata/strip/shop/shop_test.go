@@ -0,0 +1,42 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// TestEdition runs against the full product and against the edition built
+// with every feature stripped
+func TestEdition(t *testing.T) {
+	csv, rows, exportErr := Export(context.Background(), []Order{{ID: "a", Total: 1}})
+	var s Store[int]
+	added, bulkErr := s.Bulk(func(a, b int) bool { return a < b }, 2, 1)
+	merged := Merge(map[string]int{"a": 1}, map[string]int{"b": 2})
+
+	if stripped {
+		if !errors.Is(exportErr, client.ErrFeatureNotLicensed) || csv != "" || rows != 0 {
+			t.Errorf("Export() = %q, %d, %v; want a not-licensed error", csv, rows, exportErr)
+		}
+		var fe *client.FeatureNotLicensedError
+		if !errors.As(bulkErr, &fe) || fe.FeatureID != "bulk-import" || fe.Reason != client.ReasonStripped || added != 0 {
+			t.Errorf("Bulk() = %d, %v; want a stripped error for bulk-import", added, bulkErr)
+		}
+		if merged != nil {
+			t.Errorf("Merge() = %v, want nil", merged)
+		}
+		return
+	}
+
+	if csv != "a,1\n" || rows != 1 || exportErr != nil {
+		t.Errorf("Export() = %q, %d, %v", csv, rows, exportErr)
+	}
+	if added != 2 || bulkErr != nil || len(s.items) != 2 || s.items[0] != 1 {
+		t.Errorf("Bulk() = %d, %v; items %v", added, bulkErr, s.items)
+	}
+	if len(merged) != 2 {
+		t.Errorf("Merge() = %v", merged)
+	}
+}
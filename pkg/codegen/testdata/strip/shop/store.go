@@ -0,0 +1,27 @@
+package shop
+
+import (
+	"errors"
+	"sort"
+)
+
+// Store holds items in insertion order
+type Store[T any] struct {
+	items []T
+}
+
+// Add appends an item
+func (s *Store[T]) Add(item T) {
+	s.items = append(s.items, item)
+}
+
+// Bulk appends items sorted by less and reports how many were added
+func (s *Store[T]) Bulk(less func(a, b T) bool, items ...T) (added int, err error) {
+	if less == nil {
+		return 0, errors.New("bulk: less is nil")
+	}
+	sorted := append([]T(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	s.items = append(s.items, sorted...)
+	return len(sorted), nil
+}
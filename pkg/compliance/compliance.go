@@ -0,0 +1,192 @@
+// Package compliance defines the license compliance report an instance
+// produces for audits (see client.Client.ComplianceReport).
+//
+// A report combines the instance's entitlement snapshot with the usage it
+// measured locally over a period: feature checks, consumed and reported
+// units, and capacity and concurrency peaks. The instance signs the exact
+// report bytes with its key, so the customer can hand the file to the vendor
+// and the vendor can check it was produced by a registered instance and not
+// edited since. Reports are plain data with sorted lists, ready to be
+// rendered as tables (e.g. to PDF) by the customer's tooling.
+package compliance
+
+import (
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// ReportVersion is the version of the report format
+const ReportVersion = 1
+
+// ErrInvalidSignature is returned when a signed report does not verify
+var ErrInvalidSignature = errors.New("compliance report signature is invalid")
+
+// Period is the time range a report covers, End excluded
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Validate checks the period is not empty
+func (p Period) Validate() error {
+	if p.Start.IsZero() || p.End.IsZero() || !p.Start.Before(p.End) {
+		return fmt.Errorf("invalid compliance period %s - %s", p.Start.Format(time.RFC3339), p.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Report is the compliance report of one instance
+type Report struct {
+	Version        int    `json:"version"`
+	ProductID      string `json:"product_id"`
+	ProductVersion string `json:"product_version"`
+	InstanceID     string `json:"instance_id"`
+	Environment    string `json:"environment,omitempty"`
+	ClusterID      string `json:"cluster_id,omitempty"`
+	Region         string `json:"region,omitempty"`
+
+	Period      Period    `json:"period"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// CoveredFrom is the start of the usage the instance measured within
+	// the period. Usage before it (before the client started, or past its
+	// retention) is not in the report.
+	CoveredFrom time.Time `json:"covered_from"`
+
+	// Entitlements is the license as last seen by the instance, sorted by
+	// feature ID
+	Entitlements []Entitlement `json:"entitlements"`
+
+	// Features, Usage and Peaks are measured over the period
+	Features []FeatureUsage `json:"features"`
+	Usage    []UsageTotal   `json:"usage"`
+	Peaks    Peaks          `json:"peaks"`
+}
+
+// Entitlement is the licensed status of a feature, or of the product limits
+// (FeatureID "__product__")
+type Entitlement struct {
+	FeatureID      string  `json:"feature_id"`
+	Enabled        bool    `json:"enabled"`
+	Reason         string  `json:"reason,omitempty"`
+	Variant        string  `json:"variant,omitempty"`
+	MaxTPS         float64 `json:"max_tps,omitempty"`
+	MaxCapacity    int     `json:"max_capacity,omitempty"`
+	MaxConcurrency int     `json:"max_concurrency,omitempty"`
+	Quotas         []Quota `json:"quotas,omitempty"`
+
+	// CheckedUntil is when the instance's copy of the status expires
+	CheckedUntil time.Time `json:"checked_until"`
+}
+
+// Quota is a licensed quota; Dimension is "" for a feature's single quota
+type Quota struct {
+	Dimension string `json:"dimension,omitempty"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+}
+
+// FeatureUsage counts the checks of a feature by outcome. Failed checks
+// count in Checks only.
+type FeatureUsage struct {
+	FeatureID string `json:"feature_id"`
+	Checks    int64  `json:"checks"`
+	Allowed   int64  `json:"allowed"`
+	Denied    int64  `json:"denied"`
+}
+
+// UsageTotal sums the units used of a feature (or quota dimension) and the
+// calls denied for lack of quota
+type UsageTotal struct {
+	FeatureID string  `json:"feature_id"`
+	Dimension string  `json:"dimension,omitempty"`
+	Units     float64 `json:"units"`
+	Calls     int64   `json:"calls"`
+	Denied    int64   `json:"denied"`
+}
+
+// Peaks are the highest capacity in use (CheckCapacity) and concurrency
+// slots held, with when they were reached; zero if never observed
+type Peaks struct {
+	Capacity      int       `json:"capacity"`
+	CapacityAt    time.Time `json:"capacity_at,omitempty"`
+	Concurrency   int       `json:"concurrency"`
+	ConcurrencyAt time.Time `json:"concurrency_at,omitempty"`
+}
+
+// SignedReport is a report signed by the instance key. The signature
+// covers the exact report bytes, which are base64-encoded so the file
+// survives reformatting.
+type SignedReport struct {
+	Report    []byte `json:"report"`
+	Signature string `json:"signature"`
+
+	// PublicKey is the PEM public key of the instance, to look up its
+	// registration; verify against the registered key, not this one
+	PublicKey string `json:"public_key"`
+}
+
+// Sign encodes and signs r with the instance key
+func Sign(r *Report, kp auth.KeyPair) (*SignedReport, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode compliance report: %w", err)
+	}
+	signature, err := kp.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := kp.GetPublicKeyPEM()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+	return &SignedReport{Report: payload, Signature: hex.EncodeToString(signature), PublicKey: publicKey}, nil
+}
+
+// Verify checks the signature against the instance's public key and
+// returns the report
+func (s *SignedReport) Verify(publicKey crypto.PublicKey) (*Report, error) {
+	signature, err := hex.DecodeString(s.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := auth.VerifySignature(publicKey, s.Report, signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	var r Report
+	if err := json.Unmarshal(s.Report, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse compliance report: %w", err)
+	}
+	return &r, nil
+}
+
+// WriteFile writes the signed report as JSON
+func (s *SignedReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode compliance report: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadFile reads a signed report written by SignedReport.WriteFile
+func ReadFile(path string) (*SignedReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s SignedReport
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse compliance report: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,112 @@
+package compliance
+
+import (
+	"crypto"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+func newInstance(t *testing.T) (auth.KeyPair, crypto.PublicKey) {
+	t.Helper()
+	kp, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	pemStr, _ := kp.GetPublicKeyPEM()
+	pub, err := auth.ParsePublicKeyPEM([]byte(pemStr))
+	if err != nil {
+		t.Fatalf("ParsePublicKeyPEM() error = %v", err)
+	}
+	return kp, pub
+}
+
+func TestReport_RoundTrip(t *testing.T) {
+	instance, key := newInstance(t)
+	end := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	report := &Report{
+		Version:   ReportVersion,
+		ProductID: "app",
+		Period:    Period{Start: end.AddDate(0, -1, 0), End: end},
+		Usage:     []UsageTotal{{FeatureID: "export", Units: 1200, Calls: 12}},
+		Peaks:     Peaks{Capacity: 40, CapacityAt: end.Add(-time.Hour)},
+	}
+
+	signed, err := Sign(report, instance)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "compliance-report.json")
+	if err := signed.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	loaded, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	got, err := loaded.Verify(key)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got.ProductID != "app" || len(got.Usage) != 1 || got.Usage[0].Units != 1200 || got.Peaks.Capacity != 40 {
+		t.Errorf("Verify() = %+v, want the signed report", got)
+	}
+}
+
+func TestSignedReport_Verify(t *testing.T) {
+	instance, key := newInstance(t)
+	_, otherKey := newInstance(t)
+	signed, err := Sign(&Report{Version: ReportVersion, ProductID: "app"}, instance)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tampered := *signed
+	tampered.Report = append([]byte{}, signed.Report...)
+	tampered.Report[len(tampered.Report)-2] ^= 1
+
+	tests := []struct {
+		name    string
+		signed  *SignedReport
+		key     crypto.PublicKey
+		wantErr error
+	}{
+		{"valid", signed, key, nil},
+		{"other instance key", signed, otherKey, ErrInvalidSignature},
+		{"tampered report", &tampered, key, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.signed.Verify(tt.key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPeriod_Validate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		period  Period
+		wantErr bool
+	}{
+		{"month", Period{Start: now.AddDate(0, -1, 0), End: now}, false},
+		{"empty", Period{Start: now, End: now}, true},
+		{"reversed", Period{Start: now, End: now.Add(-time.Hour)}, true},
+		{"open ended", Period{Start: now}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.period.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseDuration parses a human-friendly duration such as "5s", "2m",
+// "1h30m" or "7d". A bare "0" is accepted; other unitless numbers are
+// rejected because they are almost always a misconfiguration.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	if strings.HasSuffix(s, "d") {
+		return ParseWindow(s)
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		if strings.Trim(s, "0123456789.-") == "" {
+			return 0, fmt.Errorf("duration %q has no unit (e.g. %q or %q)", s, s+"s", s+"m")
+		}
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// UnmarshalYAML decodes SDKConfig, accepting human-friendly duration strings
+// for check_interval, cache_ttl, timeout, dedup_window, breaker_cooldown,
+// cache_max_stale and downgrade_grace
+func (c *SDKConfig) UnmarshalYAML(value *yaml.Node) error {
+	if err := normalizeDurations(value, "check_interval", "cache_ttl", "timeout", "dedup_window", "breaker_cooldown", "cache_max_stale", "downgrade_grace"); err != nil {
+		return err
+	}
+	type plain SDKConfig
+	return value.Decode((*plain)(c))
+}
+
+// UnmarshalYAML decodes FeaturePolicy, accepting human-friendly duration
+// strings for cache_ttl
+func (p *FeaturePolicy) UnmarshalYAML(value *yaml.Node) error {
+	if err := normalizeDurations(value, "cache_ttl"); err != nil {
+		return err
+	}
+	type plain FeaturePolicy
+	return value.Decode((*plain)(p))
+}
+
+// UnmarshalYAML decodes ProductLimits, accepting human-friendly duration
+// strings for max_wait and expressions for max_tps, max_capacity and
+// max_concurrency
+func (p *ProductLimits) UnmarshalYAML(value *yaml.Node) error {
+	if err := normalizeDurations(value, "max_wait"); err != nil {
+		return err
+	}
+	exprs, err := extractLimitExprs(value, LimitMaxTPS, LimitMaxCapacity, LimitMaxConcurrency)
+	if err != nil {
+		return err
+	}
+	type plain ProductLimits
+	if err := value.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	if exprs != nil {
+		p.Expressions = exprs
+	}
+	return nil
+}
+
+// normalizeDurations parses the given keys of a mapping node with
+// ParseDuration and rewrites them in Go duration syntax, which the YAML
+// decoder understands. Errors carry the YAML line.
+func normalizeDurations(value *yaml.Node, keys ...string) error {
+	if value.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		if val.Kind != yaml.ScalarNode || !containsString(keys, key.Value) {
+			continue
+		}
+
+		d, err := ParseDuration(val.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", val.Line, key.Value, err)
+		}
+		val.Tag = "!!str"
+		val.Value = d.String()
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits of ProductLimits that accept an expression instead of a number
+const (
+	LimitMaxTPS         = "max_tps"
+	LimitMaxCapacity    = "max_capacity"
+	LimitMaxConcurrency = "max_concurrency"
+)
+
+// maxLimitExprLen bounds the length of a limit expression
+const maxLimitExprLen = 256
+
+// LimitExpr is a parsed limit expression such as "base * instances" or
+// "min(base * replicas, 5000)". It supports numbers, variables, + - * /,
+// unary minus, parentheses, and the functions min and max.
+type LimitExpr struct {
+	src  string
+	root exprNode
+}
+
+// ParseLimitExpr parses a limit expression
+func ParseLimitExpr(s string) (*LimitExpr, error) {
+	if len(s) > maxLimitExprLen {
+		return nil, fmt.Errorf("expression longer than %d characters", maxLimitExprLen)
+	}
+	p := &exprParser{src: s}
+	p.next()
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.tok, p.tokPos)
+	}
+	return &LimitExpr{src: strings.TrimSpace(s), root: root}, nil
+}
+
+// Eval evaluates the expression with the given variables. Unknown
+// variables and division by zero are errors.
+func (e *LimitExpr) Eval(vars map[string]float64) (float64, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%s is not a finite number", e.src)
+	}
+	return v, nil
+}
+
+// Vars returns the variables the expression refers to, sorted
+func (e *LimitExpr) Vars() []string {
+	seen := make(map[string]bool)
+	e.root.vars(seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the expression source
+func (e *LimitExpr) String() string {
+	return e.src
+}
+
+// exprNode is a node of a parsed limit expression
+type exprNode interface {
+	eval(vars map[string]float64) (float64, error)
+	vars(seen map[string]bool)
+}
+
+type numNode float64
+
+func (n numNode) eval(map[string]float64) (float64, error) { return float64(n), nil }
+func (n numNode) vars(map[string]bool)                     {}
+
+type varNode string
+
+func (n varNode) eval(vars map[string]float64) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", string(n))
+	}
+	return v, nil
+}
+
+func (n varNode) vars(seen map[string]bool) { seen[string(n)] = true }
+
+type negNode struct{ x exprNode }
+
+func (n negNode) eval(vars map[string]float64) (float64, error) {
+	v, err := n.x.eval(vars)
+	return -v, err
+}
+
+func (n negNode) vars(seen map[string]bool) { n.x.vars(seen) }
+
+type binNode struct {
+	op   byte
+	l, r exprNode
+}
+
+func (n binNode) eval(vars map[string]float64) (float64, error) {
+	l, err := n.l.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.r.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+}
+
+func (n binNode) vars(seen map[string]bool) {
+	n.l.vars(seen)
+	n.r.vars(seen)
+}
+
+type callNode struct {
+	fn   string
+	args []exprNode
+}
+
+func (n callNode) eval(vars map[string]float64) (float64, error) {
+	var out float64
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 || (n.fn == "min" && v < out) || (n.fn == "max" && v > out) {
+			out = v
+		}
+	}
+	return out, nil
+}
+
+func (n callNode) vars(seen map[string]bool) {
+	for _, arg := range n.args {
+		arg.vars(seen)
+	}
+}
+
+// maxExprDepth bounds the nesting of parentheses and calls
+const maxExprDepth = 16
+
+// exprParser is a recursive descent parser over the tokens of an
+// expression
+type exprParser struct {
+	src    string
+	pos    int
+	tok    string // current token, "" at the end
+	tokPos int
+}
+
+// next advances to the next token
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	p.tokPos = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	start := p.pos
+	switch c := rune(p.src[p.pos]); {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && isIdentChar(rune(p.src[p.pos])) {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+func isIdentChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.'
+}
+
+// parseSum parses term (('+' | '-') term)*
+func (p *exprParser) parseSum(depth int) (exprNode, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "+" || p.tok == "-" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses unary (('*' | '/') unary)*
+func (p *exprParser) parseProduct(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "*" || p.tok == "/" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses '-' unary | primary
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	if p.tok == "-" {
+		p.next()
+		x, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x: x}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+// parsePrimary parses a number, a variable, a call or a parenthesized
+// expression
+func (p *exprParser) parsePrimary(depth int) (exprNode, error) {
+	if depth >= maxExprDepth {
+		return nil, fmt.Errorf("expression nested deeper than %d levels", maxExprDepth)
+	}
+	tok, pos := p.tok, p.tokPos
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tok == "(":
+		p.next()
+		x, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, fmt.Errorf("missing ) for ( at offset %d", pos)
+		}
+		p.next()
+		return x, nil
+	case unicode.IsDigit(rune(tok[0])) || tok[0] == '.':
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", tok, pos)
+		}
+		p.next()
+		return numNode(v), nil
+	case unicode.IsLetter(rune(tok[0])) || tok[0] == '_':
+		p.next()
+		if p.tok != "(" {
+			return varNode(tok), nil
+		}
+		if tok != "min" && tok != "max" {
+			return nil, fmt.Errorf("unknown function %q at offset %d", tok, pos)
+		}
+		p.next()
+		call := callNode{fn: tok}
+		for {
+			arg, err := p.parseSum(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.tok != "," {
+				break
+			}
+			p.next()
+		}
+		if p.tok != ")" {
+			return nil, fmt.Errorf("missing ) for %s( at offset %d", tok, pos)
+		}
+		p.next()
+		return call, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok, pos)
+	}
+}
+
+// extractLimitExprs removes the limits of a mapping node whose value is
+// not a number and returns them as expressions keyed by limit name, so the
+// numeric fields decode normally. Errors carry the YAML line.
+func extractLimitExprs(value *yaml.Node, keys ...string) (map[string]string, error) {
+	if value.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var exprs map[string]string
+	content := value.Content[:0]
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		if val.Kind == yaml.ScalarNode && containsString(keys, key.Value) {
+			if _, err := strconv.ParseFloat(val.Value, 64); err != nil {
+				if _, err := ParseLimitExpr(val.Value); err != nil {
+					return nil, fmt.Errorf("line %d: %s: invalid expression: %w", val.Line, key.Value, err)
+				}
+				if exprs == nil {
+					exprs = make(map[string]string)
+				}
+				exprs[key.Value] = val.Value
+				continue
+			}
+		}
+		content = append(content, key, val)
+	}
+	value.Content = content
+	return exprs, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,28 +16,40 @@ func LoadManifest(path string) (*Manifest, error) {
 		return nil, fmt.Errorf("failed to read manifest file: %w", err)
 	}
 
-	// Parse YAML
-	manifest := GetDefaults()
-	if err := yaml.Unmarshal(data, manifest); err != nil {
+	return LoadManifestFromBytes(data)
+}
+
+// LoadManifestFromBytes loads manifest from byte slice.
+// String values in the sdk section may be secret references (see
+// ResolveSecret). Validation errors carry the YAML line and column of the
+// offending field.
+func LoadManifestFromBytes(data []byte) (*Manifest, error) {
+	// Parse YAML, keeping the node tree for error positions
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
-	// Validate
-	if err := manifest.Validate(); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	// Resolve env:, file: and exec: references in the sdk section
+	if len(root.Content) > 0 {
+		if sdk := mappingValue(root.Content[0], "sdk"); sdk != nil {
+			if err := resolveSecrets(sdk); err != nil {
+				return nil, fmt.Errorf("failed to resolve secret: %w", err)
+			}
+		}
 	}
 
-	return manifest, nil
-}
-
-// LoadManifestFromBytes loads manifest from byte slice
-func LoadManifestFromBytes(data []byte) (*Manifest, error) {
 	manifest := GetDefaults()
-	if err := yaml.Unmarshal(data, manifest); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if root.Kind != 0 {
+		if err := root.Decode(manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
 	}
+	manifest.SDK.ApplyDefaults()
 
+	// Validate
 	if err := manifest.Validate(); err != nil {
+		annotateLines(&root, err)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -114,3 +127,24 @@ func (m *Manifest) GetRequiredFeatures() []FeatureConfig {
 	}
 	return features
 }
+
+// GroupFeatures returns the feature IDs of a group
+func (m *Manifest) GroupFeatures(groupID string) ([]string, bool) {
+	ids, ok := m.Groups[groupID]
+	return ids, ok
+}
+
+// GetGroupIDs returns all group names in sorted order
+func (m *Manifest) GetGroupIDs() []string {
+	return sortedKeys(m.Groups)
+}
+
+// sortedKeys returns the keys of a group map in sorted order
+func sortedKeys(groups map[string][]string) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -325,3 +329,1131 @@ func TestValidationErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestSDKConfig_ValidateAggregates(t *testing.T) {
+	cfg := &SDKConfig{
+		Limits: &ProductLimits{MaxTPS: -1, Quota: &ProductQuotaConfig{Window: "1h"}},
+	}
+
+	err := cfg.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+
+	want := []string{"sdk.lcc_url", "sdk.product_id", "sdk.product_version", "sdk.limits.quota.max", "sdk.limits.max_tps"}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), err)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("errs[%d].Field = %q, want %q", i, errs[i].Field, field)
+		}
+	}
+
+	var one *ValidationError
+	if !errors.As(err, &one) || one.Field != "sdk.lcc_url" {
+		t.Errorf("errors.As(*ValidationError) = %v", one)
+	}
+
+	// Validate must not apply defaults
+	if cfg.Timeout != 0 || cfg.CacheTTL != 0 {
+		t.Error("Validate() mutated the receiver")
+	}
+	cfg.ApplyDefaults()
+	if cfg.Timeout != 5*time.Second || cfg.CacheTTL != 10*time.Second || cfg.CheckInterval != 30*time.Second || cfg.MaxRetries != 3 {
+		t.Errorf("ApplyDefaults() = %+v", cfg)
+	}
+
+	// ApplyDurationDefaults keeps a zero MaxRetries
+	cfg = &SDKConfig{}
+	cfg.ApplyDurationDefaults()
+	if cfg.Timeout != 5*time.Second || cfg.CacheTTL != 10*time.Second || cfg.CheckInterval != 30*time.Second || cfg.MaxRetries != 0 {
+		t.Errorf("ApplyDurationDefaults() = %+v", cfg)
+	}
+}
+
+func TestLoadManifestFromBytes_ErrorLines(t *testing.T) {
+	data := []byte(`sdk:
+  lcc_url: "http://localhost:7086"
+  product_version: "1.0.0"
+features:
+  - id: a
+    name: A
+    intercept:
+      package: p
+      function: F
+    quota:
+      limit: 10
+      period: yearly
+`)
+
+	_, err := LoadManifestFromBytes(data)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("LoadManifestFromBytes() error = %v, want ValidationErrors", err)
+	}
+
+	lines := map[string]int{}
+	for _, ve := range errs {
+		lines[ve.Field] = ve.Line
+	}
+	// Missing key points at its parent mapping
+	if got := lines["sdk.product_id"]; got != 2 {
+		t.Errorf("sdk.product_id line = %d, want 2", got)
+	}
+	if got := lines["features[0].quota"]; got != 11 {
+		t.Errorf("features[0].quota line = %d, want 11", got)
+	}
+	if !strings.Contains(err.Error(), "line 11") {
+		t.Errorf("error message lacks line info: %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"5s", 5 * time.Second, false},
+		{"2m", 2 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0", 0, false},
+		{"30", 0, true},
+		{"1.5", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDuration(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadManifestFromBytes_Durations(t *testing.T) {
+	base := `sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+`
+	m, err := LoadManifestFromBytes([]byte(base + `  timeout: 1h30m
+  cache_ttl: 2m
+features:
+  - id: a
+    name: A
+    intercept: {package: p, function: F}
+    policy:
+      cache_ttl: 1d
+`))
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if m.SDK.Timeout != 90*time.Minute || m.SDK.CacheTTL != 2*time.Minute {
+		t.Errorf("SDK durations = %v, %v", m.SDK.Timeout, m.SDK.CacheTTL)
+	}
+	if m.SDK.CheckInterval != 30*time.Second {
+		t.Errorf("CheckInterval default = %v, want 30s", m.SDK.CheckInterval)
+	}
+	if got := m.Features[0].Policy.CacheTTL; got != 24*time.Hour {
+		t.Errorf("policy cache_ttl = %v, want 24h", got)
+	}
+
+	_, err = LoadManifestFromBytes([]byte(base + "  timeout: 30\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 5") || !strings.Contains(err.Error(), "no unit") {
+		t.Errorf("unitless timeout error = %v", err)
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("LCC_TEST_SECRET", "from-env")
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "plain", false},
+		{"env:LCC_TEST_SECRET", "from-env", false},
+		{"env:LCC_TEST_UNSET_SECRET", "", true},
+		{"file:" + path, "from-file", false},
+		{"file:/nonexistent/secret", "", true},
+		{"exec:echo from-exec", "from-exec", false},
+		{"exec:false", "", true},
+		{"exec:", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ResolveSecret(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSecret(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSecret(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadManifestFromBytes_Secrets(t *testing.T) {
+	t.Setenv("LCC_TEST_URL", "https://lcc.internal:7086")
+	t.Setenv("LCC_TEST_PROXY_PASSWORD", "secret")
+
+	m, err := LoadManifestFromBytes([]byte(`sdk:
+  lcc_url: env:LCC_TEST_URL
+  product_id: app
+  product_version: "1.0.0"
+  proxy:
+    url: http://proxy.example.com:3128
+    username: svc
+    password: env:LCC_TEST_PROXY_PASSWORD
+`))
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if m.SDK.LCCURL != "https://lcc.internal:7086" {
+		t.Errorf("LCCURL = %q", m.SDK.LCCURL)
+	}
+	if m.SDK.Proxy == nil || m.SDK.Proxy.Password != "secret" {
+		t.Errorf("Proxy = %+v, want the password resolved", m.SDK.Proxy)
+	}
+
+	_, err = LoadManifestFromBytes([]byte(`sdk:
+  lcc_url: env:LCC_TEST_UNSET_URL
+`))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("unresolvable secret error = %v, want line 2", err)
+	}
+}
+
+func TestManifest_ValidateGroups(t *testing.T) {
+	m := &Manifest{
+		SDK: SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"},
+		Features: []FeatureConfig{
+			{ID: "reports", Name: "Reports", Intercept: InterceptConfig{Package: "p", Function: "F"}},
+		},
+		Groups: map[string][]string{"suite": {"reports"}},
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if ids, ok := m.GroupFeatures("suite"); !ok || len(ids) != 1 {
+		t.Errorf("GroupFeatures(suite) = %v, %v", ids, ok)
+	}
+
+	m.Groups["broken"] = []string{"reports", "nope"}
+	m.Groups["empty"] = nil
+	var errs ValidationErrors
+	if err := m.Validate(); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Validate() error = %v, want 2 group errors", err)
+	}
+	if errs[0].Field != "groups.broken[1]" || errs[1].Field != "groups.empty" {
+		t.Errorf("fields = %q, %q", errs[0].Field, errs[1].Field)
+	}
+}
+
+func TestFeaturePolicy_ValidateEnforcePercent(t *testing.T) {
+	for _, tt := range []struct {
+		percent int
+		wantErr bool
+	}{
+		{0, false},
+		{25, false},
+		{100, false},
+		{-1, true},
+		{101, true},
+	} {
+		percent := tt.percent
+		p := FeaturePolicy{EnforcePercent: &percent}
+		if err := p.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with enforce_percent %d error = %v, wantErr %v", tt.percent, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"1h", time.Hour, false},
+		{"24h", 24 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"monthly", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseWindow(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarWindow(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Wednesday
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, loc)
+
+	tests := []struct {
+		period    string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{PeriodHourly, time.Date(2026, 10, 14, 15, 0, 0, 0, loc), time.Date(2026, 10, 14, 16, 0, 0, 0, loc)},
+		{PeriodDaily, time.Date(2026, 10, 14, 0, 0, 0, 0, loc), time.Date(2026, 10, 15, 0, 0, 0, 0, loc)},
+		{PeriodWeekly, time.Date(2026, 10, 12, 0, 0, 0, 0, loc), time.Date(2026, 10, 19, 0, 0, 0, 0, loc)},
+		{PeriodMonthly, time.Date(2026, 10, 1, 0, 0, 0, 0, loc), time.Date(2026, 11, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end, err := CalendarWindow(tt.period, now)
+			if err != nil {
+				t.Fatalf("CalendarWindow() error = %v", err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("CalendarWindow() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+
+	if _, _, err := CalendarWindow("yearly", now); err == nil {
+		t.Error("CalendarWindow() with unknown period should fail")
+	}
+}
+
+func TestProductLimits_ValidateCalendar(t *testing.T) {
+	valid := &ProductLimits{Quota: &ProductQuotaConfig{Max: 10, Window: "daily", Type: WindowCalendar, Timezone: "UTC"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	badPeriod := &ProductLimits{Quota: &ProductQuotaConfig{Max: 10, Window: "24h", Type: WindowCalendar}}
+	if err := badPeriod.Validate(); err == nil {
+		t.Error("Validate() should reject duration window for calendar type")
+	}
+
+	badTZ := &ProductLimits{Quota: &ProductQuotaConfig{Max: 10, Window: "daily", Type: WindowCalendar, Timezone: "Mars/Olympus"}}
+	if err := badTZ.Validate(); err == nil {
+		t.Error("Validate() should reject unknown timezone")
+	}
+}
+
+func TestProductLimits_ValidateOverflowQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  ProductLimits
+		wantErr bool
+	}{
+		{"disabled", ProductLimits{MaxConcurrency: 4}, false},
+		{"queue with wait", ProductLimits{MaxConcurrency: 4, OverflowQueue: 8, MaxWait: time.Second}, false},
+		{"queue without wait", ProductLimits{MaxConcurrency: 4, OverflowQueue: 8}, true},
+		{"negative queue", ProductLimits{OverflowQueue: -1}, true},
+		{"negative wait", ProductLimits{MaxWait: -time.Second}, true},
+		{"rate limits", ProductLimits{RateLimits: []RateLimit{{Limit: 100, Window: "1s"}, {Limit: 50000, Window: "1d"}}}, false},
+		{"rate limit without limit", ProductLimits{RateLimits: []RateLimit{{Window: "1h"}}}, true},
+		{"rate limit bad window", ProductLimits{RateLimits: []RateLimit{{Limit: 10, Window: "hourly"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadManifestFromBytes_MaxWait(t *testing.T) {
+	m, err := LoadManifestFromBytes([]byte(`sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+  limits:
+    max_concurrency: 2
+    overflow_queue: 10
+    max_wait: 250ms
+    rate_limits:
+      - {limit: 50000, window: 1h}
+features:
+  - id: a
+    name: A
+    intercept: {package: p, function: F}
+`))
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if got := m.SDK.Limits.MaxWait; got != 250*time.Millisecond {
+		t.Errorf("MaxWait = %v, want 250ms", got)
+	}
+	if got := m.SDK.Limits.OverflowQueue; got != 10 {
+		t.Errorf("OverflowQueue = %d, want 10", got)
+	}
+	if got := m.SDK.Limits.RateLimits; len(got) != 1 || got[0] != (RateLimit{Limit: 50000, Window: "1h"}) {
+		t.Errorf("RateLimits = %+v, want 50000 per 1h", got)
+	}
+}
+
+func TestLoadManifestFromBytes_LimitExpressions(t *testing.T) {
+	manifest := func(maxTPS string) []byte {
+		return []byte(`sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+  limits:
+    max_tps: ` + maxTPS + `
+    max_concurrency: "min(base * instances, 50)"
+    max_capacity: 100
+features:
+  - id: a
+    name: A
+    intercept: {package: p, function: F}
+`)
+	}
+
+	m, err := LoadManifestFromBytes(manifest(`"base * instances"`))
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	want := map[string]string{LimitMaxTPS: "base * instances", LimitMaxConcurrency: "min(base * instances, 50)"}
+	if got := m.SDK.Limits.Expressions; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expressions = %v, want %v", got, want)
+	}
+	if l := m.SDK.Limits; l.MaxCapacity != 100 || l.MaxTPS != 0 {
+		t.Errorf("MaxCapacity = %d, MaxTPS = %v; want 100 and 0", l.MaxCapacity, l.MaxTPS)
+	}
+
+	if _, err := LoadManifestFromBytes(manifest(`"base *"`)); err == nil || !strings.Contains(err.Error(), "max_tps") {
+		t.Errorf("LoadManifestFromBytes() with an invalid expression error = %v", err)
+	}
+	limits := &ProductLimits{Expressions: map[string]string{"max_wait": "base * 2"}}
+	if err := limits.Validate(); err == nil {
+		t.Error("Validate() accepted an expression for max_wait")
+	}
+}
+
+func TestParseLimitExpr(t *testing.T) {
+	vars := map[string]float64{"base": 100, "instances": 3, "zero": 0}
+	tests := []struct {
+		expr     string
+		want     float64
+		parseErr bool
+		evalErr  bool
+	}{
+		{"base * instances", 300, false, false},
+		{"base + instances * 2", 106, false, false},
+		{"(base + instances) * 2", 206, false, false},
+		{"-base + 150", 50, false, false},
+		{"base / 4 - 1.5", 23.5, false, false},
+		{"min(base * instances, 250)", 250, false, false},
+		{"max(base, instances, 500)", 500, false, false},
+		{"42", 42, false, false},
+		{"base / zero", 0, false, true},
+		{"base * replicas", 0, false, true},
+		{"", 0, true, false},
+		{"base *", 0, true, false},
+		{"(base", 0, true, false},
+		{"base instances", 0, true, false},
+		{"pow(base, 2)", 0, true, false},
+		{"base % 2", 0, true, false},
+		{"1.2.3", 0, true, false},
+		{strings.Repeat("(", 20) + "1" + strings.Repeat(")", 20), 0, true, false},
+	}
+	for _, tt := range tests {
+		expr, err := ParseLimitExpr(tt.expr)
+		if (err != nil) != tt.parseErr {
+			t.Errorf("ParseLimitExpr(%q) error = %v, wantErr %v", tt.expr, err, tt.parseErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		got, err := expr.Eval(vars)
+		if (err != nil) != tt.evalErr || (err == nil && got != tt.want) {
+			t.Errorf("Eval(%q) = %v, %v; want %v, evalErr %v", tt.expr, got, err, tt.want, tt.evalErr)
+		}
+	}
+
+	expr, _ := ParseLimitExpr("min(base * instances, base + cap)")
+	if got := expr.Vars(); !reflect.DeepEqual(got, []string{"base", "cap", "instances"}) {
+		t.Errorf("Vars() = %v", got)
+	}
+}
+
+func TestSDKConfig_ValidateDedupWindow(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		window  time.Duration
+		wantErr bool
+	}{
+		{0, false},
+		{50 * time.Millisecond, false},
+		{2 * time.Second, true},
+		{-time.Millisecond, true},
+	} {
+		cfg := base
+		cfg.DedupWindow = tt.window
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with dedup_window %v error = %v, wantErr %v", tt.window, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateBackground(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		qps     float64
+		refresh time.Duration
+		wantErr bool
+	}{
+		{0, 0, false},
+		{0.5, 5 * time.Second, false},
+		{-1, 0, true},
+		{0, -time.Second, true},
+	} {
+		cfg := base
+		cfg.BackgroundQPS = tt.qps
+		cfg.BackgroundRefresh = tt.refresh
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with background_qps %v, background_refresh %v error = %v, wantErr %v", tt.qps, tt.refresh, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateHeartbeat(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		interval time.Duration
+		jitter   float64
+		wantErr  bool
+	}{
+		{0, 0, false},
+		{30 * time.Second, 0.25, false},
+		{time.Minute, 0.5, false},
+		{-time.Second, 0, true},
+		{0, -0.1, true},
+		{0, 0.6, true},
+	} {
+		cfg := base
+		cfg.HeartbeatInterval = tt.interval
+		cfg.HeartbeatJitter = tt.jitter
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with heartbeat_interval %v, heartbeat_jitter %v error = %v, wantErr %v", tt.interval, tt.jitter, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateConnectionThresholds(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		degraded, disconnected int
+		wantErr                bool
+	}{
+		{0, 0, false},
+		{2, 5, false},
+		{3, 3, false},
+		{5, 0, false},
+		{-1, 0, true},
+		{0, -1, true},
+		{4, 2, true},
+	} {
+		cfg := base
+		cfg.DegradedAfter = tt.degraded
+		cfg.DisconnectedAfter = tt.disconnected
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with degraded_after %d, disconnected_after %d error = %v, wantErr %v", tt.degraded, tt.disconnected, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateEndpoints(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		name    string
+		mutate  func(*SDKConfig)
+		wantErr bool
+	}{
+		{"single server", func(c *SDKConfig) {}, false},
+		{"standby", func(c *SDKConfig) { c.LCCURLs = []string{"http://standby:7086"} }, false},
+		{"weighted", func(c *SDKConfig) {
+			c.LCCURLs = []string{"https://b:7086", "https://c:7086"}
+			c.EndpointSelection = EndpointWeighted
+			c.EndpointWeights = []int{3, 1, 1}
+		}, false},
+		{"round robin", func(c *SDKConfig) {
+			c.LCCURLs = []string{"http://b:7086"}
+			c.EndpointSelection = EndpointRoundRobin
+			c.HealthCheckInterval = time.Second
+		}, false},
+		{"unknown selection", func(c *SDKConfig) { c.EndpointSelection = "random" }, true},
+		{"not a URL", func(c *SDKConfig) { c.LCCURLs = []string{"standby:7086"} }, true},
+		{"other path", func(c *SDKConfig) { c.LCCURLs = []string{"http://standby:7086/lcc"} }, true},
+		{"duplicate", func(c *SDKConfig) { c.LCCURLs = []string{"http://localhost:7086"} }, true},
+		{"too many weights", func(c *SDKConfig) { c.EndpointWeights = []int{1, 1} }, true},
+		{"zero weight", func(c *SDKConfig) {
+			c.LCCURLs = []string{"http://b:7086"}
+			c.EndpointWeights = []int{1, 0}
+		}, true},
+		{"negative health check interval", func(c *SDKConfig) { c.HealthCheckInterval = -time.Second }, true},
+		{"grpc", func(c *SDKConfig) {
+			c.Protocol = ProtocolGRPC
+			c.LCCURLs = []string{"http://b:7086"}
+		}, true},
+	} {
+		cfg := base
+		tt.mutate(&cfg)
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateDiscovery(t *testing.T) {
+	base := SDKConfig{ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		name      string
+		discovery *DiscoveryConfig
+		mutate    func(*SDKConfig)
+		wantErr   bool
+	}{
+		{"srv", &DiscoveryConfig{SRV: "_lcc._tcp.example.com", Scheme: "https", Path: "/lcc"}, nil, false},
+		{"kubernetes", &DiscoveryConfig{KubernetesService: "lcc.licensing", Port: "http", Refresh: time.Minute}, nil, false},
+		{"round robin", &DiscoveryConfig{KubernetesService: "lcc"}, func(c *SDKConfig) { c.EndpointSelection = EndpointRoundRobin }, false},
+		{"no lcc_url nor discovery", nil, nil, true},
+		{"nothing to discover", &DiscoveryConfig{}, nil, true},
+		{"both", &DiscoveryConfig{SRV: "_lcc._tcp.example.com", KubernetesService: "lcc"}, nil, true},
+		{"qualified service", &DiscoveryConfig{KubernetesService: "lcc.licensing.svc"}, nil, true},
+		{"scheme", &DiscoveryConfig{SRV: "_lcc._tcp.example.com", Scheme: "grpc"}, nil, true},
+		{"relative path", &DiscoveryConfig{SRV: "_lcc._tcp.example.com", Path: "lcc"}, nil, true},
+		{"negative refresh", &DiscoveryConfig{SRV: "_lcc._tcp.example.com", Refresh: -time.Second}, nil, true},
+		{"with lcc_url", &DiscoveryConfig{SRV: "_lcc._tcp.example.com"}, func(c *SDKConfig) { c.LCCURL = "http://localhost:7086" }, true},
+		{"with weights", &DiscoveryConfig{SRV: "_lcc._tcp.example.com"}, func(c *SDKConfig) { c.EndpointWeights = []int{1} }, true},
+		{"grpc", &DiscoveryConfig{SRV: "_lcc._tcp.example.com"}, func(c *SDKConfig) { c.Protocol = ProtocolGRPC }, true},
+	} {
+		cfg := base
+		cfg.Discovery = tt.discovery
+		if tt.mutate != nil {
+			tt.mutate(&cfg)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateLabels(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		labels  map[string]string
+		wantErr bool
+	}{
+		{nil, false},
+		{map[string]string{"team": "payments", "app.kubernetes.io/tier": "backend", "cost_center": ""}, false},
+		{map[string]string{"": "x"}, true},
+		{map[string]string{"team name": "payments"}, true},
+		{map[string]string{strings.Repeat("k", 64): "x"}, true},
+	} {
+		cfg := base
+		cfg.Region = "eu-west-1"
+		cfg.Labels = tt.labels
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with labels %v error = %v, wantErr %v", tt.labels, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateKeyAlgorithm(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		algorithm string
+		wantErr   bool
+	}{
+		{"", false},
+		{KeyAlgorithmRSA, false},
+		{KeyAlgorithmECDSAP256, false},
+		{"ecdsa-p384", true},
+	} {
+		cfg := base
+		cfg.KeyAlgorithm = tt.algorithm
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with key_algorithm %q error = %v, wantErr %v", tt.algorithm, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateSignatureScheme(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		scheme    string
+		algorithm string
+		wantErr   bool
+	}{
+		{"", "", false},
+		{SignatureSchemeRSAPKCS1v15, "", false},
+		{SignatureSchemeRSAPSS, "", false},
+		{SignatureSchemeRSAPSS, KeyAlgorithmRSA, false},
+		{SignatureSchemeRSAPSS, KeyAlgorithmECDSAP256, true},
+		{"rsa-pss-sha512", "", true},
+	} {
+		cfg := base
+		cfg.SignatureScheme = tt.scheme
+		cfg.KeyAlgorithm = tt.algorithm
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with signature_scheme %q, key_algorithm %q error = %v, wantErr %v", tt.scheme, tt.algorithm, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateHashAlgorithms(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		hashes     []string
+		instanceID string
+		wantErr    bool
+	}{
+		{nil, "", false},
+		{[]string{HashSHA384, HashSHA256}, "", false},
+		{[]string{HashSHA512}, HashSHA512, false},
+		{[]string{"md5"}, "", true},
+		{[]string{HashSHA256, HashSHA256}, "", true},
+		{nil, "sha1", true},
+	} {
+		cfg := base
+		cfg.HashAlgorithms = tt.hashes
+		cfg.InstanceIDHash = tt.instanceID
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with hash_algorithms %v, instance_id_hash %q error = %v, wantErr %v", tt.hashes, tt.instanceID, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateServerPins(t *testing.T) {
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, 32))
+	base := SDKConfig{LCCURL: "https://lcc.example.com", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(*SDKConfig)
+		wantErr bool
+	}{
+		{"pins", func(c *SDKConfig) { c.ServerPins = []string{pin, pin[len("sha256/"):]} }, false},
+		{"not base64", func(c *SDKConfig) { c.ServerPins = []string{"sha256/???"} }, true},
+		{"short digest", func(c *SDKConfig) { c.ServerPins = []string{"sha256/AAAA"} }, true},
+		{"plaintext", func(c *SDKConfig) {
+			c.ServerPins = []string{pin}
+			c.LCCURL = "http://lcc.example.com"
+		}, true},
+		{"plaintext standby", func(c *SDKConfig) {
+			c.ServerPins = []string{pin}
+			c.LCCURLs = []string{"http://standby.example.com"}
+		}, true},
+		{"plaintext discovery", func(c *SDKConfig) {
+			c.ServerPins = []string{pin}
+			c.LCCURL = ""
+			c.Discovery = &DiscoveryConfig{SRV: "_lcc._tcp.example.com"}
+		}, true},
+		{"https discovery", func(c *SDKConfig) {
+			c.ServerPins = []string{pin}
+			c.LCCURL = ""
+			c.Discovery = &DiscoveryConfig{SRV: "_lcc._tcp.example.com", Scheme: "https"}
+		}, false},
+	} {
+		cfg := base
+		tt.modify(&cfg)
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateProxy(t *testing.T) {
+	base := SDKConfig{LCCURL: "https://lcc.example.com", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(*SDKConfig)
+		wantErr bool
+	}{
+		{"proxy", func(c *SDKConfig) {
+			c.Proxy = &ProxyConfig{URL: "http://proxy.example.com:3128", NoProxy: []string{".internal"}, Username: "svc", Password: "secret"}
+		}, false},
+		{"direct", func(c *SDKConfig) { c.Proxy = &ProxyConfig{} }, false},
+		{"socks5", func(c *SDKConfig) { c.Proxy = &ProxyConfig{URL: "socks5://proxy.example.com:1080"} }, false},
+		{"bad scheme", func(c *SDKConfig) { c.Proxy = &ProxyConfig{URL: "ftp://proxy.example.com"} }, true},
+		{"no host", func(c *SDKConfig) { c.Proxy = &ProxyConfig{URL: "proxy.example.com:3128"} }, true},
+		{"password without username", func(c *SDKConfig) {
+			c.Proxy = &ProxyConfig{URL: "http://proxy.example.com:3128", Password: "secret"}
+		}, true},
+		{"grpc", func(c *SDKConfig) {
+			c.Proxy = &ProxyConfig{URL: "http://proxy.example.com:3128"}
+			c.Protocol = ProtocolGRPC
+		}, true},
+		{"egress disabled without lcc_url", func(c *SDKConfig) {
+			c.LCCURL = ""
+			c.DisableEgress = true
+		}, false},
+	} {
+		cfg := base
+		tt.modify(&cfg)
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateFingerprint(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		enabled bool
+		sources []string
+		wantErr bool
+	}{
+		{false, nil, false},
+		{true, nil, false},
+		{true, []string{"machine_id", "mac", "hostname", "cloud_instance_id", "container_id"}, false},
+		{true, []string{"serial"}, true},
+		{false, []string{"mac"}, true},
+	} {
+		cfg := base
+		cfg.Fingerprint = tt.enabled
+		cfg.FingerprintSources = tt.sources
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with fingerprint %v, sources %v error = %v, wantErr %v", tt.enabled, tt.sources, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateKeySources(t *testing.T) {
+	base := SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		name    string
+		file    string
+		env     string
+		keyring string
+		wantErr bool
+	}{
+		{"none", "", "", "", false},
+		{"file", "/var/lib/app/key.pem", "", "", false},
+		{"env", "", "LCC_PRIVATE_KEY", "", false},
+		{"keyring", "", "", "lcc-sdk", false},
+		{"file and env", "/var/lib/app/key.pem", "LCC_PRIVATE_KEY", "", true},
+		{"env and keyring", "", "LCC_PRIVATE_KEY", "lcc-sdk", true},
+	} {
+		cfg := base
+		cfg.KeyFile, cfg.KeyEnv, cfg.KeyringService = tt.file, tt.env, tt.keyring
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateProtocol(t *testing.T) {
+	base := SDKConfig{LCCURL: "lcc.internal:7443", ProductID: "app", ProductVersion: "1.0.0"}
+
+	for _, tt := range []struct {
+		protocol string
+		wantErr  bool
+	}{
+		{"", false},
+		{ProtocolHTTP, false},
+		{ProtocolGRPC, false},
+		{"websocket", true},
+	} {
+		cfg := base
+		cfg.Protocol = tt.protocol
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with protocol %q error = %v, wantErr %v", tt.protocol, err, tt.wantErr)
+		}
+	}
+
+	cfg := base
+	cfg.Protocol = ProtocolGRPC
+	cfg.Subscribe = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with subscribe over grpc should fail")
+	}
+
+	cfg = base
+	cfg.ServerPublicKeyFile = "/etc/my-app/lcc-server.pem"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with server_public_key_file error = %v", err)
+	}
+	cfg.Protocol = ProtocolGRPC
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with server_public_key_file over grpc should fail")
+	}
+
+	cfg = base
+	cfg.SignatureVersion = 2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with signature_version 2 error = %v", err)
+	}
+	cfg.SignatureVersion = 3
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with signature_version 3 should fail")
+	}
+
+	cfg = base
+	cfg.QuotaLease = 100
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with quota_lease error = %v", err)
+	}
+	cfg.Protocol = ProtocolGRPC
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with quota_lease over grpc should fail")
+	}
+	cfg = base
+	cfg.QuotaLease = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with negative quota_lease should fail")
+	}
+
+	cfg = base
+	cfg.CacheFile = "/var/cache/my-app/lcc.json"
+	cfg.CacheMaxStale = -time.Minute
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with negative cache_max_stale should fail")
+	}
+
+	for _, tt := range []struct {
+		policy  string
+		grace   time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{DowngradeImmediate, 0, false},
+		{DowngradeDrain, 0, false},
+		{DowngradeDrain, time.Minute, false},
+		{DowngradeGrace, time.Minute, false},
+		{DowngradeGrace, 0, true},
+		{DowngradeDrain, -time.Minute, true},
+		{"lenient", 0, true},
+	} {
+		cfg = base
+		cfg.DowngradePolicy = tt.policy
+		cfg.DowngradeGrace = tt.grace
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with downgrade_policy %q, grace %v error = %v, wantErr %v", tt.policy, tt.grace, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSDKConfig_ValidateOfflineMode(t *testing.T) {
+	cfg := SDKConfig{ProductID: "app", ProductVersion: "1.0.0"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() without lcc_url should fail when online")
+	}
+
+	cfg.OfflineMode = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() in offline mode error = %v, want nil (lcc_url not required)", err)
+	}
+}
+
+func TestLoadManifestFromBytes_BreakerCooldown(t *testing.T) {
+	data := []byte(`
+sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+  breaker_threshold: 5
+  breaker_cooldown: 2m
+features: []
+`)
+	m, err := LoadManifestFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if m.SDK.BreakerThreshold != 5 || m.SDK.BreakerCooldown != 2*time.Minute {
+		t.Errorf("breaker = %d, %v; want 5, 2m", m.SDK.BreakerThreshold, m.SDK.BreakerCooldown)
+	}
+
+	cfg := m.SDK
+	cfg.BreakerThreshold = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with negative breaker_threshold should fail")
+	}
+}
+
+func TestLoadManifestFromBytes_CacheMaxStale(t *testing.T) {
+	data := []byte(`
+sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+  cache_file: /var/cache/app/lcc.json
+  cache_max_stale: 1d
+features: []
+`)
+	m, err := LoadManifestFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if m.SDK.CacheMaxStale != 24*time.Hour {
+		t.Errorf("cache_max_stale = %v, want 24h", m.SDK.CacheMaxStale)
+	}
+}
+
+func TestLoadManifestFromBytes_DowngradeGrace(t *testing.T) {
+	data := []byte(`
+sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: app
+  product_version: "1.0.0"
+  downgrade_policy: grace
+  downgrade_grace: 2d
+features: []
+`)
+	m, err := LoadManifestFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	if m.SDK.DowngradeGrace != 48*time.Hour {
+		t.Errorf("downgrade_grace = %v, want 48h", m.SDK.DowngradeGrace)
+	}
+}
+
+func TestScheduleRule_Window(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	rule := &ScheduleRule{Name: "batch", Days: []string{"fri"}, Start: "22:00", End: "06:00", Timezone: "Europe/Berlin", MaxTPS: 500}
+
+	tests := []struct {
+		at      time.Time
+		active  bool
+		wantEnd time.Time
+	}{
+		{time.Date(2026, 10, 16, 21, 59, 0, 0, berlin), false, time.Time{}},                               // Friday, before
+		{time.Date(2026, 10, 16, 22, 0, 0, 0, berlin), true, time.Date(2026, 10, 17, 6, 0, 0, 0, berlin)}, // Friday night
+		{time.Date(2026, 10, 17, 5, 59, 0, 0, berlin), true, time.Date(2026, 10, 17, 6, 0, 0, 0, berlin)}, // past midnight
+		{time.Date(2026, 10, 17, 6, 0, 0, 0, berlin), false, time.Time{}},
+		{time.Date(2026, 10, 17, 23, 0, 0, 0, berlin), false, time.Time{}}, // Saturday night
+		{time.Date(2026, 10, 16, 20, 30, 0, 0, time.UTC), true, time.Date(2026, 10, 17, 6, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		_, end, active, err := rule.Window(tt.at)
+		if err != nil || active != tt.active || !end.Equal(tt.wantEnd) {
+			t.Errorf("Window(%v) = end %v, %v, %v; want %v, %v", tt.at, end, active, err, tt.wantEnd, tt.active)
+		}
+	}
+
+	if !rule.AppliesTo("anything", true) || rule.AppliesTo("export", false) {
+		t.Error("a rule without features should apply to the product limits only")
+	}
+}
+
+func TestLoadManifestFromBytes_Schedules(t *testing.T) {
+	m, err := LoadManifestFromBytes([]byte(`
+sdk:
+  lcc_url: "http://localhost:7086"
+  product_id: "app"
+  product_version: "1.0.0"
+  limits:
+    max_tps: 50
+    schedules:
+      - name: nightly-batch
+        days: [mon, tue]
+        start: "22:00"
+        end: "06:00"
+        max_tps: 500
+      - name: maintenance
+        start: "02:00"
+        end: "03:00"
+        features: [export]
+        monitor_only: true
+features:
+  - id: a
+    name: A
+    intercept: {package: p, function: F}
+`))
+	if err != nil {
+		t.Fatalf("LoadManifestFromBytes() error = %v", err)
+	}
+	got := m.SDK.Limits.Schedules
+	if len(got) != 2 || got[0].MaxTPS != 500 || got[0].Days[1] != "tue" || !got[1].MonitorOnly || got[1].Features[0] != "export" {
+		t.Errorf("Schedules = %+v", got)
+	}
+
+	bad := &ProductLimits{Schedules: []ScheduleRule{
+		{Name: "x", Days: []string{"someday"}, Start: "25:00", End: "6am", Timezone: "Mars/Olympus"},
+	}}
+	err = bad.Validate()
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 5 {
+		t.Errorf("Validate() error = %v, want 5 problems (day, start, end, timezone, no effect)", err)
+	}
+}
+
+func TestManifest_ValidateQuotaPools(t *testing.T) {
+	feature := func(id string) FeatureConfig {
+		return FeatureConfig{ID: id, Name: id, Intercept: InterceptConfig{Package: "p", Function: "F"}}
+	}
+	m := &Manifest{
+		SDK:      SDKConfig{LCCURL: "http://localhost:7086", ProductID: "app", ProductVersion: "1.0.0"},
+		Features: []FeatureConfig{feature("export_pdf"), feature("export_excel")},
+		QuotaPools: map[string]QuotaPool{
+			"exports": {Features: []string{"export_pdf", "export_excel"}, Quota: &ProductQuotaConfig{Max: 1000, Window: "30d"}},
+		},
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	m.QuotaPools["pdf"] = QuotaPool{Features: []string{"export_pdf", "nope"}, Quota: &ProductQuotaConfig{Window: "1h"}}
+	m.QuotaPools["empty"] = QuotaPool{}
+	var errs ValidationErrors
+	if err := m.Validate(); !errors.As(err, &errs) {
+		t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+	}
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"quota_pools.empty.features", "quota_pools.pdf.features[0]", "quota_pools.pdf.features[1]", "quota_pools.pdf.quota.max"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %q, want %q", fields, want)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// QuotaPool declares features that draw from one shared quota: usage of
+// any member counts against the pool
+type QuotaPool struct {
+	// Features are the member feature IDs. A feature belongs to at most one
+	// pool.
+	Features []string `yaml:"features"`
+
+	// Quota enforces the pool locally, with the window syntax of product
+	// quotas. Without it the LCC server enforces the pool and the client
+	// only tracks its usage.
+	Quota *ProductQuotaConfig `yaml:"quota,omitempty"`
+}
+
+// PoolNames returns the quota pool names in sorted order
+func (m *Manifest) PoolNames() []string {
+	names := make([]string, 0, len(m.QuotaPools))
+	for name := range m.QuotaPools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateQuotaPools checks that pools list known features, each in at
+// most one pool, and that pool quotas are valid
+func (m *Manifest) validateQuotaPools(featureIDs map[string]bool) ValidationErrors {
+	var errs ValidationErrors
+	member := make(map[string]string)
+	for _, name := range m.PoolNames() {
+		pool := m.QuotaPools[name]
+		prefix := "quota_pools." + name
+		if len(pool.Features) == 0 {
+			errs.add(prefix+".features", "must list at least one feature")
+		}
+		for i, id := range pool.Features {
+			field := fmt.Sprintf("%s.features[%d]", prefix, i)
+			switch {
+			case !featureIDs[id]:
+				errs.add(field, "unknown feature ID: "+id)
+			case member[id] != "":
+				errs.add(field, fmt.Sprintf("feature %s is already in pool %s", id, member[id]))
+			default:
+				member[id] = name
+			}
+		}
+		if pool.Quota != nil {
+			errs = append(errs, pool.Quota.validate(prefix+".quota")...)
+		}
+	}
+	return errs
+}
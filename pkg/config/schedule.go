@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleRule alters enforcement during a recurring daily window, e.g. a
+// licensed nightly batch window with a higher TPS limit, or a maintenance
+// window in which nothing is denied. Rules are evaluated locally against
+// the clock in their timezone.
+//
+// Example:
+//
+//	schedules:
+//	  - name: nightly-batch
+//	    days: [mon, tue, wed, thu, fri]
+//	    start: "22:00"
+//	    end: "06:00"
+//	    timezone: Europe/Berlin
+//	    max_tps: 500
+type ScheduleRule struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Days the window starts on ("mon" … "sun"); empty means every day
+	Days []string `yaml:"days,omitempty" json:"days,omitempty"`
+
+	// Start and End are wall-clock times ("HH:MM"). An End at or before
+	// Start ends the window on the next day.
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+
+	// Timezone is the IANA timezone of Start and End (default UTC)
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// Features the rule applies to. Empty applies it to the product limits.
+	Features []string `yaml:"features,omitempty" json:"features,omitempty"`
+
+	// Limits in effect during the window (0 keeps the licensed limit)
+	MaxTPS         float64 `yaml:"max_tps,omitempty" json:"max_tps,omitempty"`
+	MaxCapacity    int     `yaml:"max_capacity,omitempty" json:"max_capacity,omitempty"`
+	MaxConcurrency int     `yaml:"max_concurrency,omitempty" json:"max_concurrency,omitempty"`
+
+	// MonitorOnly reports denials during the window without enforcing them
+	MonitorOnly bool `yaml:"monitor_only,omitempty" json:"monitor_only,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Location returns the rule's timezone
+func (r *ScheduleRule) Location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(r.Timezone)
+}
+
+// AppliesTo reports whether the rule applies to featureID, or to the
+// product limits when product is true
+func (r *ScheduleRule) AppliesTo(featureID string, product bool) bool {
+	if len(r.Features) == 0 {
+		return product
+	}
+	for _, f := range r.Features {
+		if f == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// Window returns the occurrence of the rule's window containing t, and
+// false if t is outside every occurrence
+func (r *ScheduleRule) Window(t time.Time) (start, end time.Time, ok bool, err error) {
+	from, err := parseClock(r.Start)
+	if err != nil {
+		return start, end, false, err
+	}
+	to, err := parseClock(r.End)
+	if err != nil {
+		return start, end, false, err
+	}
+	loc, err := r.Location()
+	if err != nil {
+		return start, end, false, err
+	}
+	endDay := 0
+	if to <= from {
+		endDay = 1
+	}
+
+	t = t.In(loc)
+	y, m, d := t.Date()
+	// The occurrence containing t started today or, past midnight, yesterday
+	for _, back := range []int{0, 1} {
+		start = time.Date(y, m, d-back, 0, from, 0, 0, loc)
+		if !r.onDay(start.Weekday()) {
+			continue
+		}
+		end = time.Date(y, m, d-back+endDay, 0, to, 0, 0, loc)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true, nil
+		}
+	}
+	return time.Time{}, time.Time{}, false, nil
+}
+
+func (r *ScheduleRule) onDay(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, name := range r.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// validate collects the rule's problems under the field prefix
+func (r *ScheduleRule) validate(errs *ValidationErrors, prefix string) {
+	if r.Name == "" {
+		errs.add(prefix+".name", "required")
+	}
+	for _, day := range r.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			errs.add(prefix+".days", fmt.Sprintf("unknown day %q (want mon, tue, wed, thu, fri, sat or sun)", day))
+		}
+	}
+	if _, err := parseClock(r.Start); err != nil {
+		errs.add(prefix+".start", err.Error())
+	}
+	if _, err := parseClock(r.End); err != nil {
+		errs.add(prefix+".end", err.Error())
+	}
+	if _, err := r.Location(); err != nil {
+		errs.add(prefix+".timezone", err.Error())
+	}
+	if r.MaxTPS < 0 || r.MaxCapacity < 0 || r.MaxConcurrency < 0 {
+		errs.add(prefix, "limits must be non-negative")
+	}
+	if r.MaxTPS == 0 && r.MaxCapacity == 0 && r.MaxConcurrency == 0 && !r.MonitorOnly {
+		errs.add(prefix, "must set a limit or monitor_only")
+	}
+}
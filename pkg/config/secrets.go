@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret reference prefixes accepted in string values of the sdk section
+const (
+	SecretEnvPrefix  = "env:"
+	SecretFilePrefix = "file:"
+	SecretExecPrefix = "exec:"
+)
+
+// secretExecTimeout bounds how long an exec: reference may run
+const secretExecTimeout = 10 * time.Second
+
+// ResolveSecret resolves a secret reference:
+//
+//	env:VAR          value of environment variable VAR (must be set)
+//	file:/path       contents of the file, without trailing newline
+//	exec:cmd args    standard output of the command, without trailing newline
+//
+// Commands are run directly, not through a shell. Values without one of
+// these prefixes are returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretEnvPrefix):
+		name := strings.TrimPrefix(value, SecretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+
+	case strings.HasPrefix(value, SecretFilePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(value, SecretFilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(value, SecretExecPrefix):
+		args := strings.Fields(strings.TrimPrefix(value, SecretExecPrefix))
+		if len(args) == 0 {
+			return "", fmt.Errorf("empty secret command")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), secretExecTimeout)
+		defer cancel()
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("secret command %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+// resolveSecrets replaces secret references in every string scalar under
+// node. Errors carry the YAML line but never the resolved value.
+func resolveSecrets(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return nil
+		}
+		v, err := ResolveSecret(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = v
+
+	case yaml.MappingNode:
+		// Only values may be secrets, never keys
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := resolveSecrets(node.Content[i]); err != nil {
+				return err
+			}
+		}
+
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := resolveSecrets(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
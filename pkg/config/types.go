@@ -1,13 +1,78 @@
 package config
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Manifest represents the complete lcc-features.yaml configuration
 type Manifest struct {
 	SDK      SDKConfig       `yaml:"sdk"`
 	Features []FeatureConfig `yaml:"features"`
+
+	// Groups maps a group name (e.g. "reporting_suite") to the feature IDs
+	// it contains, so coarse-grained modules can be gated with one check
+	Groups map[string][]string `yaml:"groups,omitempty"`
+
+	// QuotaPools maps a pool name (e.g. "exports") to features sharing one
+	// quota, such as export_pdf and export_excel
+	QuotaPools map[string]QuotaPool `yaml:"quota_pools,omitempty"`
+}
+
+// Transports accepted in SDKConfig.Protocol
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Selection modes accepted in SDKConfig.EndpointSelection
+const (
+	EndpointFailover   = "failover"
+	EndpointRoundRobin = "round_robin"
+	EndpointWeighted   = "weighted"
+)
+
+// Transition policies accepted in SDKConfig.DowngradePolicy
+const (
+	DowngradeImmediate = "immediate"
+	DowngradeDrain     = "drain"
+	DowngradeGrace     = "grace"
+)
+
+// Key algorithms accepted in SDKConfig.KeyAlgorithm
+const (
+	KeyAlgorithmRSA       = "rsa"
+	KeyAlgorithmECDSAP256 = "ecdsa-p256"
+)
+
+// Signature schemes accepted in SDKConfig.SignatureScheme
+const (
+	SignatureSchemeRSAPKCS1v15 = "rsa-pkcs1v15"
+	SignatureSchemeRSAPSS      = "rsa-pss"
+)
+
+// Hash algorithms accepted in SDKConfig.HashAlgorithms and InstanceIDHash
+const (
+	HashSHA256 = "sha256"
+	HashSHA384 = "sha384"
+	HashSHA512 = "sha512"
+)
+
+// fingerprintSources are the component names accepted in
+// SDKConfig.FingerprintSources
+var fingerprintSources = map[string]bool{
+	"machine_id": true, "mac": true, "hostname": true, "cloud_instance_id": true, "container_id": true,
 }
 
+// labelKeyPattern matches the keys accepted in SDKConfig.Labels
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]{1,63}$`)
+
 // SDKConfig contains global SDK configuration
 type SDKConfig struct {
 	LCCURL         string        `yaml:"lcc_url"`
@@ -19,11 +84,280 @@ type SDKConfig struct {
 	Timeout        time.Duration `yaml:"timeout"`
 	MaxRetries     int           `yaml:"max_retries"`
 
+	// NoCache disables the feature cache, so every check asks the server
+	// (e.g. in tests). A zero CacheTTL means the default, not no caching.
+	NoCache        bool          `yaml:"no_cache,omitempty"`
+
+	// GatewayPrefix is a path prefix added by a reverse proxy in front of LCC
+	// (e.g., "/lcc" when lcc_url is "https://gw.example.com/lcc"). It is
+	// excluded from request signatures so they verify after the proxy strips it.
+	GatewayPrefix  string        `yaml:"gateway_prefix,omitempty"`
+
+	// CertificateFile is a PEM certificate chain (leaf first) for the client
+	// key, sent with signed requests to servers that trust keys by CA
+	CertificateFile string       `yaml:"certificate_file,omitempty"`
+
+	// ServerPublicKeyFile pins the LCC server's public key (PEM). Feature
+	// check, product status, quota lease and usage responses must then carry
+	// a valid X-LCC-Server-Signature, so a spoofed or intercepting server
+	// cannot grant features.
+	ServerPublicKeyFile string   `yaml:"server_public_key_file,omitempty"`
+
+	// ServerPins pins the public keys the LCC server's TLS certificate chain
+	// may carry, as "sha256/" and the base64 SHA-256 of the
+	// SubjectPublicKeyInfo. Connections to a server presenting none of them
+	// fail even if a trusted CA issued its certificate. List the current
+	// and the next key to rotate without downtime. Requires https.
+	ServerPins []string `yaml:"server_pins,omitempty"`
+
+	// KeyAlgorithm selects the instance key generated by NewClient and
+	// NewClientWithStore: "rsa" (default, RSA-2048) or "ecdsa-p256" for
+	// deployments that mandate ECDSA. A key already saved in a Store is
+	// used whatever its algorithm.
+	KeyAlgorithm   string        `yaml:"key_algorithm,omitempty"`
+
+	// KeyFile is where NewClient keeps the instance private key (PEM,
+	// 0600), so the instance ID survives restarts. The key is generated
+	// and saved on first use. Empty uses a fresh key on every start.
+	KeyFile        string        `yaml:"key_file,omitempty"`
+
+	// KeyEnv names an environment variable holding the instance private
+	// key, as PEM or base64-encoded PEM, provisioned beforehand (e.g. from
+	// a secret manager). The key is never written to disk.
+	KeyEnv         string        `yaml:"key_env,omitempty"`
+
+	// KeyringService keeps the instance private key in the OS keyring
+	// (macOS Keychain, Linux Secret Service, Windows DPAPI) under this
+	// service name, with the product ID as account
+	KeyringService string        `yaml:"keyring_service,omitempty"`
+
+	// ClockSync corrects the clock used to timestamp signed requests from
+	// the Date header of server responses, for hosts whose clock is too
+	// skewed for the server to accept their signatures
+	ClockSync      bool          `yaml:"clock_sync,omitempty"`
+
+	// SignatureVersion selects the request signing scheme: 1 (default) or
+	// 2, which also covers the query string and the Content-Type header
+	SignatureVersion int         `yaml:"signature_version,omitempty"`
+
+	// SignatureScheme selects how RSA instance keys sign requests:
+	// "rsa-pkcs1v15" (default) or "rsa-pss"
+	SignatureScheme string       `yaml:"signature_scheme,omitempty"`
+
+	// InstanceIDSalt derives the instance ID as the SHA-256 of the salt and
+	// the public key instead of the plain key fingerprint, e.g. a
+	// per-customer salt so instance IDs cannot be correlated across customers
+	InstanceIDSalt string        `yaml:"instance_id_salt,omitempty"`
+
+	// HashAlgorithms are the hashes the client may use for body hashes and
+	// request signatures, preferred first: sha256, sha384 or sha512. The
+	// client starts with the first and switches to the first one a server
+	// lists in its X-LCC-Accept-Hash header. By default it signs with
+	// sha256 until a server no longer accepts it.
+	HashAlgorithms []string      `yaml:"hash_algorithms,omitempty"`
+
+	// InstanceIDHash selects the hash deriving the instance ID from the
+	// public key (default sha256). Changing it changes the instance ID.
+	InstanceIDHash string        `yaml:"instance_id_hash,omitempty"`
+
+	// LocalEval enforces product-level limits (currently quota windows) locally
+	// from Limits instead of asking the LCC server on every call
+	LocalEval      bool          `yaml:"local_eval,omitempty"`
+
+	// ReportBuildInfo sends the binary's Go version, module version, VCS
+	// revision and platform with the registration metadata
+	ReportBuildInfo bool         `yaml:"report_build_info,omitempty"`
+
+	// Fingerprint sends a fingerprint of the host (see package fingerprint)
+	// at registration, for node-locked or per-host licenses.
+	// FingerprintSources selects its components (default machine_id, mac
+	// and cloud_instance_id).
+	Fingerprint        bool     `yaml:"fingerprint,omitempty"`
+	FingerprintSources []string `yaml:"fingerprint_sources,omitempty"`
+
+	// SDKUpdateCheck reports the SDK version with heartbeats so the server
+	// can answer with a minimum-supported-version advisory
+	SDKUpdateCheck bool          `yaml:"sdk_update_check,omitempty"`
+
+	// TPSPartitioning splits a fleet-wide MaxTPS across instances: the server
+	// assigns each instance a share via heartbeat, enforced by CheckTPS
+	TPSPartitioning bool         `yaml:"tps_partitioning,omitempty"`
+
+	// Environment labels this deployment (e.g., "prod", "staging") at
+	// registration, so licenses scoped to an environment are not consumed
+	// by other clusters
+	Environment    string        `yaml:"environment,omitempty"`
+
+	// ClusterID registers every replica of a deployment under one logical
+	// group, so limits can apply per cluster and one instance is elected
+	// to report cluster-level metrics
+	ClusterID      string        `yaml:"cluster_id,omitempty"`
+
+	// Region and Labels are reported with the instance metadata at
+	// registration and with heartbeats, so the LCC console can group
+	// instances. Region defaults to the cloud provider's region variable
+	// (AWS_REGION, ...); label keys are 1-63 letters, digits, '-', '_',
+	// '.' or '/'.
+	Region string            `yaml:"region,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// DedupWindow answers identical feature checks repeated within this
+	// window (tens of milliseconds) from a micro-cache independent of
+	// CacheTTL, and coalesces concurrent ones (0 = disabled, max 1s)
+	DedupWindow    time.Duration `yaml:"dedup_window,omitempty"`
+
+	// HeartbeatInterval is the time between heartbeats (default 5s), each
+	// spread by up to ±HeartbeatJitter of it (0 to 0.5, default 0.1)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval,omitempty"`
+	HeartbeatJitter   float64       `yaml:"heartbeat_jitter,omitempty"`
+
+	// DegradedAfter and DisconnectedAfter are the numbers of consecutive
+	// failed heartbeats that make the connection degraded (default 1) and
+	// disconnected (default 3)
+	DegradedAfter     int `yaml:"degraded_after,omitempty"`
+	DisconnectedAfter int `yaml:"disconnected_after,omitempty"`
+
+	// LCCURLs lists further LCC servers sharing lcc_url's licenses, e.g.
+	// the standby of an HA pair. They must have lcc_url's path. Calls fail
+	// over to the next healthy server on connection errors and 5xx
+	// responses (HTTP protocol only).
+	LCCURLs []string `yaml:"lcc_urls,omitempty"`
+
+	// EndpointSelection picks the server for each call with lcc_urls set:
+	// "failover" (default; lcc_url while healthy), "round_robin" or
+	// "weighted". EndpointWeights are the weights of lcc_url then lcc_urls
+	// (default 1).
+	EndpointSelection string `yaml:"endpoint_selection,omitempty"`
+	EndpointWeights   []int  `yaml:"endpoint_weights,omitempty"`
+
+	// HealthCheckInterval is how often each server is probed with lcc_urls
+	// set, so failed servers rejoin once they answer (default 10s)
+	HealthCheckInterval time.Duration `yaml:"health_check_interval,omitempty"`
+
+	// Discovery finds the LCC servers from DNS SRV records or a Kubernetes
+	// Service instead of lcc_url, refreshing them periodically
+	Discovery *DiscoveryConfig `yaml:"discovery,omitempty"`
+
+	// Proxy routes LCC server calls through this proxy instead of the one
+	// named by HTTPS_PROXY, HTTP_PROXY and NO_PROXY (HTTP protocol only)
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
+
+	// BackgroundQPS bounds the rate of requests sent by background work
+	// (heartbeats, usage redelivery, cache refreshes), 0 = unbounded
+	BackgroundQPS float64 `yaml:"background_qps,omitempty"`
+
+	// BackgroundRefresh refreshes cached feature statuses before they
+	// expire, every BackgroundRefresh (0 = disabled; keep it below CacheTTL)
+	BackgroundRefresh time.Duration `yaml:"background_refresh,omitempty"`
+
+	// OfflineMode answers every feature and limit check from a signed
+	// license file instead of the LCC server (air-gapped deployments).
+	// lcc_url is not required; see Client.LoadLicense.
+	OfflineMode    bool          `yaml:"offline_mode,omitempty"`
+
+	// LicenseFile is the signed license file loaded in offline mode
+	LicenseFile    string        `yaml:"license_file,omitempty"`
+
+	// DisableEgress forbids all network calls: calls to the LCC server fail
+	// at once without connecting, so checks are decided locally from the
+	// license, the cache or fail_open. lcc_url is not required; use it with
+	// offline_mode.
+	DisableEgress  bool          `yaml:"disable_egress,omitempty"`
+
+	// BreakerThreshold opens the circuit breaker around LCC server calls
+	// after this many consecutive failures (0 = disabled). While open, calls
+	// fail fast and checks use cached or fail-open results.
+	BreakerThreshold int         `yaml:"breaker_threshold,omitempty"`
+
+	// BreakerCooldown is how long the breaker stays open before a probe
+	// call is let through (default 30s)
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown,omitempty"`
+
+	// UsageJournal enables the usage ledger with this journal file, so
+	// usage reports survive crashes and are never counted twice
+	UsageJournal   string        `yaml:"usage_journal,omitempty"`
+
+	// Protocol selects the transport to the LCC server: "http" (default) or
+	// "grpc". With grpc, lcc_url is the gRPC target: "host:port" and
+	// "https://host:port" use TLS, "http://host:port" is plaintext (e.g. to
+	// a mesh sidecar).
+	Protocol       string        `yaml:"protocol,omitempty"`
+
+	// Subscribe keeps a server-sent events stream open to LCC after
+	// registration, so license changes update cached feature statuses
+	// immediately instead of after CacheTTL (HTTP protocol only)
+	Subscribe      bool          `yaml:"subscribe,omitempty"`
+
+	// QuotaLease reserves product quota in blocks of this many units that
+	// Consume draws down locally, reconciling usage with each heartbeat
+	// (0 = check and report every call; HTTP protocol only)
+	QuotaLease     int           `yaml:"quota_lease,omitempty"`
+
+	// CacheFile persists feature check results and quota exhaustion to this
+	// file, so a restarted instance can keep operating during a short LCC
+	// outage. The file is authenticated with an HMAC keyed by CacheKey, or by
+	// the client private key when CacheKey is empty.
+	CacheFile      string        `yaml:"cache_file,omitempty"`
+	CacheKey       string        `yaml:"cache_key,omitempty"`
+
+	// CacheMaxStale is how long past expiry a cached status is used when
+	// the LCC server cannot be reached, with cache_file set (default 15m)
+	CacheMaxStale  time.Duration `yaml:"cache_max_stale,omitempty"`
+
+	// DowngradePolicy sets how a license downgrade seen on refresh takes
+	// effect: "immediate" (default), "drain" (after the concurrency slots
+	// held at the downgrade are released) or "grace" (after DowngradeGrace)
+	DowngradePolicy string        `yaml:"downgrade_policy,omitempty"`
+
+	// DowngradeGrace is the grace window, or with drain the longest a drain
+	// may take (0 = no bound)
+	DowngradeGrace  time.Duration `yaml:"downgrade_grace,omitempty"`
+
 	// Product-level limits (Zero-Intrusion API)
 	// These limits apply to the entire product, not individual features
 	Limits *ProductLimits `yaml:"limits,omitempty"`
 }
 
+// DiscoveryConfig selects how the LCC servers are discovered. Exactly one
+// of SRV and KubernetesService is set.
+type DiscoveryConfig struct {
+	// SRV is a DNS SRV record, e.g. "_lcc._tcp.licensing.example.com"
+	SRV string `yaml:"srv,omitempty"`
+
+	// KubernetesService is a Service, "name" or "name.namespace", whose
+	// ready endpoints are the LCC servers (in-cluster only). Port names
+	// the Service port (default: the first).
+	KubernetesService string `yaml:"kubernetes_service,omitempty"`
+	Port              string `yaml:"port,omitempty"`
+
+	// Scheme is "http" (default) or "https"; Path is the LCC path prefix
+	// on each server, if any
+	Scheme string `yaml:"scheme,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+
+	// Refresh is how often the servers are looked up again (default 30s)
+	Refresh time.Duration `yaml:"refresh,omitempty"`
+}
+
+// ProxyConfig routes LCC server calls through an HTTP, HTTPS or SOCKS5
+// proxy
+type ProxyConfig struct {
+	// URL is the proxy, e.g. "http://proxy.example.com:3128". Empty
+	// connects directly, ignoring the environment.
+	URL string `yaml:"url,omitempty"`
+
+	// NoProxy lists the servers reached directly, as in NO_PROXY: host
+	// names (matching their subdomains), ".domain" suffixes, IP addresses,
+	// CIDR ranges, each with an optional ":port", or "*"
+	NoProxy []string `yaml:"no_proxy,omitempty"`
+
+	// Username and Password authenticate to the proxy (basic auth),
+	// replacing credentials in URL. In a manifest, the password can be a
+	// secret reference such as "env:PROXY_PASSWORD" (see ResolveSecret).
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
 // FeatureConfig defines a single protected feature
 // This structure maps feature IDs to functions (technical mapping)
 // Authorization control (enabled/disabled, quotas) is defined in the License file
@@ -48,6 +382,9 @@ type FeatureConfig struct {
 	
 	Condition   *ConditionConfig `yaml:"condition,omitempty"`
 	OnDeny      *OnDenyConfig   `yaml:"on_deny,omitempty"`
+
+	// Policy overrides global enforcement settings for this feature only
+	Policy      *FeaturePolicy  `yaml:"policy,omitempty"`
 	
 	// Metadata fields for documentation and organization (not used in authorization)
 	Category    string          `yaml:"category,omitempty"`
@@ -81,49 +418,89 @@ type OnDenyConfig struct {
 	Code    string `yaml:"error_code,omitempty"`
 }
 
-// Validate performs validation on the manifest
+// FeaturePolicy overrides global enforcement settings for a single feature.
+// Unset fields inherit the corresponding SDKConfig value.
+type FeaturePolicy struct {
+	// FailOpen allows the feature when the LCC server cannot be reached.
+	// nil inherits SDKConfig.FailOpen.
+	FailOpen *bool `yaml:"fail_open,omitempty"`
+
+	// CacheTTL overrides SDKConfig.CacheTTL for this feature (0 = inherit)
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+
+	// MonitorOnly evaluates the license but never denies; denials are
+	// reported with a "monitor_only:" reason prefix instead.
+	MonitorOnly bool `yaml:"monitor_only,omitempty"`
+
+	// EnforcePercent stages enforcement across the installed base: only this
+	// percentage of instances, chosen by a hash of the instance ID, enforce
+	// the feature; the others run it monitor-only. Raising it keeps the
+	// instances already enforcing. nil enforces on every instance.
+	EnforcePercent *int `yaml:"enforce_percent,omitempty"`
+}
+
+// Validate validates feature policy configuration
+func (p *FeaturePolicy) Validate() error {
+	if p.CacheTTL < 0 {
+		return &ValidationError{Field: "cache_ttl", Message: "must be non-negative"}
+	}
+	if p.EnforcePercent != nil && (*p.EnforcePercent < 0 || *p.EnforcePercent > 100) {
+		return &ValidationError{Field: "enforce_percent", Message: "must be between 0 and 100"}
+	}
+	return nil
+}
+
+// Validate performs validation on the manifest and reports every problem
+// found as ValidationErrors. It does not apply defaults.
 func (m *Manifest) Validate() error {
+	var errs ValidationErrors
+
 	// Validate SDK config
-	if err := m.SDK.Validate(); err != nil {
-		return err
-	}
+	errs.merge("", m.SDK.Validate())
 
 	// Validate features
 	featureIDs := make(map[string]bool)
 	for i, feature := range m.Features {
-		if err := feature.Validate(); err != nil {
-			return &ValidationError{
-				Field:   "features[" + string(rune(i)) + "]",
-				Message: err.Error(),
-			}
-		}
+		prefix := fmt.Sprintf("features[%d]", i)
+		errs.merge(prefix, feature.Validate())
 
 		// Check for duplicate feature IDs
-		if featureIDs[feature.ID] {
-			return &ValidationError{
-				Field:   "features[" + string(rune(i)) + "].id",
-				Message: "duplicate feature ID: " + feature.ID,
-			}
+		if feature.ID != "" && featureIDs[feature.ID] {
+			errs.add(prefix+".id", "duplicate feature ID: "+feature.ID)
 		}
 		featureIDs[feature.ID] = true
 	}
 
-	return nil
+	// Validate groups reference known features
+	for _, name := range sortedKeys(m.Groups) {
+		prefix := "groups." + name
+		if len(m.Groups[name]) == 0 {
+			errs.add(prefix, "must list at least one feature")
+		}
+		for i, id := range m.Groups[name] {
+			if !featureIDs[id] {
+				errs.add(fmt.Sprintf("%s[%d]", prefix, i), "unknown feature ID: "+id)
+			}
+		}
+	}
+	errs = append(errs, m.validateQuotaPools(featureIDs)...)
+
+	return errs.err()
 }
 
-// Validate validates SDK configuration
-func (c *SDKConfig) Validate() error {
-	if c.LCCURL == "" {
-		return &ValidationError{Field: "sdk.lcc_url", Message: "required"}
-	}
-	if c.ProductID == "" {
-		return &ValidationError{Field: "sdk.product_id", Message: "required"}
-	}
-	if c.ProductVersion == "" {
-		return &ValidationError{Field: "sdk.product_version", Message: "required"}
+// ApplyDefaults fills in zero-valued settings with their defaults
+func (c *SDKConfig) ApplyDefaults() {
+	c.ApplyDurationDefaults()
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 3
 	}
+}
 
-	// Set defaults
+// ApplyDurationDefaults fills in the zero-valued intervals, which are never
+// usable as is: a zero Timeout never times out and a zero CacheTTL expires
+// every entry at once. Unlike ApplyDefaults it keeps a zero MaxRetries,
+// which NewClient takes to disable retries.
+func (c *SDKConfig) ApplyDurationDefaults() {
 	if c.CheckInterval == 0 {
 		c.CheckInterval = 30 * time.Second
 	}
@@ -133,18 +510,287 @@ func (c *SDKConfig) Validate() error {
 	if c.Timeout == 0 {
 		c.Timeout = 5 * time.Second
 	}
-	if c.MaxRetries == 0 {
-		c.MaxRetries = 3
+}
+
+// Validate validates SDK configuration and reports every problem found as
+// ValidationErrors. It does not modify c; call ApplyDefaults first to fill
+// in unset values.
+func (c *SDKConfig) Validate() error {
+	var errs ValidationErrors
+
+	if c.LCCURL == "" && !c.OfflineMode && !c.DisableEgress && c.Discovery == nil {
+		errs.add("sdk.lcc_url", "required")
+	}
+	if c.ProductID == "" {
+		errs.add("sdk.product_id", "required")
+	}
+	if c.ProductVersion == "" {
+		errs.add("sdk.product_version", "required")
+	}
+	if c.CheckInterval < 0 {
+		errs.add("sdk.check_interval", "must be non-negative")
+	}
+	if c.CacheTTL < 0 {
+		errs.add("sdk.cache_ttl", "must be non-negative")
+	}
+	if c.Timeout < 0 {
+		errs.add("sdk.timeout", "must be non-negative")
+	}
+	if c.MaxRetries < 0 {
+		errs.add("sdk.max_retries", "must be non-negative")
+	}
+	if c.BreakerThreshold < 0 {
+		errs.add("sdk.breaker_threshold", "must be non-negative")
+	}
+	if c.BreakerCooldown < 0 {
+		errs.add("sdk.breaker_cooldown", "must be non-negative")
+	}
+	switch c.KeyAlgorithm {
+	case "", KeyAlgorithmRSA, KeyAlgorithmECDSAP256:
+	default:
+		errs.add("sdk.key_algorithm", fmt.Sprintf("must be %q or %q", KeyAlgorithmRSA, KeyAlgorithmECDSAP256))
+	}
+	switch c.SignatureScheme {
+	case "", SignatureSchemeRSAPKCS1v15:
+	case SignatureSchemeRSAPSS:
+		if c.KeyAlgorithm == KeyAlgorithmECDSAP256 {
+			errs.add("sdk.signature_scheme", "rsa-pss requires key_algorithm rsa")
+		}
+	default:
+		errs.add("sdk.signature_scheme", fmt.Sprintf("must be %q or %q", SignatureSchemeRSAPKCS1v15, SignatureSchemeRSAPSS))
+	}
+	seenHashes := make(map[string]bool)
+	for _, h := range c.HashAlgorithms {
+		switch {
+		case h != HashSHA256 && h != HashSHA384 && h != HashSHA512:
+			errs.add("sdk.hash_algorithms", fmt.Sprintf("unknown algorithm %q, must be %q, %q or %q", h, HashSHA256, HashSHA384, HashSHA512))
+		case seenHashes[h]:
+			errs.add("sdk.hash_algorithms", fmt.Sprintf("duplicate algorithm %q", h))
+		}
+		seenHashes[h] = true
+	}
+	switch c.InstanceIDHash {
+	case "", HashSHA256, HashSHA384, HashSHA512:
+	default:
+		errs.add("sdk.instance_id_hash", fmt.Sprintf("must be %q, %q or %q", HashSHA256, HashSHA384, HashSHA512))
+	}
+	for _, source := range c.FingerprintSources {
+		if !fingerprintSources[source] {
+			errs.add("sdk.fingerprint_sources", fmt.Sprintf("unknown source %q", source))
+		}
+	}
+	if len(c.FingerprintSources) > 0 && !c.Fingerprint {
+		errs.add("sdk.fingerprint_sources", "requires fingerprint")
+	}
+	var badLabels []string
+	for key := range c.Labels {
+		if !labelKeyPattern.MatchString(key) {
+			badLabels = append(badLabels, key)
+		}
+	}
+	sort.Strings(badLabels)
+	for _, key := range badLabels {
+		errs.add("sdk.labels", fmt.Sprintf("invalid label key %q", key))
+	}
+	keySources := 0
+	for _, source := range []string{c.KeyFile, c.KeyEnv, c.KeyringService} {
+		if source != "" {
+			keySources++
+		}
+	}
+	if keySources > 1 {
+		errs.add("sdk.key_file", "only one of key_file, key_env and keyring_service may be set")
+	}
+	switch c.Protocol {
+	case "", ProtocolHTTP, ProtocolGRPC:
+	default:
+		errs.add("sdk.protocol", fmt.Sprintf("must be %q or %q", ProtocolHTTP, ProtocolGRPC))
+	}
+	if c.Subscribe && c.Protocol == ProtocolGRPC {
+		errs.add("sdk.subscribe", "not supported with protocol grpc")
+	}
+	c.validateServerPins(&errs)
+	if c.Proxy != nil {
+		c.validateProxy(&errs)
+	}
+	if c.ServerPublicKeyFile != "" && c.Protocol == ProtocolGRPC {
+		errs.add("sdk.server_public_key_file", "not supported with protocol grpc")
+	}
+	if c.SignatureVersion < 0 || c.SignatureVersion > 2 {
+		errs.add("sdk.signature_version", "must be 1 or 2")
+	}
+	switch c.DowngradePolicy {
+	case "", DowngradeImmediate, DowngradeDrain:
+	case DowngradeGrace:
+		if c.DowngradeGrace == 0 {
+			errs.add("sdk.downgrade_grace", "must be positive with downgrade_policy grace")
+		}
+	default:
+		errs.add("sdk.downgrade_policy", fmt.Sprintf("must be %q, %q or %q", DowngradeImmediate, DowngradeDrain, DowngradeGrace))
+	}
+	if c.DowngradeGrace < 0 {
+		errs.add("sdk.downgrade_grace", "must be non-negative")
+	}
+	if c.CacheMaxStale < 0 {
+		errs.add("sdk.cache_max_stale", "must be non-negative")
+	}
+	if c.QuotaLease < 0 {
+		errs.add("sdk.quota_lease", "must be non-negative")
+	} else if c.QuotaLease > 0 && c.Protocol == ProtocolGRPC {
+		errs.add("sdk.quota_lease", "not supported with protocol grpc")
+	}
+	if c.HeartbeatInterval < 0 {
+		errs.add("sdk.heartbeat_interval", "must be non-negative")
+	}
+	if c.HeartbeatJitter < 0 || c.HeartbeatJitter > 0.5 {
+		errs.add("sdk.heartbeat_jitter", "must be between 0 and 0.5")
+	}
+	if c.DegradedAfter < 0 {
+		errs.add("sdk.degraded_after", "must be non-negative")
+	}
+	if c.DisconnectedAfter < 0 {
+		errs.add("sdk.disconnected_after", "must be non-negative")
+	} else if c.DisconnectedAfter > 0 && c.DisconnectedAfter < c.DegradedAfter {
+		errs.add("sdk.disconnected_after", "must be at least degraded_after")
+	}
+	c.validateEndpoints(&errs)
+	if c.BackgroundQPS < 0 {
+		errs.add("sdk.background_qps", "must be non-negative")
+	}
+	if c.BackgroundRefresh < 0 {
+		errs.add("sdk.background_refresh", "must be non-negative")
+	}
+	if c.DedupWindow < 0 || c.DedupWindow > time.Second {
+		errs.add("sdk.dedup_window", "must be between 0 and 1s (use cache_ttl for longer caching)")
 	}
 
 	// Validate product limits if present
-	if c.Limits != nil {
-		if err := c.Limits.Validate(); err != nil {
-			return err
+	errs.merge("sdk", c.Limits.validate())
+
+	return errs.err()
+}
+
+// validateEndpoints checks lcc_urls and the endpoint selection settings
+func (c *SDKConfig) validateEndpoints(errs *ValidationErrors) {
+	switch c.EndpointSelection {
+	case "", EndpointFailover, EndpointRoundRobin, EndpointWeighted:
+	default:
+		errs.add("sdk.endpoint_selection", fmt.Sprintf("must be %q, %q or %q", EndpointFailover, EndpointRoundRobin, EndpointWeighted))
+	}
+	if c.HealthCheckInterval < 0 {
+		errs.add("sdk.health_check_interval", "must be non-negative")
+	}
+	if len(c.EndpointWeights) > len(c.LCCURLs)+1 {
+		errs.add("sdk.endpoint_weights", "more weights than lcc_url and lcc_urls")
+	}
+	for i, w := range c.EndpointWeights {
+		if w < 1 {
+			errs.add(fmt.Sprintf("sdk.endpoint_weights[%d]", i), "must be positive")
+		}
+	}
+	if c.Discovery != nil {
+		c.validateDiscovery(errs)
+	}
+	if len(c.LCCURLs) == 0 {
+		return
+	}
+	if c.Protocol == ProtocolGRPC {
+		errs.add("sdk.lcc_urls", "not supported with protocol grpc")
+		return
+	}
+	primary, err := url.Parse(c.LCCURL)
+	if err != nil {
+		return
+	}
+	seen := map[string]bool{primary.Host: true}
+	for i, raw := range c.LCCURLs {
+		field := fmt.Sprintf("sdk.lcc_urls[%d]", i)
+		u, err := url.Parse(raw)
+		switch {
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			errs.add(field, "must be an http or https URL")
+		case u.Path != primary.Path:
+			errs.add(field, fmt.Sprintf("must have the path of lcc_url (%q)", primary.Path))
+		case seen[u.Host]:
+			errs.add(field, "duplicate server")
+		default:
+			seen[u.Host] = true
 		}
 	}
+}
 
-	return nil
+// validateServerPins checks the pin encoding and that every server is
+// reached over https
+func (c *SDKConfig) validateServerPins(errs *ValidationErrors) {
+	if len(c.ServerPins) == 0 {
+		return
+	}
+	for _, pin := range c.ServerPins {
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(pin), "sha256/"))
+		if err != nil || len(digest) != sha256.Size {
+			errs.add("sdk.server_pins", fmt.Sprintf("invalid pin %q: want the base64 SHA-256 of a public key", pin))
+		}
+	}
+	for _, u := range append([]string{c.LCCURL}, c.LCCURLs...) {
+		if strings.HasPrefix(u, "http://") {
+			errs.add("sdk.server_pins", fmt.Sprintf("requires https, %s is plaintext", u))
+		}
+	}
+	if c.Discovery != nil && c.Discovery.Scheme != "https" {
+		errs.add("sdk.server_pins", "requires discovery scheme https")
+	}
+}
+
+// validateProxy checks the proxy settings
+func (c *SDKConfig) validateProxy(errs *ValidationErrors) {
+	p := c.Proxy
+	if p.URL != "" {
+		u, err := url.Parse(p.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+			errs.add("sdk.proxy.url", "must be an http, https or socks5 URL")
+		}
+	}
+	if p.Username == "" && p.Password != "" {
+		errs.add("sdk.proxy.username", "required with password")
+	}
+	if c.Protocol == ProtocolGRPC {
+		errs.add("sdk.proxy", "not supported with protocol grpc")
+	}
+}
+
+// validateDiscovery checks the discovery settings, which replace lcc_url
+// and lcc_urls
+func (c *SDKConfig) validateDiscovery(errs *ValidationErrors) {
+	d := c.Discovery
+	switch {
+	case d.SRV == "" && d.KubernetesService == "":
+		errs.add("sdk.discovery", "srv or kubernetes_service required")
+	case d.SRV != "" && d.KubernetesService != "":
+		errs.add("sdk.discovery", "only one of srv and kubernetes_service may be set")
+	}
+	if d.KubernetesService != "" && strings.Count(d.KubernetesService, ".") > 1 {
+		errs.add("sdk.discovery.kubernetes_service", "must be name or name.namespace")
+	}
+	switch d.Scheme {
+	case "", "http", "https":
+	default:
+		errs.add("sdk.discovery.scheme", `must be "http" or "https"`)
+	}
+	if d.Path != "" && !strings.HasPrefix(d.Path, "/") {
+		errs.add("sdk.discovery.path", "must start with /")
+	}
+	if d.Refresh < 0 {
+		errs.add("sdk.discovery.refresh", "must be non-negative")
+	}
+	if c.LCCURL != "" || len(c.LCCURLs) > 0 {
+		errs.add("sdk.discovery", "lcc_url and lcc_urls must not be set with discovery")
+	}
+	if len(c.EndpointWeights) > 0 {
+		errs.add("sdk.endpoint_weights", "not supported with discovery")
+	}
+	if c.Protocol == ProtocolGRPC {
+		errs.add("sdk.discovery", "not supported with protocol grpc")
+	}
 }
 
 // Validate validates feature configuration
@@ -182,6 +828,13 @@ func (f *FeatureConfig) Validate() error {
 		}
 	}
 
+	// Validate policy if present
+	if f.Policy != nil {
+		if err := f.Policy.Validate(); err != nil {
+			return &ValidationError{Field: "policy", Message: err.Error()}
+		}
+	}
+
 	return nil
 }
 
@@ -241,13 +894,20 @@ func (o *OnDenyConfig) Validate() error {
 	return nil
 }
 
-// ValidationError represents a configuration validation error
+// ValidationError represents a configuration validation error.
+// Line and Column locate the field in the YAML source when the configuration
+// was loaded from a file (0 otherwise).
 type ValidationError struct {
 	Field   string
 	Message string
+	Line    int
+	Column  int
 }
 
 func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("validation error in field '%s' (line %d, column %d): %s", e.Field, e.Line, e.Column, e.Message)
+	}
 	return "validation error in field '" + e.Field + "': " + e.Message
 }
 
@@ -282,6 +942,26 @@ type ProductLimits struct {
 	// MaxConcurrency defines maximum concurrent operations limit
 	MaxConcurrency int `yaml:"max_concurrency,omitempty"`
 
+	// OverflowQueue is how many callers may wait for a concurrency slot when
+	// MaxConcurrency is reached (0 rejects immediately)
+	OverflowQueue int `yaml:"overflow_queue,omitempty"`
+
+	// MaxWait bounds how long a queued caller waits for a slot
+	MaxWait time.Duration `yaml:"max_wait,omitempty"`
+
+	// RateLimits caps calls in longer windows alongside MaxTPS
+	// Example: [{limit: 50000, window: 1h}]
+	RateLimits []RateLimit `yaml:"rate_limits,omitempty"`
+
+	// Schedules alter limits or enforcement during recurring time windows
+	Schedules []ScheduleRule `yaml:"schedules,omitempty"`
+
+	// Expressions compute max_tps, max_capacity or max_concurrency at
+	// runtime, keyed by limit name (LimitMaxTPS, ...). In YAML they are
+	// written in place of the number, e.g. max_tps: "base * instances".
+	// See ParseLimitExpr.
+	Expressions map[string]string `yaml:"-"`
+
 	// Helper function references (for code generator)
 	// These specify which helper functions to call for dynamic behavior
 
@@ -298,6 +978,15 @@ type ProductLimits struct {
 	CapacityCounter string `yaml:"capacity_counter,omitempty"`
 }
 
+// RateLimit caps calls in a trailing window, e.g. 50000 per "1h". Every
+// rate limit is enforced together with MaxTPS.
+type RateLimit struct {
+	Limit int `yaml:"limit" json:"limit"`
+
+	// Window uses the quota window syntax: "1s", "1h", "1d"
+	Window string `yaml:"window" json:"window"`
+}
+
 // ProductQuotaConfig defines quota configuration for product-level limits
 type ProductQuotaConfig struct {
 	// Max is the maximum number of quota units allowed
@@ -306,55 +995,105 @@ type ProductQuotaConfig struct {
 	// Window is the time window for quota calculation
 	// Examples: "1h", "24h", "30d"
 	Window string `yaml:"window"`
+
+	// Type selects how the window is applied by local quota accounting:
+	// "sliding" (default), "fixed" or "calendar".
+	// With "calendar", Window is a period name: hourly, daily, weekly, monthly.
+	Type string `yaml:"type,omitempty"`
+
+	// Timezone is the IANA timezone calendar periods are aligned to
+	// (e.g., "America/New_York"). Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// validate collects the quota's problems, with field names under prefix
+func (q *ProductQuotaConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if q.Max <= 0 {
+		errs.add(prefix+".max", "must be positive")
+	}
+	switch q.Type {
+	case "", WindowSliding, WindowFixed:
+		if q.Window == "" {
+			errs.add(prefix+".window", "required")
+		} else if _, err := q.WindowDuration(); err != nil {
+			errs.add(prefix+".window", err.Error())
+		}
+	case WindowCalendar:
+		if _, _, err := CalendarWindow(q.Window, time.Now()); err != nil {
+			errs.add(prefix+".window", err.Error())
+		}
+	default:
+		errs.add(prefix+".type", "must be one of: sliding, fixed, calendar")
+	}
+	if _, err := q.Location(); err != nil {
+		errs.add(prefix+".timezone", err.Error())
+	}
+	return errs
 }
 
 // Validate validates product limits configuration
 func (p *ProductLimits) Validate() error {
+	return p.validate().err()
+}
+
+// validate collects every product limits problem
+func (p *ProductLimits) validate() ValidationErrors {
+	var errs ValidationErrors
 	if p == nil {
-		return nil // Product limits are optional
+		return errs // Product limits are optional
 	}
 
 	// Validate quota if present
 	if p.Quota != nil {
-		if p.Quota.Max <= 0 {
-			return &ValidationError{
-				Field:   "limits.quota.max",
-				Message: "must be positive",
-			}
-		}
-		if p.Quota.Window == "" {
-			return &ValidationError{
-				Field:   "limits.quota.window",
-				Message: "required",
-			}
-		}
+		errs = append(errs, p.Quota.validate("limits.quota")...)
 	}
 
 	// Validate numeric limits are non-negative
 	if p.MaxTPS < 0 {
-		return &ValidationError{
-			Field:   "limits.max_tps",
-			Message: "must be non-negative",
-		}
+		errs.add("limits.max_tps", "must be non-negative")
 	}
 	if p.MaxCapacity < 0 {
-		return &ValidationError{
-			Field:   "limits.max_capacity",
-			Message: "must be non-negative",
-		}
+		errs.add("limits.max_capacity", "must be non-negative")
 	}
 	if p.MaxConcurrency < 0 {
-		return &ValidationError{
-			Field:   "limits.max_concurrency",
-			Message: "must be non-negative",
+		errs.add("limits.max_concurrency", "must be non-negative")
+	}
+	if p.OverflowQueue < 0 {
+		errs.add("limits.overflow_queue", "must be non-negative")
+	}
+	if p.MaxWait < 0 {
+		errs.add("limits.max_wait", "must be non-negative")
+	}
+	if p.OverflowQueue > 0 && p.MaxWait == 0 {
+		errs.add("limits.max_wait", "required when overflow_queue is set")
+	}
+	for i, rl := range p.RateLimits {
+		if rl.Limit <= 0 {
+			errs.add(fmt.Sprintf("limits.rate_limits[%d].limit", i), "must be positive")
+		}
+		if _, err := rl.WindowDuration(); err != nil {
+			errs.add(fmt.Sprintf("limits.rate_limits[%d].window", i), err.Error())
 		}
 	}
-
-	// Warn if capacity limit is defined but no counter helper is specified
-	if p.MaxCapacity > 0 && p.CapacityCounter == "" {
-		// Note: This is a warning, not an error, because the helper can be
-		// registered programmatically via RegisterHelpers()
+	for i := range p.Schedules {
+		p.Schedules[i].validate(&errs, fmt.Sprintf("limits.schedules[%d]", i))
+	}
+	limits := make([]string, 0, len(p.Expressions))
+	for limit := range p.Expressions {
+		limits = append(limits, limit)
+	}
+	sort.Strings(limits)
+	for _, limit := range limits {
+		if !containsString([]string{LimitMaxTPS, LimitMaxCapacity, LimitMaxConcurrency}, limit) {
+			errs.add("limits."+limit, "does not accept an expression")
+		} else if _, err := ParseLimitExpr(p.Expressions[limit]); err != nil {
+			errs.add("limits."+limit, "invalid expression: "+err.Error())
+		}
 	}
 
-	return nil
+	// A capacity limit without a counter helper is not an error: the helper
+	// can be registered programmatically via RegisterHelpers()
+
+	return errs
 }
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationErrors aggregates every problem found while validating a
+// configuration. Each element is a *ValidationError, so callers can inspect
+// individual fields with errors.As.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d validation errors:", len(e))
+	for _, ve := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(ve.Error())
+	}
+	return b.String()
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, ve := range e {
+		errs[i] = ve
+	}
+	return errs
+}
+
+// add records a problem with field
+func (e *ValidationErrors) add(field, message string) {
+	*e = append(*e, &ValidationError{Field: field, Message: message})
+}
+
+// merge records err, prefixing field names with prefix (e.g. "features[2]")
+func (e *ValidationErrors) merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	join := func(field string) string {
+		switch {
+		case prefix == "":
+			return field
+		case field == "":
+			return prefix
+		default:
+			return prefix + "." + field
+		}
+	}
+
+	var many ValidationErrors
+	var one *ValidationError
+	switch {
+	case errors.As(err, &many):
+		for _, ve := range many {
+			e.add(join(ve.Field), ve.Message)
+		}
+	case errors.As(err, &one):
+		e.add(join(one.Field), one.Message)
+	default:
+		e.add(prefix, err.Error())
+	}
+}
+
+// err returns e as an error, or nil if no problems were recorded
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// annotateLines fills in the YAML position of each validation error in err,
+// using the closest node that exists for the field (e.g. the parent mapping
+// when a required key is missing).
+func annotateLines(root *yaml.Node, err error) {
+	var many ValidationErrors
+	var one *ValidationError
+	switch {
+	case errors.As(err, &many):
+		for _, ve := range many {
+			ve.Line, ve.Column = findFieldPosition(root, ve.Field)
+		}
+	case errors.As(err, &one):
+		one.Line, one.Column = findFieldPosition(root, one.Field)
+	}
+}
+
+// findFieldPosition resolves a dotted field path such as
+// "features[1].quota.period" against a YAML document
+func findFieldPosition(root *yaml.Node, field string) (int, int) {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, col := node.Line, node.Column
+
+	for _, part := range strings.Split(field, ".") {
+		key, index := part, -1
+		if i := strings.IndexByte(part, '['); i >= 0 && strings.HasSuffix(part, "]") {
+			key = part[:i]
+			if n, err := strconv.Atoi(part[i+1 : len(part)-1]); err == nil {
+				index = n
+			}
+		}
+
+		next := mappingValue(node, key)
+		if next == nil {
+			break
+		}
+		node = next
+		line, col = node.Line, node.Column
+
+		if index >= 0 {
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				break
+			}
+			node = node.Content[index]
+			line, col = node.Line, node.Column
+		}
+	}
+
+	return line, col
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota window types for ProductQuotaConfig.Type
+const (
+	// WindowSliding counts usage over the trailing window ending now
+	WindowSliding = "sliding"
+	// WindowFixed counts usage in back-to-back windows starting at first use
+	WindowFixed = "fixed"
+	// WindowCalendar counts usage in calendar periods (hourly, daily, weekly,
+	// monthly) aligned to the configured timezone
+	WindowCalendar = "calendar"
+)
+
+// Calendar periods accepted as Window when Type is "calendar"
+const (
+	PeriodHourly  = "hourly"
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+)
+
+// ParseWindow parses a quota window string.
+// In addition to Go duration syntax ("1h", "90m"), a "d" suffix for whole
+// days is accepted ("30d"), since time.ParseDuration has no day unit.
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty window")
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		if days <= 0 {
+			return 0, fmt.Errorf("window must be positive: %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("window must be positive: %q", s)
+	}
+	return d, nil
+}
+
+// WindowDuration returns the parsed quota window
+func (q *ProductQuotaConfig) WindowDuration() (time.Duration, error) {
+	return ParseWindow(q.Window)
+}
+
+// WindowDuration returns the parsed rate limit window
+func (r RateLimit) WindowDuration() (time.Duration, error) {
+	return ParseWindow(r.Window)
+}
+
+// CalendarWindow returns the calendar period containing t, in t's location.
+// Daily periods start at midnight, weekly periods on Monday at midnight and
+// monthly periods on the first of the month at midnight.
+func CalendarWindow(period string, t time.Time) (start, end time.Time, err error) {
+	y, m, d := t.Date()
+	loc := t.Location()
+
+	switch period {
+	case PeriodHourly:
+		start = time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+		end = start.Add(time.Hour)
+	case PeriodDaily:
+		start = time.Date(y, m, d, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 1)
+	case PeriodWeekly:
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		start = time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 7)
+	case PeriodMonthly:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid calendar period %q (must be one of: hourly, daily, weekly, monthly)", period)
+	}
+
+	return start, end, nil
+}
+
+// IsCalendar reports whether the quota uses calendar-aligned periods
+func (q *ProductQuotaConfig) IsCalendar() bool {
+	return q.Type == WindowCalendar
+}
+
+// Location returns the timezone used for calendar-aligned periods.
+// An empty Timezone means UTC.
+func (q *ProductQuotaConfig) Location() (*time.Location, error) {
+	if q.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", q.Timezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,103 @@
+// Package discovery resolves the LCC servers from DNS SRV records or a
+// Kubernetes Service instead of a fixed URL. The client refreshes the
+// result periodically and spreads its calls over the servers found (see
+// SDKConfig.Discovery).
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Endpoint is a discovered LCC server
+type Endpoint struct {
+	// URL is the server's base URL, the equivalent of lcc_url
+	URL string
+
+	// Weight is the server's relative share of calls (at least 1)
+	Weight int
+}
+
+// Resolver finds the LCC servers
+type Resolver interface {
+	// Resolve returns the current servers, preferred first. An empty
+	// result is not an error.
+	Resolve(ctx context.Context) ([]Endpoint, error)
+
+	// Name is the DNS name of the discovered service. The client builds
+	// its request URLs with it as the host before a server is chosen.
+	Name() string
+}
+
+// SRV resolves DNS SRV records such as _lcc._tcp.licensing.example.com.
+// Targets are ordered by priority, lowest first, and carry the record
+// weight.
+type SRV struct {
+	// Record is the full SRV name
+	Record string
+
+	// Scheme is "http" (default) or "https"; Path is the LCC path prefix
+	// (e.g. "/lcc"), if any
+	Scheme string
+	Path   string
+
+	// lookupSRV is net.DefaultResolver.LookupSRV, replaced in tests
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewSRV returns an SRV resolver for record
+func NewSRV(record, scheme, path string) *SRV {
+	return &SRV{Record: record, Scheme: scheme, Path: path, lookupSRV: net.DefaultResolver.LookupSRV}
+}
+
+// Name returns the SRV record without the trailing dot
+func (s *SRV) Name() string {
+	return strings.TrimSuffix(s.Record, ".")
+}
+
+// Resolve looks up the SRV record
+func (s *SRV) Resolve(ctx context.Context) ([]Endpoint, error) {
+	_, records, err := s.lookupSRV(ctx, "", "", s.Record)
+	if err != nil {
+		return nil, fmt.Errorf("SRV lookup of %s failed: %w", s.Record, err)
+	}
+	// LookupSRV sorts by priority and randomizes by weight within a
+	// priority; keep the priority order and make the rest stable
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Port < b.Port
+	})
+
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, r := range records {
+		host := strings.TrimSuffix(r.Target, ".")
+		if host == "" {
+			continue // "." target: the service is decidedly not available
+		}
+		endpoints = append(endpoints, Endpoint{
+			URL:    baseURL(s.Scheme, net.JoinHostPort(host, strconv.Itoa(int(r.Port))), s.Path),
+			Weight: max(int(r.Weight), 1),
+		})
+	}
+	return endpoints, nil
+}
+
+// baseURL builds an endpoint URL from its parts
+func baseURL(scheme, host, path string) string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Scheme: scheme, Host: host, Path: path}
+	return u.String()
+}
@@ -0,0 +1,111 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSRV_Resolve(t *testing.T) {
+	s := NewSRV("_lcc._tcp.example.com.", "https", "/lcc")
+	s.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		if name != "_lcc._tcp.example.com." {
+			t.Errorf("lookup of %q", name)
+		}
+		return "", []*net.SRV{
+			{Target: "standby.example.com.", Port: 7086, Priority: 20, Weight: 0},
+			{Target: "b.example.com.", Port: 7086, Priority: 10, Weight: 1},
+			{Target: "a.example.com.", Port: 7086, Priority: 10, Weight: 3},
+			{Target: ".", Port: 0, Priority: 30},
+		}, nil
+	}
+
+	got, err := s.Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := []Endpoint{
+		{URL: "https://a.example.com:7086/lcc", Weight: 3},
+		{URL: "https://b.example.com:7086/lcc", Weight: 1},
+		{URL: "https://standby.example.com:7086/lcc", Weight: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+	if name := s.Name(); name != "_lcc._tcp.example.com" {
+		t.Errorf("Name() = %q", name)
+	}
+
+	s.lookupSRV = func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+	if _, err := s.Resolve(context.Background()); err == nil {
+		t.Error("Resolve() with a failed lookup succeeded")
+	}
+}
+
+func TestKubernetes_Resolve(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/apis/discovery.k8s.io/v1/namespaces/licensing/endpointslices" ||
+			r.URL.Query().Get("labelSelector") != "kubernetes.io/service-name=lcc" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [
+			{"endpoints": [
+				{"addresses": ["10.0.0.2"], "conditions": {"ready": true}},
+				{"addresses": ["10.0.0.9"], "conditions": {"ready": false}},
+				{"addresses": ["10.0.0.1"], "conditions": {}}
+			 ],
+			 "ports": [{"name": "metrics", "port": 9090}, {"name": "http", "port": 7086}]},
+			{"endpoints": [{"addresses": ["fd00::1"], "conditions": {"ready": true}}],
+			 "ports": [{"name": "http", "port": 7086}]}
+		]}`))
+	}))
+	defer api.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	k := &Kubernetes{Service: "lcc", Namespace: "licensing", Port: "http", APIServer: api.URL, TokenFile: tokenFile}
+
+	got, err := k.Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := []Endpoint{
+		{URL: "http://10.0.0.1:7086", Weight: 1},
+		{URL: "http://10.0.0.2:7086", Weight: 1},
+		{URL: "http://[fd00::1]:7086", Weight: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+	if name := k.Name(); name != "lcc.licensing.svc" {
+		t.Errorf("Name() = %q", name)
+	}
+
+	k.TokenFile = ""
+	if _, err := k.Resolve(context.Background()); err == nil {
+		t.Error("Resolve() without a token succeeded")
+	}
+}
+
+func TestInCluster_NotInCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+	if _, err := InCluster("lcc", ""); !errors.Is(err, ErrNotInCluster) {
+		t.Errorf("InCluster() error = %v, want ErrNotInCluster", err)
+	}
+}
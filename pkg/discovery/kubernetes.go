@@ -0,0 +1,172 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceAccountDir holds the credentials Kubernetes mounts into pods
+const ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ErrNotInCluster is returned by InCluster outside a Kubernetes pod
+var ErrNotInCluster = errors.New("not running in a Kubernetes cluster")
+
+// Kubernetes resolves the ready endpoints of a Service from its
+// EndpointSlices. The service account needs get and list on
+// endpointslices in the Service's namespace.
+type Kubernetes struct {
+	Service   string
+	Namespace string
+
+	// Port is the name of the Service port; "" takes the first port
+	Port string
+
+	// Scheme is "http" (default) or "https"; Path is the LCC path prefix,
+	// if any
+	Scheme string
+	Path   string
+
+	// APIServer is the base URL of the Kubernetes API. TokenFile is read
+	// on every call, since projected tokens are rotated.
+	APIServer  string
+	TokenFile  string
+	HTTPClient *http.Client
+}
+
+// InCluster returns a resolver for service using the pod's service account.
+// An empty namespace is the pod's own.
+func InCluster(service, namespace string) (*Kubernetes, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	caPEM, err := os.ReadFile(filepath.Join(ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate in cluster CA file")
+	}
+	if namespace == "" {
+		ns, err := os.ReadFile(filepath.Join(ServiceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &Kubernetes{
+		Service:    service,
+		Namespace:  namespace,
+		APIServer:  "https://" + net.JoinHostPort(host, port),
+		TokenFile:  filepath.Join(ServiceAccountDir, "token"),
+		HTTPClient: &http.Client{Transport: transport, Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the cluster DNS name of the Service
+func (k *Kubernetes) Name() string {
+	return k.Service + "." + k.Namespace + ".svc"
+}
+
+// endpointSliceList is the part of a discovery.k8s.io/v1 EndpointSliceList
+// the resolver reads
+type endpointSliceList struct {
+	Items []struct {
+		Endpoints []struct {
+			Addresses  []string `json:"addresses"`
+			Conditions struct {
+				Ready *bool `json:"ready"`
+			} `json:"conditions"`
+		} `json:"endpoints"`
+		Ports []struct {
+			Name string `json:"name"`
+			Port *int   `json:"port"`
+		} `json:"ports"`
+	} `json:"items"`
+}
+
+// Resolve lists the Service's EndpointSlices and returns its ready
+// addresses, sorted
+func (k *Kubernetes) Resolve(ctx context.Context) ([]Endpoint, error) {
+	query := url.Values{"labelSelector": {"kubernetes.io/service-name=" + k.Service}}
+	target := fmt.Sprintf("%s/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices?%s",
+		strings.TrimSuffix(k.APIServer, "/"), url.PathEscape(k.Namespace), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if k.TokenFile != "" {
+		token, err := os.ReadFile(k.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	hc := k.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint lookup of service %s failed: %w", k.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("endpoint lookup of service %s failed: status=%d, body=%s", k.Name(), resp.StatusCode, string(body))
+	}
+
+	var list endpointSliceList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint slices: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var endpoints []Endpoint
+	for _, slice := range list.Items {
+		port := -1
+		for _, p := range slice.Ports {
+			if p.Port != nil && (k.Port == "" || p.Name == k.Port) {
+				port = *p.Port
+				break
+			}
+		}
+		if port < 0 {
+			continue
+		}
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			for _, addr := range ep.Addresses {
+				u := baseURL(k.Scheme, net.JoinHostPort(addr, strconv.Itoa(port)), k.Path)
+				if !seen[u] {
+					seen[u] = true
+					endpoints = append(endpoints, Endpoint{URL: u, Weight: 1})
+				}
+			}
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].URL < endpoints[j].URL })
+	return endpoints, nil
+}
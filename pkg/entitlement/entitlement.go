@@ -0,0 +1,185 @@
+// Package entitlement provides a verifier-only client for services that
+// enforce licensing decisions made elsewhere.
+//
+// Sidecars and edge services do not register with LCC. Instead they accept
+// either an entitlement token minted by a licensed SDK instance
+// (client.Client.EntitlementToken) or a feature assertion signed by the LCC
+// server, and check it locally.
+package entitlement
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// Headers carrying entitlement proofs between services
+const (
+	HeaderEntitlement = "X-LCC-Entitlement"
+	HeaderAssertion   = "X-LCC-Assertion"
+)
+
+// ErrNotEntitled is matched (via errors.Is) by the *DeniedError returned when
+// a valid proof does not grant the requested feature.
+var ErrNotEntitled = errors.New("not entitled")
+
+// ErrNoProof is returned when a request carries neither header
+var ErrNoProof = errors.New("no entitlement proof presented")
+
+// DeniedError reports a verified proof that does not grant a feature
+type DeniedError struct {
+	FeatureID string
+	Reason    string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s to %s: %s", ErrNotEntitled, e.FeatureID, e.Reason)
+}
+
+// Is allows errors.Is(err, ErrNotEntitled) to match
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrNotEntitled
+}
+
+// Decision is the verified outcome of an entitlement check
+type Decision struct {
+	InstanceID string
+	ProductID  string
+	FeatureID  string
+	ExpiresAt  int64
+}
+
+// Checker verifies entitlement proofs without an LCC registration.
+// It is safe for concurrent use.
+type Checker struct {
+	verifier     *auth.Verifier
+	authorityKey crypto.PublicKey
+	productID    string
+}
+
+// Option configures a Checker
+type Option func(*Checker)
+
+// WithVerifier enables entitlement tokens verified with v, which must trust
+// instance keys through a KeyLookup (e.g. an auth.KeyAllowlist of the keys
+// registered with LCC) or trusted CAs. Without it tokens are rejected: any
+// caller can mint a well-formed token with a key of its own.
+func WithVerifier(v *auth.Verifier) Option {
+	return func(c *Checker) {
+		c.verifier = v
+	}
+}
+
+// WithAuthorityKey enables server-signed feature assertions verified with key
+func WithAuthorityKey(key crypto.PublicKey) Option {
+	return func(c *Checker) {
+		c.authorityKey = key
+	}
+}
+
+// WithProductID rejects proofs issued for other products
+func WithProductID(productID string) Option {
+	return func(c *Checker) {
+		c.productID = productID
+	}
+}
+
+// NewChecker creates an entitlement checker. It accepts no proof until a
+// trust anchor is configured: WithVerifier for entitlement tokens,
+// WithAuthorityKey for feature assertions.
+func NewChecker(opts ...Option) *Checker {
+	c := &Checker{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CheckToken verifies an entitlement token for featureID. It fails if the
+// Checker has no Verifier.
+func (c *Checker) CheckToken(token, featureID string) (*Decision, error) {
+	if c.verifier == nil {
+		return nil, fmt.Errorf("entitlement tokens are not enabled: no verifier: %w", auth.ErrNoTrustPolicy)
+	}
+
+	claims, err := c.verifier.VerifyEntitlementToken(token)
+	if err != nil {
+		return nil, err
+	}
+	d := &Decision{
+		InstanceID: claims.InstanceID,
+		ProductID:  claims.ProductID,
+		FeatureID:  claims.FeatureID,
+		ExpiresAt:  claims.ExpiresAt,
+	}
+	if err := c.check(d, featureID, true, ""); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// CheckAssertion verifies a server-signed feature assertion for featureID.
+// It fails if the Checker has no authority key.
+func (c *Checker) CheckAssertion(assertion, featureID string) (*Decision, error) {
+	if c.authorityKey == nil {
+		return nil, fmt.Errorf("feature assertions are not enabled: no authority key")
+	}
+
+	a, err := auth.VerifyFeatureAssertion(assertion, c.authorityKey)
+	if err != nil {
+		return nil, err
+	}
+	d := &Decision{
+		InstanceID: a.InstanceID,
+		ProductID:  a.ProductID,
+		FeatureID:  a.FeatureID,
+		ExpiresAt:  a.ExpiresAt,
+	}
+	if err := c.check(d, featureID, a.Enabled, a.Reason); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// CheckRequest verifies the proof carried by req for featureID, preferring
+// an entitlement token over an assertion when both are present.
+func (c *Checker) CheckRequest(req *http.Request, featureID string) (*Decision, error) {
+	if token := req.Header.Get(HeaderEntitlement); token != "" {
+		return c.CheckToken(token, featureID)
+	}
+	if assertion := req.Header.Get(HeaderAssertion); assertion != "" {
+		return c.CheckAssertion(assertion, featureID)
+	}
+	return nil, ErrNoProof
+}
+
+// Middleware rejects requests without a valid proof for featureID with
+// 403 Forbidden.
+func (c *Checker) Middleware(featureID string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := c.CheckRequest(r, featureID); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// check applies feature, product and decision checks to a verified proof
+func (c *Checker) check(d *Decision, featureID string, enabled bool, reason string) error {
+	switch {
+	case d.FeatureID != featureID:
+		return &DeniedError{FeatureID: featureID, Reason: fmt.Sprintf("proof is for feature %q", d.FeatureID)}
+	case c.productID != "" && d.ProductID != c.productID:
+		return &DeniedError{FeatureID: featureID, Reason: fmt.Sprintf("proof is for product %q", d.ProductID)}
+	case !enabled:
+		if reason == "" {
+			reason = "disabled"
+		}
+		return &DeniedError{FeatureID: featureID, Reason: reason}
+	}
+	return nil
+}
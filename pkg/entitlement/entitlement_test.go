@@ -0,0 +1,152 @@
+package entitlement
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+// trustingVerifier returns a Verifier trusting only the keys of kps
+func trustingVerifier(t *testing.T, kps ...auth.KeyPair) *auth.Verifier {
+	t.Helper()
+	var pems [][]byte
+	for _, kp := range kps {
+		pem, err := kp.GetPublicKeyPEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pems = append(pems, []byte(pem))
+	}
+	allowlist, err := auth.NewKeyAllowlist(pems...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewVerifier(auth.WithKeyLookup(allowlist))
+}
+
+func TestChecker(t *testing.T) {
+	instance, _ := auth.GenerateKeyPair()
+	fp, _ := instance.GetFingerprint()
+	verifier := trustingVerifier(t, instance)
+	server, _ := auth.GenerateKeyPair()
+	serverPEM, _ := server.GetPublicKeyPEM()
+	serverKey, _ := auth.ParsePublicKeyFromPEM([]byte(serverPEM))
+
+	token, err := auth.NewRequestSigner(instance).IssueEntitlementToken(auth.EntitlementClaims{
+		InstanceID: fp, ProductID: "app", FeatureID: "analytics",
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueEntitlementToken() error = %v", err)
+	}
+	allowed, _ := auth.SignFeatureAssertion(server, auth.FeatureAssertion{
+		InstanceID: fp, ProductID: "app", FeatureID: "export", Enabled: true,
+	}, time.Minute)
+	denied, _ := auth.SignFeatureAssertion(server, auth.FeatureAssertion{
+		InstanceID: fp, ProductID: "app", FeatureID: "export", Reason: "quota_exceeded",
+	}, time.Minute)
+	forged, _ := auth.SignFeatureAssertion(instance, auth.FeatureAssertion{
+		InstanceID: fp, ProductID: "app", FeatureID: "export", Enabled: true,
+	}, time.Minute)
+
+	c := NewChecker(WithVerifier(verifier), WithAuthorityKey(serverKey), WithProductID("app"))
+
+	tests := []struct {
+		name      string
+		header    string
+		value     string
+		featureID string
+		wantErr   error
+	}{
+		{"valid token", HeaderEntitlement, token, "analytics", nil},
+		{"token for other feature", HeaderEntitlement, token, "export", ErrNotEntitled},
+		{"allowed assertion", HeaderAssertion, allowed, "export", nil},
+		{"denied assertion", HeaderAssertion, denied, "export", ErrNotEntitled},
+		{"assertion not signed by authority", HeaderAssertion, forged, "export", auth.ErrInvalidToken},
+		{"no proof", "", "", "analytics", ErrNoProof},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			d, err := c.CheckRequest(req, tt.featureID)
+			if tt.wantErr == nil {
+				if err != nil || d.InstanceID != fp {
+					t.Errorf("CheckRequest() = %+v, %v", d, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckRequest() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewChecker(WithVerifier(verifier), WithProductID("other")).CheckToken(token, "analytics"); !errors.Is(err, ErrNotEntitled) {
+		t.Errorf("CheckToken() other product error = %v, want ErrNotEntitled", err)
+	}
+	if _, err := NewChecker().CheckAssertion(allowed, "export"); err == nil {
+		t.Error("CheckAssertion() without authority key should fail")
+	}
+}
+
+func TestChecker_SelfMintedToken(t *testing.T) {
+	registered, _ := auth.GenerateKeyPair()
+	registeredPEM, _ := registered.GetPublicKeyPEM()
+	authorityKey, _ := auth.ParsePublicKeyFromPEM([]byte(registeredPEM))
+	attacker, _ := auth.GenerateKeyPair()
+	fp, _ := attacker.GetFingerprint()
+	token, err := auth.NewRequestSigner(attacker).IssueEntitlementToken(auth.EntitlementClaims{
+		InstanceID: fp, ProductID: "app", FeatureID: "analytics",
+	}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		c       *Checker
+		wantErr error
+	}{
+		{"default", NewChecker(), auth.ErrNoTrustPolicy},
+		{"authority key only", NewChecker(WithAuthorityKey(authorityKey)), auth.ErrNoTrustPolicy},
+		{"verifier without trust policy", NewChecker(WithVerifier(auth.NewVerifier())), auth.ErrNoTrustPolicy},
+		{"unregistered key", NewChecker(WithVerifier(trustingVerifier(t, registered))), auth.ErrKeyNotRegistered},
+	} {
+		if _, err := tt.c.CheckToken(token, "analytics"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: CheckToken() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChecker_Middleware(t *testing.T) {
+	instance, _ := auth.GenerateKeyPair()
+	fp, _ := instance.GetFingerprint()
+	token, _ := auth.NewRequestSigner(instance).IssueEntitlementToken(auth.EntitlementClaims{
+		InstanceID: fp, FeatureID: "analytics",
+	}, time.Minute)
+
+	h := NewChecker(WithVerifier(trustingVerifier(t, instance))).Middleware("analytics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status without proof = %d, want 403", rec.Code)
+	}
+
+	req.Header.Set(HeaderEntitlement, token)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status with token = %d, want 204", rec.Code)
+	}
+}
@@ -0,0 +1,207 @@
+// Package fingerprint derives a stable fingerprint of the host an instance
+// runs on, so the LCC server can enforce node-locked or per-host licenses.
+//
+// A fingerprint combines components read from the host: machine ID, MAC
+// addresses, cloud instance ID and, optionally, hostname and container ID.
+// Component values are hashed before they leave the host; the fingerprint
+// ID is the hash of all of them:
+//
+//	fp, err := fingerprint.Collect(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(fp.ID)
+//
+// A host change (new NIC, reinstalled OS) changes some components but
+// rarely all of them; Fingerprint.Matches compares fingerprints component
+// by component for servers tolerating such changes.
+package fingerprint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Source names a fingerprint component
+type Source string
+
+// Fingerprint components
+const (
+	// SourceMachineID is the OS installation ID (/etc/machine-id on Linux)
+	SourceMachineID Source = "machine_id"
+
+	// SourceMAC is the sorted list of globally administered MAC addresses
+	// of the physical network interfaces
+	SourceMAC Source = "mac"
+
+	// SourceHostname is the host name
+	SourceHostname Source = "hostname"
+
+	// SourceCloudInstance is the instance ID assigned by the cloud provider
+	// (AWS, GCP or Azure), read from the instance metadata service
+	SourceCloudInstance Source = "cloud_instance_id"
+
+	// SourceContainer is the ID of the container the process runs in
+	SourceContainer Source = "container_id"
+)
+
+// DefaultSources are the components collected unless WithSources is used.
+// They identify the host and survive restarts; hostnames and container IDs
+// change more often and are opt-in.
+var DefaultSources = []Source{SourceMachineID, SourceMAC, SourceCloudInstance}
+
+// AllSources lists every supported component
+var AllSources = []Source{SourceMachineID, SourceMAC, SourceHostname, SourceCloudInstance, SourceContainer}
+
+// ErrNoComponents is returned when none of the requested components could
+// be read on this host
+var ErrNoComponents = errors.New("no fingerprint component available")
+
+// DefaultMetadataTimeout bounds the cloud instance metadata lookup
+const DefaultMetadataTimeout = 2 * time.Second
+
+// Fingerprint identifies a host
+type Fingerprint struct {
+	// ID is the hex SHA-256 of the components
+	ID string `json:"id"`
+
+	// Components holds the hash of each component read on the host
+	Components map[Source]string `json:"components"`
+}
+
+// Matches returns the number of components present and equal in both
+// fingerprints
+func (f *Fingerprint) Matches(other *Fingerprint) int {
+	if f == nil || other == nil {
+		return 0
+	}
+	n := 0
+	for source, hash := range f.Components {
+		if other.Components[source] == hash {
+			n++
+		}
+	}
+	return n
+}
+
+// Option configures Collect
+type Option func(*collector)
+
+// WithSources selects the components to collect (default DefaultSources)
+func WithSources(sources ...Source) Option {
+	return func(c *collector) { c.sources = sources }
+}
+
+// WithSalt mixes a salt into the component hashes, so fingerprints cannot
+// be correlated across vendors or products
+func WithSalt(salt string) Option {
+	return func(c *collector) { c.salt = salt }
+}
+
+// WithMetadataTimeout bounds the cloud instance metadata lookup (default
+// DefaultMetadataTimeout)
+func WithMetadataTimeout(d time.Duration) Option {
+	return func(c *collector) { c.metadataTimeout = d }
+}
+
+// ParseSource returns the Source named s
+func ParseSource(s string) (Source, error) {
+	for _, source := range AllSources {
+		if string(source) == s {
+			return source, nil
+		}
+	}
+	return "", fmt.Errorf("unknown fingerprint source %q", s)
+}
+
+// collector reads components from the host. Its probes are replaced in
+// tests.
+type collector struct {
+	sources         []Source
+	salt            string
+	metadataTimeout time.Duration
+
+	readFile    func(name string) ([]byte, error)
+	interfaces  func() ([]net.Interface, error)
+	hostname    func() (string, error)
+	httpClient  *http.Client
+	metadataURL string
+}
+
+func newCollector(opts ...Option) *collector {
+	c := &collector{
+		sources:         DefaultSources,
+		metadataTimeout: DefaultMetadataTimeout,
+		readFile:        os.ReadFile,
+		interfaces:      net.Interfaces,
+		hostname:        os.Hostname,
+		httpClient:      &http.Client{},
+		metadataURL:     "http://169.254.169.254",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Collect reads the host's fingerprint. Components that cannot be read on
+// this host are left out; it fails with ErrNoComponents only when none can.
+func Collect(ctx context.Context, opts ...Option) (*Fingerprint, error) {
+	return newCollector(opts...).collect(ctx)
+}
+
+func (c *collector) collect(ctx context.Context) (*Fingerprint, error) {
+	values := make(map[Source]string)
+	for _, source := range c.sources {
+		var value string
+		switch source {
+		case SourceMachineID:
+			value = c.machineID()
+		case SourceMAC:
+			value = c.macAddresses()
+		case SourceHostname:
+			value, _ = c.hostname()
+		case SourceCloudInstance:
+			value = c.cloudInstanceID(ctx)
+		case SourceContainer:
+			value = c.containerID()
+		default:
+			return nil, fmt.Errorf("unknown fingerprint source %q", source)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			values[source] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil, ErrNoComponents
+	}
+	return c.fingerprint(values), nil
+}
+
+// fingerprint hashes component values into a Fingerprint
+func (c *collector) fingerprint(values map[Source]string) *Fingerprint {
+	fp := &Fingerprint{Components: make(map[Source]string, len(values))}
+	sources := make([]string, 0, len(values))
+	for source, value := range values {
+		sum := sha256.Sum256([]byte(c.salt + "\x00" + string(source) + "\x00" + value))
+		fp.Components[source] = hex.EncodeToString(sum[:])
+		sources = append(sources, string(source))
+	}
+	sort.Strings(sources)
+
+	h := sha256.New()
+	for _, source := range sources {
+		fmt.Fprintf(h, "%s=%s\n", source, fp.Components[Source(source)])
+	}
+	fp.ID = hex.EncodeToString(h.Sum(nil))
+	return fp
+}
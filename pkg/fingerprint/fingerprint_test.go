@@ -0,0 +1,169 @@
+package fingerprint
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const testContainerID = "3f4e5d6c7b8a99887766554433221100ffeeddccbbaa99887766554433221100"
+
+// fakeHost returns a collector reading files, interfaces and the hostname
+// from memory
+func fakeHost(files map[string]string, ifaces []net.Interface, opts ...Option) *collector {
+	c := newCollector(opts...)
+	c.readFile = func(name string) ([]byte, error) {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	c.interfaces = func() ([]net.Interface, error) { return ifaces, nil }
+	c.hostname = func() (string, error) { return "build-01", nil }
+	c.metadataURL = "http://127.0.0.1:0"
+	return c
+}
+
+func mac(s string) net.HardwareAddr {
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		panic(err)
+	}
+	return hw
+}
+
+func TestCollect(t *testing.T) {
+	files := map[string]string{
+		"/etc/machine-id":   "4c4c4544004e3510\n",
+		"/proc/self/cgroup": "0::/system.slice/docker-" + testContainerID + ".scope\n",
+	}
+	ifaces := []net.Interface{
+		{Name: "lo", Flags: net.FlagLoopback},
+		{Name: "eth1", HardwareAddr: mac("00:1b:21:0a:0b:0c")},
+		{Name: "eth0", HardwareAddr: mac("00:1b:21:01:02:03")},
+		{Name: "docker0", HardwareAddr: mac("00:1b:21:ff:ff:ff")},
+		{Name: "wlan0", HardwareAddr: mac("3a:00:00:00:00:01")}, // locally administered
+	}
+
+	c := fakeHost(files, ifaces, WithSources(AllSources...))
+	fp, err := c.collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect() error = %v", err)
+	}
+
+	want := map[Source]string{
+		SourceMachineID: "4c4c4544004e3510",
+		SourceMAC:       "00:1b:21:01:02:03,00:1b:21:0a:0b:0c",
+		SourceHostname:  "build-01",
+		SourceContainer: testContainerID,
+	}
+	if expected := c.fingerprint(want); !reflect.DeepEqual(fp, expected) {
+		t.Errorf("collect() = %+v, want %+v", fp, expected)
+	}
+	if len(fp.ID) != 64 || len(fp.Components) != 4 {
+		t.Errorf("fingerprint = %+v", fp)
+	}
+
+	// Stable across collections, and independent of interface order
+	ifaces[1], ifaces[2] = ifaces[2], ifaces[1]
+	again, _ := fakeHost(files, ifaces, WithSources(AllSources...)).collect(context.Background())
+	if again.ID != fp.ID {
+		t.Errorf("fingerprint changed: %s, %s", fp.ID, again.ID)
+	}
+
+	// A salt changes every hash
+	salted, _ := fakeHost(files, ifaces, WithSources(AllSources...), WithSalt("vendor")).collect(context.Background())
+	if salted.ID == fp.ID || salted.Matches(fp) != 0 {
+		t.Errorf("salted fingerprint matches the unsalted one")
+	}
+
+	// A new hostname keeps the other components
+	c = fakeHost(files, ifaces, WithSources(AllSources...))
+	c.hostname = func() (string, error) { return "build-02", nil }
+	renamed, _ := c.collect(context.Background())
+	if renamed.ID == fp.ID {
+		t.Error("renaming the host kept the fingerprint ID")
+	}
+	if n := renamed.Matches(fp); n != 3 {
+		t.Errorf("Matches() = %d, want 3", n)
+	}
+
+	// Default sources leave out the hostname and container ID
+	def, _ := fakeHost(files, ifaces).collect(context.Background())
+	if _, ok := def.Components[SourceHostname]; ok || len(def.Components) != 2 {
+		t.Errorf("default components = %v", def.Components)
+	}
+}
+
+func TestCollect_NoComponents(t *testing.T) {
+	_, err := fakeHost(nil, nil).collect(context.Background())
+	if !errors.Is(err, ErrNoComponents) {
+		t.Errorf("collect() error = %v, want ErrNoComponents", err)
+	}
+	if _, err := fakeHost(nil, nil, WithSources("serial")).collect(context.Background()); err == nil {
+		t.Error("collect() accepted an unknown source")
+	}
+}
+
+func TestContainerID_Mountinfo(t *testing.T) {
+	c := fakeHost(map[string]string{
+		"/proc/self/cgroup":    "0::/\n",
+		"/proc/self/mountinfo": "612 590 0:45 /var/lib/docker/containers/" + testContainerID + "/hostname /etc/hostname rw\n",
+	}, nil)
+	if got := c.containerID(); got != testContainerID {
+		t.Errorf("containerID() = %q, want %q", got, testContainerID)
+	}
+}
+
+func TestCloudInstanceID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			_, _ = w.Write([]byte("token-1"))
+		case r.URL.Path == "/latest/meta-data/instance-id" && r.Header.Get("X-aws-ec2-metadata-token") == "token-1":
+			_, _ = w.Write([]byte("i-0123456789abcdef0"))
+		case r.URL.Path == "/computeMetadata/v1/instance/id" && r.Header.Get("Metadata-Flavor") == "Google":
+			_, _ = w.Write([]byte("4520918723049812\n"))
+		case r.URL.Path == "/metadata/instance/compute/vmId" && r.Header.Get("Metadata") == "true":
+			_, _ = w.Write([]byte("02aab8a4-74ef-476e-8182-f6d2ba4166a6"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		dmi  map[string]string
+		want string
+	}{
+		{"aws nitro", map[string]string{dmiSysVendor: "Amazon EC2", dmiBoardAssetTag: "i-0fedcba987654321\n"}, "aws:i-0fedcba987654321"},
+		{"aws imds", map[string]string{dmiSysVendor: "Xen", dmiProductName: "EC2 (t2.micro)"}, "aws:i-0123456789abcdef0"},
+		{"gcp", map[string]string{dmiSysVendor: "Google", dmiProductName: "Google Compute Engine"}, "gcp:4520918723049812"},
+		{"azure", map[string]string{dmiSysVendor: "Microsoft Corporation", dmiProductName: "Virtual Machine"}, "azure:02aab8a4-74ef-476e-8182-f6d2ba4166a6"},
+		{"bare metal", map[string]string{dmiSysVendor: "Dell Inc.", dmiProductName: "PowerEdge R740"}, ""},
+	}
+	for _, tt := range tests {
+		c := fakeHost(tt.dmi, nil)
+		c.metadataURL = srv.URL
+		if got := c.cloudInstanceID(context.Background()); got != tt.want {
+			t.Errorf("%s: cloudInstanceID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	for _, source := range AllSources {
+		if got, err := ParseSource(string(source)); err != nil || got != source {
+			t.Errorf("ParseSource(%q) = %q, %v", source, got, err)
+		}
+	}
+	if _, err := ParseSource("serial"); err == nil {
+		t.Error("ParseSource() accepted an unknown source")
+	}
+}
@@ -0,0 +1,161 @@
+package fingerprint
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// machineIDFiles hold the OS installation ID, in order of preference
+var machineIDFiles = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// machineID returns the OS installation ID, or ""
+func (c *collector) machineID() string {
+	for _, name := range machineIDFiles {
+		if b, err := c.readFile(name); err == nil && strings.TrimSpace(string(b)) != "" {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// virtualInterfaces prefixes the names of bridges and virtual interfaces
+// created by container runtimes and hypervisors
+var virtualInterfaces = []string{"docker", "veth", "br-", "virbr", "cni", "flannel", "cali", "vmnet", "vboxnet"}
+
+// macAddresses returns the sorted, comma-separated MAC addresses of the
+// physical interfaces, or "". Locally administered addresses (randomized
+// or assigned to virtual interfaces) are skipped, as they change.
+func (c *collector) macAddresses() string {
+	ifaces, err := c.interfaces()
+	if err != nil {
+		return ""
+	}
+	seen := make(map[string]bool)
+	var macs []string
+	for _, iface := range ifaces {
+		mac := iface.HardwareAddr
+		if iface.Flags&net.FlagLoopback != 0 || len(mac) < 6 || mac[0]&0x02 != 0 || isVirtual(iface.Name) {
+			continue
+		}
+		if s := mac.String(); !seen[s] {
+			seen[s] = true
+			macs = append(macs, s)
+		}
+	}
+	sort.Strings(macs)
+	return strings.Join(macs, ",")
+}
+
+func isVirtual(name string) bool {
+	for _, prefix := range virtualInterfaces {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerIDPattern matches the 64 hex digit IDs of Docker, containerd
+// and CRI-O containers
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// containerID returns the ID of the container the process runs in, or "".
+// cgroup v1 paths name it; under cgroup v2 it shows in the mounts of the
+// container's hostname and resolv.conf files.
+func (c *collector) containerID() string {
+	if b, err := c.readFile("/proc/self/cgroup"); err == nil {
+		if id := containerIDPattern.Find(b); id != nil {
+			return string(id)
+		}
+	}
+	if b, err := c.readFile("/proc/self/mountinfo"); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if !strings.Contains(line, "/containers/") {
+				continue
+			}
+			if id := containerIDPattern.FindString(line); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// DMI files identifying the cloud provider on Linux
+const (
+	dmiSysVendor     = "/sys/class/dmi/id/sys_vendor"
+	dmiProductName   = "/sys/class/dmi/id/product_name"
+	dmiBoardAssetTag = "/sys/class/dmi/id/board_asset_tag"
+)
+
+// cloudInstanceID returns the provider-prefixed cloud instance ID (e.g.
+// "aws:i-0abc..."), or "". The provider is detected from DMI data first,
+// so hosts outside a cloud never query the metadata service.
+func (c *collector) cloudInstanceID(ctx context.Context) string {
+	vendor := c.dmi(dmiSysVendor)
+	product := c.dmi(dmiProductName)
+
+	ctx, cancel := context.WithTimeout(ctx, c.metadataTimeout)
+	defer cancel()
+
+	switch {
+	case strings.Contains(vendor, "Amazon") || strings.HasPrefix(product, "EC2"):
+		// Nitro instances expose the instance ID as the board asset tag
+		if tag := c.dmi(dmiBoardAssetTag); strings.HasPrefix(tag, "i-") {
+			return "aws:" + tag
+		}
+		token := c.metadata(ctx, http.MethodPut, "/latest/api/token", "X-aws-ec2-metadata-token-ttl-seconds", "60")
+		if token == "" {
+			return ""
+		}
+		if id := c.metadata(ctx, http.MethodGet, "/latest/meta-data/instance-id", "X-aws-ec2-metadata-token", token); id != "" {
+			return "aws:" + id
+		}
+	case strings.Contains(product, "Google"):
+		if id := c.metadata(ctx, http.MethodGet, "/computeMetadata/v1/instance/id", "Metadata-Flavor", "Google"); id != "" {
+			return "gcp:" + id
+		}
+	case strings.Contains(vendor, "Microsoft") && strings.Contains(product, "Virtual Machine"):
+		if id := c.metadata(ctx, http.MethodGet, "/metadata/instance/compute/vmId?api-version=2021-02-01&format=text", "Metadata", "true"); id != "" {
+			return "azure:" + id
+		}
+	}
+	return ""
+}
+
+// dmi returns the trimmed content of a DMI file, or ""
+func (c *collector) dmi(name string) string {
+	b, err := c.readFile(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// metadata queries the instance metadata service and returns the trimmed
+// response body, or "" on any failure
+func (c *collector) metadata(ctx context.Context, method, path, header, value string) string {
+	req, err := http.NewRequestWithContext(ctx, method, c.metadataURL+path, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set(header, value)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
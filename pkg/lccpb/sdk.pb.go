@@ -0,0 +1,1074 @@
+// gRPC protocol between the LCC SDK and the LCC server. It mirrors the
+// /api/v1/sdk HTTP endpoints and is selected with `protocol: grpc`.
+//
+// Requests are authenticated like HTTP requests: the X-LCC-* signature
+// headers are sent as metadata (x-lcc-publickey, x-lcc-timestamp,
+// x-lcc-nonce, x-lcc-signature, x-lcc-content-sha256). The signed method is
+// POST, the signed path is the full gRPC method name (e.g.
+// "/lcc.sdk.v1.SDKService/CheckFeature") and the body hash is the SHA-256 of
+// the deterministic serialization of the request message.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: lcc/sdk/v1/sdk.proto
+
+package lccpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RegisterRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Version   string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// PEM-encoded instance public key
+	PublicKey string `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// Host and build information (ip, hostname, build)
+	Metadata *structpb.Struct `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Deployment label, e.g. "prod"
+	Environment   string `protobuf:"bytes,5,opt,name=environment,proto3" json:"environment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Product versions the license allows; unset if not version-locked
+	LicensedVersions *VersionRange `protobuf:"bytes,1,opt,name=licensed_versions,json=licensedVersions,proto3" json:"licensed_versions,omitempty"`
+	// Environments the license is scoped to; empty if unscoped
+	LicensedEnvironments []string `protobuf:"bytes,2,rep,name=licensed_environments,json=licensedEnvironments,proto3" json:"licensed_environments,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterResponse) GetLicensedVersions() *VersionRange {
+	if x != nil {
+		return x.LicensedVersions
+	}
+	return nil
+}
+
+func (x *RegisterResponse) GetLicensedEnvironments() []string {
+	if x != nil {
+		return x.LicensedEnvironments
+	}
+	return nil
+}
+
+type VersionRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinVersion    string                 `protobuf:"bytes,1,opt,name=min_version,json=minVersion,proto3" json:"min_version,omitempty"`
+	MaxVersion    string                 `protobuf:"bytes,2,opt,name=max_version,json=maxVersion,proto3" json:"max_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionRange) Reset() {
+	*x = VersionRange{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionRange) ProtoMessage() {}
+
+func (x *VersionRange) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionRange.ProtoReflect.Descriptor instead.
+func (*VersionRange) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VersionRange) GetMinVersion() string {
+	if x != nil {
+		return x.MinVersion
+	}
+	return ""
+}
+
+func (x *VersionRange) GetMaxVersion() string {
+	if x != nil {
+		return x.MaxVersion
+	}
+	return ""
+}
+
+type HeartbeatRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Set when the instance asks for a share of the fleet TPS limit
+	TpsPartitioning bool    `protobuf:"varint,2,opt,name=tps_partitioning,json=tpsPartitioning,proto3" json:"tps_partitioning,omitempty"`
+	CurrentTps      float64 `protobuf:"fixed64,3,opt,name=current_tps,json=currentTps,proto3" json:"current_tps,omitempty"`
+	// Capacity high-water mark since the last reported heartbeat
+	CapacityPeak  *CapacityPeak `protobuf:"bytes,4,opt,name=capacity_peak,json=capacityPeak,proto3" json:"capacity_peak,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeartbeatRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetTpsPartitioning() bool {
+	if x != nil {
+		return x.TpsPartitioning
+	}
+	return false
+}
+
+func (x *HeartbeatRequest) GetCurrentTps() float64 {
+	if x != nil {
+		return x.CurrentTps
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetCapacityPeak() *CapacityPeak {
+	if x != nil {
+		return x.CapacityPeak
+	}
+	return nil
+}
+
+type CapacityPeak struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Peak  int64                  `protobuf:"varint,1,opt,name=peak,proto3" json:"peak,omitempty"`
+	// Unix seconds
+	PeakAt        int64 `protobuf:"varint,2,opt,name=peak_at,json=peakAt,proto3" json:"peak_at,omitempty"`
+	WindowStart   int64 `protobuf:"varint,3,opt,name=window_start,json=windowStart,proto3" json:"window_start,omitempty"`
+	WindowEnd     int64 `protobuf:"varint,4,opt,name=window_end,json=windowEnd,proto3" json:"window_end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapacityPeak) Reset() {
+	*x = CapacityPeak{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapacityPeak) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapacityPeak) ProtoMessage() {}
+
+func (x *CapacityPeak) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapacityPeak.ProtoReflect.Descriptor instead.
+func (*CapacityPeak) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CapacityPeak) GetPeak() int64 {
+	if x != nil {
+		return x.Peak
+	}
+	return 0
+}
+
+func (x *CapacityPeak) GetPeakAt() int64 {
+	if x != nil {
+		return x.PeakAt
+	}
+	return 0
+}
+
+func (x *CapacityPeak) GetWindowStart() int64 {
+	if x != nil {
+		return x.WindowStart
+	}
+	return 0
+}
+
+func (x *CapacityPeak) GetWindowEnd() int64 {
+	if x != nil {
+		return x.WindowEnd
+	}
+	return 0
+}
+
+type HeartbeatResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Assigned with TPS partitioning
+	TpsShare      *TPSShare `protobuf:"bytes,1,opt,name=tps_share,json=tpsShare,proto3" json:"tps_share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeartbeatResponse) GetTpsShare() *TPSShare {
+	if x != nil {
+		return x.TpsShare
+	}
+	return nil
+}
+
+type TPSShare struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MaxTps    float64                `protobuf:"fixed64,1,opt,name=max_tps,json=maxTps,proto3" json:"max_tps,omitempty"`
+	Instances int32                  `protobuf:"varint,2,opt,name=instances,proto3" json:"instances,omitempty"`
+	// Unix seconds; 0 if the share does not expire
+	ExpiresAt     int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TPSShare) Reset() {
+	*x = TPSShare{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TPSShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TPSShare) ProtoMessage() {}
+
+func (x *TPSShare) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TPSShare.ProtoReflect.Descriptor instead.
+func (*TPSShare) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TPSShare) GetMaxTps() float64 {
+	if x != nil {
+		return x.MaxTps
+	}
+	return 0
+}
+
+func (x *TPSShare) GetInstances() int32 {
+	if x != nil {
+		return x.Instances
+	}
+	return 0
+}
+
+func (x *TPSShare) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type CheckFeatureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FeatureId     string                 `protobuf:"bytes,1,opt,name=feature_id,json=featureId,proto3" json:"feature_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFeatureRequest) Reset() {
+	*x = CheckFeatureRequest{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFeatureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFeatureRequest) ProtoMessage() {}
+
+func (x *CheckFeatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFeatureRequest.ProtoReflect.Descriptor instead.
+func (*CheckFeatureRequest) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckFeatureRequest) GetFeatureId() string {
+	if x != nil {
+		return x.FeatureId
+	}
+	return ""
+}
+
+type ProductStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductStatusRequest) Reset() {
+	*x = ProductStatusRequest{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductStatusRequest) ProtoMessage() {}
+
+func (x *ProductStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductStatusRequest.ProtoReflect.Descriptor instead.
+func (*ProductStatusRequest) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{8}
+}
+
+type FeatureStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FeatureId      string                 `protobuf:"bytes,1,opt,name=feature_id,json=featureId,proto3" json:"feature_id,omitempty"`
+	Enabled        bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Quota          *QuotaInfo             `protobuf:"bytes,4,opt,name=quota,proto3" json:"quota,omitempty"`
+	MaxCapacity    int64                  `protobuf:"varint,5,opt,name=max_capacity,json=maxCapacity,proto3" json:"max_capacity,omitempty"`
+	MaxTps         float64                `protobuf:"fixed64,6,opt,name=max_tps,json=maxTps,proto3" json:"max_tps,omitempty"`
+	MaxConcurrency int64                  `protobuf:"varint,7,opt,name=max_concurrency,json=maxConcurrency,proto3" json:"max_concurrency,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FeatureStatus) Reset() {
+	*x = FeatureStatus{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeatureStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureStatus) ProtoMessage() {}
+
+func (x *FeatureStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureStatus.ProtoReflect.Descriptor instead.
+func (*FeatureStatus) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FeatureStatus) GetFeatureId() string {
+	if x != nil {
+		return x.FeatureId
+	}
+	return ""
+}
+
+func (x *FeatureStatus) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FeatureStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FeatureStatus) GetQuota() *QuotaInfo {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+func (x *FeatureStatus) GetMaxCapacity() int64 {
+	if x != nil {
+		return x.MaxCapacity
+	}
+	return 0
+}
+
+func (x *FeatureStatus) GetMaxTps() float64 {
+	if x != nil {
+		return x.MaxTps
+	}
+	return 0
+}
+
+func (x *FeatureStatus) GetMaxConcurrency() int64 {
+	if x != nil {
+		return x.MaxConcurrency
+	}
+	return 0
+}
+
+type QuotaInfo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Limit     int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Used      int64                  `protobuf:"varint,2,opt,name=used,proto3" json:"used,omitempty"`
+	Remaining int64                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// Unix seconds
+	ResetAt       int64 `protobuf:"varint,4,opt,name=reset_at,json=resetAt,proto3" json:"reset_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaInfo) Reset() {
+	*x = QuotaInfo{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaInfo) ProtoMessage() {}
+
+func (x *QuotaInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaInfo.ProtoReflect.Descriptor instead.
+func (*QuotaInfo) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QuotaInfo) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetUsed() int64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *QuotaInfo) GetResetAt() int64 {
+	if x != nil {
+		return x.ResetAt
+	}
+	return 0
+}
+
+type UsageReport struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	InstanceId string                 `protobuf:"bytes,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	FeatureId  string                 `protobuf:"bytes,2,opt,name=feature_id,json=featureId,proto3" json:"feature_id,omitempty"`
+	Count      int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// Unix seconds
+	Timestamp int64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Idempotency key; set when the usage ledger is enabled
+	EventId       string `protobuf:"bytes,5,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageReport) Reset() {
+	*x = UsageReport{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageReport) ProtoMessage() {}
+
+func (x *UsageReport) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageReport.ProtoReflect.Descriptor instead.
+func (*UsageReport) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UsageReport) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+func (x *UsageReport) GetFeatureId() string {
+	if x != nil {
+		return x.FeatureId
+	}
+	return ""
+}
+
+func (x *UsageReport) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *UsageReport) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *UsageReport) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+type UsageAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageAck) Reset() {
+	*x = UsageAck{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageAck) ProtoMessage() {}
+
+func (x *UsageAck) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageAck.ProtoReflect.Descriptor instead.
+func (*UsageAck) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{12}
+}
+
+type DeregisterRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	InstanceId string                 `protobuf:"bytes,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	// Concurrency slots the instance still holds
+	HeldSlots     int64 `protobuf:"varint,2,opt,name=held_slots,json=heldSlots,proto3" json:"held_slots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeregisterRequest) Reset() {
+	*x = DeregisterRequest{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeregisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeregisterRequest) ProtoMessage() {}
+
+func (x *DeregisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeregisterRequest.ProtoReflect.Descriptor instead.
+func (*DeregisterRequest) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeregisterRequest) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+func (x *DeregisterRequest) GetHeldSlots() int64 {
+	if x != nil {
+		return x.HeldSlots
+	}
+	return 0
+}
+
+type DeregisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeregisterResponse) Reset() {
+	*x = DeregisterResponse{}
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeregisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeregisterResponse) ProtoMessage() {}
+
+func (x *DeregisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lcc_sdk_v1_sdk_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeregisterResponse.ProtoReflect.Descriptor instead.
+func (*DeregisterResponse) Descriptor() ([]byte, []int) {
+	return file_lcc_sdk_v1_sdk_proto_rawDescGZIP(), []int{14}
+}
+
+var File_lcc_sdk_v1_sdk_proto protoreflect.FileDescriptor
+
+const file_lcc_sdk_v1_sdk_proto_rawDesc = "" +
+	"\n" +
+	"\x14lcc/sdk/v1/sdk.proto\x12\n" +
+	"lcc.sdk.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xc0\x01\n" +
+	"\x0fRegisterRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x03 \x01(\tR\tpublicKey\x123\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12 \n" +
+	"\venvironment\x18\x05 \x01(\tR\venvironment\"\x8e\x01\n" +
+	"\x10RegisterResponse\x12E\n" +
+	"\x11licensed_versions\x18\x01 \x01(\v2\x18.lcc.sdk.v1.VersionRangeR\x10licensedVersions\x123\n" +
+	"\x15licensed_environments\x18\x02 \x03(\tR\x14licensedEnvironments\"P\n" +
+	"\fVersionRange\x12\x1f\n" +
+	"\vmin_version\x18\x01 \x01(\tR\n" +
+	"minVersion\x12\x1f\n" +
+	"\vmax_version\x18\x02 \x01(\tR\n" +
+	"maxVersion\"\xb7\x01\n" +
+	"\x10HeartbeatRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12)\n" +
+	"\x10tps_partitioning\x18\x02 \x01(\bR\x0ftpsPartitioning\x12\x1f\n" +
+	"\vcurrent_tps\x18\x03 \x01(\x01R\n" +
+	"currentTps\x12=\n" +
+	"\rcapacity_peak\x18\x04 \x01(\v2\x18.lcc.sdk.v1.CapacityPeakR\fcapacityPeak\"}\n" +
+	"\fCapacityPeak\x12\x12\n" +
+	"\x04peak\x18\x01 \x01(\x03R\x04peak\x12\x17\n" +
+	"\apeak_at\x18\x02 \x01(\x03R\x06peakAt\x12!\n" +
+	"\fwindow_start\x18\x03 \x01(\x03R\vwindowStart\x12\x1d\n" +
+	"\n" +
+	"window_end\x18\x04 \x01(\x03R\twindowEnd\"F\n" +
+	"\x11HeartbeatResponse\x121\n" +
+	"\ttps_share\x18\x01 \x01(\v2\x14.lcc.sdk.v1.TPSShareR\btpsShare\"`\n" +
+	"\bTPSShare\x12\x17\n" +
+	"\amax_tps\x18\x01 \x01(\x01R\x06maxTps\x12\x1c\n" +
+	"\tinstances\x18\x02 \x01(\x05R\tinstances\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"4\n" +
+	"\x13CheckFeatureRequest\x12\x1d\n" +
+	"\n" +
+	"feature_id\x18\x01 \x01(\tR\tfeatureId\"\x16\n" +
+	"\x14ProductStatusRequest\"\xf2\x01\n" +
+	"\rFeatureStatus\x12\x1d\n" +
+	"\n" +
+	"feature_id\x18\x01 \x01(\tR\tfeatureId\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12+\n" +
+	"\x05quota\x18\x04 \x01(\v2\x15.lcc.sdk.v1.QuotaInfoR\x05quota\x12!\n" +
+	"\fmax_capacity\x18\x05 \x01(\x03R\vmaxCapacity\x12\x17\n" +
+	"\amax_tps\x18\x06 \x01(\x01R\x06maxTps\x12'\n" +
+	"\x0fmax_concurrency\x18\a \x01(\x03R\x0emaxConcurrency\"n\n" +
+	"\tQuotaInfo\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x12\n" +
+	"\x04used\x18\x02 \x01(\x03R\x04used\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x19\n" +
+	"\breset_at\x18\x04 \x01(\x03R\aresetAt\"\x9c\x01\n" +
+	"\vUsageReport\x12\x1f\n" +
+	"\vinstance_id\x18\x01 \x01(\tR\n" +
+	"instanceId\x12\x1d\n" +
+	"\n" +
+	"feature_id\x18\x02 \x01(\tR\tfeatureId\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x19\n" +
+	"\bevent_id\x18\x05 \x01(\tR\aeventId\"\n" +
+	"\n" +
+	"\bUsageAck\"S\n" +
+	"\x11DeregisterRequest\x12\x1f\n" +
+	"\vinstance_id\x18\x01 \x01(\tR\n" +
+	"instanceId\x12\x1d\n" +
+	"\n" +
+	"held_slots\x18\x02 \x01(\x03R\theldSlots\"\x14\n" +
+	"\x12DeregisterResponse2\xc5\x03\n" +
+	"\n" +
+	"SDKService\x12E\n" +
+	"\bRegister\x12\x1b.lcc.sdk.v1.RegisterRequest\x1a\x1c.lcc.sdk.v1.RegisterResponse\x12H\n" +
+	"\tHeartbeat\x12\x1c.lcc.sdk.v1.HeartbeatRequest\x1a\x1d.lcc.sdk.v1.HeartbeatResponse\x12J\n" +
+	"\fCheckFeature\x12\x1f.lcc.sdk.v1.CheckFeatureRequest\x1a\x19.lcc.sdk.v1.FeatureStatus\x12O\n" +
+	"\x10GetProductStatus\x12 .lcc.sdk.v1.ProductStatusRequest\x1a\x19.lcc.sdk.v1.FeatureStatus\x12<\n" +
+	"\vReportUsage\x12\x17.lcc.sdk.v1.UsageReport\x1a\x14.lcc.sdk.v1.UsageAck\x12K\n" +
+	"\n" +
+	"Deregister\x12\x1d.lcc.sdk.v1.DeregisterRequest\x1a\x1e.lcc.sdk.v1.DeregisterResponseB,Z*github.com/yourorg/lcc-sdk/pkg/lccpb;lccpbb\x06proto3"
+
+var (
+	file_lcc_sdk_v1_sdk_proto_rawDescOnce sync.Once
+	file_lcc_sdk_v1_sdk_proto_rawDescData []byte
+)
+
+func file_lcc_sdk_v1_sdk_proto_rawDescGZIP() []byte {
+	file_lcc_sdk_v1_sdk_proto_rawDescOnce.Do(func() {
+		file_lcc_sdk_v1_sdk_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_lcc_sdk_v1_sdk_proto_rawDesc), len(file_lcc_sdk_v1_sdk_proto_rawDesc)))
+	})
+	return file_lcc_sdk_v1_sdk_proto_rawDescData
+}
+
+var file_lcc_sdk_v1_sdk_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_lcc_sdk_v1_sdk_proto_goTypes = []any{
+	(*RegisterRequest)(nil),      // 0: lcc.sdk.v1.RegisterRequest
+	(*RegisterResponse)(nil),     // 1: lcc.sdk.v1.RegisterResponse
+	(*VersionRange)(nil),         // 2: lcc.sdk.v1.VersionRange
+	(*HeartbeatRequest)(nil),     // 3: lcc.sdk.v1.HeartbeatRequest
+	(*CapacityPeak)(nil),         // 4: lcc.sdk.v1.CapacityPeak
+	(*HeartbeatResponse)(nil),    // 5: lcc.sdk.v1.HeartbeatResponse
+	(*TPSShare)(nil),             // 6: lcc.sdk.v1.TPSShare
+	(*CheckFeatureRequest)(nil),  // 7: lcc.sdk.v1.CheckFeatureRequest
+	(*ProductStatusRequest)(nil), // 8: lcc.sdk.v1.ProductStatusRequest
+	(*FeatureStatus)(nil),        // 9: lcc.sdk.v1.FeatureStatus
+	(*QuotaInfo)(nil),            // 10: lcc.sdk.v1.QuotaInfo
+	(*UsageReport)(nil),          // 11: lcc.sdk.v1.UsageReport
+	(*UsageAck)(nil),             // 12: lcc.sdk.v1.UsageAck
+	(*DeregisterRequest)(nil),    // 13: lcc.sdk.v1.DeregisterRequest
+	(*DeregisterResponse)(nil),   // 14: lcc.sdk.v1.DeregisterResponse
+	(*structpb.Struct)(nil),      // 15: google.protobuf.Struct
+}
+var file_lcc_sdk_v1_sdk_proto_depIdxs = []int32{
+	15, // 0: lcc.sdk.v1.RegisterRequest.metadata:type_name -> google.protobuf.Struct
+	2,  // 1: lcc.sdk.v1.RegisterResponse.licensed_versions:type_name -> lcc.sdk.v1.VersionRange
+	4,  // 2: lcc.sdk.v1.HeartbeatRequest.capacity_peak:type_name -> lcc.sdk.v1.CapacityPeak
+	6,  // 3: lcc.sdk.v1.HeartbeatResponse.tps_share:type_name -> lcc.sdk.v1.TPSShare
+	10, // 4: lcc.sdk.v1.FeatureStatus.quota:type_name -> lcc.sdk.v1.QuotaInfo
+	0,  // 5: lcc.sdk.v1.SDKService.Register:input_type -> lcc.sdk.v1.RegisterRequest
+	3,  // 6: lcc.sdk.v1.SDKService.Heartbeat:input_type -> lcc.sdk.v1.HeartbeatRequest
+	7,  // 7: lcc.sdk.v1.SDKService.CheckFeature:input_type -> lcc.sdk.v1.CheckFeatureRequest
+	8,  // 8: lcc.sdk.v1.SDKService.GetProductStatus:input_type -> lcc.sdk.v1.ProductStatusRequest
+	11, // 9: lcc.sdk.v1.SDKService.ReportUsage:input_type -> lcc.sdk.v1.UsageReport
+	13, // 10: lcc.sdk.v1.SDKService.Deregister:input_type -> lcc.sdk.v1.DeregisterRequest
+	1,  // 11: lcc.sdk.v1.SDKService.Register:output_type -> lcc.sdk.v1.RegisterResponse
+	5,  // 12: lcc.sdk.v1.SDKService.Heartbeat:output_type -> lcc.sdk.v1.HeartbeatResponse
+	9,  // 13: lcc.sdk.v1.SDKService.CheckFeature:output_type -> lcc.sdk.v1.FeatureStatus
+	9,  // 14: lcc.sdk.v1.SDKService.GetProductStatus:output_type -> lcc.sdk.v1.FeatureStatus
+	12, // 15: lcc.sdk.v1.SDKService.ReportUsage:output_type -> lcc.sdk.v1.UsageAck
+	14, // 16: lcc.sdk.v1.SDKService.Deregister:output_type -> lcc.sdk.v1.DeregisterResponse
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_lcc_sdk_v1_sdk_proto_init() }
+func file_lcc_sdk_v1_sdk_proto_init() {
+	if File_lcc_sdk_v1_sdk_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lcc_sdk_v1_sdk_proto_rawDesc), len(file_lcc_sdk_v1_sdk_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lcc_sdk_v1_sdk_proto_goTypes,
+		DependencyIndexes: file_lcc_sdk_v1_sdk_proto_depIdxs,
+		MessageInfos:      file_lcc_sdk_v1_sdk_proto_msgTypes,
+	}.Build()
+	File_lcc_sdk_v1_sdk_proto = out.File
+	file_lcc_sdk_v1_sdk_proto_goTypes = nil
+	file_lcc_sdk_v1_sdk_proto_depIdxs = nil
+}
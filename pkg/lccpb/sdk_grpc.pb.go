@@ -0,0 +1,351 @@
+// gRPC protocol between the LCC SDK and the LCC server. It mirrors the
+// /api/v1/sdk HTTP endpoints and is selected with `protocol: grpc`.
+//
+// Requests are authenticated like HTTP requests: the X-LCC-* signature
+// headers are sent as metadata (x-lcc-publickey, x-lcc-timestamp,
+// x-lcc-nonce, x-lcc-signature, x-lcc-content-sha256). The signed method is
+// POST, the signed path is the full gRPC method name (e.g.
+// "/lcc.sdk.v1.SDKService/CheckFeature") and the body hash is the SHA-256 of
+// the deterministic serialization of the request message.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: lcc/sdk/v1/sdk.proto
+
+package lccpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SDKService_Register_FullMethodName         = "/lcc.sdk.v1.SDKService/Register"
+	SDKService_Heartbeat_FullMethodName        = "/lcc.sdk.v1.SDKService/Heartbeat"
+	SDKService_CheckFeature_FullMethodName     = "/lcc.sdk.v1.SDKService/CheckFeature"
+	SDKService_GetProductStatus_FullMethodName = "/lcc.sdk.v1.SDKService/GetProductStatus"
+	SDKService_ReportUsage_FullMethodName      = "/lcc.sdk.v1.SDKService/ReportUsage"
+	SDKService_Deregister_FullMethodName       = "/lcc.sdk.v1.SDKService/Deregister"
+)
+
+// SDKServiceClient is the client API for SDKService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SDKService is implemented by the LCC server
+type SDKServiceClient interface {
+	// Register announces an application instance (POST /api/v1/sdk/register)
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	// Heartbeat keeps the instance alive (POST /api/v1/sdk/heartbeat)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// CheckFeature returns the status of one feature
+	// (GET /api/v1/sdk/features/{feature_id}/check)
+	CheckFeature(ctx context.Context, in *CheckFeatureRequest, opts ...grpc.CallOption) (*FeatureStatus, error)
+	// GetProductStatus returns the product-level limits and quota
+	// (GET /api/v1/sdk/product/status). Servers without product status answer
+	// UNIMPLEMENTED and the SDK falls back to checking the "__product__"
+	// feature.
+	GetProductStatus(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*FeatureStatus, error)
+	// ReportUsage records usage (POST /api/v1/sdk/usage). A report whose
+	// event_id was already counted is answered with ALREADY_EXISTS or OK.
+	ReportUsage(ctx context.Context, in *UsageReport, opts ...grpc.CallOption) (*UsageAck, error)
+	// Deregister drops the instance at once instead of when its heartbeats
+	// time out (POST /api/v1/sdk/deregister)
+	Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error)
+}
+
+type sDKServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSDKServiceClient(cc grpc.ClientConnInterface) SDKServiceClient {
+	return &sDKServiceClient{cc}
+}
+
+func (c *sDKServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, SDKService_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, SDKService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKServiceClient) CheckFeature(ctx context.Context, in *CheckFeatureRequest, opts ...grpc.CallOption) (*FeatureStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FeatureStatus)
+	err := c.cc.Invoke(ctx, SDKService_CheckFeature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKServiceClient) GetProductStatus(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*FeatureStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FeatureStatus)
+	err := c.cc.Invoke(ctx, SDKService_GetProductStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKServiceClient) ReportUsage(ctx context.Context, in *UsageReport, opts ...grpc.CallOption) (*UsageAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageAck)
+	err := c.cc.Invoke(ctx, SDKService_ReportUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKServiceClient) Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeregisterResponse)
+	err := c.cc.Invoke(ctx, SDKService_Deregister_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SDKServiceServer is the server API for SDKService service.
+// All implementations must embed UnimplementedSDKServiceServer
+// for forward compatibility.
+//
+// SDKService is implemented by the LCC server
+type SDKServiceServer interface {
+	// Register announces an application instance (POST /api/v1/sdk/register)
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	// Heartbeat keeps the instance alive (POST /api/v1/sdk/heartbeat)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// CheckFeature returns the status of one feature
+	// (GET /api/v1/sdk/features/{feature_id}/check)
+	CheckFeature(context.Context, *CheckFeatureRequest) (*FeatureStatus, error)
+	// GetProductStatus returns the product-level limits and quota
+	// (GET /api/v1/sdk/product/status). Servers without product status answer
+	// UNIMPLEMENTED and the SDK falls back to checking the "__product__"
+	// feature.
+	GetProductStatus(context.Context, *ProductStatusRequest) (*FeatureStatus, error)
+	// ReportUsage records usage (POST /api/v1/sdk/usage). A report whose
+	// event_id was already counted is answered with ALREADY_EXISTS or OK.
+	ReportUsage(context.Context, *UsageReport) (*UsageAck, error)
+	// Deregister drops the instance at once instead of when its heartbeats
+	// time out (POST /api/v1/sdk/deregister)
+	Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error)
+	mustEmbedUnimplementedSDKServiceServer()
+}
+
+// UnimplementedSDKServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSDKServiceServer struct{}
+
+func (UnimplementedSDKServiceServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedSDKServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedSDKServiceServer) CheckFeature(context.Context, *CheckFeatureRequest) (*FeatureStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckFeature not implemented")
+}
+func (UnimplementedSDKServiceServer) GetProductStatus(context.Context, *ProductStatusRequest) (*FeatureStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductStatus not implemented")
+}
+func (UnimplementedSDKServiceServer) ReportUsage(context.Context, *UsageReport) (*UsageAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportUsage not implemented")
+}
+func (UnimplementedSDKServiceServer) Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deregister not implemented")
+}
+func (UnimplementedSDKServiceServer) mustEmbedUnimplementedSDKServiceServer() {}
+func (UnimplementedSDKServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeSDKServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SDKServiceServer will
+// result in compilation errors.
+type UnsafeSDKServiceServer interface {
+	mustEmbedUnimplementedSDKServiceServer()
+}
+
+func RegisterSDKServiceServer(s grpc.ServiceRegistrar, srv SDKServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSDKServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SDKService_ServiceDesc, srv)
+}
+
+func _SDKService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDKService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDKService_CheckFeature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckFeatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).CheckFeature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_CheckFeature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).CheckFeature(ctx, req.(*CheckFeatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDKService_GetProductStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).GetProductStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_GetProductStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).GetProductStatus(ctx, req.(*ProductStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDKService_ReportUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).ReportUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_ReportUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).ReportUsage(ctx, req.(*UsageReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDKService_Deregister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeregisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServiceServer).Deregister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SDKService_Deregister_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServiceServer).Deregister(ctx, req.(*DeregisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SDKService_ServiceDesc is the grpc.ServiceDesc for SDKService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SDKService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lcc.sdk.v1.SDKService",
+	HandlerType: (*SDKServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _SDKService_Register_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _SDKService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "CheckFeature",
+			Handler:    _SDKService_CheckFeature_Handler,
+		},
+		{
+			MethodName: "GetProductStatus",
+			Handler:    _SDKService_GetProductStatus_Handler,
+		},
+		{
+			MethodName: "ReportUsage",
+			Handler:    _SDKService_ReportUsage_Handler,
+		},
+		{
+			MethodName: "Deregister",
+			Handler:    _SDKService_Deregister_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "lcc/sdk/v1/sdk.proto",
+}
@@ -0,0 +1,200 @@
+// Package license implements signed license files for air-gapped
+// deployments.
+//
+// Unlike an activation (see package activation), a license file is not bound
+// to an instance key: the vendor signs it once per customer and it ships
+// alongside the product. The product embeds the vendor public key, verifies
+// the file on startup and makes every feature and limit decision from it
+// without contacting an LCC server (see client.Client.LoadLicense).
+package license
+
+import (
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+// Errors returned when verifying or validating a license file
+var (
+	ErrInvalidSignature = errors.New("license signature is invalid")
+	ErrExpired          = errors.New("license has expired")
+	ErrNotYetValid      = errors.New("license is not yet valid")
+)
+
+// Quota is the product quota granted by a license. It is enforced by local
+// windowed accounting and uses the same window syntax as the SDK config.
+type Quota struct {
+	Max      int    `json:"max"`
+	Window   string `json:"window"`
+	Type     string `json:"type,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Config returns the quota as a product quota config for local accounting
+func (q *Quota) Config() *config.ProductQuotaConfig {
+	return &config.ProductQuotaConfig{Max: q.Max, Window: q.Window, Type: q.Type, Timezone: q.Timezone}
+}
+
+// Limits are the product-level limits granted by a license (0 = unlimited)
+type Limits struct {
+	MaxTPS         float64 `json:"max_tps,omitempty"`
+	MaxCapacity    int     `json:"max_capacity,omitempty"`
+	MaxConcurrency int     `json:"max_concurrency,omitempty"`
+	Quota          *Quota  `json:"quota,omitempty"`
+
+	// RateLimits caps calls in longer windows alongside MaxTPS
+	RateLimits []config.RateLimit `json:"rate_limits,omitempty"`
+
+	// Schedules alter limits or enforcement during recurring time windows,
+	// e.g. a licensed batch window
+	Schedules []config.ScheduleRule `json:"schedules,omitempty"`
+}
+
+// Feature is a feature granted by a license with its optional limits.
+// The ID "*" grants every feature.
+type Feature struct {
+	ID             string  `json:"id"`
+	MaxTPS         float64 `json:"max_tps,omitempty"`
+	MaxCapacity    int     `json:"max_capacity,omitempty"`
+	MaxConcurrency int     `json:"max_concurrency,omitempty"`
+}
+
+// Pool is a quota shared by several features: usage of any member counts
+// against it
+type Pool struct {
+	Name     string   `json:"name"`
+	Features []string `json:"features"`
+	Quota    Quota    `json:"quota"`
+}
+
+// License is the vendor's grant to a customer
+type License struct {
+	LicenseID string    `json:"license_id"`
+	ProductID string    `json:"product_id"`
+	Customer  string    `json:"customer,omitempty"`
+	Features  []Feature `json:"features"`
+	Limits    Limits    `json:"limits"`
+	Pools     []Pool    `json:"pools,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Feature returns the grant for featureID. An exact grant takes precedence
+// over "*".
+func (l *License) Feature(featureID string) (*Feature, bool) {
+	var wildcard *Feature
+	for i := range l.Features {
+		switch l.Features[i].ID {
+		case featureID:
+			return &l.Features[i], true
+		case "*":
+			wildcard = &l.Features[i]
+		}
+	}
+	return wildcard, wildcard != nil
+}
+
+// Validate checks the license's validity window at now
+func (l *License) Validate(now time.Time) error {
+	if !l.NotBefore.IsZero() && now.Before(l.NotBefore) {
+		return ErrNotYetValid
+	}
+	if !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// File is a vendor-signed license file. The signature covers the exact
+// license bytes, which are base64-encoded so the file survives reformatting.
+type File struct {
+	License   []byte `json:"license"`
+	Signature string `json:"signature"`
+}
+
+// Sign signs lic with the vendor key. This is used by vendor tooling;
+// IssuedAt defaults to now.
+func Sign(lic *License, vendor auth.KeyPair) (*File, error) {
+	if lic.IssuedAt.IsZero() {
+		lic.IssuedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(lic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode license: %w", err)
+	}
+	signature, err := vendor.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		License:   payload,
+		Signature: hex.EncodeToString(signature),
+	}, nil
+}
+
+// Verify checks the vendor signature and returns the license. The validity
+// window is not checked here; see License.Validate.
+func (f *File) Verify(vendorKey crypto.PublicKey) (*License, error) {
+	signature, err := hex.DecodeString(f.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := auth.VerifySignature(vendorKey, f.License, signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	var lic License
+	if err := json.Unmarshal(f.License, &lic); err != nil {
+		return nil, fmt.Errorf("failed to parse license: %w", err)
+	}
+	return &lic, nil
+}
+
+// WriteFile writes the license file as JSON
+func (f *File) WriteFile(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode license file: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadFile reads a license file
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse license file: %w", err)
+	}
+	return &f, nil
+}
+
+// Load reads the license file at path and verifies it against vendorKey,
+// typically embedded in the product binary:
+//
+//	//go:embed vendor.pem
+//	var vendorPEM []byte
+//
+//	key, _ := auth.ParsePublicKeyPEM(vendorPEM)
+//	lic, err := license.Load("/etc/myapp/license.json", key)
+func Load(path string, vendorKey crypto.PublicKey) (*License, error) {
+	f, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.Verify(vendorKey)
+}
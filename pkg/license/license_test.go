@@ -0,0 +1,128 @@
+package license
+
+import (
+	"crypto/rsa"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/auth"
+)
+
+func newVendor(t *testing.T) (auth.KeyPair, *rsa.PublicKey) {
+	t.Helper()
+	kp, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	pemStr, _ := kp.GetPublicKeyPEM()
+	pub, err := auth.ParsePublicKeyFromPEM([]byte(pemStr))
+	if err != nil {
+		t.Fatalf("ParsePublicKeyFromPEM() error = %v", err)
+	}
+	return kp, pub
+}
+
+func TestLicense_RoundTrip(t *testing.T) {
+	vendor, vendorKey := newVendor(t)
+	path := filepath.Join(t.TempDir(), "license.json")
+
+	f, err := Sign(&License{
+		LicenseID: "lic-1",
+		ProductID: "app",
+		Features:  []Feature{{ID: "reports", MaxCapacity: 10}},
+		Limits:    Limits{MaxTPS: 50, Quota: &Quota{Max: 1000, Window: "24h"}},
+	}, vendor)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if err := f.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	lic, err := Load(path, vendorKey)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if lic.IssuedAt.IsZero() {
+		t.Error("IssuedAt not defaulted")
+	}
+	if feat, ok := lic.Feature("reports"); !ok || feat.MaxCapacity != 10 {
+		t.Errorf("Feature(reports) = %+v, %v", feat, ok)
+	}
+	if _, ok := lic.Feature("export"); ok {
+		t.Error("Feature(export) granted without a grant")
+	}
+	if q := lic.Limits.Quota.Config(); q.Max != 1000 || q.Window != "24h" {
+		t.Errorf("Quota.Config() = %+v", q)
+	}
+}
+
+func TestFile_Verify(t *testing.T) {
+	vendor, vendorKey := newVendor(t)
+	_, otherKey := newVendor(t)
+
+	f, err := Sign(&License{ProductID: "app", Features: []Feature{{ID: "*"}}}, vendor)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tampered := *f
+	tampered.License = append([]byte{}, f.License...)
+	tampered.License[len(tampered.License)-2] ^= 1
+
+	tests := []struct {
+		name    string
+		file    *File
+		key     *rsa.PublicKey
+		wantErr error
+	}{
+		{"valid", f, vendorKey, nil},
+		{"wrong vendor key", f, otherKey, ErrInvalidSignature},
+		{"tampered license", &tampered, vendorKey, ErrInvalidSignature},
+		{"malformed signature", &File{License: f.License, Signature: "zz"}, vendorKey, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.file.Verify(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLicense_Feature(t *testing.T) {
+	lic := &License{Features: []Feature{{ID: "*"}, {ID: "reports", MaxTPS: 5}}}
+
+	if feat, ok := lic.Feature("reports"); !ok || feat.MaxTPS != 5 {
+		t.Errorf("Feature(reports) = %+v, %v; want the exact grant", feat, ok)
+	}
+	if feat, ok := lic.Feature("export"); !ok || feat.ID != "*" {
+		t.Errorf("Feature(export) = %+v, %v; want the wildcard grant", feat, ok)
+	}
+}
+
+func TestLicense_Validate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		lic     License
+		wantErr error
+	}{
+		{"perpetual", License{}, nil},
+		{"within window", License{NotBefore: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}, nil},
+		{"expired", License{ExpiresAt: now.Add(-time.Second)}, ErrExpired},
+		{"not yet valid", License{NotBefore: now.Add(time.Hour)}, ErrNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.lic.Validate(now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// OtherFeature is the feature label of the IDs folded by the label
+// controls: not allowlisted, or beyond the WithMaxFeatures limit
+const OtherFeature = "other"
+
+// WithFeatureAllowlist exports only the listed feature IDs as feature
+// labels. The others are mapped by WithFeatureHashing or
+// WithFeatureBuckets if set, and reported as OtherFeature if not.
+func WithFeatureAllowlist(ids ...string) Option {
+	return func(m *Metrics) {
+		if m.features.allow == nil {
+			m.features.allow = make(map[string]bool, len(ids))
+		}
+		for _, id := range ids {
+			m.features.allow[id] = true
+		}
+	}
+}
+
+// WithFeatureHashing exports feature IDs as the first 8 hex digits of their
+// SHA-256, so feature names do not reach the monitoring backend. It hides
+// names without bounding the number of series; combine it with
+// WithMaxFeatures for that.
+func WithFeatureHashing() Option {
+	return func(m *Metrics) { m.features.mapID = hashFeature }
+}
+
+// WithFeatureBuckets exports feature IDs as one of n buckets chosen by
+// their hash ("bucket-00" to "bucket-<n-1>"), bounding the feature label to
+// n values. n < 1 is ignored.
+func WithFeatureBuckets(n int) Option {
+	return func(m *Metrics) {
+		if n < 1 {
+			return
+		}
+		width := len(fmt.Sprint(n - 1))
+		if width < 2 {
+			width = 2
+		}
+		m.features.mapID = func(id string) string {
+			sum := sha256.Sum256([]byte(id))
+			return fmt.Sprintf("bucket-%0*d", width, binary.BigEndian.Uint64(sum[:8])%uint64(n))
+		}
+	}
+}
+
+// WithMaxFeatures caps the number of distinct feature labels: once n
+// labels have been exported, new ones are reported as OtherFeature. Labels
+// are kept in order of first appearance. n < 1 means no limit.
+func WithMaxFeatures(n int) Option {
+	return func(m *Metrics) { m.features.max = n }
+}
+
+// hashFeature returns the short hash label of a feature ID
+func hashFeature(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:4])
+}
+
+// featureLabels maps feature IDs to the feature label of exported metrics
+type featureLabels struct {
+	allow map[string]bool        // nil exports every ID
+	mapID func(id string) string // applied to IDs not allowlisted
+	max   int
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// label returns the feature label of id
+func (f *featureLabels) label(id string) string {
+	label := id
+	switch {
+	case f.allow[id]:
+	case f.mapID != nil:
+		label = f.mapID(id)
+	case f.allow != nil:
+		label = OtherFeature
+	}
+	if f.max < 1 || label == OtherFeature {
+		return label
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.seen[label] {
+		return label
+	}
+	if len(f.seen) >= f.max {
+		return OtherFeature
+	}
+	if f.seen == nil {
+		f.seen = make(map[string]bool)
+	}
+	f.seen[label] = true
+	return label
+}
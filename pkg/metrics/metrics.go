@@ -0,0 +1,256 @@
+// Package metrics exports SDK internals as Prometheus metrics.
+//
+// A Metrics instruments one or more clients through their public extension
+// points (check pipeline stages, transport middleware and callbacks) and
+// serves the collected values in the Prometheus text exposition format,
+// from Handler or a side HTTP server started with Serve:
+//
+//	m := metrics.New()
+//	if err := m.Instrument(lccClient); err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Handle("/metrics", m.Handler())
+//
+// The package has no dependency on the Prometheus client library, so it can
+// be used in binaries that do not link it.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+)
+
+// DefaultNamespace prefixes every metric name unless WithNamespace is used
+const DefaultNamespace = "lcc_sdk"
+
+// DefaultLatencyBuckets are the bounds, in seconds, of the HTTP latency
+// histogram
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Names of the pipeline stages added by Instrument
+const (
+	StageMetrics     = "metrics"
+	StageCacheLookup = "metrics_cache"
+	StageCacheMiss   = "metrics_remote"
+)
+
+// Metrics collects metrics from instrumented clients. It is safe for
+// concurrent use.
+//
+// Metrics exported (with the default namespace):
+//
+//	lcc_sdk_feature_checks_total{feature,result}    result: enabled, disabled, error
+//	lcc_sdk_cache_hits_total, lcc_sdk_cache_misses_total
+//	lcc_sdk_consume_total{result}                   result: allowed, denied, error
+//	lcc_sdk_consume_units_total{result}
+//	lcc_sdk_current_tps                             summed over clients
+//	lcc_sdk_heartbeats_total{result}                result: ok, failed
+//	lcc_sdk_http_request_duration_seconds{endpoint,code}
+//
+// Every checked feature ID becomes a feature label value. When feature IDs
+// are dynamic, bound the label's cardinality with WithFeatureAllowlist,
+// WithFeatureHashing, WithFeatureBuckets or WithMaxFeatures.
+type Metrics struct {
+	namespace string
+	buckets   []float64
+	features  featureLabels
+
+	checks       *family
+	cacheHits    *family
+	cacheMisses  *family
+	consumes     *family
+	consumeUnits *family
+	tps          *family
+	heartbeats   *family
+	httpLatency  *family
+	families     []*family
+
+	mu      sync.Mutex
+	clients []*client.Client
+}
+
+// Option configures a Metrics
+type Option func(*Metrics)
+
+// WithNamespace sets the prefix of metric names (default "lcc_sdk")
+func WithNamespace(namespace string) Option {
+	return func(m *Metrics) { m.namespace = namespace }
+}
+
+// WithLatencyBuckets sets the bounds, in seconds, of the HTTP latency
+// histogram
+func WithLatencyBuckets(buckets ...float64) Option {
+	return func(m *Metrics) { m.buckets = buckets }
+}
+
+// New creates a Metrics with no instrumented clients
+func New(opts ...Option) *Metrics {
+	m := &Metrics{namespace: DefaultNamespace, buckets: DefaultLatencyBuckets}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.checks = m.newFamily("feature_checks_total", kindCounter, "Feature checks by result.", "feature", "result")
+	m.cacheHits = m.newFamily("cache_hits_total", kindCounter, "Feature checks answered from the cache.")
+	m.cacheMisses = m.newFamily("cache_misses_total", kindCounter, "Feature checks that went to the LCC server.")
+	m.consumes = m.newFamily("consume_total", kindCounter, "Consume calls by result.", "result")
+	m.consumeUnits = m.newFamily("consume_units_total", kindCounter, "Units passed to Consume by result.", "result")
+	m.tps = m.newFamily("current_tps", kindGauge, "Current request rate compared against the TPS limit.")
+	m.tps.sample = m.currentTPS
+	m.heartbeats = m.newFamily("heartbeats_total", kindCounter, "Background heartbeats by result.", "result")
+	m.httpLatency = m.newFamily("http_request_duration_seconds", kindHistogram, "Latency of LCC server calls.", "endpoint", "code")
+	m.httpLatency.buckets = m.buckets
+	return m
+}
+
+func (m *Metrics) newFamily(name, kind, help string, labels ...string) *family {
+	if m.namespace != "" {
+		name = m.namespace + "_" + name
+	}
+	f := &family{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+	m.families = append(m.families, f)
+	return f
+}
+
+// Instrument starts collecting metrics from c. Call it once per client,
+// after the check pipeline is set up: it adds a stage at the front of the
+// pipeline and markers before the cache (or dedup) and remote stages, a
+// transport middleware, and OnConsume and OnHeartbeat callbacks.
+func (m *Metrics) Instrument(c *client.Client) error {
+	if c == nil {
+		return errors.New("client is nil")
+	}
+	if err := c.InsertStageBefore(client.StageRemote, client.StageFunc(StageCacheMiss, m.checkMiss)); err != nil {
+		return err
+	}
+	for _, name := range []string{client.StageCache, client.StageDedup} {
+		if c.InsertStageBefore(name, client.StageFunc(StageCacheLookup, m.checkLookup)) == nil {
+			break
+		}
+	}
+	c.UseStage(client.StageFunc(StageMetrics, m.checkResult))
+	c.Use(m.transport)
+	c.OnConsume(m.observeConsume)
+	c.OnHeartbeat(m.observeHeartbeat)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients = append(m.clients, c)
+	return nil
+}
+
+// checkResult counts feature checks by result
+func (m *Metrics) checkResult(ctx context.Context, req *client.CheckRequest, next client.CheckFunc) (*client.FeatureStatus, error) {
+	status, err := next(ctx, req)
+	result := "error"
+	switch {
+	case err != nil || status == nil:
+	case status.Enabled:
+		result = "enabled"
+	default:
+		result = "disabled"
+	}
+	m.checks.add(1, m.features.label(req.FeatureID), result)
+	return status, err
+}
+
+// cacheProbe is set by the miss marker for a check seen by the lookup
+// marker
+type cacheProbe struct{ missed bool }
+
+type cacheProbeKey struct{}
+
+// checkLookup counts a check reaching the cache as a hit unless the miss
+// marker saw it too
+func (m *Metrics) checkLookup(ctx context.Context, req *client.CheckRequest, next client.CheckFunc) (*client.FeatureStatus, error) {
+	probe := &cacheProbe{}
+	status, err := next(context.WithValue(ctx, cacheProbeKey{}, probe), req)
+	if probe.missed {
+		m.cacheMisses.add(1)
+	} else if err == nil {
+		m.cacheHits.add(1)
+	}
+	return status, err
+}
+
+func (m *Metrics) checkMiss(ctx context.Context, req *client.CheckRequest, next client.CheckFunc) (*client.FeatureStatus, error) {
+	if probe, ok := ctx.Value(cacheProbeKey{}).(*cacheProbe); ok {
+		probe.missed = true
+	}
+	return next(ctx, req)
+}
+
+func (m *Metrics) observeConsume(ev client.ConsumeEvent) {
+	result := "error"
+	switch {
+	case ev.Allowed:
+		result = "allowed"
+	case ev.Denied:
+		result = "denied"
+	}
+	m.consumes.add(1, result)
+	m.consumeUnits.add(float64(ev.Amount), result)
+}
+
+func (m *Metrics) observeHeartbeat(err error) {
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	m.heartbeats.add(1, result)
+}
+
+// currentTPS sums the current TPS of the instrumented clients
+func (m *Metrics) currentTPS() float64 {
+	m.mu.Lock()
+	clients := m.clients
+	m.mu.Unlock()
+
+	var total float64
+	for _, c := range clients {
+		if tps, err := c.CurrentTPS(); err == nil {
+			total += tps
+		}
+	}
+	return total
+}
+
+// transport is client middleware timing each LCC server call
+func (m *Metrics) transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		m.httpLatency.observe(time.Since(start).Seconds(), endpointOf(req.URL.Path), code)
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// endpointOf returns the SDK endpoint of a request path, with feature IDs
+// replaced by a placeholder to bound the label's cardinality
+func endpointOf(path string) string {
+	if i := strings.Index(path, "/api/"); i >= 0 {
+		path = path[i:] // drop a gateway prefix
+	}
+	parts := strings.Split(path, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "features" && parts[i+1] != "" {
+			parts[i+1] = ":feature"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/lcc-sdk/pkg/client"
+	"github.com/yourorg/lcc-sdk/pkg/config"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	return buf.String()
+}
+
+// waitFor waits for text in the exposition; callbacks run asynchronously
+func waitFor(t *testing.T, m *Metrics, text string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		out := scrape(t, m)
+		if strings.Contains(out, text) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics missing %q:\n%s", text, out)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMetrics_Instrument(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/sdk/product/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enabled":    true,
+				"quota_info": map[string]interface{}{"limit": 10, "used": 0, "remaining": 10},
+			})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/sdk/features/"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": !strings.Contains(r.URL.Path, "sso")})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient(&config.SDKConfig{
+		LCCURL:         srv.URL,
+		ProductID:      "test-app",
+		ProductVersion: "1.0.0",
+		Timeout:        5 * time.Second,
+		CacheTTL:       time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	m := New()
+	if err := m.Instrument(c); err != nil {
+		t.Fatalf("Instrument() error = %v", err)
+	}
+
+	_, _ = c.CheckFeature("reports")
+	_, _ = c.CheckFeature("reports")
+	_, _ = c.CheckFeature("sso")
+	if ok, _, err := c.Consume(2); !ok {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	c.SetHeartbeatInterval(10 * time.Millisecond)
+	if err := c.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	out := scrape(t, m)
+	for _, line := range []string{
+		`lcc_sdk_feature_checks_total{feature="reports",result="enabled"} 2`,
+		`lcc_sdk_feature_checks_total{feature="sso",result="disabled"} 1`,
+		`lcc_sdk_cache_hits_total 1`,
+		`# TYPE lcc_sdk_http_request_duration_seconds histogram`,
+		`lcc_sdk_http_request_duration_seconds_count{endpoint="/api/v1/sdk/features/:feature/check",code="200"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics missing %q:\n%s", line, out)
+		}
+	}
+	// Two feature queries and the product status lookup
+	if !strings.Contains(out, "lcc_sdk_cache_misses_total 3\n") {
+		t.Errorf("cache misses, want 3:\n%s", out)
+	}
+	waitFor(t, m, "lcc_sdk_consume_total{result=\"allowed\"} 1\n")
+	waitFor(t, m, "lcc_sdk_consume_units_total{result=\"allowed\"} 2\n")
+	waitFor(t, m, "lcc_sdk_heartbeats_total{result=\"ok\"} ")
+}
+
+func TestMetrics_Serve(t *testing.T) {
+	m := New(WithNamespace("app"), WithLatencyBuckets(0.1, 1))
+	m.httpLatency.observe(0.5, "/api/v1/sdk/usage", "200")
+	m.httpLatency.observe(2, "/api/v1/sdk/usage", "200")
+
+	srv, err := m.Serve("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	want := `app_http_request_duration_seconds_bucket{endpoint="/api/v1/sdk/usage",code="200",le="0.1"} 0
+app_http_request_duration_seconds_bucket{endpoint="/api/v1/sdk/usage",code="200",le="1"} 1
+app_http_request_duration_seconds_bucket{endpoint="/api/v1/sdk/usage",code="200",le="+Inf"} 2
+app_http_request_duration_seconds_sum{endpoint="/api/v1/sdk/usage",code="200"} 2.5
+app_http_request_duration_seconds_count{endpoint="/api/v1/sdk/usage",code="200"} 2
+`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("histogram exposition:\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestEndpointOf(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/sdk/features/export_pdf/check": "/api/v1/sdk/features/:feature/check",
+		"/gw/lcc/api/v1/sdk/heartbeat":          "/api/v1/sdk/heartbeat",
+		"/api/v1/sdk/product/status":            "/api/v1/sdk/product/status",
+	}
+	for path, want := range tests {
+		if got := endpointOf(path); got != want {
+			t.Errorf("endpointOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestMetrics_FeatureLabels(t *testing.T) {
+	ids := []string{"reports", "sso", "tenant-1", "tenant-2", "tenant-3"}
+	labels := func(opts ...Option) []string {
+		m := New(opts...)
+		out := make([]string, len(ids))
+		for i, id := range ids {
+			out[i] = m.features.label(id)
+		}
+		return out
+	}
+
+	if got := labels(); !reflect.DeepEqual(got, ids) {
+		t.Errorf("default labels = %v, want %v", got, ids)
+	}
+	if got, want := labels(WithFeatureAllowlist("reports", "sso")), []string{"reports", "sso", OtherFeature, OtherFeature, OtherFeature}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allowlist labels = %v, want %v", got, want)
+	}
+	if got, want := labels(WithMaxFeatures(2)), []string{"reports", "sso", OtherFeature, OtherFeature, OtherFeature}; !reflect.DeepEqual(got, want) {
+		t.Errorf("max features labels = %v, want %v", got, want)
+	}
+
+	hashed := labels(WithFeatureAllowlist("reports"), WithFeatureHashing())
+	if hashed[0] != "reports" {
+		t.Errorf("allowlisted label = %q, want reports", hashed[0])
+	}
+	for i, label := range hashed[1:] {
+		if len(label) != 8 || label == ids[i+1] || label != hashFeature(ids[i+1]) {
+			t.Errorf("hashed label of %s = %q", ids[i+1], label)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		label := New(WithFeatureBuckets(4)).features.label(fmt.Sprintf("feature-%d", i))
+		if !strings.HasPrefix(label, "bucket-0") {
+			t.Fatalf("bucket label = %q", label)
+		}
+		seen[label] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("bucket labels = %v, want 4", seen)
+	}
+	if a, b := labels(WithFeatureBuckets(4)), labels(WithFeatureBuckets(4)); !reflect.DeepEqual(a, b) {
+		t.Errorf("bucket labels are not stable: %v, %v", a, b)
+	}
+
+	m := New(WithFeatureAllowlist("reports"))
+	m.checks.add(1, m.features.label("tenant-1"), "enabled")
+	m.checks.add(1, m.features.label("tenant-2"), "enabled")
+	if out := scrape(t, m); !strings.Contains(out, `lcc_sdk_feature_checks_total{feature="other",result="enabled"} 2`+"\n") {
+		t.Errorf("folded features not exported as other:\n%s", out)
+	}
+}
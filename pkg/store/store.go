@@ -0,0 +1,220 @@
+// Package store defines the key/value persistence used for SDK state.
+//
+// A client created with client.NewClientWithStore keeps its key pair, the
+// feature cache snapshot, undelivered usage events and a monotonic clock
+// mark in a Store. FileStore (a directory) and MemoryStore are provided;
+// embedded environments without a writable filesystem can supply their own
+// implementation, e.g. on top of a device's preferences or secure storage.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Get for a key that was never stored or has
+// been deleted
+var ErrNotFound = errors.New("store: key not found")
+
+// Store is a key/value store for SDK state. Keys are file-name-like: not
+// empty, "." or "..", and without path separators.
+//
+// Implementations must be safe for concurrent use. Put must be atomic: a
+// Get, including one after a crash during Put, returns either the previous
+// value or the new one, never a mix.
+type Store interface {
+	// Get returns the value of key, or ErrNotFound
+	Get(key string) ([]byte, error)
+
+	// Put replaces the value of key atomically
+	Put(key string, value []byte) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// ValidKey reports whether key can be used with the stores of this package
+func ValidKey(key string) bool {
+	return key != "" && key != "." && key != ".." && !strings.ContainsAny(key, "/\\\x00")
+}
+
+// FileStore keeps each key in a file of its own in a directory. Values are
+// written to a temporary file, synced and renamed over the old one, with
+// owner-only permissions.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store in dir. The directory is created (0700) on
+// the first Put if it does not exist.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Path returns the file holding key
+func (s *FileStore) Path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+// Get implements Store
+func (s *FileStore) Get(key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("store: invalid key %q", key)
+	}
+	data, err := os.ReadFile(s.Path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put implements Store
+func (s *FileStore) Put(key string, value []byte) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("store: invalid key %q", key)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, key+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path(key))
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(key string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("store: invalid key %q", key)
+	}
+	err := os.Remove(s.Path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// MemoryStore keeps values in memory. It suits tests and processes whose
+// state need not survive a restart.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string][]byte)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(key string, value []byte) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("store: invalid key %q", key)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// Counter is a monotonic counter persisted in a Store: its value never
+// decreases, including across restarts
+type Counter struct {
+	store Store
+	key   string
+
+	mu    sync.Mutex
+	value uint64
+}
+
+// NewCounter loads the counter stored under key; a missing key starts it
+// at zero
+func NewCounter(s Store, key string) (*Counter, error) {
+	c := &Counter{store: s, key: key}
+	data, err := s.Get(key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to load counter %s: %w", key, err)
+	default:
+		v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load counter %s: %w", key, err)
+		}
+		c.value = v
+	}
+	return c, nil
+}
+
+// Value returns the current value
+func (c *Counter) Value() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// Advance raises the counter to v if v is greater, persisting it first, and
+// returns the resulting value
+func (c *Counter) Advance(v uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.advanceLocked(v)
+}
+
+// Increment adds one to the counter, persisting it first, and returns the
+// new value
+func (c *Counter) Increment() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.advanceLocked(c.value + 1)
+}
+
+func (c *Counter) advanceLocked(v uint64) (uint64, error) {
+	if v <= c.value {
+		return c.value, nil
+	}
+	if err := c.store.Put(c.key, []byte(strconv.FormatUint(v, 10))); err != nil {
+		return c.value, fmt.Errorf("failed to save counter %s: %w", c.key, err)
+	}
+	c.value = v
+	return v, nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	if _, err := s.Get("cache"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a missing key error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put("cache", []byte("v1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("cache", []byte("v2")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	value, err := s.Get("cache")
+	if err != nil || string(value) != "v2" {
+		t.Errorf("Get() = %q, %v; want the latest value", value, err)
+	}
+	value[0] = 'x'
+	if again, _ := s.Get("cache"); string(again) != "v2" {
+		t.Errorf("modifying a returned value changed the store: %q", again)
+	}
+
+	if err := s.Put("../escape", []byte("x")); err == nil {
+		t.Error("Put() with a path in the key should fail")
+	}
+
+	if err := s.Delete("cache"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := s.Delete("cache"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+	if _, err := s.Get("cache"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	s := NewFileStore(dir)
+	testStore(t, s)
+
+	if err := s.Put("keypair", []byte("secret")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if info, err := os.Stat(s.Path("keypair")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("value file mode = %v, %v; want 0600", info, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("store directory holds %d files, want no temporary files left", len(entries))
+	}
+}
+
+func TestCounter(t *testing.T) {
+	s := NewMemoryStore()
+	c, err := NewCounter(s, "clock")
+	if err != nil || c.Value() != 0 {
+		t.Fatalf("NewCounter() = %v, %v; want a zero counter", c, err)
+	}
+
+	if v, err := c.Advance(100); err != nil || v != 100 {
+		t.Errorf("Advance(100) = %d, %v", v, err)
+	}
+	if v, _ := c.Advance(50); v != 100 {
+		t.Errorf("Advance(50) = %d, want 100 (never decreases)", v)
+	}
+	if v, _ := c.Increment(); v != 101 {
+		t.Errorf("Increment() = %d, want 101", v)
+	}
+
+	reloaded, err := NewCounter(s, "clock")
+	if err != nil || reloaded.Value() != 101 {
+		t.Errorf("reloaded counter = %v, %v; want 101", reloaded.Value(), err)
+	}
+
+	_ = s.Put("bad", []byte("not a number"))
+	if _, err := NewCounter(s, "bad"); err == nil {
+		t.Error("NewCounter() of a corrupt value should fail")
+	}
+}